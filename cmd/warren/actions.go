@@ -0,0 +1,461 @@
+// The action registry: every command a key, menu item or help entry can
+// name, with its label and handler, in one place.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/controller"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/runner"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// Keyboard actions. The names match the keys of the [keybindings] config
+// section, as listed by config.KeybindingsConfig.Bindings, so conflict
+// messages point at the right setting.
+const (
+	actionQuit            = "quit"
+	actionNavigateUp      = "navigate_up"
+	actionNavigateDown    = "navigate_down"
+	actionGoTop           = "go_top"
+	actionGoBottom        = "go_bottom"
+	actionHalfPageDown    = "half_page_down"
+	actionHalfPageUp      = "half_page_up"
+	actionViewTop         = "view_top"
+	actionViewMiddle      = "view_middle"
+	actionViewBottom      = "view_bottom"
+	actionParentDir       = "parent_dir"
+	actionEnterDir        = "enter_dir"
+	actionJump            = "jump"
+	actionToggleHidden    = "toggle_hidden"
+	actionFilter          = "filter"
+	actionToggleTree      = "toggle_tree"
+	actionCollapseAll     = "collapse_all"
+	actionExpandLevel     = "expand_level"
+	actionCycleSortMode   = "cycle_sort_mode"
+	actionToggleSortOrder = "toggle_sort_order"
+	actionYank            = "yank"
+	actionCut             = "cut"
+	actionDelete          = "delete"
+	actionShred           = "shred"
+	actionPaste           = "paste"
+	actionPasteTo         = "paste_to"
+	actionSendToWorkspace = "send_to_workspace"
+	actionHardLink        = "hard_link"
+	actionRename          = "rename"
+	actionRenamePhotos    = "rename_photos"
+	actionCleanup         = "cleanup"
+	actionShowHelp        = "show_help"
+	actionShowLog         = "show_log"
+	actionPreferences     = "preferences"
+	actionMoveToWorkspace = "move_to_workspace"
+	actionOpenOnWorkspace = "open_on_workspace"
+	actionWorkspaces      = "workspaces"
+	actionPinWorkspace    = "pin_workspace"
+	actionEdit            = "edit"
+	actionRun             = "run"
+	actionDefaultApp      = "default_app"
+	actionTogglePreview   = "toggle_preview"
+	actionQuickLook       = "quick_look"
+	actionProperties      = "properties"
+	actionToggleDualPane  = "toggle_dual_pane"
+	actionSwitchPane      = "switch_pane"
+	actionComparePanes    = "compare_panes"
+	actionCompareChecksum = "compare_checksum"
+	actionSyncNewer       = "sync_newer"
+	actionVisual          = "visual"
+	actionCommand         = "command"
+	actionRegister        = "register"
+	actionRegisters       = "registers"
+	actionUndo            = "undo"
+	actionHistory         = "history"
+
+	// actionContextMenu opens the context menu from the keyboard. It has
+	// no setting: the Menu key and Shift+F10 are bound like arrow keys
+	actionContextMenu = "context_menu"
+
+	// actionNewWindow opens another window. It has no setting: Ctrl+N is
+	// the application's shortcut for it
+	actionNewWindow = "new_window"
+
+	// scriptActionPrefix marks actions that run an init.lua command
+	scriptActionPrefix = "script:"
+)
+
+// actionState is what actions act on: one window's widgets and the
+// keyboard handler's modes. Only touched on the GTK main thread.
+type actionState struct {
+	cfg         *config.Config
+	views       *panes
+	previewPane *ui.PreviewPane
+	pathLabel   *gtk.Label
+	sortLabel   *gtk.Label
+	statusBar   *ui.StatusBar
+	window      *gtk.ApplicationWindow
+	wmState     *compositorState
+	quickLook   *ui.QuickLook
+	km          *keymap.Keymap
+	menuActions map[string]*gio.SimpleAction // The window's context menu actions
+
+	// The interaction layer: keyboard modes, navigation and editing
+	ctl *controller.Controller
+
+	// The key controller feeding ctl, and the input method context
+	// attached to it while a line is edited in the status bar
+	keys *gtk.EventControllerKey
+	im   *gtk.IMMulticontext
+}
+
+// count is the number typed before a command ("5j"). n is 1 when none
+// was typed, as for commands run from a menu.
+type count struct {
+	n     int
+	given bool
+}
+
+// action is a command of the registry.
+type action struct {
+	// name is the action's config key, which keys and menu items use
+	name string
+
+	// label is a short name for menus; help is the longer description in
+	// the shortcuts window, when label alone doesn't say enough
+	label string
+	help  string
+
+	// group is the shortcuts window section listing the action
+	group string
+
+	// enabled reports whether the action can run now; nil means always.
+	// Menus grey out disabled actions and keys do nothing, showing the
+	// reason from hint, if any.
+	enabled func(s *actionState) bool
+	hint    func(s *actionState) string
+
+	run func(s *actionState, c count)
+}
+
+// title returns the action's description in the shortcuts window.
+func (a *action) title() string {
+	if a.help != "" {
+		return a.help
+	}
+	return a.label
+}
+
+// Groups of the shortcuts window, in display order.
+const (
+	groupNavigation  = "Navigation"
+	groupFiles       = "File Operations"
+	groupDualPane    = "Dual Pane"
+	groupView        = "View"
+	groupApplication = "Application"
+)
+
+// actionGroups lists the groups in display order.
+var actionGroups = []string{groupNavigation, groupFiles, groupDualPane, groupView, groupApplication}
+
+// Common enabled predicates.
+func hasSelection(s *actionState) bool { return s.views.active().GetSelected() != nil }
+func inTreeMode(s *actionState) bool   { return s.views.active().TreeMode() }
+func inDualPane(s *actionState) bool   { return s.views.dual }
+
+// actions is the registry, in shortcuts window order within each group.
+var actions []action
+
+// The registry is filled in init because some actions, such as show_help,
+// read it themselves, which a variable initializer can't.
+func init() {
+	actions = []action{
+		// Navigation
+		{name: actionNavigateUp, label: "Move up", help: "Move up (count repeats)", group: groupNavigation,
+			run: func(s *actionState, c count) { s.ctl.Move(-c.n) }},
+		{name: actionNavigateDown, label: "Move down", help: "Move down (count repeats)", group: groupNavigation,
+			run: func(s *actionState, c count) { s.ctl.Move(c.n) }},
+		{name: actionParentDir, label: "Parent directory", group: groupNavigation,
+			run: func(s *actionState, _ count) { s.ctl.Parent() }},
+		{name: actionEnterDir, label: "Open", help: "Enter directory / open file", group: groupNavigation,
+			enabled: hasSelection, run: func(s *actionState, _ count) { s.ctl.Enter() }},
+		{name: actionJump, label: "Jump to name", help: "Jump to name (type a prefix)", group: groupNavigation,
+			run: func(s *actionState, _ count) { s.ctl.StartJump() }},
+		{name: actionGoTop, label: "Go to first entry", group: groupNavigation,
+			run: func(s *actionState, c count) { s.ctl.GoTop(c.n, c.given) }},
+		{name: actionGoBottom, label: "Go to last entry", help: "Go to last entry, or line N with a count", group: groupNavigation,
+			run: func(s *actionState, c count) { s.ctl.GoBottom(c.n, c.given) }},
+		{name: actionHalfPageDown, label: "Half page down", group: groupNavigation,
+			run: func(s *actionState, _ count) { s.ctl.HalfPage(true) }},
+		{name: actionHalfPageUp, label: "Half page up", group: groupNavigation,
+			run: func(s *actionState, _ count) { s.ctl.HalfPage(false) }},
+		{name: actionViewTop, label: "Top of screen", group: groupNavigation,
+			run: func(s *actionState, c count) { s.ctl.ViewTop(c.n) }},
+		{name: actionViewMiddle, label: "Middle of screen", group: groupNavigation,
+			run: func(s *actionState, _ count) { s.ctl.ViewMiddle() }},
+		{name: actionViewBottom, label: "Bottom of screen", group: groupNavigation,
+			run: func(s *actionState, c count) { s.ctl.ViewBottom(c.n) }},
+
+		// File operations
+		{name: actionYank, label: "Yank (copy)", help: "Yank (copy) file / unyank if already yanked", group: groupFiles,
+			enabled: hasSelection, run: func(s *actionState, c count) { s.ctl.Yank(c.n, c.given) }},
+		{name: actionCut, label: "Cut", help: "Cut file (moved on paste)", group: groupFiles,
+			enabled: hasSelection,
+			run:     func(s *actionState, c count) { s.ctl.Cut(c.n) }},
+		{name: actionVisual, label: "Select range", help: "Visual mode: move to select a range, then yank or cut it", group: groupFiles,
+			enabled: hasSelection, run: func(s *actionState, _ count) { s.ctl.StartVisual() }},
+		{name: actionPaste, label: "Paste", help: "Paste yanked files, or a register's after naming it", group: groupFiles,
+			enabled: func(s *actionState) bool { return !s.register(s.ctl.PendingRegister()).IsEmpty() },
+			hint:    func(s *actionState) string { return s.pasteHint() },
+			run: func(s *actionState, _ count) {
+				s.paste(s.ctl.TakeRegister(), s.active().GetCurrentPath())
+			}},
+		{name: actionPasteTo, label: "Paste to…", help: "Paste yanked files into a directory typed or bookmarked", group: groupFiles,
+			enabled: func(s *actionState) bool { return !s.register(s.ctl.PendingRegister()).IsEmpty() },
+			hint:    func(s *actionState) string { return s.pasteHint() },
+			run:     func(s *actionState, _ count) { s.ctl.StartPasteTo() }},
+		{name: actionSendToWorkspace, label: "Send to workspace", help: "Paste yanked files into the directory remembered for workspace N (type N first)", group: groupFiles,
+			enabled: func(s *actionState) bool {
+				return !s.register(s.ctl.PendingRegister()).IsEmpty() && s.wmState.active()
+			},
+			hint: func(s *actionState) string {
+				if !s.wmState.active() {
+					return "Workspace integration is not active (needs Hyprland or Sway)"
+				}
+				return s.pasteHint()
+			},
+			run: func(s *actionState, c count) {
+				if !c.given {
+					s.statusBar.Info(fmt.Sprintf("Type a workspace number first, e.g. 2 %s", s.cfg.Keybindings.SendToWorkspace))
+					return
+				}
+				dir, err := workspaceDir(s.wmState, c.n)
+				if err != nil {
+					s.statusBar.Warn(fmt.Sprintf("Can't send to workspace %d: %v", c.n, err))
+					return
+				}
+				s.paste(s.ctl.TakeRegister(), dir)
+			}},
+		{name: actionHardLink, label: "Hard link here", help: "Create hard links to yanked files in this directory", group: groupFiles,
+			enabled: func(s *actionState) bool { return !s.register(s.ctl.PendingRegister()).IsEmpty() },
+			hint:    func(s *actionState) string { return s.pasteHint() },
+			run: func(s *actionState, _ count) {
+				s.hardLink(s.ctl.TakeRegister(), s.active().GetCurrentPath())
+			}},
+		{name: actionRegister, label: "Use register", help: "Name a register (a to z) for the next yank, cut or paste", group: groupFiles,
+			run: func(s *actionState, _ count) { s.ctl.StartRegister() }},
+		{name: actionRegisters, label: "Registers…", help: "List the registers to paste or clear one", group: groupFiles,
+			run: func(s *actionState, _ count) { showRegisters(s) }},
+		{name: actionUndo, label: "Undo", help: "Undo the last copy, move, rename or trash (repeat to go further back)", group: groupFiles,
+			run: func(s *actionState, _ count) { undoLast(s) }},
+		{name: actionHistory, label: "History…", help: "List finished operations, filtered by type or path", group: groupFiles,
+			run: func(s *actionState, _ count) { showHistory(s) }},
+		{name: actionDelete, label: "Delete", help: "Delete file", group: groupFiles,
+			enabled: hasSelection,
+			run: func(s *actionState, _ count) {
+				fileView := s.active()
+				showDeleteDialog(s.cfg, s.window, fileView, fileView.GetSelected(), s.statusBar, s.pathLabel, s.wmState)
+			}},
+		{name: actionShred, label: "Shred", help: "Shred file: overwrite, then delete (secure_delete)", group: groupFiles,
+			enabled: func(s *actionState) bool { return s.cfg.General.SecureDelete && hasSelection(s) },
+			hint: func(s *actionState) string {
+				if !s.cfg.General.SecureDelete {
+					return "Secure delete is off; set secure_delete = true under [general] to enable it"
+				}
+				return ""
+			},
+			run: func(s *actionState, _ count) {
+				fileView := s.active()
+				showShredDialog(s.cfg, s.window, fileView, fileView.GetSelected(), s.statusBar)
+			}},
+		{name: actionRename, label: "Rename…", help: "Rename file", group: groupFiles,
+			enabled: hasSelection,
+			run:     func(s *actionState, _ count) { s.ctl.StartRename() }},
+		{name: actionRenamePhotos, label: "Rename photos by date", help: "Rename yanked photos after their capture time", group: groupFiles,
+			run: func(s *actionState, _ count) { renamePhotos(s.window, s.active(), s.statusBar) }},
+		{name: actionCleanup, label: "Find clutter", help: "Find broken links and empty directories to delete", group: groupFiles,
+			run: func(s *actionState, _ count) { findCleanup(s.window, s.active(), s.statusBar) }},
+		{name: actionProperties, label: "Properties", help: "Properties (size, type, media details)", group: groupFiles,
+			enabled: hasSelection,
+			run:     func(s *actionState, _ count) { ui.ShowProperties(&s.window.Window, s.active().GetSelected()) }},
+
+		// Dual pane
+		{name: actionToggleDualPane, label: "Dual pane", help: "Show/hide a second pane", group: groupDualPane,
+			run: func(s *actionState, _ count) {
+				if err := s.views.setDual(!s.views.dual); err != nil {
+					s.statusBar.Error(err.Error())
+					return
+				}
+				s.showFocusedPane()
+			}},
+		{name: actionSwitchPane, label: "Switch pane", help: "Focus the other pane", group: groupDualPane,
+			enabled: inDualPane,
+			hint: func(s *actionState) string {
+				return fmt.Sprintf("Only one pane; %s shows a second", s.cfg.Keybindings.ToggleDualPane)
+			},
+			run: func(s *actionState, _ count) {
+				s.views.switchFocus()
+				s.showFocusedPane()
+			}},
+		{name: actionComparePanes, label: "Compare panes", help: "Compare the panes by size and modification time", group: groupDualPane,
+			enabled: inDualPane, hint: func(*actionState) string { return "Comparing needs dual-pane mode" },
+			run: func(s *actionState, _ count) { comparePanes(s.views, fileops.CompareSizeTime, s.statusBar) }},
+		{name: actionCompareChecksum, label: "Compare panes by contents", help: "Compare the panes by file contents", group: groupDualPane,
+			enabled: inDualPane, hint: func(*actionState) string { return "Comparing needs dual-pane mode" },
+			run: func(s *actionState, _ count) { comparePanes(s.views, fileops.CompareChecksum, s.statusBar) }},
+		{name: actionSyncNewer, label: "Copy newer to other pane", help: "Copy missing and newer files to the other pane", group: groupDualPane,
+			enabled: inDualPane, hint: func(*actionState) string { return "Syncing needs dual-pane mode" },
+			run: func(s *actionState, _ count) { syncNewer(s.cfg, s.window, s.views, s.statusBar) }},
+
+		// View
+		{name: actionToggleHidden, label: "Hidden files", help: "Toggle hidden files", group: groupView,
+			run: func(s *actionState, _ count) { s.ctl.ToggleHidden() }},
+		{name: actionFilter, label: "Filter…", help: "Filter by glob or extension (empty clears)", group: groupView,
+			run: func(s *actionState, _ count) { s.ctl.StartFilter() }},
+		{name: actionToggleTree, label: "Tree mode", help: "Toggle tree mode (expand directories in place)", group: groupView,
+			run: func(s *actionState, _ count) { s.ctl.ToggleTree() }},
+		{name: actionCollapseAll, label: "Collapse all", help: "Collapse all directories", group: groupView,
+			run: func(s *actionState, _ count) { s.ctl.CollapseAll() }},
+		{name: actionExpandLevel, label: "Expand levels", help: "Expand directories N levels deep (type N first)", group: groupView,
+			enabled: inTreeMode, hint: func(*actionState) string { return "Expanding needs tree mode" },
+			// Without a count, one level: the current directory's children
+			run: func(s *actionState, c count) { s.ctl.ExpandLevels(c.n) }},
+		{name: actionTogglePreview, label: "Preview pane", help: "Show/hide the preview pane", group: groupView,
+			run: func(s *actionState, _ count) { s.previewPane.SetVisible(!s.previewPane.Visible()) }},
+		{name: actionQuickLook, label: "Quick look", help: "Quick look (←/→ for neighbours, Escape closes)", group: groupView,
+			enabled: hasSelection,
+			run: func(s *actionState, _ count) {
+				fileView := s.active()
+				s.quickLook.SetTextLimit(s.cfg.Preview.MaxTextBytes())
+				s.quickLook.Open(fileView.Files(), fileView.SelectedIndex())
+			}},
+		{name: actionCycleSortMode, label: "Cycle sort mode", group: groupView,
+			run: func(s *actionState, _ count) { s.ctl.CycleSortMode() }},
+		{name: actionToggleSortOrder, label: "Reverse sort order", help: "Toggle sort order", group: groupView,
+			run: func(s *actionState, _ count) { s.ctl.ToggleSortOrder() }},
+
+		// Application
+		{name: actionShowHelp, label: "Keyboard shortcuts", help: "Show this help", group: groupApplication,
+			run: func(s *actionState, _ count) { showShortcutsWindow(s.window, s.km) }},
+		{name: actionShowLog, label: "Log", help: "Show the log (for bug reports)", group: groupApplication,
+			run: func(s *actionState, _ count) { showLogViewer(s.window, s.statusBar) }},
+		{name: actionPreferences, label: "Preferences", group: groupApplication,
+			run: func(s *actionState, _ count) {
+				cfg := s.cfg
+				ui.ShowPreferences(&s.window.Window, cfg, func(edited *config.Config) error {
+					if err := saveConfig(edited); err != nil {
+						return fmt.Errorf("failed to save settings: %w", err)
+					}
+					applyConfig(cfg, edited)
+					return nil
+				})
+			}},
+		{name: actionMoveToWorkspace, label: "Move window to workspace", help: "Move window to workspace N (type N first)", group: groupApplication,
+			run: func(s *actionState, c count) {
+				if !c.given {
+					s.statusBar.Info(fmt.Sprintf("Type a workspace number first, e.g. 2 %s", s.cfg.Keybindings.MoveToWorkspace))
+					return
+				}
+				moveToWorkspace(s.wmState, c.n, s.active(), s.pathLabel, s.statusBar)
+			}},
+		{name: actionOpenOnWorkspace, label: "Open on workspace", help: "Open file on workspace N, or where its type was last opened", group: groupApplication,
+			enabled: func(s *actionState) bool {
+				selected := s.active().GetSelected()
+				return selected != nil && !selected.IsDir && s.wmState.active()
+			},
+			hint: func(s *actionState) string {
+				if !s.wmState.active() {
+					return "Workspace integration is not active (needs Hyprland or Sway)"
+				}
+				return ""
+			},
+			run: func(s *actionState, c count) {
+				// A count names the workspace; without one the file goes where
+				// its type was last opened
+				workspace := config.OpenLastWorkspace
+				if c.given {
+					workspace = strconv.Itoa(c.n)
+				}
+				openSelected(s.cfg, s.wmState, s.active().GetSelected(), workspace, s.statusBar)
+			}},
+		{name: actionWorkspaces, label: "Workspace memory…", help: "List the directories remembered per workspace to go to, edit or forget", group: groupApplication,
+			run: func(s *actionState, _ count) { showWorkspaceMemory(s) }},
+		{name: actionPinWorkspace, label: "Pin directory to workspace", help: "Always open this directory on this workspace, or unpin it", group: groupApplication,
+			run: func(s *actionState, _ count) { togglePin(s.wmState, s.active().GetCurrentPath(), s.statusBar) }},
+		{name: actionEdit, label: "Edit in terminal", help: "Open file in $EDITOR inside a terminal", group: groupApplication,
+			enabled: hasSelection,
+			run: func(s *actionState, _ count) {
+				editSelected(s.cfg, s.wmState, s.active().GetSelected(), s.statusBar)
+			}},
+		{name: actionRun, label: "Run…", help: "Run executable file, asking for arguments", group: groupApplication,
+			enabled: func(s *actionState) bool {
+				selected := s.active().GetSelected()
+				return selected != nil && !selected.IsDir && runner.Executable(selected.Path)
+			},
+			hint: func(*actionState) string { return "Select an executable file" },
+			run:  func(s *actionState, _ count) { s.ctl.StartRun() }},
+		{name: actionDefaultApp, label: "Default application…", help: "Change the application that opens this type of file", group: groupApplication,
+			enabled: hasSelection,
+			run: func(s *actionState, _ count) {
+				showDefaultAppDialog(s.window, s.active().GetSelected(), s.statusBar)
+			}},
+		{name: actionCommand, label: "Run command", help: "Run an action by name (Tab completes)", group: groupApplication,
+			run: func(s *actionState, _ count) { s.ctl.StartCommand() }},
+		{name: actionContextMenu, label: "Context menu", group: groupApplication,
+			run: func(s *actionState, _ count) { showContextMenu(s) }},
+		{name: actionNewWindow, label: "New window", help: "Open another window in this directory", group: groupApplication,
+			run: func(s *actionState, _ count) {
+				activate(s.window.Application(), s.cfg, s.active().GetCurrentPath(), "")
+			}},
+		{name: actionQuit, label: "Close window", group: groupApplication,
+			run: func(s *actionState, _ count) { s.window.Close() }},
+	}
+}
+
+// findAction returns the registered action called name, or nil.
+func findAction(name string) *action {
+	for i := range actions {
+		if actions[i].name == name {
+			return &actions[i]
+		}
+	}
+	return nil
+}
+
+// runAction runs the action called name, including init.lua commands,
+// unless it is disabled, when its hint goes to the status bar. Returns
+// false for an unknown name.
+func runAction(s *actionState, name string, c count) bool {
+	if command, ok := strings.CutPrefix(name, scriptActionPrefix); ok {
+		runScriptCommand(command, s.statusBar)
+		return true
+	}
+	a := findAction(name)
+	if a == nil {
+		return false
+	}
+	if a.enabled != nil && !a.enabled(s) {
+		if a.hint != nil {
+			if hint := a.hint(s); hint != "" {
+				s.statusBar.Warn(hint)
+			}
+		}
+		return true
+	}
+	a.run(s, c)
+	return true
+}
+
+// active returns the focused file view.
+func (s *actionState) active() *ui.FileView {
+	return s.views.active()
+}
+
+// showFocusedPane updates the window for the pane that now has focus.
+func (s *actionState) showFocusedPane() {
+	showFocusedPane(s.views, s.previewPane, s.pathLabel, s.sortLabel, s.statusBar)
+}
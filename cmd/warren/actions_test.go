@@ -0,0 +1,43 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lawrab/warren/internal/config"
+)
+
+func TestActionsCoverBindings(t *testing.T) {
+	for _, b := range config.Default().Keybindings.Bindings() {
+		if findAction(b.Action) == nil {
+			t.Errorf("keybinding %s has no registered action", b.Action)
+		}
+	}
+}
+
+func TestActions(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, a := range actions {
+		if seen[a.name] {
+			t.Errorf("action %s is registered twice", a.name)
+		}
+		seen[a.name] = true
+		if a.label == "" || a.run == nil {
+			t.Errorf("action %s lacks a label or handler", a.name)
+		}
+		if !slices.Contains(actionGroups, a.group) {
+			t.Errorf("action %s is in unknown group %q", a.name, a.group)
+		}
+		if a.hint != nil && a.enabled == nil {
+			t.Errorf("action %s has a hint but is never disabled", a.name)
+		}
+	}
+
+	for _, section := range contextMenuSections {
+		for _, name := range section {
+			if findAction(name) == nil {
+				t.Errorf("context menu item %s is not a registered action", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Cleanup of broken symlinks and empty directories under the current
+// directory.
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// findCleanup scans the current directory's tree in the background and
+// lists what it finds for review.
+func findCleanup(window *gtk.ApplicationWindow, fileView *ui.FileView, statusBar *ui.StatusBar) {
+	root := fileView.GetCurrentPath()
+	statusBar.Info("Looking for broken links and empty directories…")
+
+	go func() {
+		items, err := fileops.FindCleanup(context.Background(), root)
+		glib.IdleAdd(func() {
+			switch {
+			case err != nil:
+				statusBar.Error(fmt.Sprintf("Failed to scan: %v", err))
+			case len(items) == 0:
+				statusBar.Info("No broken links or empty directories")
+			default:
+				showCleanupWindow(window, fileView, root, items, statusBar)
+			}
+		})
+	}()
+}
+
+// showCleanupWindow lists cleanup candidates with a checkbox each, all
+// ticked, and deletes the ticked ones when confirmed. Each row also has a
+// button revealing the entry in the main window.
+func showCleanupWindow(window *gtk.ApplicationWindow, fileView *ui.FileView, root string, items []fileops.CleanupItem, statusBar *ui.StatusBar) {
+	win := gtk.NewWindow()
+	win.SetTitle("Clean Up")
+	win.SetTransientFor(&window.Window)
+	win.SetModal(true)
+	win.SetDefaultSize(520, 420)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 6)
+	box.SetMarginTop(12)
+	box.SetMarginBottom(12)
+	box.SetMarginStart(12)
+	box.SetMarginEnd(12)
+
+	header := gtk.NewLabel(fmt.Sprintf("Found %d item(s) under %s. Untick anything to keep.", len(items), fileops.DisplayName(root)))
+	header.SetXAlign(0)
+	header.SetWrap(true)
+	box.Append(header)
+
+	list := gtk.NewBox(gtk.OrientationVertical, 2)
+	checks := make([]*gtk.CheckButton, len(items))
+	for i, item := range items {
+		rel, err := filepath.Rel(root, item.Path)
+		if err != nil {
+			rel = item.Path
+		}
+		checks[i] = gtk.NewCheckButtonWithLabel(fmt.Sprintf("%s (%s)", fileops.DisplayName(rel), item.Kind))
+		checks[i].SetActive(true)
+		checks[i].SetHExpand(true)
+
+		reveal := gtk.NewButtonFromIconName("folder-open-symbolic")
+		reveal.SetTooltipText("Show in folder")
+		ui.SetAccessibleLabel(reveal, "Show "+rel+" in folder")
+		reveal.AddCSSClass("flat")
+		path := item.Path
+		reveal.ConnectClicked(func() {
+			win.Close()
+			if w := windowFor(window); w != nil {
+				_ = w.reveal(path)
+			}
+		})
+
+		row := gtk.NewBox(gtk.OrientationHorizontal, 6)
+		row.Append(checks[i])
+		row.Append(reveal)
+		list.Append(row)
+	}
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetVExpand(true)
+	scrolled.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	scrolled.SetChild(list)
+	box.Append(scrolled)
+
+	buttons := gtk.NewBox(gtk.OrientationHorizontal, 6)
+	buttons.SetHAlign(gtk.AlignEnd)
+	cancel := gtk.NewButtonWithLabel("Cancel")
+	remove := gtk.NewButtonWithLabel("Delete")
+	remove.AddCSSClass("destructive-action")
+	buttons.Append(cancel)
+	buttons.Append(remove)
+	box.Append(buttons)
+
+	cancel.ConnectClicked(win.Close)
+	remove.ConnectClicked(func() {
+		var chosen []fileops.CleanupItem
+		for i, check := range checks {
+			if check.Active() {
+				chosen = append(chosen, items[i])
+			}
+		}
+		win.Close()
+		if len(chosen) > 0 {
+			deleteCleanup(window, fileView, root, chosen, statusBar)
+		}
+	})
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			win.Close()
+			return true
+		}
+		return false
+	})
+	win.AddController(keys)
+
+	win.SetChild(box)
+	win.Present()
+}
+
+// deleteCleanup removes the chosen items in the background and reloads
+// the listing when done.
+func deleteCleanup(window *gtk.ApplicationWindow, fileView *ui.FileView, root string, items []fileops.CleanupItem, statusBar *ui.StatusBar) {
+	fileops.DeleteCleanup(items, func(op *fileops.Operation) {
+		glib.IdleAdd(func() {
+			switch op.Status {
+			case fileops.StatusCompleted:
+				statusBar.Info(fmt.Sprintf("Removed %d item(s)", len(items)))
+			case fileops.StatusFailed:
+				statusBar.Error(op.Error.Error())
+			}
+			if fileView.GetCurrentPath() == root {
+				_ = fileView.LoadDirectory(root)
+			}
+			windowFor(window).reportOperation(op, root)
+		})
+	})
+}
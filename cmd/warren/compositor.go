@@ -0,0 +1,496 @@
+// Compositor integration setup and event handling.
+// This file contains the Hyprland/Sway wiring code including compositor
+// detection, workspace change listeners, and workspace memory integration.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/compositor"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// compositorState holds compositor (Hyprland or Sway) integration state.
+// Each window has its own, sharing sharedMemory. Without a compositor, wm
+// is nil and memory, if set, remembers one directory under sessionKey
+// (see fallback_memory).
+type compositorState struct {
+	wm         compositor.Compositor
+	memory     *compositor.WorkspaceMemory // sharedMemory, or nil when memory is off
+	sessionKey string                      // Memory key without a compositor
+	cfg        *config.Config
+
+	stopEvents context.CancelFunc // Ends the workspace subscription; nil if not subscribed
+}
+
+// sharedMemory is the workspace memory of every window, loaded by the
+// first that needs it. Windows with their own copies would each save
+// theirs over the others'. GTK thread only.
+var sharedMemory *compositor.WorkspaceMemory
+
+// setupCompositor initializes Hyprland or Sway integration if enabled and
+// available. Without either, it falls back to remembering the last
+// directory per session if fallback_memory is set, and otherwise returns
+// nil, as it does when integration is disabled in config.
+func setupCompositor(cfg *config.Config) *compositorState {
+	// Check if integration is enabled
+	if !cfg.Hyprland.Enabled {
+		slog.Info("Compositor integration disabled in config")
+		return nil
+	}
+
+	wm, err := compositor.Detect()
+	if errors.Is(err, compositor.ErrNotDetected) {
+		slog.Info("Not running in Hyprland or Sway, skipping integration")
+		return setupSessionMemory(cfg)
+	}
+	if err != nil {
+		slog.Warn("Failed to connect to compositor", "err", err)
+		return setupSessionMemory(cfg)
+	}
+
+	// Create workspace memory if enabled
+	var memory *compositor.WorkspaceMemory
+	if cfg.Hyprland.WorkspaceMemory {
+		memory = loadSharedMemory()
+	}
+
+	slog.Info("Compositor integration initialized", "compositor", wm.Name())
+	return &compositorState{
+		wm:     wm,
+		memory: memory,
+		cfg:    cfg,
+	}
+}
+
+// setupSessionMemory returns the state for remembering the last directory
+// without a compositor, keyed by compositor.SessionKey, or nil if
+// fallback_memory or workspace_memory is off.
+func setupSessionMemory(cfg *config.Config) *compositorState {
+	if !cfg.Hyprland.WorkspaceMemory || !cfg.Hyprland.FallbackMemory {
+		return nil
+	}
+	memory := loadSharedMemory()
+	if memory == nil {
+		return nil
+	}
+	key := compositor.SessionKey()
+	slog.Info("Remembering the last directory per session", "key", key)
+	return &compositorState{
+		memory:     memory,
+		sessionKey: key,
+		cfg:        cfg,
+	}
+}
+
+// loadSharedMemory returns sharedMemory, loading it first if no window
+// has, or nil if it can't be created.
+func loadSharedMemory() *compositor.WorkspaceMemory {
+	if sharedMemory != nil {
+		return sharedMemory
+	}
+	stateDir, err := config.StateDir()
+	if err != nil {
+		slog.Warn("Failed to get state dir", "err", err)
+		stateDir = ""
+	}
+
+	memory, err := compositor.NewWorkspaceMemory(stateDir)
+	if err != nil {
+		slog.Warn("Failed to create workspace memory", "err", err)
+		return nil
+	}
+	slog.Info("Workspace memory enabled")
+	sharedMemory = memory
+	return memory
+}
+
+// startWorkspaceListener starts listening for workspace changes in a
+// goroutine and switches the file view to the remembered directory.
+// With several windows open, only the most recently focused one follows,
+// so they don't all end up in the same directory.
+// The subscription ends when cs.stopEvents is called.
+func startWorkspaceListener(cs *compositorState, cfg *config.Config, fileView *ui.FileView, pathLabel *gtk.Label, statusBar *ui.StatusBar) {
+	if cs == nil || cs.wm == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes, err := cs.wm.WorkspaceChanges(ctx)
+	if err != nil {
+		cancel()
+		slog.Warn("Failed to subscribe to compositor events", "compositor", cs.wm.Name(), "err", err)
+		return
+	}
+	cs.stopEvents = cancel
+
+	go func() {
+		for workspace := range changes {
+			if !cfg.Hyprland.AutoSwitch || cs.memory == nil {
+				continue
+			}
+
+			// Get remembered directory for this workspace
+			rememberedDir := cs.memory.Get(cs.memoryKey(workspace))
+			if rememberedDir == "" {
+				slog.Debug("No remembered directory for workspace", "workspace", workspace)
+				continue
+			}
+
+			// Verify directory still exists
+			if info, err := os.Stat(rememberedDir); err != nil || !info.IsDir() {
+				slog.Info("Remembered directory no longer exists", "path", rememberedDir)
+				continue
+			}
+
+			// Switch to remembered directory (must use glib.IdleAdd for GTK operations)
+			glib.IdleAdd(func() {
+				if !followsWorkspace(fileView) {
+					return
+				}
+				if err := fileView.LoadDirectory(rememberedDir); err != nil {
+					slog.Warn("Failed to load remembered directory", "err", err)
+					statusBar.Error(fmt.Sprintf("Failed to load: %v", err))
+				} else {
+					pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+					updateStatusBar(statusBar, fileView)
+				}
+			})
+		}
+		slog.Debug("Workspace listener stopped")
+	}()
+
+	slog.Debug("Workspace listener started", "compositor", cs.wm.Name())
+}
+
+// followsWorkspace reports whether the window showing fileView is the
+// Warren window focused last, the one workspace changes move.
+func followsWorkspace(fileView *ui.FileView) bool {
+	w := windowForView(fileView)
+	return w != nil && activeWindow(w.window.Application()) == w
+}
+
+// saveCurrentDirectoryToWorkspace saves the current directory to workspace
+// memory, unless the workspace is pinned to another.
+func saveCurrentDirectoryToWorkspace(cs *compositorState, currentPath string) {
+	if cs == nil || cs.memory == nil {
+		return
+	}
+
+	// Get current workspace
+	key := cs.sessionKey
+	if cs.wm != nil {
+		workspace, err := cs.currentWorkspace()
+		if err != nil {
+			slog.Warn("Failed to get active workspace", "err", err)
+			return
+		}
+		key = cs.memoryKey(workspace)
+	}
+
+	// Save current directory to memory
+	if !cs.memory.Remember(key, currentPath) {
+		return
+	}
+
+	// Persist to disk
+	if err := cs.memory.Save(); err != nil {
+		slog.Warn("Failed to save workspace memory", "err", err)
+	}
+}
+
+// directoryVisited records a directory the user navigated to. With
+// switch_workspace set, a directory another workspace remembers takes
+// Warren to that workspace instead, leaving the current one's memory as
+// it was; otherwise it is remembered for the current workspace.
+func directoryVisited(cs *compositorState, dir string, statusBar *ui.StatusBar) {
+	if cs == nil || cs.wm == nil || cs.memory == nil || !cs.cfg.Hyprland.SwitchWorkspace {
+		saveCurrentDirectoryToWorkspace(cs, dir)
+		return
+	}
+
+	current, err := cs.currentWorkspace()
+	if err != nil {
+		slog.Warn("Failed to get active workspace", "err", err)
+		return
+	}
+	currentKey := cs.memoryKey(current)
+	keys := cs.memory.WorkspacesOf(dir)
+	if len(keys) == 0 || slices.Contains(keys, currentKey) {
+		saveCurrentDirectoryToWorkspace(cs, dir)
+		return
+	}
+
+	workspace, _ := compositor.SplitMemoryKey(keys[0])
+	if err := cs.wm.MoveToWorkspace(workspace, os.Getpid()); err != nil {
+		slog.Warn("Failed to move to workspace", "workspace", workspace, "err", err)
+		statusBar.Error(fmt.Sprintf("Failed to move to workspace %s: %v", workspace, err))
+		return
+	}
+	statusBar.Info(fmt.Sprintf("Moved to workspace %s, which remembers %s", workspace, fileops.DisplayName(dir)))
+}
+
+// moveToWorkspace sends Warren's window to a numbered workspace and loads
+// the directory remembered there. With nothing remembered, the current
+// directory comes along and is remembered for the new workspace.
+func moveToWorkspace(cs *compositorState, number int, fileView *ui.FileView, pathLabel *gtk.Label, statusBar *ui.StatusBar) {
+	if cs == nil || cs.wm == nil {
+		statusBar.Warn("Workspace integration is not active (needs Hyprland or Sway)")
+		return
+	}
+
+	// Remember where we were on the workspace being left
+	saveCurrentDirectoryToWorkspace(cs, fileView.GetCurrentPath())
+
+	workspace := strconv.Itoa(number)
+	if err := cs.wm.MoveToWorkspace(workspace, os.Getpid()); err != nil {
+		slog.Warn("Failed to move to workspace", "workspace", workspace, "err", err)
+		statusBar.Error(fmt.Sprintf("Failed to move to workspace %s: %v", workspace, err))
+		return
+	}
+
+	if cs.memory == nil {
+		statusBar.Info(fmt.Sprintf("Moved to workspace %s", workspace))
+		return
+	}
+
+	key := cs.memoryKey(workspace)
+	rememberedDir := cs.memory.Get(key)
+	if info, err := os.Stat(rememberedDir); rememberedDir == "" || err != nil || !info.IsDir() {
+		if cs.memory.Remember(key, fileView.GetCurrentPath()) {
+			if err := cs.memory.Save(); err != nil {
+				slog.Warn("Failed to save workspace memory", "err", err)
+			}
+		}
+		statusBar.Info(fmt.Sprintf("Moved to workspace %s", workspace))
+		return
+	}
+
+	if err := fileView.LoadDirectory(rememberedDir); err != nil {
+		slog.Warn("Failed to load remembered directory", "err", err)
+		statusBar.Error(fmt.Sprintf("Failed to load: %v", err))
+		return
+	}
+	pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+	updateStatusBar(statusBar, fileView)
+	statusBar.Info(fmt.Sprintf("Moved to workspace %s", workspace))
+}
+
+// workspaceDir returns the directory remembered for a numbered workspace,
+// so files can be pasted there without going to it.
+func workspaceDir(cs *compositorState, number int) (string, error) {
+	if cs == nil || cs.wm == nil {
+		return "", errors.New("workspace integration is not active (needs Hyprland or Sway)")
+	}
+	if cs.memory == nil {
+		return "", errors.New("workspace memory is off (workspace_memory under [hyprland])")
+	}
+
+	workspace := strconv.Itoa(number)
+	dir := cs.memory.Get(cs.memoryKey(workspace))
+	if dir == "" {
+		return "", fmt.Errorf("no directory is remembered for workspace %s", workspace)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s, remembered for workspace %s, no longer exists", fileops.DisplayName(dir), workspace)
+	}
+	return dir, nil
+}
+
+// togglePin pins the workspace Warren is on to dir, so it always opens
+// there, or unpins it if it is pinned.
+func togglePin(cs *compositorState, dir string, statusBar *ui.StatusBar) {
+	if cs == nil || cs.wm == nil {
+		statusBar.Warn("Workspace integration is not active (needs Hyprland or Sway)")
+		return
+	}
+	if cs.memory == nil {
+		statusBar.Warn("Workspace memory is off (workspace_memory under [hyprland])")
+		return
+	}
+	workspace, err := cs.currentWorkspace()
+	if err != nil {
+		statusBar.Error(fmt.Sprintf("Failed to find the workspace: %v", err))
+		return
+	}
+
+	key := cs.memoryKey(workspace)
+	if cs.memory.Pinned(key) {
+		cs.memory.Pin(key, false)
+		cs.memory.Set(key, dir)
+		statusBar.Info(fmt.Sprintf("Unpinned workspace %s", workspace))
+	} else {
+		cs.memory.Set(key, dir)
+		cs.memory.Pin(key, true)
+		statusBar.Info(fmt.Sprintf("Workspace %s always opens %s", workspace, fileops.DisplayName(dir)))
+	}
+	if err := cs.memory.Save(); err != nil {
+		slog.Warn("Failed to save workspace memory", "err", err)
+	}
+}
+
+// newWindowTimeout is how long to wait for an opened file's window to
+// appear so it can be focused.
+const newWindowTimeout = 10 * time.Second
+
+// openFile opens a file with its opener from cfg or its default
+// application (see fileops.OpenFile). Under Hyprland or
+// Sway, workspace picks where it opens: "" for the current workspace,
+// config.OpenLastWorkspace for the one its file type was last opened on,
+// or a workspace name, which the compositor switches to before launching
+// the application there. The new window is focused once it appears.
+//
+// With focus_existing set, a window that already shows the file is
+// focused instead and reused reports true.
+func openFile(cfg *config.Config, cs *compositorState, path, workspace string) (reused bool, err error) {
+	if cs == nil || cs.wm == nil {
+		return false, fileops.OpenFile(path, cfg.Openers)
+	}
+
+	if cs.cfg.Hyprland.FocusExisting {
+		found, err := cs.wm.FocusWindow(compositor.FileWindowMatcher(path, fileops.DefaultAppID(path)))
+		if err != nil {
+			slog.Warn("Failed to look for a window showing file", "path", path, "err", err)
+		}
+		if found && err == nil {
+			return true, nil
+		}
+	}
+
+	// Subscribe before launching so the new window cannot be missed
+	ctx, cancel := context.WithTimeout(context.Background(), newWindowTimeout)
+	focused, err := cs.wm.FocusNextWindow(ctx)
+	if err != nil {
+		cancel()
+		slog.Warn("Failed to watch for the new window", "err", err)
+	} else {
+		go func() {
+			defer cancel()
+			if err := <-focused; err != nil {
+				slog.Debug("Did not focus the window for file", "path", path, "err", err)
+			}
+		}()
+	}
+	if err := launchOnWorkspace(cs, path, workspace); err != nil {
+		cancel()
+		return false, err
+	}
+	return false, nil
+}
+
+// launchOnWorkspace opens path with its opener or default application on
+// workspace (see openFile) and remembers where its file type was opened.
+func launchOnWorkspace(cs *compositorState, path, workspace string) error {
+	ext := filepath.Ext(path)
+	if workspace == config.OpenLastWorkspace {
+		workspace = ""
+		if cs.memory != nil {
+			workspace = cs.memory.TypeWorkspace(ext)
+		}
+	}
+
+	if workspace == "" {
+		if err := fileops.OpenFile(path, cs.cfg.Openers); err != nil {
+			return err
+		}
+		if current, err := cs.currentWorkspace(); err == nil {
+			rememberFileType(cs, ext, current)
+		}
+		return nil
+	}
+
+	argv, err := fileops.OpenCommand(path, cs.cfg.Openers)
+	if err != nil {
+		return err
+	}
+	if err := cs.wm.ExecOn(workspace, argv...); err != nil {
+		return fmt.Errorf("failed to open file on workspace %s: %w", workspace, err)
+	}
+	rememberFileType(cs, ext, workspace)
+	return nil
+}
+
+// launchHere starts argv in dir on the workspace Warren is on. Launched
+// through the compositor, the command would start in the compositor's
+// directory, so a shell changes to dir first.
+func launchHere(cs *compositorState, dir string, argv ...string) error {
+	if cs == nil || cs.wm == nil {
+		return fileops.Launch(dir, argv...)
+	}
+	workspace, err := cs.currentWorkspace()
+	if err != nil {
+		slog.Warn("Failed to find Warren's workspace", "err", err)
+		return fileops.Launch(dir, argv...)
+	}
+	inDir := append([]string{"sh", "-c", `cd -- "$0" && exec "$@"`, dir}, argv...)
+	return cs.wm.ExecOn(workspace, inDir...)
+}
+
+// rememberFileType records the workspace a file type was opened on.
+func rememberFileType(cs *compositorState, ext, workspace string) {
+	if cs.memory == nil || ext == "" {
+		return
+	}
+	cs.memory.SetTypeWorkspace(ext, workspace)
+	if err := cs.memory.Save(); err != nil {
+		slog.Warn("Failed to save workspace memory", "err", err)
+	}
+}
+
+// currentWorkspace returns the name of the workspace Warren is on. Its
+// window's workspace is used, which catches special workspaces shown over
+// the monitor's regular one; if the window cannot be found the active
+// workspace is assumed.
+func (cs *compositorState) currentWorkspace() (string, error) {
+	if workspace, err := cs.wm.WindowWorkspace(os.Getpid()); err == nil && workspace != "" {
+		return workspace, nil
+	}
+	return cs.wm.ActiveWorkspace()
+}
+
+// active reports whether a compositor is connected, for the features
+// that need one rather than just the memory. cs may be nil.
+func (cs *compositorState) active() bool {
+	return cs != nil && cs.wm != nil
+}
+
+// startKey returns the memory key of the directory a new window opens:
+// the active workspace's, or the session's without a compositor.
+func (cs *compositorState) startKey() (string, error) {
+	if cs.wm == nil {
+		return cs.sessionKey, nil
+	}
+	workspace, err := cs.wm.ActiveWorkspace()
+	if err != nil {
+		return "", err
+	}
+	return cs.memoryKey(workspace), nil
+}
+
+// memoryKey returns the workspace memory key for a workspace. With
+// per_monitor_memory set it includes the monitor showing the workspace, so
+// the same workspace on different monitors is remembered separately.
+func (cs *compositorState) memoryKey(workspace string) string {
+	if !cs.cfg.Hyprland.PerMonitorMemory {
+		return workspace
+	}
+
+	monitor, err := cs.wm.MonitorOf(workspace)
+	if err != nil {
+		slog.Warn("Failed to find monitor of workspace", "workspace", workspace, "err", err)
+		return workspace
+	}
+	return compositor.MemoryKey(workspace, monitor)
+}
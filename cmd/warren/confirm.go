@@ -0,0 +1,321 @@
+// Confirmation dialogs for destructive or expensive operations.
+// Which operations ask first is set in the [confirm] config section; each
+// dialog has a "Don't ask again" checkbox that turns its settings off.
+//
+//nolint:staticcheck // gtk.Dialog is deprecated in GTK4 but still functional in our version
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// maxListedConflicts caps how many overwritten names a dialog lists.
+const maxListedConflicts = 5
+
+// confirmKind identifies a setting in the [confirm] config section.
+type confirmKind int
+
+const (
+	confirmOverwrite confirmKind = iota
+	confirmCrossFilesystem
+	confirmLargeOperation
+	confirmSymlinks
+	// confirmNoSpace has no setting: a transfer that doesn't fit always asks
+	confirmNoSpace
+	// confirmCaseConflict has no setting either: names that clash only on a
+	// case-insensitive filesystem always ask
+	confirmCaseConflict
+	// confirmFixNames always asks too, offering to paste under names the
+	// destination can store
+	confirmFixNames
+)
+
+// confirmation is one reason an operation needs the user's approval.
+type confirmation struct {
+	kind    confirmKind
+	message string
+}
+
+// transferConfirmations returns the reasons a paste described by info
+// needs confirmation under the given settings, or nil if it can go ahead.
+func transferConfirmations(c config.ConfirmConfig, info fileops.TransferInfo) []confirmation {
+	var reasons []confirmation
+
+	if info.OutOfSpace() {
+		reasons = append(reasons, confirmation{
+			kind:    confirmNoSpace,
+			message: fmt.Sprintf("Not enough space: this needs %s, but only %s is free at the destination. The transfer will fail partway through.", fileops.FormatSize(info.NeededBytes), fileops.FormatSize(info.FreeBytes)),
+		})
+	}
+
+	if c.Overwrite && len(info.Conflicts) > 0 {
+		names := make([]string, 0, maxListedConflicts)
+		for i, path := range info.Conflicts {
+			if i == maxListedConflicts {
+				names = append(names, fmt.Sprintf("…and %d more", len(info.Conflicts)-i))
+				break
+			}
+			names = append(names, fileops.DisplayName(filepath.Base(path)))
+		}
+		reasons = append(reasons, confirmation{
+			kind:    confirmOverwrite,
+			message: fmt.Sprintf("Replace %d existing item(s)?\n%s", len(info.Conflicts), strings.Join(names, "\n")),
+		})
+	}
+
+	if len(info.CaseConflicts) > 0 {
+		clashes := make([]string, 0, maxListedConflicts)
+		for i, conflict := range info.CaseConflicts {
+			if i == maxListedConflicts {
+				clashes = append(clashes, fmt.Sprintf("…and %d more", len(info.CaseConflicts)-i))
+				break
+			}
+			clashes = append(clashes, fmt.Sprintf("%s with %s",
+				fileops.DisplayName(conflict.Name), fileops.DisplayName(conflict.Taken)))
+		}
+		reasons = append(reasons, confirmation{
+			kind:    confirmCaseConflict,
+			message: fmt.Sprintf("The destination doesn't tell upper and lower case apart. %d name(s) would replace another:\n%s", len(info.CaseConflicts), strings.Join(clashes, "\n")),
+		})
+	}
+
+	if len(info.NameFixes) > 0 {
+		renames := make([]string, 0, maxListedConflicts)
+		for i, fix := range info.NameFixes {
+			if i == maxListedConflicts {
+				renames = append(renames, fmt.Sprintf("…and %d more", len(info.NameFixes)-i))
+				break
+			}
+			renames = append(renames, fmt.Sprintf("%s → %s",
+				fileops.DisplayName(filepath.Base(fix.Path)), fileops.DisplayName(fix.Name)))
+		}
+		reasons = append(reasons, confirmation{
+			kind:    confirmFixNames,
+			message: fmt.Sprintf("The destination can't store %d name(s). Paste them renamed:\n%s", len(info.NameFixes), strings.Join(renames, "\n")),
+		})
+	}
+
+	if c.CrossFilesystem && info.CrossFilesystem {
+		reasons = append(reasons, confirmation{
+			kind:    confirmCrossFilesystem,
+			message: "The destination is on another filesystem: files will be copied, then the originals deleted.",
+		})
+	}
+
+	if limit := c.LargeOperationBytes(); limit > 0 && info.TotalBytes > limit {
+		reasons = append(reasons, confirmation{
+			kind:    confirmLargeOperation,
+			message: fmt.Sprintf("This will transfer %s.", fileops.FormatSize(info.TotalBytes)),
+		})
+	}
+
+	if c.Symlinks && info.Symlinks > 0 {
+		reasons = append(reasons, confirmation{
+			kind:    confirmSymlinks,
+			message: fmt.Sprintf("This holds %d symbolic link(s), copied as chosen below.", info.Symlinks),
+		})
+	}
+
+	return reasons
+}
+
+// deleteGuards returns why a delete described by info needs "yes" typed
+// before it goes ahead, or nil if the ordinary confirmation will do.
+func deleteGuards(c config.ConfirmConfig, info fileops.DeleteInfo) []string {
+	var reasons []string
+	if limit := c.LargeDeleteBytes(); limit > 0 && info.TotalBytes > limit {
+		reasons = append(reasons, fmt.Sprintf("This is more than %s.", fileops.FormatSize(limit)))
+	}
+	if c.LargeDeleteItems > 0 && info.Items > c.LargeDeleteItems {
+		reasons = append(reasons, fmt.Sprintf("This is more than %s.", fileops.FormatItemCount(c.LargeDeleteItems)))
+	}
+	if c.DeleteOutsideHome && info.OutsideHome {
+		reasons = append(reasons, "This is outside your home directory.")
+	}
+	return reasons
+}
+
+// canDisable reports whether any of reasons has a setting to turn off, so
+// the dialog should offer "Don't ask again".
+func canDisable(reasons []confirmation) bool {
+	return slices.ContainsFunc(reasons, func(r confirmation) bool {
+		return r.kind != confirmNoSpace && r.kind != confirmCaseConflict && r.kind != confirmFixNames
+	})
+}
+
+// disableConfirmations turns off the settings behind reasons.
+func disableConfirmations(c *config.ConfirmConfig, reasons []confirmation) {
+	for _, r := range reasons {
+		switch r.kind {
+		case confirmOverwrite:
+			c.Overwrite = false
+		case confirmCrossFilesystem:
+			c.CrossFilesystem = false
+		case confirmLargeOperation:
+			c.LargeOperation = 0
+		case confirmSymlinks:
+			c.Symlinks = false
+		}
+	}
+}
+
+// saveConfirmSettings persists a "Don't ask again" choice.
+func saveConfirmSettings(cfg *config.Config, statusBar *ui.StatusBar) {
+	if err := saveConfig(cfg); err != nil {
+		slog.Error("Failed to save config", "err", err)
+		statusBar.Error(fmt.Sprintf("Failed to save settings: %v", err))
+		return
+	}
+	statusBar.Info("Confirmation turned off; re-enable it under [confirm] in config.toml")
+}
+
+// showConfirmDialog asks a yes/no question with a "Don't ask again"
+// checkbox. onConfirm runs only if the user accepts, and is told whether
+// the checkbox was ticked. 'y' and 'n' answer the dialog from the keyboard.
+func showConfirmDialog(window *gtk.ApplicationWindow, title, message, confirmLabel string, onConfirm func(dontAskAgain bool)) {
+	showQuestion(window, title, message, confirmLabel, true, nil, onConfirm)
+}
+
+// showQuestionDialog is showConfirmDialog for questions that have no
+// setting to turn them off, so there is no "Don't ask again" checkbox.
+func showQuestionDialog(window *gtk.ApplicationWindow, title, message, confirmLabel string, onConfirm func()) {
+	showQuestion(window, title, message, confirmLabel, false, nil, func(bool) { onConfirm() })
+}
+
+// showTypedConfirmDialog asks for confirmation that has to be typed:
+// confirmLabel only becomes clickable once "yes" is in the entry, and
+// Return in the entry confirms then too. It has no "Don't ask again"; the
+// settings that call for it are in the [confirm] config.
+func showTypedConfirmDialog(window *gtk.ApplicationWindow, title, message, confirmLabel string, onConfirm func()) {
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(title)
+	dialog.SetTransientFor(&window.Window)
+	dialog.SetModal(true)
+
+	label := gtk.NewLabel(message + "\n\nType yes to confirm")
+	ui.SetAccessibleDescription(dialog, message)
+	label.SetMarginTop(12)
+	label.SetMarginBottom(12)
+	label.SetMarginStart(12)
+	label.SetMarginEnd(12)
+
+	entry := gtk.NewEntry()
+	entry.SetPlaceholderText("yes")
+	ui.SetAccessibleLabel(entry, "Type yes to confirm")
+	entry.SetMarginStart(12)
+	entry.SetMarginEnd(12)
+	entry.SetMarginBottom(12)
+
+	box := dialog.ContentArea()
+	box.Append(label)
+	box.Append(entry)
+
+	dialog.AddButton("Cancel", int(gtk.ResponseCancel))
+	confirm := dialog.AddButton(confirmLabel, int(gtk.ResponseOK))
+	gtk.BaseWidget(confirm).AddCSSClass("destructive-action")
+	dialog.SetResponseSensitive(int(gtk.ResponseOK), false)
+	dialog.SetDefaultResponse(int(gtk.ResponseCancel))
+
+	typed := func() bool { return strings.EqualFold(strings.TrimSpace(entry.Text()), "yes") }
+	entry.ConnectChanged(func() {
+		dialog.SetResponseSensitive(int(gtk.ResponseOK), typed())
+	})
+	entry.ConnectActivate(func() {
+		if typed() {
+			dialog.Response(int(gtk.ResponseOK))
+		}
+	})
+
+	keyController := gtk.NewEventControllerKey()
+	keyController.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			dialog.Response(int(gtk.ResponseCancel))
+			return true
+		}
+		return false
+	})
+	dialog.AddController(keyController)
+
+	dialog.ConnectResponse(func(responseID int) {
+		// The check guards the response from anything but the button too
+		ok := responseID == int(gtk.ResponseOK) && typed()
+		dialog.Destroy()
+		if ok {
+			onConfirm()
+		}
+	})
+
+	dialog.Show()
+	entry.GrabFocus()
+}
+
+// showQuestion builds the dialogs of showConfirmDialog and
+// showQuestionDialog. extra, if not nil, goes below the message, for
+// choices the question comes with.
+func showQuestion(window *gtk.ApplicationWindow, title, message, confirmLabel string, offerDontAsk bool, extra gtk.Widgetter, onConfirm func(dontAskAgain bool)) {
+	dialog := gtk.NewDialog()
+	dialog.SetTitle(title)
+	dialog.SetTransientFor(&window.Window)
+	dialog.SetModal(true)
+
+	label := gtk.NewLabel(message + "\n\nPress 'y' to confirm or 'n' to cancel")
+	ui.SetAccessibleDescription(dialog, message)
+	label.SetMarginTop(12)
+	label.SetMarginBottom(12)
+	label.SetMarginStart(12)
+	label.SetMarginEnd(12)
+
+	dontAsk := gtk.NewCheckButtonWithLabel("Don't ask again")
+	dontAsk.SetMarginStart(12)
+	dontAsk.SetMarginEnd(12)
+	dontAsk.SetMarginBottom(12)
+	dontAsk.SetVisible(offerDontAsk)
+
+	box := dialog.ContentArea()
+	box.Append(label)
+	if extra != nil {
+		gtk.BaseWidget(extra).SetMarginStart(12)
+		gtk.BaseWidget(extra).SetMarginEnd(12)
+		gtk.BaseWidget(extra).SetMarginBottom(12)
+		box.Append(extra)
+	}
+	box.Append(dontAsk)
+
+	dialog.AddButton("Cancel (n)", int(gtk.ResponseCancel))
+	dialog.AddButton(confirmLabel+" (y)", int(gtk.ResponseOK))
+	dialog.SetDefaultResponse(int(gtk.ResponseCancel))
+
+	keyController := gtk.NewEventControllerKey()
+	keyController.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		switch keyval {
+		case gdk.KEY_y, gdk.KEY_Y:
+			dialog.Response(int(gtk.ResponseOK))
+			return true
+		case gdk.KEY_n, gdk.KEY_N, gdk.KEY_Escape:
+			dialog.Response(int(gtk.ResponseCancel))
+			return true
+		}
+		return false
+	})
+	dialog.AddController(keyController)
+
+	dialog.ConnectResponse(func(responseID int) {
+		checked := dontAsk.Active()
+		dialog.Destroy()
+		if responseID == int(gtk.ResponseOK) {
+			onConfirm(checked)
+		}
+	})
+
+	dialog.Show()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+func TestTransferConfirmations(t *testing.T) {
+	all := config.ConfirmConfig{Overwrite: true, CrossFilesystem: true, LargeOperation: 1}
+	big := fileops.TransferInfo{TotalBytes: 2 << 30}
+
+	tests := []struct {
+		name    string
+		confirm config.ConfirmConfig
+		info    fileops.TransferInfo
+		want    []confirmKind
+	}{
+		{"nothing to confirm", all, fileops.TransferInfo{TotalBytes: 10}, nil},
+		{"overwrite", all, fileops.TransferInfo{Conflicts: []string{"/a/b"}}, []confirmKind{confirmOverwrite}},
+		{"overwrite disabled", config.ConfirmConfig{}, fileops.TransferInfo{Conflicts: []string{"/a/b"}}, nil},
+		{"cross filesystem", all, fileops.TransferInfo{CrossFilesystem: true}, []confirmKind{confirmCrossFilesystem}},
+		{"large", all, big, []confirmKind{confirmLargeOperation}},
+		{"large disabled", config.ConfirmConfig{LargeOperation: 0}, big, nil},
+		{"symlinks", config.ConfirmConfig{Symlinks: true}, fileops.TransferInfo{Symlinks: 2}, []confirmKind{confirmSymlinks}},
+		{"symlinks disabled", all, fileops.TransferInfo{Symlinks: 2}, nil},
+		{"out of space", config.ConfirmConfig{}, fileops.TransferInfo{NeededBytes: 2, FreeBytes: 1}, []confirmKind{confirmNoSpace}},
+		{"fits", all, fileops.TransferInfo{NeededBytes: 1, FreeBytes: 1}, nil},
+		{"case conflict", config.ConfirmConfig{}, fileops.TransferInfo{CaseConflicts: []fileops.CaseConflict{{Name: "A", Taken: "a"}}}, []confirmKind{confirmCaseConflict}},
+		{"name fixes", config.ConfirmConfig{}, fileops.TransferInfo{NameFixes: []fileops.NameFix{{Path: "/a:b", Name: "a_b"}}}, []confirmKind{confirmFixNames}},
+		{"several", all, fileops.TransferInfo{Conflicts: []string{"/a/b"}, CrossFilesystem: true, TotalBytes: 2 << 30},
+			[]confirmKind{confirmOverwrite, confirmCrossFilesystem, confirmLargeOperation}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := transferConfirmations(tt.confirm, tt.info)
+			if len(got) != len(tt.want) {
+				t.Fatalf("transferConfirmations() = %v, want kinds %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i].kind != tt.want[i] {
+					t.Errorf("reason %d kind = %v, want %v", i, got[i].kind, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDisableConfirmations(t *testing.T) {
+	c := config.ConfirmConfig{Delete: true, Overwrite: true, CrossFilesystem: true, LargeOperation: 10, Symlinks: true}
+	disableConfirmations(&c, []confirmation{{kind: confirmOverwrite}, {kind: confirmLargeOperation}, {kind: confirmSymlinks}})
+
+	want := config.ConfirmConfig{Delete: true, Overwrite: false, CrossFilesystem: true, LargeOperation: 0}
+	if c != want {
+		t.Errorf("disableConfirmations() = %+v, want %+v", c, want)
+	}
+}
+
+func TestCanDisable(t *testing.T) {
+	if canDisable([]confirmation{{kind: confirmNoSpace}}) {
+		t.Error("canDisable() = true for out of space only, want false")
+	}
+	if canDisable([]confirmation{{kind: confirmCaseConflict}}) {
+		t.Error("canDisable() = true for a case conflict only, want false")
+	}
+	if !canDisable([]confirmation{{kind: confirmNoSpace}, {kind: confirmOverwrite}}) {
+		t.Error("canDisable() = false with an overwrite, want true")
+	}
+}
+
+func TestDeleteGuards(t *testing.T) {
+	guarded := config.ConfirmConfig{LargeDelete: 1, LargeDeleteItems: 100, DeleteOutsideHome: true}
+
+	tests := []struct {
+		name    string
+		confirm config.ConfirmConfig
+		info    fileops.DeleteInfo
+		want    int
+	}{
+		{"small, at home", guarded, fileops.DeleteInfo{Items: 3, TotalBytes: 10}, 0},
+		{"large", guarded, fileops.DeleteInfo{Items: 1, TotalBytes: 2 << 30}, 1},
+		{"many items", guarded, fileops.DeleteInfo{Items: 101}, 1},
+		{"outside home", guarded, fileops.DeleteInfo{Items: 1, OutsideHome: true}, 1},
+		{"all three", guarded, fileops.DeleteInfo{Items: 101, TotalBytes: 2 << 30, OutsideHome: true}, 3},
+		{"disabled", config.ConfirmConfig{}, fileops.DeleteInfo{Items: 101, TotalBytes: 2 << 30, OutsideHome: true}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := deleteGuards(tt.confirm, tt.info); len(got) != tt.want {
+			t.Errorf("%s: deleteGuards() = %q, want %d reason(s)", tt.name, got, tt.want)
+		}
+	}
+}
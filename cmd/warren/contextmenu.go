@@ -0,0 +1,85 @@
+// The file context menu, built from the action registry.
+package main
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/lawrab/warren/internal/keymap"
+)
+
+// contextMenuSections lists the actions of the context menu, in sections
+// separated by lines. Labels and enabled states come from the registry.
+var contextMenuSections = [][]string{
+	{actionEnterDir, actionOpenOnWorkspace, actionEdit, actionRun, actionDefaultApp, actionQuickLook},
+	{actionYank, actionCut, actionPaste, actionPasteTo, actionHardLink, actionRegisters},
+	{actionRename, actionDelete, actionShred},
+	{actionProperties},
+	{actionToggleHidden, actionToggleTree, actionTogglePreview, actionToggleDualPane},
+}
+
+// setupContextMenu adds the context menu's actions to the window as
+// "win.<name>" actions running the registry's handlers, and opens the menu
+// on a secondary click in either pane, which focuses that pane.
+func setupContextMenu(s *actionState) {
+	s.menuActions = make(map[string]*gio.SimpleAction)
+	for _, section := range contextMenuSections {
+		for _, name := range section {
+			simple := gio.NewSimpleAction(name, nil)
+			simple.ConnectActivate(func(*glib.Variant) {
+				runAction(s, name, count{n: 1})
+			})
+			s.window.AddAction(simple)
+			s.menuActions[name] = simple
+		}
+	}
+
+	for _, view := range s.views.views {
+		view.ConnectContextMenu(func(x, y float64) {
+			if s.active() != view && s.views.switchFocus() {
+				s.showFocusedPane()
+			}
+			view.PopupMenuAt(contextMenu(s), x, y)
+		})
+	}
+}
+
+// showContextMenu opens the context menu of the focused pane from the
+// keyboard.
+func showContextMenu(s *actionState) {
+	s.active().PopupMenu(contextMenu(s))
+}
+
+// contextMenu builds the context menu for the current state: actions that
+// can't run now are greyed out, and single-key bindings are shown beside
+// their items.
+func contextMenu(s *actionState) *gio.Menu {
+	accels := menuAccels(s.km)
+	menu := gio.NewMenu()
+	for _, names := range contextMenuSections {
+		section := gio.NewMenu()
+		for _, name := range names {
+			a := findAction(name)
+			s.menuActions[name].SetEnabled(a.enabled == nil || a.enabled(s))
+
+			item := gio.NewMenuItem(a.label, "win."+name)
+			if accel, ok := accels[name]; ok {
+				item.SetAttributeValue("accel", glib.NewVariantString(accel))
+			}
+			section.AppendItem(item)
+		}
+		menu.AppendSection("", section)
+	}
+	return menu
+}
+
+// menuAccels returns the first single-key binding of each action in km as
+// a GTK accelerator. Menus can't show chords.
+func menuAccels(km *keymap.Keymap) map[string]string {
+	accels := make(map[string]string)
+	for _, b := range km.Bindings() {
+		if _, ok := accels[b.Action]; !ok && len(b.Keys) == 1 {
+			accels[b.Action] = b.Keys.Accelerator()
+		}
+	}
+	return accels
+}
@@ -0,0 +1,77 @@
+// Control socket wiring.
+// This file connects the internal/ipc server to the active Warren window,
+// running every command on the GTK main thread.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ipc"
+)
+
+// startControlSocket starts the scripting socket and exports its path as
+// WARREN_SOCKET for processes launched from Warren. Returns nil if the
+// socket could not be created.
+func startControlSocket(app *gtk.Application) *ipc.Server {
+	server, err := ipc.Listen(ipc.SocketPath(), func(cmd ipc.Command) (string, error) {
+		type result struct {
+			out string
+			err error
+		}
+
+		// Commands touch GTK widgets, so hop onto the main thread and wait
+		done := make(chan result, 1)
+		glib.IdleAdd(func() {
+			out, err := runControlCommand(app, cmd)
+			done <- result{out, err}
+		})
+		r := <-done
+		return r.out, r.err
+	})
+	if err != nil {
+		slog.Warn("Control socket disabled", "err", err)
+		return nil
+	}
+
+	if err := os.Setenv("WARREN_SOCKET", server.Path()); err != nil {
+		slog.Warn("Failed to export WARREN_SOCKET", "err", err)
+	}
+	slog.Info("Control socket listening", "path", server.Path())
+	return server
+}
+
+// runControlCommand executes a control command against the active window.
+func runControlCommand(app *gtk.Application, cmd ipc.Command) (string, error) {
+	w := activeWindow(app)
+	if w == nil {
+		return "", fmt.Errorf("no Warren window is open")
+	}
+
+	switch cmd.Name {
+	case ipc.CmdCd:
+		dir, selectPath, err := fileops.ResolveTarget(cmd.Arg)
+		if err != nil {
+			return "", err
+		}
+		return "", w.navigate(dir, selectPath)
+
+	case ipc.CmdSelect:
+		return "", w.selectEntry(cmd.Arg)
+
+	case ipc.CmdGetSelection:
+		return w.fileView.GetSelectedPath(), nil
+
+	case ipc.CmdPwd:
+		return w.fileView.GetCurrentPath(), nil
+
+	case ipc.CmdReload:
+		return "", w.navigate(w.fileView.GetCurrentPath(), w.fileView.GetSelectedPath())
+	}
+
+	return "", fmt.Errorf("unsupported command %q", cmd.Name)
+}
@@ -0,0 +1,154 @@
+// The window side of the interaction layer: actionState is the
+// controller.Host of a window's controller, which handles keyboard modes,
+// navigation and editing (see internal/controller).
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/bookmarks"
+	"github.com/lawrab/warren/internal/controller"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+var (
+	_ controller.Host     = (*actionState)(nil)
+	_ controller.FileView = (*ui.FileView)(nil)
+	_ controller.Status   = (*ui.StatusBar)(nil)
+)
+
+// setupController creates the window's controller. Text typed through an
+// input method arrives as commits rather than key presses while a line is
+// edited, and goes to the controller too.
+func setupController(s *actionState) {
+	s.ctl = controller.New(s, s.statusBar, yankRegisters)
+
+	s.im = gtk.NewIMMulticontext()
+	s.im.SetClientWidget(s.window)
+	s.im.ConnectCommit(s.ctl.Insert)
+}
+
+// Active implements controller.Host.
+func (s *actionState) Active() controller.FileView {
+	return s.views.active()
+}
+
+// Run implements controller.Host. Besides actions, name may be an init.lua
+// command, with or without scriptActionPrefix.
+func (s *actionState) Run(name string, n int, given bool) bool {
+	if findAction(name) == nil && scriptEngine != nil && slices.Contains(scriptEngine.Commands(), name) {
+		name = scriptActionPrefix + name
+	}
+	return runAction(s, name, count{n: n, given: given})
+}
+
+// Commands implements controller.Host: registered actions and init.lua
+// commands.
+func (s *actionState) Commands() []string {
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		names = append(names, a.name)
+	}
+	if scriptEngine != nil {
+		names = append(names, scriptEngine.Commands()...)
+	}
+	return names
+}
+
+// SelectionChanged implements controller.Host.
+func (s *actionState) SelectionChanged() {
+	updateStatusBar(s.statusBar, s.active())
+}
+
+// SortChanged implements controller.Host.
+func (s *actionState) SortChanged() {
+	showSortMode(s.sortLabel, s.active())
+}
+
+// Open implements controller.Host: directories load in the focused pane,
+// files open on the configured workspace. A link to a directory loads
+// through the link, or at what it resolves to with physical_paths.
+func (s *actionState) Open(file *models.FileInfo) {
+	fileView := s.active()
+	done := func(err error) {
+		directoryLoaded(s.views, fileView, err, s.pathLabel, s.statusBar, s.wmState)
+	}
+	if file.IsSymlink {
+		if target, ok := fileops.LinkedDirectory(file.Path); ok {
+			path := file.Path
+			if s.cfg.General.PhysicalPaths {
+				path = target
+			}
+			fileView.LoadDirectoryAsync(path, done)
+			return
+		}
+	}
+	if !file.IsDir {
+		openSelected(s.cfg, s.wmState, file, s.cfg.Hyprland.OpenOnWorkspace, s.statusBar)
+		return
+	}
+	fileView.NavigateInto(done)
+}
+
+// Leave implements controller.Host.
+func (s *actionState) Leave() {
+	fileView := s.active()
+	fileView.NavigateUp(func(err error) {
+		directoryLoaded(s.views, fileView, err, s.pathLabel, s.statusBar, s.wmState)
+	})
+}
+
+// Rename implements controller.Host.
+func (s *actionState) Rename(file *models.FileInfo, newPath string) {
+	fileView := s.active()
+	fileops.Rename(file.Path, newPath, func(op *fileops.Operation) {
+		// Update UI on GTK thread
+		glib.IdleAdd(func() {
+			if op.Status != fileops.StatusCompleted {
+				s.statusBar.Error(fmt.Sprintf("Failed to rename: %v", op.Error))
+				return
+			}
+			s.statusBar.Info(fmt.Sprintf("Renamed to: %s", filepath.Base(newPath)))
+			_ = fileView.LoadDirectory(fileView.GetCurrentPath())
+			fileView.SelectPath(newPath)
+			s.pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+			updateStatusBar(s.statusBar, fileView)
+			saveCurrentDirectoryToWorkspace(s.wmState, fileView.GetCurrentPath())
+		})
+	})
+}
+
+// Editing implements controller.Host: the input method context is
+// attached to the key controller while a line is edited.
+func (s *actionState) Editing(active bool) {
+	if active {
+		s.keys.SetIMContext(s.im)
+		s.im.FocusIn()
+		return
+	}
+	s.im.FocusOut()
+	s.im.Reset()
+	s.keys.SetIMContext(nil)
+}
+
+// PasteTo implements controller.Host.
+func (s *actionState) PasteTo(register rune, dir string) {
+	s.paste(register, dir)
+}
+
+// Bookmarks implements controller.Host: the GTK bookmarks, read afresh
+// so ones added in a file chooser meanwhile are offered.
+func (s *actionState) Bookmarks() []bookmarks.Bookmark {
+	marks, err := bookmarks.Load()
+	if err != nil {
+		slog.Warn("Failed to read bookmarks", "err", err)
+	}
+	return marks
+}
@@ -0,0 +1,127 @@
+// D-Bus integration.
+// This file exports the org.freedesktop.FileManager1 interface so other
+// applications (browsers' "Show in folder", portals) can open Warren at a
+// location with the relevant item selected.
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+const (
+	fileManager1Name = "org.freedesktop.FileManager1"
+	fileManager1Path = "/org/freedesktop/FileManager1"
+
+	// dbusNameFlagDoNotQueue makes RequestName fail instead of queueing
+	// behind another file manager that already owns the name.
+	dbusNameFlagDoNotQueue = 4
+	// dbusRequestNamePrimaryOwner is the RequestName reply on success.
+	dbusRequestNamePrimaryOwner = 1
+)
+
+const fileManager1XML = `
+<node>
+  <interface name="org.freedesktop.FileManager1">
+    <method name="ShowFolders">
+      <arg type="as" name="URIs" direction="in"/>
+      <arg type="s" name="StartupId" direction="in"/>
+    </method>
+    <method name="ShowItems">
+      <arg type="as" name="URIs" direction="in"/>
+      <arg type="s" name="StartupId" direction="in"/>
+    </method>
+    <method name="ShowItemProperties">
+      <arg type="as" name="URIs" direction="in"/>
+      <arg type="s" name="StartupId" direction="in"/>
+    </method>
+  </interface>
+</node>`
+
+// exportFileManager1 registers the FileManager1 object on the application's
+// session bus connection and claims the well-known name. Failures are logged
+// and ignored: another file manager may already own the name.
+func exportFileManager1(app *gtk.Application, cfg *config.Config) {
+	conn := app.DBusConnection()
+	if conn == nil {
+		return
+	}
+
+	nodeInfo, err := gio.NewDBusNodeInfoForXML(fileManager1XML)
+	if err != nil {
+		slog.Error("Failed to parse FileManager1 introspection data", "err", err)
+		return
+	}
+
+	// Arguments we don't need are typed as interface{} so the closure
+	// marshaller doesn't have to convert them
+	methodCall := func(_, _, _, _ interface{}, method string, _ interface{}, invocation *gio.DBusMethodInvocation) {
+		uris := invocation.Parameters().ChildValue(0).Strv()
+		handleFileManager1Call(app, cfg, method, uris)
+		invocation.ReturnValue(nil)
+	}
+
+	// The interface has no properties, but gotk4 requires non-nil closures
+	noProperty := func() {}
+
+	iface := nodeInfo.LookupInterface(fileManager1Name)
+	if _, err := conn.RegisterObject(fileManager1Path, iface, methodCall, noProperty, noProperty); err != nil {
+		slog.Warn("Failed to export FileManager1", "err", err)
+		return
+	}
+
+	reply, err := conn.CallSync(context.Background(),
+		"org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "RequestName",
+		glib.NewVariantTuple([]*glib.Variant{
+			glib.NewVariantString(fileManager1Name),
+			glib.NewVariantUint32(dbusNameFlagDoNotQueue),
+		}),
+		glib.NewVariantType("(u)"), gio.DBusCallFlagsNone, -1)
+	if err != nil {
+		slog.Warn("Failed to request bus name", "name", fileManager1Name, "err", err)
+		return
+	}
+	if reply.ChildValue(0).Uint32() != dbusRequestNamePrimaryOwner {
+		slog.Info("Bus name is owned by another application", "name", fileManager1Name)
+		return
+	}
+
+	slog.Info("Exported on the session bus", "name", fileManager1Name)
+}
+
+// handleFileManager1Call shows the requested URIs. The first location reuses
+// the active window; any further locations open in new windows.
+func handleFileManager1Call(app *gtk.Application, cfg *config.Config, method string, uris []string) {
+	for i, uri := range uris {
+		var dir, selectPath string
+		var err error
+
+		switch method {
+		case "ShowFolders":
+			dir, selectPath, err = fileops.ResolveTarget(uri)
+		case "ShowItems", "ShowItemProperties":
+			dir, selectPath, err = fileops.RevealTarget(uri)
+		default:
+			slog.Warn("Unknown FileManager1 method", "method", method)
+			return
+		}
+
+		if err != nil {
+			slog.Warn("FileManager1 cannot show URI", "method", method, "uri", uri, "err", err)
+			continue
+		}
+
+		showLocation(app, cfg, dir, selectPath, i > 0)
+		if method == "ShowItemProperties" {
+			if w := activeWindow(app); w != nil {
+				w.showProperties()
+			}
+		}
+	}
+}
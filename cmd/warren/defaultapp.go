@@ -0,0 +1,196 @@
+// Viewing and changing the default application for a file's type.
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/lawrab/warren/internal/ui"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// sniffBytes is how much of a file is read to tell its type when its
+// name doesn't.
+const sniffBytes = 4096
+
+// contentType returns the MIME type of file, from its name or, when that
+// is inconclusive, its first bytes.
+func contentType(file *models.FileInfo) string {
+	if file.IsDir {
+		return "inode/directory"
+	}
+	uncertain, guess := gio.ContentTypeGuess(file.Name, nil)
+	if !uncertain {
+		return guess
+	}
+	f, err := os.Open(file.Path) // #nosec G304 -- the selected file
+	if err != nil {
+		return guess
+	}
+	defer func() { _ = f.Close() }()
+	head := make([]byte, sniffBytes)
+	n, err := io.ReadFull(f, head)
+	if n == 0 && err != nil {
+		return guess
+	}
+	_, guess = gio.ContentTypeGuess(file.Name, head[:n])
+	return guess
+}
+
+// typeApps returns the applications to offer for contentType, sorted by
+// name: those registered for it, or with all set every application shown
+// in menus.
+func typeApps(contentType string, all bool) []*gio.AppInfo {
+	var apps []*gio.AppInfo
+	if all {
+		for _, app := range gio.AppInfoGetAll() {
+			if app.ShouldShow() {
+				apps = append(apps, app)
+			}
+		}
+	} else {
+		apps = gio.AppInfoGetAllForType(contentType)
+	}
+	sort.SliceStable(apps, func(i, j int) bool {
+		return strings.ToLower(apps[i].DisplayName()) < strings.ToLower(apps[j].DisplayName())
+	})
+	return apps
+}
+
+// showDefaultAppDialog lists the applications that can open the selected
+// file's type with the current default selected, and makes the chosen one
+// the default for the type (written to mimeapps.list by GIO).
+func showDefaultAppDialog(window *gtk.ApplicationWindow, file *models.FileInfo, statusBar *ui.StatusBar) {
+	mimeType := contentType(file)
+	description := gio.ContentTypeGetDescription(mimeType)
+	defaultID := ""
+	if app := gio.AppInfoGetDefaultForType(mimeType, false); app != nil {
+		defaultID = app.ID()
+	}
+
+	win := gtk.NewWindow()
+	win.SetTitle("Default Application")
+	win.SetTransientFor(&window.Window)
+	win.SetModal(true)
+	win.SetDefaultSize(420, 480)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 6)
+	box.SetMarginTop(12)
+	box.SetMarginBottom(12)
+	box.SetMarginStart(12)
+	box.SetMarginEnd(12)
+
+	header := gtk.NewLabel(fmt.Sprintf("Open %s (%s) with:", description, mimeType))
+	header.SetXAlign(0)
+	header.SetWrap(true)
+	box.Append(header)
+
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetVExpand(true)
+	scrolled.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	box.Append(scrolled)
+
+	showAll := gtk.NewCheckButtonWithLabel("Show all applications")
+	box.Append(showAll)
+
+	cancel := gtk.NewButtonWithLabel("Cancel")
+	cancel.ConnectClicked(win.Close)
+	setDefault := gtk.NewButtonWithLabel("Set as Default")
+	setDefault.AddCSSClass("suggested-action")
+
+	footer := gtk.NewBox(gtk.OrientationHorizontal, 6)
+	footer.SetHAlign(gtk.AlignEnd)
+	footer.Append(cancel)
+	footer.Append(setDefault)
+	box.Append(footer)
+
+	var apps []*gio.AppInfo
+	var list *gtk.ListBox
+	isDefault := func(app *gio.AppInfo) bool {
+		return defaultID != "" && app.ID() == defaultID
+	}
+
+	choose := func(app *gio.AppInfo) {
+		win.Close()
+		if isDefault(app) {
+			statusBar.Info(fmt.Sprintf("%s already opens %s", app.DisplayName(), mimeType))
+			return
+		}
+		if err := app.SetAsDefaultForType(mimeType); err != nil {
+			statusBar.Error(fmt.Sprintf("Failed to set default application: %v", err))
+			slog.Warn("Failed to set default application", "type", mimeType, "app", app.ID(), "err", err)
+			return
+		}
+		statusBar.Info(fmt.Sprintf("%s now opens %s", app.DisplayName(), mimeType))
+	}
+
+	fill := func() {
+		apps = typeApps(mimeType, showAll.Active())
+		list = gtk.NewListBox()
+		list.SetSelectionMode(gtk.SelectionBrowse)
+		ui.SetAccessibleLabel(list, "Applications for "+description)
+		placeholder := gtk.NewLabel("No application is registered for this type")
+		placeholder.AddCSSClass("dim-label")
+		list.SetPlaceholder(placeholder)
+
+		for i, app := range apps {
+			row := gtk.NewBox(gtk.OrientationHorizontal, 8)
+			row.SetMarginTop(4)
+			row.SetMarginBottom(4)
+			if icon := app.Icon(); icon != nil {
+				image := gtk.NewImageFromGIcon(icon)
+				image.SetIconSize(gtk.IconSizeLarge)
+				row.Append(image)
+			}
+			name := gtk.NewLabel(app.DisplayName())
+			name.SetXAlign(0)
+			name.SetHExpand(true)
+			name.SetEllipsize(pango.EllipsizeEnd)
+			row.Append(name)
+			if isDefault(app) {
+				current := gtk.NewLabel("Default")
+				current.AddCSSClass("dim-label")
+				row.Append(current)
+			}
+			list.Append(row)
+			if isDefault(app) || i == 0 {
+				list.SelectRow(list.RowAtIndex(i))
+			}
+		}
+		list.ConnectRowActivated(func(row *gtk.ListBoxRow) {
+			choose(apps[row.Index()])
+		})
+		setDefault.SetSensitive(len(apps) > 0)
+		scrolled.SetChild(list)
+	}
+	fill()
+	showAll.ConnectToggled(fill)
+
+	setDefault.ConnectClicked(func() {
+		if row := list.SelectedRow(); row != nil {
+			choose(apps[row.Index()])
+		}
+	})
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			win.Close()
+			return true
+		}
+		return false
+	})
+	win.AddController(keys)
+
+	win.SetChild(box)
+	win.Present()
+	list.GrabFocus()
+}
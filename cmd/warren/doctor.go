@@ -0,0 +1,41 @@
+// The GTK part of "warren --doctor"; internal/doctor checks the rest.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/doctor"
+)
+
+// runDoctor prints the doctor report to stdout and returns the exit
+// status: 1 if it found problems.
+func runDoctor() int {
+	sections := append(doctor.Sections(), gtkSection())
+	problems, err := doctor.Write(os.Stdout, sections)
+	if err != nil || problems > 0 {
+		return 1
+	}
+	return 0
+}
+
+// gtkSection reports the GTK Warren runs with and, when a display can be
+// opened, the theme settings it would pick up.
+func gtkSection() doctor.Section {
+	checks := []doctor.Check{
+		{Name: "Version", Value: fmt.Sprintf("%d.%d.%d", gtk.GetMajorVersion(), gtk.GetMinorVersion(), gtk.GetMicroVersion())},
+		doctor.EnvCheck("GTK_THEME"),
+		doctor.EnvCheck("GDK_BACKEND"),
+	}
+	if !gtk.InitCheck() {
+		checks = append(checks, doctor.Check{Name: "Display", Value: "can't be opened", Problem: true})
+		return doctor.Section{Title: "GTK", Checks: checks}
+	}
+	if settings := gtk.SettingsGetDefault(); settings != nil {
+		for _, property := range []string{"gtk-theme-name", "gtk-icon-theme-name", "gtk-font-name", "gtk-application-prefer-dark-theme"} {
+			checks = append(checks, doctor.Check{Name: property, Value: fmt.Sprint(settings.ObjectProperty(property))})
+		}
+	}
+	return doctor.Section{Title: "GTK", Checks: checks}
+}
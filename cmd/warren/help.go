@@ -0,0 +1,122 @@
+// The keyboard shortcuts window, generated from the action registry and
+// the keymap.
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/keymap"
+)
+
+// helpEntry describes one action in the shortcuts window. Entries with an
+// accel are application shortcuts outside the keymap.
+type helpEntry struct {
+	action string
+	title  string
+	accel  string
+}
+
+// helpGroup is a titled group of entries in the shortcuts window.
+type helpGroup struct {
+	title   string
+	entries []helpEntry
+}
+
+// appShortcuts are application shortcuts outside the keymap, listed after
+// the actions of the Application group.
+var appShortcuts = []helpEntry{
+	{title: "Quit", accel: "<Ctrl>q"},
+	{title: "New window", accel: "<Ctrl>n"},
+}
+
+// helpGroups lists every registered action by group, in display order.
+func helpGroups() []helpGroup {
+	groups := make([]helpGroup, len(actionGroups))
+	for i, title := range actionGroups {
+		groups[i].title = title
+		for _, a := range actions {
+			if a.group == title {
+				groups[i].entries = append(groups[i].entries, helpEntry{action: a.name, title: a.title()})
+			}
+		}
+		if title == groupApplication {
+			groups[i].entries = append(groups[i].entries, appShortcuts...)
+		}
+	}
+	return groups
+}
+
+// shortcutsXML returns GtkBuilder XML for a shortcuts window, with the id
+// "shortcuts", listing the bindings of km: user-configured keys, chords,
+// the fixed arrow keys and init.lua bindings alike. Actions bound to
+// several keys show all of them.
+func shortcutsXML(km *keymap.Keymap) string {
+	accels := make(map[string][]string)
+	var scripts []string
+	for _, b := range km.Bindings() {
+		if _, ok := accels[b.Action]; !ok && strings.HasPrefix(b.Action, scriptActionPrefix) {
+			scripts = append(scripts, b.Action)
+		}
+		accels[b.Action] = append(accels[b.Action], b.Keys.Accelerator())
+	}
+
+	groups := helpGroups()
+	if len(scripts) > 0 {
+		group := helpGroup{title: "Scripts"}
+		for _, action := range scripts {
+			group.entries = append(group.entries, helpEntry{action: action, title: strings.TrimPrefix(action, scriptActionPrefix)})
+		}
+		groups = append(groups, group)
+	}
+
+	var b strings.Builder
+	b.WriteString(`<interface><object class="GtkShortcutsWindow" id="shortcuts"><property name="modal">1</property>`)
+	b.WriteString(`<child><object class="GtkShortcutsSection"><property name="section-name">shortcuts</property><property name="max-height">14</property>`)
+	for _, group := range groups {
+		var shortcuts strings.Builder
+		for _, entry := range group.entries {
+			accel := entry.accel
+			if accel == "" {
+				// Unbound actions are left out
+				accel = strings.Join(accels[entry.action], " ")
+			}
+			if accel == "" {
+				continue
+			}
+			shortcuts.WriteString(`<child><object class="GtkShortcutsShortcut">`)
+			writeProperty(&shortcuts, "title", entry.title)
+			writeProperty(&shortcuts, "accelerator", accel)
+			shortcuts.WriteString(`</object></child>`)
+		}
+		if shortcuts.Len() == 0 {
+			continue
+		}
+		b.WriteString(`<child><object class="GtkShortcutsGroup">`)
+		writeProperty(&b, "title", group.title)
+		b.WriteString(shortcuts.String())
+		b.WriteString(`</object></child>`)
+	}
+	b.WriteString(`</object></child></object></interface>`)
+	return b.String()
+}
+
+// writeProperty writes a GtkBuilder property element with an escaped value.
+func writeProperty(b *strings.Builder, name, value string) {
+	b.WriteString(`<property name="` + name + `">`)
+	_ = xml.EscapeText(b, []byte(value))
+	b.WriteString(`</property>`)
+}
+
+// showShortcutsWindow shows the keyboard shortcuts of km, grouped by
+// category and searchable by typing.
+func showShortcutsWindow(window *gtk.ApplicationWindow, km *keymap.Keymap) {
+	builder := gtk.NewBuilderFromString(shortcutsXML(km))
+	shortcuts, ok := builder.GetObject("shortcuts").Cast().(*gtk.ShortcutsWindow)
+	if !ok {
+		return
+	}
+	shortcuts.SetTransientFor(&window.Window)
+	shortcuts.Present()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lawrab/warren/internal/config"
+)
+
+func TestShortcutsXML(t *testing.T) {
+	cfg := config.Default()
+	cfg.Keybindings.Rename = "" // unbound actions are left out
+	km, errs := newKeymap(cfg)
+	if len(errs) > 0 {
+		t.Fatalf("default keybindings conflict: %v", errs)
+	}
+	if err := km.Bind("<Ctrl>less", scriptActionPrefix+"open <terminal>"); err != nil {
+		t.Fatal(err)
+	}
+
+	text := shortcutsXML(km)
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	for {
+		if _, err := decoder.Token(); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("shortcutsXML() is not valid XML: %v\n%s", err, text)
+		}
+	}
+
+	for _, want := range []string{
+		`<property name="accelerator">j Down</property>`,
+		`<property name="accelerator">g&amp;g Home</property>`,
+		`<property name="accelerator">question</property>`,
+		`<property name="title">Scripts</property>`,
+		`<property name="title">open &lt;terminal&gt;</property>`,
+		`<property name="accelerator">&lt;Ctrl&gt;less</property>`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("shortcutsXML() lacks %s", want)
+		}
+	}
+	if strings.Contains(text, "Rename file") {
+		t.Error("shortcutsXML() lists the unbound rename action")
+	}
+}
@@ -4,15 +4,129 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"time"
 
-	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/lawrab/warren/internal/fileops"
 	"github.com/lawrab/warren/internal/ui"
 )
 
-// updateStatusBar updates the status bar label based on current selection and yank state.
-func updateStatusBar(label *gtk.Label, fileView *ui.FileView) {
+// selectionSize computes the total size of a set of paths in the
+// background, so yanking large directories doesn't block the UI.
+type selectionSize struct {
+	paths  []string
+	bytes  int64
+	done   bool
+	cancel context.CancelFunc
+}
+
+// lookup returns the total size of paths, or -1 if it isn't known yet.
+// A new set of paths cancels any walk still running for the old one and
+// starts another; onDone runs on the GTK main thread when it finishes.
+func (s *selectionSize) lookup(paths []string, onDone func()) int64 {
+	if slices.Equal(paths, s.paths) {
+		if s.done {
+			return s.bytes
+		}
+		return -1
+	}
+
+	s.reset()
+	if len(paths) == 0 {
+		return -1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	walk := slices.Clone(paths)
+	s.paths = walk
+	s.cancel = cancel
+
+	go func() {
+		bytes, err := fileops.SelectionSize(ctx, walk)
+		if err != nil {
+			return
+		}
+		glib.IdleAdd(func() {
+			// The selection may have changed while the walk was finishing
+			if ctx.Err() != nil {
+				return
+			}
+			s.bytes, s.done = bytes, true
+			onDone()
+		})
+	}()
+	return -1
+}
+
+// reset cancels any running walk and forgets the cached size.
+func (s *selectionSize) reset() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	*s = selectionSize{}
+}
+
+// freeSpaceTTL is how long the free space shown in the status bar is kept
+// before it is looked up again.
+const freeSpaceTTL = 5 * time.Second
+
+// freeSpace looks up the filesystem of the current directory in the
+// background, so a hung network mount can't freeze the status bar.
+type freeSpace struct {
+	dir     string
+	info    fileops.FilesystemInfo
+	known   bool
+	checked time.Time
+	pending bool
+}
+
+// lookup returns the filesystem holding dir, and whether it is known yet.
+// A new dir, or a result older than freeSpaceTTL, starts another lookup;
+// onDone runs on the GTK main thread if it brings a change.
+func (f *freeSpace) lookup(dir string, onDone func()) (fileops.FilesystemInfo, bool) {
+	if dir != f.dir {
+		*f = freeSpace{dir: dir}
+	}
+	if f.pending || time.Since(f.checked) < freeSpaceTTL {
+		return f.info, f.known
+	}
+
+	f.pending = true
+	go func() {
+		info, err := fileops.StatFilesystem(dir)
+		glib.IdleAdd(func() {
+			// The directory may have changed while statfs was running
+			if f.dir != dir {
+				return
+			}
+			f.pending, f.checked = false, time.Now()
+			if err != nil || (f.known && info == f.info) {
+				return
+			}
+			f.info, f.known = info, true
+			onDone()
+		})
+	}()
+	return f.info, f.known
+}
+
+// windowForView returns the window with fileView in one of its panes, or nil.
+func windowForView(fileView *ui.FileView) *appWindow {
+	for _, w := range windows {
+		if w.panes.contains(fileView) {
+			return w
+		}
+	}
+	return nil
+}
+
+// updateStatusBar updates the status bar summary based on current
+// selection and yank state, and every segment.
+func updateStatusBar(statusBar *ui.StatusBar, fileView *ui.FileView) {
 	selected := fileView.GetSelected()
 	yanked := fileView.GetYanked()
 
@@ -23,17 +137,89 @@ func updateStatusBar(label *gtk.Label, fileView *ui.FileView) {
 		status = "Ready"
 	}
 
-	// Add yank indicator if files are yanked
-	if len(yanked) > 0 {
-		if len(yanked) == 1 {
-			yankName := filepath.Base(yanked[0])
-			status = fmt.Sprintf("%s  [Yanked: %s]", status, yankName)
+	// Add yank indicator if files are yanked, with the total size once
+	// it has been worked out for more than one. Each window sizes its own
+	// yank; the window is only missing while it is still being built,
+	// before anything can be yanked.
+	var size int64 = -1
+	if w := windowForView(fileView); w != nil {
+		if len(yanked) > 1 {
+			size = w.yankedSize.lookup(yanked, func() {
+				updateStatusBar(statusBar, fileView)
+			})
 		} else {
-			status = fmt.Sprintf("%s  [Yanked: %d files]", status, len(yanked))
+			w.yankedSize.reset()
 		}
 	}
+	if len(yanked) == 1 {
+		yankName := filepath.Base(yanked[0])
+		status = fmt.Sprintf("%s  [Yanked: %s]", status, yankName)
+	} else if len(yanked) > 1 {
+		summary := fileops.FormatSelection(len(yanked), size, fileView.GetSizeFormat())
+		status = fmt.Sprintf("%s  [Yanked: %s]", status, summary)
+	}
+
+	statusBar.SetBusy(len(yanked) > 1 && size < 0)
+	statusBar.SetSummary(status)
+
+	showItemCount(statusBar, fileView)
+	showMarked(statusBar, fileView)
+	showFilter(statusBar, fileView)
+	showFreeSpace(statusBar, fileView)
+}
+
+// showItemCount shows how many entries fileView lists, and how many hidden
+// ones it leaves out.
+func showItemCount(statusBar *ui.StatusBar, fileView *ui.FileView) {
+	statusBar.SetSegment(ui.SegmentItems, fileops.FormatListingCount(fileView.GetFileCount(), fileView.HiddenCount()))
+}
 
-	label.SetText(status)
+// showMarked shows how many entries of fileView are marked and, once it
+// has been worked out in the background, their total size.
+func showMarked(statusBar *ui.StatusBar, fileView *ui.FileView) {
+	marked := fileView.GetMarked()
+	w := windowForView(fileView)
+	if len(marked) == 0 || w == nil {
+		if w != nil {
+			w.markedSize.reset()
+		}
+		statusBar.SetSegment(ui.SegmentMarked, "")
+		return
+	}
+	paths := make([]string, len(marked))
+	for i, file := range marked {
+		paths[i] = file.Path
+	}
+	size := w.markedSize.lookup(paths, func() {
+		showMarked(statusBar, fileView)
+	})
+	statusBar.SetSegment(ui.SegmentMarked, "Marked: "+fileops.FormatSelection(len(marked), size, fileView.GetSizeFormat()))
+}
+
+// showFilter shows the filter fileView lists through, if any.
+func showFilter(statusBar *ui.StatusBar, fileView *ui.FileView) {
+	text := ""
+	if filter := fileView.Filter(); !filter.IsEmpty() {
+		text = "Filter: " + filter.String()
+	}
+	statusBar.SetSegment(ui.SegmentFilter, text)
+}
+
+// showFreeSpace shows the free space on the filesystem of fileView's
+// directory, once it has been looked up in the background.
+func showFreeSpace(statusBar *ui.StatusBar, fileView *ui.FileView) {
+	w := windowForView(fileView)
+	if w == nil {
+		return
+	}
+	fs, known := w.freeSpace.lookup(fileView.GetCurrentPath(), func() {
+		showFreeSpace(statusBar, fileView)
+	})
+	text := ""
+	if known {
+		text = fmt.Sprintf("%s free on %s", fileops.FormatSizeAs(fs.FreeBytes, fileView.GetSizeFormat()), fs.Type)
+	}
+	statusBar.SetSegment(ui.SegmentFreeSpace, text)
 }
 
 // formatSortMode returns a formatted string showing the current sort mode and order.
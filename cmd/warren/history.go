@@ -0,0 +1,262 @@
+// The operation history: recording finished operations, the history
+// window, and undoing them one after another.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/history"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// opHistory holds the operations of every window. GTK thread only.
+var opHistory = history.New("")
+
+// undoing is the ID of the entry being undone, if any, so pressing undo
+// again meanwhile doesn't reverse it twice.
+var undoing string
+
+// setupHistory loads the saved history and records every operation that
+// finishes from now on.
+func setupHistory() {
+	stateDir, err := config.StateDir()
+	if err != nil {
+		slog.Warn("Operation history will not be kept across restarts", "err", err)
+	} else {
+		opHistory = history.New(stateDir)
+		if err := opHistory.Load(); err != nil {
+			slog.Warn("Failed to load operation history", "err", err)
+		}
+	}
+
+	fileops.DefaultQueue().Subscribe(func(ev fileops.Event) {
+		if ev.Type != fileops.EventFinished {
+			return
+		}
+		entry := history.FromOperation(ev.Op)
+		glib.IdleAdd(func() {
+			opHistory.Add(entry)
+			saveHistory()
+		})
+	})
+}
+
+// saveHistory writes the history, logging failures.
+func saveHistory() {
+	if err := opHistory.Save(); err != nil {
+		slog.Warn("Failed to save operation history", "err", err)
+	}
+}
+
+// undoLast reverses the newest operation in the history not undone yet:
+// copies and links go to the trash, moved and renamed files move back and
+// trashed files are restored. Each press goes one operation further back.
+func undoLast(s *actionState) {
+	entry, ok := opHistory.LastUndoable()
+	if !ok {
+		s.statusBar.Info("Nothing to undo")
+		return
+	}
+	if entry.ID == undoing {
+		s.statusBar.Info(fmt.Sprintf("Still undoing: %s", entry.Summary()))
+		return
+	}
+	summary := entry.Summary()
+	rev := entry.Reversal()
+
+	// A copy deleted since needs no undoing
+	var trash []string
+	for _, path := range rev.Trash {
+		if _, err := os.Lstat(path); err == nil {
+			trash = append(trash, path)
+		}
+	}
+	if len(rev.From) == 0 && len(trash) == 0 {
+		opHistory.MarkUndone(entry.ID, "")
+		saveHistory()
+		s.statusBar.Info(fmt.Sprintf("Nothing left to undo: %s", summary))
+		return
+	}
+
+	fileView := s.active()
+	startDir := fileView.GetCurrentPath()
+	finish := func(op *fileops.Operation) {
+		undoing = ""
+		switch op.Status {
+		case fileops.StatusCompleted:
+			if rev.Untrash {
+				for _, trashed := range rev.From {
+					if err := os.Remove(fileops.TrashInfoPath(trashed)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+						slog.Warn("Failed to remove trash info", "path", trashed, "err", err)
+					}
+				}
+			}
+			opHistory.MarkUndone(entry.ID, op.ID)
+			saveHistory()
+			s.statusBar.Info(fmt.Sprintf("Undone: %s", summary))
+			_ = fileView.LoadDirectory(fileView.GetCurrentPath())
+			updateStatusBar(s.statusBar, fileView)
+		case fileops.StatusFailed:
+			s.statusBar.Error(fmt.Sprintf("Failed to undo %s: %v", summary, op.Error))
+		default:
+			return
+		}
+		windowFor(s.window).reportOperation(op, startDir)
+	}
+	callback := func(op *fileops.Operation) {
+		if op.GetStatus() == fileops.StatusRunning {
+			return
+		}
+		glib.IdleAdd(func() { finish(op) })
+	}
+
+	undoing = entry.ID
+	if len(trash) > 0 {
+		fileops.TrashMultiple(trash, callback)
+	} else {
+		fileops.MovePaths(rev.From, rev.To, callback)
+	}
+	s.statusBar.Info(fmt.Sprintf("Undoing: %s", summary))
+}
+
+// historyTimeFormat is how the history window shows when an operation
+// finished.
+const historyTimeFormat = "2006-01-02 15:04"
+
+// showHistory lists finished operations, newest first, with a search
+// entry narrowing them down by type, status or path.
+func showHistory(s *actionState) {
+	win := gtk.NewWindow()
+	win.SetTitle("History")
+	win.SetTransientFor(&s.window.Window)
+	win.SetModal(true)
+	win.SetDefaultSize(720, 480)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 6)
+	box.SetMarginTop(12)
+	box.SetMarginBottom(12)
+	box.SetMarginStart(12)
+	box.SetMarginEnd(12)
+
+	search := gtk.NewSearchEntry()
+	search.SetPlaceholderText("Filter by type, status or path")
+	ui.SetAccessibleLabel(search, "Filter history")
+	box.Append(search)
+
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetVExpand(true)
+	scrolled.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	box.Append(scrolled)
+
+	undoButton := gtk.NewButtonWithLabel("Undo Last")
+	undoButton.SetSensitive(false)
+	if entry, ok := opHistory.LastUndoable(); ok {
+		undoButton.SetSensitive(true)
+		undoButton.SetTooltipText(fileops.DisplayName(entry.Summary()))
+	}
+	undoButton.ConnectClicked(func() {
+		win.Close()
+		undoLast(s)
+	})
+	closeButton := gtk.NewButtonWithLabel("Close")
+	closeButton.ConnectClicked(win.Close)
+
+	footer := gtk.NewBox(gtk.OrientationHorizontal, 6)
+	footer.SetHAlign(gtk.AlignEnd)
+	footer.Append(undoButton)
+	footer.Append(closeButton)
+	box.Append(footer)
+
+	entries := opHistory.Entries()
+	fill := func() {
+		list := gtk.NewListBox()
+		list.SetSelectionMode(gtk.SelectionNone)
+		ui.SetAccessibleLabel(list, "Finished operations")
+		placeholder := gtk.NewLabel("No operations")
+		placeholder.AddCSSClass("dim-label")
+		list.SetPlaceholder(placeholder)
+
+		query := search.Text()
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].Matches(query) {
+				list.Append(historyRow(entries[i]))
+			}
+		}
+		scrolled.SetChild(list)
+	}
+	fill()
+	search.ConnectSearchChanged(fill)
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			win.Close()
+			return true
+		}
+		return false
+	})
+	win.AddController(keys)
+
+	win.SetChild(box)
+	win.Present()
+	search.GrabFocus()
+}
+
+// historyRow shows one entry: when, what, and how it ended. The tooltip
+// lists every path.
+func historyRow(e history.Entry) *gtk.Box {
+	row := gtk.NewBox(gtk.OrientationHorizontal, 12)
+	row.SetMarginTop(4)
+	row.SetMarginBottom(4)
+
+	when := gtk.NewLabel(e.Time.Local().Format(historyTimeFormat))
+	when.AddCSSClass("dim-label")
+	when.AddCSSClass("numeric")
+	row.Append(when)
+
+	what := gtk.NewLabel(fileops.DisplayName(e.Summary()))
+	what.SetXAlign(0)
+	what.SetHExpand(true)
+	what.SetEllipsize(pango.EllipsizeMiddle)
+	row.Append(what)
+
+	status := e.Status
+	switch {
+	case e.Undone:
+		status = "Undone"
+	case e.UndoOf != "":
+		status = "Undo"
+	}
+	result := gtk.NewLabel(status)
+	if e.Status == fileops.StatusFailed.String() {
+		result.AddCSSClass("error")
+	} else {
+		result.AddCSSClass("dim-label")
+	}
+	row.Append(result)
+
+	var tip []string
+	for i, src := range e.Sources {
+		if i < len(e.Results) && e.Results[i] != "" && e.Results[i] != src {
+			tip = append(tip, fmt.Sprintf("%s → %s", fileops.DisplayName(src), fileops.DisplayName(e.Results[i])))
+		} else {
+			tip = append(tip, fileops.DisplayName(src))
+		}
+	}
+	if e.Error != "" {
+		tip = append(tip, fileops.DisplayName(e.Error))
+	}
+	row.SetTooltipText(strings.Join(tip, "\n"))
+	return row
+}
@@ -0,0 +1,63 @@
+// Hook script wiring.
+// This file connects the internal/hooks runner to file view events and to
+// the delete and paste actions.
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/hooks"
+	"github.com/lawrab/warren/internal/ui"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// hookRunner runs scripts from ~/.config/warren/hooks. It stays nil if the
+// config directory can't be determined, which disables hooks.
+var hookRunner *hooks.Runner
+
+// setupHooks locates the hooks directory.
+func setupHooks() {
+	dir, err := config.Dir()
+	if err != nil {
+		slog.Warn("Hooks disabled", "err", err)
+		return
+	}
+	hookRunner = hooks.NewRunner(filepath.Join(dir, "hooks"))
+}
+
+// connectHooks runs on-enter-dir and on-select hooks for a window's file view.
+func connectHooks(fileView *ui.FileView, statusBar *ui.StatusBar) {
+	fileView.ConnectDirectoryChanged(func(path string) {
+		runHookAsync(hooks.EnterDir, hooks.Context{Dir: path, Path: path}, statusBar)
+	})
+	fileView.ConnectSelectionChanged(func(file *models.FileInfo) {
+		runHookAsync(hooks.Select, hooks.Context{
+			Dir:   fileView.GetCurrentPath(),
+			Path:  file.Path,
+			Files: []string{file.Path},
+		}, statusBar)
+	})
+}
+
+// runHookAsync runs a hook in the background so it never blocks the UI.
+// Any status text it prints replaces the status bar message.
+func runHookAsync(event hooks.Event, hctx hooks.Context, statusBar *ui.StatusBar) {
+	if !hookRunner.Has(event) {
+		return
+	}
+
+	go func() {
+		result, err := hookRunner.Run(event, hctx)
+		if err != nil {
+			slog.Warn("Hook failed", "err", err)
+		}
+		if result.Status != "" {
+			glib.IdleAdd(func() {
+				statusBar.Info(result.Status)
+			})
+		}
+	}()
+}
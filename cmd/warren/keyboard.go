@@ -1,14 +1,14 @@
 // Keyboard event handling and shortcuts setup.
 // This file contains keyboard controller setup, key binding matching logic,
 // and application-level shortcuts configuration.
-//
-//nolint:staticcheck // gtk.Dialog is deprecated in GTK4 but still functional in our version
 package main
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/diamondburned/gotk4/pkg/gdk/v4"
@@ -16,443 +16,412 @@ import (
 	"github.com/diamondburned/gotk4/pkg/glib/v2"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
 	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/controller"
 	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/hooks"
+	"github.com/lawrab/warren/internal/keymap"
 	"github.com/lawrab/warren/internal/ui"
 	"github.com/lawrab/warren/pkg/models"
 )
 
-// setupKeyboardHandler creates and configures the keyboard event controller.
-//
-//nolint:gocyclo // Keyboard handler naturally has high complexity due to many shortcuts
-func setupKeyboardHandler(cfg *config.Config, fileView *ui.FileView, pathLabel, statusLabel, sortLabel *gtk.Label, window *gtk.ApplicationWindow, hyprState *hyprlandState) *gtk.EventControllerKey {
-	keyController := gtk.NewEventControllerKey()
-	keyController.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
-		// Convert pressed key to string for comparison
-		keyName := gdk.KeyvalName(keyval)
-
-		// Check custom keybindings from config
-		if keyMatchesConfig(keyval, cfg.Keybindings.NavigateDown) || keyval == gdk.KEY_Down {
-			fileView.SelectNext()
-			updateStatusBar(statusLabel, fileView)
-			return true
-		}
+// newKeymap builds the window keymap from the configured bindings, the
+// fixed arrow/Return/BackSpace keys, and init.lua bindings. Bindings that
+// fail to parse or conflict with an earlier one are skipped and returned.
+func newKeymap(cfg *config.Config) (*keymap.Keymap, []error) {
+	km := keymap.New()
+	var errs []error
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.NavigateUp) || keyval == gdk.KEY_Up {
-			fileView.SelectPrevious()
-			updateStatusBar(statusLabel, fileView)
-			return true
+	for _, b := range cfg.Keybindings.Bindings() {
+		if b.Key == "" {
+			continue
 		}
-
-		if keyMatchesConfig(keyval, cfg.Keybindings.ParentDir) || keyval == gdk.KEY_Left || keyval == gdk.KEY_BackSpace {
-			if err := fileView.NavigateUp(); err != nil {
-				statusLabel.SetText(err.Error())
-			} else {
-				pathLabel.SetText(fileView.GetCurrentPath())
-				updateStatusBar(statusLabel, fileView)
-				// Save new directory to workspace memory
-				saveCurrentDirectoryToWorkspace(hyprState, fileView.GetCurrentPath())
-			}
-			return true
+		if err := km.Bind(b.Key, b.Action); err != nil {
+			errs = append(errs, err)
 		}
+	}
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.EnterDir) || keyval == gdk.KEY_Right || keyval == gdk.KEY_Return {
-			selected := fileView.GetSelected()
-			if selected == nil {
-				return true
-			}
+	// Arrow keys always work unless the user rebound them explicitly
+	for _, b := range []struct{ spec, action string }{
+		{"Down", actionNavigateDown},
+		{"Up", actionNavigateUp},
+		{"Left", actionParentDir},
+		{"BackSpace", actionParentDir},
+		{"Right", actionEnterDir},
+		{"Return", actionEnterDir},
+		{"Home", actionGoTop},
+		{"End", actionGoBottom},
+		{"Page_Down", actionHalfPageDown},
+		{"Page_Up", actionHalfPageUp},
+		{"Menu", actionContextMenu},
+		{"<Shift>F10", actionContextMenu},
+	} {
+		_ = km.Bind(b.spec, b.action)
+	}
 
-			if selected.IsDir {
-				// Navigate into directory
-				if err := fileView.NavigateInto(); err != nil {
-					statusLabel.SetText(err.Error())
-				} else {
-					pathLabel.SetText(fileView.GetCurrentPath())
-					updateStatusBar(statusLabel, fileView)
-					// Save new directory to workspace memory
-					saveCurrentDirectoryToWorkspace(hyprState, fileView.GetCurrentPath())
-				}
-			} else {
-				// Open file with default application
-				if err := fileops.OpenFile(selected.Path); err != nil {
-					statusLabel.SetText(fmt.Sprintf("Failed to open: %v", err))
-					log.Printf("Failed to open file %s: %v", selected.Path, err)
-				} else {
-					statusLabel.SetText(fmt.Sprintf("Opened: %s", selected.Name))
-				}
+	if scriptEngine != nil {
+		for _, b := range scriptEngine.Bindings() {
+			if err := km.Bind(b.Key, scriptActionPrefix+b.Command); err != nil {
+				errs = append(errs, err)
 			}
-			return true
 		}
+	}
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.ToggleHidden) {
-			if err := fileView.ToggleHidden(); err != nil {
-				statusLabel.SetText(err.Error())
-			} else {
-				updateStatusBar(statusLabel, fileView)
-			}
-			return true
-		}
+	return km, errs
+}
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.CycleSortMode) {
-			if err := fileView.CycleSortMode(); err != nil {
-				statusLabel.SetText(err.Error())
-			} else {
-				sortLabel.SetText(formatSortMode(fileView))
-				updateStatusBar(statusLabel, fileView)
-			}
-			return true
-		}
+// setupKeyboardHandler creates and configures the keyboard event controller.
+// Keys go to the window's controller (see controller.go), which handles
+// them in the current keyboard mode; in normal mode they are looked up in the keymap and run actions of the registry
+// (see actions.go) on the focused pane. The returned function rebuilds the
+// bindings from cfg after it changes.
+func setupKeyboardHandler(cfg *config.Config, views *panes, previewPane *ui.PreviewPane, pathLabel *gtk.Label, statusBar *ui.StatusBar, sortLabel *gtk.Label, window *gtk.ApplicationWindow, wmState *compositorState) (*gtk.EventControllerKey, func()) {
+	s := &actionState{
+		cfg:         cfg,
+		views:       views,
+		previewPane: previewPane,
+		pathLabel:   pathLabel,
+		sortLabel:   sortLabel,
+		statusBar:   statusBar,
+		window:      window,
+		wmState:     wmState,
+	}
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.ToggleSortOrder) {
-			if err := fileView.ToggleSortOrder(); err != nil {
-				statusLabel.SetText(err.Error())
-			} else {
-				sortLabel.SetText(formatSortMode(fileView))
-				updateStatusBar(statusLabel, fileView)
-			}
-			return true
-		}
+	// Spacebar preview; stepping through files moves the selection along
+	s.quickLook = ui.NewQuickLook(&window.Window)
+	s.quickLook.ConnectFileShown(func(file *models.FileInfo) {
+		fileView := views.active()
+		fileView.SelectPath(file.Path)
+		updateStatusBar(statusBar, fileView)
+	})
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.Yank) {
-			selected := fileView.GetSelected()
-			if selected != nil {
-				// Toggle yank: if already yanked, unyank it
-				if fileView.IsYanked(selected.Path) {
-					fileView.ClearYanked()
-					statusLabel.SetText(fmt.Sprintf("Unyanked: %s", selected.Name))
-				} else {
-					fileView.YankSelected()
-					statusLabel.SetText(fmt.Sprintf("Yanked: %s", selected.Name))
-				}
-			}
-			return true
+	setupController(s)
+	reloadKeymap := func() {
+		s.quickLook.SetCloseKey(cfg.Keybindings.QuickLook)
+		km, errs := newKeymap(cfg)
+		for _, err := range errs {
+			slog.Warn("Keybinding ignored", "err", err)
 		}
-
-		if keyMatchesConfig(keyval, cfg.Keybindings.Delete) {
-			selected := fileView.GetSelected()
-			if selected != nil {
-				showDeleteDialog(window, fileView, selected, statusLabel, pathLabel, hyprState)
-			}
-			return true
-		}
-
-		if keyMatchesConfig(keyval, cfg.Keybindings.Paste) {
-			yanked := fileView.GetYanked()
-			if len(yanked) > 0 {
-				showPasteDialog(window, fileView, yanked, statusLabel, pathLabel, hyprState)
-			} else {
-				statusLabel.SetText("No files yanked")
-			}
-			return true
+		if len(errs) > 0 {
+			statusBar.Warn(fmt.Sprintf("Keybinding ignored: %v", errs[0]))
 		}
+		s.km = km
+		s.ctl.SetKeymaps(km, controller.VisualKeymap(cfg))
+	}
+	reloadKeymap()
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.Rename) {
-			selected := fileView.GetSelected()
-			if selected != nil {
-				showRenameDialog(window, fileView, selected, statusLabel, pathLabel, hyprState)
-			}
-			return true
-		}
+	setupContextMenu(s)
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.ShowHelp) {
-			showShortcutsWindow(window, cfg)
-			return true
-		}
+	s.keys = gtk.NewEventControllerKey()
+	s.keys.ConnectKeyPressed(func(keyval uint, _ uint, state gdk.ModifierType) bool {
+		return s.ctl.Feed(ui.KeyEvent(keyval, state), time.Now())
+	})
+	return s.keys, reloadKeymap
+}
 
-		if keyMatchesConfig(keyval, cfg.Keybindings.Quit) {
-			window.Close()
-			return true
-		}
+// openSelected opens a file on workspace (see openFile) and reports the
+// outcome in the status bar.
+func openSelected(cfg *config.Config, wmState *compositorState, file *models.FileInfo, workspace string, statusBar *ui.StatusBar) {
+	reused, err := openFile(cfg, wmState, file.Path, workspace)
+	switch {
+	case err != nil:
+		statusBar.Error(fmt.Sprintf("Failed to open: %v", err))
+		slog.Warn("Failed to open file", "path", file.Path, "err", err)
+	case reused:
+		statusBar.Info(fmt.Sprintf("Already open: %s", file.Name))
+	default:
+		statusBar.Info(fmt.Sprintf("Opened: %s", file.Name))
+	}
+}
 
-		_ = keyName // Keep for potential debugging
-		return false
-	})
-	return keyController
+// editSelected opens file in the configured editor inside a terminal, on
+// the workspace Warren is on.
+func editSelected(cfg *config.Config, wmState *compositorState, file *models.FileInfo, statusBar *ui.StatusBar) {
+	argv, err := fileops.TerminalCommand(cfg.General.Terminal, fileops.EditorCommand(cfg.General.Editor, file.Path)...)
+	if err == nil {
+		err = launchHere(wmState, filepath.Dir(file.Path), argv...)
+	}
+	if err != nil {
+		statusBar.Error(fmt.Sprintf("Failed to edit: %v", err))
+		slog.Warn("Failed to start editor", "path", file.Path, "err", err)
+		return
+	}
+	statusBar.Info(fmt.Sprintf("Editing: %s", file.Name))
 }
 
-// setupShortcuts configures application-level keyboard shortcuts.
-func setupShortcuts(app *gtk.Application, window *gtk.ApplicationWindow) {
-	// Quit on Ctrl+Q
+// setupShortcuts configures application-level actions and keyboard shortcuts.
+func setupShortcuts(app *gtk.Application, cfg *config.Config) {
+	// Quit on Ctrl+Q closes every window so close handlers still run
 	quitAction := gio.NewSimpleAction("quit", nil)
 	quitAction.ConnectActivate(func(_ *glib.Variant) {
-		window.Close()
+		for _, win := range app.Windows() {
+			win.Close()
+		}
 	})
 	app.AddAction(quitAction)
 	app.SetAccelsForAction("app.quit", []string{"<Ctrl>Q"})
+
+	// New window on Ctrl+N; also activated remotely by "warren --new-window"
+	newWindowAction := gio.NewSimpleAction("new-window", nil)
+	newWindowAction.ConnectActivate(func(_ *glib.Variant) {
+		activate(app, cfg, "", "")
+	})
+	app.AddAction(newWindowAction)
+	app.SetAccelsForAction("app.new-window", []string{"<Ctrl>N"})
 }
 
-// keyMatchesConfig checks if a pressed keyval matches a configured key binding.
-// The config string can be a simple letter ("j", "k") or a special key name
-// ("period", "Return", "Escape", etc.).
-func keyMatchesConfig(keyval uint, configKey string) bool {
-	if configKey == "" {
-		return false
+// directoryLoaded updates the window after fileView finished loading
+// another directory in the background. The path label and workspace
+// memory follow the focused pane only, which may have changed meanwhile.
+func directoryLoaded(views *panes, fileView *ui.FileView, err error, pathLabel *gtk.Label, statusBar *ui.StatusBar, wmState *compositorState) {
+	if err != nil {
+		statusBar.Error(err.Error())
+		return
 	}
+	if views.active() != fileView {
+		return
+	}
+	pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+	updateStatusBar(statusBar, fileView)
+	// Save new directory to workspace memory
+	directoryVisited(wmState, fileView.GetCurrentPath(), statusBar)
+}
 
-	// Get the key name from the keyval
-	keyName := gdk.KeyvalName(keyval)
-
-	// Direct name match (e.g., "Return", "Escape", "BackSpace")
-	if keyName == configKey {
-		return true
+// showDeleteDialog shows how much deleting a file removes and asks for
+// confirmation, unless delete confirmation is turned off in the config.
+// Deletes past the [confirm] limits, or outside the home directory, ask
+// for "yes" to be typed even then.
+func showDeleteDialog(cfg *config.Config, window *gtk.ApplicationWindow, fileView *ui.FileView, file *models.FileInfo, statusBar *ui.StatusBar, pathLabel *gtk.Label, wmState *compositorState) {
+	c := cfg.Confirm
+	if !c.Delete && c.LargeDeleteBytes() == 0 && c.LargeDeleteItems == 0 && !c.DeleteOutsideHome {
+		deleteFile(window, fileView, file, statusBar, pathLabel, wmState)
+		return
 	}
 
-	// Single character match (e.g., "j" matches 'j')
-	if len(configKey) == 1 {
-		if keyval == uint(configKey[0]) {
-			return true
-		}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		slog.Warn("Failed to get home directory", "err", err)
+	}
+	if file.IsDir {
+		statusBar.Info(fmt.Sprintf("Counting what %s holds…", file.Name))
 	}
 
-	return false
+	// Measuring walks the whole tree, so keep it off the GTK thread
+	go func() {
+		info := fileops.CheckDelete([]string{file.Path}, home)
+		glib.IdleAdd(func() {
+			extent := fileops.FormatSelection(info.Items, info.TotalBytes, fileView.GetSizeFormat())
+			message := fmt.Sprintf("Delete %s?\n\nThis will permanently delete %s:\n%s", fileops.DisplayName(file.Name), extent, fileops.DisplayName(file.Path))
+			del := func() { deleteFile(window, fileView, file, statusBar, pathLabel, wmState) }
+
+			if guards := deleteGuards(cfg.Confirm, info); len(guards) > 0 {
+				showTypedConfirmDialog(window, "Delete File", message+"\n\n"+strings.Join(guards, "\n"), "Delete", del)
+				return
+			}
+			if !cfg.Confirm.Delete {
+				del()
+				return
+			}
+			showConfirmDialog(window, "Delete File", message, "Delete", func(dontAskAgain bool) {
+				if dontAskAgain {
+					cfg.Confirm.Delete = false
+					saveConfirmSettings(cfg, statusBar)
+				}
+				del()
+			})
+		})
+	}()
 }
 
-// showDeleteDialog shows a confirmation dialog before deleting a file.
-func showDeleteDialog(window *gtk.ApplicationWindow, fileView *ui.FileView, file *models.FileInfo, statusLabel, pathLabel *gtk.Label, hyprState *hyprlandState) {
-	dialog := gtk.NewDialog()
-	dialog.SetTitle("Delete File")
-	dialog.SetTransientFor(&window.Window)
-	dialog.SetModal(true)
-
-	// Add message label
-	label := gtk.NewLabel(fmt.Sprintf("Delete %s?\n\nThis will permanently delete:\n%s\n\nPress 'y' to confirm or 'n' to cancel", file.Name, file.Path))
-	label.SetMarginTop(12)
-	label.SetMarginBottom(12)
-	label.SetMarginStart(12)
-	label.SetMarginEnd(12)
-
-	box := dialog.ContentArea()
-	box.Append(label)
-
-	// Add buttons
-	dialog.AddButton("Cancel (n)", int(gtk.ResponseCancel))
-	dialog.AddButton("Delete (y)", int(gtk.ResponseOK))
-	dialog.SetDefaultResponse(int(gtk.ResponseCancel))
-
-	// Add keyboard event controller for y/n shortcuts
-	keyController := gtk.NewEventControllerKey()
-	keyController.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
-		switch keyval {
-		case gdk.KEY_y, gdk.KEY_Y:
-			dialog.Response(int(gtk.ResponseOK))
-			return true
-		case gdk.KEY_n, gdk.KEY_N, gdk.KEY_Escape:
-			dialog.Response(int(gtk.ResponseCancel))
-			return true
+// deleteFile deletes a file in the background after the pre-delete hook.
+func deleteFile(window *gtk.ApplicationWindow, fileView *ui.FileView, file *models.FileInfo, statusBar *ui.StatusBar, pathLabel *gtk.Label, wmState *compositorState) {
+	hctx := hooks.Context{
+		Dir:   fileView.GetCurrentPath(),
+		Path:  file.Path,
+		Files: []string{file.Path},
+	}
+
+	var finish func(op *fileops.Operation)
+	finish = func(op *fileops.Operation) {
+		_, removed, total, _ := op.GetProgress()
+		if op.Status == fileops.StatusCompleted {
+			message := fmt.Sprintf("Deleted: %s", file.Name)
+			if removed > 1 {
+				message += fmt.Sprintf(" (%s)", fileops.FormatItemCount(int(removed)))
+			}
+			statusBar.Info(message)
+			// Reload directory
+			_ = fileView.LoadDirectory(fileView.GetCurrentPath())
+			pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+			updateStatusBar(statusBar, fileView)
+			saveCurrentDirectoryToWorkspace(wmState, fileView.GetCurrentPath())
+		} else if op.Status == fileops.StatusCancelled {
+			statusBar.Warn(fmt.Sprintf("Delete cancelled: %d of %d items removed", removed, total))
+			_ = fileView.LoadDirectory(fileView.GetCurrentPath())
+		} else {
+			message := fmt.Sprintf("Failed to delete: %v", op.Error)
+			if removed > 0 {
+				message += fmt.Sprintf(" (%d of %d items removed)", removed, total)
+			}
+			statusBar.Error(message)
+			offerPrivilegedRetry(window, op, statusBar, finish)
 		}
-		return false
-	})
-	dialog.AddController(keyController)
-
-	dialog.ConnectResponse(func(responseID int) {
-		dialog.Destroy()
-
-		if responseID == int(gtk.ResponseOK) {
-			// Delete the file using our fileops backend
-			op := fileops.Delete(file.Path, nil)
-
-			// Wait for operation to complete
-			go func() {
-				// Simple polling - in production would use channels
-				for {
-					time.Sleep(50 * time.Millisecond)
-					if op.Status != fileops.StatusPending && op.Status != fileops.StatusRunning {
-						break
-					}
-				}
+		windowFor(window).reportOperation(op, hctx.Dir)
+	}
 
-				// Update UI on GTK thread
-				glib.IdleAdd(func() {
-					if op.Status == fileops.StatusCompleted {
-						statusLabel.SetText(fmt.Sprintf("Deleted: %s", file.Name))
-						// Reload directory
-						_ = fileView.LoadDirectory(fileView.GetCurrentPath())
-						pathLabel.SetText(fileView.GetCurrentPath())
-						updateStatusBar(statusLabel, fileView)
-						saveCurrentDirectoryToWorkspace(hyprState, fileView.GetCurrentPath())
-					} else {
-						statusLabel.SetText(fmt.Sprintf("Failed to delete: %v", op.Error))
-					}
-				})
-			}()
+	go func() {
+		// The pre-delete hook can veto the delete
+		result, err := hookRunner.Run(hooks.PreDelete, hctx)
+		if err != nil {
+			slog.Warn("Hook failed", "err", err)
+		}
+		if result.Cancel {
+			glib.IdleAdd(func() {
+				statusBar.Warn(fmt.Sprintf("Delete cancelled: %s", result.Status))
+			})
+			return
 		}
-	})
 
-	dialog.Show()
+		// Delete the file using our fileops backend
+		fileops.Delete(file.Path, func(op *fileops.Operation) {
+			if op.GetStatus() == fileops.StatusRunning {
+				return
+			}
+			// Update UI on GTK thread
+			glib.IdleAdd(func() { finish(op) })
+		})
+	}()
 }
 
-// showPasteDialog executes paste operation with progress feedback.
-func showPasteDialog(_ *gtk.ApplicationWindow, fileView *ui.FileView, yanked []string, statusLabel, pathLabel *gtk.Label, hyprState *hyprlandState) {
-	currentDir := fileView.GetCurrentPath()
-
-	// Start copy operation
-	op := fileops.CopyMultiple(yanked, currentDir, func(operation *fileops.Operation) {
-		// Update UI on GTK thread
+// showPasteDialog pastes yanked files into dir, moving them if cut is set,
+// first asking for confirmation if the paste would overwrite files, move
+// across filesystems, transfer a lot of data or copy symlinks (see the
+// [confirm] config); for symlinks the dialog offers the policies, starting
+// with general.symlinks. Names the destination can't store are always
+// shown first, and pasted as fileops.FixName renames them. pasted, if not
+// nil, runs once the paste is done.
+func showPasteDialog(cfg *config.Config, window *gtk.ApplicationWindow, fileView *ui.FileView, dir string, yanked []string, cut bool, pasted func(), statusBar *ui.StatusBar, pathLabel *gtk.Label, wmState *compositorState) {
+	// Sizing the sources walks them, so keep it off the GTK thread
+	go func() {
+		info, err := fileops.CheckTransfer(yanked, dir, cut)
 		glib.IdleAdd(func() {
-			if operation.Status == fileops.StatusCompleted {
-				statusLabel.SetText(fmt.Sprintf("Pasted %d file(s)", len(yanked)))
-				// Reload directory
-				_ = fileView.LoadDirectory(fileView.GetCurrentPath())
-				pathLabel.SetText(fileView.GetCurrentPath())
-				updateStatusBar(statusLabel, fileView)
-				fileView.ClearYanked()
-				saveCurrentDirectoryToWorkspace(hyprState, fileView.GetCurrentPath())
-			} else if operation.Status == fileops.StatusFailed {
-				statusLabel.SetText(fmt.Sprintf("Failed to paste: %v", operation.Error))
+			if err != nil {
+				statusBar.Error(fmt.Sprintf("Failed to paste: %v", err))
+				return
 			}
-		})
-	})
 
-	// For small files, this completes quickly. For large files, show progress
-	_ = op // Operation runs in background
-}
+			opts := fileops.TransferOptions{Symlinks: cfg.General.SymlinkPolicy()}
+			reasons := transferConfirmations(cfg.Confirm, info)
+			if len(reasons) == 0 {
+				pasteFiles(window, fileView, yanked, cut, opts, pasted, dir, statusBar, pathLabel, wmState)
+				return
+			}
 
-// showRenameDialog shows a dialog to rename a file.
-func showRenameDialog(window *gtk.ApplicationWindow, fileView *ui.FileView, file *models.FileInfo, statusLabel, pathLabel *gtk.Label, hyprState *hyprlandState) {
-	dialog := gtk.NewDialog()
-	dialog.SetTitle("Rename File")
-	dialog.SetTransientFor(&window.Window)
-	dialog.SetModal(true)
-
-	// Add entry for new name
-	entry := gtk.NewEntry()
-	entry.SetText(file.Name)
-	entry.SetActivatesDefault(true)
-
-	box := dialog.ContentArea()
-	box.SetMarginTop(12)
-	box.SetMarginBottom(12)
-	box.SetMarginStart(12)
-	box.SetMarginEnd(12)
-	box.Append(entry)
-
-	// Add buttons
-	dialog.AddButton("Cancel", int(gtk.ResponseCancel))
-	dialog.AddButton("Rename", int(gtk.ResponseOK))
-	dialog.SetDefaultResponse(int(gtk.ResponseOK))
-
-	dialog.ConnectResponse(func(responseID int) {
-		newName := entry.Text()
-		dialog.Destroy()
-
-		if responseID == int(gtk.ResponseOK) && newName != "" && newName != file.Name {
-			newPath := filepath.Join(fileView.GetCurrentPath(), newName)
-			op := fileops.Rename(file.Path, newPath, nil)
-
-			// Wait for operation
-			go func() {
-				for {
-					time.Sleep(50 * time.Millisecond)
-					if op.Status != fileops.StatusPending && op.Status != fileops.StatusRunning {
-						break
-					}
+			messages := make([]string, len(reasons))
+			var policy *gtk.DropDown
+			for i, r := range reasons {
+				messages[i] = r.message
+				if r.kind == confirmFixNames {
+					opts.FixNames = true
 				}
+				if r.kind == confirmSymlinks {
+					policy = gtk.NewDropDownFromStrings(symlinkPolicyLabels)
+					policy.SetSelected(uint(opts.Symlinks))
+					ui.SetAccessibleLabel(policy, "How to copy symbolic links")
+				}
+			}
+			paste := func() {
+				if policy != nil {
+					opts.Symlinks = fileops.SymlinkPolicy(policy.Selected())
+				}
+				pasteFiles(window, fileView, yanked, cut, opts, pasted, dir, statusBar, pathLabel, wmState)
+			}
+			var extra gtk.Widgetter
+			if policy != nil {
+				extra = policy
+			}
+			label := "Paste"
+			switch {
+			case opts.FixNames:
+				label = "Paste Renamed"
+			case !canDisable(reasons):
+				label = "Paste Anyway"
+			}
+			showQuestion(window, "Paste Files", strings.Join(messages, "\n\n"), label, canDisable(reasons), extra, func(dontAskAgain bool) {
+				if dontAskAgain {
+					disableConfirmations(&cfg.Confirm, reasons)
+					saveConfirmSettings(cfg, statusBar)
+				}
+				paste()
+			})
+		})
+	}()
+}
 
-				glib.IdleAdd(func() {
-					if op.Status == fileops.StatusCompleted {
-						statusLabel.SetText(fmt.Sprintf("Renamed to: %s", newName))
-						_ = fileView.LoadDirectory(fileView.GetCurrentPath())
-						pathLabel.SetText(fileView.GetCurrentPath())
-						updateStatusBar(statusLabel, fileView)
-						saveCurrentDirectoryToWorkspace(hyprState, fileView.GetCurrentPath())
-					} else {
-						statusLabel.SetText(fmt.Sprintf("Failed to rename: %v", op.Error))
-					}
-				})
-			}()
-		}
-	})
-
-	dialog.Show()
+// symlinkPolicyLabels describes the fileops.SymlinkPolicy values, in order,
+// in the paste dialog.
+var symlinkPolicyLabels = []string{
+	"Keep links as they are",
+	"Point relative links back at the originals",
+	"Copy what links point to",
 }
 
-// showShortcutsWindow shows a dialog with all keyboard shortcuts.
-func showShortcutsWindow(window *gtk.ApplicationWindow, cfg *config.Config) {
-	dialog := gtk.NewDialog()
-	dialog.SetTitle("Keyboard Shortcuts")
-	dialog.SetTransientFor(&window.Window)
-	dialog.SetModal(true)
-	dialog.SetDefaultSize(500, 600)
-
-	// Create scrolled window for shortcuts
-	scrolled := gtk.NewScrolledWindow()
-	scrolled.SetVExpand(true)
-	scrolled.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
-
-	// Create box to hold all shortcuts
-	box := gtk.NewBox(gtk.OrientationVertical, 12)
-	box.SetMarginTop(12)
-	box.SetMarginBottom(12)
-	box.SetMarginStart(12)
-	box.SetMarginEnd(12)
-
-	// Helper function to add a section
-	addSection := func(title string, shortcuts map[string]string) {
-		// Section header
-		header := gtk.NewLabel(title)
-		header.SetXAlign(0)
-		header.SetMarkup(fmt.Sprintf("<b>%s</b>", title))
-		header.SetMarginTop(6)
-		box.Append(header)
-
-		// Add shortcuts
-		for key, desc := range shortcuts {
-			shortcutBox := gtk.NewBox(gtk.OrientationHorizontal, 12)
-
-			keyLabel := gtk.NewLabel(key)
-			keyLabel.SetXAlign(0)
-			keyLabel.SetWidthChars(15)
-			keyLabel.AddCSSClass("dim-label")
-			shortcutBox.Append(keyLabel)
-
-			descLabel := gtk.NewLabel(desc)
-			descLabel.SetXAlign(0)
-			descLabel.SetHExpand(true)
-			shortcutBox.Append(descLabel)
-
-			box.Append(shortcutBox)
-		}
+// pasteFiles copies (or, for cut files, moves) yanked into dir with
+// progress feedback, then runs pasted if it is not nil. The transfer is
+// adjusted by opts.
+func pasteFiles(window *gtk.ApplicationWindow, fileView *ui.FileView, yanked []string, cut bool, opts fileops.TransferOptions, pasted func(), dir string, statusBar *ui.StatusBar, pathLabel *gtk.Label, wmState *compositorState) {
+	start, verb := fileops.CopyMultipleWith, "Pasted"
+	if cut {
+		start, verb = fileops.MoveMultipleWith, "Moved"
 	}
 
-	// Navigation shortcuts
-	addSection("Navigation", map[string]string{
-		cfg.Keybindings.NavigateUp + "/k":   "Move up",
-		cfg.Keybindings.NavigateDown + "/j": "Move down",
-		cfg.Keybindings.ParentDir + "/h":    "Parent directory",
-		cfg.Keybindings.EnterDir + "/l":     "Enter directory / Open file",
-	})
-
-	// File operations
-	addSection("File Operations", map[string]string{
-		cfg.Keybindings.Yank:   "Yank (copy) file / Unyank if already yanked",
-		cfg.Keybindings.Paste:  "Paste yanked files",
-		cfg.Keybindings.Delete: "Delete file (y/n to confirm)",
-		cfg.Keybindings.Rename: "Rename file",
-	})
-
-	// View options
-	addSection("View", map[string]string{
-		cfg.Keybindings.ToggleHidden:    "Toggle hidden files",
-		cfg.Keybindings.CycleSortMode:   "Cycle sort mode",
-		cfg.Keybindings.ToggleSortOrder: "Toggle sort order",
-	})
-
-	// Application
-	addSection("Application", map[string]string{
-		cfg.Keybindings.ShowHelp: "Show this help",
-		cfg.Keybindings.Quit:     "Quit",
-		"Ctrl+Q":                 "Quit (alternative)",
-	})
-
-	scrolled.SetChild(box)
-	dialog.ContentArea().Append(scrolled)
-
-	// Close button
-	dialog.AddButton("Close", int(gtk.ResponseClose))
-	dialog.SetDefaultResponse(int(gtk.ResponseClose))
+	phase := fileops.PhaseTransfer
+	var finish func(operation *fileops.Operation)
+	finish = func(operation *fileops.Operation) {
+		if operation.Status == fileops.StatusRunning {
+			// Moves across filesystems copy, then remove the originals
+			if p := operation.GetPhase(); p != phase {
+				phase = p
+				statusBar.Info(fmt.Sprintf("Moving %d file(s): %s", len(yanked), p))
+			}
+			return
+		}
+		if operation.Status == fileops.StatusCompleted {
+			message := fmt.Sprintf("%s %d file(s)", verb, len(yanked))
+			if dir != fileView.GetCurrentPath() {
+				message += " to " + dir
+			}
+			statusBar.Info(message)
+			// Reload directory
+			_ = fileView.LoadDirectory(fileView.GetCurrentPath())
+			pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+			updateStatusBar(statusBar, fileView)
+			if pasted != nil {
+				pasted()
+			}
+			saveCurrentDirectoryToWorkspace(wmState, fileView.GetCurrentPath())
+			if !cut {
+				runHookAsync(hooks.PostCopy, hooks.Context{
+					Dir:   dir,
+					Files: yanked,
+					Dest:  dir,
+				}, statusBar)
+			}
+		} else if operation.Status == fileops.StatusFailed {
+			statusBar.Error(fmt.Sprintf("Failed to paste: %v", operation.Error))
+			offerPrivilegedRetry(window, operation, statusBar, finish)
+		} else {
+			return
+		}
+		windowFor(window).reportOperation(operation, dir)
+	}
 
-	dialog.ConnectResponse(func(_ int) {
-		dialog.Destroy()
+	op := start(yanked, dir, opts, func(operation *fileops.Operation) {
+		// Update UI on GTK thread
+		glib.IdleAdd(func() { finish(operation) })
 	})
 
-	dialog.Show()
+	// Operations on the same disk run one at a time
+	if position := fileops.DefaultQueue().Position(op.ID); position > 0 {
+		statusBar.Info(fmt.Sprintf("Paste queued (#%d in line)", position))
+	}
 }
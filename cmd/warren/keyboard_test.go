@@ -2,11 +2,25 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/ui"
 )
 
-func TestKeyMatchesConfig(t *testing.T) {
+// keyMatches reports whether a key press matches a configured binding key.
+func keyMatches(t *testing.T, keyval uint, state gdk.ModifierType, configKey string) bool {
+	t.Helper()
+	key, err := keymap.ParseKey(configKey)
+	if err != nil {
+		return false
+	}
+	return key.Matches(ui.KeyEvent(keyval, state))
+}
+
+func TestKeyEventMatches(t *testing.T) {
 	tests := []struct {
 		name     string
 		keyval   uint
@@ -42,32 +56,104 @@ func TestKeyMatchesConfig(t *testing.T) {
 		{"Up arrow", gdk.KEY_Up, "Up", true},
 		{"Left arrow", gdk.KEY_Left, "Left", true},
 		{"Right arrow", gdk.KEY_Right, "Right", true},
+
+		// GTK names for printable keys
+		{"period name", gdk.KEY_period, "period", true},
+		{"space name", gdk.KEY_space, "space", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := keyMatchesConfig(tt.keyval, tt.config)
+			result := keyMatches(t, tt.keyval, 0, tt.config)
 			if result != tt.expected {
-				t.Errorf("keyMatchesConfig(%d, %q) = %v, want %v",
+				t.Errorf("keyMatches(%d, %q) = %v, want %v",
 					tt.keyval, tt.config, result, tt.expected)
 			}
 		})
 	}
 }
 
-func TestKeyMatchesConfigEdgeCases(t *testing.T) {
+func TestKeyEventModifiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		keyval   uint
+		state    gdk.ModifierType
+		config   string
+		expected bool
+	}{
+		{"ctrl binding", gdk.KEY_d, gdk.ControlMask, "<Ctrl>d", true},
+		{"ctrl binding without ctrl", gdk.KEY_d, 0, "<Ctrl>d", false},
+		{"plain binding ignores ctrl press", gdk.KEY_d, gdk.ControlMask, "d", false},
+		{"shifted letter", gdk.KEY_G, gdk.ShiftMask, "G", true},
+		{"shift binding", gdk.KEY_G, gdk.ShiftMask, "<Shift>g", true},
+		{"shifted symbol", gdk.KEY_question, gdk.ShiftMask, "question", true},
+		{"alt binding", gdk.KEY_x, gdk.AltMask, "<Alt>x", true},
+		{"shift on special key", gdk.KEY_Tab, gdk.ShiftMask, "<Shift>Tab", true},
+		{"unrelated mask ignored", gdk.KEY_j, gdk.Button1Mask, "j", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyMatches(t, tt.keyval, tt.state, tt.config); got != tt.expected {
+				t.Errorf("keyMatches(%d, %v, %q) = %v, want %v",
+					tt.keyval, tt.state, tt.config, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestKeyEventMatchesEdgeCases(t *testing.T) {
 	// Test that multi-character strings only match if they're key names
-	if keyMatchesConfig(uint('a'), "abc") {
+	if keyMatches(t, uint('a'), 0, "abc") {
 		t.Error("Multi-character non-keyname should not match single character")
 	}
 
 	// Test that number keys work
-	if !keyMatchesConfig(uint('0'), "0") {
+	if !keyMatches(t, uint('0'), 0, "0") {
 		t.Error("Number key '0' should match config '0'")
 	}
 
 	// Test space key
-	if !keyMatchesConfig(uint(' '), " ") {
+	if !keyMatches(t, uint(' '), 0, " ") {
 		t.Error("Space key should match single space config")
 	}
 }
+
+func TestNewKeymapDefaults(t *testing.T) {
+	km, errs := newKeymap(config.Default())
+	if len(errs) > 0 {
+		t.Fatalf("default keybindings conflict: %v", errs)
+	}
+
+	now := time.Now()
+	if action, _ := km.Feed(ui.KeyEvent(gdk.KEY_Down, 0), now); action != actionNavigateDown {
+		t.Errorf("Down arrow = %q, want %q", action, actionNavigateDown)
+	}
+	if _, handled := km.Feed(ui.KeyEvent(gdk.KEY_g, 0), now); !handled {
+		t.Error("g should start the go_top chord")
+	}
+	if action, _ := km.Feed(ui.KeyEvent(gdk.KEY_g, 0), now); action != actionGoTop {
+		t.Errorf("g g = %q, want %q", action, actionGoTop)
+	}
+	if _, handled := km.Feed(ui.KeyEvent(gdk.KEY_d, 0), now); !handled {
+		t.Error("d should start the cut chord")
+	}
+	if action, _ := km.Feed(ui.KeyEvent(gdk.KEY_d, 0), now); action != actionCut {
+		t.Errorf("d d = %q, want %q", action, actionCut)
+	}
+	if action, _ := km.Feed(ui.KeyEvent(gdk.KEY_D, gdk.ShiftMask), now); action != actionDelete {
+		t.Errorf("D = %q, want %q", action, actionDelete)
+	}
+}
+
+func TestNewKeymapConflicts(t *testing.T) {
+	cfg := config.Default()
+	cfg.Keybindings.Rename = "D"      // same as delete
+	cfg.Keybindings.GoBottom = "g"    // prefix of go_top's "g g"
+	cfg.Keybindings.ShowHelp = "<Foo" // not a conflict, just a literal key
+
+	_, errs := newKeymap(cfg)
+	if len(errs) != 2 {
+		t.Errorf("expected 2 keybinding errors, got %d: %v", len(errs), errs)
+	}
+}
@@ -0,0 +1,98 @@
+// Log level selection and the log viewer.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/logging"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// logViewerBytes is how much of the end of the log file the viewer shows.
+const logViewerBytes = 256 << 10
+
+// debugLogging is set by --debug, which overrides general.log_level.
+var debugLogging bool
+
+// applyLogLevel sets the log level from cfg, or to debug with --debug.
+func applyLogLevel(cfg *config.Config) {
+	if debugLogging {
+		logging.SetLevel(slog.LevelDebug)
+		return
+	}
+	// An invalid level is reported by config.Validate and logs at info
+	level, _ := logging.ParseLevel(cfg.General.LogLevel)
+	logging.SetLevel(level)
+}
+
+// showLogViewer shows the end of the log file, with a button copying it
+// for a bug report.
+func showLogViewer(window *gtk.ApplicationWindow, statusBar *ui.StatusBar) {
+	path, err := logging.Path()
+	if err != nil {
+		statusBar.Error(fmt.Sprintf("Cannot find the log: %v", err))
+		return
+	}
+	text, err := logging.Tail(path, logViewerBytes)
+	if err != nil {
+		statusBar.Error(fmt.Sprintf("Cannot read the log: %v", err))
+		return
+	}
+	if text == "" {
+		statusBar.Info("The log is empty")
+		return
+	}
+
+	dialog := gtk.NewDialog()
+	dialog.SetTitle("Log")
+	dialog.SetTransientFor(&window.Window)
+	dialog.SetModal(true)
+	dialog.SetDefaultSize(900, 600)
+
+	box := dialog.ContentArea()
+	box.SetSpacing(6)
+	box.SetMarginTop(12)
+	box.SetMarginBottom(12)
+	box.SetMarginStart(12)
+	box.SetMarginEnd(12)
+
+	pathLabel := gtk.NewLabel(path)
+	pathLabel.SetXAlign(0)
+	pathLabel.SetSelectable(true)
+	pathLabel.AddCSSClass("dim-label")
+	box.Append(pathLabel)
+
+	textView := gtk.NewTextView()
+	textView.SetEditable(false)
+	textView.SetCursorVisible(false)
+	textView.SetMonospace(true)
+	buffer := textView.Buffer()
+	buffer.SetText(text)
+
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetVExpand(true)
+	scrolled.SetChild(textView)
+	box.Append(scrolled)
+
+	// The newest records are the interesting ones
+	buffer.PlaceCursor(buffer.EndIter())
+	textView.ScrollToMark(buffer.GetInsert(), 0, false, 0, 0)
+
+	dialog.AddButton("Copy to Clipboard", int(gtk.ResponseApply))
+	dialog.AddButton("Close", int(gtk.ResponseClose))
+	dialog.SetDefaultResponse(int(gtk.ResponseClose))
+
+	dialog.ConnectResponse(func(responseID int) {
+		if responseID == int(gtk.ResponseApply) {
+			dialog.Clipboard().SetText(text)
+			statusBar.Info("Copied the log to the clipboard")
+			return
+		}
+		dialog.Destroy()
+	})
+
+	dialog.Show()
+}
@@ -3,24 +3,69 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/cli"
+	"github.com/lawrab/warren/internal/compositor"
 	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ipc"
+	"github.com/lawrab/warren/internal/logging"
+	"github.com/lawrab/warren/internal/theme"
 	"github.com/lawrab/warren/internal/ui"
 	"github.com/lawrab/warren/internal/version"
+	"github.com/lawrab/warren/pkg/models"
 )
 
 const appID = "com.lawrab.warren"
 
+// newWindowHint is passed as the open hint when --new-window was requested,
+// so the primary instance knows not to reuse an existing window.
+const newWindowHint = "new-window"
+
+// windows tracks open Warren windows so remote launches can reuse them.
+var windows []*appWindow
+
+// appWindow groups the widgets of one Warren window.
+type appWindow struct {
+	cfg          *config.Config
+	window       *gtk.ApplicationWindow
+	fileView     *ui.FileView // First pane; the one IPC and workspace memory follow
+	panes        *panes
+	preview      *ui.PreviewPane
+	previewPaned *gtk.Paned         // File list beside the preview
+	layout       config.WindowState // Layout the window opened with
+	pathLabel    *gtk.Label
+	sortLabel    *gtk.Label
+	statusBar    *ui.StatusBar
+	toasts       *ui.ToastOverlay
+	wmState      *compositorState
+	reloadKeymap func()
+	yankedSize   selectionSize // Total size of a multi-file yank; GTK thread only
+	markedSize   selectionSize // Total size of the marked entries; GTK thread only
+	freeSpace    freeSpace     // Filesystem of the current directory; GTK thread only
+}
+
 func main() {
+	// Headless subcommands (cp, mv, rm, trash) run without initializing GTK
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stderr))
+	}
+
 	// Parse command line flags
 	showVersion := flag.Bool("version", false, "Show version information")
 	flag.BoolVar(showVersion, "v", false, "Show version information (shorthand)")
+	newWindow := flag.Bool("new-window", false, "Open a new window even if Warren is already running")
+	flag.BoolVar(&debugLogging, "debug", false, "Log debug messages, whatever general.log_level says")
+	doctorReport := flag.Bool("doctor", false, "Report what Warren finds of its environment, for bug reports")
 	flag.Parse()
 
 	// Handle version flag
@@ -28,40 +73,382 @@ func main() {
 		fmt.Println(version.FullVersion())
 		os.Exit(0)
 	}
+	if *doctorReport {
+		os.Exit(runDoctor())
+	}
+
+	// Log to stderr and the log file, at debug level until the config
+	// sets it so config problems are not lost
+	logging.SetLevel(slog.LevelDebug)
+	logFile, err := logging.Setup(os.Stderr)
+	if err != nil {
+		slog.Warn("Logging to stderr only", "err", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	// Write a commented config file on the first launch
+	if wrote, err := config.Scaffold(); err != nil {
+		slog.Warn("Failed to write config template", "err", err)
+	} else if wrote {
+		firstRun = true
+		slog.Info("Wrote config template")
+	}
 
-	// Load configuration
+	// Upgrade an outdated config file, keeping a backup, then load it
+	if backup, err := config.Upgrade(); err != nil {
+		slog.Warn("Failed to upgrade config", "err", err)
+	} else if backup != "" {
+		slog.Info("Upgraded config file", "backup", backup)
+	}
 	cfg := config.LoadOrDefault()
+	for _, err := range config.Validate(cfg) {
+		slog.Warn("Config problem", "err", err)
+	}
+	applyLogLevel(cfg)
+	fileops.SetRateLimit(cfg.General.IOLimitBytes())
+
+	// Move files older releases kept among the settings to the state directory
+	if moved, err := config.MigrateState(compositor.MemoryFile); err != nil {
+		slog.Warn("Failed to move state out of the config directory", "err", err)
+	} else if len(moved) > 0 {
+		slog.Info("Moved state out of the config directory", "files", moved)
+	}
+	slog.Info("Starting Warren", "version", version.FullVersion())
+
+	// GtkApplication is single-instance: launching Warren again forwards
+	// activate/open to the running process instead of starting a new one.
+	// HandlesOpen lets GApplication turn path and file:// URI arguments
+	// into GFiles delivered through the open signal.
+	app := gtk.NewApplication(appID, gio.ApplicationHandlesOpen)
+	var control *ipc.Server
+	var configWatcher *fileops.FileWatcher
+	app.ConnectStartup(func() {
+		loadStyles()
+		setupTheme(app, cfg)
+		setupHooks()
+		setupRegisters()
+		setupHistory()
+		setupScripting(app)
+		setupShortcuts(app, cfg)
+		exportFileManager1(app, cfg)
+		if cfg.General.ControlSocket {
+			control = startControlSocket(app)
+		}
+		configWatcher = watchConfig(cfg)
+	})
+	app.ConnectShutdown(func() {
+		if scriptEngine != nil {
+			scriptEngine.Close()
+		}
+		if control != nil {
+			if err := control.Close(); err != nil {
+				slog.Warn("Failed to close control socket", "err", err)
+			}
+		}
+		if configWatcher != nil {
+			if err := configWatcher.Stop(); err != nil {
+				slog.Warn("Failed to stop config watcher", "err", err)
+			}
+		}
+	})
+	app.ConnectActivate(func() { presentWindow(app, cfg) })
+	app.ConnectOpen(func(files []gio.Filer, hint string) {
+		for _, file := range files {
+			openLocation(app, cfg, file, hint == newWindowHint)
+		}
+	})
+
+	// Flags were consumed above; pass only positional arguments through
+	args := append([]string{os.Args[0]}, flag.Args()...)
+
+	if *newWindow {
+		// GApplication can't forward our own flags, so register first and
+		// send the request explicitly. If another instance owns the app ID
+		// it handles the request and this process exits.
+		if err := app.Register(context.Background()); err != nil {
+			slog.Error("Failed to register application", "err", err)
+			os.Exit(1)
+		}
+
+		if files := filesForArgs(flag.Args()); len(files) > 0 {
+			app.Open(files, newWindowHint)
+		} else {
+			app.ActivateAction("new-window", nil)
+		}
+
+		if app.IsRemote() {
+			if conn := app.DBusConnection(); conn != nil {
+				_ = conn.FlushSync(context.Background())
+			}
+			return
+		}
 
-	app := gtk.NewApplication(appID, 0)
-	app.ConnectActivate(func() { activate(app, cfg) })
+		// We are the primary instance and the window already exists;
+		// Run just presents it and enters the main loop
+		args = args[:1]
+	}
 
-	if code := app.Run(os.Args); code > 0 {
+	if code := app.Run(args); code > 0 {
 		os.Exit(code)
 	}
 }
 
-func activate(app *gtk.Application, cfg *config.Config) {
-	// Initialize Hyprland integration
-	hyprState := setupHyprland(cfg)
+// filesForArgs converts command line arguments (paths or URIs) to GFiles.
+func filesForArgs(args []string) []gio.Filer {
+	files := make([]gio.Filer, 0, len(args))
+	for _, arg := range args {
+		files = append(files, gio.NewFileForCommandlineArg(arg))
+	}
+	return files
+}
 
-	// Add CSS styling
+// loadStyles installs Warren's CSS for the default display.
+func loadStyles() {
 	cssProvider := gtk.NewCSSProvider()
 	cssProvider.LoadFromString(`
 		/* Dim label styling */
 		.dim-label {
 			opacity: 0.65;
 		}
+
+		/* Status bar message severities */
+		.status-warning {
+			color: @warning_color;
+		}
+		.status-error {
+			color: @error_color;
+		}
+		.status-segment {
+			margin-start: 12px;
+		}
+
+		/* Toasts for finished background operations */
+		.toast {
+			padding: 8px 16px;
+			border-radius: 999px;
+			background-color: alpha(@theme_fg_color, 0.85);
+			color: @theme_bg_color;
+		}
+		.toast-error {
+			background-color: @error_color;
+		}
+
+		/* File list rows: zebra striping and per-file state classes */
+		columnview > listview > row:nth-child(even) {
+			background-color: alpha(@theme_fg_color, 0.03);
+		}
+		.file-yanked {
+			color: @accent_color;
+			font-weight: bold;
+		}
+		.file-cut {
+			font-style: italic;
+			opacity: 0.6;
+		}
+		.file-hidden {
+			opacity: 0.65;
+		}
+		.file-marked {
+			font-weight: bold;
+			text-decoration: underline;
+		}
+		.file-symlink {
+			font-style: italic;
+		}
+		.file-executable {
+			color: @success_color;
+		}
+		.file-setuid {
+			color: @error_color;
+			font-weight: bold;
+		}
+		.file-unreadable {
+			opacity: 0.5;
+		}
+		.file-broken-symlink {
+			color: @error_color;
+			text-decoration: line-through;
+		}
+		.symlink-target {
+			font-style: normal;
+			opacity: 0.6;
+		}
+		.symlink-target.file-broken-symlink {
+			opacity: 1;
+			text-decoration: none;
+			font-style: italic;
+		}
+
+		/* Dual-pane comparison: unique, differing and newer entries */
+		.file-compare-unique {
+			color: @success_color;
+		}
+		.file-compare-differs {
+			color: @warning_color;
+		}
+		.file-compare-newer {
+			color: @warning_color;
+			font-weight: bold;
+		}
+		.pane-inactive {
+			opacity: 0.7;
+		}
 	`)
 	gtk.StyleContextAddProviderForDisplay(
 		gdk.DisplayGetDefault(),
 		cssProvider,
 		gtk.STYLE_PROVIDER_PRIORITY_APPLICATION,
 	)
+}
+
+// applyPreviewConfig applies the [preview] settings to a preview pane.
+func applyPreviewConfig(previewPane *ui.PreviewPane, cfg *config.Config) {
+	previewPane.SetOptions(cfg.Preview.MaxTextBytes(), cfg.Preview.LineNumbers)
+	previewPane.SetVisible(cfg.Preview.Enabled)
+}
+
+// applyDisplayFormats sets the file list's size and date formats, its
+// icon size and where it cuts long names from the config. Invalid values were already reported by
+// validation and fall back to the defaults.
+func applyDisplayFormats(fileView *ui.FileView, cfg *config.Config) {
+	size, _ := fileops.ParseSizeFormat(cfg.Appearance.SizeFormat)
+	modTime, _ := fileops.ParseTimeFormat(cfg.Appearance.DateFormat)
+	fileView.SetDisplayFormats(size, modTime)
+	iconSize := cfg.Appearance.IconSize
+	if iconSize < config.MinIconSize || iconSize > config.MaxIconSize {
+		iconSize = config.DefaultIconSize
+	}
+	fileView.SetIconSize(iconSize)
+	ellipsize, _ := theme.ParseEllipsize(cfg.Appearance.Ellipsize)
+	fileView.SetEllipsize(ellipsize)
+}
+
+// presentWindow raises the most recently used window, creating one if
+// Warren has no windows yet.
+func presentWindow(app *gtk.Application, cfg *config.Config) {
+	if w := activeWindow(app); w != nil {
+		w.window.Present()
+		return
+	}
+	activate(app, cfg, "", "")
+}
+
+// activeWindow returns the most recently focused Warren window, or nil.
+func activeWindow(app *gtk.Application) *appWindow {
+	// GTK keeps the application's window list in most-recently-focused order
+	for _, win := range app.Windows() {
+		for _, w := range windows {
+			if w.window.Eq(win) {
+				return w
+			}
+		}
+	}
+	return nil
+}
+
+// openLocation shows a location received through the open signal.
+// Directories are opened directly; files open their parent with the file selected.
+// Unless forceNew is set, an existing window is navigated instead of opening another.
+func openLocation(app *gtk.Application, cfg *config.Config, file gio.Filer, forceNew bool) {
+	location := file.Path()
+	if location == "" {
+		location = file.URI()
+	}
+
+	dir, selectPath, err := fileops.ResolveTarget(location)
+	if err != nil {
+		slog.Warn("Cannot open location", "location", location, "err", err)
+		if forceNew {
+			activate(app, cfg, "", "")
+		} else {
+			presentWindow(app, cfg)
+		}
+		return
+	}
+
+	showLocation(app, cfg, dir, selectPath, forceNew)
+}
+
+// showLocation navigates the active window to dir, selecting selectPath if
+// set, or opens a new window there when forceNew is set or none exists.
+func showLocation(app *gtk.Application, cfg *config.Config, dir, selectPath string, forceNew bool) {
+	if w := activeWindow(app); w != nil && !forceNew {
+		_ = w.navigate(dir, selectPath)
+		w.window.Present()
+		return
+	}
+
+	activate(app, cfg, dir, selectPath)
+}
+
+// navigate loads dir in the window and selects selectPath if it is set.
+func (w *appWindow) navigate(dir, selectPath string) error {
+	if err := w.fileView.LoadDirectory(dir); err != nil {
+		w.statusBar.Error(err.Error())
+		return err
+	}
+	if selectPath != "" {
+		w.fileView.SelectPath(selectPath)
+	}
+	w.pathLabel.SetText(fileops.DisplayName(w.fileView.GetCurrentPath()))
+	updateStatusBar(w.statusBar, w.fileView)
+	directoryVisited(w.wmState, w.fileView.GetCurrentPath(), w.statusBar)
+	return nil
+}
+
+// reveal shows path in its directory: the focused pane opens the parent
+// directory and selects path there. Results views listing files from
+// several directories use it to jump to one of them.
+func (w *appWindow) reveal(path string) error {
+	fileView := w.panes.active()
+	if err := fileView.LoadDirectory(filepath.Dir(path)); err != nil {
+		w.statusBar.Error(err.Error())
+		return err
+	}
+	if !fileView.SelectPath(path) {
+		w.statusBar.Warn(fmt.Sprintf("%s is gone or hidden", filepath.Base(path)))
+	}
+	w.pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+	updateStatusBar(w.statusBar, fileView)
+	directoryVisited(w.wmState, fileView.GetCurrentPath(), w.statusBar)
+	return nil
+}
+
+// showProperties opens the properties window for the selected entry.
+func (w *appWindow) showProperties() {
+	if selected := w.panes.active().GetSelected(); selected != nil {
+		ui.ShowProperties(&w.window.Window, selected)
+	}
+}
+
+// selectEntry selects an entry in the current listing. Relative names are
+// resolved against the current directory.
+func (w *appWindow) selectEntry(name string) error {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(w.fileView.GetCurrentPath(), path)
+	}
+	if !w.fileView.SelectPath(path) {
+		return fmt.Errorf("not in current directory: %s", name)
+	}
+	updateStatusBar(w.statusBar, w.fileView)
+	return nil
+}
+
+// activate builds the main window. startDir and selectPath are optional:
+// when startDir is empty the configured or remembered directory is used.
+func activate(app *gtk.Application, cfg *config.Config, startDir, selectPath string) *appWindow {
+	// Initialize Hyprland/Sway integration
+	wmState := setupCompositor(cfg)
 
 	// Create main window
 	window := gtk.NewApplicationWindow(app)
 	window.SetTitle(fmt.Sprintf("Warren %s", version.Short()))
-	window.SetDefaultSize(cfg.Appearance.WindowWidth, cfg.Appearance.WindowHeight)
+	layout := loadWindowState(cfg)
+	window.SetDefaultSize(layout.Width, layout.Height)
+	if layout.Maximized {
+		window.Maximize()
+	}
 
 	// Create a header bar
 	headerBar := gtk.NewHeaderBar()
@@ -77,101 +464,203 @@ func activate(app *gtk.Application, cfg *config.Config) {
 	// Create main box layout
 	box := gtk.NewBox(gtk.OrientationVertical, 0)
 
-	// Create file view
+	// Create the file views (the second is shown in dual-pane mode), with
+	// toasts for finished background operations
 	fileView := ui.NewFileView()
-	box.Append(fileView.Widget())
+	views := newPanes(fileView, ui.NewFileView())
+	views.split = layout.PaneSplit
+	views.saved = layout.Panes[1]
+	toasts := ui.NewToastOverlay(views.Widget())
+
+	// Preview the focused pane's selection beside the list
+	previewPane := ui.NewPreviewPane()
+	applyPreviewConfig(previewPane, cfg)
+	for _, view := range views.views {
+		view.ConnectSelectionChanged(func(file *models.FileInfo) {
+			if views.active() == view {
+				previewPane.Show(file)
+			}
+		})
+		view.ConnectDirectoryChanged(func(string) {
+			if views.active() == view && view.GetSelected() == nil {
+				previewPane.Clear()
+			}
+		})
+	}
+
+	paned := gtk.NewPaned(gtk.OrientationHorizontal)
+	paned.SetStartChild(toasts.Widget())
+	paned.SetEndChild(previewPane.Widget())
+	paned.SetShrinkStartChild(false)
+	paned.SetShrinkEndChild(false)
+	setSplit(paned, layout.PreviewSplit)
+	paned.SetVExpand(true)
+	box.Append(paned)
 
 	// Create status bar
-	statusBar := gtk.NewBox(gtk.OrientationHorizontal, 12)
-	statusBar.SetMarginTop(6)
-	statusBar.SetMarginBottom(6)
-	statusBar.SetMarginStart(12)
-	statusBar.SetMarginEnd(12)
-	statusLabel := gtk.NewLabel("Ready")
-	statusLabel.SetXAlign(0)
-	statusLabel.SetHExpand(true)
-	statusBar.Append(statusLabel)
+	statusBox := gtk.NewBox(gtk.OrientationHorizontal, 12)
+	statusBox.SetMarginTop(6)
+	statusBox.SetMarginBottom(6)
+	statusBox.SetMarginStart(12)
+	statusBox.SetMarginEnd(12)
+	statusBar := ui.NewStatusBar()
+	statusBox.Append(statusBar.Widget())
 
 	// Add sort mode indicator
-	sortLabel := gtk.NewLabel(formatSortMode(fileView))
+	sortLabel := gtk.NewLabel("")
+	showSortMode(sortLabel, fileView)
 	sortLabel.AddCSSClass("dim-label")
 	sortLabel.SetMarginEnd(12)
-	statusBar.Append(sortLabel)
+	statusBox.Append(sortLabel)
 
 	helpLabel := gtk.NewLabel("?: help  j/k: nav")
+	ui.SetAccessibleLabel(helpLabel, "Press question mark for keyboard shortcuts")
 	helpLabel.AddCSSClass("dim-label")
-	statusBar.Append(helpLabel)
+	statusBox.Append(helpLabel)
+
+	box.Append(statusBox)
+
+	// Keep the focused pane's segments of the status bar up to date
+	for _, view := range views.views {
+		view.ConnectListingChanged(func() {
+			if views.active() == view {
+				showItemCount(statusBar, view)
+			}
+		})
+		view.ConnectMarksChanged(func([]models.FileInfo) {
+			if views.active() == view {
+				showMarked(statusBar, view)
+			}
+		})
+	}
+
+	// Run user hooks on navigation and selection, including the first load
+	connectHooks(fileView, statusBar)
 
-	box.Append(statusBar)
+	// Point out config mistakes that would otherwise be silently ignored
+	warnConfigProblems(cfg, statusBar)
 
 	// Add box to window
 	window.SetChild(box)
 
 	// Determine starting directory
-	// First check if there's a remembered directory for current workspace
-	startDir := config.GetStartDirectory(cfg.General.StartDirectory)
-	if hyprState != nil && hyprState.client != nil && hyprState.memory != nil && cfg.Hyprland.WorkspaceMemory {
-		if ws, err := hyprState.client.GetActiveWorkspace(); err == nil {
-			if rememberedDir := hyprState.memory.Get(ws.ID); rememberedDir != "" {
-				// Verify directory still exists
-				if info, err := os.Stat(rememberedDir); err == nil && info.IsDir() {
-					startDir = rememberedDir
-					log.Printf("Using remembered directory for workspace %d: %s", ws.ID, rememberedDir)
+	// A location given on the command line wins; otherwise check if there's
+	// a remembered directory for current workspace
+	if startDir == "" {
+		startDir = config.GetStartDirectory(cfg.General.StartDirectory)
+		if wmState != nil && wmState.memory != nil && cfg.Hyprland.WorkspaceMemory {
+			if key, err := wmState.startKey(); err == nil {
+				if rememberedDir := wmState.memory.Get(key); rememberedDir != "" {
+					// Verify directory still exists
+					if info, err := os.Stat(rememberedDir); err == nil && info.IsDir() {
+						startDir = rememberedDir
+						slog.Debug("Using remembered directory for workspace", "workspace", key, "path", rememberedDir)
+					}
 				}
 			}
 		}
 	}
 
-	// Apply sort mode from config
-	sortMode := config.ParseSortMode(cfg.Appearance.DefaultSortMode)
-	sortOrder := config.ParseSortOrder(cfg.Appearance.DefaultSortOrder)
-	fileView.SetSortMode(sortMode, sortOrder)
+	// Each pane lists as it did in the last window, or as the config says
+	for i, view := range views.views {
+		view.ApplyState(layout.Panes[i])
+		_ = view.SetHiddenLast(cfg.Appearance.HiddenLast) // Nothing is loaded yet
+		applyDisplayFormats(view, cfg)
+		_ = view.SetItemCounts(cfg.General.CountItems) // Nothing is loaded yet
+		view.SetWatchSubdirectories(cfg.General.WatchSubdirectories && cfg.General.CountItems)
+		view.SetPollInterval(cfg.General.PollDuration())
+	}
 
 	// Load initial directory
 	if err := fileView.LoadDirectory(startDir); err != nil {
-		log.Printf("Failed to load directory: %v", err)
-		statusLabel.SetText(err.Error())
+		slog.Warn("Failed to load directory", "err", err)
+		statusBar.Error(err.Error())
 	} else {
-		pathLabel.SetText(fileView.GetCurrentPath())
-		updateStatusBar(statusLabel, fileView)
+		pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+		updateStatusBar(statusBar, fileView)
 		// Save initial directory to workspace memory
-		saveCurrentDirectoryToWorkspace(hyprState, fileView.GetCurrentPath())
+		saveCurrentDirectoryToWorkspace(wmState, fileView.GetCurrentPath())
 	}
 
-	// Apply initial show hidden files setting from config
-	if cfg.Appearance.ShowHidden {
-		if err := fileView.ToggleHidden(); err != nil {
-			log.Printf("Failed to apply show_hidden setting: %v", err)
-		}
+	// Bring back the yank of the last session
+	restoreYank(fileView)
+
+	// Select the file named on the command line, if any, or else the one
+	// selected when the last window closed
+	if selectPath == "" {
+		selectPath = layout.Panes[0].Selected
+	}
+	if selectPath != "" && fileView.SelectPath(selectPath) {
+		updateStatusBar(statusBar, fileView)
 	}
 
 	// Update sort label to reflect initial state
-	sortLabel.SetText(formatSortMode(fileView))
+	showSortMode(sortLabel, fileView)
 
-	// Start Hyprland event listener
-	startHyprlandListener(hyprState, cfg, fileView, pathLabel, statusLabel)
+	// Start the workspace listener (Hyprland or Sway)
+	startWorkspaceListener(wmState, cfg, fileView, pathLabel, statusBar)
 
 	// Set up keyboard event controller
-	keyController := setupKeyboardHandler(cfg, fileView, pathLabel, statusLabel, sortLabel, window, hyprState)
+	keyController, reloadKeymap := setupKeyboardHandler(cfg, views, previewPane, pathLabel, statusBar, sortLabel, window, wmState)
 	window.AddController(keyController)
 
-	// Keyboard shortcuts
-	setupShortcuts(app, window)
+	w := &appWindow{
+		cfg:          cfg,
+		window:       window,
+		fileView:     fileView,
+		panes:        views,
+		preview:      previewPane,
+		previewPaned: paned,
+		layout:       layout,
+		pathLabel:    pathLabel,
+		sortLabel:    sortLabel,
+		statusBar:    statusBar,
+		toasts:       toasts,
+		wmState:      wmState,
+		reloadKeymap: reloadKeymap,
+	}
+	windows = append(windows, w)
 
-	// Cleanup file watcher and save workspace memory when window closes
+	// Cleanup file watcher and save workspace memory and the window's
+	// layout when it closes
 	window.ConnectCloseRequest(func() bool {
-		if err := fileView.Close(); err != nil {
-			log.Printf("Warning: Failed to close file watcher: %v", err)
+		w.saveWindowState()
+		saveYank(views.active())
+		for _, view := range views.views {
+			if err := view.Close(); err != nil {
+				slog.Warn("Failed to close file watcher", "err", err)
+			}
+		}
+		if wmState != nil && wmState.stopEvents != nil {
+			wmState.stopEvents()
 		}
 		// Save workspace memory on exit
-		if hyprState != nil && hyprState.memory != nil {
-			if err := hyprState.memory.Save(); err != nil {
-				log.Printf("Warning: Failed to save workspace memory: %v", err)
+		if wmState != nil && wmState.memory != nil {
+			if err := wmState.memory.Save(); err != nil {
+				slog.Warn("Failed to save workspace memory", "err", err)
 			}
 		}
+		w.yankedSize.reset()
+		w.markedSize.reset()
+		removeWindow(w)
 		return false // Allow window to close
 	})
 
 	// Show window
 	window.Present()
+	if firstRun {
+		firstRun = false
+		showOnboarding(window, cfg, statusBar)
+	}
+	return w
+}
+
+// removeWindow drops a closed window from the window list.
+func removeWindow(w *appWindow) {
+	for i, other := range windows {
+		if other == w {
+			windows = append(windows[:i], windows[i+1:]...)
+			return
+		}
+	}
 }
@@ -0,0 +1,66 @@
+// Completion notifications for background file operations.
+// When a copy, move or delete finishes after the user has moved on, a toast
+// is shown in the window and optionally a desktop notification is sent.
+package main
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// windowFor returns the appWindow wrapping a GTK window, or nil.
+func windowFor(window *gtk.ApplicationWindow) *appWindow {
+	for _, w := range windows {
+		if w.window.Eq(window) {
+			return w
+		}
+	}
+	return nil
+}
+
+// reportOperation announces a finished operation that was started in
+// startDir. The caller has already updated the status bar; this adds a
+// toast when the user has since left that directory or the window has lost
+// focus (for example after switching workspace), and a desktop
+// notification when the window is unfocused and they are enabled.
+// Must be called on the GTK main thread.
+func (w *appWindow) reportOperation(op *fileops.Operation, startDir string) {
+	if w == nil {
+		return
+	}
+
+	focused := w.window.IsActive()
+	if focused && w.fileView.GetCurrentPath() == startDir {
+		return
+	}
+
+	failed := op.Status == fileops.StatusFailed
+	summary := op.Summary()
+	w.toasts.Show(summary, failed)
+
+	if !focused && w.cfg.General.DesktopNotifications {
+		sendDesktopNotification(w.window.Application(), op, summary, failed)
+	}
+}
+
+// sendDesktopNotification posts a notification through GApplication, which
+// delivers it via org.freedesktop.Notifications (or the portal when
+// sandboxed). Each operation uses its own ID so notifications stack.
+func sendDesktopNotification(app *gtk.Application, op *fileops.Operation, summary string, failed bool) {
+	if app == nil {
+		return
+	}
+
+	title := op.Type.String() + " finished"
+	if failed {
+		title = op.Type.String() + " failed"
+	}
+
+	notification := gio.NewNotification(title)
+	notification.SetBody(summary)
+	if failed {
+		notification.SetPriority(gio.NotificationPriorityHigh)
+	}
+	app.SendNotification("operation-"+op.ID, notification)
+}
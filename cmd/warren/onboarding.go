@@ -0,0 +1,115 @@
+// First-run onboarding.
+// On the first launch main writes a commented config.toml (see
+// config.Scaffold) and the first window shows a short introduction to
+// Warren's vim-style keys, offering to turn off compositor integration.
+//
+//nolint:staticcheck // gtk.Dialog is deprecated in GTK4 but still functional in our version
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/compositor"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// firstRun is set when main wrote the config file, until the first window
+// has shown the onboarding dialog. GTK thread only once the app runs.
+var firstRun bool
+
+// onboardingKeys returns the introduction's lines of keys and what they
+// do, in the keys the config binds. Unbound actions are left out.
+func onboardingKeys(k config.KeybindingsConfig) []string {
+	var lines []string
+	for _, line := range []struct {
+		keys []string
+		does string
+	}{
+		{[]string{k.NavigateDown, k.NavigateUp}, "move down and up"},
+		{[]string{k.ParentDir, k.EnterDir}, "go to the parent directory, open the selection"},
+		{[]string{k.GoTop, k.GoBottom}, "jump to the first and last entry"},
+		{[]string{k.Jump}, "jump to a name by typing its start"},
+		{[]string{k.Yank, k.Cut, k.Paste}, "copy, cut and paste"},
+		{[]string{k.Delete}, "delete"},
+		{[]string{k.ShowHelp}, "list every shortcut"},
+		{[]string{k.Preferences}, "open preferences"},
+	} {
+		var keys []string
+		for _, key := range line.keys {
+			if key != "" {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) > 0 {
+			lines = append(lines, fmt.Sprintf("%s\t%s", strings.Join(keys, ", "), line.does))
+		}
+	}
+	return lines
+}
+
+// showOnboarding introduces Warren on its first launch. When it runs
+// under a supported compositor it offers to turn the integration off,
+// which rewrites the freshly written config file.
+func showOnboarding(window *gtk.ApplicationWindow, cfg *config.Config, statusBar *ui.StatusBar) {
+	dialog := gtk.NewDialog()
+	dialog.SetTitle("Welcome to Warren")
+	dialog.SetTransientFor(&window.Window)
+	dialog.SetModal(true)
+
+	text := "Warren is driven from the keyboard, with vim-style keys:\n\n" +
+		strings.Join(onboardingKeys(cfg.Keybindings), "\n")
+	if path, err := config.Path(); err == nil {
+		text += fmt.Sprintf("\n\nEvery setting is listed, with comments, in\n%s\nChanges apply as soon as you save it.", path)
+	}
+
+	label := gtk.NewLabel(text)
+	ui.SetAccessibleDescription(dialog, text)
+	label.SetXAlign(0)
+	label.SetSelectable(true)
+	label.SetMarginTop(12)
+	label.SetMarginBottom(12)
+	label.SetMarginStart(12)
+	label.SetMarginEnd(12)
+
+	box := dialog.ContentArea()
+	box.Append(label)
+
+	// Integration is enabled in the template; without a compositor it
+	// just stays idle, so there is nothing to ask
+	var integration *gtk.CheckButton
+	if name := compositor.Detected(); name != "" && cfg.Hyprland.Enabled {
+		integration = gtk.NewCheckButtonWithLabel(fmt.Sprintf("Enable %s integration (workspace memory, opening files on workspaces)", name))
+		integration.SetActive(true)
+		integration.SetMarginStart(12)
+		integration.SetMarginEnd(12)
+		integration.SetMarginBottom(12)
+		box.Append(integration)
+	}
+
+	dialog.AddButton("Get Started", int(gtk.ResponseOK))
+	dialog.SetDefaultResponse(int(gtk.ResponseOK))
+
+	dialog.ConnectResponse(func(int) {
+		disable := integration != nil && !integration.Active()
+		dialog.Destroy()
+		if !disable {
+			return
+		}
+
+		if err := config.WriteTemplate(false); err != nil {
+			slog.Error("Failed to save config", "err", err)
+			statusBar.Error(fmt.Sprintf("Failed to save settings: %v", err))
+			return
+		}
+		// Already applied; don't let the watcher reload it
+		loadedConfig = config.Template(false)
+		cfg.Hyprland.Enabled = false
+		statusBar.Info("Compositor integration turned off; this window keeps it until Warren restarts. Re-enable it under [hyprland] in config.toml")
+	})
+
+	dialog.Show()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lawrab/warren/internal/config"
+)
+
+func TestOnboardingKeys(t *testing.T) {
+	k := config.Default().Keybindings
+	lines := onboardingKeys(k)
+	if want := "j, k\tmove down and up"; lines[0] != want {
+		t.Errorf("onboardingKeys()[0] = %q, want %q", lines[0], want)
+	}
+
+	// Rebound keys are shown, unbound actions left out
+	k.NavigateDown, k.NavigateUp = "n", "e"
+	k.Delete = ""
+	lines = onboardingKeys(k)
+	if want := "n, e\tmove down and up"; lines[0] != want {
+		t.Errorf("onboardingKeys()[0] = %q, want %q", lines[0], want)
+	}
+	if slices.ContainsFunc(lines, func(line string) bool { return strings.HasSuffix(line, "\tdelete") }) {
+		t.Errorf("onboardingKeys() = %q, want no delete line", lines)
+	}
+}
@@ -0,0 +1,299 @@
+// Dual-pane mode: a second file view beside the first, directory
+// comparison between the two, and copying newer files across.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// classInactivePane dims the pane without keyboard focus in dual-pane mode.
+const classInactivePane = "pane-inactive"
+
+// panes holds the file views of a window. The second view is only shown
+// in dual-pane mode, where keyboard commands act on the focused one.
+// Only touched on the GTK main thread.
+type panes struct {
+	views   [2]*ui.FileView
+	focused int
+	dual    bool
+	paned   *gtk.Paned
+	split   float64 // Fraction of the width for the first pane
+
+	// saved is where the second pane was in the last window, reopened
+	// the first time it is shown
+	saved config.ViewState
+}
+
+// newPanes lays out two file views side by side, with the second hidden.
+func newPanes(first, second *ui.FileView) *panes {
+	p := &panes{
+		views: [2]*ui.FileView{first, second},
+		paned: gtk.NewPaned(gtk.OrientationHorizontal),
+		split: 0.5,
+	}
+	p.paned.SetStartChild(first.Widget())
+	p.paned.SetEndChild(second.Widget())
+	p.paned.SetShrinkStartChild(false)
+	p.paned.SetShrinkEndChild(false)
+	gtk.BaseWidget(second.Widget()).SetVisible(false)
+	return p
+}
+
+// Widget returns the GTK widget holding both panes.
+func (p *panes) Widget() gtk.Widgetter {
+	return p.paned
+}
+
+// active returns the focused file view.
+func (p *panes) active() *ui.FileView {
+	return p.views[p.focused]
+}
+
+// other returns the file view without focus.
+func (p *panes) other() *ui.FileView {
+	return p.views[1-p.focused]
+}
+
+// contains reports whether fileView is one of the panes.
+func (p *panes) contains(fileView *ui.FileView) bool {
+	return p.views[0] == fileView || p.views[1] == fileView
+}
+
+// setDual shows or hides the second pane. The first time it is shown it
+// opens the directory it had in the last window, if that is still there,
+// or else the first pane's. The divider returns to where it was
+// when the second pane was last hidden. Hiding it moves focus back to the
+// first pane and clears comparison highlights.
+func (p *panes) setDual(dual bool) error {
+	second := p.views[1]
+	if dual && second.GetCurrentPath() == "" {
+		dir := p.views[0].GetCurrentPath()
+		if info, err := os.Stat(p.saved.Path); err == nil && info.IsDir() {
+			dir = p.saved.Path
+		}
+		if err := second.LoadDirectory(dir); err != nil {
+			return err
+		}
+		if p.saved.Selected != "" {
+			second.SelectPath(p.saved.Selected)
+		}
+	}
+
+	if !dual {
+		p.split = p.splitRatio()
+	}
+	p.dual = dual
+	gtk.BaseWidget(second.Widget()).SetVisible(dual)
+	if dual {
+		setSplit(p.paned, p.split)
+	} else {
+		p.focused = 0
+		for _, view := range p.views {
+			if view.HasCompareMarks() {
+				view.SetCompareMarks(nil)
+			}
+		}
+	}
+	p.updateFocusClasses()
+	return nil
+}
+
+// splitRatio returns the fraction of the width for the first pane: where
+// the divider is in dual-pane mode, otherwise where it last was.
+func (p *panes) splitRatio() float64 {
+	if !p.dual {
+		return p.split
+	}
+	return splitOf(p.paned, p.split)
+}
+
+// switchFocus moves keyboard focus to the other pane. Returns false
+// outside dual-pane mode.
+func (p *panes) switchFocus() bool {
+	if !p.dual {
+		return false
+	}
+	p.focused = 1 - p.focused
+	p.updateFocusClasses()
+	return true
+}
+
+// updateFocusClasses dims the unfocused pane.
+func (p *panes) updateFocusClasses() {
+	for i, view := range p.views {
+		widget := gtk.BaseWidget(view.Widget())
+		if p.dual && i != p.focused {
+			widget.AddCSSClass(classInactivePane)
+		} else {
+			widget.RemoveCSSClass(classInactivePane)
+		}
+	}
+}
+
+// showFocusedPane updates the path and sort labels, status bar and preview
+// for the pane that now has focus.
+func showFocusedPane(p *panes, previewPane *ui.PreviewPane, pathLabel, sortLabel *gtk.Label, statusBar *ui.StatusBar) {
+	fileView := p.active()
+	pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+	showSortMode(sortLabel, fileView)
+	updateStatusBar(statusBar, fileView)
+	if selected := fileView.GetSelected(); selected != nil {
+		previewPane.Show(selected)
+	} else {
+		previewPane.Clear()
+	}
+}
+
+// showSortMode shows fileView's sort mode in label, and says it in words
+// for screen readers, which would read the arrow as "upwards arrow".
+func showSortMode(label *gtk.Label, fileView *ui.FileView) {
+	label.SetText(formatSortMode(fileView))
+	ui.SetAccessibleLabel(label, fmt.Sprintf("Sorted by %s, %s",
+		strings.ToLower(fileView.GetSortMode().String()), fileView.GetSortOrder()))
+}
+
+// comparePanes compares the focused pane's directory with the other
+// pane's in the background, then highlights entries unique to either side
+// and entries that differ in both.
+func comparePanes(p *panes, mode fileops.CompareMode, statusBar *ui.StatusBar) {
+	if !p.dual {
+		statusBar.Warn("Comparing needs dual-pane mode")
+		return
+	}
+
+	here, there := p.active(), p.other()
+	hereDir, thereDir := here.GetCurrentPath(), there.GetCurrentPath()
+	if hereDir == thereDir {
+		statusBar.Info("Both panes show the same directory")
+		return
+	}
+	statusBar.Info("Comparing…")
+
+	go func() {
+		entries, err := fileops.CompareDirectories(hereDir, thereDir, mode)
+		glib.IdleAdd(func() {
+			if err != nil {
+				statusBar.Error(fmt.Sprintf("Failed to compare: %v", err))
+				return
+			}
+			// Either pane may have moved on while the comparison ran
+			if here.GetCurrentPath() != hereDir || there.GetCurrentPath() != thereDir {
+				return
+			}
+
+			hereMarks, thereMarks := fileops.CompareMarks(entries)
+			here.SetCompareMarks(hereMarks)
+			there.SetCompareMarks(thereMarks)
+
+			summary := fileops.Summarize(entries)
+			if summary == (fileops.CompareSummary{}) {
+				statusBar.Info("Directories match")
+				return
+			}
+			statusBar.Info(fmt.Sprintf("%d only here, %d only there, %d differ",
+				summary.LeftOnly, summary.RightOnly, summary.Differ))
+		})
+	}()
+}
+
+// syncNewer copies what the other pane is missing, and files it has an
+// older copy of, from the focused pane. Overwrites and large transfers are
+// confirmed as for a paste.
+func syncNewer(cfg *config.Config, window *gtk.ApplicationWindow, p *panes, statusBar *ui.StatusBar) {
+	if !p.dual {
+		statusBar.Warn("Syncing needs dual-pane mode")
+		return
+	}
+
+	here, there := p.active(), p.other()
+	hereDir, thereDir := here.GetCurrentPath(), there.GetCurrentPath()
+	if hereDir == thereDir {
+		statusBar.Info("Both panes show the same directory")
+		return
+	}
+
+	go func() {
+		entries, err := fileops.CompareDirectories(hereDir, thereDir, fileops.CompareSizeTime)
+		var info fileops.TransferInfo
+		sources := fileops.NewerSources(entries)
+		if err == nil && len(sources) > 0 {
+			info, err = fileops.CheckTransfer(sources, thereDir, false)
+		}
+
+		glib.IdleAdd(func() {
+			if err != nil {
+				statusBar.Error(fmt.Sprintf("Failed to sync: %v", err))
+				return
+			}
+			if len(sources) == 0 {
+				statusBar.Info("Nothing newer to copy")
+				return
+			}
+			if len(info.NameFixes) > 0 {
+				// Syncing copies under the same names, so it would fail
+				statusBar.Error(fmt.Sprintf("Can't sync: %s can't store %d of the names", thereDir, len(info.NameFixes)))
+				return
+			}
+
+			start := func() {
+				startSync(window, p, entries, hereDir, thereDir, statusBar)
+			}
+			reasons := transferConfirmations(cfg.Confirm, info)
+			if len(reasons) == 0 {
+				start()
+				return
+			}
+
+			message := fmt.Sprintf("Copy %d newer item(s) to %s?", len(sources), thereDir)
+			for _, r := range reasons {
+				message += "\n\n" + r.message
+			}
+			if !canDisable(reasons) {
+				showQuestionDialog(window, "Sync Newer", message, "Copy Anyway", start)
+				return
+			}
+			showConfirmDialog(window, "Sync Newer", message, "Copy", func(dontAskAgain bool) {
+				if dontAskAgain {
+					disableConfirmations(&cfg.Confirm, reasons)
+					saveConfirmSettings(cfg, statusBar)
+				}
+				start()
+			})
+		})
+	}()
+}
+
+// startSync runs the sync copy and compares the panes again when it ends.
+func startSync(window *gtk.ApplicationWindow, p *panes, entries []fileops.CompareEntry, hereDir, thereDir string, statusBar *ui.StatusBar) {
+	count := len(fileops.NewerSources(entries))
+	fileops.SyncNewer(entries, thereDir, func(op *fileops.Operation) {
+		glib.IdleAdd(func() {
+			switch op.Status {
+			case fileops.StatusCompleted:
+				statusBar.Info(fmt.Sprintf("Copied %d newer item(s)", count))
+			case fileops.StatusFailed:
+				statusBar.Error(fmt.Sprintf("Failed to sync: %v", op.Error))
+			default:
+				return
+			}
+
+			for _, view := range p.views {
+				if view.GetCurrentPath() == thereDir {
+					_ = view.LoadDirectory(thereDir)
+				}
+			}
+			if p.active().GetCurrentPath() == hereDir && p.other().GetCurrentPath() == thereDir {
+				comparePanes(p, fileops.CompareSizeTime, statusBar)
+			}
+			windowFor(window).reportOperation(op, hereDir)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+// Renaming photos after the time they were taken.
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// renamePhotos renames the yanked files, or the selected one, after their
+// EXIF capture time once confirmed. Capture times are read in the
+// background; files without one are left alone.
+func renamePhotos(window *gtk.ApplicationWindow, fileView *ui.FileView, statusBar *ui.StatusBar) {
+	paths := fileView.GetYanked()
+	if len(paths) == 0 {
+		if selected := fileView.GetSelected(); selected != nil && !selected.IsDir {
+			paths = []string{selected.Path}
+		}
+	}
+	if len(paths) == 0 {
+		statusBar.Info("Select or yank photos to rename")
+		return
+	}
+	statusBar.Info("Reading capture times…")
+
+	go func() {
+		renames, skipped := fileops.PlanPhotoRenames(paths)
+		glib.IdleAdd(func() {
+			if len(renames) == 0 {
+				if len(skipped) > 0 {
+					statusBar.Info(fmt.Sprintf("No capture time in %d file(s)", len(skipped)))
+				} else {
+					statusBar.Info("Photos are already named after their capture time")
+				}
+				return
+			}
+
+			first := renames[0]
+			message := fmt.Sprintf("Rename %d photo(s) after their capture time?\n\n%s → %s",
+				len(renames), fileops.DisplayName(filepath.Base(first.From)), fileops.DisplayName(filepath.Base(first.To)))
+			if len(renames) > 1 {
+				message += "\n…"
+			}
+			if len(skipped) > 0 {
+				message += fmt.Sprintf("\n\n%d file(s) without a capture time are left alone", len(skipped))
+			}
+
+			showQuestionDialog(window, "Rename Photos", message, "Rename", func() {
+				n, err := fileops.RenamePhotos(renames)
+				if err != nil {
+					statusBar.Error(fmt.Sprintf("Renamed %d of %d photo(s): %v", n, len(renames), err))
+				} else {
+					statusBar.Info(fmt.Sprintf("Renamed %d photo(s)", n))
+				}
+				// The yanked paths no longer exist
+				if n > 0 && fileView.HasYanked() {
+					fileView.ClearYanked()
+				}
+				_ = fileView.LoadDirectory(fileView.GetCurrentPath())
+			})
+		})
+	}()
+}
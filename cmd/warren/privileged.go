@@ -0,0 +1,43 @@
+// Retrying operations that failed for lack of permission as root.
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// offerPrivilegedRetry asks whether to redo op, which failed with a
+// permission error, as administrator through polkit. Other failures are
+// left alone. Only that one operation runs as root. finish gets the result
+// on the GTK main thread, like the callback of the original operation.
+func offerPrivilegedRetry(window *gtk.ApplicationWindow, op *fileops.Operation, statusBar *ui.StatusBar, finish func(*fileops.Operation)) {
+	if !fileops.NeedsPrivileges(op) {
+		return
+	}
+
+	what := fmt.Sprintf("%d items", len(op.Source))
+	if len(op.Source) == 1 {
+		what = filepath.Base(op.Source[0])
+	}
+	verb := strings.ToLower(op.Type.String())
+	message := fmt.Sprintf("You don't have permission to %s %s:\n%v\n\nRetry as administrator? You will be asked to authenticate, and only this %s runs with administrator rights.", verb, what, op.Error, verb)
+
+	showQuestionDialog(window, "Permission Denied", message, "Retry as Administrator", func() {
+		statusBar.Info("Waiting for authentication…")
+		fileops.RetryPrivileged(op, func(retried *fileops.Operation) {
+			glib.IdleAdd(func() {
+				if retried.Status == fileops.StatusCancelled {
+					statusBar.Warn("Authentication cancelled")
+					return
+				}
+				finish(retried)
+			})
+		})
+	})
+}
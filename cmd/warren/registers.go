@@ -0,0 +1,197 @@
+// Yank registers: named sets of yanked files shared by all windows, the
+// register picker, hard linking yanked files, and keeping the ordinary
+// yank across restarts.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/registers"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// yankRegisters holds the named registers of every window. Its unnamed
+// register only carries the ordinary yank, which each pane keeps itself,
+// between sessions: see restoreYank and saveYank.
+var yankRegisters = registers.New("")
+
+// setupRegisters loads the yank saved by the last session.
+func setupRegisters() {
+	stateDir, err := config.StateDir()
+	if err != nil {
+		slog.Warn("Yank will not be kept across restarts", "err", err)
+		return
+	}
+	yankRegisters = registers.New(stateDir)
+	if err := yankRegisters.Load(); err != nil {
+		slog.Warn("Failed to load saved yank", "err", err)
+	}
+}
+
+// restoreYank gives the first window opened the yank saved by the last
+// session.
+func restoreYank(fileView *ui.FileView) {
+	if reg := yankRegisters.Get(registers.Unnamed); !reg.IsEmpty() {
+		fileView.SetYanked(reg.Paths, reg.Cut)
+		yankRegisters.Clear(registers.Unnamed)
+	}
+}
+
+// saveYank saves the yank of a closing window's focused pane for the next
+// session.
+func saveYank(fileView *ui.FileView) {
+	yankRegisters.Set(registers.Unnamed, registers.Register{Paths: fileView.GetYanked(), Cut: fileView.IsCut()})
+	if err := yankRegisters.Save(); err != nil {
+		slog.Warn("Failed to save yank", "err", err)
+	}
+}
+
+// register returns what a register holds. The unnamed register is the
+// focused pane's yank.
+func (s *actionState) register(name rune) registers.Register {
+	if name == registers.Unnamed {
+		fileView := s.active()
+		return registers.Register{Paths: fileView.GetYanked(), Cut: fileView.IsCut()}
+	}
+	return yankRegisters.Get(name)
+}
+
+// paste pastes a register into dir. The focused pane's own yank is
+// cleared once pasted, as is a named register whose files were cut and
+// have moved; a named register of copies can be pasted again.
+func (s *actionState) paste(name rune, dir string) {
+	fileView := s.active()
+	reg := s.register(name)
+	pasted := fileView.ClearYanked
+	if name != registers.Unnamed {
+		pasted = nil
+		if reg.Cut {
+			pasted = func() { yankRegisters.Clear(name) }
+		}
+	}
+	showPasteDialog(s.cfg, s.window, fileView, dir, reg.Paths, reg.Cut, pasted, s.statusBar, s.pathLabel, s.wmState)
+}
+
+// hardLink creates hard links to a register's files in dir, clearing the
+// focused pane's own yank once linked, as pasting does.
+func (s *actionState) hardLink(name rune, dir string) {
+	fileView := s.active()
+	reg := s.register(name)
+	fileops.HardLink(reg.Paths, dir, func(op *fileops.Operation) {
+		if op.GetStatus() == fileops.StatusRunning {
+			return
+		}
+		glib.IdleAdd(func() {
+			switch op.Status {
+			case fileops.StatusCompleted:
+				s.statusBar.Info(fmt.Sprintf("Linked %d file(s)", len(reg.Paths)))
+				if name == registers.Unnamed {
+					fileView.ClearYanked()
+				}
+				_ = fileView.LoadDirectory(fileView.GetCurrentPath())
+				updateStatusBar(s.statusBar, fileView)
+			case fileops.StatusFailed:
+				s.statusBar.Error(fmt.Sprintf("Failed to link: %v", op.Error))
+			default:
+				return
+			}
+			windowFor(s.window).reportOperation(op, dir)
+		})
+	})
+}
+
+// pasteHint explains why there is nothing to paste.
+func (s *actionState) pasteHint() string {
+	if name := s.ctl.PendingRegister(); name != registers.Unnamed {
+		return fmt.Sprintf("Register %s is empty", registers.Name(name))
+	}
+	return "No files yanked"
+}
+
+// showRegisters lists the registers holding files, with buttons to paste
+// or clear each. Typing a register's letter pastes it too.
+func showRegisters(s *actionState) {
+	names := yankRegisters.Names()
+	if !s.register(registers.Unnamed).IsEmpty() {
+		names = append([]rune{registers.Unnamed}, names...)
+	}
+	if len(names) == 0 {
+		s.statusBar.Info(fmt.Sprintf("No registers hold files; %s then a letter names one", s.cfg.Keybindings.Register))
+		return
+	}
+
+	win := gtk.NewWindow()
+	win.SetTitle("Registers")
+	win.SetTransientFor(&s.window.Window)
+	win.SetModal(true)
+	win.SetDefaultSize(520, -1)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 6)
+	box.SetMarginTop(12)
+	box.SetMarginBottom(12)
+	box.SetMarginStart(12)
+	box.SetMarginEnd(12)
+
+	paste := func(name rune) {
+		win.Close()
+		s.paste(name, s.active().GetCurrentPath())
+	}
+	for _, name := range names {
+		label := gtk.NewLabel(fmt.Sprintf("%s  %s", registers.Name(name), fileops.DisplayName(s.register(name).Summary())))
+		label.SetXAlign(0)
+		label.SetHExpand(true)
+		label.SetEllipsize(pango.EllipsizeEnd)
+
+		pasteButton := gtk.NewButtonWithLabel("Paste")
+		ui.SetAccessibleLabel(pasteButton, "Paste register "+string(name))
+		pasteButton.ConnectClicked(func() { paste(name) })
+
+		clearButton := gtk.NewButtonFromIconName("edit-clear-symbolic")
+		clearButton.SetTooltipText("Clear")
+		ui.SetAccessibleLabel(clearButton, "Clear register "+string(name))
+		clearButton.AddCSSClass("flat")
+
+		row := gtk.NewBox(gtk.OrientationHorizontal, 6)
+		row.Append(label)
+		row.Append(pasteButton)
+		row.Append(clearButton)
+		clearButton.ConnectClicked(func() {
+			if name == registers.Unnamed {
+				s.active().ClearYanked()
+				updateStatusBar(s.statusBar, s.active())
+			} else {
+				yankRegisters.Clear(name)
+			}
+			box.Remove(row)
+			if len(yankRegisters.Names()) == 0 && s.register(registers.Unnamed).IsEmpty() {
+				win.Close()
+			}
+		})
+		box.Append(row)
+	}
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval uint, _ uint, state gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			win.Close()
+			return true
+		}
+		ev := ui.KeyEvent(keyval, state)
+		if ev.Mods == 0 && registers.Valid(ev.Rune) && !s.register(ev.Rune).IsEmpty() {
+			paste(ev.Rune)
+			return true
+		}
+		return false
+	})
+	win.AddController(keys)
+
+	win.SetChild(box)
+	win.Present()
+}
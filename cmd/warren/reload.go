@@ -0,0 +1,172 @@
+// Live configuration reload.
+// config.toml is watched with the same FileWatcher the file view uses; when
+// it changes the new settings are applied to every open window. style.css
+// in the same directory is reloaded too. A config
+// that fails to parse is reported in the status bar and the previous
+// settings stay in effect; one that parses but fails config.Validate is
+// applied with a warning.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// loadedConfig holds the config file contents last applied or written by
+// Warren, so the watcher only reacts to outside edits. GTK thread only.
+var loadedConfig []byte
+
+// saveConfig writes cfg to config.toml without triggering a reload of
+// the same settings. Must be called on the GTK main thread.
+func saveConfig(cfg *config.Config) error {
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	// #nosec G304 -- path is derived from XDG spec
+	loadedConfig, err = os.ReadFile(path)
+	return err
+}
+
+// watchConfig starts watching the config directory and reloads cfg in
+// place when config.toml changes. Returns nil if there is nothing to watch.
+func watchConfig(cfg *config.Config) *fileops.FileWatcher {
+	path, err := config.Path()
+	if err != nil {
+		slog.Warn("Config reload disabled", "err", err)
+		return nil
+	}
+
+	// Editors often save by replacing the file, which drops a watch on the
+	// file itself, so watch its directory instead. Other files there (hooks,
+	// init.lua, workspace memory) also trigger events; comparing contents
+	// filters those out.
+	loadedConfig, _ = os.ReadFile(path) // #nosec G304 -- path is derived from XDG spec
+
+	watcher, err := fileops.NewFileWatcher(func() {
+		glib.IdleAdd(func() {
+			loadUserStyle()
+
+			// #nosec G304 -- path is derived from XDG spec
+			data, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				reportConfigError(err)
+				return
+			}
+			if bytes.Equal(data, loadedConfig) {
+				return
+			}
+			loadedConfig = data
+
+			next, err := config.Parse(data)
+			if err != nil {
+				reportConfigError(err)
+				return
+			}
+			applyConfig(cfg, next)
+		})
+	})
+	if err != nil {
+		slog.Warn("Config reload disabled", "err", err)
+		return nil
+	}
+
+	if err := watcher.Start(filepath.Dir(path)); err != nil {
+		// No config directory yet; nothing to reload until restart
+		slog.Warn("Config reload disabled", "err", err)
+		_ = watcher.Stop()
+		return nil
+	}
+	return watcher
+}
+
+// reportConfigError shows a config that could not be loaded in every window.
+func reportConfigError(err error) {
+	slog.Warn("Config not reloaded", "err", err)
+	for _, w := range windows {
+		w.statusBar.Error(fmt.Sprintf("Config not reloaded: %v", err))
+	}
+}
+
+// warnConfigProblems shows the result of config.Validate in the status bar.
+func warnConfigProblems(cfg *config.Config, statusBar *ui.StatusBar) {
+	errs := config.Validate(cfg)
+	switch len(errs) {
+	case 0:
+	case 1:
+		statusBar.Warn(fmt.Sprintf("Config: %v", errs[0]))
+	default:
+		statusBar.Warn(fmt.Sprintf("Config: %v (and %d more problems)", errs[0], len(errs)-1))
+	}
+}
+
+// applyConfig replaces the shared config with next and updates open
+// windows. Appearance and sort settings are only applied when they changed
+// in the file, so a sort picked at runtime survives unrelated edits.
+func applyConfig(cfg *config.Config, next *config.Config) {
+	prev := *cfg
+	*cfg = *next
+	applyTheme(cfg)
+	applyLogLevel(cfg)
+	fileops.SetRateLimit(cfg.General.IOLimitBytes())
+
+	for _, w := range windows {
+		w.applyConfig(prev)
+	}
+}
+
+// applyConfig updates the window for settings that differ from prev.
+// Must be called on the GTK main thread.
+func (w *appWindow) applyConfig(prev config.Config) {
+	cur := w.cfg.Appearance
+
+	for _, view := range w.panes.views {
+		if cur.ShowHidden != prev.Appearance.ShowHidden && cur.ShowHidden != view.ShowHidden() {
+			if err := view.ToggleHidden(); err != nil {
+				slog.Warn("Failed to apply show_hidden setting", "err", err)
+			}
+		}
+
+		if cur.DefaultSortMode != prev.Appearance.DefaultSortMode || cur.DefaultSortOrder != prev.Appearance.DefaultSortOrder {
+			view.SetSortMode(config.ParseSortMode(cur.DefaultSortMode), config.ParseSortOrder(cur.DefaultSortOrder))
+			if err := view.Refresh(); err != nil {
+				slog.Warn("Failed to apply sort setting", "err", err)
+			}
+		}
+		if err := view.SetHiddenLast(cur.HiddenLast); err != nil {
+			slog.Warn("Failed to apply hidden_last setting", "err", err)
+		}
+
+		applyDisplayFormats(view, w.cfg)
+		if err := view.SetItemCounts(w.cfg.General.CountItems); err != nil {
+			slog.Warn("Failed to apply count_items setting", "err", err)
+		}
+		view.SetWatchSubdirectories(w.cfg.General.WatchSubdirectories && w.cfg.General.CountItems)
+		view.SetPollInterval(w.cfg.General.PollDuration())
+	}
+	showSortMode(w.sortLabel, w.panes.active())
+
+	if w.cfg.Preview != prev.Preview {
+		applyPreviewConfig(w.preview, w.cfg)
+	}
+
+	if cur.WindowWidth != prev.Appearance.WindowWidth || cur.WindowHeight != prev.Appearance.WindowHeight {
+		w.window.SetDefaultSize(cur.WindowWidth, cur.WindowHeight)
+	}
+
+	w.reloadKeymap()
+	updateStatusBar(w.statusBar, w.panes.active())
+	w.statusBar.Info("Configuration reloaded")
+	warnConfigProblems(w.cfg, w.statusBar)
+}
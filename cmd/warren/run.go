@@ -0,0 +1,146 @@
+// Running executables from the file list, with their output in a window.
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/runner"
+	"github.com/lawrab/warren/internal/ui"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// runOutputLines caps the lines the output window keeps; older ones are
+// dropped so a chatty program can't use up memory.
+const runOutputLines = 10000
+
+// RunFile implements controller.Host.
+func (s *actionState) RunFile(file *models.FileInfo, args []string) {
+	showRunOutput(s.window, file, args, s.statusBar)
+}
+
+// showRunOutput runs file with args in its directory and shows what it
+// prints, stderr in red, as it arrives, then its exit status. Stop or
+// closing the window kills it.
+func showRunOutput(window *gtk.ApplicationWindow, file *models.FileInfo, args []string, statusBar *ui.StatusBar) {
+	argv := append([]string{file.Path}, args...)
+	commandLine := strings.Join(append([]string{file.Name}, args...), " ")
+
+	win := gtk.NewWindow()
+	win.SetTitle("Run " + fileops.DisplayName(file.Name))
+	win.SetTransientFor(&window.Window)
+	win.SetDefaultSize(800, 500)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 6)
+	box.SetMarginTop(12)
+	box.SetMarginBottom(12)
+	box.SetMarginStart(12)
+	box.SetMarginEnd(12)
+
+	header := gtk.NewLabel(fileops.DisplayName(commandLine))
+	header.SetXAlign(0)
+	header.SetSelectable(true)
+	header.AddCSSClass("dim-label")
+	box.Append(header)
+
+	textView := gtk.NewTextView()
+	textView.SetEditable(false)
+	textView.SetCursorVisible(false)
+	textView.SetMonospace(true)
+	ui.SetAccessibleLabel(textView, "Output of "+fileops.DisplayName(file.Name))
+	buffer := textView.Buffer()
+	stderrTag := gtk.NewTextTag("stderr")
+	stderrTag.SetObjectProperty("foreground", "#e01b24")
+	buffer.TagTable().Add(stderrTag)
+	// Right gravity keeps the mark after text inserted at the end
+	end := buffer.CreateMark("end", buffer.EndIter(), false)
+
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetVExpand(true)
+	scrolled.SetChild(textView)
+	box.Append(scrolled)
+
+	spinner := gtk.NewSpinner()
+	spinner.Start()
+	state := gtk.NewLabel("Running…")
+	state.SetXAlign(0)
+	state.SetHExpand(true)
+	stop := gtk.NewButtonWithLabel("Stop")
+	closeButton := gtk.NewButtonWithLabel("Close")
+	closeButton.ConnectClicked(win.Close)
+
+	footer := gtk.NewBox(gtk.OrientationHorizontal, 6)
+	footer.Append(spinner)
+	footer.Append(state)
+	footer.Append(stop)
+	footer.Append(closeButton)
+	box.Append(footer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop.ConnectClicked(cancel)
+	closed := false
+	win.ConnectCloseRequest(func() bool {
+		closed = true
+		cancel()
+		return false
+	})
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			win.Close()
+			return true
+		}
+		return false
+	})
+	win.AddController(keys)
+
+	appendLine := func(line runner.Line) {
+		if closed {
+			return
+		}
+		start := buffer.EndIter().Offset()
+		buffer.Insert(buffer.EndIter(), line.Text+"\n")
+		if line.Stderr {
+			buffer.ApplyTag(stderrTag, buffer.IterAtOffset(start), buffer.EndIter())
+		}
+		if extra := buffer.LineCount() - runOutputLines; extra > 0 {
+			cut, _ := buffer.IterAtLine(extra)
+			buffer.Delete(buffer.StartIter(), cut)
+		}
+		textView.ScrollToMark(end, 0, false, 0, 0)
+	}
+
+	finish := func(status int, err error) {
+		spinner.Stop()
+		stop.SetSensitive(false)
+		var message string
+		switch {
+		case err != nil && ctx.Err() != nil:
+			message = "Stopped"
+		case err != nil:
+			message = fmt.Sprintf("Failed to run: %v", err)
+		default:
+			message = fmt.Sprintf("Exited with status %d", status)
+		}
+		state.SetText(message)
+		statusBar.Info(fmt.Sprintf("%s: %s", file.Name, message))
+	}
+
+	go func() {
+		status, err := runner.Run(ctx, filepath.Dir(file.Path), argv, func(line runner.Line) {
+			glib.IdleAdd(func() { appendLine(line) })
+		})
+		glib.IdleAdd(func() { finish(status, err) })
+	}()
+
+	win.SetChild(box)
+	win.Present()
+	statusBar.Info(fmt.Sprintf("Running: %s", commandLine))
+}
@@ -0,0 +1,177 @@
+// Lua scripting wiring.
+// This file loads ~/.config/warren/init.lua and implements the script.Host
+// interface on top of the active window.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/script"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// scriptEngine holds commands and bindings from init.lua. It is nil when
+// the user has no init.lua or it failed to load.
+var scriptEngine *script.Engine
+
+// setupScripting loads the user's init.lua, if there is one.
+func setupScripting(app *gtk.Application) {
+	dir, err := config.Dir()
+	if err != nil {
+		slog.Warn("Scripting disabled", "err", err)
+		return
+	}
+
+	path := filepath.Join(dir, script.InitFile)
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+
+	engine := script.New(&scriptHost{app: app})
+	if err := engine.LoadFile(path); err != nil {
+		slog.Warn("Scripting disabled", "err", err)
+		engine.Close()
+		return
+	}
+
+	scriptEngine = engine
+	slog.Info("Loaded script commands", "count", len(engine.Commands()), "path", path)
+}
+
+// runScriptCommand runs an init.lua command, reporting errors in the
+// status bar.
+func runScriptCommand(name string, statusBar *ui.StatusBar) {
+	if scriptEngine == nil {
+		return
+	}
+	if err := scriptEngine.Run(name); err != nil {
+		statusBar.Error(err.Error())
+		slog.Warn("Script error", "err", err)
+	}
+}
+
+// scriptHost performs script API calls against the active window.
+type scriptHost struct {
+	app *gtk.Application
+}
+
+// window returns the active window or an error if none is open.
+func (h *scriptHost) window() (*appWindow, error) {
+	w := activeWindow(h.app)
+	if w == nil {
+		return nil, fmt.Errorf("no Warren window is open")
+	}
+	return w, nil
+}
+
+func (h *scriptHost) Cd(path string) error {
+	w, err := h.window()
+	if err != nil {
+		return err
+	}
+	dir, selectPath, err := fileops.ResolveTarget(path)
+	if err != nil {
+		return err
+	}
+	return w.navigate(dir, selectPath)
+}
+
+func (h *scriptHost) Up() error {
+	w, err := h.window()
+	if err != nil {
+		return err
+	}
+	current := w.fileView.GetCurrentPath()
+	return w.navigate(fileops.GetParentDir(current), current)
+}
+
+func (h *scriptHost) Pwd() string {
+	if w := activeWindow(h.app); w != nil {
+		return w.fileView.GetCurrentPath()
+	}
+	return ""
+}
+
+func (h *scriptHost) Reload() error {
+	w, err := h.window()
+	if err != nil {
+		return err
+	}
+	return w.navigate(w.fileView.GetCurrentPath(), w.fileView.GetSelectedPath())
+}
+
+func (h *scriptHost) Selected() string {
+	if w := activeWindow(h.app); w != nil {
+		return w.fileView.GetSelectedPath()
+	}
+	return ""
+}
+
+func (h *scriptHost) Select(path string) error {
+	w, err := h.window()
+	if err != nil {
+		return err
+	}
+	return w.selectEntry(path)
+}
+
+func (h *scriptHost) Copy(sources []string, dest string) error {
+	var opts fileops.TransferOptions
+	if w := activeWindow(h.app); w != nil {
+		opts.Symlinks = w.cfg.General.SymlinkPolicy()
+	}
+	return h.startOperation("Copied", func(cb fileops.ProgressCallback) *fileops.Operation {
+		return fileops.CopyMultipleWith(sources, dest, opts, cb)
+	})
+}
+
+func (h *scriptHost) Move(sources []string, dest string) error {
+	return h.startOperation("Moved", func(cb fileops.ProgressCallback) *fileops.Operation {
+		return fileops.MoveMultiple(sources, dest, cb)
+	})
+}
+
+func (h *scriptHost) Trash(paths []string) error {
+	return h.startOperation("Trashed", func(cb fileops.ProgressCallback) *fileops.Operation {
+		return fileops.TrashMultiple(paths, cb)
+	})
+}
+
+func (h *scriptHost) Status(message string) {
+	if w := activeWindow(h.app); w != nil {
+		w.statusBar.Info(message)
+	}
+}
+
+// startOperation runs a file operation in the background and reports the
+// outcome in the window's status bar, and as a toast if the user has moved
+// on. The file watcher refreshes the view.
+func (h *scriptHost) startOperation(verb string, start func(fileops.ProgressCallback) *fileops.Operation) error {
+	w, err := h.window()
+	if err != nil {
+		return err
+	}
+
+	startDir := w.fileView.GetCurrentPath()
+	start(func(op *fileops.Operation) {
+		glib.IdleAdd(func() {
+			switch op.Status {
+			case fileops.StatusCompleted:
+				w.statusBar.Info(fmt.Sprintf("%s %d item(s)", verb, len(op.Source)))
+			case fileops.StatusFailed:
+				w.statusBar.Error(fmt.Sprintf("%s failed: %v", op.Type, op.Error))
+			default:
+				return
+			}
+			w.reportOperation(op, startDir)
+		})
+	})
+	return nil
+}
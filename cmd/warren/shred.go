@@ -0,0 +1,79 @@
+// Secure delete: overwriting the selected file before deleting it.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/hooks"
+	"github.com/lawrab/warren/internal/ui"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// showShredDialog asks before shredding file. There is no setting to skip
+// the question, and it warns when the file is on a copy-on-write
+// filesystem, where shredding cannot reach older copies of the data.
+func showShredDialog(cfg *config.Config, window *gtk.ApplicationWindow, fileView *ui.FileView, file *models.FileInfo, statusBar *ui.StatusBar) {
+	if !cfg.General.SecureDelete {
+		statusBar.Warn("Secure delete is off; set secure_delete = true under [general] to enable it")
+		return
+	}
+
+	passes := cfg.General.ShredPasses
+	how := fmt.Sprintf("overwritten with random data %d time(s)", passes)
+	if passes == 0 {
+		how = "released from the disk (hole punched)"
+	}
+	message := fmt.Sprintf("Shred %s?\n\nIts contents will be %s, then it will be deleted:\n%s", fileops.DisplayName(file.Name), how, fileops.DisplayName(file.Path))
+	if fs := fileops.CopyOnWriteFilesystem(filepath.Dir(file.Path)); fs != "" {
+		message += fmt.Sprintf("\n\nThis is a %s filesystem, which writes changes to new blocks: the original data may survive on disk and in snapshots. Shredding here is best-effort.", fs)
+	}
+
+	showQuestionDialog(window, "Shred File", message, "Shred", func() {
+		shredFile(window, fileView, file, passes, statusBar)
+	})
+}
+
+// shredFile shreds a file in the background after the pre-delete hook.
+func shredFile(window *gtk.ApplicationWindow, fileView *ui.FileView, file *models.FileInfo, passes int, statusBar *ui.StatusBar) {
+	hctx := hooks.Context{
+		Dir:   fileView.GetCurrentPath(),
+		Path:  file.Path,
+		Files: []string{file.Path},
+	}
+
+	go func() {
+		// Shredding is a delete, so the pre-delete hook can veto it too
+		result, err := hookRunner.Run(hooks.PreDelete, hctx)
+		if err != nil {
+			slog.Warn("Hook failed", "err", err)
+		}
+		if result.Cancel {
+			glib.IdleAdd(func() {
+				statusBar.Warn(fmt.Sprintf("Shred cancelled: %s", result.Status))
+			})
+			return
+		}
+
+		fileops.Shred([]string{file.Path}, passes, func(op *fileops.Operation) {
+			glib.IdleAdd(func() {
+				switch op.Status {
+				case fileops.StatusCompleted:
+					statusBar.Info(fmt.Sprintf("Shredded: %s", file.Name))
+				case fileops.StatusFailed:
+					statusBar.Error(fmt.Sprintf("Failed to shred: %v", op.Error))
+				}
+				if fileView.GetCurrentPath() == hctx.Dir {
+					_ = fileView.LoadDirectory(hctx.Dir)
+					updateStatusBar(statusBar, fileView)
+				}
+				windowFor(window).reportOperation(op, hctx.Dir)
+			})
+		})
+	}()
+}
@@ -0,0 +1,145 @@
+// Theming: accent colour, row density, dark/light variant and user CSS.
+// The config stylesheet from internal/theme is layered over the built-in
+// CSS, and ~/.config/warren/style.css over both. With color_scheme =
+// "system" the variant follows the FreeDesktop settings portal.
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/theme"
+)
+
+const (
+	portalName      = "org.freedesktop.portal.Desktop"
+	portalPath      = "/org/freedesktop/portal/desktop"
+	portalSettings  = "org.freedesktop.portal.Settings"
+	appearanceNS    = "org.freedesktop.appearance"
+	colorSchemeKey  = "color-scheme"
+	portalTimeoutMs = 2000
+)
+
+// Theme state, owned by the GTK main thread.
+var (
+	themeProvider *gtk.CSSProvider // CSS generated from the config
+	userProvider  *gtk.CSSProvider // The user's style.css
+	userStyle     []byte           // style.css contents last loaded
+	userStyleSet  bool             // Whether userStyle has been loaded
+	portalScheme  = theme.PortalNoPreference
+)
+
+// setupTheme installs the config and user stylesheets and starts following
+// the desktop colour scheme. Must run after loadStyles so it layers on top.
+func setupTheme(app *gtk.Application, cfg *config.Config) {
+	display := gdk.DisplayGetDefault()
+
+	themeProvider = gtk.NewCSSProvider()
+	gtk.StyleContextAddProviderForDisplay(display, themeProvider, gtk.STYLE_PROVIDER_PRIORITY_APPLICATION+1)
+
+	userProvider = gtk.NewCSSProvider()
+	userProvider.ConnectParsingError(func(section *gtk.CSSSection, err error) {
+		slog.Warn("Invalid style.css", "section", section.String(), "err", err)
+	})
+	gtk.StyleContextAddProviderForDisplay(display, userProvider, gtk.STYLE_PROVIDER_PRIORITY_USER)
+
+	applyTheme(cfg)
+	loadUserStyle()
+	followPortalColorScheme(app, cfg)
+}
+
+// applyTheme regenerates the config stylesheet and picks the dark or
+// light variant. Invalid values fall back to defaults; config.Validate
+// reports them.
+func applyTheme(cfg *config.Config) {
+	if themeProvider == nil {
+		return
+	}
+
+	density, _ := theme.ParseDensity(cfg.Appearance.Density)
+	themeProvider.LoadFromString(theme.CSS(cfg.Appearance.AccentColor, density))
+
+	scheme, _ := theme.ParseScheme(cfg.Appearance.ColorScheme)
+	if settings := gtk.SettingsGetDefault(); settings != nil {
+		settings.SetObjectProperty("gtk-application-prefer-dark-theme", scheme.PrefersDark(portalScheme))
+	}
+}
+
+// loadUserStyle (re)loads style.css from the config directory if it
+// changed. A missing file clears any previously loaded user CSS.
+func loadUserStyle() {
+	if userProvider == nil {
+		return
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		return
+	}
+
+	// #nosec G304 -- path is derived from XDG spec
+	data, err := os.ReadFile(filepath.Join(dir, theme.UserStyleFile))
+	if err != nil && !os.IsNotExist(err) {
+		slog.Warn("Failed to read user styles", "file", theme.UserStyleFile, "err", err)
+		return
+	}
+	if userStyleSet && bytes.Equal(data, userStyle) {
+		return
+	}
+	userStyle, userStyleSet = data, true
+	userProvider.LoadFromString(string(data))
+}
+
+// followPortalColorScheme reads the desktop colour scheme from the
+// settings portal and re-applies the theme whenever it changes. Without a
+// portal the light variant is used unless the config says dark.
+func followPortalColorScheme(app *gtk.Application, cfg *config.Config) {
+	conn := app.DBusConnection()
+	if conn == nil {
+		return
+	}
+
+	conn.Call(context.Background(), portalName, portalPath, portalSettings, "Read",
+		glib.NewVariantTuple([]*glib.Variant{
+			glib.NewVariantString(appearanceNS),
+			glib.NewVariantString(colorSchemeKey),
+		}),
+		glib.NewVariantType("(v)"), gio.DBusCallFlagsNone, portalTimeoutMs,
+		func(res gio.AsyncResulter) {
+			reply, err := conn.CallFinish(res)
+			if err != nil {
+				slog.Info("Settings portal unavailable, not following system color scheme", "err", err)
+				return
+			}
+			portalScheme = unwrapUint32(reply.ChildValue(0))
+			applyTheme(cfg)
+		})
+
+	conn.SignalSubscribe(portalName, portalSettings, "SettingChanged", portalPath, appearanceNS, gio.DBusSignalFlagsNone,
+		func(_ *gio.DBusConnection, _, _, _, _ string, params *glib.Variant) {
+			if params.ChildValue(1).String() != colorSchemeKey {
+				return
+			}
+			portalScheme = unwrapUint32(params.ChildValue(2))
+			applyTheme(cfg)
+		})
+}
+
+// unwrapUint32 extracts a uint32 from a possibly nested variant. Older
+// portals wrap the value of Read in an extra variant.
+func unwrapUint32(v *glib.Variant) uint32 {
+	for v.TypeString() == "v" {
+		v = v.Variant()
+	}
+	if v.TypeString() != "u" {
+		return theme.PortalNoPreference
+	}
+	return v.Uint32()
+}
@@ -0,0 +1,88 @@
+// Restoring the size, maximized state, pane splits and what each pane
+// showed of the last window closed (appearance.remember_window).
+package main
+
+import (
+	"log/slog"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+)
+
+// loadWindowState returns the layout for a new window: the saved one if
+// remember_window is set, otherwise the configured size.
+func loadWindowState(cfg *config.Config) config.WindowState {
+	state := config.DefaultWindowState(cfg)
+	if !cfg.Appearance.RememberWindow {
+		return state
+	}
+	stateDir, err := config.StateDir()
+	if err != nil {
+		slog.Warn("Failed to find state directory", "err", err)
+		return state
+	}
+	state, err = config.LoadWindowState(stateDir, state)
+	if err != nil {
+		slog.Warn("Failed to load window state", "err", err)
+	}
+	return state
+}
+
+// saveWindowState records the window's layout for the next one opened.
+// The size is the unmaximized one, which GTK keeps as the default size.
+// Splits of panes that are hidden keep the values the window opened with,
+// as does a second pane that was never shown.
+func (w *appWindow) saveWindowState() {
+	if !w.cfg.Appearance.RememberWindow {
+		return
+	}
+
+	state := w.layout
+	state.Width, state.Height = w.window.DefaultSize()
+	state.Maximized = w.window.IsMaximized()
+	if w.preview.Visible() {
+		state.PreviewSplit = splitOf(w.previewPaned, state.PreviewSplit)
+	}
+	state.PaneSplit = w.panes.splitRatio()
+	for i, view := range w.panes.views {
+		if view.GetCurrentPath() != "" {
+			state.Panes[i] = view.State()
+		}
+	}
+
+	stateDir, err := config.StateDir()
+	if err == nil {
+		err = config.SaveWindowState(stateDir, state)
+	}
+	if err != nil {
+		slog.Warn("Failed to save window state", "err", err)
+	}
+}
+
+// setSplit moves paned's divider to split of its width, once the paned
+// has a width if it has not been allocated yet.
+func setSplit(paned *gtk.Paned, split float64) {
+	if width := paned.Width(); width > 0 {
+		paned.SetPosition(int(float64(width) * split))
+		return
+	}
+	paned.AddTickCallback(func(gtk.Widgetter, gdk.FrameClocker) bool {
+		width := paned.Width()
+		if width == 0 {
+			return true // Not laid out yet; try the next frame
+		}
+		paned.SetPosition(int(float64(width) * split))
+		return false
+	})
+}
+
+// splitOf returns the fraction of paned's width before its divider, or
+// fallback if the paned has no width.
+func splitOf(paned *gtk.Paned, fallback float64) float64 {
+	width := paned.Width()
+	if width == 0 {
+		return fallback
+	}
+	return float64(paned.Position()) / float64(width)
+}
@@ -0,0 +1,195 @@
+// The workspace memory dialog: every directory remembered per workspace,
+// to jump to, change, pin or forget.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/compositor"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/ui"
+)
+
+// showWorkspaceMemory lists the remembered directories, one row per
+// workspace (and monitor, with per_monitor_memory). Each row's directory
+// can be edited in place, Return saving it; the pin button pins it, Go
+// opens it in the focused pane and the clear button forgets it.
+func showWorkspaceMemory(s *actionState) {
+	if s.wmState == nil {
+		s.statusBar.Warn("Workspace integration is not active (needs Hyprland or Sway)")
+		return
+	}
+	memory := s.wmState.memory
+	if memory == nil {
+		s.statusBar.Warn("Workspace memory is off (workspace_memory under [hyprland])")
+		return
+	}
+	keys := memory.Workspaces()
+	if len(keys) == 0 {
+		s.statusBar.Info("No directories remembered yet")
+		return
+	}
+
+	win := gtk.NewWindow()
+	win.SetTitle("Workspace Memory")
+	win.SetTransientFor(&s.window.Window)
+	win.SetModal(true)
+	win.SetDefaultSize(600, 360)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 6)
+	box.SetMarginTop(12)
+	box.SetMarginBottom(12)
+	box.SetMarginStart(12)
+	box.SetMarginEnd(12)
+
+	save := func() {
+		if err := memory.Save(); err != nil {
+			slog.Warn("Failed to save workspace memory", "err", err)
+			s.statusBar.Error(fmt.Sprintf("Failed to save workspace memory: %v", err))
+		}
+	}
+
+	list := gtk.NewBox(gtk.OrientationVertical, 6)
+	rows := 0
+	for _, key := range keys {
+		workspace, monitor := compositor.SplitMemoryKey(key)
+		name := workspace
+		if monitor != "" {
+			name = fmt.Sprintf("%s on %s", workspace, monitor)
+		}
+		label := gtk.NewLabel(name)
+		label.SetXAlign(0)
+		label.SetWidthChars(12)
+
+		dir := memory.Get(key)
+		entry := gtk.NewEntry()
+		entry.SetText(fileops.DisplayName(dir))
+		entry.SetHExpand(true)
+		ui.SetAccessibleLabel(entry, "Directory for workspace "+name)
+		markMissing := func() {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				entry.AddCSSClass("warning")
+				entry.SetTooltipText("No longer exists")
+			} else {
+				entry.RemoveCSSClass("warning")
+				entry.SetTooltipText("")
+			}
+		}
+		markMissing()
+		entry.ConnectActivate(func() {
+			// The entry shows the name escaped, so unchanged text keeps
+			// names it can't show as they are
+			if entry.Text() == fileops.DisplayName(dir) {
+				return
+			}
+			edited, selectPath, err := fileops.ResolveTarget(entry.Text())
+			if err == nil && selectPath != "" {
+				err = fmt.Errorf("not a directory: %s", selectPath)
+			}
+			if err != nil {
+				entry.AddCSSClass("error")
+				s.statusBar.Error(err.Error())
+				return
+			}
+			entry.RemoveCSSClass("error")
+			dir = edited
+			entry.SetText(fileops.DisplayName(dir))
+			memory.Set(key, dir)
+			save()
+			markMissing()
+			s.statusBar.Info(fmt.Sprintf("Workspace %s now opens %s", name, fileops.DisplayName(dir)))
+		})
+
+		pin := gtk.NewToggleButton()
+		pin.SetIconName("view-pin-symbolic")
+		pin.SetTooltipText("Pin: always open this directory on the workspace")
+		ui.SetAccessibleLabel(pin, "Pin the directory of workspace "+name)
+		pin.AddCSSClass("flat")
+		pin.SetActive(memory.Pinned(key))
+		pin.ConnectToggled(func() {
+			memory.Pin(key, pin.Active())
+			save()
+		})
+
+		goButton := gtk.NewButtonWithLabel("Go")
+		ui.SetAccessibleLabel(goButton, "Go to the directory of workspace "+name)
+		goButton.ConnectClicked(func() {
+			win.Close()
+			goToDirectory(s, dir)
+		})
+
+		clearButton := gtk.NewButtonFromIconName("edit-clear-symbolic")
+		clearButton.SetTooltipText("Forget")
+		ui.SetAccessibleLabel(clearButton, "Forget the directory of workspace "+name)
+		clearButton.AddCSSClass("flat")
+
+		row := gtk.NewBox(gtk.OrientationHorizontal, 6)
+		row.Append(label)
+		row.Append(entry)
+		row.Append(pin)
+		row.Append(goButton)
+		row.Append(clearButton)
+		clearButton.ConnectClicked(func() {
+			memory.Clear(key)
+			save()
+			list.Remove(row)
+			if rows--; rows == 0 {
+				win.Close()
+			}
+		})
+		list.Append(row)
+		rows++
+	}
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetVExpand(true)
+	scrolled.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	scrolled.SetChild(list)
+	box.Append(scrolled)
+
+	buttons := gtk.NewBox(gtk.OrientationHorizontal, 6)
+	buttons.SetHAlign(gtk.AlignEnd)
+	clearAll := gtk.NewButtonWithLabel("Forget All")
+	clearAll.AddCSSClass("destructive-action")
+	closeButton := gtk.NewButtonWithLabel("Close")
+	buttons.Append(clearAll)
+	buttons.Append(closeButton)
+	box.Append(buttons)
+
+	closeButton.ConnectClicked(win.Close)
+	clearAll.ConnectClicked(func() {
+		memory.ClearAll()
+		save()
+		win.Close()
+		s.statusBar.Info("Forgot every workspace's directory")
+	})
+
+	keyController := gtk.NewEventControllerKey()
+	keyController.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			win.Close()
+			return true
+		}
+		return false
+	})
+	win.AddController(keyController)
+
+	win.SetChild(box)
+	win.Present()
+}
+
+// goToDirectory opens dir in the focused pane as navigating there would,
+// which remembers it for the current workspace (see directoryVisited).
+func goToDirectory(s *actionState, dir string) {
+	fileView := s.active()
+	if err := fileView.LoadDirectory(dir); err != nil {
+		s.statusBar.Error(fmt.Sprintf("Failed to load: %v", err))
+		return
+	}
+	s.pathLabel.SetText(fileops.DisplayName(fileView.GetCurrentPath()))
+	updateStatusBar(s.statusBar, fileView)
+	directoryVisited(s.wmState, fileView.GetCurrentPath(), s.statusBar)
+}
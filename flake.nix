@@ -94,7 +94,9 @@
           version = "0.1.0-dev";
           src = ./.;
 
-          vendorHash = null; # Will need to be updated after go mod vendor
+          # Hash of the Go module dependencies. After changing go.mod, build
+          # once: the error reports the new hash to paste in here.
+          vendorHash = pkgs.lib.fakeHash;
 
           nativeBuildInputs = with pkgs; [
             pkg-config
@@ -106,6 +108,17 @@
             glib
           ];
 
+          # Desktop entry registers Warren as a handler for inode/directory;
+          # the D-Bus service file lets "Show in folder" start Warren on demand
+          postInstall = ''
+            install -Dm644 data/com.lawrab.warren.desktop \
+              $out/share/applications/com.lawrab.warren.desktop
+            install -Dm644 data/org.freedesktop.FileManager1.service \
+              $out/share/dbus-1/services/org.freedesktop.FileManager1.service
+            substituteInPlace $out/share/dbus-1/services/org.freedesktop.FileManager1.service \
+              --replace-fail "Exec=warren" "Exec=$out/bin/warren"
+          '';
+
           # Build flags
           ldflags = [
             "-s"
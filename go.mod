@@ -2,12 +2,15 @@ module github.com/lawrab/warren
 
 go 1.25.1
 
-require github.com/diamondburned/gotk4/pkg v0.3.1
+require (
+	github.com/diamondburned/gotk4/pkg v0.3.1
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/yuin/gopher-lua v1.1.2
+)
 
 require (
 	github.com/KarpelesLab/weak v0.1.1 // indirect
-	github.com/fsnotify/fsnotify v1.9.0 // indirect
-	github.com/pelletier/go-toml/v2 v2.2.4 // indirect
 	go4.org/unsafe/assume-no-moving-gc v0.0.0-20231121144256-b99613f794b6 // indirect
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
 	golang.org/x/sys v0.13.0 // indirect
@@ -0,0 +1,79 @@
+package bookmarks
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bookmark is a bookmarked directory.
+type Bookmark struct {
+	Name string // The label, or the directory's base name without one
+	Path string
+}
+
+// Path returns the bookmarks file, under $XDG_CONFIG_HOME or ~/.config.
+func Path() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "gtk-3.0", "bookmarks"), nil
+}
+
+// Load reads the bookmarks file. A missing file means no bookmarks.
+func Load() ([]Bookmark, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304 -- the path is GTK's bookmarks file
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data), nil
+}
+
+// Parse reads bookmarks in the GTK format, skipping lines that aren't
+// local file URIs.
+func Parse(data []byte) []Bookmark {
+	var bookmarks []Bookmark
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		uri, label, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		u, err := url.Parse(uri)
+		if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") || u.Path == "" {
+			continue
+		}
+		path := filepath.Clean(u.Path)
+		name := strings.TrimSpace(label)
+		if name == "" {
+			name = filepath.Base(path)
+		}
+		bookmarks = append(bookmarks, Bookmark{Name: name, Path: path})
+	}
+	return bookmarks
+}
+
+// Find returns the path of the bookmark called name.
+func Find(bookmarks []Bookmark, name string) (string, bool) {
+	for _, b := range bookmarks {
+		if b.Name == name {
+			return b.Path, true
+		}
+	}
+	return "", false
+}
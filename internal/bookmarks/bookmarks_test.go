@@ -0,0 +1,61 @@
+package bookmarks
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`file:///home/user/Documents
+file:///home/user/My%20Photos Photos
+file:///mnt/backup/ Backup drive
+sftp://server/srv Server
+file://otherhost/share
+
+`)
+	want := []Bookmark{
+		{Name: "Documents", Path: "/home/user/Documents"},
+		{Name: "Photos", Path: "/home/user/My Photos"},
+		{Name: "Backup drive", Path: "/mnt/backup"},
+	}
+	if got := Parse(data); !slices.Equal(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFind(t *testing.T) {
+	bookmarks := []Bookmark{{Name: "Photos", Path: "/p"}}
+	if path, ok := Find(bookmarks, "Photos"); !ok || path != "/p" {
+		t.Errorf("Find(Photos) = %q, %v", path, ok)
+	}
+	if _, ok := Find(bookmarks, "photos"); ok {
+		t.Error("Find matched a name in a different case")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	bookmarks, err := Load()
+	if err != nil || len(bookmarks) != 0 {
+		t.Fatalf("Load() without a file = %v, %v", bookmarks, err)
+	}
+
+	dir := filepath.Join(configHome, "gtk-3.0")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bookmarks"), []byte("file:///tmp Temp\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	bookmarks, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []Bookmark{{Name: "Temp", Path: "/tmp"}}; !slices.Equal(bookmarks, want) {
+		t.Errorf("Load() = %+v, want %+v", bookmarks, want)
+	}
+}
@@ -0,0 +1,9 @@
+// Package bookmarks reads the GTK bookmarks file, the folders listed in
+// the sidebar of GTK file choosers and Files, so Warren offers the same
+// places as paste destinations.
+//
+// The file is $XDG_CONFIG_HOME/gtk-3.0/bookmarks, which GTK 4 still uses:
+// one file:// URI per line, optionally followed by a space and a label.
+// Other URIs (sftp://, smb://) are skipped, since Warren works on local
+// paths. The package has no GTK dependency.
+package bookmarks
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK      = 0 // Operation completed
+	ExitFailure = 1 // Operation failed or was cancelled
+	ExitUsage   = 2 // Invalid arguments
+)
+
+// progressInterval limits how often progress lines are redrawn.
+const progressInterval = 100 * time.Millisecond
+
+// command describes a headless subcommand.
+type command struct {
+	usage   string
+	minArgs int
+	start   func(args []string) (*fileops.Operation, error)
+}
+
+var commands = map[string]command{
+	"cp": {
+		usage:   "cp SOURCE... DEST",
+		minArgs: 2,
+		start: func(args []string) (*fileops.Operation, error) {
+			sources, dest := args[:len(args)-1], args[len(args)-1]
+			if isDir(dest) {
+				return fileops.CopyMultiple(sources, dest, nil), nil
+			}
+			if len(sources) > 1 {
+				return nil, fmt.Errorf("target %s is not a directory", dest)
+			}
+			return fileops.Copy(sources[0], dest, nil), nil
+		},
+	},
+	"mv": {
+		usage:   "mv SOURCE... DEST",
+		minArgs: 2,
+		start: func(args []string) (*fileops.Operation, error) {
+			sources, dest := args[:len(args)-1], args[len(args)-1]
+			if isDir(dest) {
+				return fileops.MoveMultiple(sources, dest, nil), nil
+			}
+			if len(sources) > 1 {
+				return nil, fmt.Errorf("target %s is not a directory", dest)
+			}
+			return fileops.Move(sources[0], dest, nil), nil
+		},
+	},
+	"rm": {
+		usage:   "rm PATH...",
+		minArgs: 1,
+		start: func(args []string) (*fileops.Operation, error) {
+			return fileops.DeleteMultiple(args, nil), nil
+		},
+	},
+	"trash": {
+		usage:   "trash PATH...",
+		minArgs: 1,
+		start: func(args []string) (*fileops.Operation, error) {
+			return fileops.TrashMultiple(args, nil), nil
+		},
+	},
+}
+
+// IsCommand reports whether name is a headless subcommand.
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run executes the subcommand named by args[0] and returns the exit code.
+// Progress and errors are written to stderr. An interrupt (Ctrl+C)
+// cancels the running operation.
+func Run(args []string, stderr io.Writer) int {
+	if len(args) == 0 || !IsCommand(args[0]) {
+		_, _ = fmt.Fprintln(stderr, "usage: warren {cp|mv|rm|trash} [-q] ARGS...")
+		return ExitUsage
+	}
+
+	name, cmd := args[0], commands[args[0]]
+	flags := flag.NewFlagSet("warren "+name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	quiet := flags.Bool("q", false, "Don't print progress")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "usage: warren %s\n", cmd.usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() < cmd.minArgs {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	op, err := cmd.start(flags.Args())
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "warren %s: %v\n", name, err)
+		return ExitFailure
+	}
+	reporter := newProgressReporter(stderr, *quiet)
+	op.Subscribe(reporter.event)
+
+	// Cancel the operation on Ctrl+C. Its worker stops at the next file
+	// or chunk, but Run returns at once, so what it was writing may be
+	// left incomplete; the message says where
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	select {
+	case <-reporter.done:
+	case <-interrupt:
+		op.Cancel()
+		<-reporter.done
+	}
+
+	switch op.GetStatus() {
+	case fileops.StatusCompleted:
+		return ExitOK
+	case fileops.StatusCancelled:
+		_, _ = fmt.Fprintf(stderr, "warren %s: cancelled%s\n", name, partialTarget(op))
+	default:
+		_, _ = fmt.Fprintf(stderr, "warren %s: %v\n", name, op.Error)
+	}
+	return ExitFailure
+}
+
+// partialTarget says where a cancelled copy or move may have left an
+// incomplete file, or returns "" for other operations.
+func partialTarget(op *fileops.Operation) string {
+	if op.Type != fileops.OpCopy && op.Type != fileops.OpMove {
+		return ""
+	}
+	return fmt.Sprintf("; %s may be incomplete", op.Destination)
+}
+
+// progressReporter prints throttled progress lines and signals completion.
+type progressReporter struct {
+	out       io.Writer
+	quiet     bool
+	done      chan struct{}
+	once      sync.Once
+	mu        sync.Mutex
+	lastPrint time.Time
+	printed   bool
+}
+
+func newProgressReporter(out io.Writer, quiet bool) *progressReporter {
+	return &progressReporter{
+		out:   out,
+		quiet: quiet,
+		done:  make(chan struct{}),
+	}
+}
+
+// event handles the events of the operation being run.
+func (r *progressReporter) event(ev fileops.Event) {
+	finished := ev.Type == fileops.EventFinished
+	if ev.Type != fileops.EventProgress && !finished {
+		return
+	}
+	op := ev.Op
+
+	if !r.quiet {
+		r.mu.Lock()
+		if finished || time.Since(r.lastPrint) >= progressInterval {
+			r.lastPrint = time.Now()
+			r.print(op, finished)
+		}
+		r.mu.Unlock()
+	}
+
+	if finished {
+		r.once.Do(func() { close(r.done) })
+	}
+}
+
+// print writes a single progress line, redrawn in place with \r.
+func (r *progressReporter) print(op *fileops.Operation, finished bool) {
+	progress, processed, total, current := op.GetProgress()
+	if finished && !r.printed {
+		return // nothing was drawn, so there is no line to finish
+	}
+
+	label := op.Type.String()
+	phase := op.GetPhase()
+	if phase != fileops.PhaseTransfer {
+		label += " (" + phase.String() + ")"
+	}
+	line := fmt.Sprintf("%s: %3.0f%%", label, progress*100)
+	switch {
+	case phase == fileops.PhaseRemoving || op.Type == fileops.OpDelete:
+		line += fmt.Sprintf(" (%d / %d items)", processed, total)
+	case op.Type == fileops.OpCopy || phase == fileops.PhaseCopying:
+		line += fmt.Sprintf(" (%s / %s)", fileops.FormatSize(processed), fileops.FormatSize(total))
+	}
+	if current != "" {
+		line += " " + filepath.Base(current)
+	}
+
+	_, _ = fmt.Fprintf(r.out, "\r\033[K%s", line)
+	r.printed = true
+	if finished {
+		_, _ = fmt.Fprintln(r.out)
+	}
+}
+
+// isDir reports whether path exists and is a directory.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
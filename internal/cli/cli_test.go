@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	for _, name := range []string{"cp", "mv", "rm", "trash"} {
+		if !IsCommand(name) {
+			t.Errorf("IsCommand(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"", "ls", "/tmp", "--version"} {
+		if IsCommand(name) {
+			t.Errorf("IsCommand(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestRunCopy(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "a.txt")
+	writeFile(t, src, "hello")
+
+	t.Run("to new name", func(t *testing.T) {
+		var stderr bytes.Buffer
+		dst := filepath.Join(tmpDir, "b.txt")
+		if code := Run([]string{"cp", src, dst}, &stderr); code != ExitOK {
+			t.Fatalf("Run cp = %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+		}
+		if data, _ := os.ReadFile(dst); string(data) != "hello" { //nolint:gosec // Test path
+			t.Errorf("Copied content = %q, want hello", data)
+		}
+	})
+
+	t.Run("into directory", func(t *testing.T) {
+		var stderr bytes.Buffer
+		dstDir := filepath.Join(tmpDir, "dir")
+		if err := os.Mkdir(dstDir, 0750); err != nil {
+			t.Fatal(err)
+		}
+		if code := Run([]string{"cp", "-q", src, dstDir}, &stderr); code != ExitOK {
+			t.Fatalf("Run cp = %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+		}
+		if _, err := os.Stat(filepath.Join(dstDir, "a.txt")); err != nil {
+			t.Errorf("Expected a.txt in destination: %v", err)
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("Expected no output with -q, got %q", stderr.String())
+		}
+	})
+
+	t.Run("multiple sources need directory", func(t *testing.T) {
+		var stderr bytes.Buffer
+		code := Run([]string{"cp", src, src, filepath.Join(tmpDir, "nope")}, &stderr)
+		if code != ExitFailure {
+			t.Errorf("Run cp = %d, want %d", code, ExitFailure)
+		}
+		if !strings.Contains(stderr.String(), "not a directory") {
+			t.Errorf("Expected 'not a directory' error, got %q", stderr.String())
+		}
+	})
+}
+
+func TestRunMove(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "a.txt")
+	dst := filepath.Join(tmpDir, "b.txt")
+	writeFile(t, src, "hello")
+
+	var stderr bytes.Buffer
+	if code := Run([]string{"mv", src, dst}, &stderr); code != ExitOK {
+		t.Fatalf("Run mv = %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("Source should not exist after move")
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Errorf("Destination should exist after move: %v", err)
+	}
+}
+
+func TestRunRemove(t *testing.T) {
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a.txt")
+	b := filepath.Join(tmpDir, "b.txt")
+	writeFile(t, a, "a")
+	writeFile(t, b, "b")
+
+	var stderr bytes.Buffer
+	if code := Run([]string{"rm", a, b}, &stderr); code != ExitOK {
+		t.Fatalf("Run rm = %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+	}
+	for _, path := range []string{a, b} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should have been removed", path)
+		}
+	}
+}
+
+func TestRunTrash(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+	file := filepath.Join(tmpDir, "a.txt")
+	writeFile(t, file, "a")
+
+	var stderr bytes.Buffer
+	if code := Run([]string{"trash", file}, &stderr); code != ExitOK {
+		t.Fatalf("Run trash = %d, want %d (stderr: %s)", code, ExitOK, stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "data", "Trash", "files", "a.txt")); err != nil {
+		t.Errorf("Expected file in trash: %v", err)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	var stderr bytes.Buffer
+	code := Run([]string{"rm", "/nonexistent/warren/path"}, &stderr)
+	// os.RemoveAll succeeds on missing paths, matching rm -f
+	if code != ExitOK {
+		t.Errorf("Run rm missing = %d, want %d", code, ExitOK)
+	}
+
+	stderr.Reset()
+	code = Run([]string{"cp", "/nonexistent/warren/path", t.TempDir()}, &stderr)
+	if code != ExitFailure {
+		t.Errorf("Run cp missing = %d, want %d", code, ExitFailure)
+	}
+	if stderr.Len() == 0 {
+		t.Error("Expected an error message on stderr")
+	}
+}
+
+func TestPartialTarget(t *testing.T) {
+	cp := fileops.NewOperation(fileops.OpCopy, []string{"/src/a"}, "/dst")
+	if got := partialTarget(cp); !strings.Contains(got, "/dst") {
+		t.Errorf("partialTarget(cp) = %q, want it to name /dst", got)
+	}
+	rm := fileops.NewOperation(fileops.OpDelete, []string{"/src/a"}, "")
+	if got := partialTarget(rm); got != "" {
+		t.Errorf("partialTarget(rm) = %q, want \"\"", got)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	tests := [][]string{
+		nil,
+		{"ls"},
+		{"cp", "only-one"},
+		{"rm"},
+		{"mv", "-bogus", "a", "b"},
+	}
+
+	for _, args := range tests {
+		var stderr bytes.Buffer
+		if code := Run(args, &stderr); code != ExitUsage {
+			t.Errorf("Run(%v) = %d, want %d", args, code, ExitUsage)
+		}
+		if !strings.Contains(stderr.String(), "usage") {
+			t.Errorf("Run(%v) should print usage, got %q", args, stderr.String())
+		}
+	}
+}
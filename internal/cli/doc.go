@@ -0,0 +1,7 @@
+// Package cli implements Warren's headless subcommands.
+//
+// Subcommands such as "warren cp" and "warren rm" reuse the operations in
+// internal/fileops without initializing GTK, so the same copy/move/delete
+// code paths can be driven from shell scripts. Progress is written to
+// stderr and the exit status reports success or failure.
+package cli
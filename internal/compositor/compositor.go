@@ -0,0 +1,97 @@
+package compositor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lawrab/warren/internal/hyprland"
+	"github.com/lawrab/warren/internal/sway"
+)
+
+// ErrNotDetected is returned by Detect outside a supported compositor.
+var ErrNotDetected = errors.New("no supported compositor (neither HYPRLAND_INSTANCE_SIGNATURE nor SWAYSOCK is set)")
+
+// Compositor is the window manager side of Warren's workspace
+// integration. Workspaces are identified by name throughout.
+type Compositor interface {
+	// Name returns the compositor's display name, e.g. "Hyprland".
+	Name() string
+
+	// ActiveWorkspace returns the focused workspace.
+	ActiveWorkspace() (string, error)
+
+	// WindowWorkspace returns the workspace holding the window owned by
+	// pid, preferring the most recently focused one if there are several.
+	WindowWorkspace(pid int) (string, error)
+
+	// MonitorOf returns the name of the monitor (output) showing workspace.
+	MonitorOf(workspace string) (string, error)
+
+	// FocusWorkspace switches to workspace, creating it if needed.
+	FocusWorkspace(workspace string) error
+
+	// MoveToWorkspace moves the window owned by pid to workspace and
+	// follows it there.
+	MoveToWorkspace(workspace string, pid int) error
+
+	// ExecOn switches to workspace and launches argv there.
+	ExecOn(workspace string, argv ...string) error
+
+	// FocusWindow focuses the most recently used window, other than
+	// Warren's own, for which match(title, class) is true, and reports
+	// whether there was one.
+	FocusWindow(match func(title, class string) bool) (bool, error)
+
+	// FocusNextWindow focuses the next window to open. It subscribes
+	// before returning, so a launch started afterwards is seen. The
+	// outcome arrives on the channel: nil once a window was focused, or
+	// ctx's error if none opened in time.
+	FocusNextWindow(ctx context.Context) (<-chan error, error)
+
+	// WorkspaceChanges delivers the name of the workspace Warren is now
+	// on whenever the user switches workspace or Warren's window moves,
+	// until ctx is cancelled, when the channel is closed.
+	WorkspaceChanges(ctx context.Context) (<-chan string, error)
+}
+
+var (
+	_ Compositor = (*hyprland.Client)(nil)
+	_ Compositor = (*sway.Client)(nil)
+)
+
+// Detect connects to the compositor Warren is running under, trying
+// Hyprland first and then Sway.
+func Detect() (Compositor, error) {
+	// Backends are returned only on success, so a failed connection is a
+	// nil Compositor rather than one holding a nil client
+	switch {
+	case hyprland.IsHyprland():
+		client, err := hyprland.New()
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	case sway.IsSway():
+		client, err := sway.New()
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, ErrNotDetected
+	}
+}
+
+// Detected returns the display name of the compositor Warren is running
+// under, judging by its environment without connecting, or "" if it is
+// not a supported one.
+func Detected() string {
+	switch {
+	case hyprland.IsHyprland():
+		return "Hyprland"
+	case sway.IsSway():
+		return "Sway"
+	default:
+		return ""
+	}
+}
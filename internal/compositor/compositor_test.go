@@ -0,0 +1,64 @@
+package compositor
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.sock")
+
+	tests := []struct {
+		name     string
+		hyprland string
+		swaysock string
+		wantErr  string // Substring naming the backend that was tried
+	}{
+		{"neither", "", "", "no supported compositor"},
+		{"hyprland without socket", "abc123", "", "hyprland"},
+		{"sway without socket", "", missing, "sway"},
+		{"hyprland wins over sway", "abc123", missing, "hyprland"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", tt.hyprland)
+			t.Setenv("SWAYSOCK", tt.swaysock)
+			t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+			wm, err := Detect()
+			if err == nil {
+				t.Fatalf("Detect() = %v, want an error without a socket", wm)
+			}
+			if wm != nil {
+				t.Errorf("Detect() returned %v alongside an error", wm)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Detect() error = %q, want it to mention %q", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotDetected) != (tt.hyprland == "" && tt.swaysock == "") {
+				t.Errorf("Detect() error = %v, ErrNotDetected only without either variable", err)
+			}
+		})
+	}
+}
+
+func TestDetected(t *testing.T) {
+	tests := []struct {
+		hyprland, swaysock, want string
+	}{
+		{"", "", ""},
+		{"abc123", "", "Hyprland"},
+		{"", "/run/sway.sock", "Sway"},
+		{"abc123", "/run/sway.sock", "Hyprland"},
+	}
+	for _, tt := range tests {
+		t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", tt.hyprland)
+		t.Setenv("SWAYSOCK", tt.swaysock)
+		if got := Detected(); got != tt.want {
+			t.Errorf("Detected() with %q, %q = %q, want %q", tt.hyprland, tt.swaysock, got, tt.want)
+		}
+	}
+}
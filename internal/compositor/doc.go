@@ -0,0 +1,20 @@
+// Package compositor abstracts the Wayland compositors Warren integrates
+// with, so workspace memory and auto-switching work the same on each.
+//
+// Detect picks a backend from the environment: Hyprland when
+// HYPRLAND_INSTANCE_SIGNATURE is set (package hyprland), Sway when
+// SWAYSOCK is set (package sway). Both implement Compositor.
+//
+// Basic usage:
+//
+//	wm, err := compositor.Detect()
+//	if err != nil {
+//	    // No supported compositor; run without workspace integration
+//	    return
+//	}
+//
+//	workspace, err := wm.ActiveWorkspace()
+//
+// The package also holds WorkspaceMemory, the per-workspace directory
+// memory persisted in the Warren config directory.
+package compositor
@@ -0,0 +1,77 @@
+package compositor
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// FileWindowMatcher returns a FocusWindow matcher for windows likely to
+// be showing path: the title must contain the file name as a whole word
+// (so "notes.txt" does not match "old-notes.txt"). If appID, the desktop
+// ID of the file's default application (e.g. "org.gnome.Evince"), is
+// known, the window class must belong to that application too.
+func FileWindowMatcher(path, appID string) func(title, class string) bool {
+	name := filepath.Base(path)
+	return func(title, class string) bool {
+		if !containsWord(title, name) {
+			return false
+		}
+		return appID == "" || classMatches(class, appID)
+	}
+}
+
+// containsWord reports whether s contains word, not as part of a longer
+// file name.
+func containsWord(s, word string) bool {
+	if word == "" {
+		return false
+	}
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(word)
+		if !nameRune(lastRune(s[:start])) && !nameRune(firstRune(s[end:])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// nameRune reports whether r can continue a file name, so a match next
+// to it is only part of a longer name.
+func nameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-'
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+func lastRune(s string) rune {
+	r := rune(0)
+	for _, c := range s {
+		r = c
+	}
+	return r
+}
+
+// classMatches reports whether a window class or app ID belongs to the
+// application with desktop ID appID. Classes are often the full ID
+// ("org.gnome.Evince") or its last part in any case ("evince").
+func classMatches(class, appID string) bool {
+	if class == "" {
+		return false
+	}
+	if strings.EqualFold(class, appID) {
+		return true
+	}
+	short := appID[strings.LastIndex(appID, ".")+1:]
+	return strings.EqualFold(class, short)
+}
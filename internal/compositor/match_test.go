@@ -0,0 +1,34 @@
+package compositor
+
+import "testing"
+
+func TestFileWindowMatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		appID string
+		title string
+		class string
+		want  bool
+	}{
+		{"title contains name", "/home/u/notes.txt", "", "notes.txt - gedit", "gedit", true},
+		{"title with path", "/home/u/notes.txt", "", "~/notes.txt (NORMAL) - NVIM", "kitty", true},
+		{"longer name before", "/home/u/notes.txt", "", "old-notes.txt - gedit", "gedit", false},
+		{"longer name after", "/home/u/notes.txt", "", "notes.txt.bak - gedit", "gedit", false},
+		{"second occurrence matches", "/home/u/a.pdf", "", "aa.pdf, a.pdf", "evince", true},
+		{"other file", "/home/u/notes.txt", "", "todo.txt - gedit", "gedit", false},
+		{"app full id", "/home/u/paper.pdf", "org.gnome.Evince", "paper.pdf", "org.gnome.Evince", true},
+		{"app short id", "/home/u/paper.pdf", "org.gnome.Evince", "paper.pdf — Document Viewer", "evince", true},
+		{"other app showing the name", "/home/u/paper.pdf", "org.gnome.Evince", "paper.pdf - Firefox", "firefox", false},
+		{"no class", "/home/u/paper.pdf", "org.gnome.Evince", "paper.pdf", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := FileWindowMatcher(tt.path, tt.appID)
+			if got := match(tt.title, tt.class); got != tt.want {
+				t.Errorf("match(%q, %q) = %v, want %v", tt.title, tt.class, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package compositor
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// WorkspaceMemory tracks the last directory accessed per workspace.
+// This allows Warren to remember and restore the directory when switching workspaces.
+// Workspaces are keyed by name, so named ("code") and special
+// ("special:files") workspaces each get their own slot; numbered
+// workspaces are named after their ID ("2").
+//
+// A workspace can be pinned to its directory, so it always opens there:
+// Remember, which records where Warren was, leaves pinned ones alone.
+//
+// It also records the workspace each file type was last opened on, for
+// opening files where their application usually lives.
+type WorkspaceMemory struct {
+	workspaceDirs  map[string]string
+	pinned         map[string]bool   // Workspaces Remember doesn't change
+	typeWorkspaces map[string]string // File extension -> workspace name
+	mu             sync.RWMutex
+	path           string // Path to save/load memory
+}
+
+// MemoryKey returns the memory key for a workspace on a monitor, for
+// setups that remember directories per (monitor, workspace) pair rather
+// than per workspace. An empty monitor gives the plain workspace key.
+func MemoryKey(workspace, monitor string) string {
+	if monitor == "" {
+		return workspace
+	}
+	return workspace + "@" + monitor
+}
+
+// SessionKey returns the memory key used without a compositor, when
+// Warren can't tell workspaces apart and remembers one directory per
+// session instead. The session is named by $WARREN_SESSION, so launchers
+// can keep several apart, or else by the desktop ($XDG_SESSION_DESKTOP,
+// $XDG_CURRENT_DESKTOP): "session:gnome".
+func SessionKey() string {
+	for _, env := range []string{"WARREN_SESSION", "XDG_SESSION_DESKTOP", "XDG_CURRENT_DESKTOP"} {
+		if tag := os.Getenv(env); tag != "" {
+			return "session:" + tag
+		}
+	}
+	return "session:default"
+}
+
+// SplitMemoryKey undoes MemoryKey, returning the workspace and the
+// monitor, which is "" for a plain workspace key.
+func SplitMemoryKey(key string) (workspace, monitor string) {
+	if i := strings.LastIndex(key, "@"); i > 0 {
+		return key[:i], key[i+1:]
+	}
+	return key, ""
+}
+
+// MemoryFile is the memory's file name in the state directory. It
+// predates Sway support and is kept so existing memory still loads.
+// Releases before config.StateDir kept it in the config directory, which
+// config.MigrateState moves it out of.
+const MemoryFile = "hyprland-memory.json"
+
+// memoryData is the structure saved to disk.
+type memoryData struct {
+	WorkspaceDirs  map[string]string `json:"workspace_dirs"`
+	Pinned         []string          `json:"pinned,omitempty"`
+	TypeWorkspaces map[string]string `json:"type_workspaces,omitempty"`
+}
+
+// NewWorkspaceMemory creates a new workspace memory tracker saved in
+// stateDir. If stateDir is empty, uses
+// ~/.local/state/warren/hyprland-memory.json
+func NewWorkspaceMemory(stateDir string) (*WorkspaceMemory, error) {
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		stateDir = filepath.Join(home, ".local", "state", "warren")
+	}
+
+	// Ensure state directory exists
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(stateDir, MemoryFile)
+
+	wm := &WorkspaceMemory{
+		workspaceDirs:  make(map[string]string),
+		pinned:         make(map[string]bool),
+		typeWorkspaces: make(map[string]string),
+		path:           path,
+	}
+
+	// Load existing memory if file exists (ignore if file doesn't exist)
+	_ = wm.Load()
+
+	return wm, nil
+}
+
+// Set saves the directory for a workspace.
+func (wm *WorkspaceMemory) Set(workspace string, directory string) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	wm.workspaceDirs[workspace] = directory
+}
+
+// Remember records the directory Warren is in on a workspace, like Set,
+// unless the workspace is pinned. It reports whether it changed anything.
+func (wm *WorkspaceMemory) Remember(workspace string, directory string) bool {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	if wm.pinned[workspace] || wm.workspaceDirs[workspace] == directory {
+		return false
+	}
+	wm.workspaceDirs[workspace] = directory
+	return true
+}
+
+// Pin sets whether a workspace is pinned to its directory, which Set
+// chooses; Remember doesn't change a pinned workspace's directory.
+func (wm *WorkspaceMemory) Pin(workspace string, pinned bool) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	if pinned {
+		wm.pinned[workspace] = true
+	} else {
+		delete(wm.pinned, workspace)
+	}
+}
+
+// Pinned reports whether a workspace is pinned to its directory.
+func (wm *WorkspaceMemory) Pinned(workspace string) bool {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	return wm.pinned[workspace]
+}
+
+// Get retrieves the last directory for a workspace.
+// Returns empty string if no directory is remembered for this workspace.
+func (wm *WorkspaceMemory) Get(workspace string) string {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	return wm.workspaceDirs[workspace]
+}
+
+// SetTypeWorkspace records the workspace a file type (extension, e.g.
+// ".pdf") was last opened on. Files without an extension are not tracked.
+func (wm *WorkspaceMemory) SetTypeWorkspace(ext, workspace string) {
+	if ext == "" {
+		return
+	}
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	wm.typeWorkspaces[strings.ToLower(ext)] = workspace
+}
+
+// TypeWorkspace returns the workspace a file type was last opened on, or
+// "" if none is recorded.
+func (wm *WorkspaceMemory) TypeWorkspace(ext string) string {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	return wm.typeWorkspaces[strings.ToLower(ext)]
+}
+
+// Clear removes the directory mapping for a workspace, and its pin.
+func (wm *WorkspaceMemory) Clear(workspace string) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	delete(wm.workspaceDirs, workspace)
+	delete(wm.pinned, workspace)
+}
+
+// ClearAll removes all directory mappings and pins.
+func (wm *WorkspaceMemory) ClearAll() {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	wm.workspaceDirs = make(map[string]string)
+	wm.pinned = make(map[string]bool)
+}
+
+// Save persists the workspace memory to disk.
+func (wm *WorkspaceMemory) Save() error {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	data := memoryData{
+		WorkspaceDirs:  wm.workspaceDirs,
+		TypeWorkspaces: wm.typeWorkspaces,
+	}
+	for workspace := range wm.pinned {
+		data.Pinned = append(data.Pinned, workspace)
+	}
+	slices.Sort(data.Pinned)
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fileops.WriteFileAtomic(wm.path, jsonData, 0600)
+}
+
+// Load reads the workspace memory from disk.
+func (wm *WorkspaceMemory) Load() error {
+	data, err := os.ReadFile(wm.path)
+	if err != nil {
+		return err
+	}
+
+	var loaded memoryData
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	wm.workspaceDirs = loaded.WorkspaceDirs
+	if wm.workspaceDirs == nil {
+		wm.workspaceDirs = make(map[string]string)
+	}
+	wm.pinned = make(map[string]bool, len(loaded.Pinned))
+	for _, workspace := range loaded.Pinned {
+		wm.pinned[workspace] = true
+	}
+	wm.typeWorkspaces = loaded.TypeWorkspaces
+	if wm.typeWorkspaces == nil {
+		wm.typeWorkspaces = make(map[string]string)
+	}
+
+	return nil
+}
+
+// GetAll returns a copy of all workspace directories.
+// This is useful for debugging or displaying current state.
+func (wm *WorkspaceMemory) GetAll() map[string]string {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+
+	result := make(map[string]string, len(wm.workspaceDirs))
+	for k, v := range wm.workspaceDirs {
+		result[k] = v
+	}
+	return result
+}
+
+// Workspaces returns the keys with a remembered directory in the order a
+// list shows them: numbered workspaces by number, then named and special
+// ones by name, each with its monitors (see MemoryKey) in name order.
+func (wm *WorkspaceMemory) Workspaces() []string {
+	wm.mu.RLock()
+	keys := make([]string, 0, len(wm.workspaceDirs))
+	for key := range wm.workspaceDirs {
+		keys = append(keys, key)
+	}
+	wm.mu.RUnlock()
+
+	slices.SortFunc(keys, compareMemoryKeys)
+	return keys
+}
+
+// WorkspacesOf returns the keys remembering directory, pinned ones first
+// and otherwise in Workspaces order.
+func (wm *WorkspaceMemory) WorkspacesOf(directory string) []string {
+	var keys []string
+	for _, key := range wm.Workspaces() {
+		if wm.Get(key) == directory {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return -cmp.Compare(boolInt(wm.Pinned(a)), boolInt(wm.Pinned(b)))
+	})
+	return keys
+}
+
+// boolInt is 1 for true and 0 for false, for ordering by a flag.
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// compareMemoryKeys orders memory keys for Workspaces.
+func compareMemoryKeys(a, b string) int {
+	wa, ma := SplitMemoryKey(a)
+	wb, mb := SplitMemoryKey(b)
+	na, errA := strconv.Atoi(wa)
+	nb, errB := strconv.Atoi(wb)
+	switch {
+	case errA == nil && errB == nil && na != nb:
+		return cmp.Compare(na, nb)
+	case errA == nil && errB != nil:
+		return -1
+	case errA != nil && errB == nil:
+		return 1
+	}
+	return cmp.Or(strings.Compare(wa, wb), strings.Compare(ma, mb))
+}
@@ -0,0 +1,482 @@
+package compositor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestWorkspaceMemory_SetAndGet(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	// Test Set and Get
+	wm.Set("1", "/home/user/workspace1")
+	wm.Set("2", "/home/user/workspace2")
+
+	if got := wm.Get("1"); got != "/home/user/workspace1" {
+		t.Errorf("Get(\"1\") = %q, want %q", got, "/home/user/workspace1")
+	}
+
+	if got := wm.Get("2"); got != "/home/user/workspace2" {
+		t.Errorf("Get(\"2\") = %q, want %q", got, "/home/user/workspace2")
+	}
+
+	// Test non-existent workspace
+	if got := wm.Get("999"); got != "" {
+		t.Errorf("Get(\"999\") = %q, want empty string", got)
+	}
+}
+
+func TestWorkspaceMemory_Clear(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	wm.Set("1", "/home/user/workspace1")
+	wm.Set("2", "/home/user/workspace2")
+
+	// Clear workspace 1
+	wm.Clear("1")
+
+	if got := wm.Get("1"); got != "" {
+		t.Errorf("After Clear(\"1\"), Get(\"1\") = %q, want empty string", got)
+	}
+
+	// Workspace 2 should still exist
+	if got := wm.Get("2"); got != "/home/user/workspace2" {
+		t.Errorf("Get(\"2\") = %q, want %q", got, "/home/user/workspace2")
+	}
+}
+
+func TestWorkspaceMemory_ClearAll(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	wm.Set("1", "/home/user/workspace1")
+	wm.Set("2", "/home/user/workspace2")
+	wm.Set("3", "/home/user/workspace3")
+
+	wm.ClearAll()
+
+	// All should be cleared
+	for _, name := range []string{"1", "2", "3"} {
+		if got := wm.Get(name); got != "" {
+			t.Errorf("After ClearAll(), Get(%q) = %q, want empty string", name, got)
+		}
+	}
+
+	// GetAll should return empty map
+	all := wm.GetAll()
+	if len(all) != 0 {
+		t.Errorf("After ClearAll(), GetAll() has %d items, want 0", len(all))
+	}
+}
+
+func TestWorkspaceMemory_SaveAndLoad(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	// Set some data
+	wm.Set("1", "/home/user/workspace1")
+	wm.Set("2", "/home/user/workspace2")
+	wm.Set("5", "/home/user/workspace5")
+
+	// Save to disk
+	if err := wm.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	// Verify file exists
+	configPath := filepath.Join(tempDir, "hyprland-memory.json")
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		t.Fatalf("Config file was not created")
+	}
+
+	// Create new instance and load
+	wm2, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create second workspace memory: %v", err)
+	}
+
+	// Verify data was loaded
+	tests := []struct {
+		workspace string
+		want      string
+	}{
+		{"1", "/home/user/workspace1"},
+		{"2", "/home/user/workspace2"},
+		{"5", "/home/user/workspace5"},
+		{"999", ""},
+	}
+
+	for _, tt := range tests {
+		if got := wm2.Get(tt.workspace); got != tt.want {
+			t.Errorf("After load, Get(%q) = %q, want %q", tt.workspace, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaceMemory_SavePrivate(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, MemoryFile)
+
+	// os.WriteFile only set the mode when creating the file, so an
+	// existing one could stay world-readable
+	if err := os.WriteFile(path, []byte(`{"workspace_dirs":{}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+	wm.Set("1", "/home/user/private")
+	if err := wm.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestWorkspaceMemory_GetAll(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	// Set some data
+	wm.Set("1", "/home/user/workspace1")
+	wm.Set("2", "/home/user/workspace2")
+	wm.Set("5", "/home/user/workspace5")
+
+	all := wm.GetAll()
+
+	// Verify all entries
+	if len(all) != 3 {
+		t.Errorf("GetAll() returned %d items, want 3", len(all))
+	}
+
+	expected := map[string]string{
+		"1": "/home/user/workspace1",
+		"2": "/home/user/workspace2",
+		"5": "/home/user/workspace5",
+	}
+
+	for workspace, dir := range expected {
+		if got := all[workspace]; got != dir {
+			t.Errorf("GetAll()[%q] = %q, want %q", workspace, got, dir)
+		}
+	}
+
+	// Verify it's a copy (modifying returned map shouldn't affect internal state)
+	all["1"] = "/modified/path"
+	if got := wm.Get("1"); got != "/home/user/workspace1" {
+		t.Errorf("Modifying GetAll() result affected internal state: Get(\"1\") = %q", got)
+	}
+}
+
+func TestWorkspaceMemory_DefaultPath(t *testing.T) {
+	// Test that NewWorkspaceMemory with empty string uses default path
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	wm, err := NewWorkspaceMemory("")
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory with default path: %v", err)
+	}
+
+	expectedPath := filepath.Join(home, ".local", "state", "warren", "hyprland-memory.json")
+
+	if wm.path != expectedPath {
+		t.Errorf("path = %q, want %q", wm.path, expectedPath)
+	}
+}
+
+func TestWorkspaceMemory_LoadNonExistent(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	// Should start empty when no file exists
+	if got := wm.Get("1"); got != "" {
+		t.Errorf("Get(\"1\") on fresh instance = %q, want empty string", got)
+	}
+
+	all := wm.GetAll()
+	if len(all) != 0 {
+		t.Errorf("GetAll() on fresh instance has %d items, want 0", len(all))
+	}
+}
+
+func TestWorkspaceMemory_Concurrent(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	// Test concurrent access
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		workspace := strconv.Itoa(i)
+		go func() {
+			wm.Set(workspace, filepath.Join("/path/to/workspace", workspace))
+			_ = wm.Get(workspace)
+			done <- true
+		}()
+	}
+
+	// Wait for all goroutines
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+	// Verify all data is present
+	all := wm.GetAll()
+	if len(all) != 10 {
+		t.Errorf("After concurrent operations, GetAll() has %d items, want 10", len(all))
+	}
+}
+
+func TestWorkspaceMemory_NamedAndSpecial(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	wm.Set("code", "/home/user/src")
+	wm.Set("special:files", "/home/user/Downloads")
+	if err := wm.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	wm2, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create second workspace memory: %v", err)
+	}
+	if got := wm2.Get("code"); got != "/home/user/src" {
+		t.Errorf("Get(\"code\") = %q, want %q", got, "/home/user/src")
+	}
+	if got := wm2.Get("special:files"); got != "/home/user/Downloads" {
+		t.Errorf("Get(\"special:files\") = %q, want %q", got, "/home/user/Downloads")
+	}
+}
+
+func TestWorkspaceMemory_LoadIDKeyedFile(t *testing.T) {
+	// Files written when memory was keyed by workspace ID use the same
+	// JSON shape, with numbered workspaces named after their ID
+	tempDir := t.TempDir()
+	data := `{"workspace_dirs": {"1": "/home/user/one", "3": "/home/user/three"}}`
+	if err := os.WriteFile(filepath.Join(tempDir, "hyprland-memory.json"), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write memory file: %v", err)
+	}
+
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+	if got := wm.Get("3"); got != "/home/user/three" {
+		t.Errorf("Get(\"3\") = %q, want %q", got, "/home/user/three")
+	}
+}
+
+func TestWorkspaceMemory_TypeWorkspace(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	wm.SetTypeWorkspace(".pdf", "3")
+	wm.SetTypeWorkspace(".PNG", "special:media")
+	wm.SetTypeWorkspace("", "4") // Extensionless files are not tracked
+
+	if err := wm.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	wm2, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create second workspace memory: %v", err)
+	}
+
+	tests := []struct {
+		ext  string
+		want string
+	}{
+		{".pdf", "3"},
+		{".png", "special:media"},
+		{".Png", "special:media"},
+		{"", ""},
+		{".txt", ""},
+	}
+	for _, tt := range tests {
+		if got := wm2.TypeWorkspace(tt.ext); got != tt.want {
+			t.Errorf("After load, TypeWorkspace(%q) = %q, want %q", tt.ext, got, tt.want)
+		}
+	}
+}
+
+func TestWorkspaceMemory_TypeWorkspaceOldFile(t *testing.T) {
+	// Files from before file types were tracked have no type_workspaces
+	tempDir := t.TempDir()
+	data := `{"workspace_dirs": {"1": "/home/user/one"}}`
+	if err := os.WriteFile(filepath.Join(tempDir, "hyprland-memory.json"), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write memory file: %v", err)
+	}
+
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+	wm.SetTypeWorkspace(".pdf", "2")
+	if got := wm.TypeWorkspace(".pdf"); got != "2" {
+		t.Errorf("TypeWorkspace(\".pdf\") = %q, want %q", got, "2")
+	}
+}
+
+func TestMemoryKey(t *testing.T) {
+	tests := []struct {
+		workspace, monitor, want string
+	}{
+		{"2", "", "2"},
+		{"2", "DP-1", "2@DP-1"},
+		{"special:files", "HDMI-A-1", "special:files@HDMI-A-1"},
+	}
+
+	for _, tt := range tests {
+		if got := MemoryKey(tt.workspace, tt.monitor); got != tt.want {
+			t.Errorf("MemoryKey(%q, %q) = %q, want %q", tt.workspace, tt.monitor, got, tt.want)
+		}
+		if workspace, monitor := SplitMemoryKey(tt.want); workspace != tt.workspace || monitor != tt.monitor {
+			t.Errorf("SplitMemoryKey(%q) = %q, %q, want %q, %q", tt.want, workspace, monitor, tt.workspace, tt.monitor)
+		}
+	}
+}
+
+func TestWorkspaceMemory_Workspaces(t *testing.T) {
+	wm, err := NewWorkspaceMemory(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+	for _, key := range []string{"special:files", "10", "code", "2@HDMI-A-1", "2@DP-1", "1"} {
+		wm.Set(key, "/home/user/"+key)
+	}
+
+	want := []string{"1", "2@DP-1", "2@HDMI-A-1", "10", "code", "special:files"}
+	if got := wm.Workspaces(); !slices.Equal(got, want) {
+		t.Errorf("Workspaces() = %q, want %q", got, want)
+	}
+}
+
+func TestWorkspaceMemory_Pin(t *testing.T) {
+	tempDir := t.TempDir()
+	wm, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+
+	if !wm.Remember("1", "/home/user/notes") {
+		t.Error("Remember() on an unpinned workspace = false, want true")
+	}
+	if wm.Remember("1", "/home/user/notes") {
+		t.Error("Remember() of the same directory = true, want false")
+	}
+
+	wm.Set("2", "/home/user/project")
+	wm.Pin("2", true)
+	if wm.Remember("2", "/tmp") {
+		t.Error("Remember() on a pinned workspace = true, want false")
+	}
+	if got := wm.Get("2"); got != "/home/user/project" {
+		t.Errorf("Get(\"2\") = %q after Remember, want the pinned directory", got)
+	}
+
+	// Pins are kept across sessions
+	if err := wm.Save(); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	loaded, err := NewWorkspaceMemory(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create second workspace memory: %v", err)
+	}
+	if !loaded.Pinned("2") || loaded.Pinned("1") {
+		t.Errorf("Pinned() after loading = %v, %v, want true for 2 only", loaded.Pinned("2"), loaded.Pinned("1"))
+	}
+
+	// Forgetting a workspace unpins it
+	loaded.Clear("2")
+	if loaded.Pinned("2") {
+		t.Error("Pinned(\"2\") = true after Clear, want false")
+	}
+	loaded.Set("2", "/home/user/project")
+	loaded.Pin("2", true)
+	loaded.ClearAll()
+	if loaded.Pinned("2") {
+		t.Error("Pinned(\"2\") = true after ClearAll, want false")
+	}
+}
+
+func TestWorkspaceMemory_WorkspacesOf(t *testing.T) {
+	wm, err := NewWorkspaceMemory(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create workspace memory: %v", err)
+	}
+	wm.Set("1", "/home/user/project")
+	wm.Set("2", "/home/user")
+	wm.Set("3", "/home/user/project")
+	wm.Set("4", "/home/user/project")
+	wm.Pin("4", true)
+
+	want := []string{"4", "1", "3"}
+	if got := wm.WorkspacesOf("/home/user/project"); !slices.Equal(got, want) {
+		t.Errorf("WorkspacesOf() = %q, want %q", got, want)
+	}
+	if got := wm.WorkspacesOf("/tmp"); len(got) != 0 {
+		t.Errorf("WorkspacesOf(\"/tmp\") = %q, want none", got)
+	}
+}
+
+func TestSessionKey(t *testing.T) {
+	t.Setenv("WARREN_SESSION", "")
+	t.Setenv("XDG_SESSION_DESKTOP", "")
+	t.Setenv("XDG_CURRENT_DESKTOP", "")
+	if got := SessionKey(); got != "session:default" {
+		t.Errorf("SessionKey() = %q, want %q", got, "session:default")
+	}
+
+	t.Setenv("XDG_CURRENT_DESKTOP", "GNOME")
+	if got := SessionKey(); got != "session:GNOME" {
+		t.Errorf("SessionKey() = %q, want the desktop", got)
+	}
+
+	t.Setenv("WARREN_SESSION", "photos")
+	if got := SessionKey(); got != "session:photos" {
+		t.Errorf("SessionKey() = %q, want $WARREN_SESSION", got)
+	}
+}
@@ -4,90 +4,424 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/lawrab/warren/internal/fileops"
 	"github.com/pelletier/go-toml/v2"
 )
 
 // Config represents Warren's configuration structure.
 type Config struct {
+	Version     int               `toml:"version"` // Config format version, see CurrentVersion
 	Appearance  AppearanceConfig  `toml:"appearance"`
 	Keybindings KeybindingsConfig `toml:"keybindings"`
 	General     GeneralConfig     `toml:"general"`
+	Confirm     ConfirmConfig     `toml:"confirm"`
+	Preview     PreviewConfig     `toml:"preview"`
 	Hyprland    HyprlandConfig    `toml:"hyprland"`
+	Openers     map[string]string `toml:"openers"` // Commands that open files, by name or MIME glob (see fileops.OpenerCommand)
 }
 
 // AppearanceConfig controls visual appearance settings.
 type AppearanceConfig struct {
 	ShowHidden       bool   `toml:"show_hidden"`        // Show hidden files by default
+	HiddenLast       bool   `toml:"hidden_last"`        // List hidden files after the others instead of among them
 	WindowWidth      int    `toml:"window_width"`       // Default window width
 	WindowHeight     int    `toml:"window_height"`      // Default window height
-	DefaultSortMode  string `toml:"default_sort_mode"`  // Default sort mode: "name", "size", "modified", "extension"
+	RememberWindow   bool   `toml:"remember_window"`    // Restore the last window size, maximized state and pane splits
+	DefaultSortMode  string `toml:"default_sort_mode"`  // Default sort mode: "name", "size", "modified", "extension", "taken"
 	DefaultSortOrder string `toml:"default_sort_order"` // Default sort order: "ascending", "descending"
+	ColorScheme      string `toml:"color_scheme"`       // "system" (follow the desktop), "light" or "dark"
+	AccentColor      string `toml:"accent_color"`       // Selection colour, e.g. "#3584e4"; empty for the theme's
+	Density          string `toml:"density"`            // Row spacing: "compact", "normal", "comfortable"
+	DateFormat       string `toml:"date_format"`        // Modified column: "default", "relative", "iso", "locale"
+	SizeFormat       string `toml:"size_format"`        // Size column: "binary", "decimal", "bytes"
+	IconSize         int    `toml:"icon_size"`          // Pixel size of the icons beside file names
+	Ellipsize        string `toml:"ellipsize"`          // Where long names are cut: "middle", "end"
 }
 
+// Bounds and default of AppearanceConfig.IconSize.
+const (
+	MinIconSize     = 8
+	MaxIconSize     = 64
+	DefaultIconSize = 16
+)
+
 // KeybindingsConfig defines keyboard shortcuts.
-// Each field should contain a single key name (e.g., "j", "period", "space").
-// For special keys, use GTK key names (e.g., "Return", "BackSpace", "Escape").
+// Each field holds a key name (e.g., "j", "period", "space") or a GTK key
+// name for special keys (e.g., "Return", "BackSpace", "Escape"). Keys may
+// take modifiers ("<Ctrl>d", "<Shift>g") and several keys separated by
+// spaces form a chord ("g g"). See internal/keymap for the full syntax.
 type KeybindingsConfig struct {
 	Quit            string `toml:"quit"`              // Quit application
 	NavigateUp      string `toml:"navigate_up"`       // Move selection up
 	NavigateDown    string `toml:"navigate_down"`     // Move selection down
+	GoTop           string `toml:"go_top"`            // Select the first entry
+	GoBottom        string `toml:"go_bottom"`         // Select the last entry
+	HalfPageDown    string `toml:"half_page_down"`    // Scroll down half a page
+	HalfPageUp      string `toml:"half_page_up"`      // Scroll up half a page
+	ViewTop         string `toml:"view_top"`          // Select the top visible entry
+	ViewMiddle      string `toml:"view_middle"`       // Select the middle visible entry
+	ViewBottom      string `toml:"view_bottom"`       // Select the bottom visible entry
 	ParentDir       string `toml:"parent_dir"`        // Go to parent directory
 	EnterDir        string `toml:"enter_dir"`         // Enter directory or open file
+	Jump            string `toml:"jump"`              // Type a name prefix to jump to it
 	ToggleHidden    string `toml:"toggle_hidden"`     // Toggle hidden files visibility
+	Filter          string `toml:"filter"`            // Show only names matching a glob or extension
+	ToggleTree      string `toml:"toggle_tree"`       // Expand directories in place instead of entering them
+	CollapseAll     string `toml:"collapse_all"`      // Collapse every expanded directory (tree mode)
+	ExpandLevel     string `toml:"expand_level"`      // Expand directories N levels deep (count prefix, tree mode)
 	CycleSortMode   string `toml:"cycle_sort_mode"`   // Cycle through sort modes
 	ToggleSortOrder string `toml:"toggle_sort_order"` // Toggle sort order (ascending/descending)
 	Yank            string `toml:"yank"`              // Yank (copy) selected file
+	Cut             string `toml:"cut"`               // Cut selected file (move on paste)
 	Delete          string `toml:"delete"`            // Delete selected file
+	Shred           string `toml:"shred"`             // Overwrite and delete selected file (needs general.secure_delete)
 	Paste           string `toml:"paste"`             // Paste yanked files
+	PasteTo         string `toml:"paste_to"`          // Paste yanked files into a directory typed or bookmarked
+	SendToWorkspace string `toml:"send_to_workspace"` // Paste yanked files into the directory remembered for workspace N (count prefix)
+	HardLink        string `toml:"hard_link"`         // Create hard links to yanked files in the current directory
 	Rename          string `toml:"rename"`            // Rename selected file
+	RenamePhotos    string `toml:"rename_photos"`     // Rename yanked or selected photos after their capture time
+	Cleanup         string `toml:"cleanup"`           // Find broken links and empty directories under the current one
 	ShowHelp        string `toml:"show_help"`         // Show keyboard shortcuts help
+	ShowLog         string `toml:"show_log"`          // Show the end of Warren's log file
+	Preferences     string `toml:"preferences"`       // Open the preferences window
+	MoveToWorkspace string `toml:"move_to_workspace"` // Send the window to workspace N (count prefix) in Hyprland
+	OpenOnWorkspace string `toml:"open_on_workspace"` // Open the selected file on workspace N (count prefix) or its type's last one
+	Workspaces      string `toml:"workspaces"`        // List the directories remembered per workspace to go to, edit or forget
+	PinWorkspace    string `toml:"pin_workspace"`     // Pin the current directory to the workspace, or unpin it
+	Edit            string `toml:"edit"`              // Open the selected file in the editor, in a terminal
+	Run             string `toml:"run"`               // Run the selected executable, asking for arguments
+	DefaultApp      string `toml:"default_app"`       // Change the default application for the selected file's type
+	TogglePreview   string `toml:"toggle_preview"`    // Show or hide the preview pane
+	QuickLook       string `toml:"quick_look"`        // Preview the selected file fullscreen
+	Properties      string `toml:"properties"`        // Show details of the selected file
+	ToggleDualPane  string `toml:"toggle_dual_pane"`  // Show or hide a second file pane
+	SwitchPane      string `toml:"switch_pane"`       // Move keyboard focus to the other pane
+	ComparePanes    string `toml:"compare_panes"`     // Highlight differences between the panes (size and time)
+	CompareChecksum string `toml:"compare_checksum"`  // Like compare_panes, but compare file contents
+	SyncNewer       string `toml:"sync_newer"`        // Copy files missing or older in the other pane to it
+	Visual          string `toml:"visual"`            // Select a range of entries to yank or cut
+	Command         string `toml:"command"`           // Type the name of an action to run it
+	Register        string `toml:"register"`          // Name the register the next yank, cut or paste uses
+	Registers       string `toml:"registers"`         // List the registers to paste or clear one
+	Undo            string `toml:"undo"`              // Undo the last copy, move, rename or trash; repeat to go further back
+	History         string `toml:"history"`           // List finished operations
+}
+
+// Binding is one configured keybinding. Action is the config key, which
+// is also the action name used by the keyboard handler.
+type Binding struct {
+	Action string
+	Key    string
+}
+
+// bindingField pairs an action name with the field holding its key.
+type bindingField struct {
+	action string
+	key    *string
+}
+
+// fields lists the keybinding fields in config file order.
+func (k *KeybindingsConfig) fields() []bindingField {
+	return []bindingField{
+		{"quit", &k.Quit},
+		{"navigate_up", &k.NavigateUp},
+		{"navigate_down", &k.NavigateDown},
+		{"go_top", &k.GoTop},
+		{"go_bottom", &k.GoBottom},
+		{"half_page_down", &k.HalfPageDown},
+		{"half_page_up", &k.HalfPageUp},
+		{"view_top", &k.ViewTop},
+		{"view_middle", &k.ViewMiddle},
+		{"view_bottom", &k.ViewBottom},
+		{"parent_dir", &k.ParentDir},
+		{"enter_dir", &k.EnterDir},
+		{"jump", &k.Jump},
+		{"toggle_hidden", &k.ToggleHidden},
+		{"filter", &k.Filter},
+		{"toggle_tree", &k.ToggleTree},
+		{"collapse_all", &k.CollapseAll},
+		{"expand_level", &k.ExpandLevel},
+		{"cycle_sort_mode", &k.CycleSortMode},
+		{"toggle_sort_order", &k.ToggleSortOrder},
+		{"yank", &k.Yank},
+		{"cut", &k.Cut},
+		{"delete", &k.Delete},
+		{"shred", &k.Shred},
+		{"paste", &k.Paste},
+		{"paste_to", &k.PasteTo},
+		{"send_to_workspace", &k.SendToWorkspace},
+		{"hard_link", &k.HardLink},
+		{"rename", &k.Rename},
+		{"rename_photos", &k.RenamePhotos},
+		{"cleanup", &k.Cleanup},
+		{"show_help", &k.ShowHelp},
+		{"show_log", &k.ShowLog},
+		{"preferences", &k.Preferences},
+		{"move_to_workspace", &k.MoveToWorkspace},
+		{"open_on_workspace", &k.OpenOnWorkspace},
+		{"workspaces", &k.Workspaces},
+		{"pin_workspace", &k.PinWorkspace},
+		{"edit", &k.Edit},
+		{"run", &k.Run},
+		{"default_app", &k.DefaultApp},
+		{"toggle_preview", &k.TogglePreview},
+		{"quick_look", &k.QuickLook},
+		{"properties", &k.Properties},
+		{"toggle_dual_pane", &k.ToggleDualPane},
+		{"switch_pane", &k.SwitchPane},
+		{"compare_panes", &k.ComparePanes},
+		{"compare_checksum", &k.CompareChecksum},
+		{"sync_newer", &k.SyncNewer},
+		{"visual", &k.Visual},
+		{"command", &k.Command},
+		{"register", &k.Register},
+		{"registers", &k.Registers},
+		{"undo", &k.Undo},
+		{"history", &k.History},
+	}
+}
+
+// Bindings lists the configured keybindings in config file order.
+func (k KeybindingsConfig) Bindings() []Binding {
+	fields := k.fields()
+	bindings := make([]Binding, len(fields))
+	for i, f := range fields {
+		bindings[i] = Binding{Action: f.action, Key: *f.key}
+	}
+	return bindings
+}
+
+// Set changes the key bound to action. An empty key unbinds it.
+func (k *KeybindingsConfig) Set(action, key string) error {
+	for _, f := range k.fields() {
+		if f.action == action {
+			*f.key = key
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown keybinding action %q", action)
 }
 
 // GeneralConfig contains general application settings.
 type GeneralConfig struct {
-	StartDirectory string `toml:"start_directory"` // Starting directory ("~", "/", or "last")
+	StartDirectory       string  `toml:"start_directory"`       // Starting directory ("~", "/", or "last")
+	ControlSocket        bool    `toml:"control_socket"`        // Listen on a Unix socket for scripting commands
+	DesktopNotifications bool    `toml:"desktop_notifications"` // Notify the desktop when background operations finish unfocused
+	CountItems           bool    `toml:"count_items"`           // Show the number of entries of directories in the Size column
+	WatchSubdirectories  bool    `toml:"watch_subdirectories"`  // Keep directory item counts live by watching one level deeper
+	PollInterval         int     `toml:"poll_interval"`         // Seconds between checks of directories that cannot be watched (NFS, FUSE, SMB)
+	SecureDelete         bool    `toml:"secure_delete"`         // Enable the shred keybinding, which overwrites files before deleting them
+	ShredPasses          int     `toml:"shred_passes"`          // Overwrite passes for shred; 0 punches holes instead
+	LogLevel             string  `toml:"log_level"`             // Least severe log records written: "debug", "info", "warn", "error"
+	Terminal             string  `toml:"terminal"`              // Terminal the editor runs in, e.g. "foot"; "" for $TERMINAL or the first one found
+	Editor               string  `toml:"editor"`                // Editor for the edit keybinding; "" for $VISUAL, $EDITOR or vi
+	IOLimit              float64 `toml:"io_limit"`              // Most MB per second copies write, over all operations (0 for no limit)
+	Symlinks             string  `toml:"symlinks"`              // What copies do with symlinks: "preserve", "rewrite-relative", "dereference"
+	PhysicalPaths        bool    `toml:"physical_paths"`        // Entering a link to a directory opens its resolved target, not the path through the link
+}
+
+// PollDuration returns the poll interval as a duration.
+func (g GeneralConfig) PollDuration() time.Duration {
+	return time.Duration(g.PollInterval) * time.Second
 }
 
-// HyprlandConfig controls Hyprland integration features.
+// IOLimitBytes returns the copy rate limit in bytes per second, or 0 when
+// there is none.
+func (g GeneralConfig) IOLimitBytes() int64 {
+	if g.IOLimit <= 0 {
+		return 0
+	}
+	return int64(g.IOLimit * (1 << 20))
+}
+
+// SymlinkPolicy returns the configured symlink policy for copies.
+func (g GeneralConfig) SymlinkPolicy() fileops.SymlinkPolicy {
+	policy, _ := fileops.ParseSymlinkPolicy(g.Symlinks) // Checked by Validate
+	return policy
+}
+
+// ConfirmConfig controls which operations ask for confirmation first.
+// The "Don't ask again" checkbox in a confirmation dialog turns the
+// matching option off and saves the config.
+type ConfirmConfig struct {
+	Delete          bool    `toml:"delete"`           // Confirm before deleting
+	Overwrite       bool    `toml:"overwrite"`        // Confirm before a paste replaces existing files
+	CrossFilesystem bool    `toml:"cross_filesystem"` // Confirm before moving files to another filesystem
+	LargeOperation  float64 `toml:"large_operation"`  // Confirm copies/moves larger than this many GB (0 to disable)
+	Symlinks        bool    `toml:"symlinks"`         // Ask how to copy symlinks when a paste holds any
+
+	// Deletes past these limits ask for "yes" to be typed, even with
+	// delete confirmation off
+	LargeDelete       float64 `toml:"large_delete"`        // Deletes larger than this many GB (0 to disable)
+	LargeDeleteItems  int     `toml:"large_delete_items"`  // Deletes of more than this many items (0 to disable)
+	DeleteOutsideHome bool    `toml:"delete_outside_home"` // Deletes outside the home directory
+}
+
+// LargeDeleteBytes returns the large delete threshold in bytes, or 0
+// when the check is disabled.
+func (c ConfirmConfig) LargeDeleteBytes() int64 {
+	if c.LargeDelete <= 0 {
+		return 0
+	}
+	return int64(c.LargeDelete * (1 << 30))
+}
+
+// LargeOperationBytes returns the large operation threshold in bytes,
+// or 0 when the check is disabled.
+func (c ConfirmConfig) LargeOperationBytes() int64 {
+	if c.LargeOperation <= 0 {
+		return 0
+	}
+	return int64(c.LargeOperation * (1 << 30))
+}
+
+// PreviewConfig controls the preview pane beside the file list.
+type PreviewConfig struct {
+	Enabled     bool `toml:"enabled"`      // Show the preview pane
+	MaxTextKB   int  `toml:"max_text_kb"`  // How much of a text file to show, in KiB
+	LineNumbers bool `toml:"line_numbers"` // Number the lines of text previews
+}
+
+// MaxTextBytes returns the text preview limit in bytes.
+func (p PreviewConfig) MaxTextBytes() int {
+	return p.MaxTextKB * 1024
+}
+
+// HyprlandConfig controls Hyprland integration features. The same settings
+// apply under Sway.
 type HyprlandConfig struct {
-	Enabled         bool `toml:"enabled"`          // Enable Hyprland integration (auto-detected if not set)
-	WorkspaceMemory bool `toml:"workspace_memory"` // Remember directory per workspace
-	AutoSwitch      bool `toml:"auto_switch"`      // Auto-switch to remembered directory on workspace change
+	Enabled          bool   `toml:"enabled"`            // Enable Hyprland/Sway integration (auto-detected if not set)
+	WorkspaceMemory  bool   `toml:"workspace_memory"`   // Remember directory per workspace
+	AutoSwitch       bool   `toml:"auto_switch"`        // Auto-switch to remembered directory on workspace change
+	SwitchWorkspace  bool   `toml:"switch_workspace"`   // Move to the workspace remembering a directory navigated to
+	FallbackMemory   bool   `toml:"fallback_memory"`    // Without Hyprland or Sway, remember the last directory per session
+	PerMonitorMemory bool   `toml:"per_monitor_memory"` // Remember directories per (monitor, workspace) pair
+	OpenOnWorkspace  string `toml:"open_on_workspace"`  // Where opened files go: "" (current), "last" (per file type) or a workspace name
+	FocusExisting    bool   `toml:"focus_existing"`     // Focus a window already showing a file instead of opening it again
 }
 
+// OpenLastWorkspace is the open_on_workspace value that opens each file
+// type on the workspace it was last opened on.
+const OpenLastWorkspace = "last"
+
 // Default returns a Config with sensible default values.
 func Default() *Config {
 	return &Config{
+		Version: CurrentVersion,
 		Appearance: AppearanceConfig{
 			ShowHidden:       false,
+			HiddenLast:       false,
 			WindowWidth:      1000,
 			WindowHeight:     700,
+			RememberWindow:   true,
 			DefaultSortMode:  "name",
 			DefaultSortOrder: "ascending",
+			ColorScheme:      "system",
+			AccentColor:      "",
+			Density:          "normal",
+			DateFormat:       "default",
+			SizeFormat:       "binary",
+			IconSize:         DefaultIconSize,
+			Ellipsize:        "middle",
 		},
 		Keybindings: KeybindingsConfig{
 			Quit:            "q",
 			NavigateUp:      "k",
 			NavigateDown:    "j",
+			GoTop:           "g g",
+			GoBottom:        "G",
+			HalfPageDown:    "<Ctrl>d",
+			HalfPageUp:      "<Ctrl>u",
+			ViewTop:         "H",
+			ViewMiddle:      "M",
+			ViewBottom:      "L",
 			ParentDir:       "h",
 			EnterDir:        "l",
+			Jump:            "f",
 			ToggleHidden:    "period",
+			Filter:          "F",
+			ToggleTree:      "z t",
+			CollapseAll:     "z M",
+			ExpandLevel:     "z r",
 			CycleSortMode:   "s",
 			ToggleSortOrder: "o",
 			Yank:            "y",
-			Delete:          "d",
+			Cut:             "d d",
+			Delete:          "D",
+			Shred:           "g D",
 			Paste:           "p",
+			PasteTo:         "P",
+			SendToWorkspace: "g p",
+			HardLink:        "g l",
 			Rename:          "r",
+			RenamePhotos:    "R",
+			Cleanup:         "g c",
 			ShowHelp:        "question",
+			ShowLog:         "g L",
+			Preferences:     "<Ctrl>comma",
+			MoveToWorkspace: "g w",
+			OpenOnWorkspace: "g o",
+			Workspaces:      "g W",
+			PinWorkspace:    "g P",
+			Edit:            "e",
+			Run:             "exclam",
+			DefaultApp:      "g a",
+			TogglePreview:   "z p",
+			QuickLook:       "space",
+			Properties:      "i",
+			ToggleDualPane:  "z d",
+			SwitchPane:      "z w",
+			ComparePanes:    "z c",
+			CompareChecksum: "z C",
+			SyncNewer:       "z s",
+			Visual:          "v",
+			Command:         "colon",
+			Register:        "quotedbl",
+			Registers:       "g quotedbl",
+			Undo:            "u",
+			History:         "g h",
 		},
 		General: GeneralConfig{
-			StartDirectory: "~",
+			StartDirectory:       "~",
+			ControlSocket:        true,
+			DesktopNotifications: false,
+			CountItems:           true,
+			WatchSubdirectories:  true,
+			PollInterval:         2,
+			SecureDelete:         false,
+			ShredPasses:          1,
+			LogLevel:             "info",
+			Symlinks:             "preserve",
+			PhysicalPaths:        false,
+		},
+		Confirm: ConfirmConfig{
+			Delete:          true,
+			Overwrite:       true,
+			CrossFilesystem: false,
+			LargeOperation:  10,
+			Symlinks:        true,
+
+			LargeDelete:       1,
+			LargeDeleteItems:  1000,
+			DeleteOutsideHome: true,
+		},
+		Preview: PreviewConfig{
+			Enabled:     true,
+			MaxTextKB:   64,
+			LineNumbers: true,
 		},
 		Hyprland: HyprlandConfig{
-			Enabled:         true, // Auto-enabled if running in Hyprland
-			WorkspaceMemory: true,
-			AutoSwitch:      true,
+			Enabled:          true, // Auto-enabled if running in Hyprland
+			WorkspaceMemory:  true,
+			AutoSwitch:       true,
+			SwitchWorkspace:  false,
+			FallbackMemory:   true,
+			PerMonitorMemory: false,
+			OpenOnWorkspace:  "",
+			FocusExisting:    true,
 		},
 	}
 }
@@ -140,12 +474,31 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return Parse(data)
+}
+
+// Parse decodes configuration file contents. Missing fields are filled
+// with default values.
+func Parse(data []byte) (*Config, error) {
+	// The version is read on its own: after overlaying defaults a file
+	// without one would look current
+	var header struct {
+		Version int `toml:"version"`
+	}
+	if err := toml.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if header.Version > CurrentVersion {
+		return nil, fmt.Errorf("config version %d is newer than supported version %d", header.Version, CurrentVersion)
+	}
+
 	// Start with defaults, then overlay user config
 	config := Default()
 	if err := toml.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	migrate(config, header.Version)
 	return config, nil
 }
 
@@ -169,8 +522,8 @@ func Save(cfg *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	// Replace the file atomically so a crash can't leave it half written
+	if err := fileops.WriteFileAtomic(configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDefault(t *testing.T) {
@@ -13,6 +14,9 @@ func TestDefault(t *testing.T) {
 	if cfg.Appearance.ShowHidden != false {
 		t.Errorf("Expected ShowHidden to be false, got %v", cfg.Appearance.ShowHidden)
 	}
+	if cfg.Appearance.HiddenLast != false {
+		t.Errorf("Expected HiddenLast to be false, got %v", cfg.Appearance.HiddenLast)
+	}
 	if cfg.Appearance.WindowWidth != 1000 {
 		t.Errorf("Expected WindowWidth to be 1000, got %d", cfg.Appearance.WindowWidth)
 	}
@@ -35,6 +39,15 @@ func TestDefault(t *testing.T) {
 	if cfg.General.StartDirectory != "~" {
 		t.Errorf("Expected StartDirectory to be '~', got %s", cfg.General.StartDirectory)
 	}
+	if cfg.General.ControlSocket != true {
+		t.Errorf("Expected ControlSocket to be true, got %v", cfg.General.ControlSocket)
+	}
+	if cfg.General.DesktopNotifications != false {
+		t.Errorf("Expected DesktopNotifications to be false, got %v", cfg.General.DesktopNotifications)
+	}
+	if cfg.General.PhysicalPaths != false {
+		t.Errorf("Expected PhysicalPaths to be false, got %v", cfg.General.PhysicalPaths)
+	}
 
 	// Check hyprland defaults
 	if cfg.Hyprland.Enabled != true {
@@ -113,6 +126,34 @@ func TestLoadNonexistent(t *testing.T) {
 	}
 }
 
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+		check   func(*Config) bool
+	}{
+		{"empty uses defaults", "", false, func(c *Config) bool { return c.Keybindings.Quit == "q" }},
+		{"overlay", "[keybindings]\nquit = \"Q\"\n", false, func(c *Config) bool {
+			return c.Keybindings.Quit == "Q" && c.Keybindings.NavigateUp == "k"
+		}},
+		{"invalid toml", "[keybindings\nquit = ", true, nil},
+		{"wrong type", "[appearance]\nwindow_width = \"wide\"\n", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil && !tt.check(cfg) {
+				t.Errorf("Parse() = %+v, unexpected values", cfg)
+			}
+		})
+	}
+}
+
 func TestLoadOrDefault(t *testing.T) {
 	// Create temporary directory for testing
 	tmpDir := t.TempDir()
@@ -186,6 +227,12 @@ quit = "Q"
 	if cfg.Hyprland.AutoSwitch != true {
 		t.Errorf("Expected Hyprland.AutoSwitch default (true), got %v", cfg.Hyprland.AutoSwitch)
 	}
+	if cfg.Hyprland.SwitchWorkspace {
+		t.Errorf("Expected Hyprland.SwitchWorkspace default (false), got %v", cfg.Hyprland.SwitchWorkspace)
+	}
+	if !cfg.Hyprland.FallbackMemory {
+		t.Errorf("Expected Hyprland.FallbackMemory default (true), got %v", cfg.Hyprland.FallbackMemory)
+	}
 
 	// Verify other defaults were applied
 	if cfg.Keybindings.NavigateUp != "k" {
@@ -194,6 +241,42 @@ quit = "Q"
 	if cfg.General.StartDirectory != "~" {
 		t.Errorf("Expected StartDirectory default ('~'), got %s", cfg.General.StartDirectory)
 	}
+	if cfg.Confirm.Delete != true || cfg.Confirm.Overwrite != true {
+		t.Errorf("Expected delete and overwrite confirmations by default, got %+v", cfg.Confirm)
+	}
+}
+
+func TestLargeOperationBytes(t *testing.T) {
+	tests := []struct {
+		gb   float64
+		want int64
+	}{
+		{0, 0},
+		{-1, 0},
+		{1, 1 << 30},
+		{0.5, 1 << 29},
+	}
+
+	for _, tt := range tests {
+		c := ConfirmConfig{LargeOperation: tt.gb}
+		if got := c.LargeOperationBytes(); got != tt.want {
+			t.Errorf("LargeOperationBytes() with %v GB = %d, want %d", tt.gb, got, tt.want)
+		}
+		c = ConfirmConfig{LargeDelete: tt.gb}
+		if got := c.LargeDeleteBytes(); got != tt.want {
+			t.Errorf("LargeDeleteBytes() with %v GB = %d, want %d", tt.gb, got, tt.want)
+		}
+	}
+}
+
+func TestPollDuration(t *testing.T) {
+	g := GeneralConfig{PollInterval: 5}
+	if got := g.PollDuration(); got != 5*time.Second {
+		t.Errorf("PollDuration() = %v, want 5s", got)
+	}
+	if got := Default().General.PollDuration(); got != 2*time.Second {
+		t.Errorf("default PollDuration() = %v, want 2s", got)
+	}
 }
 
 func TestLoadWithPartialHyprlandSection(t *testing.T) {
@@ -278,3 +361,28 @@ func TestDirWithNoXDG(t *testing.T) {
 		t.Errorf("Dir() = %s, want %s", dir, expectedDir)
 	}
 }
+
+func TestKeybindingsSet(t *testing.T) {
+	kb := Default().Keybindings
+
+	if err := kb.Set("rename", "R"); err != nil {
+		t.Fatalf("Set(rename) error = %v", err)
+	}
+	if kb.Rename != "R" {
+		t.Errorf("Rename = %q, want %q", kb.Rename, "R")
+	}
+
+	found := false
+	for _, b := range kb.Bindings() {
+		if b.Action == "rename" {
+			found = b.Key == "R"
+		}
+	}
+	if !found {
+		t.Errorf("Bindings() does not reflect Set: %v", kb.Bindings())
+	}
+
+	if err := kb.Set("no_such_action", "x"); err == nil {
+		t.Error("Set() with unknown action should fail")
+	}
+}
@@ -3,7 +3,7 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 
@@ -17,7 +17,7 @@ func GetStartDirectory(configDir string) string {
 	if configDir == "~" || configDir == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			log.Printf("Failed to get home directory: %v", err)
+			slog.Warn("Failed to get home directory", "err", err)
 			return "/"
 		}
 		return homeDir
@@ -29,7 +29,7 @@ func GetStartDirectory(configDir string) string {
 		if info, err := os.Stat(configDir); err == nil && info.IsDir() {
 			return configDir
 		}
-		log.Printf("Configured start directory %s does not exist, using home", configDir)
+		slog.Warn("Configured start directory does not exist, using home", "path", configDir)
 	}
 
 	// Fall back to home directory
@@ -40,30 +40,47 @@ func GetStartDirectory(configDir string) string {
 	return homeDir
 }
 
-// ParseSortMode converts a string to a SortBy value.
+// ParseSortMode converts a string to a SortBy value, defaulting to name.
 func ParseSortMode(mode string) models.SortBy {
+	sortBy, _ := lookupSortMode(mode)
+	return sortBy
+}
+
+// lookupSortMode converts a string to a SortBy value and reports whether
+// the string was recognised.
+func lookupSortMode(mode string) (models.SortBy, bool) {
 	switch mode {
 	case "name", "Name":
-		return models.SortByName
+		return models.SortByName, true
 	case "size", "Size":
-		return models.SortBySize
+		return models.SortBySize, true
 	case "modified", "Modified", "modtime":
-		return models.SortByModTime
+		return models.SortByModTime, true
 	case "extension", "Extension", "ext":
-		return models.SortByExtension
+		return models.SortByExtension, true
+	case "taken", "Taken":
+		return models.SortByTaken, true
 	default:
-		return models.SortByName
+		return models.SortByName, false
 	}
 }
 
-// ParseSortOrder converts a string to a SortOrder value.
+// ParseSortOrder converts a string to a SortOrder value, defaulting to
+// ascending.
 func ParseSortOrder(order string) models.SortOrder {
+	sortOrder, _ := lookupSortOrder(order)
+	return sortOrder
+}
+
+// lookupSortOrder converts a string to a SortOrder value and reports
+// whether the string was recognised.
+func lookupSortOrder(order string) (models.SortOrder, bool) {
 	switch order {
 	case "ascending", "Ascending", "asc":
-		return models.SortAscending
+		return models.SortAscending, true
 	case "descending", "Descending", "desc":
-		return models.SortDescending
+		return models.SortDescending, true
 	default:
-		return models.SortAscending
+		return models.SortAscending, false
 	}
 }
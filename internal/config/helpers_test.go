@@ -24,6 +24,7 @@ func TestParseSortMode(t *testing.T) {
 		{"extension", "extension", models.SortByExtension},
 		{"Extension capitalized", "Extension", models.SortByExtension},
 		{"ext", "ext", models.SortByExtension},
+		{"taken", "taken", models.SortByTaken},
 		{"invalid defaults to name", "invalid", models.SortByName},
 		{"empty defaults to name", "", models.SortByName},
 	}
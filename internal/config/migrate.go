@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// CurrentVersion is the config format version written by this release.
+// Files without a version field are version 0.
+const CurrentVersion = 2
+
+// migrations upgrade a config one version at a time: migrations[i] turns
+// version i into version i+1. They run after the file has been overlaid
+// on the defaults, so they only need to fix values that changed meaning.
+var migrations = []func(*Config){
+	// 0 -> 1: toggle_sort_order used to default to "r", which is also
+	// rename. Files that kept both move sort order to its new default.
+	func(c *Config) {
+		if c.Keybindings.ToggleSortOrder == "r" && c.Keybindings.Rename == "r" {
+			c.Keybindings.ToggleSortOrder = "o"
+		}
+	},
+	// 1 -> 2: cut became "d d" and delete "D". Files that still bind
+	// delete to "d" would clash with the new cut chord, so cut goes back
+	// to its old key.
+	func(c *Config) {
+		if c.Keybindings.Delete == "d" && c.Keybindings.Cut == "d d" {
+			c.Keybindings.Cut = "x"
+		}
+	},
+}
+
+// migrate applies every migration newer than version and marks cfg current.
+func migrate(cfg *Config, version int) {
+	for v := version; v < len(migrations); v++ {
+		migrations[v](cfg)
+	}
+	cfg.Version = CurrentVersion
+}
+
+// Upgrade rewrites an outdated config file in the current format, filling
+// in defaults for new settings. The old file is kept next to it with a
+// timestamped .bak suffix, whose path is returned. Returns an empty path
+// when there is no config file or it is already current.
+func Upgrade() (backup string, err error) {
+	configPath, err := Path()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config path: %w", err)
+	}
+
+	// #nosec G304 -- configPath is derived from XDG spec, not user input
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var header struct {
+		Version int `toml:"version"`
+	}
+	if err := toml.Unmarshal(data, &header); err != nil {
+		return "", fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if header.Version >= CurrentVersion {
+		return "", nil
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return "", err
+	}
+
+	backup = fmt.Sprintf("%s.v%d-%s.bak", configPath, header.Version, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up config file: %w", err)
+	}
+	if err := Save(cfg); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMigrates(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string // toggle_sort_order after parsing
+	}{
+		{"old default clashing with rename", "[keybindings]\ntoggle_sort_order = \"r\"\n", "o"},
+		{"old default with rename moved", "[keybindings]\ntoggle_sort_order = \"r\"\nrename = \"R\"\n", "r"},
+		{"version 1 is not migrated again", "version = 1\n[keybindings]\ntoggle_sort_order = \"r\"\n", "r"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if cfg.Keybindings.ToggleSortOrder != tt.want {
+				t.Errorf("ToggleSortOrder = %q, want %q", cfg.Keybindings.ToggleSortOrder, tt.want)
+			}
+			if cfg.Version != CurrentVersion {
+				t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion)
+			}
+		})
+	}
+}
+
+func TestParseMigratesCutChord(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantCut    string
+		wantDelete string
+	}{
+		{"delete kept on d", "version = 1\n[keybindings]\ndelete = \"d\"\n", "x", "d"},
+		{"old defaults written out", "version = 1\n[keybindings]\ncut = \"x\"\ndelete = \"d\"\n", "x", "d"},
+		{"new defaults", "version = 1\n", "d d", "D"},
+		{"current version is left alone", "version = 2\n[keybindings]\ndelete = \"d\"\n", "d d", "d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if cfg.Keybindings.Cut != tt.wantCut || cfg.Keybindings.Delete != tt.wantDelete {
+				t.Errorf("cut, delete = %q, %q, want %q, %q",
+					cfg.Keybindings.Cut, cfg.Keybindings.Delete, tt.wantCut, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestParseRejectsNewerVersion(t *testing.T) {
+	if _, err := Parse([]byte("version = 99\n")); err == nil {
+		t.Error("Parse() of a newer config version should fail")
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmpDir)
+
+	configDir := filepath.Join(tmpDir, "warren")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	configPath := filepath.Join(configDir, "config.toml")
+	old := "[keybindings]\nquit = \"Q\"\ntoggle_sort_order = \"r\"\n"
+	if err := os.WriteFile(configPath, []byte(old), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	backup, err := Upgrade()
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if !strings.HasPrefix(backup, configPath+".v0-") {
+		t.Errorf("Upgrade() backup = %q, want prefix %q", backup, configPath+".v0-")
+	}
+
+	saved, err := os.ReadFile(backup)
+	if err != nil || string(saved) != old {
+		t.Errorf("backup contents = %q (err %v), want original file", saved, err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() after upgrade error = %v", err)
+	}
+	if cfg.Keybindings.Quit != "Q" {
+		t.Errorf("Upgrade() lost user setting: quit = %q", cfg.Keybindings.Quit)
+	}
+	if cfg.Keybindings.ToggleSortOrder != "o" {
+		t.Errorf("Upgrade() toggle_sort_order = %q, want %q", cfg.Keybindings.ToggleSortOrder, "o")
+	}
+
+	// A current file is left alone
+	backup, err = Upgrade()
+	if err != nil || backup != "" {
+		t.Errorf("second Upgrade() = %q, %v; want no backup", backup, err)
+	}
+}
+
+func TestUpgradeNoConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if backup, err := Upgrade(); err != nil || backup != "" {
+		t.Errorf("Upgrade() without config = %q, %v; want no-op", backup, err)
+	}
+}
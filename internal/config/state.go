@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// StateDir returns the directory where Warren keeps data it records
+// itself, such as workspace memory, as opposed to settings the user
+// edits. Follows XDG Base Directory specification: $XDG_STATE_HOME/warren
+// or defaults to ~/.local/state/warren if XDG_STATE_HOME is not set.
+func StateDir() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateHome, "warren"), nil
+}
+
+// MigrateState moves the named files, which releases before StateDir
+// kept in the config directory, to the state directory. A file already in
+// the state directory wins and the old copy is left alone. Returns the
+// names of the files moved.
+func MigrateState(names ...string) ([]string, error) {
+	configDir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	stateDir, err := StateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var moved []string
+	for _, name := range names {
+		oldPath := filepath.Join(configDir, name)
+		newPath := filepath.Join(stateDir, name)
+		if _, err := os.Lstat(oldPath); err != nil {
+			continue
+		}
+		if _, err := os.Lstat(newPath); err == nil {
+			continue
+		}
+
+		if err := os.MkdirAll(stateDir, 0700); err != nil {
+			return moved, fmt.Errorf("failed to create state directory: %w", err)
+		}
+		if err := moveFile(oldPath, newPath); err != nil {
+			return moved, fmt.Errorf("failed to move %s to %s: %w", name, stateDir, err)
+		}
+		moved = append(moved, name)
+	}
+	return moved, nil
+}
+
+// moveFile renames oldPath to newPath, copying it when they are on
+// different filesystems.
+func moveFile(oldPath, newPath string) error {
+	err := os.Rename(oldPath, newPath)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	// #nosec G304 -- oldPath is a state file in the config directory
+	data, err := os.ReadFile(oldPath)
+	if err != nil {
+		return err
+	}
+	if err := fileops.WriteFileAtomic(newPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Remove(oldPath)
+}
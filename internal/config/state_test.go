@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStateDir(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	dir, err := StateDir()
+	if err != nil {
+		t.Fatalf("StateDir() error = %v", err)
+	}
+	if dir != "/state/warren" {
+		t.Errorf("StateDir() = %s, want /state/warren", dir)
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/user")
+	dir, err = StateDir()
+	if err != nil {
+		t.Fatalf("StateDir() error = %v", err)
+	}
+	if dir != "/home/user/.local/state/warren" {
+		t.Errorf("StateDir() = %s, want /home/user/.local/state/warren", dir)
+	}
+}
+
+func TestMigrateState(t *testing.T) {
+	configHome, stateHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	configDir := filepath.Join(configHome, "warren")
+	stateDir := filepath.Join(stateHome, "warren")
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(configDir, "memory.json"), "old memory")
+	write(filepath.Join(configDir, "history"), "old history")
+	write(filepath.Join(stateDir, "history"), "new history")
+	write(filepath.Join(configDir, "config.toml"), "settings")
+
+	moved, err := MigrateState("memory.json", "history", "missing")
+	if err != nil {
+		t.Fatalf("MigrateState() error = %v", err)
+	}
+	if !slices.Equal(moved, []string{"memory.json"}) {
+		t.Errorf("MigrateState() moved %q, want only memory.json", moved)
+	}
+
+	expected := map[string]string{
+		filepath.Join(stateDir, "memory.json"):  "old memory",
+		filepath.Join(stateDir, "history"):      "new history",
+		filepath.Join(configDir, "history"):     "old history",
+		filepath.Join(configDir, "config.toml"): "settings",
+	}
+	for path, want := range expected {
+		if data, err := os.ReadFile(path); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", path, data, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "memory.json")); !os.IsNotExist(err) {
+		t.Error("memory.json should have left the config directory")
+	}
+
+	// Running again finds nothing to do
+	if moved, err := MigrateState("memory.json"); err != nil || len(moved) != 0 {
+		t.Errorf("second MigrateState() = %q, %v, want nothing moved", moved, err)
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// template is a config file listing every option with its default value
+// and a comment explaining it.
+//
+//go:embed template.toml
+var template []byte
+
+// Template returns the commented config file Warren writes on first
+// launch, with Hyprland integration enabled or not.
+func Template(hyprland bool) []byte {
+	if hyprland {
+		return bytes.Clone(template)
+	}
+
+	// Only the [hyprland] section's switch: [preview] has one too
+	section := bytes.Index(template, []byte("\n[hyprland]\n"))
+	enabled := -1
+	if section >= 0 {
+		enabled = bytes.Index(template[section:], []byte("\nenabled = true\n"))
+	}
+	if enabled < 0 {
+		panic("config template has no [hyprland] enabled setting")
+	}
+	at := section + enabled + len("\nenabled = ")
+
+	out := make([]byte, 0, len(template)+1)
+	out = append(out, template[:at]...)
+	out = append(out, "false"...)
+	return append(out, template[at+len("true"):]...)
+}
+
+// Scaffold writes the commented template to the config file if there is
+// none yet, so a new user has every option to hand. Reports whether it
+// wrote one, which means this is Warren's first launch.
+func Scaffold() (bool, error) {
+	configPath, err := Path()
+	if err != nil {
+		return false, fmt.Errorf("failed to get config path: %w", err)
+	}
+	if _, err := os.Lstat(configPath); !os.IsNotExist(err) {
+		return false, nil
+	}
+	if err := WriteTemplate(true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WriteTemplate replaces the config file with the commented template,
+// with Hyprland integration enabled or not.
+func WriteTemplate(hyprland bool) error {
+	configPath, err := Path()
+	if err != nil {
+		return fmt.Errorf("failed to get config path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := fileops.WriteFileAtomic(configPath, Template(hyprland), 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	for _, hyprland := range []bool{true, false} {
+		cfg, err := Parse(Template(hyprland))
+		if err != nil {
+			t.Fatalf("Parse(Template(%v)) error = %v", hyprland, err)
+		}
+		if cfg.Hyprland.Enabled != hyprland {
+			t.Errorf("Template(%v): hyprland.enabled = %v", hyprland, cfg.Hyprland.Enabled)
+		}
+		if !cfg.Preview.Enabled {
+			t.Errorf("Template(%v): preview.enabled = false, want it left alone", hyprland)
+		}
+		if errs := Validate(cfg); len(errs) > 0 {
+			t.Errorf("Validate(Template(%v)) = %v, want no errors", hyprland, errs)
+		}
+	}
+}
+
+func TestScaffold(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configPath, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrote, err := Scaffold()
+	if err != nil {
+		t.Fatalf("Scaffold() error = %v", err)
+	}
+	if !wrote {
+		t.Error("Scaffold() = false, want true without a config file")
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, Template(true)) {
+		t.Error("Scaffold() did not write the template")
+	}
+
+	// An existing file is left alone
+	if err := os.WriteFile(configPath, []byte("version = 2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wrote, err = Scaffold()
+	if err != nil {
+		t.Fatalf("Scaffold() error = %v", err)
+	}
+	if wrote {
+		t.Error("Scaffold() = true, want false with a config file")
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "version = 2\n" {
+		t.Errorf("Scaffold() replaced the config file with %d bytes", len(data))
+	}
+
+	if err := WriteTemplate(false); err != nil {
+		t.Fatalf("WriteTemplate(false) error = %v", err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Hyprland.Enabled {
+		t.Error("WriteTemplate(false) left Hyprland integration enabled")
+	}
+}
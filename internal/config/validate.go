@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/logging"
+	"github.com/lawrab/warren/internal/theme"
+)
+
+// maxPreviewKB caps preview.max_text_kb; larger previews make the pane
+// slow to lay out.
+const maxPreviewKB = 4096
+
+// Validate checks settings that parse as TOML but cannot work: unknown
+// key names, conflicting keybindings, unrecognised sort values and theme
+// settings. It returns every problem found; Warren still starts with an
+// invalid config, ignoring or defaulting the bad values.
+func Validate(cfg *Config) []error {
+	var errs []error
+
+	// Binding into a scratch keymap catches both identical bindings and
+	// chords that shadow each other ("g" and "g g")
+	km := keymap.New()
+	for _, b := range cfg.Keybindings.Bindings() {
+		if b.Key == "" {
+			continue
+		}
+		seq, err := keymap.Parse(b.Key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("keybindings.%s: %w", b.Action, err))
+			continue
+		}
+		for _, key := range seq {
+			if !key.Known() {
+				errs = append(errs, fmt.Errorf("keybindings.%s: unknown key %q", b.Action, key.Name))
+			}
+		}
+		// Bind errors already name the action
+		if err := km.Bind(b.Key, b.Action); err != nil {
+			errs = append(errs, fmt.Errorf("keybindings: %w", err))
+		}
+	}
+
+	if _, ok := lookupSortMode(cfg.Appearance.DefaultSortMode); !ok {
+		errs = append(errs, fmt.Errorf("appearance.default_sort_mode: unknown sort mode %q (use name, size, modified, extension or taken)", cfg.Appearance.DefaultSortMode))
+	}
+	if _, ok := lookupSortOrder(cfg.Appearance.DefaultSortOrder); !ok {
+		errs = append(errs, fmt.Errorf("appearance.default_sort_order: unknown sort order %q (use ascending or descending)", cfg.Appearance.DefaultSortOrder))
+	}
+
+	if _, err := theme.ParseScheme(cfg.Appearance.ColorScheme); err != nil {
+		errs = append(errs, fmt.Errorf("appearance.color_scheme: %w", err))
+	}
+	if _, err := theme.ParseDensity(cfg.Appearance.Density); err != nil {
+		errs = append(errs, fmt.Errorf("appearance.density: %w", err))
+	}
+	if _, err := fileops.ParseTimeFormat(cfg.Appearance.DateFormat); err != nil {
+		errs = append(errs, fmt.Errorf("appearance.date_format: %w", err))
+	}
+	if _, err := fileops.ParseSizeFormat(cfg.Appearance.SizeFormat); err != nil {
+		errs = append(errs, fmt.Errorf("appearance.size_format: %w", err))
+	}
+	if _, err := theme.ParseEllipsize(cfg.Appearance.Ellipsize); err != nil {
+		errs = append(errs, fmt.Errorf("appearance.ellipsize: %w", err))
+	}
+	if err := theme.ValidateColor(cfg.Appearance.AccentColor); err != nil {
+		errs = append(errs, fmt.Errorf("appearance.accent_color: %w", err))
+	}
+
+	if cfg.Appearance.IconSize < MinIconSize || cfg.Appearance.IconSize > MaxIconSize {
+		errs = append(errs, fmt.Errorf("appearance.icon_size: %d must be between %d and %d", cfg.Appearance.IconSize, MinIconSize, MaxIconSize))
+	}
+	if cfg.Appearance.WindowWidth <= 0 || cfg.Appearance.WindowHeight <= 0 {
+		errs = append(errs, fmt.Errorf("appearance: window size %dx%d must be positive", cfg.Appearance.WindowWidth, cfg.Appearance.WindowHeight))
+	}
+	if cfg.General.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("general.poll_interval: %d must be at least 1 second", cfg.General.PollInterval))
+	}
+	if cfg.General.ShredPasses < 0 || cfg.General.ShredPasses > fileops.MaxShredPasses {
+		errs = append(errs, fmt.Errorf("general.shred_passes: %d must be between 0 and %d", cfg.General.ShredPasses, fileops.MaxShredPasses))
+	}
+	if _, err := logging.ParseLevel(cfg.General.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("general.log_level: %w", err))
+	}
+	if cfg.General.IOLimit < 0 {
+		errs = append(errs, fmt.Errorf("general.io_limit: %v must not be negative", cfg.General.IOLimit))
+	}
+	if _, err := fileops.ParseSymlinkPolicy(cfg.General.Symlinks); err != nil {
+		errs = append(errs, fmt.Errorf("general.symlinks: %w", err))
+	}
+	if strings.ContainsAny(cfg.Hyprland.OpenOnWorkspace, ",;[]") {
+		errs = append(errs, fmt.Errorf("hyprland.open_on_workspace: %q is not a workspace name", cfg.Hyprland.OpenOnWorkspace))
+	}
+	if cfg.Preview.MaxTextKB < 1 || cfg.Preview.MaxTextKB > maxPreviewKB {
+		errs = append(errs, fmt.Errorf("preview.max_text_kb: %d must be between 1 and %d", cfg.Preview.MaxTextKB, maxPreviewKB))
+	}
+	if cfg.Confirm.LargeOperation < 0 {
+		errs = append(errs, fmt.Errorf("confirm.large_operation: %v must not be negative", cfg.Confirm.LargeOperation))
+	}
+	if cfg.Confirm.LargeDelete < 0 {
+		errs = append(errs, fmt.Errorf("confirm.large_delete: %v must not be negative", cfg.Confirm.LargeDelete))
+	}
+	if cfg.Confirm.LargeDeleteItems < 0 {
+		errs = append(errs, fmt.Errorf("confirm.large_delete_items: %d must not be negative", cfg.Confirm.LargeDeleteItems))
+	}
+	patterns := make([]string, 0, len(cfg.Openers))
+	for pattern := range cfg.Openers {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if err := fileops.CheckOpener(pattern, cfg.Openers[pattern]); err != nil {
+			errs = append(errs, fmt.Errorf("openers: %w", err))
+		}
+	}
+
+	return errs
+}
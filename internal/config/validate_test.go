@@ -0,0 +1,72 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDefault(t *testing.T) {
+	if errs := Validate(Default()); len(errs) > 0 {
+		t.Errorf("Validate(Default()) = %v, want no errors", errs)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	cfg, err := Parse(Template(true))
+	if err != nil {
+		t.Fatalf("Parse(template) error = %v", err)
+	}
+	if errs := Validate(cfg); len(errs) > 0 {
+		t.Errorf("Validate(template) = %v, want no errors", errs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string // substring of the single expected error
+	}{
+		{"unknown key name", func(c *Config) { c.Keybindings.Quit = "Escpae" }, "keybindings.quit: unknown key"},
+		{"bad modifier", func(c *Config) { c.Keybindings.Quit = "<Hyper>q" }, "keybindings.quit: unknown modifier"},
+		{"duplicate", func(c *Config) { c.Keybindings.Yank = "D" }, `keybindings: delete: "D" conflicts`},
+		{"chord prefix", func(c *Config) { c.Keybindings.Jump = "g" }, "bound to go_top"},
+		{"sort mode", func(c *Config) { c.Appearance.DefaultSortMode = "date" }, "default_sort_mode"},
+		{"sort order", func(c *Config) { c.Appearance.DefaultSortOrder = "up" }, "default_sort_order"},
+		{"color scheme", func(c *Config) { c.Appearance.ColorScheme = "dusk" }, "appearance.color_scheme"},
+		{"density", func(c *Config) { c.Appearance.Density = "tiny" }, "appearance.density"},
+		{"date format", func(c *Config) { c.Appearance.DateFormat = "rfc3339" }, "appearance.date_format"},
+		{"size format", func(c *Config) { c.Appearance.SizeFormat = "kibibytes" }, "appearance.size_format"},
+		{"icon size", func(c *Config) { c.Appearance.IconSize = 4 }, "appearance.icon_size"},
+		{"ellipsize", func(c *Config) { c.Appearance.Ellipsize = "start" }, "appearance.ellipsize"},
+		{"poll interval", func(c *Config) { c.General.PollInterval = 0 }, "general.poll_interval"},
+		{"shred passes", func(c *Config) { c.General.ShredPasses = -1 }, "general.shred_passes"},
+		{"io limit", func(c *Config) { c.General.IOLimit = -5 }, "general.io_limit"},
+		{"symlinks", func(c *Config) { c.General.Symlinks = "follow" }, "general.symlinks"},
+		{"log level", func(c *Config) { c.General.LogLevel = "verbose" }, "general.log_level"},
+		{"accent color", func(c *Config) { c.Appearance.AccentColor = "#12" }, "appearance.accent_color"},
+		{"window size", func(c *Config) { c.Appearance.WindowWidth = 0 }, "window size"},
+		{"large operation", func(c *Config) { c.Confirm.LargeOperation = -1 }, "large_operation"},
+		{"large delete", func(c *Config) { c.Confirm.LargeDelete = -1 }, "large_delete"},
+		{"large delete items", func(c *Config) { c.Confirm.LargeDeleteItems = -1 }, "large_delete_items"},
+		{"open on workspace", func(c *Config) { c.Hyprland.OpenOnWorkspace = "2,3" }, "hyprland.open_on_workspace"},
+		{"preview size", func(c *Config) { c.Preview.MaxTextKB = 0 }, "preview.max_text_kb"},
+		{"preview too large", func(c *Config) { c.Preview.MaxTextKB = 1 << 20 }, "preview.max_text_kb"},
+		{"opener pattern", func(c *Config) { c.Openers = map[string]string{"[pdf": "zathura"} }, "openers: bad pattern"},
+		{"opener command", func(c *Config) { c.Openers = map[string]string{"*.pdf": ""} }, "openers: *.pdf: empty command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			errs := Validate(cfg)
+			if len(errs) != 1 {
+				t.Fatalf("Validate() = %v, want exactly one error", errs)
+			}
+			if !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("Validate() error = %q, want it to contain %q", errs[0], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// WindowStateFile is the window state's file name in the state directory.
+const WindowStateFile = "window.json"
+
+// Limits on restored window state. A saved size below minWindowSize or a
+// split outside minSplit..1-minSplit, which would hide a pane, is ignored.
+const (
+	minWindowSize = 200
+	minSplit      = 0.1
+)
+
+// WindowState is the layout of the last main window closed, restored
+// when appearance.remember_window is set. Splits are the fraction of the
+// width given to the left side, so they carry over between sizes.
+type WindowState struct {
+	Width        int          `json:"width"`
+	Height       int          `json:"height"`
+	Maximized    bool         `json:"maximized"`
+	PreviewSplit float64      `json:"preview_split"` // File list beside the preview
+	PaneSplit    float64      `json:"pane_split"`    // First pane in dual-pane mode
+	Panes        [2]ViewState `json:"panes"`         // The first pane, then the second
+}
+
+// ViewState is what one pane shows and how it lists it. Each pane keeps
+// its own, so sorting or filtering one leaves the other as it was. The
+// sort takes the names default_sort_mode and default_sort_order do, and
+// Filter is as fileops.ParseNameFilter reads it.
+type ViewState struct {
+	Path       string `json:"path,omitempty"`     // Empty before the pane first loads
+	Selected   string `json:"selected,omitempty"` // Path of the selected entry
+	SortMode   string `json:"sort_mode"`
+	SortOrder  string `json:"sort_order"`
+	ShowHidden bool   `json:"show_hidden"`
+	Filter     string `json:"filter,omitempty"`
+}
+
+// DefaultViewState returns how a pane lists directories without saved
+// state: the configured sort and hidden files, unfiltered.
+func DefaultViewState(cfg *Config) ViewState {
+	return ViewState{
+		SortMode:   cfg.Appearance.DefaultSortMode,
+		SortOrder:  cfg.Appearance.DefaultSortOrder,
+		ShowHidden: cfg.Appearance.ShowHidden,
+	}
+}
+
+// DefaultWindowState returns the layout of a window opened without saved
+// state: the configured size, three fifths of it for the file list,
+// dual-pane mode split down the middle and both panes listing as the
+// config says.
+func DefaultWindowState(cfg *Config) WindowState {
+	view := DefaultViewState(cfg)
+	return WindowState{
+		Width:        cfg.Appearance.WindowWidth,
+		Height:       cfg.Appearance.WindowHeight,
+		PreviewSplit: 0.6,
+		PaneSplit:    0.5,
+		Panes:        [2]ViewState{view, view},
+	}
+}
+
+// LoadWindowState reads the window state saved in stateDir over defaults.
+// Values that are missing or out of range keep their default. A missing
+// file is not an error.
+func LoadWindowState(stateDir string, defaults WindowState) (WindowState, error) {
+	// #nosec G304 -- the path is in Warren's state directory
+	data, err := os.ReadFile(filepath.Join(stateDir, WindowStateFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return defaults, nil
+	}
+	if err != nil {
+		return defaults, err
+	}
+
+	var saved WindowState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return defaults, fmt.Errorf("invalid %s: %w", WindowStateFile, err)
+	}
+
+	state := defaults
+	if saved.Width >= minWindowSize && saved.Height >= minWindowSize {
+		state.Width, state.Height = saved.Width, saved.Height
+	}
+	state.Maximized = saved.Maximized
+	if validSplit(saved.PreviewSplit) {
+		state.PreviewSplit = saved.PreviewSplit
+	}
+	if validSplit(saved.PaneSplit) {
+		state.PaneSplit = saved.PaneSplit
+	}
+	for i, view := range saved.Panes {
+		// Files from before panes were saved have none
+		if view.SortMode != "" {
+			state.Panes[i] = view
+		}
+	}
+	return state, nil
+}
+
+// SaveWindowState writes state to stateDir, creating it if needed.
+func SaveWindowState(stateDir string, state WindowState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return fileops.WriteFileAtomic(filepath.Join(stateDir, WindowStateFile), data, 0600)
+}
+
+// validSplit reports whether split leaves both sides of a pane visible.
+func validSplit(split float64) bool {
+	return split >= minSplit && split <= 1-minSplit
+}
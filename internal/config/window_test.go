@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWindowStateRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "warren")
+	defaults := DefaultWindowState(Default())
+
+	// Nothing saved yet
+	state, err := LoadWindowState(dir, defaults)
+	if err != nil {
+		t.Fatalf("LoadWindowState() error = %v", err)
+	}
+	if state != defaults {
+		t.Errorf("LoadWindowState() = %+v without a file, want %+v", state, defaults)
+	}
+
+	want := WindowState{Width: 1400, Height: 900, Maximized: true, PreviewSplit: 0.7, PaneSplit: 0.4}
+	want.Panes[0] = ViewState{
+		Path:      "/home/user/src",
+		Selected:  "/home/user/src/go.mod",
+		SortMode:  "Modified",
+		SortOrder: "descending",
+		Filter:    "*.go",
+	}
+	want.Panes[1] = ViewState{Path: "/tmp", SortMode: "Name", SortOrder: "ascending", ShowHidden: true}
+	if err := SaveWindowState(dir, want); err != nil {
+		t.Fatalf("SaveWindowState() error = %v", err)
+	}
+	state, err = LoadWindowState(dir, defaults)
+	if err != nil {
+		t.Fatalf("LoadWindowState() error = %v", err)
+	}
+	if state != want {
+		t.Errorf("LoadWindowState() = %+v, want %+v", state, want)
+	}
+}
+
+func TestLoadWindowStateOutOfRange(t *testing.T) {
+	dir := t.TempDir()
+	defaults := DefaultWindowState(Default())
+	path := filepath.Join(dir, WindowStateFile)
+
+	// Saved before panes were, so they keep the configured listing
+	data := `{"width": 50, "height": 900, "maximized": true, "preview_split": 0.99}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	state, err := LoadWindowState(dir, defaults)
+	if err != nil {
+		t.Fatalf("LoadWindowState() error = %v", err)
+	}
+	want := defaults
+	want.Maximized = true
+	if state != want {
+		t.Errorf("LoadWindowState() = %+v, want %+v", state, want)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	state, err = LoadWindowState(dir, defaults)
+	if err == nil {
+		t.Error("LoadWindowState() accepted invalid JSON")
+	}
+	if state != defaults {
+		t.Errorf("LoadWindowState() = %+v after an error, want the defaults", state)
+	}
+}
@@ -0,0 +1,393 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lawrab/warren/internal/bookmarks"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/registers"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// FileView is the file list a Controller acts on. *ui.FileView implements
+// it; tests use a fake.
+type FileView interface {
+	GetSelected() *models.FileInfo // Nil if the list is empty
+	SelectedIndex() int            // -1 if the list is empty
+	GetFileCount() int
+	FileNames() []string
+	GetCurrentPath() string
+
+	MoveSelection(delta int)
+	SelectIndex(index int)
+	SelectFirst()
+	SelectLast()
+	SelectLine(n int) // 1-based, as a count gives it
+	HalfPageDown()
+	HalfPageUp()
+	SelectViewTop(offset int)
+	SelectViewMiddle()
+	SelectViewBottom(offset int)
+
+	YankSelected()
+	YankRange(count int, cut bool) int
+	RangePaths(count int) []string // Of count entries from the selection
+	IsYanked(path string) bool
+	ClearYanked()
+
+	ToggleHidden() error
+	Filter() fileops.NameFilter
+	SetFilter(filter fileops.NameFilter) error
+	CycleSortMode() error
+	ToggleSortOrder() error
+
+	TreeMode() bool
+	SetTreeMode(enabled bool) error
+	ToggleExpanded() error
+	CollapseParent() bool
+	CollapseAll()
+	ExpandToLevel(depth int)
+
+	// HoldReloads defers reloads until release is called, so the entry
+	// being renamed stays put
+	HoldReloads() (release func())
+}
+
+// Status shows messages and prompts. *ui.StatusBar implements it.
+type Status interface {
+	Info(text string)
+	Warn(text string)
+	Error(text string)
+	SetPrompt(text string) // "" clears it
+}
+
+// Host does what needs the toolkit or the rest of the application.
+type Host interface {
+	// Active returns the focused file view
+	Active() FileView
+
+	// Run runs the action or init.lua command called name with a count.
+	// It returns false for an unknown name.
+	Run(name string, n int, given bool) bool
+
+	// Commands lists the names Run accepts, for command completion
+	Commands() []string
+
+	// SelectionChanged updates the status bar summary after the
+	// selection, the yank or the listing changed
+	SelectionChanged()
+
+	// SortChanged updates the sort indicator
+	SortChanged()
+
+	// Open enters a directory or opens a file with its application
+	Open(file *models.FileInfo)
+
+	// Leave loads the parent of the current directory
+	Leave()
+
+	// Rename renames file to newPath and reports the result
+	Rename(file *models.FileInfo, newPath string)
+
+	// PasteTo pastes a register (registers.Unnamed for the view's yank)
+	// into dir
+	PasteTo(register rune, dir string)
+
+	// Bookmarks lists the bookmarked directories paste-to mode offers
+	Bookmarks() []bookmarks.Bookmark
+
+	// RunFile runs an executable with arguments and shows its output
+	RunFile(file *models.FileInfo, args []string)
+
+	// Editing is called with true when a line mode starts and false when
+	// it ends, so input method commits can be routed to Insert meanwhile
+	Editing(active bool)
+}
+
+// Controller turns key presses into changes to the focused view. It is
+// not safe for concurrent use; cmd/warren only calls it on the GTK main
+// thread.
+type Controller struct {
+	host   Host
+	status Status
+
+	// Named registers, and the one named for the next yank, cut or paste
+	// (0 for the view's own yank) with the mode to go back to after
+	// reading it
+	regs         *registers.Registers
+	register     rune
+	registerFrom keymap.Mode
+
+	modes  *keymap.Dispatcher
+	normal *keymap.Bindings
+	visual *keymap.Bindings
+	lines  map[keymap.Mode]*lineMode
+
+	// In jump mode, printable keys build a name prefix to select
+	jump *keymap.TypeAhead
+
+	// Visual mode's range runs from the entry at visualAnchor to the
+	// selection
+	visualAnchor int
+
+	// The entry rename mode was entered for, and the hold on its view's
+	// reloads, released when a line mode ends
+	renaming *models.FileInfo
+	release  func()
+
+	// The register paste-to mode pastes
+	pasteRegister rune
+
+	// The executable run mode reads arguments for
+	running *models.FileInfo
+}
+
+// New creates a controller in normal mode, yanking into regs when a
+// register is named. Keys do nothing until SetKeymaps is called.
+func New(host Host, status Status, regs *registers.Registers) *Controller {
+	c := &Controller{
+		host:   host,
+		status: status,
+		regs:   regs,
+		modes:  keymap.NewDispatcher(),
+		jump:   keymap.NewTypeAhead(),
+	}
+
+	c.normal = &keymap.Bindings{
+		Keymap: keymap.New(),
+		Run: func(name string, n int, given bool) {
+			host.Run(name, n, given)
+			// A register only applies to the command right after it
+			if name != actionRegister {
+				c.dropRegister()
+			}
+		},
+		Prompt: c.showCount,
+	}
+	c.modes.Handle(keymap.ModeNormal, c.normal)
+
+	c.visual = &keymap.Bindings{
+		Keymap: keymap.New(),
+		Run:    c.runVisual,
+		Prompt: c.showVisual,
+	}
+	c.modes.Handle(keymap.ModeVisual, c.visual)
+
+	c.modes.Handle(keymap.ModeJump, keymap.HandlerFunc(c.jumpKey))
+	c.modes.Handle(keymap.ModeRegister, keymap.HandlerFunc(c.registerKey))
+
+	c.lines = make(map[keymap.Mode]*lineMode)
+	c.addLineMode(keymap.ModeCommand, ":", c.runCommand, c.completeCommand)
+	c.addLineMode(keymap.ModeFilter, "Filter: ", c.applyFilter, nil)
+	c.addLineMode(keymap.ModeRename, "Rename: ", c.renameSelected, nil)
+	c.addLineMode(keymap.ModePasteTo, "Paste to: ", c.pasteTo, c.completeDestination)
+	c.addLineMode(keymap.ModeRun, "Run: ", c.runSelected, nil)
+	return c
+}
+
+// SetKeymaps sets the keymaps of normal and visual mode, e.g. after the
+// config changed.
+func (c *Controller) SetKeymaps(normal, visual *keymap.Keymap) {
+	c.normal.Keymap = normal
+	c.visual.Keymap = visual
+}
+
+// Feed handles a key press in the current mode and reports whether it was
+// used.
+func (c *Controller) Feed(ev keymap.Event, now time.Time) bool {
+	return c.modes.Feed(ev, now)
+}
+
+// Mode returns the current keyboard mode.
+func (c *Controller) Mode() keymap.Mode {
+	return c.modes.Mode()
+}
+
+// Move moves the selection by delta entries.
+func (c *Controller) Move(delta int) {
+	c.host.Active().MoveSelection(delta)
+	c.host.SelectionChanged()
+}
+
+// GoTop selects line n, or the first entry without a count.
+func (c *Controller) GoTop(n int, given bool) {
+	if given {
+		c.host.Active().SelectLine(n)
+	} else {
+		c.host.Active().SelectFirst()
+	}
+	c.host.SelectionChanged()
+}
+
+// GoBottom selects line n, or the last entry without a count.
+func (c *Controller) GoBottom(n int, given bool) {
+	if given {
+		c.host.Active().SelectLine(n)
+	} else {
+		c.host.Active().SelectLast()
+	}
+	c.host.SelectionChanged()
+}
+
+// HalfPage moves the selection half a page down, or up if down is false.
+func (c *Controller) HalfPage(down bool) {
+	if down {
+		c.host.Active().HalfPageDown()
+	} else {
+		c.host.Active().HalfPageUp()
+	}
+	c.host.SelectionChanged()
+}
+
+// ViewTop selects the entry n-1 rows below the top of the viewport, as H
+// does in vim.
+func (c *Controller) ViewTop(n int) {
+	c.host.Active().SelectViewTop(n - 1)
+	c.host.SelectionChanged()
+}
+
+// ViewMiddle selects the entry in the middle of the viewport.
+func (c *Controller) ViewMiddle() {
+	c.host.Active().SelectViewMiddle()
+	c.host.SelectionChanged()
+}
+
+// ViewBottom selects the entry n-1 rows above the bottom of the viewport.
+func (c *Controller) ViewBottom(n int) {
+	c.host.Active().SelectViewBottom(n - 1)
+	c.host.SelectionChanged()
+}
+
+// Enter enters the selected directory, expands it in tree mode, or opens
+// the selected file.
+func (c *Controller) Enter() {
+	fileView := c.host.Active()
+	selected := fileView.GetSelected()
+	switch {
+	case selected == nil:
+	case selected.IsDir && fileView.TreeMode():
+		c.report(fileView.ToggleExpanded())
+	default:
+		c.host.Open(selected)
+	}
+}
+
+// Parent leaves the current directory, or in tree mode collapses the
+// expanded directory holding the selection.
+func (c *Controller) Parent() {
+	if c.host.Active().CollapseParent() {
+		c.host.SelectionChanged()
+		return
+	}
+	c.host.Leave()
+}
+
+// Yank yanks n files from the selection, or without a count toggles
+// whether the selected file is yanked. After a register is named, the
+// files go into it instead.
+func (c *Controller) Yank(n int, given bool) {
+	fileView := c.host.Active()
+	if name := c.TakeRegister(); name != registers.Unnamed {
+		c.yankInto(name, fileView.RangePaths(n), false)
+		return
+	}
+	if given {
+		yanked := fileView.YankRange(n, false)
+		c.host.SelectionChanged()
+		c.status.Info(fmt.Sprintf("Yanked %d file(s)", yanked))
+		return
+	}
+
+	selected := fileView.GetSelected()
+	if selected == nil {
+		return
+	}
+	if fileView.IsYanked(selected.Path) {
+		fileView.ClearYanked()
+		c.status.Info(fmt.Sprintf("Unyanked: %s", selected.Name))
+	} else {
+		fileView.YankSelected()
+		c.status.Info(fmt.Sprintf("Yanked: %s", selected.Name))
+	}
+	c.host.SelectionChanged()
+}
+
+// Cut marks n files from the selection to be moved on paste, in the named
+// register if there is one.
+func (c *Controller) Cut(n int) {
+	fileView := c.host.Active()
+	if name := c.TakeRegister(); name != registers.Unnamed {
+		c.yankInto(name, fileView.RangePaths(n), true)
+		return
+	}
+	if cut := fileView.YankRange(n, true); cut > 0 {
+		c.host.SelectionChanged()
+		c.status.Info(fmt.Sprintf("Cut %d file(s)", cut))
+	}
+}
+
+// yankInto stores paths in a named register.
+func (c *Controller) yankInto(name rune, paths []string, cut bool) {
+	if len(paths) == 0 {
+		return
+	}
+	c.regs.Set(name, registers.Register{Paths: paths, Cut: cut})
+	verb := "Yanked"
+	if cut {
+		verb = "Cut"
+	}
+	c.status.Info(fmt.Sprintf("%s %d file(s) into %s", verb, len(paths), registers.Name(name)))
+}
+
+// ToggleHidden shows or hides dotfiles.
+func (c *Controller) ToggleHidden() {
+	c.report(c.host.Active().ToggleHidden())
+}
+
+// ToggleTree switches tree mode on or off.
+func (c *Controller) ToggleTree() {
+	fileView := c.host.Active()
+	c.report(fileView.SetTreeMode(!fileView.TreeMode()))
+}
+
+// CollapseAll collapses every expanded directory.
+func (c *Controller) CollapseAll() {
+	c.host.Active().CollapseAll()
+	c.host.SelectionChanged()
+}
+
+// ExpandLevels expands directories depth levels deep.
+func (c *Controller) ExpandLevels(depth int) {
+	c.host.Active().ExpandToLevel(depth)
+	c.host.SelectionChanged()
+}
+
+// CycleSortMode sorts by the next key.
+func (c *Controller) CycleSortMode() {
+	c.reportSort(c.host.Active().CycleSortMode())
+}
+
+// ToggleSortOrder reverses the sort order.
+func (c *Controller) ToggleSortOrder() {
+	c.reportSort(c.host.Active().ToggleSortOrder())
+}
+
+// report shows err in the status bar, or the updated selection without one.
+func (c *Controller) report(err error) {
+	if err != nil {
+		c.status.Error(err.Error())
+		return
+	}
+	c.host.SelectionChanged()
+}
+
+// reportSort is report for sort changes, which also update the sort
+// indicator.
+func (c *Controller) reportSort(err error) {
+	if err == nil {
+		c.host.SortChanged()
+	}
+	c.report(err)
+}
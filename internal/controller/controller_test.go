@@ -0,0 +1,402 @@
+package controller
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lawrab/warren/internal/bookmarks"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/registers"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// fakeView is a FileView over a fixed list of entries in /dir, with a
+// ten-row viewport.
+type fakeView struct {
+	dir      string
+	files    []models.FileInfo
+	selected int
+	yanked   []string
+	cut      bool
+	hidden   bool
+	filter   fileops.NameFilter
+	tree     bool
+	expanded map[string]bool
+	sorts    int
+	held     int // Reload holds not yet released
+	failSort bool
+}
+
+func newFakeView(names ...string) *fakeView {
+	v := &fakeView{dir: "/dir", expanded: make(map[string]bool)}
+	for _, name := range names {
+		v.files = append(v.files, models.FileInfo{
+			Name:  strings.TrimSuffix(name, "/"),
+			Path:  filepath.Join("/dir", strings.TrimSuffix(name, "/")),
+			IsDir: strings.HasSuffix(name, "/"),
+		})
+	}
+	if len(v.files) == 0 {
+		v.selected = -1
+	}
+	return v
+}
+
+func (v *fakeView) GetSelected() *models.FileInfo {
+	if v.selected < 0 {
+		return nil
+	}
+	return &v.files[v.selected]
+}
+
+func (v *fakeView) SelectedIndex() int { return v.selected }
+func (v *fakeView) GetFileCount() int  { return len(v.files) }
+
+func (v *fakeView) FileNames() []string {
+	names := make([]string, len(v.files))
+	for i, f := range v.files {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func (v *fakeView) GetCurrentPath() string { return v.dir }
+
+func (v *fakeView) SelectIndex(index int) {
+	if index >= 0 && index < len(v.files) {
+		v.selected = index
+	}
+}
+
+func (v *fakeView) MoveSelection(delta int) {
+	v.SelectIndex(max(0, min(v.selected+delta, len(v.files)-1)))
+}
+
+func (v *fakeView) SelectFirst()                { v.SelectIndex(0) }
+func (v *fakeView) SelectLast()                 { v.SelectIndex(len(v.files) - 1) }
+func (v *fakeView) SelectLine(n int)            { v.SelectIndex(min(n, len(v.files)) - 1) }
+func (v *fakeView) HalfPageDown()               { v.MoveSelection(5) }
+func (v *fakeView) HalfPageUp()                 { v.MoveSelection(-5) }
+func (v *fakeView) SelectViewTop(offset int)    { v.SelectIndex(offset) }
+func (v *fakeView) SelectViewMiddle()           { v.SelectIndex(min(5, len(v.files)-1)) }
+func (v *fakeView) SelectViewBottom(offset int) { v.SelectIndex(min(9, len(v.files)-1) - offset) }
+
+func (v *fakeView) YankSelected() {
+	v.yanked, v.cut = []string{v.files[v.selected].Path}, false
+}
+
+func (v *fakeView) YankRange(count int, cut bool) int {
+	v.yanked, v.cut = v.RangePaths(count), cut
+	return len(v.yanked)
+}
+
+func (v *fakeView) RangePaths(count int) []string {
+	if v.selected < 0 || count < 1 {
+		return nil
+	}
+	var paths []string
+	for _, f := range v.files[v.selected:min(v.selected+count, len(v.files))] {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
+
+func (v *fakeView) IsYanked(path string) bool { return slices.Contains(v.yanked, path) }
+func (v *fakeView) ClearYanked()              { v.yanked, v.cut = nil, false }
+
+func (v *fakeView) ToggleHidden() error {
+	v.hidden = !v.hidden
+	return nil
+}
+
+func (v *fakeView) Filter() fileops.NameFilter { return v.filter }
+
+func (v *fakeView) SetFilter(filter fileops.NameFilter) error {
+	v.filter = filter
+	return nil
+}
+
+func (v *fakeView) CycleSortMode() error {
+	if v.failSort {
+		return errors.New("sort failed")
+	}
+	v.sorts++
+	return nil
+}
+
+func (v *fakeView) ToggleSortOrder() error { return v.CycleSortMode() }
+func (v *fakeView) TreeMode() bool         { return v.tree }
+
+func (v *fakeView) SetTreeMode(enabled bool) error {
+	v.tree = enabled
+	return nil
+}
+
+func (v *fakeView) ToggleExpanded() error {
+	path := v.files[v.selected].Path
+	v.expanded[path] = !v.expanded[path]
+	return nil
+}
+
+func (v *fakeView) CollapseParent() bool    { return false }
+func (v *fakeView) CollapseAll()            { clear(v.expanded) }
+func (v *fakeView) ExpandToLevel(depth int) {}
+
+func (v *fakeView) HoldReloads() func() {
+	v.held++
+	return func() { v.held-- }
+}
+
+// fakeHost records what the controller asks of the window. Run handles a
+// few actions the way cmd/warren's registry does.
+type fakeHost struct {
+	view     *fakeView
+	c        *Controller
+	calls    []string
+	opened   string
+	renamed  [2]string
+	editing  bool
+	commands []string
+	pasted   rune // Register of the last paste
+	pastedTo string
+	ran      []string // The file run and its arguments
+	marks    []bookmarks.Bookmark
+}
+
+func (h *fakeHost) Active() FileView { return h.view }
+
+func (h *fakeHost) Run(name string, n int, given bool) bool {
+	h.calls = append(h.calls, name)
+	switch name {
+	case "navigate_down":
+		h.c.Move(n)
+	case "navigate_up":
+		h.c.Move(-n)
+	case "go_top":
+		h.c.GoTop(n, given)
+	case "go_bottom":
+		h.c.GoBottom(n, given)
+	case "enter_dir":
+		h.c.Enter()
+	case "yank":
+		h.c.Yank(n, given)
+	case "cut":
+		h.c.Cut(n)
+	case "visual":
+		h.c.StartVisual()
+	case "jump":
+		h.c.StartJump()
+	case "filter":
+		h.c.StartFilter()
+	case "rename":
+		h.c.StartRename()
+	case "command":
+		h.c.StartCommand()
+	case "register":
+		h.c.StartRegister()
+	case "paste":
+		h.pasted = h.c.TakeRegister()
+	case "paste_to":
+		h.c.StartPasteTo()
+	case "run":
+		h.c.StartRun()
+	case "toggle_hidden":
+		h.c.ToggleHidden()
+	case "cycle_sort_mode":
+		h.c.CycleSortMode()
+	default:
+		return slices.Contains(h.commands, name)
+	}
+	return true
+}
+
+func (h *fakeHost) Commands() []string         { return h.commands }
+func (h *fakeHost) SelectionChanged()          { h.calls = append(h.calls, "selection changed") }
+func (h *fakeHost) SortChanged()               { h.calls = append(h.calls, "sort changed") }
+func (h *fakeHost) Open(file *models.FileInfo) { h.opened = file.Path }
+func (h *fakeHost) Leave()                     { h.calls = append(h.calls, "leave") }
+func (h *fakeHost) Rename(file *models.FileInfo, newPath string) {
+	h.renamed = [2]string{file.Path, newPath}
+}
+func (h *fakeHost) Editing(active bool) { h.editing = active }
+func (h *fakeHost) PasteTo(register rune, dir string) {
+	h.pasted, h.pastedTo = register, dir
+}
+func (h *fakeHost) Bookmarks() []bookmarks.Bookmark { return h.marks }
+func (h *fakeHost) RunFile(file *models.FileInfo, args []string) {
+	h.ran = append([]string{file.Path}, args...)
+}
+
+// fakeStatus keeps the latest message and prompt.
+type fakeStatus struct {
+	message string
+	prompt  string
+}
+
+func (s *fakeStatus) Info(text string)      { s.message = "info: " + text }
+func (s *fakeStatus) Warn(text string)      { s.message = "warn: " + text }
+func (s *fakeStatus) Error(text string)     { s.message = "error: " + text }
+func (s *fakeStatus) SetPrompt(text string) { s.prompt = text }
+
+// harness is a controller over a fake view, with the default keybindings.
+type harness struct {
+	t      *testing.T
+	c      *Controller
+	view   *fakeView
+	host   *fakeHost
+	status *fakeStatus
+	regs   *registers.Registers
+	now    time.Time
+}
+
+func newHarness(t *testing.T, names ...string) *harness {
+	t.Helper()
+	cfg := config.Default()
+	normal := keymap.New()
+	for _, b := range cfg.Keybindings.Bindings() {
+		if b.Key != "" {
+			if err := normal.Bind(b.Key, b.Action); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// cmd/warren binds Return like l
+	if err := normal.Bind("Return", "enter_dir"); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &harness{t: t, view: newFakeView(names...), status: &fakeStatus{}, regs: registers.New(""), now: time.Unix(0, 0)}
+	h.host = &fakeHost{view: h.view}
+	h.c = New(h.host, h.status, h.regs)
+	h.host.c = h.c
+	h.c.SetKeymaps(normal, VisualKeymap(cfg))
+	return h
+}
+
+// typeKeys feeds keys, each a single character or a key name such as
+// "Escape" or "<Ctrl>w", each a little after the last.
+func (h *harness) typeKeys(keys ...string) {
+	h.t.Helper()
+	for _, key := range keys {
+		var ev keymap.Event
+		if r := []rune(key); len(r) == 1 {
+			ev = keymap.NewEvent(key, r[0], 0)
+		} else {
+			k, err := keymap.ParseKey(key)
+			if err != nil {
+				h.t.Fatal(err)
+			}
+			ev = keymap.Event{Name: k.Name, Mods: k.Mods}
+		}
+		h.now = h.now.Add(10 * time.Millisecond)
+		h.c.Feed(ev, h.now)
+	}
+}
+
+// typeText types each character of text.
+func (h *harness) typeText(text string) {
+	h.t.Helper()
+	for _, r := range text {
+		h.typeKeys(string(r))
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	h := newHarness(t, "a", "b", "c", "d", "e", "f")
+
+	h.typeKeys("j")
+	if h.view.selected != 1 {
+		t.Errorf("after j, selected = %d, want 1", h.view.selected)
+	}
+	h.typeKeys("3", "j")
+	if h.view.selected != 4 {
+		t.Errorf("after 3j, selected = %d, want 4", h.view.selected)
+	}
+	h.typeKeys("g", "g")
+	if h.view.selected != 0 {
+		t.Errorf("after gg, selected = %d, want 0", h.view.selected)
+	}
+	h.typeKeys("G")
+	if h.view.selected != 5 {
+		t.Errorf("after G, selected = %d, want 5", h.view.selected)
+	}
+	h.typeKeys("2", "G")
+	if h.view.selected != 1 {
+		t.Errorf("after 2G, selected = %d, want 1", h.view.selected)
+	}
+	if h.host.calls[len(h.host.calls)-1] != "selection changed" {
+		t.Errorf("status not updated after moving: %v", h.host.calls)
+	}
+}
+
+func TestCountPrompt(t *testing.T) {
+	h := newHarness(t, "a", "b")
+	h.typeKeys("1", "2")
+	if h.status.prompt != "12" {
+		t.Errorf("prompt = %q while typing a count, want %q", h.status.prompt, "12")
+	}
+	h.typeKeys("j")
+	if h.status.prompt != "" {
+		t.Errorf("prompt = %q after the command, want it cleared", h.status.prompt)
+	}
+}
+
+func TestEnter(t *testing.T) {
+	h := newHarness(t, "sub/", "file.txt")
+
+	h.typeKeys("l")
+	if h.host.opened != "/dir/sub" {
+		t.Errorf("l on a directory opened %q, want /dir/sub", h.host.opened)
+	}
+
+	h.host.opened = ""
+	h.view.tree = true
+	h.typeKeys("l")
+	if h.host.opened != "" || !h.view.expanded["/dir/sub"] {
+		t.Errorf("l in tree mode should expand in place; opened %q, expanded %v", h.host.opened, h.view.expanded)
+	}
+
+	h.typeKeys("j", "l")
+	if h.host.opened != "/dir/file.txt" {
+		t.Errorf("l on a file opened %q, want /dir/file.txt", h.host.opened)
+	}
+}
+
+func TestYank(t *testing.T) {
+	h := newHarness(t, "a", "b", "c")
+
+	h.typeKeys("y")
+	if !slices.Equal(h.view.yanked, []string{"/dir/a"}) || h.status.message != "info: Yanked: a" {
+		t.Errorf("y yanked %v, message %q", h.view.yanked, h.status.message)
+	}
+	h.typeKeys("y")
+	if len(h.view.yanked) != 0 || h.status.message != "info: Unyanked: a" {
+		t.Errorf("second y left %v, message %q", h.view.yanked, h.status.message)
+	}
+
+	h.typeKeys("2", "d", "d")
+	if !slices.Equal(h.view.yanked, []string{"/dir/a", "/dir/b"}) || !h.view.cut {
+		t.Errorf("2dd yanked %v, cut %v", h.view.yanked, h.view.cut)
+	}
+}
+
+func TestReportSort(t *testing.T) {
+	h := newHarness(t, "a")
+	h.typeKeys("s")
+	if !slices.Contains(h.host.calls, "sort changed") {
+		t.Errorf("sort indicator not updated: %v", h.host.calls)
+	}
+
+	h.host.calls = nil
+	h.view.failSort = true
+	h.typeKeys("s")
+	if slices.Contains(h.host.calls, "sort changed") || h.status.message != "error: sort failed" {
+		t.Errorf("failed sort: calls %v, message %q", h.host.calls, h.status.message)
+	}
+}
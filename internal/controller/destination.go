@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lawrab/warren/internal/bookmarks"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/registers"
+)
+
+// StartPasteTo enters paste-to mode, which reads the directory to paste
+// the named register, or the view's yank, into. The line starts with the
+// current directory.
+func (c *Controller) StartPasteTo() {
+	c.pasteRegister = c.TakeRegister()
+	label := "Paste to: "
+	if c.pasteRegister != registers.Unnamed {
+		label = fmt.Sprintf("Paste %s to: ", registers.Name(c.pasteRegister))
+	}
+	c.lines[keymap.ModePasteTo].label = label
+	c.startLine(keymap.ModePasteTo, withSeparator(c.host.Active().GetCurrentPath()), -1)
+}
+
+// pasteTo pastes into the directory typed, if it is one.
+func (c *Controller) pasteTo(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	dir := c.destination(text)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		c.status.Error(fmt.Sprintf("Not a directory: %s", dir))
+		return
+	}
+	c.host.PasteTo(c.pasteRegister, dir)
+}
+
+// destination resolves what was typed in paste-to mode: a bookmark's
+// name, or a path that is absolute, starts with ~ or is relative to the
+// current directory.
+func (c *Controller) destination(text string) string {
+	if path, ok := bookmarks.Find(c.host.Bookmarks(), text); ok {
+		return path
+	}
+	return resolvePath(text, c.host.Active().GetCurrentPath())
+}
+
+// completeDestination completes a bookmark's name, or the path of a
+// directory. Text that starts like a path (/, ~ or .) is always a path.
+func (c *Controller) completeDestination(text string) string {
+	if !strings.HasPrefix(text, "/") && !strings.HasPrefix(text, "~") && !strings.HasPrefix(text, ".") {
+		var names []string
+		for _, b := range c.host.Bookmarks() {
+			if strings.HasPrefix(b.Name, text) {
+				names = append(names, b.Name)
+			}
+		}
+		if len(names) > 0 {
+			return completePrefix(text, names)
+		}
+	}
+	return completeDir(text, c.host.Active().GetCurrentPath())
+}
+
+// completeDir completes the last element of text to the longest prefix
+// shared by the directories starting with it, adding a separator when
+// only one does. Hidden directories are offered once a dot is typed.
+func completeDir(text, cwd string) string {
+	cut := strings.LastIndex(text, string(filepath.Separator)) + 1
+	parent, base := text[:cut], text[cut:]
+	dir := resolvePath(parent, cwd)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return text
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		// Follow symlinks, so links to directories are offered too
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.IsDir() {
+			names = append(names, name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return text
+	case 1:
+		return parent + withSeparator(names[0])
+	}
+	return parent + completePrefix(base, names)
+}
+
+// resolvePath makes text an absolute path: ~ is the home directory and
+// relative paths are under cwd.
+func resolvePath(text, cwd string) string {
+	if text == "~" || strings.HasPrefix(text, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			text = filepath.Join(home, strings.TrimPrefix(text, "~"))
+		}
+	}
+	if !filepath.IsAbs(text) {
+		text = filepath.Join(cwd, text)
+	}
+	return filepath.Clean(text)
+}
+
+// withSeparator returns path ending in a separator, so completion lists
+// what is inside it.
+func withSeparator(path string) string {
+	if strings.HasSuffix(path, string(filepath.Separator)) {
+		return path
+	}
+	return path + string(filepath.Separator)
+}
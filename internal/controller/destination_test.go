@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lawrab/warren/internal/bookmarks"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/registers"
+)
+
+func TestPasteTo(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"photos", "projects", ".hidden"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pics.txt"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newHarness(t, "a")
+	h.view.dir = dir
+	h.host.marks = []bookmarks.Bookmark{{Name: "Backup", Path: filepath.Join(dir, "projects")}}
+
+	// The line starts in the current directory; Tab completes directories
+	h.typeKeys("P")
+	if h.c.Mode() != keymap.ModePasteTo || h.status.prompt != "Paste to: "+dir+"/"+CursorMark {
+		t.Fatalf("paste-to mode: mode %v, prompt %q", h.c.Mode(), h.status.prompt)
+	}
+	h.typeText("p")
+	h.typeKeys("Tab")
+	if want := "Paste to: " + dir + "/p" + CursorMark; h.status.prompt != want {
+		t.Errorf("Tab with two matches = %q, want %q", h.status.prompt, want)
+	}
+	h.typeText("h")
+	h.typeKeys("Tab")
+	if want := "Paste to: " + dir + "/photos/" + CursorMark; h.status.prompt != want {
+		t.Errorf("Tab with one match = %q, want %q", h.status.prompt, want)
+	}
+	h.typeKeys("Return")
+	if h.host.pastedTo != filepath.Join(dir, "photos") || h.host.pasted != registers.Unnamed {
+		t.Errorf("pasted %q to %q", h.host.pasted, h.host.pastedTo)
+	}
+
+	// A bookmark's name completes and pastes into it, here from register a
+	h.typeKeys(`"`, "a", "P")
+	if want := `Paste "a to: ` + dir + "/" + CursorMark; h.status.prompt != want {
+		t.Errorf("prompt = %q, want %q", h.status.prompt, want)
+	}
+	h.typeKeys("<Ctrl>u")
+	h.typeText("Ba")
+	h.typeKeys("Tab", "Return")
+	if h.host.pastedTo != filepath.Join(dir, "projects") || h.host.pasted != 'a' {
+		t.Errorf("pasted %q to %q, want register a to the bookmark", h.host.pasted, h.host.pastedTo)
+	}
+
+	// Relative paths are under the current directory and must exist
+	h.typeKeys("P", "<Ctrl>u")
+	h.typeText("pics.txt")
+	h.typeKeys("Return")
+	if want := "error: Not a directory: " + filepath.Join(dir, "pics.txt"); h.status.message != want {
+		t.Errorf("message = %q, want %q", h.status.message, want)
+	}
+}
+
+func TestCompleteDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"music", ".config"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(dir, "music"), filepath.Join(dir, "tunes")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"m", "music/"},
+		{"t", "tunes/"}, // Links to directories count
+		{"", ""},        // Hidden directories wait for a dot; two others match
+		{".c", ".config/"},
+		{"x", "x"},
+		{dir + "/mu", dir + "/music/"},
+		{"nowhere/m", "nowhere/m"},
+	}
+	for _, tt := range tests {
+		if got := completeDir(tt.text, dir); got != tt.want {
+			t.Errorf("completeDir(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
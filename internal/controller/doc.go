@@ -0,0 +1,17 @@
+// Package controller is Warren's interaction layer: what key presses do to
+// the focused file view and the status bar, without GTK.
+//
+// A Controller routes key presses through keyboard modes (normal, visual,
+// jump, and the command, filter and rename line modes) and carries out
+// navigation, yanking, filtering and renaming on a FileView. Everything
+// that needs the toolkit, such as opening files, dialogs and the status
+// bar summary, goes through Host, which cmd/warren implements for each
+// window:
+//
+//	c := controller.New(host, statusBar, regs)
+//	c.SetKeymaps(normal, controller.VisualKeymap(cfg))
+//	handled := c.Feed(ev, time.Now())
+//
+// Tests drive a Controller with a fake FileView and Host, so the layer is
+// covered without a display.
+package controller
@@ -0,0 +1,416 @@
+package controller
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/registers"
+	"github.com/lawrab/warren/internal/runner"
+)
+
+// CursorMark shows the cursor of a line being edited in the status bar.
+const CursorMark = "▏"
+
+// Actions visual mode handles itself. Action names are the keys of the
+// [keybindings] config section.
+const (
+	actionYank     = "yank"
+	actionCut      = "cut"
+	actionVisual   = "visual"
+	actionRegister = "register"
+)
+
+// visualActions are the bindings visual mode keeps from normal mode:
+// movement, and yank and cut, which act on the range, into a register if
+// one is named.
+var visualActions = []string{
+	"navigate_up", "navigate_down", "go_top", "go_bottom",
+	"half_page_down", "half_page_up", "view_top", "view_middle", "view_bottom",
+	actionYank, actionCut, actionVisual, actionRegister,
+}
+
+// VisualKeymap builds the visual mode keymap from the configured bindings
+// of movement, yank, cut, visual and register, plus the arrow keys. Escape leaves
+// visual mode, like the visual key. Bindings that fail to bind were
+// already reported for the normal keymap, so they are skipped silently.
+func VisualKeymap(cfg *config.Config) *keymap.Keymap {
+	km := keymap.New()
+	for _, b := range cfg.Keybindings.Bindings() {
+		if b.Key != "" && slices.Contains(visualActions, b.Action) {
+			_ = km.Bind(b.Key, b.Action)
+		}
+	}
+	for _, b := range []struct{ spec, action string }{
+		{"Down", "navigate_down"},
+		{"Up", "navigate_up"},
+		{"Home", "go_top"},
+		{"End", "go_bottom"},
+		{"Page_Down", "half_page_down"},
+		{"Page_Up", "half_page_up"},
+		{"Escape", actionVisual},
+	} {
+		_ = km.Bind(b.spec, b.action)
+	}
+	return km
+}
+
+// lineMode is a mode editing a line of text in the status bar.
+type lineMode struct {
+	line  *keymap.Line
+	label string // Shown before the text, e.g. "Rename: "
+}
+
+// addLineMode adds a mode editing a line labelled label. submit gets the
+// text on Return, after the mode has ended.
+func (c *Controller) addLineMode(mode keymap.Mode, label string, submit func(text string), complete func(text string) string) {
+	lm := &lineMode{label: label}
+	lm.line = &keymap.Line{
+		Submit: func(text string) {
+			c.endLine()
+			submit(text)
+		},
+		Cancel:   c.endLine,
+		Complete: complete,
+		Changed: func() {
+			c.status.SetPrompt(lm.label + lm.line.WithCursor(CursorMark))
+		},
+	}
+	c.lines[mode] = lm
+	c.modes.Handle(mode, lm.line)
+}
+
+// startLine enters a line mode with text, the cursor at rune offset
+// cursor (-1 for the end).
+func (c *Controller) startLine(mode keymap.Mode, text string, cursor int) {
+	c.modes.SetMode(mode)
+	c.host.Editing(true)
+	c.lines[mode].line.SetText(text, cursor)
+}
+
+// endLine leaves a line mode for normal mode.
+func (c *Controller) endLine() {
+	c.host.Editing(false)
+	if c.release != nil {
+		c.release()
+		c.release = nil
+	}
+	c.status.SetPrompt("")
+	c.modes.SetMode(keymap.ModeNormal)
+}
+
+// Insert types text into the line being edited, if any. Input methods
+// deliver composed text this way rather than as key presses.
+func (c *Controller) Insert(text string) {
+	if lm := c.lines[c.modes.Mode()]; lm != nil {
+		lm.line.Insert(text)
+	}
+}
+
+// StartJump enters jump mode.
+func (c *Controller) StartJump() {
+	c.jump.Reset()
+	c.modes.SetMode(keymap.ModeJump)
+	c.status.SetPrompt("Jump: ")
+}
+
+// jumpKey handles jump mode: printable keys build a name prefix and
+// select the first entry starting with it. Any other key leaves jump mode
+// and is handled normally, so Return opens the match.
+func (c *Controller) jumpKey(ev keymap.Event, now time.Time) keymap.Result {
+	if ev.Name == "Escape" {
+		c.status.SetPrompt("")
+		c.modes.SetMode(keymap.ModeNormal)
+		return keymap.Consumed
+	}
+
+	timedOut := c.jump.String() != "" && c.jump.Expired(now)
+	if !timedOut && ev.Mods == 0 && unicode.IsPrint(ev.Rune) {
+		fileView := c.host.Active()
+		if index := c.jump.Type(ev.Rune, fileView.FileNames(), fileView.SelectedIndex(), now); index >= 0 {
+			fileView.SelectIndex(index)
+		}
+		c.status.SetPrompt(fmt.Sprintf("Jump: %s", c.jump.String()))
+		return keymap.Consumed
+	}
+
+	c.status.SetPrompt("")
+	c.modes.SetMode(keymap.ModeNormal)
+	return keymap.Passed
+}
+
+// StartVisual enters visual mode with the range anchored at the selection.
+func (c *Controller) StartVisual() {
+	c.visualAnchor = c.host.Active().SelectedIndex()
+	c.modes.SetMode(keymap.ModeVisual)
+	c.showVisual("")
+}
+
+// visualRange returns the first index of the visual range and its length.
+func (c *Controller) visualRange() (int, int) {
+	fileView := c.host.Active()
+	current := fileView.SelectedIndex()
+	if current < 0 {
+		return 0, 0
+	}
+	anchor := min(c.visualAnchor, fileView.GetFileCount()-1)
+	return min(anchor, current), max(anchor, current) - min(anchor, current) + 1
+}
+
+// showVisual shows visual mode and the size of its range in the status
+// bar, followed by a count being typed.
+func (c *Controller) showVisual(typed string) {
+	_, n := c.visualRange()
+	prompt := fmt.Sprintf("-- VISUAL -- %d item(s)", n)
+	if typed = c.registerPrefix() + typed; typed != "" {
+		prompt += " " + typed
+	}
+	c.status.SetPrompt(prompt)
+}
+
+// runVisual runs an action of visual mode: yank and cut take the range
+// and end the mode, the visual key and Escape end it, and movement
+// extends the range.
+func (c *Controller) runVisual(name string, n int, given bool) {
+	switch name {
+	case actionYank, actionCut:
+		fileView := c.host.Active()
+		first, length := c.visualRange()
+		fileView.SelectIndex(first)
+		c.endVisual()
+		if reg := c.TakeRegister(); reg != registers.Unnamed {
+			c.yankInto(reg, fileView.RangePaths(length), name == actionCut)
+			c.host.SelectionChanged()
+			return
+		}
+		yanked := fileView.YankRange(length, name == actionCut)
+		c.host.SelectionChanged()
+		if name == actionCut {
+			c.status.Info(fmt.Sprintf("Cut %d file(s)", yanked))
+		} else {
+			c.status.Info(fmt.Sprintf("Yanked %d file(s)", yanked))
+		}
+	case actionVisual:
+		c.endVisual()
+	case actionRegister:
+		c.StartRegister()
+	default:
+		c.host.Run(name, n, given)
+		c.showVisual("")
+	}
+}
+
+// endVisual leaves visual mode for normal mode.
+func (c *Controller) endVisual() {
+	c.status.SetPrompt("")
+	c.modes.SetMode(keymap.ModeNormal)
+}
+
+// StartRegister reads the name of a register, a to z, for the next yank,
+// cut or paste, then goes back to the mode it was called from.
+func (c *Controller) StartRegister() {
+	c.registerFrom = c.modes.Mode()
+	c.modes.SetMode(keymap.ModeRegister)
+	c.status.SetPrompt(`"`)
+}
+
+// registerKey handles register mode: a to z names the register, Escape
+// or any other key names none.
+func (c *Controller) registerKey(ev keymap.Event, _ time.Time) keymap.Result {
+	if ev.IsModifierKey() {
+		return keymap.Consumed
+	}
+	c.modes.SetMode(c.registerFrom)
+	if ev.Mods == 0 && ev.Rune != registers.Unnamed && registers.Valid(ev.Rune) {
+		c.register = ev.Rune
+	} else if ev.Name != "Escape" {
+		c.status.Warn("Registers are named a to z")
+	}
+	c.refreshPrompt()
+	return keymap.Consumed
+}
+
+// TakeRegister returns the register named for this command, or
+// registers.Unnamed if none was, and forgets it.
+func (c *Controller) TakeRegister() rune {
+	name := c.PendingRegister()
+	c.dropRegister()
+	return name
+}
+
+// PendingRegister returns the register named for the next command, or
+// registers.Unnamed if none was.
+func (c *Controller) PendingRegister() rune {
+	if c.register == 0 {
+		return registers.Unnamed
+	}
+	return c.register
+}
+
+// dropRegister forgets the named register.
+func (c *Controller) dropRegister() {
+	if c.register == 0 {
+		return
+	}
+	c.register = 0
+	c.refreshPrompt()
+}
+
+// registerPrefix returns the named register as typed, e.g. `"a`, or "".
+func (c *Controller) registerPrefix() string {
+	if c.register == 0 {
+		return ""
+	}
+	return registers.Name(c.register)
+}
+
+// showCount shows normal mode's count after the named register.
+func (c *Controller) showCount(typed string) {
+	c.status.SetPrompt(c.registerPrefix() + typed)
+}
+
+// refreshPrompt shows the prompt of normal or visual mode after the named
+// register changed. Other modes keep their own prompt.
+func (c *Controller) refreshPrompt() {
+	switch c.modes.Mode() {
+	case keymap.ModeNormal:
+		c.showCount("")
+	case keymap.ModeVisual:
+		c.showVisual("")
+	}
+}
+
+// StartCommand enters command mode.
+func (c *Controller) StartCommand() {
+	c.startLine(keymap.ModeCommand, "", -1)
+}
+
+// runCommand runs the action or init.lua command named by text.
+func (c *Controller) runCommand(text string) {
+	name := strings.TrimSpace(text)
+	if name != "" && !c.host.Run(name, 1, false) {
+		c.status.Error(fmt.Sprintf("Unknown command: %s", name))
+	}
+}
+
+// completeCommand completes text to the longest prefix shared by the
+// command names starting with it.
+func (c *Controller) completeCommand(text string) string {
+	return completePrefix(text, c.host.Commands())
+}
+
+// completePrefix returns the longest common prefix of the names starting
+// with text, or text if none does.
+func completePrefix(text string, names []string) string {
+	var prefix string
+	found := false
+	for _, name := range names {
+		if !strings.HasPrefix(name, text) {
+			continue
+		}
+		if !found {
+			prefix, found = name, true
+			continue
+		}
+		for !strings.HasPrefix(name, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if !found {
+		return text
+	}
+	return prefix
+}
+
+// StartFilter enters filter mode with the current filter.
+func (c *Controller) StartFilter() {
+	c.startLine(keymap.ModeFilter, c.host.Active().Filter().String(), -1)
+}
+
+// applyFilter restricts the listing to names matching the glob patterns
+// in text. An empty text clears the filter.
+func (c *Controller) applyFilter(text string) {
+	filter, err := fileops.ParseNameFilter(text)
+	if err != nil {
+		c.status.Error(err.Error())
+		return
+	}
+	c.report(c.host.Active().SetFilter(filter))
+}
+
+// StartRename enters rename mode for the selected entry, with the cursor
+// before a file's extension. The listing holds still meanwhile.
+func (c *Controller) StartRename() {
+	fileView := c.host.Active()
+	selected := fileView.GetSelected()
+	if selected == nil {
+		return
+	}
+	c.renaming = selected
+	c.release = fileView.HoldReloads()
+
+	// A name with newlines or bytes that aren't UTF-8 is edited escaped;
+	// whatever is typed over it is taken as it is
+	name := fileops.DisplayName(selected.Name)
+	cursor := -1
+	if ext := filepath.Ext(name); !selected.IsDir && ext != name {
+		cursor = len([]rune(strings.TrimSuffix(name, ext)))
+	}
+	c.startLine(keymap.ModeRename, name, cursor)
+}
+
+// renameSelected renames the entry rename mode was started for.
+func (c *Controller) renameSelected(newName string) {
+	file := c.renaming
+	c.renaming = nil
+	if file == nil || newName == "" || newName == fileops.DisplayName(file.Name) {
+		return
+	}
+	if strings.ContainsRune(newName, filepath.Separator) {
+		c.status.Error(fmt.Sprintf("A name can't contain %q", filepath.Separator))
+		return
+	}
+	if len(newName) > fileops.MaxNameLength {
+		c.status.Error(fmt.Sprintf("A name can't be longer than %d bytes", fileops.MaxNameLength))
+		return
+	}
+	c.host.Rename(file, filepath.Join(filepath.Dir(file.Path), newName))
+}
+
+// StartRun enters run mode, which reads arguments for the selected
+// executable. Return with none runs it bare.
+func (c *Controller) StartRun() {
+	selected := c.host.Active().GetSelected()
+	if selected == nil {
+		return
+	}
+	if selected.IsDir || !runner.Executable(selected.Path) {
+		c.status.Warn(fmt.Sprintf("Not an executable file: %s", selected.Name))
+		return
+	}
+	c.running = selected
+	c.lines[keymap.ModeRun].label = fmt.Sprintf("Run %s ", fileops.DisplayName(selected.Name))
+	c.startLine(keymap.ModeRun, "", -1)
+}
+
+// runSelected runs the executable run mode was started for, with the
+// arguments in text.
+func (c *Controller) runSelected(text string) {
+	file := c.running
+	c.running = nil
+	if file == nil {
+		return
+	}
+	args, err := runner.Split(text)
+	if err != nil {
+		c.status.Error(fmt.Sprintf("Bad arguments: %v", err))
+		return
+	}
+	c.host.RunFile(file, args)
+}
@@ -0,0 +1,330 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/registers"
+)
+
+func TestJump(t *testing.T) {
+	h := newHarness(t, "alpha", "beta", "bravo", "charlie/")
+
+	h.typeKeys("f")
+	h.typeText("br")
+	if h.view.selected != 2 {
+		t.Errorf("jumping to br selected %d, want 2", h.view.selected)
+	}
+	if h.status.prompt != "Jump: br" {
+		t.Errorf("prompt = %q, want %q", h.status.prompt, "Jump: br")
+	}
+
+	// Return leaves jump mode and opens the match
+	h.typeKeys("Return")
+	if h.c.Mode() != keymap.ModeNormal || h.host.opened != "/dir/bravo" {
+		t.Errorf("Return in jump mode: mode %v, opened %q", h.c.Mode(), h.host.opened)
+	}
+
+	h.typeKeys("f", "Escape", "j")
+	if h.c.Mode() != keymap.ModeNormal || h.view.selected != 3 {
+		t.Errorf("after Escape, j should move: mode %v, selected %d", h.c.Mode(), h.view.selected)
+	}
+}
+
+func TestVisual(t *testing.T) {
+	h := newHarness(t, "a", "b", "c", "d", "e")
+
+	h.typeKeys("j", "v", "2", "j")
+	if h.c.Mode() != keymap.ModeVisual {
+		t.Fatalf("mode = %v after v, want visual", h.c.Mode())
+	}
+	if h.status.prompt != "-- VISUAL -- 3 item(s)" {
+		t.Errorf("prompt = %q", h.status.prompt)
+	}
+
+	// Only yank, cut and movement are bound in visual mode
+	h.typeKeys("D")
+	if slices.Contains(h.host.calls, "delete") {
+		t.Error("D ran delete in visual mode")
+	}
+
+	h.typeKeys("d", "d")
+	if h.c.Mode() != keymap.ModeNormal {
+		t.Errorf("mode = %v after cutting, want normal", h.c.Mode())
+	}
+	if want := []string{"/dir/b", "/dir/c", "/dir/d"}; !slices.Equal(h.view.yanked, want) || !h.view.cut {
+		t.Errorf("visual cut yanked %v (cut %v), want %v", h.view.yanked, h.view.cut, want)
+	}
+	if h.status.message != "info: Cut 3 file(s)" || h.status.prompt != "" {
+		t.Errorf("message %q, prompt %q", h.status.message, h.status.prompt)
+	}
+
+	// Upwards from the anchor, then Escape leaves without yanking
+	h.view.ClearYanked()
+	h.typeKeys("G", "v", "k", "k")
+	if h.status.prompt != "-- VISUAL -- 3 item(s)" {
+		t.Errorf("prompt = %q selecting upwards", h.status.prompt)
+	}
+	h.typeKeys("Escape")
+	if h.c.Mode() != keymap.ModeNormal || len(h.view.yanked) != 0 {
+		t.Errorf("Escape: mode %v, yanked %v", h.c.Mode(), h.view.yanked)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	h := newHarness(t, "a", ".hidden")
+	h.host.commands = []string{"toggle_hidden", "toggle_tree", "projects"}
+
+	h.typeKeys(":")
+	if !h.host.editing || h.status.prompt != ":"+CursorMark {
+		t.Fatalf("command mode: editing %v, prompt %q", h.host.editing, h.status.prompt)
+	}
+	h.typeText("tog")
+	h.typeKeys("Tab")
+	if h.status.prompt != ":toggle_"+CursorMark {
+		t.Errorf("Tab completed to %q", h.status.prompt)
+	}
+	h.typeText("hidden")
+	h.typeKeys("Return")
+	if !h.view.hidden || h.host.editing || h.c.Mode() != keymap.ModeNormal {
+		t.Errorf("running toggle_hidden: hidden %v, editing %v, mode %v", h.view.hidden, h.host.editing, h.c.Mode())
+	}
+
+	h.typeKeys(":")
+	h.typeText("nope")
+	h.typeKeys("Return")
+	if h.status.message != "error: Unknown command: nope" {
+		t.Errorf("message = %q", h.status.message)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	h := newHarness(t, "a.go", "b.txt")
+
+	h.typeKeys("F")
+	h.typeText("*.go")
+	h.typeKeys("Return")
+	if got := h.view.filter.String(); got != "*.go" {
+		t.Errorf("filter = %q, want *.go", got)
+	}
+
+	// Editing starts from the current filter; Ctrl+U clears it
+	h.typeKeys("F")
+	if h.status.prompt != "Filter: *.go"+CursorMark {
+		t.Errorf("prompt = %q", h.status.prompt)
+	}
+	h.typeKeys("<Ctrl>u", "Return")
+	if !h.view.filter.IsEmpty() {
+		t.Errorf("filter = %q after clearing, want empty", h.view.filter)
+	}
+}
+
+func TestRename(t *testing.T) {
+	h := newHarness(t, "notes.txt", "docs/")
+
+	h.typeKeys("r")
+	if h.status.prompt != "Rename: notes"+CursorMark+".txt" {
+		t.Errorf("prompt = %q, want the cursor before the extension", h.status.prompt)
+	}
+	if h.view.held != 1 {
+		t.Errorf("reloads held %d times while renaming, want 1", h.view.held)
+	}
+	h.typeKeys("<Ctrl>w")
+	h.typeText("todo")
+	h.typeKeys("Return")
+	if want := [2]string{"/dir/notes.txt", "/dir/todo.txt"}; h.host.renamed != want {
+		t.Errorf("renamed %v, want %v", h.host.renamed, want)
+	}
+	if h.view.held != 0 {
+		t.Errorf("reloads still held after renaming")
+	}
+
+	// Escape cancels; a name with a separator is refused
+	h.host.renamed = [2]string{}
+	h.typeKeys("j", "r", "Escape")
+	h.typeKeys("r", "End")
+	h.typeText("/x")
+	h.typeKeys("Return")
+	if h.host.renamed != [2]string{} {
+		t.Errorf("renamed %v, want nothing", h.host.renamed)
+	}
+	if h.status.message != `error: A name can't contain '/'` {
+		t.Errorf("message = %q", h.status.message)
+	}
+
+	// Nor is one too long for the filesystem
+	h.typeKeys("r", "End")
+	h.typeText(strings.Repeat("x", 256))
+	h.typeKeys("Return")
+	if h.host.renamed != [2]string{} {
+		t.Errorf("renamed %v, want nothing", h.host.renamed)
+	}
+	if h.status.message != "error: A name can't be longer than 255 bytes" {
+		t.Errorf("message = %q", h.status.message)
+	}
+}
+
+func TestRenameOddName(t *testing.T) {
+	h := newHarness(t, "two\nlines.txt")
+
+	// The name is edited escaped, and left alone if that isn't changed
+	h.typeKeys("r")
+	if h.status.prompt != `Rename: two\nlines`+CursorMark+".txt" {
+		t.Errorf("prompt = %q, want the name escaped", h.status.prompt)
+	}
+	h.typeKeys("Return")
+	if h.host.renamed != [2]string{} {
+		t.Errorf("renamed %v, want nothing", h.host.renamed)
+	}
+
+	h.typeKeys("r", "<Ctrl>u")
+	h.typeText("one line")
+	h.typeKeys("Return")
+	if want := [2]string{"/dir/two\nlines.txt", "/dir/one line.txt"}; h.host.renamed != want {
+		t.Errorf("renamed %q, want %q", h.host.renamed, want)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	h := newHarness(t, "a")
+
+	// Outside a line mode, input method text goes nowhere
+	h.c.Insert("x")
+	if h.status.prompt != "" {
+		t.Errorf("prompt = %q after Insert in normal mode", h.status.prompt)
+	}
+
+	h.typeKeys(":")
+	h.c.Insert("日本")
+	if h.status.prompt != ":日本"+CursorMark {
+		t.Errorf("prompt = %q after Insert", h.status.prompt)
+	}
+}
+
+func TestCompletePrefix(t *testing.T) {
+	names := []string{"toggle_hidden", "toggle_tree", "toggle_preview", "quit"}
+	tests := []struct {
+		text, want string
+	}{
+		{"tog", "toggle_"},
+		{"toggle_t", "toggle_tree"},
+		{"q", "quit"},
+		{"x", "x"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := completePrefix(tt.text, names); got != tt.want {
+			t.Errorf("completePrefix(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestVisualKeymap(t *testing.T) {
+	km := VisualKeymap(config.Default())
+	bound := make(map[string]string)
+	for _, b := range km.Bindings() {
+		bound[b.Keys.String()] = b.Action
+	}
+	for key, action := range map[string]string{
+		"j":      "navigate_down",
+		"y":      actionYank,
+		"d d":    actionCut,
+		"v":      actionVisual,
+		"Escape": actionVisual,
+		"Down":   "navigate_down",
+	} {
+		if bound[key] != action {
+			t.Errorf("%s is bound to %q, want %q", key, bound[key], action)
+		}
+	}
+	if action, ok := bound["D"]; ok {
+		t.Errorf("D is bound to %q in visual mode", action)
+	}
+}
+
+func TestRegisters(t *testing.T) {
+	h := newHarness(t, "a", "b", "c", "d")
+
+	// "a2y yanks into register a, leaving the view's yank alone
+	h.typeKeys("\"")
+	if h.c.Mode() != keymap.ModeRegister || h.status.prompt != `"` {
+		t.Fatalf("after \": mode %v, prompt %q", h.c.Mode(), h.status.prompt)
+	}
+	h.typeKeys("a", "2")
+	if h.status.prompt != `"a2` {
+		t.Errorf("prompt = %q, want the register before the count", h.status.prompt)
+	}
+	h.typeKeys("y")
+	if reg := h.regs.Get('a'); !slices.Equal(reg.Paths, []string{"/dir/a", "/dir/b"}) || reg.Cut {
+		t.Errorf("register a = %+v", reg)
+	}
+	if len(h.view.yanked) != 0 || h.status.prompt != "" {
+		t.Errorf("view yanked %v, prompt %q", h.view.yanked, h.status.prompt)
+	}
+	if h.status.message != `info: Yanked 2 file(s) into "a` {
+		t.Errorf("message = %q", h.status.message)
+	}
+
+	// A visual cut into b, then an ordinary yank of the first entry cut
+	h.typeKeys("j", "j", "v", "j", "\"", "b", "d", "d")
+	if reg := h.regs.Get('b'); !slices.Equal(reg.Paths, []string{"/dir/c", "/dir/d"}) || !reg.Cut {
+		t.Errorf("register b = %+v", reg)
+	}
+	h.typeKeys("y")
+	if !slices.Equal(h.view.yanked, []string{"/dir/c"}) {
+		t.Errorf("plain y yanked %v", h.view.yanked)
+	}
+
+	// Paste takes the named register; one not used by the next command is
+	// dropped
+	h.typeKeys("\"", "a", "p")
+	if h.host.pasted != 'a' {
+		t.Errorf("paste used register %q, want a", h.host.pasted)
+	}
+	h.typeKeys("\"", "b", "k", "p")
+	if h.host.pasted != registers.Unnamed {
+		t.Errorf("paste after k used register %q, want the unnamed one", h.host.pasted)
+	}
+
+	h.typeKeys("\"", "1")
+	if h.status.message != "warn: Registers are named a to z" || h.c.Mode() != keymap.ModeNormal {
+		t.Errorf("invalid register: message %q, mode %v", h.status.message, h.c.Mode())
+	}
+}
+
+func TestRun(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "build.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	h := newHarness(t, "build.sh", "notes.txt")
+	h.view.files[0].Path = script
+
+	h.typeKeys("!")
+	if h.c.Mode() != keymap.ModeRun || h.status.prompt != "Run build.sh "+CursorMark {
+		t.Fatalf("run mode: mode %v, prompt %q", h.c.Mode(), h.status.prompt)
+	}
+	h.typeText(`-o "out dir"`)
+	h.typeKeys("Return")
+	if want := []string{script, "-o", "out dir"}; !slices.Equal(h.host.ran, want) {
+		t.Errorf("ran %q, want %q", h.host.ran, want)
+	}
+
+	// Unbalanced quotes run nothing
+	h.host.ran = nil
+	h.typeKeys("!", "'", "Return")
+	if h.host.ran != nil || !strings.HasPrefix(h.status.message, "error: Bad arguments") {
+		t.Errorf("ran %q, message %q", h.host.ran, h.status.message)
+	}
+
+	// Files that aren't executable don't enter run mode
+	h.typeKeys("j", "!")
+	if h.c.Mode() != keymap.ModeNormal || h.status.message != "warn: Not an executable file: notes.txt" {
+		t.Errorf("mode %v, message %q", h.c.Mode(), h.status.message)
+	}
+}
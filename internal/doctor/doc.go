@@ -0,0 +1,8 @@
+// Package doctor builds the report "warren --doctor" prints: what Warren
+// finds of the compositor, directories, config, inotify limits, trash and
+// D-Bus in the environment it runs in, with anything likely to cause
+// trouble marked. Pasted into an issue, it answers most of the questions
+// a support request would otherwise go back and forth over.
+//
+// The checks here need no display; cmd/warren adds a section about GTK.
+package doctor
@@ -0,0 +1,276 @@
+package doctor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/lawrab/warren/internal/compositor"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/hyprland"
+	"github.com/lawrab/warren/internal/ipc"
+	"github.com/lawrab/warren/internal/logging"
+	"github.com/lawrab/warren/internal/version"
+)
+
+// inotifyDir holds the inotify limits. A variable so tests can point it
+// at files of their own.
+var inotifyDir = "/proc/sys/fs/inotify"
+
+// minUserWatches is the max_user_watches below which the report warns:
+// the limit many distributions ship, which a few editors and sync clients
+// already use up.
+const minUserWatches = 8192
+
+// Access modes for syscall.Access.
+const (
+	accessWrite   = 0x2
+	accessExecute = 0x1
+)
+
+// Check is one line of the report.
+type Check struct {
+	Name    string
+	Value   string
+	Problem bool // Likely to cause trouble; marked with "!"
+}
+
+// Section is a titled group of checks.
+type Section struct {
+	Title  string
+	Checks []Check
+}
+
+// Sections runs the checks that need no display.
+func Sections() []Section {
+	return []Section{
+		compositorSection(),
+		directoriesSection(),
+		configSection(),
+		inotifySection(),
+		trashSection(),
+		dbusSection(),
+	}
+}
+
+// Write prints the report of sections to w, headed by Warren's version,
+// and returns how many problems it marked.
+func Write(w io.Writer, sections []Section) (int, error) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, version.FullVersion())
+	problems := 0
+	for _, section := range sections {
+		fmt.Fprintf(tw, "\n%s\n", section.Title)
+		for _, check := range section.Checks {
+			mark := " "
+			if check.Problem {
+				mark = "!"
+				problems++
+			}
+			fmt.Fprintf(tw, "%s %s\t%s\n", mark, check.Name, check.Value)
+		}
+	}
+	switch problems {
+	case 0:
+		fmt.Fprintln(tw, "\nNo problems found")
+	case 1:
+		fmt.Fprintln(tw, "\n1 problem found (marked !)")
+	default:
+		fmt.Fprintf(tw, "\n%d problems found (marked !)\n", problems)
+	}
+	return problems, tw.Flush()
+}
+
+// EnvCheck reports an environment variable, or that it is not set.
+func EnvCheck(name string) Check {
+	if value := os.Getenv(name); value != "" {
+		return Check{Name: name, Value: value}
+	}
+	return Check{Name: name, Value: "not set"}
+}
+
+// socketCheck reports whether a Unix socket exists at path.
+func socketCheck(name, path string) Check {
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		return Check{Name: name, Value: path + " (missing)", Problem: true}
+	case info.Mode()&os.ModeSocket == 0:
+		return Check{Name: name, Value: path + " (not a socket)", Problem: true}
+	default:
+		return Check{Name: name, Value: path}
+	}
+}
+
+// dirCheck reports a directory Warren writes to. One that doesn't exist
+// yet is fine as long as it can be created.
+func dirCheck(name, dir string, err error) Check {
+	if err != nil {
+		return Check{Name: name, Value: err.Error(), Problem: true}
+	}
+	if info, err := os.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return Check{Name: name, Value: dir + " (not a directory)", Problem: true}
+		}
+		if err := writable(dir); err != nil {
+			return Check{Name: name, Value: dir + " (not writable)", Problem: true}
+		}
+		return Check{Name: name, Value: dir}
+	}
+	// Created with its parents, so the nearest existing one decides
+	parent := filepath.Dir(dir)
+	for {
+		if _, err := os.Stat(parent); err == nil || parent == filepath.Dir(parent) {
+			break
+		}
+		parent = filepath.Dir(parent)
+	}
+	if writable(parent) != nil {
+		return Check{Name: name, Value: dir + " (missing, and can't be created)", Problem: true}
+	}
+	return Check{Name: name, Value: dir + " (not created yet)"}
+}
+
+// writable reports whether files can be created in dir.
+func writable(dir string) error {
+	return syscall.Access(dir, accessWrite|accessExecute)
+}
+
+func compositorSection() Section {
+	var checks []Check
+	sig := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	checks = append(checks, EnvCheck("HYPRLAND_INSTANCE_SIGNATURE"))
+	if sig != "" {
+		command, events := hyprland.Sockets(sig)
+		checks = append(checks,
+			socketCheck("Hyprland command socket", command),
+			socketCheck("Hyprland event socket", events))
+	}
+	checks = append(checks, EnvCheck("SWAYSOCK"))
+	if sock := os.Getenv("SWAYSOCK"); sock != "" {
+		checks = append(checks, socketCheck("Sway socket", sock))
+	}
+	if name := compositor.Detected(); name != "" {
+		checks = append(checks, Check{Name: "Detected", Value: name})
+	} else {
+		checks = append(checks,
+			Check{Name: "Detected", Value: "none"},
+			Check{Name: "Session memory key", Value: compositor.SessionKey()})
+	}
+	return Section{Title: "Compositor", Checks: checks}
+}
+
+func directoriesSection() Section {
+	checks := []Check{
+		EnvCheck("XDG_CONFIG_HOME"),
+		EnvCheck("XDG_DATA_HOME"),
+		EnvCheck("XDG_STATE_HOME"),
+		EnvCheck("XDG_RUNTIME_DIR"),
+	}
+	configDir, err := config.Dir()
+	checks = append(checks, dirCheck("Config directory", configDir, err))
+	stateDir, err := config.StateDir()
+	checks = append(checks, dirCheck("State directory", stateDir, err))
+	if logFile, err := logging.Path(); err == nil {
+		checks = append(checks, Check{Name: "Log file", Value: logFile})
+	}
+	checks = append(checks, Check{Name: "Control socket", Value: ipc.SocketPath()})
+	return Section{Title: "Directories", Checks: checks}
+}
+
+func configSection() Section {
+	path, err := config.Path()
+	if err != nil {
+		return Section{Title: "Config", Checks: []Check{{Name: "File", Value: err.Error(), Problem: true}}}
+	}
+	checks := []Check{{Name: "File", Value: path}}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		checks[0].Value += " (missing, defaults in use)"
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		checks = append(checks, Check{Name: "Parse", Value: err.Error(), Problem: true})
+		return Section{Title: "Config", Checks: checks}
+	}
+	errs := config.Validate(cfg)
+	for _, err := range errs {
+		checks = append(checks, Check{Name: "Problem", Value: err.Error(), Problem: true})
+	}
+	if len(errs) == 0 {
+		checks = append(checks, Check{Name: "Validation", Value: "ok"})
+	}
+	return Section{Title: "Config", Checks: checks}
+}
+
+func inotifySection() Section {
+	return Section{Title: "Inotify", Checks: []Check{
+		limitCheck("max_user_watches", minUserWatches),
+		limitCheck("max_user_instances", 0),
+	}}
+}
+
+// limitCheck reports the inotify limit in file, a problem when it can't
+// be read or is below low.
+func limitCheck(file string, low int) Check {
+	data, err := os.ReadFile(filepath.Join(inotifyDir, file)) // #nosec G304 -- a fixed /proc file
+	if err != nil {
+		return Check{Name: file, Value: "unreadable (directories may be polled)", Problem: true}
+	}
+	value := strings.TrimSpace(string(data))
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return Check{Name: file, Value: value + " (not a number)", Problem: true}
+	}
+	if n < low {
+		return Check{Name: file, Value: fmt.Sprintf("%d (low; raise fs.inotify.%s)", n, file), Problem: true}
+	}
+	return Check{Name: file, Value: value}
+}
+
+func trashSection() Section {
+	dir, err := fileops.TrashDir()
+	return Section{Title: "Trash", Checks: []Check{dirCheck("Directory", dir, err)}}
+}
+
+func dbusSection() Section {
+	address := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if address == "" {
+		// Without the variable clients look for the bus in the runtime
+		// directory
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+		}
+		return Section{Title: "D-Bus", Checks: []Check{
+			{Name: "DBUS_SESSION_BUS_ADDRESS", Value: "not set"},
+			socketCheck("Session bus", filepath.Join(runtimeDir, "bus")),
+		}}
+	}
+	checks := []Check{{Name: "DBUS_SESSION_BUS_ADDRESS", Value: address}}
+	if path, ok := busSocket(address); ok {
+		checks = append(checks, socketCheck("Session bus", path))
+	}
+	return Section{Title: "D-Bus", Checks: checks}
+}
+
+// busSocket returns the socket path a D-Bus address names, if its first
+// transport is a Unix socket with a path on disk.
+func busSocket(address string) (string, bool) {
+	transport, _, _ := strings.Cut(address, ";")
+	params, ok := strings.CutPrefix(transport, "unix:")
+	if !ok {
+		return "", false
+	}
+	for param := range strings.SplitSeq(params, ",") {
+		if path, ok := strings.CutPrefix(param, "path="); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
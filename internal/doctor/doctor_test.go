@@ -0,0 +1,147 @@
+package doctor
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	var b strings.Builder
+	problems, err := Write(&b, []Section{{
+		Title: "Things",
+		Checks: []Check{
+			{Name: "Fine", Value: "yes"},
+			{Name: "Broken thing", Value: "no", Problem: true},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems != 1 {
+		t.Errorf("problems = %d, want 1", problems)
+	}
+	out := b.String()
+	for _, want := range []string{"\nThings\n", "  Fine          yes\n", "! Broken thing  no\n", "1 problem found"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSocketCheck(t *testing.T) {
+	dir := t.TempDir()
+	sock := filepath.Join(dir, "s.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := socketCheck("s", sock); c.Problem {
+		t.Errorf("socket: %+v, want no problem", c)
+	}
+	if c := socketCheck("s", file); !c.Problem {
+		t.Errorf("regular file: %+v, want a problem", c)
+	}
+	if c := socketCheck("s", filepath.Join(dir, "missing")); !c.Problem {
+		t.Errorf("missing: %+v, want a problem", c)
+	}
+}
+
+func TestDirCheck(t *testing.T) {
+	dir := t.TempDir()
+	if c := dirCheck("d", dir, nil); c.Problem || c.Value != dir {
+		t.Errorf("existing: %+v", c)
+	}
+	missing := filepath.Join(dir, "a", "b")
+	if c := dirCheck("d", missing, nil); c.Problem || !strings.Contains(c.Value, "not created yet") {
+		t.Errorf("creatable: %+v", c)
+	}
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if c := dirCheck("d", file, nil); !c.Problem {
+		t.Errorf("file: %+v, want a problem", c)
+	}
+}
+
+func TestLimitCheck(t *testing.T) {
+	inotifyDir = t.TempDir()
+	t.Cleanup(func() { inotifyDir = "/proc/sys/fs/inotify" })
+	write := func(name, value string) {
+		if err := os.WriteFile(filepath.Join(inotifyDir, name), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("max_user_watches", "524288\n")
+	write("max_user_instances", "oops\n")
+	write("low", "100\n")
+
+	if c := limitCheck("max_user_watches", minUserWatches); c.Problem || c.Value != "524288" {
+		t.Errorf("max_user_watches: %+v", c)
+	}
+	if c := limitCheck("max_user_instances", 0); !c.Problem {
+		t.Errorf("garbage: %+v, want a problem", c)
+	}
+	if c := limitCheck("low", minUserWatches); !c.Problem {
+		t.Errorf("low: %+v, want a problem", c)
+	}
+	if c := limitCheck("missing", 0); !c.Problem {
+		t.Errorf("missing: %+v, want a problem", c)
+	}
+}
+
+func TestConfigSection(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	if s := configSection(); hasProblem(s) {
+		t.Errorf("no config file: %+v, want no problem", s)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, "warren"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "warren", "config.toml"), []byte("[general\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if s := configSection(); !hasProblem(s) {
+		t.Errorf("broken config: %+v, want a problem", s)
+	}
+}
+
+func TestBusSocket(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		ok      bool
+	}{
+		{"unix:path=/run/user/1000/bus", "/run/user/1000/bus", true},
+		{"unix:guid=abc,path=/tmp/bus;tcp:host=x", "/tmp/bus", true},
+		{"unix:abstract=/tmp/dbus-x", "", false},
+		{"tcp:host=localhost,port=1234", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := busSocket(tt.address)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("busSocket(%q) = %q, %v, want %q, %v", tt.address, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// hasProblem reports whether any check of s is marked.
+func hasProblem(s Section) bool {
+	for _, c := range s.Checks {
+		if c.Problem {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+// Package exif reads the capture time, camera and GPS position from the
+// EXIF data of JPEG and TIFF photos.
+//
+// Only the handful of tags Warren shows or sorts by are decoded: Make,
+// Model, DateTimeOriginal (falling back to DateTime) and the GPS latitude
+// and longitude. The reader looks at the start of the file only, where
+// cameras put the EXIF block, so it is cheap enough to run on every photo
+// of a directory when sorting by capture date. No GTK dependency.
+package exif
@@ -0,0 +1,280 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoExif is returned for files without EXIF data, including every file
+// that isn't a JPEG or TIFF.
+var ErrNoExif = errors.New("no EXIF data")
+
+// headerLimit is how much of a file is read. The EXIF block of a JPEG is
+// one APP1 segment of at most 64 KiB near the start.
+const headerLimit = 256 * 1024
+
+// timeLayout is the EXIF date format, in local time of the camera.
+const timeLayout = "2006:01:02 15:04:05"
+
+// Tags read from the TIFF structure.
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+
+	tagGPSLatitudeRef  = 0x0001
+	tagGPSLatitude     = 0x0002
+	tagGPSLongitudeRef = 0x0003
+	tagGPSLongitude    = 0x0004
+)
+
+// TIFF field types used by the tags above.
+const (
+	typeASCII    = 2
+	typeLong     = 4
+	typeRational = 5
+)
+
+// Data is what Warren shows of a photo's EXIF block.
+type Data struct {
+	Make  string    // Camera maker, e.g. "Canon"
+	Model string    // Camera model, e.g. "Canon EOS R6"
+	Taken time.Time // Capture time in the local zone; zero when unknown
+
+	HasGPS    bool
+	Latitude  float64 // Degrees, negative south of the equator
+	Longitude float64 // Degrees, negative west of Greenwich
+}
+
+// Camera returns the maker and model, without repeating a maker the model
+// already starts with ("Canon EOS R6" rather than "Canon Canon EOS R6").
+func (d Data) Camera() string {
+	if d.Make == "" || strings.HasPrefix(strings.ToLower(d.Model), strings.ToLower(d.Make)) {
+		return d.Model
+	}
+	if d.Model == "" {
+		return d.Make
+	}
+	return d.Make + " " + d.Model
+}
+
+// Position formats the GPS position, e.g. "48.85837° N, 2.29448° E".
+func (d Data) Position() string {
+	if !d.HasGPS {
+		return ""
+	}
+	lat, lon := "N", "E"
+	if d.Latitude < 0 {
+		lat = "S"
+	}
+	if d.Longitude < 0 {
+		lon = "W"
+	}
+	return fmt.Sprintf("%.5f° %s, %.5f° %s", math.Abs(d.Latitude), lat, math.Abs(d.Longitude), lon)
+}
+
+// IsPhoto reports whether a file name has an extension Read understands.
+func IsPhoto(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jpg", ".jpeg", ".jpe", ".tif", ".tiff":
+		return true
+	}
+	return false
+}
+
+// Read reads the EXIF data of a JPEG or TIFF file.
+func Read(path string) (Data, error) {
+	f, err := os.Open(path) // #nosec G304 -- reading the user's own photos
+	if err != nil {
+		return Data{}, err
+	}
+	defer f.Close()
+
+	header, err := io.ReadAll(io.LimitReader(f, headerLimit))
+	if err != nil {
+		return Data{}, err
+	}
+	return Parse(header)
+}
+
+// Parse decodes the EXIF data at the start of a JPEG or TIFF file.
+func Parse(header []byte) (Data, error) {
+	tiff := header
+	if bytes.HasPrefix(header, []byte{0xFF, 0xD8}) {
+		tiff = findJPEGExif(header)
+	}
+	if tiff == nil {
+		return Data{}, ErrNoExif
+	}
+
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(tiff, []byte("II*\x00")):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(tiff, []byte("MM\x00*")):
+		order = binary.BigEndian
+	default:
+		return Data{}, ErrNoExif
+	}
+
+	r := reader{data: tiff, order: order}
+	ifd0 := r.readIFD(order.Uint32(tiff[4:]))
+	if ifd0 == nil {
+		return Data{}, ErrNoExif
+	}
+
+	d := Data{
+		Make:  r.ascii(ifd0[tagMake]),
+		Model: r.ascii(ifd0[tagModel]),
+	}
+	taken := r.ascii(ifd0[tagDateTime])
+	if exif := r.readIFD(r.long(ifd0[tagExifIFD])); exif != nil {
+		if original := r.ascii(exif[tagDateTimeOriginal]); original != "" {
+			taken = original
+		}
+	}
+	if t, err := time.ParseInLocation(timeLayout, taken, time.Local); err == nil {
+		d.Taken = t
+	}
+
+	if gps := r.readIFD(r.long(ifd0[tagGPSIFD])); gps != nil {
+		lat, latOK := r.degrees(gps[tagGPSLatitude])
+		lon, lonOK := r.degrees(gps[tagGPSLongitude])
+		if latOK && lonOK {
+			if r.ascii(gps[tagGPSLatitudeRef]) == "S" {
+				lat = -lat
+			}
+			if r.ascii(gps[tagGPSLongitudeRef]) == "W" {
+				lon = -lon
+			}
+			d.HasGPS, d.Latitude, d.Longitude = true, lat, lon
+		}
+	}
+	return d, nil
+}
+
+// findJPEGExif returns the TIFF structure inside a JPEG's EXIF APP1
+// segment, or nil. Segments are walked until the image data starts.
+func findJPEGExif(jpeg []byte) []byte {
+	pos := 2
+	for pos+4 <= len(jpeg) {
+		if jpeg[pos] != 0xFF {
+			return nil
+		}
+		marker := jpeg[pos+1]
+		if marker == 0xDA || marker == 0xD9 { // Start of scan, end of image
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(jpeg[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(jpeg) {
+			return nil
+		}
+		segment := jpeg[pos+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		pos = end
+	}
+	return nil
+}
+
+// entry is one IFD field: its type, value count, and the 4 bytes holding
+// either the value itself or the offset of it.
+type entry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// reader decodes IFDs from a TIFF structure, bounds-checking every offset.
+type reader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// readIFD returns the fields of the IFD at offset by tag, or nil if the
+// offset is zero or out of range.
+func (r reader) readIFD(offset uint32) map[uint16]entry {
+	if offset == 0 || int64(offset)+2 > int64(len(r.data)) {
+		return nil
+	}
+	n := int(r.order.Uint16(r.data[offset:]))
+	fields := make(map[uint16]entry, n)
+	for i := range n {
+		start := int(offset) + 2 + 12*i
+		if start+12 > len(r.data) {
+			break
+		}
+		field := r.data[start : start+12]
+		fields[r.order.Uint16(field)] = entry{
+			typ:   r.order.Uint16(field[2:]),
+			count: r.order.Uint32(field[4:]),
+			value: field[8:12],
+		}
+	}
+	return fields
+}
+
+// bytesOf returns the data of a field of size bytes per value.
+func (r reader) bytesOf(e entry, size int) []byte {
+	n := int64(e.count) * int64(size)
+	if n <= 4 {
+		return e.value[:n]
+	}
+	offset := int64(r.order.Uint32(e.value))
+	if offset+n > int64(len(r.data)) {
+		return nil
+	}
+	return r.data[offset : offset+n]
+}
+
+// ascii returns a string field without its NUL terminator and padding.
+func (r reader) ascii(e entry) string {
+	if e.typ != typeASCII {
+		return ""
+	}
+	s, _, _ := bytes.Cut(r.bytesOf(e, 1), []byte{0})
+	return strings.TrimSpace(string(s))
+}
+
+// long returns a LONG field, or 0.
+func (r reader) long(e entry) uint32 {
+	if e.typ != typeLong || e.count != 1 {
+		return 0
+	}
+	return r.order.Uint32(e.value)
+}
+
+// degrees converts a GPS coordinate stored as degrees, minutes and seconds
+// rationals into decimal degrees.
+func (r reader) degrees(e entry) (float64, bool) {
+	if e.typ != typeRational || e.count != 3 {
+		return 0, false
+	}
+	data := r.bytesOf(e, 8)
+	if data == nil {
+		return 0, false
+	}
+	var parts [3]float64
+	for i := range parts {
+		num := r.order.Uint32(data[8*i:])
+		den := r.order.Uint32(data[8*i+4:])
+		if den == 0 {
+			return 0, false
+		}
+		parts[i] = float64(num) / float64(den)
+	}
+	return parts[0] + parts[1]/60 + parts[2]/3600, true
+}
@@ -0,0 +1,205 @@
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// field is an IFD field for buildTIFF. A LONG field with ifd >= 0 points
+// at that IFD instead of holding data.
+type field struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+	ifd   int
+}
+
+func asciiField(tag uint16, s string) field {
+	return field{tag: tag, typ: typeASCII, count: uint32(len(s) + 1), data: append([]byte(s), 0), ifd: -1}
+}
+
+func pointerField(tag uint16, ifd int) field {
+	return field{tag: tag, typ: typeLong, count: 1, ifd: ifd}
+}
+
+func rationalsField(tag uint16, order binary.ByteOrder, values ...[2]uint32) field {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		order.PutUint32(data[8*i:], v[0])
+		order.PutUint32(data[8*i+4:], v[1])
+	}
+	return field{tag: tag, typ: typeRational, count: uint32(len(values)), data: data, ifd: -1}
+}
+
+// buildTIFF lays out the IFDs one after another, followed by the values
+// that don't fit in a field.
+func buildTIFF(order binary.ByteOrder, ifds ...[]field) []byte {
+	offsets := make([]uint32, len(ifds))
+	next := uint32(8)
+	for i, ifd := range ifds {
+		offsets[i] = next
+		next += uint32(2 + 12*len(ifd) + 4)
+	}
+
+	out := make([]byte, next)
+	if order == binary.LittleEndian {
+		copy(out, "II*\x00")
+	} else {
+		copy(out, "MM\x00*")
+	}
+	order.PutUint32(out[4:], offsets[0])
+
+	for i, ifd := range ifds {
+		pos := offsets[i]
+		order.PutUint16(out[pos:], uint16(len(ifd)))
+		for j, f := range ifd {
+			at := pos + 2 + uint32(12*j)
+			order.PutUint16(out[at:], f.tag)
+			order.PutUint16(out[at+2:], f.typ)
+			order.PutUint32(out[at+4:], f.count)
+			switch {
+			case f.ifd >= 0:
+				order.PutUint32(out[at+8:], offsets[f.ifd])
+			case len(f.data) <= 4:
+				copy(out[at+8:], f.data)
+			default:
+				order.PutUint32(out[at+8:], uint32(len(out)))
+				out = append(out, f.data...)
+			}
+		}
+	}
+	return out
+}
+
+// wrapJPEG puts a TIFF structure in an EXIF APP1 segment after a JFIF one.
+func wrapJPEG(tiff []byte) []byte {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x04, 'J', 'F'}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	jpeg = append(jpeg, 0xFF, 0xE1)
+	jpeg = binary.BigEndian.AppendUint16(jpeg, uint16(len(segment)+2))
+	jpeg = append(jpeg, segment...)
+	return append(jpeg, 0xFF, 0xDA, 0x00, 0x02)
+}
+
+func samplePhoto(order binary.ByteOrder) []byte {
+	return buildTIFF(order,
+		[]field{
+			asciiField(tagMake, "Canon"),
+			asciiField(tagModel, "Canon EOS R6"),
+			asciiField(tagDateTime, "2024:05:02 09:00:00"),
+			pointerField(tagExifIFD, 1),
+			pointerField(tagGPSIFD, 2),
+		},
+		[]field{
+			asciiField(tagDateTimeOriginal, "2024:05:01 13:45:12"),
+		},
+		[]field{
+			asciiField(tagGPSLatitudeRef, "N"),
+			rationalsField(tagGPSLatitude, order, [2]uint32{48, 1}, [2]uint32{51, 1}, [2]uint32{3012, 100}),
+			asciiField(tagGPSLongitudeRef, "W"),
+			rationalsField(tagGPSLongitude, order, [2]uint32{2, 1}, [2]uint32{17, 1}, [2]uint32{4000, 100}),
+		},
+	)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"jpeg little-endian", wrapJPEG(samplePhoto(binary.LittleEndian))},
+		{"jpeg big-endian", wrapJPEG(samplePhoto(binary.BigEndian))},
+		{"tiff", samplePhoto(binary.LittleEndian)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Parse(tt.data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := d.Camera(); got != "Canon EOS R6" {
+				t.Errorf("Camera() = %q, want %q", got, "Canon EOS R6")
+			}
+			want := time.Date(2024, 5, 1, 13, 45, 12, 0, time.Local)
+			if !d.Taken.Equal(want) {
+				t.Errorf("Taken = %v, want %v (DateTimeOriginal)", d.Taken, want)
+			}
+			if !d.HasGPS || math.Abs(d.Latitude-48.85837) > 1e-4 || math.Abs(d.Longitude+2.2944) > 1e-4 {
+				t.Errorf("GPS = %v %f %f, want 48.85837, -2.29444", d.HasGPS, d.Latitude, d.Longitude)
+			}
+			if got, want := d.Position(), "48.85837° N, 2.29444° W"; got != want {
+				t.Errorf("Position() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseWithoutExif(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n")},
+		{"jpeg without exif", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0x00, 0x02}},
+		{"truncated segment", []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x40, 0x00, 'E'}},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.data); !errors.Is(err, ErrNoExif) {
+				t.Errorf("Parse() error = %v, want ErrNoExif", err)
+			}
+		})
+	}
+}
+
+func TestParseOutOfRangeOffsets(t *testing.T) {
+	// A field pointing past the end must not panic
+	tiff := buildTIFF(binary.LittleEndian, []field{asciiField(tagModel, "A long model name")})
+	tiff = tiff[:len(tiff)-4]
+
+	d, err := Parse(tiff)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if d.Model != "" || !d.Taken.IsZero() {
+		t.Errorf("Parse() = %+v, want no values", d)
+	}
+}
+
+func TestCamera(t *testing.T) {
+	tests := []struct {
+		make, model, want string
+	}{
+		{"Canon", "Canon EOS R6", "Canon EOS R6"},
+		{"FUJIFILM", "X-T4", "FUJIFILM X-T4"},
+		{"Apple", "", "Apple"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := (Data{Make: tt.make, Model: tt.model}).Camera(); got != tt.want {
+			t.Errorf("Camera(%q, %q) = %q, want %q", tt.make, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, wrapJPEG(samplePhoto(binary.LittleEndian)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	d, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if d.Model != "Canon EOS R6" {
+		t.Errorf("Model = %q, want Canon EOS R6", d.Model)
+	}
+}
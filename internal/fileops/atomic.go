@@ -0,0 +1,62 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file at path with data, so a crash leaves
+// either the old file or the new one, never a mix. It writes a temporary
+// file in the same directory, syncs it to disk and renames it over path.
+// The file gets perm even if the old one had other permissions. If path is
+// a symlink, such as a config file kept in a dotfiles repository, the file
+// it points to is replaced and the link is kept.
+func WriteFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	// Only does anything if the rename below didn't happen
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := writeAndSync(tmp, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	// Sync the directory so the rename itself survives a crash
+	if d, err := os.Open(dir); err == nil { // #nosec G304 -- directory of the file being written
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
+
+// writeAndSync fills the temporary file f and closes it once it is on disk.
+func writeAndSync(f *os.File, data []byte, perm fs.FileMode) error {
+	if err := f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,80 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "memory.json")
+
+	if err := WriteFileAtomic(path, []byte("first"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	// Replacing a file fixes its permissions too
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second" {
+		t.Errorf("content = %q, want %q", data, "second")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want no temporary files left", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_Symlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.toml")
+	link := filepath.Join(dir, "config.toml")
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(link, []byte("new"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic() error = %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("%s is no longer a symlink", link)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("target content = %q, want %q", data, "new")
+	}
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "file")
+	if err := WriteFileAtomic(path, []byte("data"), 0600); err == nil {
+		t.Error("WriteFileAtomic() into a missing directory should fail")
+	}
+}
@@ -0,0 +1,89 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// caseProbeEntries caps how many entries CaseInsensitive looks through for
+// one it can probe with.
+const caseProbeEntries = 64
+
+// CaseInsensitive reports whether dir treats names that differ only in
+// case as the same name. Besides the filesystem's type, it looks up an
+// entry of dir under another case and checks whether that finds the same
+// file; an empty directory on an unknown filesystem counts as sensitive.
+// Filesystems such as SMB shares and ext4 directories with casefolding on
+// are only found out by probing.
+func CaseInsensitive(dir string) bool {
+	if fs, err := StatFilesystem(dir); err == nil && fs.traits().caseInsensitive {
+		return true
+	}
+
+	f, err := os.Open(dir) // #nosec G304 -- listing a directory the user is in
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	names, err := f.Readdirnames(caseProbeEntries)
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		swapped := swapCase(name)
+		if swapped == name {
+			continue
+		}
+		info, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		other, err := os.Lstat(filepath.Join(dir, swapped))
+		return err == nil && os.SameFile(info, other)
+	}
+	return false
+}
+
+// swapCase returns name with its upper case letters made lower case and
+// the other way round.
+func swapCase(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, name)
+}
+
+// CaseConflict is a name a transfer would write that a case-insensitive
+// destination already holds, or is given by another source, under a
+// different case. Writing it would overwrite or merge with the other.
+type CaseConflict struct {
+	Name  string // The name being written
+	Taken string // The name it clashes with
+}
+
+// caseConflicts returns the clashes between the base names of sources, and
+// between them and existing, the names already in a case-insensitive
+// destination. Names equal but for case are compared as strings.EqualFold
+// does.
+func caseConflicts(sources []string, existing []string) []CaseConflict {
+	taken := make(map[string]string, len(existing)+len(sources))
+	for _, name := range existing {
+		taken[strings.ToLower(name)] = name
+	}
+
+	var conflicts []CaseConflict
+	for _, src := range sources {
+		name := filepath.Base(src)
+		key := strings.ToLower(name)
+		if other, ok := taken[key]; ok && other != name && strings.EqualFold(other, name) {
+			conflicts = append(conflicts, CaseConflict{Name: name, Taken: other})
+			continue
+		}
+		taken[key] = name
+	}
+	return conflicts
+}
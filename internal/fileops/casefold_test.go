@@ -0,0 +1,58 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	// The temporary directory is on a case-sensitive filesystem here, with
+	// or without an entry to probe with
+	tmpDir := t.TempDir()
+	if CaseInsensitive(tmpDir) {
+		t.Error("CaseInsensitive() = true for an empty directory")
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "Notes.txt"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if CaseInsensitive(tmpDir) {
+		t.Error("CaseInsensitive() = true, want false")
+	}
+	if CaseInsensitive(filepath.Join(tmpDir, "missing")) {
+		t.Error("CaseInsensitive() = true for a missing directory")
+	}
+}
+
+func TestSwapCase(t *testing.T) {
+	if got := swapCase("Ab-Ç.txt"); got != "aB-ç.TXT" {
+		t.Errorf("swapCase() = %q", got)
+	}
+}
+
+func TestCaseConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		sources  []string
+		existing []string
+		want     []CaseConflict
+	}{
+		{"none", []string{"/src/a.txt", "/src/b.txt"}, []string{"c.txt"}, nil},
+		{"same name overwrites", []string{"/src/a.txt"}, []string{"a.txt"}, nil},
+		{"existing", []string{"/src/README.md"}, []string{"readme.md"},
+			[]CaseConflict{{Name: "README.md", Taken: "readme.md"}}},
+		{"between sources", []string{"/x/Photo.JPG", "/y/photo.jpg"}, nil,
+			[]CaseConflict{{Name: "photo.jpg", Taken: "Photo.JPG"}}},
+		{"non-ASCII", []string{"/src/ÉTÉ"}, []string{"été"},
+			[]CaseConflict{{Name: "ÉTÉ", Taken: "été"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := caseConflicts(tt.sources, tt.existing); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("caseConflicts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package fileops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// CleanupKind says why an entry was offered for cleanup.
+type CleanupKind int
+
+const (
+	// CleanupBrokenLink is a symlink whose target doesn't exist
+	CleanupBrokenLink CleanupKind = iota
+	// CleanupEmptyDir is a directory with nothing in it but empty
+	// directories
+	CleanupEmptyDir
+)
+
+// String returns a short description for listings.
+func (k CleanupKind) String() string {
+	if k == CleanupBrokenLink {
+		return "broken link"
+	}
+	return "empty directory"
+}
+
+// CleanupItem is one entry FindCleanup suggests removing.
+type CleanupItem struct {
+	Path string
+	Kind CleanupKind
+}
+
+// FindCleanup scans the tree under root for broken symlinks and empty
+// directories. A directory holding only empty directories is reported
+// once, instead of each of them; root itself is never reported. Symlinks
+// are not followed, and unreadable directories are left alone. Cancelling
+// ctx stops the scan with ctx's error.
+func FindCleanup(ctx context.Context, root string) ([]CleanupItem, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	_, items, err := scanCleanup(ctx, root, entries)
+	return items, err
+}
+
+// scanCleanup looks through the entries of dir. It reports whether dir
+// holds nothing but empty directories, in which case the caller reports
+// dir and items is empty.
+func scanCleanup(ctx context.Context, dir string, entries []fs.DirEntry) (empty bool, items []CleanupItem, err error) {
+	empty = true
+	var emptyDirs []CleanupItem
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return false, nil, err
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			empty = false
+			if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+				items = append(items, CleanupItem{Path: path, Kind: CleanupBrokenLink})
+			}
+
+		case entry.IsDir():
+			children, err := os.ReadDir(path)
+			if err != nil {
+				empty = false
+				continue
+			}
+			childEmpty, childItems, err := scanCleanup(ctx, path, children)
+			if err != nil {
+				return false, nil, err
+			}
+			if childEmpty {
+				emptyDirs = append(emptyDirs, CleanupItem{Path: path, Kind: CleanupEmptyDir})
+			} else {
+				empty = false
+				items = append(items, childItems...)
+			}
+
+		default:
+			empty = false
+		}
+	}
+
+	if empty {
+		return true, nil, nil
+	}
+	return false, append(items, emptyDirs...), nil
+}
+
+// DeleteCleanup removes items found by FindCleanup in the background.
+// Broken links are unlinked and empty directories removed from the
+// bottom up; anything that gained a file since the scan fails the
+// operation rather than being deleted with it.
+func DeleteCleanup(items []CleanupItem, callback ProgressCallback) *Operation {
+	paths := make([]string, len(items))
+	for i, item := range items {
+		paths[i] = item.Path
+	}
+	op := NewOperation(OpDelete, paths, "")
+	op.Priority = PriorityHigh
+	return defaultQueue.submit(op, func() { performDeleteCleanup(op, items, callback) }, callback)
+}
+
+// performDeleteCleanup executes DeleteCleanup.
+func performDeleteCleanup(op *Operation, items []CleanupItem, callback ProgressCallback) {
+	op.SetStatus(StatusRunning)
+
+	for i, item := range items {
+		if op.IsCancelled() {
+			break
+		}
+		op.UpdateProgress(int64(i), int64(len(items)), item.Path)
+
+		var err error
+		if item.Kind == CleanupEmptyDir {
+			err = removeEmptyTree(item.Path)
+		} else {
+			err = removeBrokenLink(item.Path)
+		}
+		if err != nil {
+			op.SetError(fmt.Errorf("failed to delete %s: %w", item.Path, err))
+			if callback != nil {
+				callback(op)
+			}
+			return
+		}
+	}
+
+	if !op.IsCancelled() {
+		op.UpdateProgress(int64(len(items)), int64(len(items)), "")
+		op.SetStatus(StatusCompleted)
+	}
+	if callback != nil {
+		callback(op)
+	}
+}
+
+// removeBrokenLink unlinks path if it is still a symlink to nothing.
+func removeBrokenLink(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSymlink == 0 {
+		return errors.New("no longer a symlink")
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return errors.New("link target exists again")
+	}
+	return os.Remove(path)
+}
+
+// removeEmptyTree removes a directory and the empty directories in it.
+// os.Remove refuses non-empty directories, so files are never touched.
+func removeEmptyTree(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			return fmt.Errorf("%s is no longer empty", filepath.Base(dir))
+		}
+		if err := removeEmptyTree(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(dir)
+}
@@ -0,0 +1,131 @@
+package fileops
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestFindCleanup(t *testing.T) {
+	root := t.TempDir()
+	mkdir := func(rel string) {
+		if err := os.MkdirAll(filepath.Join(root, rel), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(rel string) {
+		if err := os.WriteFile(filepath.Join(root, rel), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := func(target, rel string) {
+		if err := os.Symlink(target, filepath.Join(root, rel)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mkdir("empty")
+	mkdir("nested/a/b") // Reported once, as nested
+	mkdir("mixed/keep") // Has a file, so only its empty child is reported
+	mkdir("mixed/drop")
+	write("mixed/keep/file.txt")
+	write("file.txt")
+	link("file.txt", "good-link")
+	link("missing.txt", "broken-link")
+	link("gone", "mixed/keep/broken")
+	mkdir("linked")
+	link(filepath.Join(root, "nowhere"), "linked/abs") // A broken link keeps its directory
+
+	items, err := FindCleanup(context.Background(), root)
+	if err != nil {
+		t.Fatalf("FindCleanup() error = %v", err)
+	}
+
+	got := make([]string, len(items))
+	for i, item := range items {
+		rel, _ := filepath.Rel(root, item.Path)
+		got[i] = rel + " " + item.Kind.String()
+	}
+	sort.Strings(got)
+	want := []string{
+		"broken-link broken link",
+		"empty empty directory",
+		"linked/abs broken link",
+		"mixed/drop empty directory",
+		"mixed/keep/broken broken link",
+		"nested empty directory",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FindCleanup() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("item %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFindCleanupCancelled(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := FindCleanup(ctx, root); err != context.Canceled {
+		t.Errorf("FindCleanup() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestDeleteCleanup(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "nested")
+	if err := os.MkdirAll(filepath.Join(nested, "a", "b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(root, "broken")
+	if err := os.Symlink("missing", broken); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan *Operation, 1)
+	DeleteCleanup([]CleanupItem{
+		{Path: nested, Kind: CleanupEmptyDir},
+		{Path: broken, Kind: CleanupBrokenLink},
+	}, func(op *Operation) { done <- op })
+
+	if op := <-done; op.Status != StatusCompleted {
+		t.Fatalf("DeleteCleanup() status = %v, error = %v", op.Status, op.Error)
+	}
+	for _, path := range []string{nested, broken} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", path)
+		}
+	}
+}
+
+func TestDeleteCleanupKeepsNewFiles(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "was-empty")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A file added after the scan
+	file := filepath.Join(dir, "sub", "new.txt")
+	if err := os.WriteFile(file, []byte("keep me"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan *Operation, 1)
+	DeleteCleanup([]CleanupItem{{Path: dir, Kind: CleanupEmptyDir}}, func(op *Operation) { done <- op })
+
+	if op := <-done; op.Status != StatusFailed {
+		t.Errorf("DeleteCleanup() status = %v, want failed", op.Status)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("new file was deleted: %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package fileops
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// CompareStatus says how an entry in one directory relates to the other.
+type CompareStatus int
+
+const (
+	// CompareSame means the entry exists on both sides and matches
+	CompareSame CompareStatus = iota
+	// CompareLeftOnly means the entry only exists in the left directory
+	CompareLeftOnly
+	// CompareRightOnly means the entry only exists in the right directory
+	CompareRightOnly
+	// CompareDiffers means both sides have the entry but it differs
+	CompareDiffers
+)
+
+// CompareMode selects how files present on both sides are compared.
+type CompareMode int
+
+const (
+	// CompareSizeTime compares sizes and modification times
+	CompareSizeTime CompareMode = iota
+	// CompareChecksum compares contents; files of equal size are hashed
+	CompareChecksum
+)
+
+// mtimeTolerance absorbs timestamp precision differences between
+// filesystems (FAT stores two-second times, some network filesystems
+// whole seconds), so copies with preserved times still compare equal.
+const mtimeTolerance = 2 * time.Second
+
+// CompareEntry is one name from either directory and how the sides differ.
+type CompareEntry struct {
+	Name   string
+	Status CompareStatus
+	Left   *models.FileInfo // nil if only on the right
+	Right  *models.FileInfo // nil if only on the left
+}
+
+// LeftNewer reports whether both sides have the entry and the left copy
+// was modified later.
+func (e CompareEntry) LeftNewer() bool {
+	return e.Left != nil && e.Right != nil && e.Left.ModTime.Sub(e.Right.ModTime) > mtimeTolerance
+}
+
+// CompareDirectories compares the entries of two directories by name,
+// including hidden ones, sorted by name. Subdirectories present on both
+// sides compare as the same without being descended into; a file on one
+// side and a directory on the other differ.
+func CompareDirectories(left, right string, mode CompareMode) ([]CompareEntry, error) {
+	leftFiles, err := ListDirectory(left, true)
+	if err != nil {
+		return nil, err
+	}
+	rightFiles, err := ListDirectory(right, true)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*CompareEntry, len(leftFiles)+len(rightFiles))
+	for i := range leftFiles {
+		byName[leftFiles[i].Name] = &CompareEntry{
+			Name:   leftFiles[i].Name,
+			Status: CompareLeftOnly,
+			Left:   &leftFiles[i],
+		}
+	}
+	for i := range rightFiles {
+		r := &rightFiles[i]
+		e, ok := byName[r.Name]
+		if !ok {
+			byName[r.Name] = &CompareEntry{Name: r.Name, Status: CompareRightOnly, Right: r}
+			continue
+		}
+		e.Right = r
+		same, err := sameContents(*e.Left, *r, mode)
+		if err != nil {
+			return nil, err
+		}
+		if same {
+			e.Status = CompareSame
+		} else {
+			e.Status = CompareDiffers
+		}
+	}
+
+	entries := make([]CompareEntry, 0, len(byName))
+	for _, e := range byName {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
+}
+
+// sameContents compares two entries with the same name.
+func sameContents(a, b models.FileInfo, mode CompareMode) (bool, error) {
+	if a.IsDir || b.IsDir {
+		return a.IsDir == b.IsDir, nil
+	}
+	if a.Size != b.Size {
+		return false, nil
+	}
+	if mode == CompareSizeTime {
+		return a.ModTime.Sub(b.ModTime).Abs() <= mtimeTolerance, nil
+	}
+
+	sumA, err := fileChecksum(a.Path)
+	if err != nil {
+		return false, err
+	}
+	sumB, err := fileChecksum(b.Path)
+	if err != nil {
+		return false, err
+	}
+	return sumA == sumB, nil
+}
+
+// fileChecksum returns the SHA-256 of a file's contents.
+func fileChecksum(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+// CompareMark is how an entry is highlighted in its pane after a comparison.
+type CompareMark int
+
+const (
+	// MarkNone means the entry matches the other side
+	MarkNone CompareMark = iota
+	// MarkUnique means the other side has no entry of that name
+	MarkUnique
+	// MarkDiffers means both sides have the entry but it differs, and
+	// this copy is not the newer one
+	MarkDiffers
+	// MarkNewer means both sides have the entry, it differs, and this
+	// copy was modified later
+	MarkNewer
+)
+
+// CompareMarks returns the highlight of every entry that differs, keyed
+// by path, for the left and right directories.
+func CompareMarks(entries []CompareEntry) (left, right map[string]CompareMark) {
+	left = make(map[string]CompareMark)
+	right = make(map[string]CompareMark)
+	for _, e := range entries {
+		switch e.Status {
+		case CompareLeftOnly:
+			left[e.Left.Path] = MarkUnique
+		case CompareRightOnly:
+			right[e.Right.Path] = MarkUnique
+		case CompareDiffers:
+			left[e.Left.Path], right[e.Right.Path] = MarkDiffers, MarkDiffers
+			switch {
+			case e.LeftNewer():
+				left[e.Left.Path] = MarkNewer
+			case e.Right.ModTime.Sub(e.Left.ModTime) > mtimeTolerance:
+				right[e.Right.Path] = MarkNewer
+			}
+		}
+	}
+	return left, right
+}
+
+// CompareSummary counts the entries of a comparison by status.
+type CompareSummary struct {
+	LeftOnly  int
+	RightOnly int
+	Differ    int
+}
+
+// Summarize counts entries by status.
+func Summarize(entries []CompareEntry) CompareSummary {
+	var s CompareSummary
+	for _, e := range entries {
+		switch e.Status {
+		case CompareLeftOnly:
+			s.LeftOnly++
+		case CompareRightOnly:
+			s.RightOnly++
+		case CompareDiffers:
+			s.Differ++
+		}
+	}
+	return s
+}
+
+// NewerSources returns the left-side paths that "sync newer →" copies to
+// the right: entries missing on the right, and differing files whose left
+// copy is newer.
+func NewerSources(entries []CompareEntry) []string {
+	var sources []string
+	for _, e := range entries {
+		switch {
+		case e.Status == CompareLeftOnly:
+			sources = append(sources, e.Left.Path)
+		case e.Status == CompareDiffers && !e.Left.IsDir && !e.Right.IsDir && e.LeftNewer():
+			sources = append(sources, e.Left.Path)
+		}
+	}
+	return sources
+}
+
+// SyncNewer starts copying NewerSources(entries) into destination,
+// overwriting the older right-side copies. It returns nil if there is
+// nothing to copy.
+func SyncNewer(entries []CompareEntry, destination string, callback ProgressCallback) *Operation {
+	sources := NewerSources(entries)
+	if len(sources) == 0 {
+		return nil
+	}
+	return CopyMultiple(sources, destination, callback)
+}
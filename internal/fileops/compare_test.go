@@ -0,0 +1,215 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// compareFixture creates left and right directories:
+//
+//	same.txt     identical on both sides
+//	newer.txt    left copy is newer and larger
+//	older.txt    right copy is newer and larger
+//	touched.txt  same contents, left copy is newer
+//	left.txt     left only
+//	right.txt    right only
+//	dir/         a directory on both sides
+//	mixed        a file on the left, a directory on the right
+func compareFixture(t *testing.T) (left, right string) {
+	t.Helper()
+	left, right = t.TempDir(), t.TempDir()
+	old := time.Now().Add(-time.Hour)
+	now := time.Now()
+
+	write := func(dir, name, data string, mtime time.Time) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mkdir := func(dir, name string) {
+		if err := os.Mkdir(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(left, "same.txt", "same", old)
+	write(right, "same.txt", "same", old)
+	write(left, "newer.txt", "new contents", now)
+	write(right, "newer.txt", "old", old)
+	write(left, "older.txt", "old", old)
+	write(right, "older.txt", "new contents", now)
+	write(left, "touched.txt", "data", now)
+	write(right, "touched.txt", "data", old)
+	write(left, "left.txt", "left", now)
+	write(right, "right.txt", "right", now)
+	mkdir(left, "dir")
+	mkdir(right, "dir")
+	write(left, "mixed", "file", now)
+	mkdir(right, "mixed")
+	return left, right
+}
+
+func TestCompareDirectories(t *testing.T) {
+	left, right := compareFixture(t)
+
+	tests := []struct {
+		mode CompareMode
+		want map[string]CompareStatus
+	}{
+		{CompareSizeTime, map[string]CompareStatus{
+			"dir":         CompareSame,
+			"left.txt":    CompareLeftOnly,
+			"mixed":       CompareDiffers,
+			"newer.txt":   CompareDiffers,
+			"older.txt":   CompareDiffers,
+			"right.txt":   CompareRightOnly,
+			"same.txt":    CompareSame,
+			"touched.txt": CompareDiffers,
+		}},
+		{CompareChecksum, map[string]CompareStatus{
+			"dir":         CompareSame,
+			"left.txt":    CompareLeftOnly,
+			"mixed":       CompareDiffers,
+			"newer.txt":   CompareDiffers,
+			"older.txt":   CompareDiffers,
+			"right.txt":   CompareRightOnly,
+			"same.txt":    CompareSame,
+			"touched.txt": CompareSame, // Only the time differs
+		}},
+	}
+
+	for _, tt := range tests {
+		entries, err := CompareDirectories(left, right, tt.mode)
+		if err != nil {
+			t.Fatalf("CompareDirectories(mode %d) error = %v", tt.mode, err)
+		}
+		if len(entries) != len(tt.want) {
+			t.Fatalf("CompareDirectories(mode %d) returned %d entries, want %d", tt.mode, len(entries), len(tt.want))
+		}
+		for i, e := range entries {
+			if i > 0 && entries[i-1].Name >= e.Name {
+				t.Errorf("entries not sorted: %q before %q", entries[i-1].Name, e.Name)
+			}
+			if e.Status != tt.want[e.Name] {
+				t.Errorf("mode %d: %s status = %d, want %d", tt.mode, e.Name, e.Status, tt.want[e.Name])
+			}
+		}
+	}
+}
+
+func TestCompareDirectories_Missing(t *testing.T) {
+	if _, err := CompareDirectories(t.TempDir(), filepath.Join(t.TempDir(), "gone"), CompareSizeTime); err == nil {
+		t.Error("CompareDirectories() with a missing directory should fail")
+	}
+}
+
+func TestCompareMarks(t *testing.T) {
+	left, right := compareFixture(t)
+	entries, err := CompareDirectories(left, right, CompareSizeTime)
+	if err != nil {
+		t.Fatalf("CompareDirectories() error = %v", err)
+	}
+
+	leftMarks, rightMarks := CompareMarks(entries)
+	wantLeft := map[string]CompareMark{
+		"newer.txt":   MarkNewer,
+		"older.txt":   MarkDiffers,
+		"touched.txt": MarkNewer,
+		"left.txt":    MarkUnique,
+		"mixed":       MarkDiffers,
+	}
+	wantRight := map[string]CompareMark{
+		"newer.txt":   MarkDiffers,
+		"older.txt":   MarkNewer,
+		"touched.txt": MarkDiffers,
+		"right.txt":   MarkUnique,
+		"mixed":       MarkDiffers,
+	}
+	check := func(side, dir string, got, want map[string]CompareMark) {
+		if len(got) != len(want) {
+			t.Errorf("%s marks = %v, want %d entries", side, got, len(want))
+		}
+		for name, mark := range want {
+			if got[filepath.Join(dir, name)] != mark {
+				t.Errorf("%s mark of %s = %v, want %v", side, name, got[filepath.Join(dir, name)], mark)
+			}
+		}
+	}
+	check("left", left, leftMarks, wantLeft)
+	check("right", right, rightMarks, wantRight)
+
+	want := CompareSummary{LeftOnly: 1, RightOnly: 1, Differ: 4}
+	if got := Summarize(entries); got != want {
+		t.Errorf("Summarize() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewerSources(t *testing.T) {
+	left, right := compareFixture(t)
+	entries, err := CompareDirectories(left, right, CompareSizeTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, path := range NewerSources(entries) {
+		got = append(got, filepath.Base(path))
+	}
+	want := []string{"left.txt", "newer.txt", "touched.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("NewerSources() = %v, want %v", got, want)
+	}
+}
+
+func TestSyncNewer(t *testing.T) {
+	left, right := compareFixture(t)
+	entries, err := CompareDirectories(left, right, CompareSizeTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op := SyncNewer(entries, right, nil)
+	if op == nil {
+		t.Fatal("SyncNewer() returned nil with files to copy")
+	}
+	waitForOperation(t, op, 5*time.Second)
+	if op.Status != StatusCompleted {
+		t.Fatalf("sync status = %v, error = %v", op.Status, op.Error)
+	}
+
+	for name, want := range map[string]string{
+		"newer.txt": "new contents",
+		"left.txt":  "left",
+		"older.txt": "new contents", // Right copy was newer and is kept
+	} {
+		data, err := os.ReadFile(filepath.Join(right, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", name, data, want)
+		}
+	}
+
+	// Everything copied now matches; the newer right copy and the
+	// file/directory clash are left alone
+	entries, err = CompareDirectories(left, right, CompareChecksum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Status == CompareLeftOnly || (e.Status == CompareDiffers && e.Name != "mixed" && e.Name != "older.txt") {
+			t.Errorf("%s still differs after sync (status %d)", e.Name, e.Status)
+		}
+	}
+	if op := SyncNewer(nil, right, nil); op != nil {
+		t.Error("SyncNewer() with nothing to copy should return nil")
+	}
+}
@@ -0,0 +1,78 @@
+package fileops
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// maxCachedCounts bounds an ItemCounts; past it the cache starts over.
+const maxCachedCounts = 8192
+
+// ItemCounts caches the entry counts of directories. A count stays valid
+// while the directory's modification time is unchanged, since adding,
+// removing or renaming an entry updates it. Safe for concurrent use.
+type ItemCounts struct {
+	mu     sync.Mutex
+	counts map[string]cachedCount
+}
+
+// cachedCount is a count and the modification time it was taken at.
+type cachedCount struct {
+	modTime time.Time
+	n       int
+}
+
+// NewItemCounts returns an empty cache.
+func NewItemCounts() *ItemCounts {
+	return &ItemCounts{counts: make(map[string]cachedCount)}
+}
+
+// Lookup returns the cached count of the directory dir, if it is still
+// valid for dir.ModTime.
+func (c *ItemCounts) Lookup(dir models.FileInfo) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.counts[dir.Path]
+	if !ok || !cached.modTime.Equal(dir.ModTime) {
+		return 0, false
+	}
+	return cached.n, true
+}
+
+// Count returns the number of entries in the directory dir, from the cache
+// or by reading it. Unreadable directories count as -1.
+func (c *ItemCounts) Count(dir models.FileInfo) int {
+	if n, ok := c.Lookup(dir); ok {
+		return n
+	}
+	n := CountEntries(dir.Path)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.counts) >= maxCachedCounts {
+		c.counts = make(map[string]cachedCount)
+	}
+	c.counts[dir.Path] = cachedCount{modTime: dir.ModTime, n: n}
+	return n
+}
+
+// Fill sets the ItemCount of the directories in files whose count is
+// cached, and returns the indices of those still to be counted. Pending
+// directories are left out: their modification time, which a count is
+// valid for, is not known yet.
+func (c *ItemCounts) Fill(files []models.FileInfo) []int {
+	var missing []int
+	for i := range files {
+		if !files[i].IsDir || files[i].Pending {
+			continue
+		}
+		if n, ok := c.Lookup(files[i]); ok {
+			files[i].ItemCount = n
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
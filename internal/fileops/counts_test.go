@@ -0,0 +1,75 @@
+package fileops
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lawrab/warren/pkg/models"
+)
+
+func TestReadDirectoryLeavesCountsUnknown(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub", "child"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ReadDirectory(context.Background(), root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].ItemCount != -1 {
+		t.Fatalf("ReadDirectory = %+v, want sub with ItemCount -1", files)
+	}
+}
+
+func TestItemCounts(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, "a"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func() []models.FileInfo {
+		files, err := ReadDirectory(context.Background(), root, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files
+	}
+
+	counts := NewItemCounts()
+	files := list()
+	if missing := counts.Fill(files); len(missing) != 1 || missing[0] != 0 {
+		t.Fatalf("Fill on an empty cache = %v, want [0]", missing)
+	}
+	if n := counts.Count(files[0]); n != 1 {
+		t.Errorf("Count = %d, want 1", n)
+	}
+
+	files = list()
+	if missing := counts.Fill(files); len(missing) != 0 || files[0].ItemCount != 1 {
+		t.Errorf("Fill after counting = %v with ItemCount %d, want cached 1", missing, files[0].ItemCount)
+	}
+
+	// A new entry changes the directory's modification time
+	if err := os.WriteFile(filepath.Join(sub, "b"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(sub, later, later); err != nil {
+		t.Fatal(err)
+	}
+	files = list()
+	if _, ok := counts.Lookup(files[0]); ok {
+		t.Error("Lookup after a change still hit the cache")
+	}
+	if n := counts.Count(files[0]); n != 2 {
+		t.Errorf("Count after a change = %d, want 2", n)
+	}
+}
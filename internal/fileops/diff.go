@@ -0,0 +1,112 @@
+package fileops
+
+import "github.com/lawrab/warren/pkg/models"
+
+// EditKind is the kind of change a ListingEdit makes.
+type EditKind int
+
+const (
+	// EditRemove removes the row at Index
+	EditRemove EditKind = iota
+	// EditInsert inserts the new listing's File at Index
+	EditInsert
+	// EditUpdate replaces the row at Index with the new listing's File
+	EditUpdate
+)
+
+// ListingEdit is one step that turns an old directory listing into a new one.
+type ListingEdit struct {
+	Kind  EditKind
+	Index int // Row position at the time the edit is applied
+	File  int // Index into the new listing, for inserts and updates
+}
+
+// DiffListing returns the edits that turn old into new, applied in order.
+// Rows whose file is unchanged are left alone, so a view applying the edits
+// keeps their selection and scroll position. Files are matched by path;
+// a file that moved (its sort position changed) is removed and re-inserted.
+//
+// Edits are emitted front to back: once an edit at Index is applied, every
+// row before Index is in its final position.
+func DiffListing(old, new []models.FileInfo) []ListingEdit {
+	oldIndex := make(map[string]int, len(old))
+	for i, f := range old {
+		oldIndex[f.Path] = i
+	}
+	newIndex := make(map[string]int, len(new))
+	for j, f := range new {
+		newIndex[f.Path] = j
+	}
+
+	var edits []ListingEdit
+	// Files that moved: removed where they were, or inserted where they are
+	// now ahead of reaching their old row
+	removed := make(map[string]bool)
+	inserted := make(map[string]bool)
+	i, j, pos := 0, 0, 0
+	for i < len(old) || j < len(new) {
+		if i < len(old) && inserted[old[i].Path] {
+			edits = append(edits, ListingEdit{Kind: EditRemove, Index: pos})
+			i++
+			continue
+		}
+		if i == len(old) {
+			edits = append(edits, ListingEdit{Kind: EditInsert, Index: pos, File: j})
+			j, pos = j+1, pos+1
+			continue
+		}
+		if j == len(new) {
+			edits = append(edits, ListingEdit{Kind: EditRemove, Index: pos})
+			i++
+			continue
+		}
+
+		oldPath, newPath := old[i].Path, new[j].Path
+		oldAt, newWasListed := oldIndex[newPath]
+		newAt, oldStillListed := newIndex[oldPath]
+		switch {
+		case oldPath == newPath:
+			if !sameFile(old[i], new[j]) {
+				edits = append(edits, ListingEdit{Kind: EditUpdate, Index: pos, File: j})
+			}
+			i, j, pos = i+1, j+1, pos+1
+
+		case !newWasListed || removed[newPath]:
+			edits = append(edits, ListingEdit{Kind: EditInsert, Index: pos, File: j})
+			j, pos = j+1, pos+1
+
+		case !oldStillListed:
+			edits = append(edits, ListingEdit{Kind: EditRemove, Index: pos})
+			i++
+
+		case oldAt-i > newAt-j:
+			// new[j] jumped further forward than old[i] moved back: insert
+			// it here and drop its old row when reached
+			edits = append(edits, ListingEdit{Kind: EditInsert, Index: pos, File: j})
+			inserted[newPath] = true
+			j, pos = j+1, pos+1
+
+		default:
+			edits = append(edits, ListingEdit{Kind: EditRemove, Index: pos})
+			removed[oldPath] = true
+			i++
+		}
+	}
+	return edits
+}
+
+// sameFile reports whether two listings of the same path would display
+// identically.
+func sameFile(a, b models.FileInfo) bool {
+	return a.Name == b.Name &&
+		a.Size == b.Size &&
+		a.ItemCount == b.ItemCount &&
+		a.IsDir == b.IsDir &&
+		a.IsSymlink == b.IsSymlink &&
+		a.SymlinkTarget == b.SymlinkTarget &&
+		a.IsBrokenSymlink == b.IsBrokenSymlink &&
+		a.Permissions == b.Permissions &&
+		a.ModTime.Equal(b.ModTime) &&
+		a.IsHidden == b.IsHidden &&
+		a.IsUnreadable == b.IsUnreadable
+}
@@ -0,0 +1,85 @@
+package fileops
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// applyEdits replays edits on a copy of old the way FileView does on its
+// list store.
+func applyEdits(old, new []models.FileInfo, edits []ListingEdit) []models.FileInfo {
+	rows := append([]models.FileInfo(nil), old...)
+	for _, e := range edits {
+		switch e.Kind {
+		case EditRemove:
+			rows = append(rows[:e.Index], rows[e.Index+1:]...)
+		case EditInsert:
+			rows = append(rows[:e.Index], append([]models.FileInfo{new[e.File]}, rows[e.Index:]...)...)
+		case EditUpdate:
+			rows[e.Index] = new[e.File]
+		}
+	}
+	return rows
+}
+
+func listing(names ...string) []models.FileInfo {
+	files := make([]models.FileInfo, len(names))
+	for i, name := range names {
+		files[i] = models.FileInfo{Name: name, Path: "/dir/" + name, ModTime: time.Unix(1700000000, 0)}
+	}
+	return files
+}
+
+func TestDiffListing(t *testing.T) {
+	resized := listing("a", "b", "c")
+	resized[1].Size = 42
+
+	tests := []struct {
+		name      string
+		old, new  []models.FileInfo
+		wantEdits int
+	}{
+		{"identical", listing("a", "b", "c"), listing("a", "b", "c"), 0},
+		{"empty to files", nil, listing("a", "b"), 2},
+		{"files to empty", listing("a", "b"), nil, 2},
+		{"insert middle", listing("a", "c"), listing("a", "b", "c"), 1},
+		{"insert end", listing("a", "b"), listing("a", "b", "c"), 1},
+		{"remove middle", listing("a", "b", "c"), listing("a", "c"), 1},
+		{"update in place", listing("a", "b", "c"), resized, 1},
+		{"rename", listing("a", "b", "c"), listing("a", "bb", "c"), 2},
+		{"move later", listing("a", "b", "c", "d"), listing("b", "c", "a", "d"), 2},
+		{"move earlier", listing("a", "b", "c", "d"), listing("d", "a", "b", "c"), 2},
+		{"reverse", listing("a", "b", "c"), listing("c", "b", "a"), 4},
+		{"mixed", listing("a", "b", "c", "d"), listing("b", "x", "d", "e"), 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := DiffListing(tt.old, tt.new)
+			got := applyEdits(tt.old, tt.new, edits)
+			if len(got) != len(tt.new) || (len(got) > 0 && !reflect.DeepEqual(got, tt.new)) {
+				t.Errorf("applying %v gave %v, want %v", edits, got, tt.new)
+			}
+			if len(edits) != tt.wantEdits {
+				t.Errorf("DiffListing made %d edits %v, want %d", len(edits), edits, tt.wantEdits)
+			}
+		})
+	}
+}
+
+func TestDiffListingKeepsUnchangedRows(t *testing.T) {
+	old := listing("a", "b", "c", "d")
+	new := listing("a", "c", "d", "e")
+
+	for _, e := range DiffListing(old, new) {
+		if e.Kind == EditUpdate {
+			t.Errorf("unexpected update of unchanged row: %+v", e)
+		}
+		if e.Kind == EditInsert && new[e.File].Name != "e" {
+			t.Errorf("unexpected re-insert of %q", new[e.File].Name)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package fileops
+
+import "sync"
+
+// EventType says what happened to an operation.
+type EventType int
+
+const (
+	// EventStarted is sent when the operation starts running
+	EventStarted EventType = iota
+	// EventProgress is sent when more of the operation is done
+	EventProgress
+	// EventFileStarted is sent when the operation moves on to another
+	// file, named by Event.Path
+	EventFileStarted
+	// EventConflict is sent when a copy is about to overwrite the file at
+	// Event.Path
+	EventConflict
+	// EventFinished is sent once, when the operation completes, fails or
+	// is cancelled; it is the last event
+	EventFinished
+)
+
+// String returns a name for the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventStarted:
+		return "Started"
+	case EventProgress:
+		return "Progress"
+	case EventFileStarted:
+		return "FileStarted"
+	case EventConflict:
+		return "Conflict"
+	case EventFinished:
+		return "Finished"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is something that happened to an operation. Read the rest of its
+// state, such as progress, through Op's thread-safe getters.
+type Event struct {
+	Type EventType
+	Op   *Operation
+	Path string
+}
+
+// subscribers is a set of event handlers that can be added and removed
+// while events are being sent.
+type subscribers struct {
+	mu     sync.Mutex
+	next   int
+	fns    map[int]func(Event)
+	closed bool
+}
+
+// add registers fn and returns the function removing it. It returns
+// false, registering nothing, if the last event has already been sent.
+func (s *subscribers) add(fn func(Event)) (func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return func() {}, false
+	}
+	if s.fns == nil {
+		s.fns = make(map[int]func(Event))
+	}
+	id := s.next
+	s.next++
+	s.fns[id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.fns, id)
+	}, true
+}
+
+// send calls every handler with ev, outside the lock so handlers may
+// subscribe and unsubscribe. After the last event, handlers are dropped
+// and add refuses new ones.
+func (s *subscribers) send(ev Event, last bool) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	fns := make([]func(Event), 0, len(s.fns))
+	for _, fn := range s.fns {
+		fns = append(fns, fn)
+	}
+	if last {
+		s.closed = true
+		s.fns = nil
+	}
+	s.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(ev)
+	}
+}
+
+// Subscribe calls fn with each event of the operation from now on, until
+// the returned function is called. fn runs in the goroutine doing the
+// work, so it must be quick, and UI code must hand the event over to its
+// main thread. If the operation has already finished, fn is called with
+// EventFinished right away. Any number of subscribers can watch one
+// operation.
+func (op *Operation) Subscribe(fn func(Event)) (unsubscribe func()) {
+	unsubscribe, ok := op.events.add(fn)
+	if !ok {
+		fn(Event{Type: EventFinished, Op: op})
+	}
+	return unsubscribe
+}
+
+// emit sends an event of the given type to the operation's subscribers.
+// It must not be called with op.mu held.
+func (op *Operation) emit(t EventType, path string) {
+	op.events.send(Event{Type: t, Op: op, Path: path}, t == EventFinished)
+}
+
+// Subscribe calls fn with the events of every operation added to the
+// queue from now on, until the returned function is called. See
+// Operation.Subscribe.
+func (q *OperationQueue) Subscribe(fn func(Event)) (unsubscribe func()) {
+	unsubscribe, _ = q.events.add(fn)
+	return unsubscribe
+}
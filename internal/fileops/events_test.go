@@ -0,0 +1,148 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventLog records the events it is sent.
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (l *eventLog) record(ev Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, ev)
+}
+
+// types returns the recorded event types, with the path of file events.
+func (l *eventLog) types() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var types []string
+	for _, ev := range l.events {
+		name := ev.Type.String()
+		if ev.Path != "" {
+			name += " " + filepath.Base(ev.Path)
+		}
+		types = append(types, name)
+	}
+	return types
+}
+
+func TestOperation_Subscribe(t *testing.T) {
+	op := NewOperation(OpCopy, []string{"/src"}, "/dst")
+	var first, second eventLog
+	op.Subscribe(first.record)
+	op.Subscribe(second.record)
+
+	op.SetStatus(StatusRunning)
+	op.UpdateProgress(10, 100, "/src/a")
+	op.UpdateProgress(50, 100, "/src/a")
+	op.UpdateProgress(100, 100, "/src/b")
+	op.SetStatus(StatusCompleted)
+	op.SetStatus(StatusCompleted)
+	op.Cancel()
+
+	expected := []string{"Started", "FileStarted a", "Progress", "Progress", "FileStarted b", "Progress", "Finished"}
+	for _, log := range []*eventLog{&first, &second} {
+		if got := log.types(); !slices.Equal(got, expected) {
+			t.Errorf("events = %q, want %q", got, expected)
+		}
+	}
+}
+
+func TestOperation_SubscribeFailedAndCancelled(t *testing.T) {
+	failed := NewOperation(OpDelete, []string{"/src"}, "")
+	var failedLog eventLog
+	failed.Subscribe(failedLog.record)
+	failed.SetError(os.ErrNotExist)
+	failed.SetStatus(StatusFailed)
+
+	cancelled := NewOperation(OpDelete, []string{"/src"}, "")
+	var cancelledLog eventLog
+	cancelled.Subscribe(cancelledLog.record)
+	cancelled.Cancel()
+	cancelled.SetStatus(StatusCancelled)
+
+	for _, log := range []*eventLog{&failedLog, &cancelledLog} {
+		if got := log.types(); !slices.Equal(got, []string{"Finished"}) {
+			t.Errorf("events = %q, want a single Finished", got)
+		}
+	}
+}
+
+func TestOperation_Unsubscribe(t *testing.T) {
+	op := NewOperation(OpCopy, []string{"/src"}, "/dst")
+	var log eventLog
+	unsubscribe := op.Subscribe(log.record)
+
+	op.SetStatus(StatusRunning)
+	unsubscribe()
+	op.UpdateProgress(10, 100, "")
+
+	if got := log.types(); !slices.Equal(got, []string{"Started"}) {
+		t.Errorf("events = %q, want only Started", got)
+	}
+}
+
+func TestOperation_SubscribeAfterFinish(t *testing.T) {
+	op := NewOperation(OpCopy, []string{"/src"}, "/dst")
+	op.SetStatus(StatusCompleted)
+
+	var log eventLog
+	op.Subscribe(log.record)
+
+	if got := log.types(); !slices.Equal(got, []string{"Finished"}) {
+		t.Errorf("events = %q, want Finished right away", got)
+	}
+}
+
+func TestQueue_Subscribe(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src.txt")
+	dst := filepath.Join(tmpDir, "dst.txt")
+	for _, path := range []string{src, dst} {
+		if err := os.WriteFile(path, []byte("content"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := NewQueue(2)
+	var log eventLog
+	finished := make(chan struct{})
+	q.Subscribe(func(ev Event) {
+		log.record(ev)
+		if ev.Type == EventFinished {
+			close(finished)
+		}
+	})
+
+	op := q.Copy(src, dst, nil)
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for EventFinished")
+	}
+
+	types := log.types()
+	for _, want := range []string{"Started", "Conflict dst.txt", "Progress"} {
+		if !slices.Contains(types, want) {
+			t.Errorf("events = %q, want %s", types, want)
+		}
+	}
+	if types[len(types)-1] != "Finished" {
+		t.Errorf("last event = %s, want Finished", types[len(types)-1])
+	}
+	for _, ev := range log.events {
+		if ev.Op != op {
+			t.Errorf("event %s is for operation %s, want %s", ev.Type, ev.Op.ID, op.ID)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package fileops
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// fatReserved are the characters FAT and exFAT can't store in a name,
+// besides control characters.
+const fatReserved = `"*/:<>?\|`
+
+// RestrictsNames reports whether the filesystem holding dir refuses some
+// names Linux allows elsewhere, so a transfer there should fix them with
+// FixName first.
+func RestrictsNames(dir string) bool {
+	fs, err := StatFilesystem(dir)
+	return err == nil && fs.traits().restrictsNames
+}
+
+// FixName returns name as FAT and exFAT can store it: reserved characters
+// and control characters become "_", and the trailing spaces and dots
+// those filesystems would drop are removed. A name with nothing left
+// becomes "_".
+func FixName(name string) string {
+	fixed := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(fatReserved, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	fixed = strings.TrimRight(fixed, " .")
+	if fixed == "" {
+		return "_"
+	}
+	return fixed
+}
+
+// NameFix is an entry a transfer would write under another name, because
+// the destination can't store its own.
+type NameFix struct {
+	Path string // The source entry, inside or one of the sources
+	Name string // The name it gets at the destination
+}
+
+// nameFixes walks sources, not following links, and returns the entries
+// whose names FixName changes.
+func nameFixes(sources []string) ([]NameFix, error) {
+	var fixes []NameFix
+	for _, src := range sources {
+		err := filepath.WalkDir(src, func(path string, _ fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := filepath.Base(path)
+			if fixed := FixName(name); fixed != name {
+				fixes = append(fixes, NameFix{Path: path, Name: fixed})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fixes, nil
+}
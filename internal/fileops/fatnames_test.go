@@ -0,0 +1,105 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFixName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"notes.txt", "notes.txt"},
+		{"10:30 meeting.txt", "10_30 meeting.txt"},
+		{`what? "really" <yes>|*.md`, `what_ _really_ _yes___.md`},
+		{`back\slash`, "back_slash"},
+		{"tab\there", "tab_here"},
+		{"ends with dots...", "ends with dots"},
+		{"ends with space ", "ends with space"},
+		{"...", "_"},
+		{"café ☕", "café ☕"},
+	}
+
+	for _, tt := range tests {
+		if got := FixName(tt.name); got != tt.want {
+			t.Errorf("FixName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fatTree creates a directory holding names FAT can't store, returning it.
+//
+//nolint:gosec // Test file permissions are intentionally relaxed
+func fatTree(t *testing.T, dir string) string {
+	t.Helper()
+	tree := filepath.Join(dir, "photos: 2024")
+	if err := os.MkdirAll(filepath.Join(tree, "what?"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		"ok.jpg":           "ok",
+		"a*b.jpg":          "star",
+		"what?/trailing. ": "dot",
+	} {
+		if err := os.WriteFile(filepath.Join(tree, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tree
+}
+
+func TestNameFixes(t *testing.T) {
+	tree := fatTree(t, t.TempDir())
+
+	fixes, err := nameFixes([]string{tree})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []NameFix{
+		{Path: tree, Name: "photos_ 2024"},
+		{Path: filepath.Join(tree, "a*b.jpg"), Name: "a_b.jpg"},
+		{Path: filepath.Join(tree, "what?"), Name: "what_"},
+		{Path: filepath.Join(tree, "what?", "trailing. "), Name: "trailing"},
+	}
+	if !reflect.DeepEqual(fixes, want) {
+		t.Errorf("nameFixes() = %v, want %v", fixes, want)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTransferFixingNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	tree := fatTree(t, tmpDir)
+	dst := filepath.Join(tmpDir, "dst")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	op := CopyMultipleWith([]string{tree}, dst, TransferOptions{FixNames: true}, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("copy: status = %v (%v)", op.GetStatus(), op.Error)
+	}
+	copied := filepath.Join(dst, "photos_ 2024")
+	verifyFileExists(t, filepath.Join(copied, "ok.jpg"), "ok")
+	verifyFileExists(t, filepath.Join(copied, "a_b.jpg"), "star")
+	verifyFileExists(t, filepath.Join(copied, "what_", "trailing"), "dot")
+	if results := op.GetResults(); len(results) != 1 || results[0] != copied {
+		t.Errorf("GetResults() = %q, want [%s]", results, copied)
+	}
+
+	moved := filepath.Join(tmpDir, "moved")
+	if err := os.Mkdir(moved, 0755); err != nil {
+		t.Fatal(err)
+	}
+	op = MoveMultipleWith([]string{filepath.Join(tree, "a*b.jpg")}, moved, TransferOptions{FixNames: true}, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("move: status = %v (%v)", op.GetStatus(), op.Error)
+	}
+	verifyFileExists(t, filepath.Join(moved, "a_b.jpg"), "star")
+}
@@ -0,0 +1,76 @@
+package fileops
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// NameFilter restricts a listing to files whose names match any of a set
+// of glob patterns. Matching ignores case. Directories always pass, so a
+// filter stays usable while navigating. The zero value matches everything.
+type NameFilter struct {
+	patterns []string
+}
+
+// ParseNameFilter parses patterns separated by spaces or commas
+// ("*.go *.mod", "jpg,png"). A pattern without glob characters is taken
+// as an extension, so "go" and ".go" both mean "*.go". An empty string
+// gives the zero filter.
+func ParseNameFilter(s string) (NameFilter, error) {
+	var f NameFilter
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	for _, pattern := range fields {
+		if !strings.ContainsAny(pattern, "*?[") {
+			pattern = "*." + strings.TrimPrefix(pattern, ".")
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return NameFilter{}, fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+		f.patterns = append(f.patterns, pattern)
+	}
+	return f, nil
+}
+
+// IsEmpty reports whether the filter lets everything through.
+func (f NameFilter) IsEmpty() bool {
+	return len(f.patterns) == 0
+}
+
+// String returns the patterns as accepted by ParseNameFilter.
+func (f NameFilter) String() string {
+	return strings.Join(f.patterns, " ")
+}
+
+// Match reports whether a file name matches any of the patterns.
+func (f NameFilter) Match(name string) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, pattern := range f.patterns {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the directories in files and the files that match,
+// keeping their order. files is filtered in place.
+func (f NameFilter) Apply(files []models.FileInfo) []models.FileInfo {
+	if f.IsEmpty() {
+		return files
+	}
+	kept := files[:0]
+	for _, file := range files {
+		if file.IsDir || f.Match(file.Name) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,90 @@
+package fileops
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/lawrab/warren/pkg/models"
+)
+
+func TestParseNameFilter(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"*.go", "*.go", false},
+		{"go", "*.go", false},
+		{".go", "*.go", false},
+		{"*.go, *.mod", "*.go *.mod", false},
+		{"jpg,png", "*.jpg *.png", false},
+		{"  test_*  ", "test_*", false},
+		{"[a-", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseNameFilter(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseNameFilter(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseNameFilter(%q) = %q, want %q", tt.input, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNameFilter_Match(t *testing.T) {
+	tests := []struct {
+		filter string
+		name   string
+		want   bool
+	}{
+		{"", "anything", true},
+		{"*.go", "main.go", true},
+		{"*.go", "main.go.orig", false},
+		{"go", "README.md", false},
+		{"jpg", "HOLIDAY.JPG", true},
+		{"*.go *.mod", "go.mod", true},
+		{"test_*", "test_main.py", true},
+		{"test_*", "main_test.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter+"/"+tt.name, func(t *testing.T) {
+			f, err := ParseNameFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.Match(tt.name); got != tt.want {
+				t.Errorf("Match(%q) with %q = %v, want %v", tt.name, tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNameFilter_Apply(t *testing.T) {
+	files := []models.FileInfo{
+		{Name: "cmd", IsDir: true},
+		{Name: "go.mod"},
+		{Name: "main.go"},
+		{Name: "README.md"},
+		{Name: "vendor.go", IsDir: true},
+	}
+
+	f, err := ParseNameFilter("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, file := range f.Apply(files) {
+		names = append(names, file.Name)
+	}
+	want := []string{"cmd", "main.go", "vendor.go"}
+	if !slices.Equal(names, want) {
+		t.Errorf("Apply() = %v, want %v (directories always kept)", names, want)
+	}
+}
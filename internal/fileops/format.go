@@ -0,0 +1,172 @@
+package fileops
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SizeFormat selects how file sizes are displayed.
+type SizeFormat int
+
+const (
+	// SizeBinary uses 1024-based units ("1.5 MB" for 1572864 bytes)
+	SizeBinary SizeFormat = iota
+	// SizeDecimal uses 1000-based SI units ("1.6 MB" for 1572864 bytes)
+	SizeDecimal
+	// SizeBytes shows the exact byte count ("1,572,864 B")
+	SizeBytes
+)
+
+// ParseSizeFormat converts a config value ("binary", "decimal", "bytes")
+// to a SizeFormat. An empty value means binary.
+func ParseSizeFormat(s string) (SizeFormat, error) {
+	switch strings.ToLower(s) {
+	case "", "binary":
+		return SizeBinary, nil
+	case "decimal":
+		return SizeDecimal, nil
+	case "bytes":
+		return SizeBytes, nil
+	default:
+		return SizeBinary, fmt.Errorf("unknown size format %q (use binary, decimal or bytes)", s)
+	}
+}
+
+// FormatSizeAs converts a file size in bytes to a string in the given format.
+func FormatSizeAs(bytes int64, format SizeFormat) string {
+	switch format {
+	case SizeDecimal:
+		return formatUnits(bytes, 1000, []string{"kB", "MB", "GB", "TB", "PB"})
+	case SizeBytes:
+		return groupThousands(bytes) + " B"
+	default:
+		return FormatSize(bytes)
+	}
+}
+
+// formatUnits scales bytes by unit and labels the result with the matching
+// entry of units, capped at the largest.
+func formatUnits(bytes int64, unit int64, units []string) string {
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := unit, 0
+	for n := bytes / unit; n >= unit && exp < len(units)-1; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %s", float64(bytes)/float64(div), units[exp])
+}
+
+// groupThousands formats n with comma separators ("1,572,864").
+func groupThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
+
+// FormatItemCount describes the number of entries in a directory
+// ("1 item", "12 items"), or "-" when the count is unknown.
+func FormatItemCount(n int) string {
+	switch {
+	case n < 0:
+		return "-"
+	case n == 1:
+		return "1 item"
+	default:
+		return fmt.Sprintf("%d items", n)
+	}
+}
+
+// FormatListingCount describes a listing for the status bar: its entries,
+// and how many hidden ones it leaves out if any ("42 items (3 hidden)").
+func FormatListingCount(n, hidden int) string {
+	if hidden > 0 {
+		return fmt.Sprintf("%s (%d hidden)", FormatItemCount(n), hidden)
+	}
+	return FormatItemCount(n)
+}
+
+// TimeFormat selects how modification times are displayed.
+type TimeFormat int
+
+const (
+	// TimeDefault shows "Jan 02 15:04" for this year and "Jan 02  2006"
+	// for older times, like ls -l
+	TimeDefault TimeFormat = iota
+	// TimeRelative shows the age ("5m ago", "2h ago", "3d ago") for the
+	// last week and falls back to TimeDefault for older times
+	TimeRelative
+	// TimeISO shows an ISO-8601 date and time ("2006-01-02 15:04")
+	TimeISO
+	// TimeLocale uses the C library's locale format. It needs the locale
+	// machinery of the UI toolkit, so FormatTime treats it as TimeDefault
+	// and callers format it themselves.
+	TimeLocale
+)
+
+// ParseTimeFormat converts a config value ("default", "relative", "iso",
+// "locale") to a TimeFormat. An empty value means default.
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	switch strings.ToLower(s) {
+	case "", "default":
+		return TimeDefault, nil
+	case "relative":
+		return TimeRelative, nil
+	case "iso":
+		return TimeISO, nil
+	case "locale":
+		return TimeLocale, nil
+	default:
+		return TimeDefault, fmt.Errorf("unknown date format %q (use default, relative, iso or locale)", s)
+	}
+}
+
+// FormatTime formats t for display, relative to now where the format needs it.
+func FormatTime(t, now time.Time, format TimeFormat) string {
+	switch format {
+	case TimeISO:
+		return t.Format("2006-01-02 15:04")
+	case TimeRelative:
+		if rel, ok := relativeTime(now.Sub(t)); ok {
+			return rel
+		}
+	}
+
+	if t.Year() == now.Year() {
+		return t.Format("Jan 02 15:04")
+	}
+	return t.Format("Jan 02  2006")
+}
+
+// relativeTime describes an age of up to a week. It reports false for
+// older times and for times more than a minute in the future.
+func relativeTime(age time.Duration) (string, bool) {
+	switch {
+	case age < -time.Minute || age >= 7*24*time.Hour:
+		return "", false
+	case age < time.Minute:
+		return "just now", true
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age/time.Minute)), true
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age/time.Hour)), true
+	default:
+		return fmt.Sprintf("%dd ago", int(age/(24*time.Hour))), true
+	}
+}
@@ -0,0 +1,156 @@
+package fileops
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSizeFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    SizeFormat
+		wantErr bool
+	}{
+		{"", SizeBinary, false},
+		{"binary", SizeBinary, false},
+		{"Decimal", SizeDecimal, false},
+		{"bytes", SizeBytes, false},
+		{"kibibytes", SizeBinary, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSizeFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSizeFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSizeFormat(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSizeAs(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytes    int64
+		format   SizeFormat
+		expected string
+	}{
+		{"binary matches FormatSize", 1572864, SizeBinary, "1.5 MB"},
+		{"decimal under 1kB", 999, SizeDecimal, "999 B"},
+		{"decimal exactly 1kB", 1000, SizeDecimal, "1.0 kB"},
+		{"decimal MB", 1572864, SizeDecimal, "1.6 MB"},
+		{"decimal GB", 2500000000, SizeDecimal, "2.5 GB"},
+		{"decimal caps at PB", 9000000000000000000, SizeDecimal, "9000.0 PB"},
+		{"bytes small", 12, SizeBytes, "12 B"},
+		{"bytes exact thousand", 1000, SizeBytes, "1,000 B"},
+		{"bytes grouped", 1572864, SizeBytes, "1,572,864 B"},
+		{"bytes zero", 0, SizeBytes, "0 B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatSizeAs(tt.bytes, tt.format)
+			if result != tt.expected {
+				t.Errorf("FormatSizeAs(%d, %v) = %q, want %q", tt.bytes, tt.format, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatItemCount(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{-1, "-"},
+		{0, "0 items"},
+		{1, "1 item"},
+		{12, "12 items"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatItemCount(tt.n); got != tt.expected {
+			t.Errorf("FormatItemCount(%d) = %q, want %q", tt.n, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatListingCount(t *testing.T) {
+	tests := []struct {
+		n, hidden int
+		expected  string
+	}{
+		{0, 0, "0 items"},
+		{1, 0, "1 item"},
+		{42, 3, "42 items (3 hidden)"},
+		{0, 2, "0 items (2 hidden)"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatListingCount(tt.n, tt.hidden); got != tt.expected {
+			t.Errorf("FormatListingCount(%d, %d) = %q, want %q", tt.n, tt.hidden, got, tt.expected)
+		}
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TimeFormat
+		wantErr bool
+	}{
+		{"", TimeDefault, false},
+		{"default", TimeDefault, false},
+		{"relative", TimeRelative, false},
+		{"ISO", TimeISO, false},
+		{"locale", TimeLocale, false},
+		{"rfc3339", TimeDefault, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimeFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTimeFormat(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		format   TimeFormat
+		expected string
+	}{
+		{"default this year", time.Date(2024, time.March, 5, 9, 30, 0, 0, time.UTC), TimeDefault, "Mar 05 09:30"},
+		{"default older year", time.Date(2021, time.March, 5, 9, 30, 0, 0, time.UTC), TimeDefault, "Mar 05  2021"},
+		{"iso", time.Date(2021, time.March, 5, 9, 30, 0, 0, time.UTC), TimeISO, "2021-03-05 09:30"},
+		{"relative just now", now.Add(-20 * time.Second), TimeRelative, "just now"},
+		{"relative slightly future", now.Add(30 * time.Second), TimeRelative, "just now"},
+		{"relative minutes", now.Add(-5 * time.Minute), TimeRelative, "5m ago"},
+		{"relative hours", now.Add(-2*time.Hour - 10*time.Minute), TimeRelative, "2h ago"},
+		{"relative days", now.Add(-3 * 24 * time.Hour), TimeRelative, "3d ago"},
+		{"relative older falls back", time.Date(2024, time.January, 2, 8, 0, 0, 0, time.UTC), TimeRelative, "Jan 02 08:00"},
+		{"relative future falls back", now.Add(2 * time.Hour), TimeRelative, "Jun 15 14:00"},
+		{"locale falls back to default", time.Date(2024, time.March, 5, 9, 30, 0, 0, time.UTC), TimeLocale, "Mar 05 09:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatTime(tt.t, now, tt.format)
+			if result != tt.expected {
+				t.Errorf("FormatTime(%v, %v) = %q, want %q", tt.t, tt.format, result, tt.expected)
+			}
+		})
+	}
+}
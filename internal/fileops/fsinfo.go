@@ -0,0 +1,76 @@
+package fileops
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// localFilesystems names common local filesystems by statfs magic number.
+// Copy-on-write and network filesystems are named by
+// copyOnWriteFilesystems and pollFilesystems.
+var localFilesystems = map[uint32]string{
+	0xef53:     "ext4",
+	0x58465342: "xfs",
+	0x01021994: "tmpfs",
+	0x4d44:     "vfat",
+	0x2011bab0: "exfat",
+	0x5346544e: "ntfs",
+	0x9660:     "iso9660",
+	0x15013346: "udf",
+}
+
+// filesystemTraits are the quirks of a filesystem that file operations
+// have to allow for.
+type filesystemTraits struct {
+	caseInsensitive bool // Never tells names apart by case (see CaseInsensitive)
+	restrictsNames  bool // Refuses some names Linux allows (see FixName)
+}
+
+// knownTraits holds the traits of filesystems, by name, that have any.
+var knownTraits = map[string]filesystemTraits{
+	"vfat":  {caseInsensitive: true, restrictsNames: true},
+	"exfat": {caseInsensitive: true, restrictsNames: true},
+}
+
+// FilesystemInfo describes the filesystem holding a path.
+type FilesystemInfo struct {
+	// Type is the filesystem's name, such as "ext4", or its statfs magic
+	// number in hex when Warren doesn't know it
+	Type string
+
+	// FreeBytes is the space available to unprivileged users
+	FreeBytes int64
+
+	// TotalBytes is the size of the filesystem
+	TotalBytes int64
+}
+
+// StatFilesystem returns the type and free space of the filesystem
+// holding path.
+func StatFilesystem(path string) (FilesystemInfo, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return FilesystemInfo{}, fmt.Errorf("failed to stat filesystem of %s: %w", path, err)
+	}
+	return FilesystemInfo{
+		Type:       filesystemName(uint32(st.Type)),
+		FreeBytes:  int64(st.Bavail) * int64(st.Bsize),
+		TotalBytes: int64(st.Blocks) * int64(st.Bsize),
+	}, nil
+}
+
+// traits returns what is known of the filesystem's quirks.
+func (fs FilesystemInfo) traits() filesystemTraits {
+	return knownTraits[fs.Type]
+}
+
+// filesystemName returns the name of the filesystem with the given statfs
+// magic number.
+func filesystemName(magic uint32) string {
+	for _, names := range []map[uint32]string{localFilesystems, copyOnWriteFilesystems, pollFilesystems} {
+		if name, ok := names[magic]; ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%x", magic)
+}
@@ -0,0 +1,41 @@
+package fileops
+
+import "testing"
+
+func TestStatFilesystem(t *testing.T) {
+	fs, err := StatFilesystem(t.TempDir())
+	if err != nil {
+		t.Fatalf("StatFilesystem() error = %v", err)
+	}
+	if fs.Type == "" || fs.TotalBytes <= 0 || fs.FreeBytes < 0 || fs.FreeBytes > fs.TotalBytes {
+		t.Errorf("StatFilesystem() = %+v, want a type and 0 <= free <= total", fs)
+	}
+
+	if _, err := StatFilesystem("/nonexistent/dir"); err == nil {
+		t.Error("StatFilesystem() of a missing path should fail")
+	}
+}
+
+func TestFilesystemName(t *testing.T) {
+	if got := filesystemName(0xef53); got != "ext4" {
+		t.Errorf("filesystemName(0xef53) = %q, want ext4", got)
+	}
+	if got := filesystemName(0x6969); got != "nfs" {
+		t.Errorf("filesystemName(0x6969) = %q, want nfs", got)
+	}
+	if got := filesystemName(0x1234); got != "0x1234" {
+		t.Errorf("filesystemName(0x1234) = %q, want 0x1234", got)
+	}
+}
+
+func TestFilesystemTraits(t *testing.T) {
+	for _, name := range []string{"vfat", "exfat"} {
+		traits := FilesystemInfo{Type: name}.traits()
+		if !traits.caseInsensitive || !traits.restrictsNames {
+			t.Errorf("traits of %s = %+v, want case-insensitive with restricted names", name, traits)
+		}
+	}
+	if traits := (FilesystemInfo{Type: "ext4"}).traits(); traits != (filesystemTraits{}) {
+		t.Errorf("traits of ext4 = %+v, want none", traits)
+	}
+}
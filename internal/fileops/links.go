@@ -0,0 +1,171 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+// HardLink creates hard links to sources in destination, under the same
+// names. A directory is recreated with every file in it hard linked, like
+// cp -al. Nothing is replaced: a name already taken in destination fails
+// the operation before anything is linked, as does a directory linked
+// into itself, which copying would refuse too. Hard links can't cross
+// filesystems.
+func HardLink(sources []string, destination string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpLink, sources, destination)
+	return defaultQueue.submit(op, func() { performHardLink(op, sources, destination, callback) }, callback)
+}
+
+// performHardLink executes HardLink; progress counts sources.
+func performHardLink(op *Operation, sources []string, destination string, callback ProgressCallback) {
+	op.SetStatus(StatusRunning)
+
+	fail := func(err error) {
+		if !op.IsCancelled() {
+			op.SetError(err)
+		}
+		if callback != nil {
+			callback(op)
+		}
+	}
+	dests := make([]string, len(sources))
+	for i, src := range sources {
+		dests[i] = filepath.Join(destination, filepath.Base(src))
+	}
+	if err := validateTransfer(sources, dests, false); err != nil {
+		fail(err)
+		return
+	}
+	for _, dst := range dests {
+		if _, err := os.Lstat(dst); err == nil {
+			fail(fmt.Errorf("%s already exists", dst))
+			return
+		}
+	}
+
+	for i, src := range sources {
+		if op.IsCancelled() {
+			break
+		}
+		op.UpdateProgress(int64(i), int64(len(sources)), src)
+
+		if err := linkTree(op, src, dests[i]); err != nil {
+			if errors.Is(err, syscall.EXDEV) {
+				err = fmt.Errorf("hard links can't cross filesystems")
+			}
+			fail(fmt.Errorf("failed to link %s: %w", src, err))
+			return
+		}
+		op.setResult(src, dests[i])
+	}
+
+	if !op.IsCancelled() {
+		op.UpdateProgress(int64(len(sources)), int64(len(sources)), "")
+		op.SetStatus(StatusCompleted)
+	}
+	if callback != nil {
+		callback(op)
+	}
+}
+
+// linkTree hard links src at dst, recreating directories and linking
+// everything in them. Symlinks are linked themselves, not what they point
+// to.
+func linkTree(op *Operation, src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if op.IsCancelled() {
+			return fmt.Errorf("operation cancelled")
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.Mkdir(target, info.Mode().Perm())
+		}
+		return os.Link(path, target)
+	})
+}
+
+// linkFile makes dst a hard link to target, replacing a file at dst as
+// copying over it would.
+func linkFile(op *Operation, target, dst string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		op.emit(EventConflict, dst)
+		if err := os.Remove(dst); err != nil {
+			return err
+		}
+	}
+	return os.Link(target, dst)
+}
+
+// hardLinks remembers the files with several hard links that a copy has
+// started copying, by device and inode, so their other links in the tree
+// become links to the copy. The zero value is ready to use.
+type hardLinks struct {
+	mu    sync.Mutex
+	files map[fileID]*linkedFile
+}
+
+// fileID identifies a file whatever name it is reached by.
+type fileID struct {
+	dev, ino uint64
+}
+
+// linkedFile is the first copy of a file with several hard links.
+type linkedFile struct {
+	dst  string
+	done chan struct{}
+	err  error
+}
+
+// claim returns the copy of the regular file described by info, and
+// whether the caller is the first to copy it, making dst the copy; the
+// first must call finish once done. It returns nil for a file with a
+// single link.
+func (h *hardLinks) claim(info fs.FileInfo, dst string) (*linkedFile, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || st.Nlink < 2 || !info.Mode().IsRegular() {
+		return nil, false
+	}
+	id := fileID{dev: uint64(st.Dev), ino: st.Ino} //nolint:unconvert // Dev is uint32 on some platforms
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if f, ok := h.files[id]; ok {
+		return f, false
+	}
+	if h.files == nil {
+		h.files = make(map[fileID]*linkedFile)
+	}
+	f := &linkedFile{dst: dst, done: make(chan struct{})}
+	h.files[id] = f
+	return f, true
+}
+
+// finish records how copying the file ended, letting its other links go
+// ahead.
+func (f *linkedFile) finish(err error) {
+	f.err = err
+	close(f.done)
+}
+
+// wait blocks until the file is copied, returning the copy's error.
+func (f *linkedFile) wait() error {
+	<-f.done
+	return f.err
+}
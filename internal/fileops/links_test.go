@@ -0,0 +1,142 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// isHardLink reports whether a and b are hard links to one file.
+func isHardLink(t *testing.T, a, b string) bool {
+	t.Helper()
+	ai, err := os.Lstat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bi, err := os.Lstat(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return os.SameFile(ai, bi)
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestHardLink(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "file.txt")
+	dir := filepath.Join(tmpDir, "dir")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		file:                                   "file",
+		filepath.Join(dir, "sub", "inner.txt"): "inner",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("sub", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(tmpDir, "dst")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	op := HardLink([]string{file, dir}, dst, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("status = %v (%v), want %v", op.GetStatus(), op.Error, StatusCompleted)
+	}
+	if !isHardLink(t, file, filepath.Join(dst, "file.txt")) {
+		t.Error("file.txt was not hard linked")
+	}
+	if !isHardLink(t, filepath.Join(dir, "sub", "inner.txt"), filepath.Join(dst, "dir", "sub", "inner.txt")) {
+		t.Error("dir/sub/inner.txt was not hard linked")
+	}
+	if target, err := os.Readlink(filepath.Join(dst, "dir", "link")); err != nil || target != "sub" {
+		t.Errorf("linked symlink = %q, %v; want sub", target, err)
+	}
+	results := op.GetResults()
+	if len(results) != 2 || results[0] != filepath.Join(dst, "file.txt") || results[1] != filepath.Join(dst, "dir") {
+		t.Errorf("GetResults() = %q, want the links", results)
+	}
+
+	// Names already taken fail the whole operation
+	op = HardLink([]string{file}, dst, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusFailed {
+		t.Errorf("HardLink() over an existing file: status = %v, want %v", op.GetStatus(), StatusFailed)
+	}
+
+	// A directory linked below itself is refused before anything is made
+	op = HardLink([]string{dir}, filepath.Join(dir, "sub"), nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusFailed {
+		t.Errorf("HardLink() into itself: status = %v, want %v", op.GetStatus(), StatusFailed)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "sub", "dir")); !os.IsNotExist(err) {
+		t.Errorf("HardLink() into itself created %s", filepath.Join(dir, "sub", "dir"))
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestCopyPreservesHardLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	original := filepath.Join(src, "a", "original.txt")
+	if err := os.WriteFile(original, []byte("shared"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"link1.txt", "link2.txt"} {
+		if err := os.Link(original, filepath.Join(src, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check := func(t *testing.T, op *Operation, root string) {
+		t.Helper()
+		waitForOperation(t, op, 5*time.Second)
+		if op.GetStatus() != StatusCompleted {
+			t.Fatalf("status = %v (%v), want %v", op.GetStatus(), op.Error, StatusCompleted)
+		}
+		copied := filepath.Join(root, "a", "original.txt")
+		verifyFileExists(t, copied, "shared")
+		for _, name := range []string{"link1.txt", "link2.txt"} {
+			if !isHardLink(t, copied, filepath.Join(root, name)) {
+				t.Errorf("%s is not a hard link to the copy of original.txt", name)
+			}
+		}
+		if isHardLink(t, original, copied) {
+			t.Error("the copy is a hard link to the source")
+		}
+		info, err := os.Lstat(copied)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := info.Sys().(*syscall.Stat_t).Nlink; n != 3 {
+			t.Errorf("copy has %d links, want 3", n)
+		}
+		if _, done, total, _ := op.GetProgress(); done != total {
+			t.Errorf("progress = %d of %d bytes, want all", done, total)
+		}
+	}
+
+	t.Run("Copy", func(t *testing.T) {
+		dst := filepath.Join(tmpDir, "copy")
+		check(t, Copy(src, dst, nil), dst)
+	})
+	t.Run("CopyMultiple", func(t *testing.T) {
+		dst := filepath.Join(tmpDir, "multiple")
+		if err := os.Mkdir(dst, 0755); err != nil {
+			t.Fatal(err)
+		}
+		check(t, CopyMultiple([]string{src}, dst, nil), filepath.Join(dst, "src"))
+	})
+}
@@ -1,11 +1,16 @@
 package fileops
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 
 	"github.com/lawrab/warren/pkg/models"
 )
@@ -13,6 +18,75 @@ import (
 // ListDirectory reads the contents of a directory and returns a list of FileInfo.
 // Hidden files are included based on the showHidden parameter.
 func ListDirectory(path string, showHidden bool) ([]models.FileInfo, error) {
+	return ListDirectoryContext(context.Background(), path, showHidden)
+}
+
+// ListDirectoryContext is ListDirectory for listings that may be abandoned,
+// such as slow network directories the user navigates away from.
+// Cancelling ctx stops reading entries and returns ctx's error.
+func ListDirectoryContext(ctx context.Context, path string, showHidden bool) ([]models.FileInfo, error) {
+	files, err := ReadDirectory(ctx, path, showHidden)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if files[i].IsDir {
+			files[i].ItemCount = CountEntries(files[i].Path)
+		}
+	}
+	return files, nil
+}
+
+// ReadDirectory is ListDirectoryContext without counting the entries of
+// subdirectories, which takes a directory read each: their ItemCount is
+// left at -1, for an ItemCounts to fill in later.
+func ReadDirectory(ctx context.Context, path string, showHidden bool) ([]models.FileInfo, error) {
+	files, err := ReadNames(ctx, path, showHidden)
+	if err != nil {
+		return nil, err
+	}
+	return StatListed(ctx, files)
+}
+
+// StatListed is StatFiles for a listing not shown yet: entries removed
+// since ReadNames listed them are dropped instead of kept, and files is
+// reused for the result.
+func StatListed(ctx context.Context, files []models.FileInfo) ([]models.FileInfo, error) {
+	found, err := statEntries(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+	// Skip files we can't stat (rare, but possible)
+	kept := files[:0]
+	for i := range files {
+		if found[i] {
+			kept = append(kept, files[i])
+		}
+	}
+	return kept, nil
+}
+
+// DropHidden removes the hidden entries of files, reusing files for the
+// result, and returns how many it removed. Listing with them and dropping
+// them after tells how many a directory hides, before any are stat'ed.
+func DropHidden(files []models.FileInfo) ([]models.FileInfo, int) {
+	kept := files[:0]
+	for _, file := range files {
+		if !file.IsHidden {
+			kept = append(kept, file)
+		}
+	}
+	return kept, len(files) - len(kept)
+}
+
+// ReadNames is ReadDirectory without stat'ing the entries, which is most
+// of the work in a large directory: only what the directory itself
+// records is filled in, the name and the type. Every entry is Pending
+// until StatFiles reads the rest.
+func ReadNames(ctx context.Context, path string, showHidden bool) ([]models.FileInfo, error) {
 	if path == "" {
 		return nil, fmt.Errorf("path cannot be empty")
 	}
@@ -35,47 +109,108 @@ func ListDirectory(path string, showHidden bool) ([]models.FileInfo, error) {
 		return nil, fmt.Errorf("cannot read directory: %w", err)
 	}
 
-	// Convert to FileInfo
 	files := make([]models.FileInfo, 0, len(entries))
 	for _, entry := range entries {
-		info, err := entry.Info()
-		if err != nil {
-			// Skip files we can't stat (rare, but possible)
-			continue
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-
 		name := entry.Name()
 		isHidden := IsHidden(name)
 
-		// Skip hidden files if requested
+		// Skip hidden files if requested, before anything reads them
 		if isHidden && !showHidden {
 			continue
 		}
 
-		fullPath := filepath.Join(absPath, name)
-		fileInfo := models.FileInfo{
+		file := models.FileInfo{
 			Name:        name,
-			Path:        fullPath,
-			Size:        info.Size(),
-			IsDir:       info.IsDir(),
-			Permissions: info.Mode(),
-			ModTime:     info.ModTime(),
+			Path:        filepath.Join(absPath, name),
+			IsDir:       entry.IsDir(),
+			IsSymlink:   entry.Type()&os.ModeSymlink != 0,
+			Permissions: entry.Type(),
 			IsHidden:    isHidden,
+			Pending:     true,
+		}
+		if file.IsDir {
+			file.ItemCount = -1
 		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// statWorkers is how many entries StatFiles reads at a time, which mostly
+// pays off on network filesystems, where each stat is a round trip.
+const statWorkers = 8
+
+// statChunk is how many consecutive entries a StatFiles worker takes on at
+// once.
+const statChunk = 64
+
+// StatFiles fills in the size, modification time, permissions and link
+// details of the Pending entries of files, as ReadDirectory would have.
+// Entries removed since they were listed keep only their name and type.
+// It stops with ctx's error as soon as ctx is done.
+func StatFiles(ctx context.Context, files []models.FileInfo) error {
+	_, err := statEntries(ctx, files)
+	return err
+}
 
-		// Check for symlinks
-		if info.Mode()&os.ModeSymlink != 0 {
-			fileInfo.IsSymlink = true
-			target, err := os.Readlink(fullPath)
-			if err == nil {
-				fileInfo.SymlinkTarget = target
+// statEntries is StatFiles, also reporting which entries are still there.
+func statEntries(ctx context.Context, files []models.FileInfo) ([]bool, error) {
+	found := make([]bool, len(files))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(statWorkers, (len(files)+statChunk-1)/statChunk) {
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				start := int(next.Add(statChunk)) - statChunk
+				if start >= len(files) {
+					return
+				}
+				for i := start; i < min(start+statChunk, len(files)); i++ {
+					found[i] = !files[i].Pending || statFile(&files[i])
+				}
 			}
-		}
+		})
+	}
+	wg.Wait()
+	return found, ctx.Err()
+}
 
-		files = append(files, fileInfo)
+// statFile fills in file, listed by ReadNames, and reports whether it
+// still exists. It is no longer Pending either way.
+func statFile(file *models.FileInfo) bool {
+	file.Pending = false
+	info, err := os.Lstat(file.Path)
+	if err != nil {
+		return false
 	}
+	fillInfo(file, info)
+	return true
+}
 
-	return files, nil
+// fillInfo sets what file's Lstat, info, tells about it, and whether it
+// can be read and where it links to.
+func fillInfo(file *models.FileInfo, info os.FileInfo) {
+	file.Size = info.Size()
+	file.IsDir = info.IsDir()
+	file.Permissions = info.Mode()
+	file.ModTime = info.ModTime()
+	file.IsUnreadable = isUnreadable(file.Path, file.IsDir)
+
+	// Check for symlinks
+	if info.Mode()&os.ModeSymlink != 0 {
+		file.IsSymlink = true
+		target, err := os.Readlink(file.Path)
+		if err == nil {
+			file.SymlinkTarget = target
+		}
+		if _, err := os.Stat(file.Path); err != nil {
+			file.IsBrokenSymlink = true
+		}
+	}
 }
 
 // GetFileInfo returns detailed information about a single file or directory.
@@ -101,30 +236,60 @@ func GetFileInfo(path string) (models.FileInfo, error) {
 	}
 
 	fileInfo := models.FileInfo{
-		Name:        filepath.Base(path),
-		Path:        absPath,
-		Size:        info.Size(),
-		IsDir:       info.IsDir(),
-		Permissions: info.Mode(),
-		ModTime:     info.ModTime(),
-		IsHidden:    IsHidden(filepath.Base(path)),
+		Name:     filepath.Base(path),
+		Path:     absPath,
+		IsHidden: IsHidden(filepath.Base(path)),
 	}
-
-	// Check for symlinks
-	if info.Mode()&os.ModeSymlink != 0 {
-		fileInfo.IsSymlink = true
-		target, err := os.Readlink(path)
-		if err == nil {
-			fileInfo.SymlinkTarget = target
-		}
+	fillInfo(&fileInfo, info)
+	if fileInfo.IsDir {
+		fileInfo.ItemCount = CountEntries(path)
 	}
 
 	return fileInfo, nil
 }
 
+// access(2) mode bits; not exported by syscall.
+const (
+	accessExecute = 0x1
+	accessWrite   = 0x2
+	accessRead    = 0x4
+)
+
+// isUnreadable reports whether the current user is denied reading path,
+// or listing and entering it if it is a directory. Symlinks are checked
+// through to their target; broken ones are not unreadable.
+func isUnreadable(path string, isDir bool) bool {
+	mode := uint32(accessRead)
+	if isDir {
+		mode |= accessExecute
+	}
+	return errors.Is(syscall.Access(path, mode), syscall.EACCES)
+}
+
+// CountEntries returns the number of entries in a directory, including
+// hidden ones, or -1 if it cannot be read.
+func CountEntries(path string) int {
+	dir, err := os.Open(path)
+	if err != nil {
+		return -1
+	}
+	defer dir.Close()
+
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return -1
+	}
+	return len(names)
+}
+
 // SortFiles sorts a list of files according to the specified criteria.
-// Directories are always listed before files.
+// Directories are always listed before files. Sorting by capture time
+// reads the EXIF data of photos that don't have a TakenTime yet.
 func SortFiles(files []models.FileInfo, sortBy models.SortBy, order models.SortOrder) {
+	if sortBy == models.SortByTaken {
+		ReadTakenTimes(files)
+	}
+
 	sort.Slice(files, func(i, j int) bool {
 		// Always sort directories before files
 		if files[i].IsDir != files[j].IsDir {
@@ -139,6 +304,8 @@ func SortFiles(files []models.FileInfo, sortBy models.SortBy, order models.SortO
 			less = files[i].Size < files[j].Size
 		case models.SortByModTime:
 			less = files[i].ModTime.Before(files[j].ModTime)
+		case models.SortByTaken:
+			less = takenOrModTime(files[i]).Before(takenOrModTime(files[j]))
 		case models.SortByExtension:
 			extI := filepath.Ext(files[i].Name)
 			extJ := filepath.Ext(files[j].Name)
@@ -158,6 +325,15 @@ func SortFiles(files []models.FileInfo, sortBy models.SortBy, order models.SortO
 	})
 }
 
+// GroupHidden moves the hidden entries of sorted files after the others,
+// keeping the order within each group, so dotfiles end the listing rather
+// than being interleaved with the rest.
+func GroupHidden(files []models.FileInfo) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return !files[i].IsHidden && files[j].IsHidden
+	})
+}
+
 // IsHidden returns true if a filename should be considered hidden.
 // On Unix systems, this means the name starts with a dot.
 func IsHidden(name string) bool {
@@ -1,6 +1,9 @@
 package fileops
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -157,6 +160,27 @@ func TestSortFiles(t *testing.T) {
 	})
 }
 
+func TestGroupHidden(t *testing.T) {
+	files := []models.FileInfo{
+		{Name: ".config", IsDir: true, IsHidden: true},
+		{Name: "docs", IsDir: true},
+		{Name: ".bashrc", IsHidden: true},
+		{Name: "notes.txt"},
+		{Name: ".profile", IsHidden: true},
+	}
+	SortFiles(files, models.SortByName, models.SortAscending)
+	GroupHidden(files)
+
+	want := []string{"docs", "notes.txt", ".config", ".bashrc", ".profile"}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Name)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("GroupHidden() order = %q, want %q", got, want)
+	}
+}
+
 // setupTestDirectory creates a test directory structure for ListDirectory tests.
 // Returns tmpDir and file1 path for verification.
 func setupTestDirectory(t *testing.T) (string, string) {
@@ -233,6 +257,56 @@ func TestListDirectory(t *testing.T) {
 		}
 	})
 
+	t.Run("directory item counts", func(t *testing.T) {
+		for _, name := range []string{"a", ".b"} {
+			if err := os.WriteFile(filepath.Join(tmpDir, "subdir", name), nil, 0600); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+
+		files, err := ListDirectory(tmpDir, false)
+		if err != nil {
+			t.Fatalf("ListDirectory failed: %v", err)
+		}
+		for _, f := range files {
+			switch {
+			case f.Name == "subdir" && f.ItemCount != 2:
+				t.Errorf("subdir ItemCount = %d, want 2 (hidden entries count)", f.ItemCount)
+			case f.Name != "subdir" && f.ItemCount != 0:
+				t.Errorf("%s ItemCount = %d, want 0 for files", f.Name, f.ItemCount)
+			}
+		}
+	})
+
+	t.Run("unreadable entries", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can read everything")
+		}
+		locked := filepath.Join(tmpDir, "locked")
+		if err := os.Mkdir(locked, 0o300); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		defer os.Remove(locked)
+
+		files, err := ListDirectory(tmpDir, false)
+		if err != nil {
+			t.Fatalf("ListDirectory failed: %v", err)
+		}
+		for _, f := range files {
+			if want := f.Name == "locked"; f.IsUnreadable != want {
+				t.Errorf("%s IsUnreadable = %v, want %v", f.Name, f.IsUnreadable, want)
+			}
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := ListDirectoryContext(ctx, tmpDir, false); !errors.Is(err, context.Canceled) {
+			t.Errorf("ListDirectoryContext error = %v, want %v", err, context.Canceled)
+		}
+	})
+
 	t.Run("list with hidden files", func(t *testing.T) {
 		files, err := ListDirectory(tmpDir, true)
 		if err != nil {
@@ -409,5 +483,158 @@ func TestGetFileInfo(t *testing.T) {
 		if info.SymlinkTarget == "" {
 			t.Error("Symlink target should be set")
 		}
+
+		if info.IsBrokenSymlink {
+			t.Error("Symlink to an existing file should not be broken")
+		}
 	})
+
+	t.Run("broken symlink", func(t *testing.T) {
+		linkPath := filepath.Join(tmpDir, "dangling.txt")
+		if err := os.Symlink(filepath.Join(tmpDir, "missing.txt"), linkPath); err != nil {
+			t.Skip("Skipping symlink test (requires symlink support)")
+		}
+
+		info, err := GetFileInfo(linkPath)
+		if err != nil {
+			t.Fatalf("GetFileInfo failed: %v", err)
+		}
+		if !info.IsBrokenSymlink {
+			t.Error("Symlink to a missing file should be marked as IsBrokenSymlink")
+		}
+
+		files, err := ListDirectory(tmpDir, false)
+		if err != nil {
+			t.Fatalf("ListDirectory failed: %v", err)
+		}
+		for _, f := range files {
+			if f.Name == "dangling.txt" && !f.IsBrokenSymlink {
+				t.Error("ListDirectory should mark dangling.txt as IsBrokenSymlink")
+			}
+		}
+	})
+}
+
+func TestCountEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	if got := CountEntries(tmpDir); got != 0 {
+		t.Errorf("CountEntries(empty) = %d, want 0", got)
+	}
+
+	for _, name := range []string{"one", "two", ".three"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), nil, 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	if got := CountEntries(tmpDir); got != 3 {
+		t.Errorf("CountEntries = %d, want 3", got)
+	}
+
+	if got := CountEntries(filepath.Join(tmpDir, "missing")); got != -1 {
+		t.Errorf("CountEntries(missing) = %d, want -1", got)
+	}
+}
+
+func TestReadNames(t *testing.T) {
+	tmpDir, _ := setupTestDirectory(t)
+	if err := os.Symlink("file1.txt", filepath.Join(tmpDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := ReadNames(context.Background(), tmpDir, false)
+	if err != nil {
+		t.Fatalf("ReadNames failed: %v", err)
+	}
+	want, err := ReadDirectory(context.Background(), tmpDir, false)
+	if err != nil {
+		t.Fatalf("ReadDirectory failed: %v", err)
+	}
+	if len(names) != len(want) {
+		t.Fatalf("ReadNames listed %d entries, ReadDirectory %d", len(names), len(want))
+	}
+	for i, f := range names {
+		if !f.Pending || f.Size != 0 || !f.ModTime.IsZero() {
+			t.Errorf("%s: Pending = %v, Size = %d, ModTime = %v; want only the name and type", f.Name, f.Pending, f.Size, f.ModTime)
+		}
+		if f.Name != want[i].Name || f.IsDir != want[i].IsDir || f.IsSymlink != want[i].IsSymlink {
+			t.Errorf("%s: IsDir = %v, IsSymlink = %v; ReadDirectory has %v, %v", f.Name, f.IsDir, f.IsSymlink, want[i].IsDir, want[i].IsSymlink)
+		}
+	}
+
+	// Filling them in reads what ReadDirectory does
+	if err := StatFiles(context.Background(), names); err != nil {
+		t.Fatalf("StatFiles failed: %v", err)
+	}
+	for i, f := range names {
+		if f != want[i] {
+			t.Errorf("StatFiles filled in %+v, want %+v", f, want[i])
+		}
+	}
+}
+
+func TestDropHidden(t *testing.T) {
+	tmpDir, _ := setupTestDirectory(t)
+
+	all, err := ReadNames(context.Background(), tmpDir, true)
+	if err != nil {
+		t.Fatalf("ReadNames failed: %v", err)
+	}
+	files, hidden := DropHidden(all)
+	if hidden != 1 {
+		t.Errorf("DropHidden() dropped %d entries, want 1 (.hidden)", hidden)
+	}
+	files, err = StatListed(context.Background(), files)
+	if err != nil {
+		t.Fatalf("StatListed failed: %v", err)
+	}
+	want, err := ReadDirectory(context.Background(), tmpDir, false)
+	if err != nil {
+		t.Fatalf("ReadDirectory failed: %v", err)
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got %d entries, ReadDirectory lists %d", len(files), len(want))
+	}
+	for i := range files {
+		if files[i] != want[i] {
+			t.Errorf("got %+v, want %+v", files[i], want[i])
+		}
+	}
+}
+
+func TestStatFilesRemoved(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := range 3 * statChunk {
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("f%03d", i)), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := ReadNames(context.Background(), tmpDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone := files[statChunk+1]
+	if err := os.Remove(gone.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := StatFiles(context.Background(), files); err != nil {
+		t.Fatalf("StatFiles failed: %v", err)
+	}
+	for _, f := range files {
+		switch {
+		case f.Pending:
+			t.Errorf("%s still Pending", f.Name)
+		case f.Path == gone.Path && f.Size != 0:
+			t.Errorf("removed %s has Size %d", f.Name, f.Size)
+		case f.Path != gone.Path && f.Size != 1:
+			t.Errorf("%s has Size %d, want 1", f.Name, f.Size)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	files, _ = ReadNames(context.Background(), tmpDir, true)
+	if err := StatFiles(ctx, files); !errors.Is(err, context.Canceled) {
+		t.Errorf("StatFiles(cancelled) = %v, want %v", err, context.Canceled)
+	}
 }
@@ -0,0 +1,78 @@
+package fileops
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the longest file name, in bytes, that Linux
+// filesystems accept (NAME_MAX).
+const MaxNameLength = 255
+
+// DisplayName returns name made safe to show on one line: control
+// characters are escaped as \n, \t or \xNN, bytes that aren't valid UTF-8
+// as \xNN, and the bidirectional overrides that can make a name read as
+// another as \u202E and the like. A name that needs none of this is
+// returned as it is. The result is plain text; markup still needs
+// escaping.
+func DisplayName(name string) string {
+	if !needsEscaping(name) {
+		return name
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, name[i])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x80 && unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case unicode.IsControl(r) || isBidiControl(r):
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteString(name[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// needsEscaping reports whether DisplayName has to change name.
+func needsEscaping(name string) bool {
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		if (r == utf8.RuneError && size == 1) || unicode.IsControl(r) || isBidiControl(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// isBidiControl reports whether r is one of the embedding, override or
+// isolate characters that reorder the text around them.
+func isBidiControl(r rune) bool {
+	return (r >= 0x202A && r <= 0x202E) || (r >= 0x2066 && r <= 0x2069)
+}
+
+// truncateName shortens name to at most n bytes, cutting at a character
+// boundary so valid UTF-8 stays valid.
+func truncateName(name string, n int) string {
+	if len(name) <= n {
+		return name
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return name[:cut]
+}
@@ -0,0 +1,201 @@
+package fileops
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"notes.txt", "notes.txt"},
+		{"café ☕.txt", "café ☕.txt"},
+		{"two\nlines", `two\nlines`},
+		{"tab\there\r", `tab\there\r`},
+		{"bell\x07\x7f", `bell\x07\x7f`},
+		{"latin1-\xe9t\xe9", `latin1-\xe9t\xe9`},
+		{"cut-\xe2\x98", `cut-\xe2\x98`},
+		{"invoice\u202efdp.exe", `invoice\u202Efdp.exe`},
+		{"isolate\u2066x\u2069", `isolate\u2066x\u2069`},
+		{"c1\u0085", `c1\u0085`},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DisplayName(tt.name); got != tt.want {
+			t.Errorf("DisplayName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateName(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"abcdef", 3, "abc"},
+		{"aé", 2, "a"}, // Doesn't split é
+		{"ééé", 5, "éé"},
+	}
+
+	for _, tt := range tests {
+		got := truncateName(tt.name, tt.n)
+		if got != tt.want {
+			t.Errorf("truncateName(%q, %d) = %q, want %q", tt.name, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateName(%q, %d) = %q, not valid UTF-8", tt.name, tt.n, got)
+		}
+	}
+}
+
+// oddNames are file names a filesystem accepts that are easy to mishandle.
+var oddNames = []string{
+	"two\nlines.txt",
+	"latin1-\xe9t\xe9.txt",
+	" leading and trailing spaces ",
+	"-starts-with-dash",
+	strings.Repeat("n", MaxNameLength),
+	strings.Repeat("é", MaxNameLength/2), // 254 bytes
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestOperationsOnOddNames(t *testing.T) {
+	for _, name := range oddNames {
+		t.Run(truncateName(DisplayName(name), 20), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+			src := filepath.Join(tmpDir, "src")
+			dst := filepath.Join(tmpDir, "dst")
+			for _, dir := range []string{src, dst} {
+				if err := os.Mkdir(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			file := filepath.Join(src, name)
+			if err := os.WriteFile(file, []byte("odd"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			op := Copy(file, filepath.Join(dst, name), nil)
+			waitForOperation(t, op, 5*time.Second)
+			if op.GetStatus() != StatusCompleted {
+				t.Fatalf("copy: status = %v (%v)", op.GetStatus(), op.Error)
+			}
+			verifyFileExists(t, filepath.Join(dst, name), "odd")
+
+			op = CopyMultiple([]string{src}, dst, nil)
+			waitForOperation(t, op, 5*time.Second)
+			if op.GetStatus() != StatusCompleted {
+				t.Fatalf("copy tree: status = %v (%v)", op.GetStatus(), op.Error)
+			}
+			verifyFileExists(t, filepath.Join(dst, "src", name), "odd")
+
+			renamed := filepath.Join(src, "renamed")
+			op = Rename(file, renamed, nil)
+			waitForOperation(t, op, 5*time.Second)
+			if op.GetStatus() != StatusCompleted {
+				t.Fatalf("rename: status = %v (%v)", op.GetStatus(), op.Error)
+			}
+			op = Move(renamed, file, nil)
+			waitForOperation(t, op, 5*time.Second)
+			if op.GetStatus() != StatusCompleted {
+				t.Fatalf("move: status = %v (%v)", op.GetStatus(), op.Error)
+			}
+
+			op = Trash(file, nil)
+			waitForOperation(t, op, 5*time.Second)
+			if op.GetStatus() != StatusCompleted {
+				t.Fatalf("trash: status = %v (%v)", op.GetStatus(), op.Error)
+			}
+			results := op.GetResults()
+			if len(results) != 1 {
+				t.Fatalf("trash: GetResults() = %q, want one file", results)
+			}
+			verifyFileExists(t, results[0], "odd")
+			if _, err := os.Stat(TrashInfoPath(results[0])); err != nil {
+				t.Errorf("trash: no trashinfo for %q: %v", results[0], err)
+			}
+		})
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTrashLongNameCollision(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+
+	name := strings.Repeat("x", MaxNameLength)
+	var paths []string
+	for _, dir := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(tmpDir, dir, name)
+		if err := os.WriteFile(path, []byte(dir), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	op := TrashMultiple(paths, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("status = %v (%v), want %v", op.GetStatus(), op.Error, StatusCompleted)
+	}
+	results := op.GetResults()
+	if len(results) != 2 || results[0] == results[1] {
+		t.Fatalf("GetResults() = %q, want two distinct names", results)
+	}
+	for i, dir := range []string{"a", "b"} {
+		verifyFileExists(t, results[i], dir)
+		if n := len(filepath.Base(TrashInfoPath(results[i]))); n > MaxNameLength {
+			t.Errorf("trashinfo name is %d bytes, over %d", n, MaxNameLength)
+		}
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestCopyDeepTree(t *testing.T) {
+	// Deeper than PATH_MAX lets a path be, so it has to be built a level
+	// at a time from inside the last
+	tmpDir := t.TempDir()
+	root := filepath.Join(tmpDir, "deep")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	segment := strings.Repeat("d", 200)
+	t.Chdir(root)
+	for range 25 {
+		if err := os.Mkdir(segment, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chdir(segment); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(tmpDir, "dst")
+	if err := os.Mkdir(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	op := CopyMultiple([]string{root}, dst, nil)
+	waitForOperation(t, op, 10*time.Second)
+	if op.GetStatus() != StatusFailed {
+		t.Fatalf("status = %v, want %v", op.GetStatus(), StatusFailed)
+	}
+	if !errors.Is(op.Error, syscall.ENAMETOOLONG) {
+		t.Errorf("error = %v, want the path being too long", op.Error)
+	}
+}
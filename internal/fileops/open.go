@@ -3,17 +3,53 @@ package fileops
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 )
 
-// OpenFile opens a file with the default application using xdg-open (Linux),
-// open (macOS), or start (Windows).
-func OpenFile(path string) error {
+// OpenCommand returns the command line that opens path: the matching
+// command in openers (see OpenerCommand), or else the default application
+// through xdg-open (Linux), open (macOS), or start (Windows).
+func OpenCommand(path string, openers map[string]string) ([]string, error) {
 	if path == "" {
-		return fmt.Errorf("path cannot be empty")
+		return nil, fmt.Errorf("path cannot be empty")
 	}
 
-	var cmd *exec.Cmd
+	argv, err := OpenerCommand(openers, path)
+	if err != nil || argv != nil {
+		return argv, err
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		return []string{"xdg-open", path}, nil
+	case "darwin":
+		return []string{"open", path}, nil
+	case "windows":
+		return []string{"cmd", "/c", "start", path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	}
+}
+
+// OpenFile opens a file with its opener or the default application (see
+// OpenCommand). An opener runs in the file's directory.
+func OpenFile(path string, openers map[string]string) error {
+	if path != "" {
+		argv, err := OpenerCommand(openers, path)
+		if err != nil {
+			return err
+		}
+		if argv != nil {
+			return Launch(filepath.Dir(path), argv...)
+		}
+	}
+
+	argv, err := OpenCommand(path, nil)
+	if err != nil {
+		return err
+	}
 
 	// Security note: We're intentionally passing user-controlled file paths to system commands.
 	// This is safe because:
@@ -23,16 +59,7 @@ func OpenFile(path string) error {
 	// 4. This is the standard way to open files with default applications
 	//
 	// #nosec G204 -- Subprocess launched with file path - intentional for file opening
-	switch runtime.GOOS {
-	case "linux":
-		cmd = exec.Command("xdg-open", path)
-	case "darwin":
-		cmd = exec.Command("open", path)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", path)
-	default:
-		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
-	}
+	cmd := exec.Command(argv[0], argv[1:]...)
 
 	// Run the command without waiting for it to complete
 	// We don't want to block on the opened application
@@ -49,6 +76,31 @@ func OpenFile(path string) error {
 	return nil
 }
 
+// DefaultAppID returns the desktop ID (e.g. "org.gnome.Evince") of the
+// application that opens path by default, as reported by xdg-mime, or ""
+// if it cannot be determined.
+func DefaultAppID(path string) string {
+	mime := queryFiletype(path)
+	if mime == "" {
+		return ""
+	}
+
+	// #nosec G204 -- Fixed program, MIME type passed as an argument
+	desktop, err := exec.Command("xdg-mime", "query", "default", mime).Output()
+	if err != nil {
+		return ""
+	}
+	return desktopID(string(desktop))
+}
+
+// desktopID turns xdg-mime's default application output
+// ("org.gnome.Evince.desktop\n", possibly several ";"-separated entries)
+// into the first desktop ID.
+func desktopID(output string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(output), ";")
+	return strings.TrimSuffix(strings.TrimSpace(first), ".desktop")
+}
+
 // CanOpen checks if a file can potentially be opened.
 // This does a basic check but doesn't guarantee the file can actually be opened.
 func CanOpen(path string) (bool, error) {
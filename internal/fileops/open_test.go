@@ -0,0 +1,41 @@
+package fileops
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestOpenCommand(t *testing.T) {
+	if _, err := OpenCommand("", nil); err == nil {
+		t.Error("OpenCommand(\"\") should error")
+	}
+
+	if runtime.GOOS != "linux" {
+		t.Skip("command line checked on Linux only")
+	}
+	argv, err := OpenCommand("/tmp/a b.txt", nil)
+	if err != nil {
+		t.Fatalf("OpenCommand() error = %v", err)
+	}
+	if len(argv) != 2 || argv[0] != "xdg-open" || argv[1] != "/tmp/a b.txt" {
+		t.Errorf("OpenCommand() = %q, want [xdg-open /tmp/a b.txt]", argv)
+	}
+}
+
+func TestDesktopID(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{"org.gnome.Evince.desktop\n", "org.gnome.Evince"},
+		{"mpv.desktop;vlc.desktop\n", "mpv"},
+		{"", ""},
+		{"\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := desktopID(tt.output); got != tt.expected {
+			t.Errorf("desktopID(%q) = %q, want %q", tt.output, got, tt.expected)
+		}
+	}
+}
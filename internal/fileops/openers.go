@@ -0,0 +1,131 @@
+package fileops
+
+import (
+	"fmt"
+	"mime"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/lawrab/warren/internal/runner"
+)
+
+// fileToken in an opener command is replaced by the path of the file.
+const fileToken = "{file}"
+
+// CheckOpener reports whether pattern and command make a usable opener:
+// pattern a valid glob and command a non-empty command line.
+func CheckOpener(pattern, command string) error {
+	if pattern == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("bad pattern %q: %w", pattern, err)
+	}
+	argv, err := runner.Split(command)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pattern, err)
+	}
+	if len(argv) == 0 {
+		return fmt.Errorf("%s: empty command", pattern)
+	}
+	return nil
+}
+
+// OpenerCommand returns the command line of the opener for path, or nil if
+// none matches. openers maps patterns to commands: a pattern with a "/" is
+// matched against the file's MIME type ("image/*"), any other against its
+// name ("*.pdf"), ignoring case. Name patterns win over MIME patterns, and
+// of several matches the longest pattern wins. {file} in the command is
+// replaced by path, which is appended if the command has no {file}.
+func OpenerCommand(openers map[string]string, path string) ([]string, error) {
+	pattern := matchOpener(openers, path)
+	if pattern == "" {
+		return nil, nil
+	}
+	argv, err := runner.Split(openers[pattern])
+	if err != nil {
+		return nil, fmt.Errorf("opener for %s: %w", pattern, err)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("opener for %s: empty command", pattern)
+	}
+
+	substituted := false
+	for i, arg := range argv {
+		if strings.Contains(arg, fileToken) {
+			argv[i] = strings.ReplaceAll(arg, fileToken, path)
+			substituted = true
+		}
+	}
+	if !substituted {
+		argv = append(argv, path)
+	}
+	return argv, nil
+}
+
+// matchOpener returns the pattern in openers that picks the command for
+// path (see OpenerCommand), or "".
+func matchOpener(openers map[string]string, path string) string {
+	if len(openers) == 0 {
+		return ""
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	var mimeType string
+	best, bestByName := "", false
+	for pattern := range openers {
+		byName := !strings.Contains(pattern, "/")
+		var subject string
+		if byName {
+			subject = name
+		} else {
+			if mimeType == "" {
+				mimeType = MimeType(path)
+			}
+			subject = mimeType
+		}
+		if ok, err := filepath.Match(strings.ToLower(pattern), subject); err != nil || !ok {
+			continue
+		}
+		if best == "" || betterOpener(pattern, byName, best, bestByName) {
+			best, bestByName = pattern, byName
+		}
+	}
+	return best
+}
+
+// betterOpener reports whether pattern is more specific than best: a name
+// pattern beats a MIME one, then a longer pattern a shorter one. Equal
+// lengths compare as strings so the choice doesn't depend on map order.
+func betterOpener(pattern string, byName bool, best string, bestByName bool) bool {
+	if byName != bestByName {
+		return byName
+	}
+	if len(pattern) != len(best) {
+		return len(pattern) > len(best)
+	}
+	return pattern < best
+}
+
+// MimeType returns the MIME type of path ("application/pdf"), from its
+// extension or, failing that, xdg-mime. Returns "" if it cannot be
+// determined.
+func MimeType(path string) string {
+	if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+		// Drop parameters such as "; charset=utf-8"
+		mediaType, _, _ := strings.Cut(byExt, ";")
+		return strings.TrimSpace(mediaType)
+	}
+	return queryFiletype(path)
+}
+
+// queryFiletype asks xdg-mime for the MIME type of path; "" on failure.
+func queryFiletype(path string) string {
+	// #nosec G204 -- Fixed program, file path passed as an argument
+	out, err := exec.Command("xdg-mime", "query", "filetype", path).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,72 @@
+package fileops
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOpenerCommand(t *testing.T) {
+	openers := map[string]string{
+		"*.pdf":     "zathura {file}",
+		"*.tar.gz":  "file-roller",
+		"*.gz":      "gzip -l",
+		"image/*":   "imv --",
+		"image/png": "pinta",
+		"text/html": "sh -c 'less \"$1\"' less {file}",
+		"*.png":     "'my viewer' --path={file}",
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"/a/doc.pdf", []string{"zathura", "/a/doc.pdf"}},
+		{"/a/DOC.PDF", []string{"zathura", "/a/DOC.PDF"}},
+		{"/a/b c.tar.gz", []string{"file-roller", "/a/b c.tar.gz"}},
+		{"/a/log.gz", []string{"gzip", "-l", "/a/log.gz"}},
+		{"/a/photo.jpg", []string{"imv", "--", "/a/photo.jpg"}},
+		{"/a/shot.png", []string{"my viewer", "--path=/a/shot.png"}},
+		{"/a/page.html", []string{"sh", "-c", `less "$1"`, "less", "/a/page.html"}},
+		{"/a/song.flac-unknown-ext", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := OpenerCommand(openers, tt.path)
+		if err != nil {
+			t.Errorf("OpenerCommand(%q) error = %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("OpenerCommand(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+
+	if got, err := OpenerCommand(nil, "/a/doc.pdf"); got != nil || err != nil {
+		t.Errorf("OpenerCommand(nil) = %q, %v, want nil", got, err)
+	}
+	if _, err := OpenerCommand(map[string]string{"*.pdf": "zathura 'unclosed"}, "/a/doc.pdf"); err == nil {
+		t.Error("OpenerCommand() with a bad command should error")
+	}
+}
+
+func TestCheckOpener(t *testing.T) {
+	tests := []struct {
+		pattern string
+		command string
+		valid   bool
+	}{
+		{"*.pdf", "zathura {file}", true},
+		{"image/*", "imv", true},
+		{"", "imv", false},
+		{"[", "imv", false},
+		{"*.pdf", "", false},
+		{"*.pdf", "zathura 'x", false},
+	}
+
+	for _, tt := range tests {
+		err := CheckOpener(tt.pattern, tt.command)
+		if (err == nil) != tt.valid {
+			t.Errorf("CheckOpener(%q, %q) error = %v, want valid %v", tt.pattern, tt.command, err, tt.valid)
+		}
+	}
+}
@@ -3,11 +3,14 @@ package fileops
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +26,12 @@ const (
 	OpDelete
 	// OpRename represents a rename operation
 	OpRename
+	// OpTrash represents moving files to the trash
+	OpTrash
+	// OpShred represents overwriting files before deleting them
+	OpShred
+	// OpLink represents creating hard links
+	OpLink
 )
 
 // String returns a human-readable name for the operation type.
@@ -36,6 +45,12 @@ func (o OperationType) String() string {
 		return "Delete"
 	case OpRename:
 		return "Rename"
+	case OpTrash:
+		return "Trash"
+	case OpShred:
+		return "Shred"
+	case OpLink:
+		return "Link"
 	default:
 		return "Unknown"
 	}
@@ -75,12 +90,39 @@ func (s OperationStatus) String() string {
 	}
 }
 
+// OperationPhase is the step a multi-step operation is on. Progress starts
+// over in each phase.
+type OperationPhase int
+
+const (
+	// PhaseTransfer is the only phase of single-step operations, and the
+	// renaming step of moves
+	PhaseTransfer OperationPhase = iota
+	// PhaseCopying is a move that could not rename copying the data
+	PhaseCopying
+	// PhaseRemoving is a move removing its sources after copying them;
+	// progress counts entries instead of bytes
+	PhaseRemoving
+)
+
+// String returns a label for the phase, or "" for PhaseTransfer.
+func (p OperationPhase) String() string {
+	switch p {
+	case PhaseCopying:
+		return "copying…"
+	case PhaseRemoving:
+		return "removing source…"
+	default:
+		return ""
+	}
+}
+
 // Operation represents a file operation with progress tracking.
 type Operation struct {
 	// ID is a unique identifier for this operation
 	ID string
 
-	// Type is the operation type (copy, move, delete, rename)
+	// Type is the operation type (copy, move, delete, rename, trash)
 	Type OperationType
 
 	// Source is the source path(s) for the operation
@@ -89,16 +131,30 @@ type Operation struct {
 	// Destination is the destination path (not used for delete)
 	Destination string
 
+	// Results holds, for each Source path, where it ended up: the copy,
+	// its new path or the file in the trash. It is "" for sources not
+	// done, and for deletes and shreds. Read it with GetResults
+	Results []string
+
 	// Status is the current operation status
 	Status OperationStatus
 
+	// Phase is the step the operation is on
+	Phase OperationPhase
+
+	// Priority orders the operation among those waiting in an
+	// OperationQueue
+	Priority Priority
+
 	// Progress is the current progress (0.0 to 1.0)
 	Progress float64
 
-	// BytesProcessed is the number of bytes processed so far
+	// BytesProcessed is the number of bytes processed so far. Deletes, and
+	// moves in PhaseRemoving, count entries removed instead
 	BytesProcessed int64
 
-	// BytesTotal is the total number of bytes to process
+	// BytesTotal is the total number of bytes to process, or of entries to
+	// remove
 	BytesTotal int64
 
 	// CurrentFile is the file currently being processed
@@ -117,11 +173,22 @@ type Operation struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	// done is closed when the operation ends, or once its worker returns
+	// if held (see hold)
+	done     chan struct{}
+	doneOnce sync.Once
+	held     bool
+
+	// events are sent to the handlers in here (see Subscribe)
+	events subscribers
+
 	// Mutex for thread-safe updates
 	mu sync.RWMutex
 }
 
-// ProgressCallback is called when operation progress updates.
+// ProgressCallback is called when operation progress updates, and when
+// the operation ends. It is the single handler given to the functions
+// starting operations; Subscribe lets any number of others watch.
 type ProgressCallback func(op *Operation)
 
 // NewOperation creates a new operation with a unique ID.
@@ -132,23 +199,71 @@ func NewOperation(opType OperationType, source []string, destination string) *Op
 		Type:        opType,
 		Source:      source,
 		Destination: destination,
+		Results:     make([]string, len(source)),
 		Status:      StatusPending,
 		Progress:    0.0,
 		ctx:         ctx,
 		cancel:      cancel,
+		done:        make(chan struct{}),
 	}
 }
 
 // Cancel cancels the operation.
 func (op *Operation) Cancel() {
 	op.mu.Lock()
-	defer op.mu.Unlock()
+	finished := false
 	if op.Status == StatusRunning || op.Status == StatusPending {
 		op.Status = StatusCancelled
 		op.cancel()
+		finished = op.finish()
+	}
+	op.mu.Unlock()
+
+	if finished {
+		op.emit(EventFinished, "")
+	}
+}
+
+// Done returns a channel that is closed when the operation completes,
+// fails or is cancelled. A cancelled operation may still be stopping
+// when it is closed. For operations started by an OperationQueue's own
+// methods it is closed after the last call to their callback.
+func (op *Operation) Done() <-chan struct{} {
+	return op.done
+}
+
+// hold keeps SetStatus and SetError from closing Done when the operation
+// ends, leaving it to release. Cancel still closes it at once.
+func (op *Operation) hold() {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	op.held = true
+}
+
+// release closes Done, if it is still open, and sends EventFinished.
+// Workers of held operations call it once they have returned.
+func (op *Operation) release() {
+	op.mu.Lock()
+	op.held = false
+	finished := op.finish()
+	op.mu.Unlock()
+
+	if finished {
+		op.emit(EventFinished, "")
 	}
 }
 
+// finish closes the done channel, once, and reports whether this call
+// closed it, so the caller sends EventFinished.
+func (op *Operation) finish() bool {
+	first := false
+	op.doneOnce.Do(func() {
+		close(op.done)
+		first = true
+	})
+	return first
+}
+
 // IsCancelled returns true if the operation was cancelled.
 func (op *Operation) IsCancelled() bool {
 	select {
@@ -162,8 +277,7 @@ func (op *Operation) IsCancelled() bool {
 // UpdateProgress updates the operation's progress.
 func (op *Operation) UpdateProgress(bytesProcessed, bytesTotal int64, currentFile string) {
 	op.mu.Lock()
-	defer op.mu.Unlock()
-
+	fileStarted := currentFile != "" && currentFile != op.CurrentFile
 	op.BytesProcessed = bytesProcessed
 	op.BytesTotal = bytesTotal
 	op.CurrentFile = currentFile
@@ -171,29 +285,93 @@ func (op *Operation) UpdateProgress(bytesProcessed, bytesTotal int64, currentFil
 	if bytesTotal > 0 {
 		op.Progress = float64(bytesProcessed) / float64(bytesTotal)
 	}
+	op.mu.Unlock()
+
+	if fileStarted {
+		op.emit(EventFileStarted, currentFile)
+	}
+	op.emit(EventProgress, "")
 }
 
 // SetStatus sets the operation status.
 func (op *Operation) SetStatus(status OperationStatus) {
 	op.mu.Lock()
-	defer op.mu.Unlock()
+	started, finished := false, false
 	op.Status = status
 
 	if status == StatusRunning && op.StartTime.IsZero() {
 		op.StartTime = time.Now()
+		started = true
 	}
 	if status == StatusCompleted || status == StatusFailed || status == StatusCancelled {
 		op.EndTime = time.Now()
+		finished = !op.held && op.finish()
+	}
+	op.mu.Unlock()
+
+	if started {
+		op.emit(EventStarted, "")
+	}
+	if finished {
+		op.emit(EventFinished, "")
 	}
 }
 
+// GetStatus returns the current status (thread-safe).
+func (op *Operation) GetStatus() OperationStatus {
+	op.mu.RLock()
+	defer op.mu.RUnlock()
+	return op.Status
+}
+
+// SetPhase moves the operation to the next phase, restarting its progress.
+func (op *Operation) SetPhase(phase OperationPhase) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	op.Phase = phase
+	op.Progress = 0
+	op.BytesProcessed = 0
+	op.BytesTotal = 0
+}
+
+// GetPhase returns the step the operation is on (thread-safe).
+func (op *Operation) GetPhase() OperationPhase {
+	op.mu.RLock()
+	defer op.mu.RUnlock()
+	return op.Phase
+}
+
 // SetError sets an error and marks the operation as failed.
 func (op *Operation) SetError(err error) {
 	op.mu.Lock()
-	defer op.mu.Unlock()
 	op.Error = err
 	op.Status = StatusFailed
 	op.EndTime = time.Now()
+	finished := !op.held && op.finish()
+	op.mu.Unlock()
+
+	if finished {
+		op.emit(EventFinished, "")
+	}
+}
+
+// setResult records that source ended up at path (see Results).
+func (op *Operation) setResult(source, path string) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	for i, s := range op.Source {
+		if s == source && i < len(op.Results) {
+			op.Results[i] = path
+			return
+		}
+	}
+}
+
+// GetResults returns a copy of Results (thread-safe).
+func (op *Operation) GetResults() []string {
+	op.mu.RLock()
+	defer op.mu.RUnlock()
+	return append([]string(nil), op.Results...)
 }
 
 // GetProgress returns the current progress information (thread-safe).
@@ -203,64 +381,111 @@ func (op *Operation) GetProgress() (float64, int64, int64, string) {
 	return op.Progress, op.BytesProcessed, op.BytesTotal, op.CurrentFile
 }
 
+// Affects reports whether the operation adds or removes entries directly
+// in dir: a copy, move or hard link into it, or a move, rename, delete,
+// trash or shred of something in it.
+func (op *Operation) Affects(dir string) bool {
+	dir = filepath.Clean(dir)
+	if op.Destination != "" {
+		dest := filepath.Clean(op.Destination)
+		if dest == dir || filepath.Dir(dest) == dir {
+			return true
+		}
+	}
+	if op.Type == OpCopy || op.Type == OpLink {
+		return false
+	}
+	for _, source := range op.Source {
+		if filepath.Dir(filepath.Clean(source)) == dir {
+			return true
+		}
+	}
+	return false
+}
+
 // Copy performs a copy operation from source to destination.
-// It supports copying files and directories recursively.
+// It supports copying files and directories recursively. Like the other
+// package-level operations it runs on the default queue (see DefaultQueue).
 func Copy(source string, destination string, callback ProgressCallback) *Operation {
-	op := NewOperation(OpCopy, []string{source}, destination)
-	go performCopy(op, source, destination, callback)
-	return op
+	return defaultQueue.Copy(source, destination, callback)
 }
 
 // CopyMultiple copies multiple files/directories to a destination directory.
 func CopyMultiple(sources []string, destination string, callback ProgressCallback) *Operation {
-	op := NewOperation(OpCopy, sources, destination)
-	go performCopyMultiple(op, sources, destination, callback)
-	return op
+	return defaultQueue.CopyMultiple(sources, destination, callback)
+}
+
+// TransferOptions adjusts how CopyMultipleWith and MoveMultipleWith write
+// the destination. The zero value copies and moves as CopyMultiple and
+// MoveMultiple do.
+type TransferOptions struct {
+	// Symlinks is what a copy does with the symlinks it meets; moves keep
+	// them as they are
+	Symlinks SymlinkPolicy
+
+	// FixNames writes every entry under FixName's version of its name,
+	// for destinations that can't store some names (see RestrictsNames)
+	FixNames bool
+}
+
+// CopyMultipleWith is CopyMultiple adjusted by opts.
+func CopyMultipleWith(sources []string, destination string, opts TransferOptions, callback ProgressCallback) *Operation {
+	return defaultQueue.CopyMultipleWith(sources, destination, opts, callback)
 }
 
 // Move performs a move operation from source to destination.
 func Move(source string, destination string, callback ProgressCallback) *Operation {
-	op := NewOperation(OpMove, []string{source}, destination)
-	go performMove(op, source, destination, callback)
-	return op
+	return defaultQueue.Move(source, destination, callback)
 }
 
 // MoveMultiple moves multiple files/directories to a destination directory.
 func MoveMultiple(sources []string, destination string, callback ProgressCallback) *Operation {
-	op := NewOperation(OpMove, sources, destination)
-	go performMoveMultiple(op, sources, destination, callback)
-	return op
+	return defaultQueue.MoveMultiple(sources, destination, callback)
+}
+
+// MoveMultipleWith is MoveMultiple adjusted by opts.
+func MoveMultipleWith(sources []string, destination string, opts TransferOptions, callback ProgressCallback) *Operation {
+	return defaultQueue.MoveMultipleWith(sources, destination, opts, callback)
+}
+
+// MovePaths moves each of sources to the path at the same index in dests.
+func MovePaths(sources, dests []string, callback ProgressCallback) *Operation {
+	return defaultQueue.MovePaths(sources, dests, callback)
 }
 
 // Delete performs a delete operation on the given path.
 func Delete(path string, callback ProgressCallback) *Operation {
-	op := NewOperation(OpDelete, []string{path}, "")
-	go performDelete(op, path, callback)
-	return op
+	return defaultQueue.Delete(path, callback)
 }
 
 // DeleteMultiple deletes multiple files/directories.
 func DeleteMultiple(paths []string, callback ProgressCallback) *Operation {
-	op := NewOperation(OpDelete, paths, "")
-	go performDeleteMultiple(op, paths, callback)
-	return op
+	return defaultQueue.DeleteMultiple(paths, callback)
 }
 
 // Rename performs a rename operation.
 func Rename(oldPath string, newPath string, callback ProgressCallback) *Operation {
-	op := NewOperation(OpRename, []string{oldPath}, newPath)
-	go performRename(op, oldPath, newPath, callback)
-	return op
+	return defaultQueue.Rename(oldPath, newPath, callback)
 }
 
 // performCopy executes the copy operation.
-func performCopy(op *Operation, source, destination string, callback ProgressCallback) {
+func performCopy(op *Operation, source, destination string, symlinks SymlinkPolicy, callback ProgressCallback) {
 	op.SetStatus(StatusRunning)
 
+	if err := validateTransfer([]string{source}, []string{destination}, false); err != nil {
+		op.SetError(err)
+		if callback != nil {
+			callback(op)
+		}
+		return
+	}
+
 	// Calculate total size
-	totalSize, err := calculateSize(source)
+	totalSize, err := treeSize(op.ctx, source, symlinks)
 	if err != nil {
-		op.SetError(fmt.Errorf("failed to calculate size: %w", err))
+		if !op.IsCancelled() {
+			op.SetError(fmt.Errorf("failed to calculate size: %w", err))
+		}
 		if callback != nil {
 			callback(op)
 		}
@@ -273,13 +498,15 @@ func performCopy(op *Operation, source, destination string, callback ProgressCal
 	}
 
 	// Perform the copy
-	var bytesProcessed int64
-	err = copyRecursive(op, source, destination, &bytesProcessed, totalSize, callback)
+	state := newCopyState(op, totalSize, callback)
+	state.symlinks, state.roots = symlinks, []string{filepath.Clean(source)}
+	err = copyRecursive(op, source, destination, state)
 	if err != nil {
 		if !op.IsCancelled() {
 			op.SetError(err)
 		}
 	} else {
+		op.setResult(source, destination)
 		op.SetStatus(StatusCompleted)
 	}
 
@@ -289,45 +516,45 @@ func performCopy(op *Operation, source, destination string, callback ProgressCal
 }
 
 // performCopyMultiple executes copy operation for multiple sources.
-func performCopyMultiple(op *Operation, sources []string, destination string, callback ProgressCallback) {
+func performCopyMultiple(op *Operation, sources []string, destination string, opts TransferOptions, callback ProgressCallback) {
 	op.SetStatus(StatusRunning)
 
-	// Calculate total size
-	var totalSize int64
-	for _, src := range sources {
-		size, err := calculateSize(src)
-		if err != nil {
-			op.SetError(fmt.Errorf("failed to calculate size for %s: %w", src, err))
-			if callback != nil {
-				callback(op)
-			}
-			return
+	dests := transferDests(sources, destination, opts)
+	if err := validateTransfer(sources, dests, false); err != nil {
+		op.SetError(err)
+		if callback != nil {
+			callback(op)
 		}
-		totalSize += size
+		return
 	}
 
-	var bytesProcessed int64
+	// Calculate total size
+	var totalSize int64
 	for _, src := range sources {
-		if op.IsCancelled() {
-			break
-		}
-
-		// Determine destination path
-		destPath := filepath.Join(destination, filepath.Base(src))
-
-		err := copyRecursive(op, src, destPath, &bytesProcessed, totalSize, callback)
+		size, err := treeSize(op.ctx, src, opts.Symlinks)
 		if err != nil {
 			if !op.IsCancelled() {
-				op.SetError(fmt.Errorf("failed to copy %s: %w", src, err))
+				op.SetError(fmt.Errorf("failed to calculate size for %s: %w", src, err))
 			}
 			if callback != nil {
 				callback(op)
 			}
 			return
 		}
+		totalSize += size
 	}
 
-	if !op.IsCancelled() {
+	state := newCopyState(op, totalSize, callback)
+	state.symlinks, state.fixNames = opts.Symlinks, opts.FixNames
+	for _, src := range sources {
+		state.roots = append(state.roots, filepath.Clean(src))
+	}
+	err := copyParallel(op, sources, dests, state)
+	if err != nil {
+		if !op.IsCancelled() {
+			op.SetError(err)
+		}
+	} else if !op.IsCancelled() {
 		op.SetStatus(StatusCompleted)
 	}
 
@@ -336,8 +563,22 @@ func performCopyMultiple(op *Operation, sources []string, destination string, ca
 	}
 }
 
+// transferDests returns where each of sources goes when copied or moved
+// into destination with opts.
+func transferDests(sources []string, destination string, opts TransferOptions) []string {
+	dests := make([]string, len(sources))
+	for i, src := range sources {
+		name := filepath.Base(src)
+		if opts.FixNames {
+			name = FixName(name)
+		}
+		dests[i] = filepath.Join(destination, name)
+	}
+	return dests
+}
+
 // copyRecursive recursively copies files and directories.
-func copyRecursive(op *Operation, src, dst string, bytesProcessed *int64, totalSize int64, callback ProgressCallback) error {
+func copyRecursive(op *Operation, src, dst string, state *copyState) error {
 	if op.IsCancelled() {
 		return fmt.Errorf("operation cancelled")
 	}
@@ -349,24 +590,22 @@ func copyRecursive(op *Operation, src, dst string, bytesProcessed *int64, totalS
 
 	// Handle symlinks
 	if srcInfo.Mode()&os.ModeSymlink != 0 {
-		target, err := os.Readlink(src)
-		if err != nil {
-			return fmt.Errorf("failed to read symlink: %w", err)
+		if srcInfo = state.symlinks.follow(src); srcInfo == nil {
+			return copyLink(src, dst, state)
 		}
-		return os.Symlink(target, dst)
 	}
 
 	// Handle directories
 	if srcInfo.IsDir() {
-		return copyDir(op, src, dst, bytesProcessed, totalSize, callback)
+		return copyDir(op, src, dst, state)
 	}
 
 	// Handle regular files
-	return copyFile(op, src, dst, bytesProcessed, totalSize, callback)
+	return copyFile(op, src, dst, state)
 }
 
 // copyDir copies a directory recursively.
-func copyDir(op *Operation, src, dst string, bytesProcessed *int64, totalSize int64, callback ProgressCallback) error {
+func copyDir(op *Operation, src, dst string, state *copyState) error {
 	// Create destination directory
 	srcInfo, err := os.Stat(src)
 	if err != nil {
@@ -390,9 +629,9 @@ func copyDir(op *Operation, src, dst string, bytesProcessed *int64, totalSize in
 		}
 
 		srcPath := filepath.Join(src, entry.Name())
-		dstPath := filepath.Join(dst, entry.Name())
+		dstPath := filepath.Join(dst, state.destName(entry.Name()))
 
-		if err := copyRecursive(op, srcPath, dstPath, bytesProcessed, totalSize, callback); err != nil {
+		if err := copyRecursive(op, srcPath, dstPath, state); err != nil {
 			return err
 		}
 	}
@@ -400,8 +639,35 @@ func copyDir(op *Operation, src, dst string, bytesProcessed *int64, totalSize in
 	return nil
 }
 
-// copyFile copies a single file with progress tracking.
-func copyFile(op *Operation, src, dst string, bytesProcessed *int64, totalSize int64, callback ProgressCallback) error {
+// copyFile copies a single file with progress tracking. A file with more
+// than one hard link is copied once per operation: later links to it are
+// hard links to that copy, as with cp --preserve=links.
+func copyFile(op *Operation, src, dst string, state *copyState) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	copied, first := state.links.claim(info, dst)
+	if copied != nil && !first {
+		// Copy the data after all if the first copy failed, or the link
+		// doesn't work (another filesystem mounted in the tree)
+		if copied.wait() == nil && linkFile(op, copied.dst, dst) == nil {
+			state.add(info.Size(), src)
+			return nil
+		}
+		return copyData(op, src, dst, state)
+	}
+
+	err = copyData(op, src, dst, state)
+	if first {
+		copied.finish(err)
+	}
+	return err
+}
+
+// copyData copies the contents and permissions of the file src to dst.
+func copyData(op *Operation, src, dst string, state *copyState) error {
 	srcFile, err := os.Open(src) // #nosec G304 - file path from user operation
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %w", err)
@@ -418,6 +684,9 @@ func copyFile(op *Operation, src, dst string, bytesProcessed *int64, totalSize i
 		return fmt.Errorf("failed to stat source file: %w", err)
 	}
 
+	if _, err := os.Lstat(dst); err == nil {
+		op.emit(EventConflict, dst)
+	}
 	dstFile, err := os.Create(dst) // #nosec G304 - file path from user operation
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
@@ -446,13 +715,11 @@ func copyFile(op *Operation, src, dst string, bytesProcessed *int64, totalSize i
 			if _, writeErr := dstFile.Write(buf[:n]); writeErr != nil {
 				return fmt.Errorf("failed to write to destination: %w", writeErr)
 			}
-
-			*bytesProcessed += int64(n)
-			op.UpdateProgress(*bytesProcessed, totalSize, src)
-
-			if callback != nil {
-				callback(op)
+			if ioLimit.wait(op.ctx, n) != nil {
+				return fmt.Errorf("operation cancelled")
 			}
+
+			state.add(int64(n), src)
 		}
 
 		if err == io.EOF {
@@ -470,102 +737,121 @@ func copyFile(op *Operation, src, dst string, bytesProcessed *int64, totalSize i
 func performMove(op *Operation, source, destination string, callback ProgressCallback) {
 	op.SetStatus(StatusRunning)
 
-	// Try atomic rename first (same filesystem)
-	err := os.Rename(source, destination)
-	if err == nil {
-		op.UpdateProgress(1, 1, source)
-		op.SetStatus(StatusCompleted)
+	if err := validateTransfer([]string{source}, []string{destination}, true); err != nil {
+		op.SetError(err)
 		if callback != nil {
 			callback(op)
 		}
 		return
 	}
 
-	// Fall back to copy + delete
-	totalSize, err := calculateSize(source)
+	// Try atomic rename first (same filesystem)
+	err := os.Rename(source, destination)
 	if err != nil {
-		op.SetError(fmt.Errorf("failed to calculate size: %w", err))
-		if callback != nil {
-			callback(op)
-		}
+		moveAcross(op, []string{source}, []string{destination}, TransferOptions{}, callback)
 		return
 	}
 
-	var bytesProcessed int64
-	err = copyRecursive(op, source, destination, &bytesProcessed, totalSize, callback)
-	if err != nil {
-		op.SetError(fmt.Errorf("failed to copy: %w", err))
+	op.setResult(source, destination)
+	op.UpdateProgress(1, 1, source)
+	op.SetStatus(StatusCompleted)
+	if callback != nil {
+		callback(op)
+	}
+}
+
+// performMoveMultiple executes move operation for multiple sources.
+func performMoveMultiple(op *Operation, sources []string, destination string, opts TransferOptions, callback ProgressCallback) {
+	op.SetStatus(StatusRunning)
+
+	dests := transferDests(sources, destination, opts)
+	if err := validateTransfer(sources, dests, true); err != nil {
+		op.SetError(err)
 		if callback != nil {
 			callback(op)
 		}
 		return
 	}
 
-	// Delete source after successful copy
-	err = os.RemoveAll(source)
-	if err != nil {
-		op.SetError(fmt.Errorf("failed to remove source after copy: %w", err))
-		if callback != nil {
-			callback(op)
+	// Rename what can be renamed first; progress counts sources
+	var pending, pendingDests []string
+	for i, src := range sources {
+		if op.IsCancelled() {
+			break
 		}
+		op.UpdateProgress(int64(i), int64(len(sources)), src)
+
+		destPath := dests[i]
+		if err := os.Rename(src, destPath); err != nil {
+			pending = append(pending, src)
+			pendingDests = append(pendingDests, destPath)
+		} else {
+			op.setResult(src, destPath)
+		}
+	}
+
+	if len(pending) > 0 && !op.IsCancelled() {
+		moveAcross(op, pending, pendingDests, opts, callback)
 		return
 	}
 
-	op.SetStatus(StatusCompleted)
+	if !op.IsCancelled() {
+		op.UpdateProgress(int64(len(sources)), int64(len(sources)), "")
+		op.SetStatus(StatusCompleted)
+	}
+
 	if callback != nil {
 		callback(op)
 	}
 }
 
-// performMoveMultiple executes move operation for multiple sources.
-func performMoveMultiple(op *Operation, sources []string, destination string, callback ProgressCallback) {
+// performMovePaths executes a move of sources to the paths in dests.
+func performMovePaths(op *Operation, sources, dests []string, callback ProgressCallback) {
 	op.SetStatus(StatusRunning)
 
-	for _, src := range sources {
-		if op.IsCancelled() {
-			break
-		}
-
-		destPath := filepath.Join(destination, filepath.Base(src))
-
-		// Try atomic rename first
-		err := os.Rename(src, destPath)
-		if err == nil {
-			continue
+	fail := func(err error) {
+		op.SetError(err)
+		if callback != nil {
+			callback(op)
 		}
-
-		// Fall back to copy + delete
-		var totalSize int64
-		totalSize, err = calculateSize(src)
-		if err != nil {
-			op.SetError(fmt.Errorf("failed to calculate size for %s: %w", src, err))
-			if callback != nil {
-				callback(op)
-			}
+	}
+	if len(sources) != len(dests) {
+		fail(fmt.Errorf("%d sources for %d destinations", len(sources), len(dests)))
+		return
+	}
+	for _, dest := range dests {
+		if _, err := os.Lstat(dest); err == nil {
+			fail(fmt.Errorf("%s already exists", dest))
 			return
 		}
+	}
 
-		var bytesProcessed int64
-		err = copyRecursive(op, src, destPath, &bytesProcessed, totalSize, callback)
-		if err != nil {
-			op.SetError(fmt.Errorf("failed to move %s: %w", src, err))
-			if callback != nil {
-				callback(op)
-			}
-			return
+	var pending, pendingDests []string
+	for i, src := range sources {
+		if op.IsCancelled() {
+			break
 		}
+		op.UpdateProgress(int64(i), int64(len(sources)), src)
 
-		err = os.RemoveAll(src)
-		if err != nil {
-			op.SetError(fmt.Errorf("failed to remove source %s: %w", src, err))
-			if callback != nil {
-				callback(op)
-			}
+		if err := os.MkdirAll(filepath.Dir(dests[i]), 0750); err != nil {
+			fail(fmt.Errorf("failed to move %s: %w", src, err))
 			return
 		}
+		if err := os.Rename(src, dests[i]); err != nil {
+			pending = append(pending, src)
+			pendingDests = append(pendingDests, dests[i])
+		} else {
+			op.setResult(src, dests[i])
+		}
+	}
+
+	if len(pending) > 0 && !op.IsCancelled() {
+		moveAcross(op, pending, pendingDests, TransferOptions{}, callback)
+		return
 	}
 
 	if !op.IsCancelled() {
+		op.UpdateProgress(int64(len(sources)), int64(len(sources)), "")
 		op.SetStatus(StatusCompleted)
 	}
 
@@ -574,57 +860,145 @@ func performMoveMultiple(op *Operation, sources []string, destination string, ca
 	}
 }
 
-// performDelete executes the delete operation.
-func performDelete(op *Operation, path string, callback ProgressCallback) {
-	op.SetStatus(StatusRunning)
-
-	totalSize, err := calculateSize(path)
-	if err != nil {
-		op.SetError(fmt.Errorf("failed to calculate size: %w", err))
+// moveAcross moves sources that could not be renamed, usually because
+// they are on another filesystem, to dests by copying them and then
+// removing them, in PhaseCopying and PhaseRemoving. Sources are removed
+// only once all of them are copied, so a failed copy leaves every source
+// in place. Names inside directories are fixed if opts says so.
+func moveAcross(op *Operation, sources, dests []string, opts TransferOptions, callback ProgressCallback) {
+	fail := func(err error) {
+		if !op.IsCancelled() {
+			op.SetError(err)
+		}
 		if callback != nil {
 			callback(op)
 		}
-		return
 	}
 
-	op.UpdateProgress(0, totalSize, path)
+	op.SetPhase(PhaseCopying)
+	var totalSize int64
+	for _, src := range sources {
+		size, err := treeSize(op.ctx, src, SymlinksPreserve)
+		if err != nil {
+			fail(fmt.Errorf("failed to calculate size for %s: %w", src, err))
+			return
+		}
+		totalSize += size
+	}
+	op.UpdateProgress(0, totalSize, sources[0])
 	if callback != nil {
 		callback(op)
 	}
 
-	err = os.RemoveAll(path)
-	if err != nil {
-		op.SetError(fmt.Errorf("failed to delete: %w", err))
-	} else {
-		op.UpdateProgress(totalSize, totalSize, path)
-		op.SetStatus(StatusCompleted)
+	progress := newCopyState(op, totalSize, callback)
+	progress.fixNames = opts.FixNames
+	for i, src := range sources {
+		if err := copyRecursive(op, src, dests[i], progress); err != nil {
+			fail(fmt.Errorf("failed to move %s: %w", src, err))
+			return
+		}
+	}
+
+	op.SetPhase(PhaseRemoving)
+	if err := removeSources(op, sources, callback); err != nil {
+		fail(fmt.Errorf("failed to remove source after copy: %w", err))
+		return
+	}
+	for i, src := range sources {
+		op.setResult(src, dests[i])
 	}
 
+	if !op.IsCancelled() {
+		op.SetStatus(StatusCompleted)
+	}
 	if callback != nil {
 		callback(op)
 	}
 }
 
-// performDeleteMultiple executes delete operation for multiple paths.
-func performDeleteMultiple(op *Operation, paths []string, callback ProgressCallback) {
-	op.SetStatus(StatusRunning)
-
+// removeSources removes paths and everything under them, bottom up and
+// one entry at a time, stopping between entries if op is cancelled.
+// Progress counts entries removed. Paths already gone count as removed,
+// as with os.RemoveAll.
+func removeSources(op *Operation, paths []string, callback ProgressCallback) error {
+	var total int64
 	for _, path := range paths {
-		if op.IsCancelled() {
-			break
+		err := filepath.WalkDir(path, func(_ string, _ fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			total++
+			return err
+		})
+		if err != nil {
+			return err
 		}
+	}
+	op.UpdateProgress(0, total, "")
 
-		err := os.RemoveAll(path)
+	var removed int64
+	var remove func(path string) error
+	remove = func(path string) error {
+		if op.IsCancelled() {
+			return fmt.Errorf("operation cancelled")
+		}
+		info, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
 		if err != nil {
-			op.SetError(fmt.Errorf("failed to delete %s: %w", path, err))
-			if callback != nil {
-				callback(op)
+			return err
+		}
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return err
 			}
-			return
+			for _, entry := range entries {
+				if err := remove(filepath.Join(path, entry.Name())); err != nil {
+					return err
+				}
+			}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		removed++
+		op.UpdateProgress(removed, total, path)
+		if callback != nil {
+			callback(op)
 		}
+		return nil
 	}
 
-	if !op.IsCancelled() {
+	for _, path := range paths {
+		if err := remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// performDelete executes the delete operation; progress counts entries.
+func performDelete(op *Operation, path string, callback ProgressCallback) {
+	performDeleteMultiple(op, []string{path}, callback)
+}
+
+// performDeleteMultiple executes delete operation for multiple paths;
+// progress counts entries, so a failed or cancelled delete still tells
+// how many went.
+func performDeleteMultiple(op *Operation, paths []string, callback ProgressCallback) {
+	op.SetStatus(StatusRunning)
+	if callback != nil {
+		callback(op)
+	}
+
+	if err := removeSources(op, paths, callback); err != nil {
+		if !op.IsCancelled() {
+			op.SetError(fmt.Errorf("failed to delete: %w", err))
+		}
+	} else if !op.IsCancelled() {
 		op.SetStatus(StatusCompleted)
 	}
 
@@ -637,10 +1011,14 @@ func performDeleteMultiple(op *Operation, paths []string, callback ProgressCallb
 func performRename(op *Operation, oldPath, newPath string, callback ProgressCallback) {
 	op.SetStatus(StatusRunning)
 
-	err := os.Rename(oldPath, newPath)
+	err := checkRenameTarget(oldPath, newPath)
+	if err == nil {
+		err = os.Rename(oldPath, newPath)
+	}
 	if err != nil {
 		op.SetError(fmt.Errorf("failed to rename: %w", err))
 	} else {
+		op.setResult(oldPath, newPath)
 		op.UpdateProgress(1, 1, newPath)
 		op.SetStatus(StatusCompleted)
 	}
@@ -650,24 +1028,26 @@ func performRename(op *Operation, oldPath, newPath string, callback ProgressCall
 	}
 }
 
-// calculateSize calculates the total size of a file or directory.
-func calculateSize(path string) (int64, error) {
-	var size int64
-
-	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			size += info.Size()
-		}
+// checkRenameTarget refuses a rename onto another file, which os.Rename
+// would silently replace. On a case-insensitive filesystem newPath may
+// name a different file than it reads as; renaming to another case of the
+// same name finds oldPath itself and is allowed.
+func checkRenameTarget(oldPath, newPath string) error {
+	target, err := os.Lstat(newPath)
+	if err != nil {
 		return nil
-	})
-
-	return size, err
+	}
+	if source, err := os.Lstat(oldPath); err == nil && os.SameFile(source, target) {
+		return nil
+	}
+	return fmt.Errorf("%s already exists", filepath.Base(newPath))
 }
 
+// lastOperationID numbers operations; the queue keys its maps by ID, so
+// operations created in the same clock tick must not share one.
+var lastOperationID atomic.Uint64
+
 // generateOperationID generates a unique ID for an operation.
 func generateOperationID() string {
-	return fmt.Sprintf("op-%d", time.Now().UnixNano())
+	return fmt.Sprintf("op-%d", lastOperationID.Add(1))
 }
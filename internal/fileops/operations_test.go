@@ -1,8 +1,11 @@
 package fileops
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -16,6 +19,9 @@ func TestOperationType_String(t *testing.T) {
 		{OpMove, "Move"},
 		{OpDelete, "Delete"},
 		{OpRename, "Rename"},
+		{OpTrash, "Trash"},
+		{OpShred, "Shred"},
+		{OpLink, "Link"},
 	}
 
 	for _, tt := range tests {
@@ -71,6 +77,17 @@ func TestNewOperation(t *testing.T) {
 	}
 }
 
+func TestNewOperation_UniqueIDs(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 1000 {
+		op := NewOperation(OpRename, []string{"/a"}, "/b")
+		if seen[op.ID] {
+			t.Fatalf("ID %s given to two operations", op.ID)
+		}
+		seen[op.ID] = true
+	}
+}
+
 func TestOperation_UpdateProgress(t *testing.T) {
 	op := NewOperation(OpCopy, []string{"/src"}, "/dst")
 	op.UpdateProgress(50, 100, "/src/file.txt")
@@ -235,6 +252,73 @@ func TestCopyMultiple(t *testing.T) {
 	// Verify files were copied
 	verifyFileExists(t, filepath.Join(dstDir, "file1.txt"), "content1")
 	verifyFileExists(t, filepath.Join(dstDir, "file2.txt"), "content2")
+
+	results := op.GetResults()
+	if len(results) != 2 || results[0] != filepath.Join(dstDir, "file1.txt") || results[1] != filepath.Join(dstDir, "file2.txt") {
+		t.Errorf("GetResults() = %q, want the copies", results)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTransferRefusedUpFront(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "file.txt")
+	if err := os.WriteFile(file, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tmpDir, "dir")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range []*Operation{
+		CopyMultiple([]string{file}, tmpDir, nil),
+		Copy(file, file, nil),
+		CopyMultiple([]string{dir}, dir, nil),
+		MoveMultiple([]string{dir}, dir, nil),
+	} {
+		waitForOperation(t, op, 5*time.Second)
+		if op.GetStatus() != StatusFailed {
+			t.Errorf("%v %v: status = %v, want %v", op.Type, op.Source, op.GetStatus(), StatusFailed)
+		}
+	}
+	// Copying onto itself used to truncate the file
+	verifyFileExists(t, file, "content")
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("dir holds %d entries (%v), want none", len(entries), err)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestMovePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a.txt")
+	b := filepath.Join(tmpDir, "b.txt")
+	for _, path := range []string{a, b} {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An existing destination stops the whole move
+	op := MovePaths([]string{a, b}, []string{filepath.Join(tmpDir, "new", "a.txt"), a}, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusFailed {
+		t.Fatalf("MovePaths() onto an existing file: status = %v, want %v", op.GetStatus(), StatusFailed)
+	}
+	verifyFileExists(t, a, "a.txt")
+
+	dests := []string{filepath.Join(tmpDir, "new", "a.txt"), filepath.Join(tmpDir, "renamed.txt")}
+	op = MovePaths([]string{a, b}, dests, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("MovePaths() status = %v, want %v (error: %v)", op.GetStatus(), StatusCompleted, op.Error)
+	}
+	verifyFileExists(t, dests[0], "a.txt")
+	verifyFileExists(t, dests[1], "b.txt")
+	if results := op.GetResults(); results[0] != dests[0] || results[1] != dests[1] {
+		t.Errorf("GetResults() = %q, want %q", results, dests)
+	}
 }
 
 //nolint:dupl,gosec // Test similarity acceptable, test files use relaxed permissions
@@ -277,6 +361,64 @@ func TestMove(t *testing.T) {
 	}
 }
 
+// TestMoveAcross runs the copy and remove fallback that moves between
+// filesystems take, since the test directories share one.
+//
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestMoveAcross(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := filepath.Join(tmpDir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "sub/b.txt"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte("moved"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst := filepath.Join(tmpDir, "dst")
+
+	op := NewOperation(OpMove, []string{src}, dst)
+	op.SetStatus(StatusRunning)
+	var phases []OperationPhase
+	var removed, removeTotal int64
+	moveAcross(op, []string{src}, []string{dst}, TransferOptions{}, func(op *Operation) {
+		phase := op.GetPhase()
+		if len(phases) == 0 || phases[len(phases)-1] != phase {
+			phases = append(phases, phase)
+		}
+		if phase == PhaseRemoving {
+			_, removed, removeTotal, _ = op.GetProgress()
+		}
+	})
+
+	if op.Status != StatusCompleted {
+		t.Fatalf("Operation status = %v (%v), want %v", op.Status, op.Error, StatusCompleted)
+	}
+	if want := []OperationPhase{PhaseCopying, PhaseRemoving}; !slices.Equal(phases, want) {
+		t.Errorf("phases = %v, want %v", phases, want)
+	}
+	// src, sub and the two files
+	if removed != 4 || removeTotal != 4 {
+		t.Errorf("removal progress = %d / %d, want 4 / 4", removed, removeTotal)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("Source should not exist after move")
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "sub", "b.txt")); err != nil || string(data) != "moved" {
+		t.Errorf("Destination content = %q, %v, want moved", data, err)
+	}
+}
+
+func TestOperationPhase_String(t *testing.T) {
+	if got := PhaseTransfer.String(); got != "" {
+		t.Errorf("PhaseTransfer.String() = %q, want empty", got)
+	}
+	if got := PhaseRemoving.String(); got != "removing source…" {
+		t.Errorf("PhaseRemoving.String() = %q, want removing source…", got)
+	}
+}
+
 //nolint:gosec // Test file permissions are intentionally relaxed
 func TestDelete(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -344,6 +486,89 @@ func TestDeleteMultiple(t *testing.T) {
 	}
 }
 
+// deleteTree creates a directory of dirs subdirectories holding files
+// files each, returning it and how many entries it holds in all.
+//
+//nolint:gosec // Test file permissions are intentionally relaxed
+func deleteTree(t *testing.T, dirs, files int) (string, int64) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "tree")
+	for d := range dirs {
+		dir := filepath.Join(root, fmt.Sprintf("dir%d", d))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for f := range files {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.txt", f)), []byte("x"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return root, int64(1 + dirs + dirs*files)
+}
+
+func TestDeleteTreeProgress(t *testing.T) {
+	root, entries := deleteTree(t, 3, 10)
+
+	var mu sync.Mutex
+	var steps []int64
+	op := Delete(root, func(op *Operation) {
+		if _, done, total, _ := op.GetProgress(); total == entries {
+			mu.Lock()
+			steps = append(steps, done)
+			mu.Unlock()
+		}
+	})
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("status = %v (%v), want %v", op.GetStatus(), op.Error, StatusCompleted)
+	}
+	if _, err := os.Lstat(root); !os.IsNotExist(err) {
+		t.Errorf("%s still exists", root)
+	}
+
+	// Every entry removed is reported, the tree itself last
+	mu.Lock()
+	defer mu.Unlock()
+	if int64(len(steps)) < entries {
+		t.Fatalf("progress reported %d times, want at least %d", len(steps), entries)
+	}
+	if last := steps[len(steps)-1]; last != entries {
+		t.Errorf("progress ended at %d entries, want %d", last, entries)
+	}
+	if _, done, total, current := op.GetProgress(); done != total || current != root {
+		t.Errorf("GetProgress() = %d/%d at %s, want all of %s", done, total, current, root)
+	}
+}
+
+func TestDeleteCancelledBetweenFiles(t *testing.T) {
+	root, entries := deleteTree(t, 2, 20)
+
+	op := Delete(root, func(op *Operation) {
+		if _, done, _, _ := op.GetProgress(); done == 5 {
+			op.Cancel()
+		}
+	})
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCancelled {
+		t.Fatalf("status = %v, want %v", op.GetStatus(), StatusCancelled)
+	}
+	if _, done, total, _ := op.GetProgress(); done != 5 || total != entries {
+		t.Errorf("GetProgress() = %d/%d entries, want 5/%d", done, total, entries)
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Errorf("tree gone after cancelling: %v", err)
+	}
+}
+
+func TestDeleteMissing(t *testing.T) {
+	op := Delete(filepath.Join(t.TempDir(), "gone"), nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Errorf("status = %v (%v), want %v like os.RemoveAll", op.GetStatus(), op.Error, StatusCompleted)
+	}
+}
+
 //nolint:dupl // Test similarity is acceptable
 func TestRename(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -384,6 +609,25 @@ func TestRename(t *testing.T) {
 	}
 }
 
+func TestRenameOntoExisting(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldPath := filepath.Join(tmpDir, "a.txt")
+	newPath := filepath.Join(tmpDir, "b.txt")
+	for _, path := range []string{oldPath, newPath} {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0644); err != nil { //nolint:gosec // Test file
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	op := Rename(oldPath, newPath, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.Status != StatusFailed {
+		t.Errorf("Operation status = %v, want %v", op.Status, StatusFailed)
+	}
+	verifyFileExists(t, oldPath, "a.txt")
+	verifyFileExists(t, newPath, "b.txt")
+}
+
 func TestCopyWithProgress(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -466,51 +710,15 @@ func TestOperationCancel(t *testing.T) {
 	}
 }
 
-//nolint:gosec // Test file permissions are intentionally relaxed
-func TestCalculateSize(t *testing.T) {
-	tmpDir := t.TempDir()
-
-	// Create test files
-	file1 := filepath.Join(tmpDir, "file1.txt")
-	file2 := filepath.Join(tmpDir, "file2.txt")
-	content1 := "Hello"  // 5 bytes
-	content2 := "World!" // 6 bytes
-	if err := os.WriteFile(file1, []byte(content1), 0644); err != nil {
-		t.Fatalf("Failed to create file1: %v", err)
-	}
-	if err := os.WriteFile(file2, []byte(content2), 0644); err != nil {
-		t.Fatalf("Failed to create file2: %v", err)
-	}
-
-	// Calculate size of directory
-	size, err := calculateSize(tmpDir)
-	if err != nil {
-		t.Fatalf("calculateSize failed: %v", err)
-	}
-
-	expectedSize := int64(len(content1) + len(content2))
-	if size != expectedSize {
-		t.Errorf("Size = %d, want %d", size, expectedSize)
-	}
-}
-
 // Helper functions
 
 //nolint:unparam // timeout parameter useful for test flexibility
 func waitForOperation(t *testing.T, op *Operation, timeout time.Duration) {
 	t.Helper()
-	start := time.Now()
-	for {
-		if time.Since(start) > timeout {
-			t.Fatalf("Operation timed out after %v", timeout)
-		}
-
-		status := op.Status
-		if status == StatusCompleted || status == StatusFailed || status == StatusCancelled {
-			return
-		}
-
-		time.Sleep(10 * time.Millisecond)
+	select {
+	case <-op.Done():
+	case <-time.After(timeout):
+		t.Fatalf("Operation timed out after %v", timeout)
 	}
 }
 
@@ -527,3 +735,26 @@ func verifyFileExists(t *testing.T, path string, expectedContent string) {
 		t.Errorf("File %s content = %q, want %q", path, string(content), expectedContent)
 	}
 }
+
+func TestOperation_Affects(t *testing.T) {
+	tests := []struct {
+		name string
+		op   *Operation
+		dir  string
+		want bool
+	}{
+		{"copy into dir", NewOperation(OpCopy, []string{"/src/a"}, "/dst"), "/dst", true},
+		{"copy to a path in dir", NewOperation(OpCopy, []string{"/src/a"}, "/dst/a"), "/dst/", true},
+		{"copy from dir", NewOperation(OpCopy, []string{"/src/a"}, "/dst"), "/src", false},
+		{"move from dir", NewOperation(OpMove, []string{"/src/a"}, "/dst"), "/src", true},
+		{"delete in dir", NewOperation(OpDelete, []string{"/dir/a", "/other/b"}, ""), "/other", true},
+		{"delete elsewhere", NewOperation(OpDelete, []string{"/dir/sub/a"}, ""), "/dir", false},
+		{"trash in dir", NewOperation(OpTrash, []string{"/dir/a"}, ""), "/dir", true},
+		{"rename in dir", NewOperation(OpRename, []string{"/dir/a"}, "/dir/b"), "/dir", true},
+	}
+	for _, tt := range tests {
+		if got := tt.op.Affects(tt.dir); got != tt.want {
+			t.Errorf("%s: Affects(%q) = %v, want %v", tt.name, tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package fileops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// copyWorkers is how many files a multi-file copy copies at once. Trees
+// of small files are bound by per-file overhead (open, create, chmod,
+// close) rather than bandwidth, which a few files in flight hide.
+const copyWorkers = 4
+
+// errStopped ends a walk once a parallel copy has failed or is cancelled.
+var errStopped = errors.New("copy stopped")
+
+// copyState is what the copies of one operation, which may run side by
+// side, share: the bytes written so far, reported one at a time so the
+// operation's callback is never called concurrently, and the hard-linked
+// files copied so far.
+type copyState struct {
+	op       *Operation
+	total    int64
+	callback ProgressCallback
+	links    hardLinks
+	symlinks SymlinkPolicy // Only copies follow or rewrite links
+	roots    []string      // The sources copied, for SymlinksRewriteRelative
+	fixNames bool          // Write entries under FixName's names
+
+	mu   sync.Mutex
+	done int64
+}
+
+// newCopyState starts counting towards total bytes for op.
+func newCopyState(op *Operation, total int64, callback ProgressCallback) *copyState {
+	return &copyState{op: op, total: total, callback: callback}
+}
+
+// add counts n more bytes written, to file.
+func (s *copyState) add(n int64, file string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done += n
+	s.op.UpdateProgress(s.done, s.total, file)
+	if s.callback != nil {
+		s.callback(s.op)
+	}
+}
+
+// destName returns the name an entry called name gets in the copy.
+func (s *copyState) destName(name string) string {
+	if s.fixNames {
+		return FixName(name)
+	}
+	return name
+}
+
+// copyJob is a regular file to copy, from the tree of sources[source].
+type copyJob struct {
+	source   int
+	src, dst string
+}
+
+// copyParallel copies each of sources to the path in dests. Directories
+// and symlinks are created while walking the sources, in order, following
+// links as state.symlinks says, and regular files are handed to
+// copyWorkers workers. The first error stops the rest; copies already
+// started finish. Results are set for the sources copied in full.
+func copyParallel(op *Operation, sources, dests []string, state *copyState) error {
+	ctx, stop := context.WithCancel(op.ctx)
+	defer stop()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		failed   = make([]bool, len(sources)) // Sources not copied in full
+	)
+	fail := func(source int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed[source] = true
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to copy %s: %w", sources[source], err)
+			stop()
+		}
+	}
+
+	jobs := make(chan copyJob)
+	var wg sync.WaitGroup
+	for range copyWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if ctx.Err() != nil {
+					fail(job.source, nil)
+					continue
+				}
+				if err := copyFile(op, job.src, job.dst, state); err != nil {
+					fail(job.source, err)
+				}
+			}
+		}()
+	}
+
+	// walk creates the directories and links of src's tree at dst and
+	// queues its files
+	var walk func(source int, src, dst string) error
+	walk = func(source int, src, dst string) error {
+		if ctx.Err() != nil {
+			return errStopped
+		}
+		info, err := os.Lstat(src)
+		if err != nil {
+			return fmt.Errorf("failed to stat source: %w", err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info = state.symlinks.follow(src); info == nil {
+				return copyLink(src, dst, state)
+			}
+		}
+
+		if !info.IsDir() {
+			select {
+			case jobs <- copyJob{source: source, src: src, dst: dst}:
+				return nil
+			case <-ctx.Done():
+				return errStopped
+			}
+		}
+		if err := os.MkdirAll(dst, info.Mode()); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return fmt.Errorf("failed to read directory: %w", err)
+		}
+		for _, entry := range entries {
+			if err := walk(source, filepath.Join(src, entry.Name()), filepath.Join(dst, state.destName(entry.Name()))); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	walked := 0
+	for i := range sources {
+		if err := walk(i, sources[i], dests[i]); err != nil {
+			if !errors.Is(err, errStopped) {
+				fail(i, err)
+			}
+			break
+		}
+		walked++
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range walked {
+		if !failed[i] {
+			op.setResult(sources[i], dests[i])
+		}
+	}
+	if op.IsCancelled() {
+		return fmt.Errorf("operation cancelled")
+	}
+	return firstErr
+}
@@ -0,0 +1,102 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestCopyMultipleTree(t *testing.T) {
+	tmpDir := t.TempDir()
+	tree := filepath.Join(tmpDir, "tree")
+	var total int64
+	for d := 0; d < 3; d++ {
+		dir := filepath.Join(tree, fmt.Sprintf("dir%d", d), "sub")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for f := 0; f < 20; f++ {
+			content := fmt.Sprintf("file %d in dir %d", f, d)
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.txt", f)), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			total += int64(len(content))
+		}
+	}
+	if err := os.Symlink("dir0", filepath.Join(tree, "link")); err != nil {
+		t.Fatal(err)
+	}
+	single := filepath.Join(tmpDir, "single.txt")
+	if err := os.WriteFile(single, []byte("single"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	total += int64(len("single"))
+
+	dstDir := filepath.Join(tmpDir, "dst")
+	if err := os.Mkdir(dstDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	op := CopyMultiple([]string{tree, single}, dstDir, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("status = %v (%v), want %v", op.GetStatus(), op.Error, StatusCompleted)
+	}
+
+	for d := 0; d < 3; d++ {
+		for f := 0; f < 20; f++ {
+			path := filepath.Join(dstDir, "tree", fmt.Sprintf("dir%d", d), "sub", fmt.Sprintf("%d.txt", f))
+			verifyFileExists(t, path, fmt.Sprintf("file %d in dir %d", f, d))
+		}
+	}
+	verifyFileExists(t, filepath.Join(dstDir, "single.txt"), "single")
+	if target, err := os.Readlink(filepath.Join(dstDir, "tree", "link")); err != nil || target != "dir0" {
+		t.Errorf("copied symlink = %q, %v; want dir0", target, err)
+	}
+
+	if _, done, _, _ := op.GetProgress(); done != total {
+		t.Errorf("progress = %d bytes written, want %d", done, total)
+	}
+	results := op.GetResults()
+	if len(results) != 2 || results[0] != filepath.Join(dstDir, "tree") || results[1] != filepath.Join(dstDir, "single.txt") {
+		t.Errorf("GetResults() = %q, want the copies", results)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestCopyMultipleFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	good := filepath.Join(tmpDir, "good.txt")
+	if err := os.WriteFile(good, []byte("good"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(tmpDir, "bad")
+	if err := os.MkdirAll(filepath.Join(bad, "taken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bad, "taken", "file.txt"), []byte("bad"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file where the copy needs a directory makes copying bad fail
+	dstDir := filepath.Join(tmpDir, "dst")
+	if err := os.MkdirAll(filepath.Join(dstDir, "bad"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, "bad", "taken"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	op := CopyMultiple([]string{good, bad}, dstDir, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusFailed {
+		t.Fatalf("status = %v, want %v", op.GetStatus(), StatusFailed)
+	}
+	results := op.GetResults()
+	if len(results) != 2 || results[0] != filepath.Join(dstDir, "good.txt") || results[1] != "" {
+		t.Errorf("GetResults() = %q, want only good.txt's copy", results)
+	}
+}
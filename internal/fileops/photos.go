@@ -0,0 +1,121 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lawrab/warren/internal/exif"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// photoNameLayout names photos after their capture time. It sorts
+// chronologically and avoids characters that are awkward on other
+// filesystems.
+const photoNameLayout = "2006-01-02_15-04-05"
+
+// ReadTakenTimes fills in the TakenTime of photos that don't have one yet
+// from their EXIF data. Files without a capture time are left alone.
+func ReadTakenTimes(files []models.FileInfo) {
+	for i := range files {
+		f := &files[i]
+		if f.IsDir || !f.TakenTime.IsZero() || !exif.IsPhoto(f.Name) {
+			continue
+		}
+		if data, err := exif.Read(f.Path); err == nil {
+			f.TakenTime = data.Taken
+		}
+	}
+}
+
+// takenOrModTime is the capture time of a photo, or the modification
+// time of anything without one, so photos and other files sort together.
+func takenOrModTime(f models.FileInfo) time.Time {
+	if !f.TakenTime.IsZero() {
+		return f.TakenTime
+	}
+	return f.ModTime
+}
+
+// PhotoRename is one planned rename of a photo to its capture time.
+type PhotoRename struct {
+	From string
+	To   string
+}
+
+// PlanPhotoRenames works out new names for photos from their EXIF capture
+// time, e.g. "2024-05-01_13-45-12.jpg". Photos taken in the same second
+// get "_2", "_3" and so on. Paths without a capture time are returned as
+// skipped; photos already named after theirs are left out.
+func PlanPhotoRenames(paths []string) (renames []PhotoRename, skipped []string) {
+	var photos []takenPhoto
+	for _, path := range paths {
+		data, err := exif.Read(path)
+		if err != nil || data.Taken.IsZero() {
+			skipped = append(skipped, path)
+			continue
+		}
+		photos = append(photos, takenPhoto{path: path, taken: data.Taken})
+	}
+	return planPhotoNames(photos, func(path string) bool {
+		_, err := os.Lstat(path)
+		return err == nil
+	}), skipped
+}
+
+// takenPhoto is a photo path and its capture time.
+type takenPhoto struct {
+	path  string
+	taken time.Time
+}
+
+// planPhotoNames names each photo after its capture time, numbering names
+// already taken by an earlier photo or by another file (exists).
+func planPhotoNames(photos []takenPhoto, exists func(path string) bool) []PhotoRename {
+	var renames []PhotoRename
+	claimed := make(map[string]bool)
+
+	for _, p := range photos {
+		dir := filepath.Dir(p.path)
+		ext := strings.ToLower(filepath.Ext(p.path))
+		base := p.taken.Format(photoNameLayout)
+
+		for n := 1; ; n++ {
+			name := base + ext
+			if n > 1 {
+				name = fmt.Sprintf("%s_%d%s", base, n, ext)
+			}
+			target := filepath.Join(dir, name)
+			if target == p.path {
+				claimed[target] = true
+				break
+			}
+			// Names of files that are being renamed count as taken too, so
+			// the renames can run in any order
+			if claimed[target] || exists(target) {
+				continue
+			}
+			claimed[target] = true
+			renames = append(renames, PhotoRename{From: p.path, To: target})
+			break
+		}
+	}
+	return renames
+}
+
+// RenamePhotos carries out planned renames in order, refusing to replace
+// a file that appeared since they were planned. It stops at the first
+// failure and returns how many photos were renamed.
+func RenamePhotos(renames []PhotoRename) (int, error) {
+	for i, r := range renames {
+		if _, err := os.Lstat(r.To); err == nil {
+			return i, fmt.Errorf("failed to rename %s: %s already exists", filepath.Base(r.From), filepath.Base(r.To))
+		}
+		if err := os.Rename(r.From, r.To); err != nil {
+			return i, fmt.Errorf("failed to rename %s: %w", filepath.Base(r.From), err)
+		}
+	}
+	return len(renames), nil
+}
@@ -0,0 +1,92 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lawrab/warren/pkg/models"
+)
+
+func TestPlanPhotoNames(t *testing.T) {
+	taken := time.Date(2024, 5, 1, 13, 45, 12, 0, time.Local)
+	existing := map[string]bool{
+		"/photos/IMG_1.JPG":                 true,
+		"/photos/IMG_2.jpg":                 true,
+		"/photos/IMG_3.jpg":                 true,
+		"/photos/2024-05-01_13-45-12_3.jpg": true, // Unrelated file
+		"/photos/2024-05-02_08-00-00.jpg":   true,
+		"/photos/2024-05-03_08-00-00.jpeg":  true,
+	}
+	photos := []takenPhoto{
+		{"/photos/IMG_1.JPG", taken},
+		{"/photos/IMG_2.jpg", taken},
+		{"/photos/IMG_3.jpg", taken},
+		{"/photos/2024-05-02_08-00-00.jpg", time.Date(2024, 5, 2, 8, 0, 0, 0, time.Local)},
+	}
+
+	got := planPhotoNames(photos, func(path string) bool { return existing[path] })
+	want := []PhotoRename{
+		{"/photos/IMG_1.JPG", "/photos/2024-05-01_13-45-12.jpg"},
+		{"/photos/IMG_2.jpg", "/photos/2024-05-01_13-45-12_2.jpg"},
+		{"/photos/IMG_3.jpg", "/photos/2024-05-01_13-45-12_4.jpg"},
+		// The last photo already has its name and is left out
+	}
+	if len(got) != len(want) {
+		t.Fatalf("planPhotoNames() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("planPhotoNames()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPlanPhotoRenamesSkipsFilesWithoutExif(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scan.jpg")
+	if err := os.WriteFile(path, []byte{0xFF, 0xD8, 0xFF, 0xD9}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	renames, skipped := PlanPhotoRenames([]string{path})
+	if len(renames) != 0 || len(skipped) != 1 || skipped[0] != path {
+		t.Errorf("PlanPhotoRenames() = %v, %v; want nothing renamed and %s skipped", renames, skipped, path)
+	}
+}
+
+func TestRenamePhotosRefusesToReplace(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "a.jpg")
+	to := filepath.Join(dir, "b.jpg")
+	for _, p := range []string{from, to} {
+		if err := os.WriteFile(p, []byte(p), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := RenamePhotos([]PhotoRename{{From: from, To: to}})
+	if err == nil || n != 0 {
+		t.Fatalf("RenamePhotos() = %d, %v; want an error", n, err)
+	}
+	if data, _ := os.ReadFile(to); string(data) != to {
+		t.Error("RenamePhotos() replaced an existing file")
+	}
+}
+
+func TestSortFilesByTaken(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := []models.FileInfo{
+		{Name: "late.jpg", TakenTime: base.Add(3 * time.Hour), ModTime: base},
+		{Name: "notes.txt", ModTime: base.Add(2 * time.Hour)},
+		{Name: "early.jpg", TakenTime: base.Add(time.Hour), ModTime: base.Add(5 * time.Hour)},
+	}
+
+	SortFiles(files, models.SortByTaken, models.SortAscending)
+	for i, want := range []string{"early.jpg", "notes.txt", "late.jpg"} {
+		if files[i].Name != want {
+			t.Errorf("files[%d] = %s, want %s", i, files[i].Name, want)
+		}
+	}
+}
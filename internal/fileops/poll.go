@@ -0,0 +1,94 @@
+package fileops
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"os"
+	"syscall"
+	"time"
+)
+
+// DefaultPollInterval is how often a polled directory is checked for changes.
+const DefaultPollInterval = 2 * time.Second
+
+// Filesystem magic numbers (statfs f_type) of network and userspace
+// filesystems where inotify misses changes made by other machines or
+// processes outside the kernel's view.
+var pollFilesystems = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x65735546: "fuse",
+	0x564c:     "ncp",
+	0x73757245: "coda",
+	0x01021997: "9p",
+}
+
+// pollFilesystem returns the name of the filesystem holding path if it is
+// one inotify cannot be trusted on, or "" otherwise.
+func pollFilesystem(path string) string {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return ""
+	}
+	return pollFilesystems[uint32(st.Type)]
+}
+
+// pollSignature summarises a directory's entries (names, sizes, modes and
+// modification times) and the directory's own modification time, so any
+// change visible in the listing changes the signature.
+func pollSignature(path string) (uint64, error) {
+	dirInfo, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+
+	h := fnv.New64a()
+	var buf [8]byte
+	writeInt := func(n int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(n))
+		h.Write(buf[:])
+	}
+
+	writeInt(dirInfo.ModTime().UnixNano())
+	for _, entry := range entries {
+		h.Write([]byte(entry.Name()))
+		info, err := entry.Info()
+		if err != nil {
+			// Vanished between ReadDir and Info; the next poll sees it gone
+			continue
+		}
+		writeInt(info.Size())
+		writeInt(int64(info.Mode()))
+		writeInt(info.ModTime().UnixNano())
+	}
+	return h.Sum64(), nil
+}
+
+// pollLoop calls onChange whenever path's signature changes from last,
+// checking every interval until stop is closed.
+func pollLoop(path string, last uint64, interval time.Duration, onChange func(), stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sig, err := pollSignature(path)
+			if err != nil || sig == last {
+				continue
+			}
+			last = sig
+			if onChange != nil {
+				onChange()
+			}
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPollSignature(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "a.txt")
+	if err := os.WriteFile(file, []byte("one"), 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	base, err := pollSignature(tmpDir)
+	if err != nil {
+		t.Fatalf("pollSignature failed: %v", err)
+	}
+	if again, _ := pollSignature(tmpDir); again != base {
+		t.Error("signature changed without any change to the directory")
+	}
+
+	t.Run("content change", func(t *testing.T) {
+		if err := os.WriteFile(file, []byte("longer content"), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if sig, _ := pollSignature(tmpDir); sig == base {
+			t.Error("signature should change when a file's size changes")
+		}
+	})
+
+	t.Run("new entry", func(t *testing.T) {
+		before, _ := pollSignature(tmpDir)
+		if err := os.WriteFile(filepath.Join(tmpDir, "b.txt"), nil, 0600); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if sig, _ := pollSignature(tmpDir); sig == before {
+			t.Error("signature should change when an entry is added")
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := pollSignature(filepath.Join(tmpDir, "missing")); err == nil {
+			t.Error("expected an error for a missing directory")
+		}
+	})
+}
+
+func TestFileWatcherPolling(t *testing.T) {
+	tmpDir := t.TempDir()
+	fw, changed := newTestWatcher(t)
+	fw.SetPollInterval(50 * time.Millisecond)
+	if err := fw.Start(tmpDir); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	// Switch to polling as an inotify overflow would
+	fw.mu.Lock()
+	fw.startPolling()
+	fw.mu.Unlock()
+	if !fw.Polling() {
+		t.Fatal("Polling() = false after falling back to polling")
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "new.txt"), nil, 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if !waitForChange(changed) {
+		t.Fatal("polling should notice a new file")
+	}
+
+	// Reloading the same directory keeps polling
+	if err := fw.Start(tmpDir); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !fw.Polling() {
+		t.Error("restarting on the polled directory should keep polling")
+	}
+
+	// Moving to a directory inotify can watch stops polling
+	if err := fw.Start(t.TempDir()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if fw.Polling() && pollFilesystem(tmpDir) == "" {
+		t.Error("starting on another directory should stop polling")
+	}
+}
@@ -0,0 +1,286 @@
+package fileops
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// TransferInfo describes what a copy or move would do before it starts,
+// so the UI can decide whether to ask for confirmation.
+type TransferInfo struct {
+	// Conflicts lists destination paths that already exist and would be
+	// overwritten
+	Conflicts []string
+
+	// CaseConflicts lists the names that clash on a case-insensitive
+	// destination with an existing name, or another source's, that differs
+	// only in case, which would overwrite or merge with it under the name
+	// already there. They aren't listed in Conflicts as well
+	CaseConflicts []CaseConflict
+
+	// CrossFilesystem is true when a move would have to copy data between
+	// filesystems instead of renaming
+	CrossFilesystem bool
+
+	// TotalBytes is the combined size of all sources
+	TotalBytes int64
+
+	// NeededBytes is how much of TotalBytes has to be written to the
+	// destination filesystem: all of it for a copy, and only what comes
+	// from other filesystems for a move
+	NeededBytes int64
+
+	// FreeBytes is the space available on the destination filesystem, or
+	// -1 if it is unknown
+	FreeBytes int64
+
+	// Symlinks counts the symbolic links in what a copy would copy, which
+	// the copy's SymlinkPolicy applies to. Moves keep links as they are
+	Symlinks int
+
+	// NameFixes lists the entries, sources or inside them, whose names
+	// the destination can't store (see RestrictsNames), with the names
+	// TransferOptions.FixNames would give them. Without fixing, the
+	// transfer fails at the first
+	NameFixes []NameFix
+}
+
+// OutOfSpace reports whether the destination lacks the free space the
+// transfer needs, so it would fail partway through.
+func (t TransferInfo) OutOfSpace() bool {
+	return t.FreeBytes >= 0 && t.NeededBytes > t.FreeBytes
+}
+
+// CheckTransfer inspects sources and the destination directory for a copy
+// or move, including whether the destination has room for it. Sources that
+// are already in the destination directory are not reported as conflicts,
+// since pasting them in place is a no-op for moves. A transfer that can't
+// work at all, such as copying a directory into itself, is an error (see
+// checkTransferPaths).
+func CheckTransfer(sources []string, destination string, move bool) (TransferInfo, error) {
+	info := TransferInfo{FreeBytes: -1}
+
+	dests := make([]string, len(sources))
+	for i, src := range sources {
+		dests[i] = filepath.Join(destination, filepath.Base(src))
+	}
+	if err := checkTransferPaths(sources, dests, move); err != nil {
+		return info, err
+	}
+
+	destDev, err := deviceOf(destination)
+	if err != nil {
+		return info, err
+	}
+	if fs, err := StatFilesystem(destination); err == nil {
+		info.FreeBytes = fs.FreeBytes
+	}
+
+	for _, src := range sources {
+		size, err := treeSize(context.Background(), src, SymlinksPreserve)
+		if err != nil {
+			return info, fmt.Errorf("failed to calculate size for %s: %w", src, err)
+		}
+		info.TotalBytes += size
+
+		destPath := filepath.Join(destination, filepath.Base(src))
+		if destPath != filepath.Clean(src) {
+			if _, err := os.Lstat(destPath); err == nil {
+				info.Conflicts = append(info.Conflicts, destPath)
+			}
+		}
+
+		if !move {
+			info.NeededBytes += size
+			links, err := CountSymlinks([]string{src})
+			if err != nil {
+				return info, err
+			}
+			info.Symlinks += links
+			continue
+		}
+		srcDev, err := deviceOf(src)
+		if err != nil {
+			return info, err
+		}
+		if srcDev != destDev {
+			// Renames within a filesystem take no space
+			info.CrossFilesystem = true
+			info.NeededBytes += size
+		}
+	}
+
+	if RestrictsNames(destination) {
+		if info.NameFixes, err = nameFixes(sources); err != nil {
+			return info, err
+		}
+	}
+
+	if CaseInsensitive(destination) {
+		entries, err := os.ReadDir(destination)
+		if err != nil {
+			return info, fmt.Errorf("failed to read %s: %w", destination, err)
+		}
+		existing := make([]string, len(entries))
+		for i, entry := range entries {
+			existing[i] = entry.Name()
+		}
+		info.CaseConflicts = caseConflicts(sources, existing)
+		info.Conflicts = slices.DeleteFunc(info.Conflicts, func(path string) bool {
+			return slices.ContainsFunc(info.CaseConflicts, func(c CaseConflict) bool {
+				return c.Name == filepath.Base(path)
+			})
+		})
+	}
+
+	return info, nil
+}
+
+// validateTransfer checks, before anything is written, that copying or
+// moving each source to the path beside it in dests can work: see
+// checkTransferPaths and checkWritable. Running out of space is left to
+// CheckTransfer, since how much a transfer needs takes a walk to tell.
+func validateTransfer(sources, dests []string, move bool) error {
+	if err := checkTransferPaths(sources, dests, move); err != nil {
+		return err
+	}
+	dirs := make([]string, 0, len(dests)+len(sources))
+	for _, dst := range dests {
+		dirs = append(dirs, filepath.Dir(dst))
+	}
+	if move {
+		// Moving out of a directory removes an entry from it too
+		for _, src := range sources {
+			dirs = append(dirs, filepath.Dir(filepath.Clean(src)))
+		}
+	}
+	return checkWritable(dirs)
+}
+
+// checkTransferPaths returns an error for the first source a transfer to
+// the path beside it in dests would never finish or would destroy: a
+// directory written into itself or below itself, or a copy onto the very
+// file it reads from. Paths are compared with links resolved. A move onto
+// itself is allowed, as renaming in place does nothing.
+func checkTransferPaths(sources, dests []string, move bool) error {
+	verb := "copy"
+	if move {
+		verb = "move"
+	}
+	for i, src := range sources {
+		srcInfo, err := os.Lstat(src)
+		if err != nil {
+			return err
+		}
+		dst := dests[i]
+		if dstInfo, err := os.Lstat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+			if move {
+				continue
+			}
+			return fmt.Errorf("can't copy %s onto itself", src)
+		}
+		if !srcInfo.IsDir() {
+			continue
+		}
+		realSrc, err := filepath.EvalSymlinks(src)
+		if err != nil {
+			return err
+		}
+		realDir, err := filepath.EvalSymlinks(filepath.Dir(dst))
+		if err != nil {
+			return err
+		}
+		if realDir == realSrc || insideDir(realDir, realSrc) {
+			return fmt.Errorf("can't %s %s into itself", verb, src)
+		}
+	}
+	return nil
+}
+
+// checkWritable returns an error for the first of dirs the user can't add
+// entries to or remove them from. A permission error wraps
+// fs.ErrPermission, so the transfer can still be retried with privileges.
+func checkWritable(dirs []string) error {
+	checked := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		if checked[dir] {
+			continue
+		}
+		checked[dir] = true
+		if err := syscall.Access(dir, accessWrite|accessExecute); err != nil {
+			return fmt.Errorf("can't write to %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// deviceOf returns the ID of the filesystem holding path.
+func deviceOf(path string) (uint64, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("cannot determine filesystem of %s", path)
+	}
+	return uint64(st.Dev), nil //nolint:unconvert // Dev is uint32 on some platforms
+}
+
+// DeleteInfo describes what a delete would remove, so the UI can show it
+// and decide how firmly to ask.
+type DeleteInfo struct {
+	// Items counts the entries removed, including everything inside
+	// directories
+	Items int
+
+	// TotalBytes is the combined size of the files removed
+	TotalBytes int64
+
+	// OutsideHome is true when a path is not inside the home directory
+	// (the home directory itself counts as outside)
+	OutsideHome bool
+}
+
+// CheckDelete measures what deleting paths would remove. Directories are
+// walked without following symlinks, and entries that cannot be read are
+// skipped, so the totals are a lower bound. With home "", every path
+// counts as outside the home directory.
+func CheckDelete(paths []string, home string) DeleteInfo {
+	var info DeleteInfo
+	for _, path := range paths {
+		if !insideDir(path, home) {
+			info.OutsideHome = true
+		}
+		_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Skip unreadable entries. A directory that can't be listed
+				// was already counted before its listing failed
+				return nil
+			}
+			info.Items++
+			if !d.IsDir() {
+				if fi, err := d.Info(); err == nil {
+					info.TotalBytes += fi.Size()
+				}
+			}
+			return nil
+		})
+	}
+	return info
+}
+
+// insideDir reports whether path is below dir.
+func insideDir(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
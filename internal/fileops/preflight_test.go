@@ -0,0 +1,222 @@
+package fileops
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckTransfer(t *testing.T) {
+	srcDir := t.TempDir()
+	destDir := t.TempDir()
+
+	a := filepath.Join(srcDir, "a.txt")
+	b := filepath.Join(srcDir, "b.txt")
+	if err := os.WriteFile(a, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("world!"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(destDir, "b.txt"), []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		sources       []string
+		dest          string
+		move          bool
+		wantConflicts []string
+		wantBytes     int64
+	}{
+		{"no conflicts", []string{a}, destDir, false, nil, 5},
+		{"overwrite", []string{a, b}, destDir, false, []string{filepath.Join(destDir, "b.txt")}, 11},
+		{"same directory move", []string{a}, srcDir, true, nil, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := CheckTransfer(tt.sources, tt.dest, tt.move)
+			if err != nil {
+				t.Fatalf("CheckTransfer() error = %v", err)
+			}
+			if len(info.Conflicts) != len(tt.wantConflicts) {
+				t.Fatalf("Conflicts = %v, want %v", info.Conflicts, tt.wantConflicts)
+			}
+			for i := range tt.wantConflicts {
+				if info.Conflicts[i] != tt.wantConflicts[i] {
+					t.Errorf("Conflicts[%d] = %q, want %q", i, info.Conflicts[i], tt.wantConflicts[i])
+				}
+			}
+			if info.TotalBytes != tt.wantBytes {
+				t.Errorf("TotalBytes = %d, want %d", info.TotalBytes, tt.wantBytes)
+			}
+			// Temporary directories share a filesystem
+			if info.CrossFilesystem {
+				t.Errorf("CrossFilesystem = true, want false")
+			}
+			wantNeeded := tt.wantBytes
+			if tt.move {
+				wantNeeded = 0
+			}
+			if info.NeededBytes != wantNeeded {
+				t.Errorf("NeededBytes = %d, want %d", info.NeededBytes, wantNeeded)
+			}
+			if info.FreeBytes < 0 || info.OutOfSpace() {
+				t.Errorf("FreeBytes = %d, want the free space of the destination", info.FreeBytes)
+			}
+		})
+	}
+}
+
+func TestCheckTransferMissingSource(t *testing.T) {
+	if _, err := CheckTransfer([]string{"/nonexistent/file"}, t.TempDir(), false); err == nil {
+		t.Error("CheckTransfer() with missing source should fail")
+	}
+}
+
+func TestCheckTransferPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "dir")
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(tmpDir, "file.txt")
+	if err := os.WriteFile(file, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hardLink := filepath.Join(sub, "hard.txt")
+	if err := os.Link(file, hardLink); err != nil {
+		t.Fatal(err)
+	}
+	// A link into dir hides that the destination is inside it
+	viaLink := filepath.Join(tmpDir, "link")
+	if err := os.Symlink(sub, viaLink); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		dst     string
+		move    bool
+		wantErr string
+	}{
+		{"copy into itself", dir, filepath.Join(dir, "dir"), false, "into itself"},
+		{"copy below itself", dir, filepath.Join(sub, "dir"), false, "into itself"},
+		{"copy below itself through a link", dir, filepath.Join(viaLink, "dir"), false, "into itself"},
+		{"move below itself", dir, filepath.Join(sub, "dir"), true, "into itself"},
+		{"copy file onto itself", file, file, false, "onto itself"},
+		{"copy directory onto itself", dir, dir, false, "onto itself"},
+		{"copy onto a hard link", file, hardLink, false, "onto itself"},
+		{"move in place", file, file, true, ""},
+		{"copy beside itself", dir, filepath.Join(tmpDir, "dir2"), false, ""},
+		{"copy file into a sibling", file, filepath.Join(dir, "file.txt"), false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTransferPaths([]string{tt.src}, []string{tt.dst}, tt.move)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkTransferPaths() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkTransferPaths() error = %v, want one saying %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// CheckTransfer reports the same before the paste is confirmed
+	if _, err := CheckTransfer([]string{dir}, sub, false); err == nil {
+		t.Error("CheckTransfer() copying a directory into itself should fail")
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to any directory")
+	}
+	dir := t.TempDir()
+	if err := checkWritable([]string{dir}); err != nil {
+		t.Fatalf("checkWritable() error = %v, want nil", err)
+	}
+	if err := os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0700) })
+	err := checkWritable([]string{dir})
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("checkWritable() error = %v, want a permission error", err)
+	}
+}
+
+func TestTransferInfoOutOfSpace(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     TransferInfo
+		expected bool
+	}{
+		{"fits", TransferInfo{NeededBytes: 10, FreeBytes: 10}, false},
+		{"too big", TransferInfo{NeededBytes: 11, FreeBytes: 10}, true},
+		{"full disk", TransferInfo{NeededBytes: 1, FreeBytes: 0}, true},
+		{"unknown free space", TransferInfo{NeededBytes: 11, FreeBytes: -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.OutOfSpace(); got != tt.expected {
+				t.Errorf("OutOfSpace() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckDelete(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(home, "dir")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "b.txt"), []byte("world!"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Links are counted, not followed
+	if err := os.Symlink(home, filepath.Join(dir, "up")); err != nil {
+		t.Fatal(err)
+	}
+
+	info := CheckDelete([]string{dir}, home)
+	if info.Items != 5 || info.OutsideHome {
+		t.Errorf("CheckDelete() = %+v, want 5 items inside home", info)
+	}
+	if info.TotalBytes < 11 {
+		t.Errorf("TotalBytes = %d, want at least the 11 bytes of the files", info.TotalBytes)
+	}
+
+	for _, tt := range []struct {
+		path string
+		home string
+		want bool
+	}{
+		{dir, home, false},
+		{home, home, true},
+		{filepath.Dir(home), home, true},
+		{home + "-other", home, true},
+		{dir, "", true},
+	} {
+		if got := CheckDelete([]string{tt.path}, tt.home).OutsideHome; got != tt.want {
+			t.Errorf("CheckDelete(%s) with home %q: OutsideHome = %v, want %v", tt.path, tt.home, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package fileops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Exit statuses of pkexec when the user dismisses the authentication
+// dialog or is not authorised.
+const (
+	pkexecDismissed    = 126
+	pkexecUnauthorised = 127
+)
+
+// ErrNoPkexec is returned by RetryPrivileged when pkexec (polkit) is not
+// installed.
+var ErrNoPkexec = errors.New("pkexec is not installed")
+
+// NeedsPrivileges reports whether op failed because the user lacks
+// permission, so RetryPrivileged may succeed where it did not.
+func NeedsPrivileges(op *Operation) bool {
+	if op.Status != StatusFailed || !errors.Is(op.Error, fs.ErrPermission) {
+		return false
+	}
+	switch op.Type {
+	case OpCopy, OpMove, OpDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// PrivilegedCommand returns the command line that redoes op as root: pkexec
+// running helper (the warren executable) with the matching headless
+// subcommand, so only that one operation runs privileged.
+func PrivilegedCommand(helper string, op *Operation) ([]string, error) {
+	var name string
+	args := op.Source
+	switch op.Type {
+	case OpCopy:
+		name = "cp"
+		args = append(args[:len(args):len(args)], op.Destination)
+	case OpMove:
+		name = "mv"
+		args = append(args[:len(args):len(args)], op.Destination)
+	case OpDelete:
+		name = "rm"
+	default:
+		return nil, fmt.Errorf("cannot run %s as administrator", strings.ToLower(op.Type.String()))
+	}
+	if len(op.Source) == 0 {
+		return nil, fmt.Errorf("nothing to %s", strings.ToLower(op.Type.String()))
+	}
+
+	// "--" keeps paths starting with a dash from being read as flags
+	argv := []string{"pkexec", helper, name, "-q", "--"}
+	return append(argv, args...), nil
+}
+
+// RetryPrivileged redoes a failed copy, move or delete as root in the
+// background, through pkexec, which asks the user to authenticate. It
+// returns a new operation for the same files. Progress is not reported,
+// and the operation cannot be cancelled once the user has authenticated.
+// Dismissing the authentication dialog cancels it.
+func RetryPrivileged(failed *Operation, callback ProgressCallback) *Operation {
+	op := NewOperation(failed.Type, failed.Source, failed.Destination)
+	return defaultQueue.submit(op, func() { performPrivileged(op, callback) }, callback)
+}
+
+// performPrivileged executes RetryPrivileged.
+func performPrivileged(op *Operation, callback ProgressCallback) {
+	op.SetStatus(StatusRunning)
+	if err := runPrivileged(op); err != nil {
+		op.SetError(err)
+	} else if op.IsCancelled() {
+		op.SetStatus(StatusCancelled)
+	} else {
+		op.SetStatus(StatusCompleted)
+	}
+	if callback != nil {
+		callback(op)
+	}
+}
+
+// runPrivileged runs the pkexec helper for op and waits for it.
+func runPrivileged(op *Operation) error {
+	if _, err := exec.LookPath("pkexec"); err != nil {
+		return ErrNoPkexec
+	}
+	helper, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the warren executable: %w", err)
+	}
+	argv, err := PrivilegedCommand(helper, op)
+	if err != nil {
+		return err
+	}
+
+	// #nosec G204 -- runs this executable with the operation's own paths
+	cmd := exec.Command(argv[0], argv[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exit) && exit.ExitCode() == pkexecDismissed:
+		op.Cancel()
+		return nil
+	case errors.As(err, &exit) && exit.ExitCode() == pkexecUnauthorised:
+		return errors.New("not authorised to run as administrator")
+	}
+
+	// The helper prints "warren <cmd>: <error>" as its last line
+	if lines := strings.Split(strings.TrimSpace(stderr.String()), "\n"); lines[len(lines)-1] != "" {
+		return errors.New(lines[len(lines)-1])
+	}
+	return fmt.Errorf("failed to run as administrator: %w", err)
+}
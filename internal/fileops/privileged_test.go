@@ -0,0 +1,85 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"testing"
+)
+
+func TestNeedsPrivileges(t *testing.T) {
+	denied := fmt.Errorf("failed to delete: %w", fs.ErrPermission)
+	tests := []struct {
+		name     string
+		opType   OperationType
+		status   OperationStatus
+		err      error
+		expected bool
+	}{
+		{"denied delete", OpDelete, StatusFailed, denied, true},
+		{"denied copy", OpCopy, StatusFailed, denied, true},
+		{"other error", OpDelete, StatusFailed, fs.ErrNotExist, false},
+		{"denied rename", OpRename, StatusFailed, denied, false},
+		{"completed", OpMove, StatusCompleted, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := NewOperation(tt.opType, []string{"/etc/a"}, "")
+			op.Status = tt.status
+			op.Error = tt.err
+			if got := NeedsPrivileges(op); got != tt.expected {
+				t.Errorf("NeedsPrivileges() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrivilegedCommand(t *testing.T) {
+	sources := []string{"/etc/a", "-b"}
+	tests := []struct {
+		name     string
+		op       *Operation
+		expected []string
+	}{
+		{"copy", NewOperation(OpCopy, sources, "/etc/x"),
+			[]string{"pkexec", "/bin/warren", "cp", "-q", "--", "/etc/a", "-b", "/etc/x"}},
+		{"move", NewOperation(OpMove, sources, "/etc/x"),
+			[]string{"pkexec", "/bin/warren", "mv", "-q", "--", "/etc/a", "-b", "/etc/x"}},
+		{"delete", NewOperation(OpDelete, sources, ""),
+			[]string{"pkexec", "/bin/warren", "rm", "-q", "--", "/etc/a", "-b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argv, err := PrivilegedCommand("/bin/warren", tt.op)
+			if err != nil {
+				t.Fatalf("PrivilegedCommand() error = %v", err)
+			}
+			if !slices.Equal(argv, tt.expected) {
+				t.Errorf("PrivilegedCommand() = %q, want %q", argv, tt.expected)
+			}
+		})
+	}
+	if !slices.Equal(sources, []string{"/etc/a", "-b"}) {
+		t.Errorf("PrivilegedCommand() changed the sources to %q", sources)
+	}
+
+	if _, err := PrivilegedCommand("/bin/warren", NewOperation(OpRename, sources, "/etc/x")); err == nil {
+		t.Error("PrivilegedCommand() should refuse a rename")
+	}
+}
+
+func TestRetryPrivilegedWithoutPkexec(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	failed := NewOperation(OpDelete, []string{"/etc/a"}, "")
+	done := make(chan *Operation, 1)
+	RetryPrivileged(failed, func(op *Operation) { done <- op })
+
+	op := <-done
+	if op.Status != StatusFailed || !errors.Is(op.Error, ErrNoPkexec) {
+		t.Errorf("RetryPrivileged() = %v (%v), want failure with ErrNoPkexec", op.Status, op.Error)
+	}
+}
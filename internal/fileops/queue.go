@@ -1,26 +1,87 @@
 package fileops
 
 import (
+	"path/filepath"
+	"slices"
 	"sync"
 )
 
+// DefaultMaxConcurrent is how many operations the default queue runs at
+// once.
+const DefaultMaxConcurrent = 4
+
+// defaultHistory is how many finished operations the default queue
+// remembers.
+const defaultHistory = 100
+
+// defaultQueue runs the package-level operations (Copy, Move, …).
+var defaultQueue = newDefaultQueue()
+
+// newDefaultQueue creates the queue behind DefaultQueue.
+func newDefaultQueue() *OperationQueue {
+	q := NewQueue(DefaultMaxConcurrent)
+	q.MaxHistory = defaultHistory
+	return q
+}
+
+// DefaultQueue returns the queue the package-level operations run on, for
+// looking up their place in line.
+func DefaultQueue() *OperationQueue {
+	return defaultQueue
+}
+
+// Priority orders the operations waiting in an OperationQueue.
+type Priority int
+
+const (
+	// PriorityLow operations wait for all others
+	PriorityLow Priority = -1
+	// PriorityNormal is the default
+	PriorityNormal Priority = 0
+	// PriorityHigh operations go ahead of all others
+	PriorityHigh Priority = 1
+)
+
 // OperationQueue manages a queue of file operations.
-// It limits concurrent operations and provides status tracking.
+// It limits concurrent operations and provides status tracking. Waiting
+// operations are started in priority order, first come first served
+// within a priority. Operations writing to the same device run one at a
+// time, so spinning disks don't thrash between them, while operations on
+// different devices run in parallel.
 type OperationQueue struct {
 	// MaxConcurrent is the maximum number of concurrent operations
 	MaxConcurrent int
 
+	// MaxHistory is how many finished operations are kept for GetAll and
+	// GetCompleted, oldest dropped first; 0 keeps them all
+	MaxHistory int
+
 	// operations stores all operations (pending, running, completed)
 	operations []*Operation
 
+	// pending holds operations waiting to start, in the order they will
+	pending []*Operation
+
 	// running tracks currently running operations
 	running map[string]*Operation
 
+	// jobs holds how to start each waiting or running operation, and busy
+	// marks devices with a running operation
+	jobs map[string]queuedJob
+	busy map[uint64]bool
+
+	// events forwards the events of the queue's operations (see Subscribe)
+	events subscribers
+
 	// mutex protects concurrent access
 	mu sync.RWMutex
+}
 
-	// sem limits concurrent operations
-	sem chan struct{}
+// queuedJob is an operation's entry in the queue.
+type queuedJob struct {
+	start    func()
+	callback ProgressCallback
+	device   uint64 // written to by the operation, 0 if unknown
 }
 
 // NewQueue creates a new operation queue.
@@ -33,49 +94,182 @@ func NewQueue(maxConcurrent int) *OperationQueue {
 		MaxConcurrent: maxConcurrent,
 		operations:    make([]*Operation, 0),
 		running:       make(map[string]*Operation),
-		sem:           make(chan struct{}, maxConcurrent),
+		jobs:          make(map[string]queuedJob),
+		busy:          make(map[uint64]bool),
 	}
 }
 
-// Add adds an operation to the queue and starts it if possible.
+// Add adds an operation that was started elsewhere to the queue, which
+// tracks it and counts it against its limits once it would have started.
+// Use Submit to have the queue start the operation.
 func (q *OperationQueue) Add(op *Operation) {
+	q.Submit(op, nil, nil)
+}
+
+// Submit adds a pending operation to the queue and has start run it when
+// its turn comes, in a goroutine of the queue's. The operation holds its
+// slot until it ends (see Operation.Done). If it is cancelled while
+// waiting, start is never called and callback, if not nil, is told
+// instead.
+func (q *OperationQueue) Submit(op *Operation, start func(), callback ProgressCallback) {
+	job := queuedJob{start: start, callback: callback, device: operationDevice(op)}
+	op.Subscribe(func(ev Event) { q.events.send(ev, false) })
+
 	q.mu.Lock()
+	defer q.mu.Unlock()
 	q.operations = append(q.operations, op)
-	q.mu.Unlock()
+	q.jobs[op.ID] = job
 
-	go q.runOperation(op)
+	// Behind everything of the same or higher priority
+	i := slices.IndexFunc(q.pending, func(p *Operation) bool {
+		return p.Priority < op.Priority
+	})
+	if i < 0 {
+		i = len(q.pending)
+	}
+	q.pending = slices.Insert(q.pending, i, op)
+	q.dispatch()
+	go q.watch(op)
 }
 
-// runOperation runs an operation when a slot is available.
-func (q *OperationQueue) runOperation(op *Operation) {
-	// Wait for available slot
-	q.sem <- struct{}{}
-	defer func() { <-q.sem }()
+// submit is Submit for the queue's own operations, returning op. Their
+// workers end them and tell callback before returning, so op is held
+// until start returns: waiting for Done then also waits for the last
+// callback.
+func (q *OperationQueue) submit(op *Operation, start func(), callback ProgressCallback) *Operation {
+	op.hold()
+	q.Submit(op, func() {
+		defer op.release()
+		start()
+	}, callback)
+	return op
+}
 
+// watch drops op from the waiting line if it is cancelled before it
+// starts, however it was cancelled.
+func (q *OperationQueue) watch(op *Operation) {
+	<-op.Done()
 	q.mu.Lock()
-	q.running[op.ID] = op
-	q.mu.Unlock()
+	defer q.mu.Unlock()
+	if slices.Contains(q.pending, op) {
+		q.dispatch()
+	}
+}
 
-	// Wait for operation to complete
-	// The operation goroutine will update its status
-waitLoop:
-	for {
-		status := op.Status
-		if status == StatusCompleted || status == StatusFailed || status == StatusCancelled {
-			break waitLoop
+// dispatch drops cancelled operations from the waiting line and starts
+// waiting operations while there are free slots, skipping those whose
+// device is busy. The caller must hold q.mu.
+func (q *OperationQueue) dispatch() {
+	q.pending = slices.DeleteFunc(q.pending, func(op *Operation) bool {
+		if !op.IsCancelled() {
+			return false
+		}
+		if callback := q.jobs[op.ID].callback; callback != nil {
+			go callback(op)
+		}
+		delete(q.jobs, op.ID)
+		return true
+	})
+
+	for i := 0; i < len(q.pending) && len(q.running) < q.MaxConcurrent; {
+		op := q.pending[i]
+		job := q.jobs[op.ID]
+		if job.device != 0 && q.busy[job.device] {
+			i++
+			continue
+		}
+
+		q.pending = slices.Delete(q.pending, i, i+1)
+		q.running[op.ID] = op
+		if job.device != 0 {
+			q.busy[job.device] = true
 		}
-		// Small sleep to avoid busy waiting
-		// In production, consider using channels or condition variables
-		select {
-		case <-op.ctx.Done():
-			break waitLoop
-		default:
+		go q.runOperation(op, job)
+	}
+}
+
+// runOperation starts an operation, if the queue is to start it, and
+// waits for it to end before starting the next ones.
+func (q *OperationQueue) runOperation(op *Operation, job queuedJob) {
+	switch {
+	case job.start == nil:
+	case op.IsCancelled():
+		// Cancelled on its way out of the waiting line
+		if job.callback != nil {
+			job.callback(op)
 		}
+	default:
+		job.start()
 	}
+	<-op.Done()
 
 	q.mu.Lock()
+	defer q.mu.Unlock()
 	delete(q.running, op.ID)
-	q.mu.Unlock()
+	delete(q.busy, q.jobs[op.ID].device)
+	delete(q.jobs, op.ID)
+	q.prune()
+	q.dispatch()
+}
+
+// prune forgets the oldest finished operations beyond MaxHistory. The
+// caller must hold q.mu.
+func (q *OperationQueue) prune() {
+	if q.MaxHistory <= 0 {
+		return
+	}
+	finished := 0
+	for _, op := range q.operations {
+		if _, ok := q.jobs[op.ID]; !ok {
+			finished++
+		}
+	}
+	q.operations = slices.DeleteFunc(q.operations, func(op *Operation) bool {
+		if _, ok := q.jobs[op.ID]; ok || finished <= q.MaxHistory {
+			return false
+		}
+		finished--
+		return true
+	})
+}
+
+// Position returns where the operation with the given ID is in the line
+// of waiting operations, starting from 1, or 0 if it isn't waiting.
+func (q *OperationQueue) Position(id string) int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return slices.IndexFunc(q.pending, func(op *Operation) bool {
+		return op.ID == id
+	}) + 1
+}
+
+// operationDevice returns the ID of the filesystem an operation writes
+// data to: a copy or move's destination, or the first file being shredded.
+// A destination that doesn't exist yet is on its closest existing parent's
+// filesystem. It returns 0 for operations that only change metadata, such
+// as renames and deletes, which need not wait for the device.
+func operationDevice(op *Operation) uint64 {
+	var path string
+	switch {
+	case op.Type == OpCopy || op.Type == OpMove:
+		path = op.Destination
+	case op.Type == OpShred && len(op.Source) > 0:
+		path = op.Source[0]
+	}
+	if path == "" {
+		return 0
+	}
+
+	for {
+		if device, err := deviceOf(path); err == nil {
+			return device
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return 0
+		}
+		path = parent
+	}
 }
 
 // Get returns an operation by ID.
@@ -113,18 +307,26 @@ func (q *OperationQueue) GetRunning() []*Operation {
 	return result
 }
 
-// GetPending returns all pending operations.
-func (q *OperationQueue) GetPending() []*Operation {
+// Busy reports whether a running operation affects dir (see
+// Operation.Affects), for holding off reloads of a listing it is still
+// changing.
+func (q *OperationQueue) Busy(dir string) bool {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
-
-	result := make([]*Operation, 0)
 	for _, op := range q.operations {
-		if op.Status == StatusPending {
-			result = append(result, op)
+		if op.GetStatus() == StatusRunning && op.Affects(dir) {
+			return true
 		}
 	}
-	return result
+	return false
+}
+
+// GetPending returns the operations waiting to start, in the order they
+// will.
+func (q *OperationQueue) GetPending() []*Operation {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return slices.Clone(q.pending)
 }
 
 // GetCompleted returns all completed operations.
@@ -134,7 +336,7 @@ func (q *OperationQueue) GetCompleted() []*Operation {
 
 	result := make([]*Operation, 0)
 	for _, op := range q.operations {
-		if op.Status == StatusCompleted {
+		if op.GetStatus() == StatusCompleted {
 			result = append(result, op)
 		}
 	}
@@ -144,11 +346,16 @@ func (q *OperationQueue) GetCompleted() []*Operation {
 // Cancel cancels an operation by ID.
 func (q *OperationQueue) Cancel(id string) bool {
 	op := q.Get(id)
-	if op != nil {
-		op.Cancel()
-		return true
+	if op == nil {
+		return false
 	}
-	return false
+	op.Cancel()
+
+	// Drop it from the waiting line
+	q.mu.Lock()
+	q.dispatch()
+	q.mu.Unlock()
+	return true
 }
 
 // CancelAll cancels all pending and running operations.
@@ -157,10 +364,11 @@ func (q *OperationQueue) CancelAll() {
 	defer q.mu.Unlock()
 
 	for _, op := range q.operations {
-		if op.Status == StatusPending || op.Status == StatusRunning {
+		if status := op.GetStatus(); status == StatusPending || status == StatusRunning {
 			op.Cancel()
 		}
 	}
+	q.dispatch()
 }
 
 // Clear removes completed, failed, and cancelled operations from history.
@@ -170,7 +378,7 @@ func (q *OperationQueue) Clear() {
 
 	active := make([]*Operation, 0)
 	for _, op := range q.operations {
-		if op.Status == StatusPending || op.Status == StatusRunning {
+		if status := op.GetStatus(); status == StatusPending || status == StatusRunning {
 			active = append(active, op)
 		}
 	}
@@ -190,3 +398,69 @@ func (q *OperationQueue) RunningCount() int {
 	defer q.mu.RUnlock()
 	return len(q.running)
 }
+
+// Copy queues a copy from source to destination.
+// It supports copying files and directories recursively.
+func (q *OperationQueue) Copy(source string, destination string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpCopy, []string{source}, destination)
+	return q.submit(op, func() { performCopy(op, source, destination, SymlinksPreserve, callback) }, callback)
+}
+
+// CopyMultiple queues a copy of multiple files/directories to a
+// destination directory.
+func (q *OperationQueue) CopyMultiple(sources []string, destination string, callback ProgressCallback) *Operation {
+	return q.CopyMultipleWith(sources, destination, TransferOptions{}, callback)
+}
+
+// CopyMultipleWith is CopyMultiple adjusted by opts.
+func (q *OperationQueue) CopyMultipleWith(sources []string, destination string, opts TransferOptions, callback ProgressCallback) *Operation {
+	op := NewOperation(OpCopy, sources, destination)
+	return q.submit(op, func() { performCopyMultiple(op, sources, destination, opts, callback) }, callback)
+}
+
+// Move queues a move from source to destination.
+func (q *OperationQueue) Move(source string, destination string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpMove, []string{source}, destination)
+	return q.submit(op, func() { performMove(op, source, destination, callback) }, callback)
+}
+
+// MoveMultiple queues a move of multiple files/directories to a
+// destination directory.
+func (q *OperationQueue) MoveMultiple(sources []string, destination string, callback ProgressCallback) *Operation {
+	return q.MoveMultipleWith(sources, destination, TransferOptions{}, callback)
+}
+
+// MoveMultipleWith is MoveMultiple adjusted by opts.
+func (q *OperationQueue) MoveMultipleWith(sources []string, destination string, opts TransferOptions, callback ProgressCallback) *Operation {
+	op := NewOperation(OpMove, sources, destination)
+	return q.submit(op, func() { performMoveMultiple(op, sources, destination, opts, callback) }, callback)
+}
+
+// MovePaths queues moving each of sources to the path at the same index
+// in dests, as undoing a move or rename does. It fails without moving
+// anything if a destination exists.
+func (q *OperationQueue) MovePaths(sources, dests []string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpMove, sources, "")
+	return q.submit(op, func() { performMovePaths(op, sources, dests, callback) }, callback)
+}
+
+// Delete queues deleting path. Deletes go ahead of waiting transfers.
+func (q *OperationQueue) Delete(path string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpDelete, []string{path}, "")
+	op.Priority = PriorityHigh
+	return q.submit(op, func() { performDelete(op, path, callback) }, callback)
+}
+
+// DeleteMultiple queues deleting multiple files/directories.
+func (q *OperationQueue) DeleteMultiple(paths []string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpDelete, paths, "")
+	op.Priority = PriorityHigh
+	return q.submit(op, func() { performDeleteMultiple(op, paths, callback) }, callback)
+}
+
+// Rename queues a rename. Renames go ahead of waiting transfers.
+func (q *OperationQueue) Rename(oldPath string, newPath string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpRename, []string{oldPath}, newPath)
+	op.Priority = PriorityHigh
+	return q.submit(op, func() { performRename(op, oldPath, newPath, callback) }, callback)
+}
@@ -245,3 +245,186 @@ func TestQueue_Concurrency(t *testing.T) {
 		t.Errorf("Completed count = %d, want 5", len(completed))
 	}
 }
+
+func TestQueue_Priority(t *testing.T) {
+	q := NewQueue(1)
+	dir := t.TempDir()
+
+	first := NewOperation(OpCopy, []string{"/src"}, dir)
+	q.Add(first)
+	low := NewOperation(OpCopy, []string{"/src"}, dir)
+	low.Priority = PriorityLow
+	q.Add(low)
+	normal := NewOperation(OpCopy, []string{"/src"}, dir)
+	q.Add(normal)
+	high := NewOperation(OpCopy, []string{"/src"}, dir)
+	high.Priority = PriorityHigh
+	q.Add(high)
+	normal2 := NewOperation(OpCopy, []string{"/src"}, dir)
+	q.Add(normal2)
+	defer q.CancelAll()
+
+	if q.Position(first.ID) != 0 {
+		t.Errorf("Position(first) = %d, want 0 (running)", q.Position(first.ID))
+	}
+	for i, op := range []*Operation{high, normal, normal2, low} {
+		if got := q.Position(op.ID); got != i+1 {
+			t.Errorf("Position(%d) = %d, want %d", i, got, i+1)
+		}
+	}
+
+	q.Cancel(high.ID)
+	if got := q.Position(normal.ID); got != 1 {
+		t.Errorf("Position(normal) after cancelling high = %d, want 1", got)
+	}
+}
+
+func TestQueue_SerializePerDevice(t *testing.T) {
+	q := NewQueue(3)
+	dir := t.TempDir()
+
+	a := NewOperation(OpCopy, []string{"/src"}, filepath.Join(dir, "a"))
+	b := NewOperation(OpCopy, []string{"/src"}, filepath.Join(dir, "b"))
+	other := NewOperation(OpCopy, []string{"/src"}, "/proc")
+	q.Add(a)
+	q.Add(b)
+	q.Add(other)
+	defer q.CancelAll()
+
+	// b waits for a, which writes to the same device; other doesn't
+	if got := q.RunningCount(); got != 2 {
+		t.Errorf("RunningCount() = %d, want 2", got)
+	}
+	if got := q.Position(b.ID); got != 1 {
+		t.Errorf("Position(b) = %d, want 1", got)
+	}
+}
+
+func TestQueue_SubmitGatesStart(t *testing.T) {
+	q := NewQueue(1)
+	dir := t.TempDir()
+
+	first := NewOperation(OpCopy, []string{"/src"}, dir)
+	firstStarted := make(chan struct{})
+	q.Submit(first, func() { close(firstStarted) }, nil)
+	<-firstStarted
+
+	second := NewOperation(OpCopy, []string{"/src"}, dir)
+	secondStarted := make(chan struct{})
+	q.Submit(second, func() {
+		close(secondStarted)
+		second.SetStatus(StatusCompleted)
+	}, nil)
+
+	select {
+	case <-secondStarted:
+		t.Fatal("second operation started while the first was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	first.SetStatus(StatusCompleted)
+	waitForOperation(t, second, 5*time.Second)
+}
+
+func TestQueue_SubmitCancelledWhileWaiting(t *testing.T) {
+	q := NewQueue(1)
+	dir := t.TempDir()
+
+	first := NewOperation(OpCopy, []string{"/src"}, dir)
+	q.Submit(first, nil, nil)
+	defer first.Cancel()
+
+	waiting := NewOperation(OpCopy, []string{"/src"}, dir)
+	told := make(chan *Operation, 1)
+	q.Submit(waiting, func() {
+		t.Error("start called for an operation cancelled while waiting")
+	}, func(op *Operation) { told <- op })
+
+	q.Cancel(waiting.ID)
+	select {
+	case op := <-told:
+		if op.GetStatus() != StatusCancelled {
+			t.Errorf("callback status = %v, want %v", op.GetStatus(), StatusCancelled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not called for the cancelled operation")
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestQueue_CopyWaitsForDispatch(t *testing.T) {
+	q := NewQueue(1)
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.txt")
+	if err := os.WriteFile(src, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	blocker := NewOperation(OpCopy, []string{"/src"}, dir)
+	q.Submit(blocker, func() {}, nil)
+
+	op := q.Copy(src, filepath.Join(dir, "dest.txt"), nil)
+	if op.GetStatus() != StatusPending || q.Position(op.ID) != 1 {
+		t.Fatalf("queued copy = %v at position %d, want Pending at 1", op.GetStatus(), q.Position(op.ID))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dest.txt")); !os.IsNotExist(err) {
+		t.Fatal("queued copy ran before it was dispatched")
+	}
+
+	blocker.SetStatus(StatusCompleted)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Errorf("copy status = %v (%v), want %v", op.GetStatus(), op.Error, StatusCompleted)
+	}
+	verifyFileExists(t, filepath.Join(dir, "dest.txt"), "test")
+}
+
+func TestQueue_MaxHistory(t *testing.T) {
+	q := NewQueue(1)
+	q.MaxHistory = 2
+
+	var ops []*Operation
+	for range 4 {
+		op := NewOperation(OpRename, []string{"/a"}, "/b")
+		q.Submit(op, func() { op.SetStatus(StatusCompleted) }, nil)
+		ops = append(ops, op)
+	}
+	for _, op := range ops {
+		waitForOperation(t, op, 5*time.Second)
+	}
+
+	// The last one is forgotten only once the queue has seen it finish
+	deadline := time.Now().Add(5 * time.Second)
+	for q.Count() > 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if all := q.GetAll(); len(all) != 2 || all[0] != ops[2] || all[1] != ops[3] {
+		t.Errorf("GetAll() kept %d operations, want the last 2", len(all))
+	}
+}
+
+func TestQueue_Busy(t *testing.T) {
+	q := NewQueue(1)
+	dir := t.TempDir()
+
+	op := NewOperation(OpCopy, []string{"/src/a"}, dir)
+	started := make(chan struct{})
+	q.Submit(op, func() {
+		op.SetStatus(StatusRunning)
+		close(started)
+	}, nil)
+	<-started
+
+	if !q.Busy(dir) {
+		t.Error("Busy() = false while copying into the directory")
+	}
+	if q.Busy("/src") {
+		t.Error("Busy() = true for the directory copied from")
+	}
+
+	op.SetStatus(StatusCompleted)
+	waitForOperation(t, op, 5*time.Second)
+	if q.Busy(dir) {
+		t.Error("Busy() = true after the copy finished")
+	}
+}
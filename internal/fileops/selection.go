@@ -0,0 +1,48 @@
+package fileops
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// SelectionSize returns the combined size of paths, descending into
+// directories. Symlinks are counted by their own size rather than followed.
+// Entries that vanish or cannot be read are skipped, so the total is a best
+// effort like du's. The only error returned is ctx's, when the walk is
+// cancelled before it finishes.
+func SelectionSize(ctx context.Context, paths []string) (int64, error) {
+	var total int64
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil || d.IsDir() {
+				// Skip unreadable entries; a nil error on a directory descends
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// FormatSelection describes a multi-file selection for the status bar
+// ("3 items, 1.2 GB"). A negative size means it is not known yet and only
+// the count is shown.
+func FormatSelection(count int, bytes int64, format SizeFormat) string {
+	if bytes < 0 {
+		return FormatItemCount(count)
+	}
+	return fmt.Sprintf("%s, %s", FormatItemCount(count), FormatSizeAs(bytes, format))
+}
@@ -0,0 +1,90 @@
+package fileops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectionSize(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, size int) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	file := write("a.txt", 100)
+	write("sub/b.txt", 200)
+	write("sub/deeper/c.txt", 300)
+	sub := filepath.Join(dir, "sub")
+	missing := filepath.Join(dir, "gone")
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  int64
+	}{
+		{"single file", []string{file}, 100},
+		{"directory is recursive", []string{sub}, 500},
+		{"files and directories", []string{file, sub}, 600},
+		{"missing paths are skipped", []string{file, missing}, 100},
+		{"empty selection", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectionSize(context.Background(), tt.paths)
+			if err != nil {
+				t.Fatalf("SelectionSize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SelectionSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectionSize_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := SelectionSize(ctx, []string{dir}); !errors.Is(err, context.Canceled) {
+		t.Errorf("SelectionSize() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestFormatSelection(t *testing.T) {
+	tests := []struct {
+		name   string
+		count  int
+		bytes  int64
+		format SizeFormat
+		want   string
+	}{
+		{"binary", 3, 1572864, SizeBinary, "3 items, 1.5 MB"},
+		{"decimal", 2, 2500000000, SizeDecimal, "2 items, 2.5 GB"},
+		{"empty files", 2, 0, SizeBytes, "2 items, 0 B"},
+		{"size unknown", 4, -1, SizeBinary, "4 items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatSelection(tt.count, tt.bytes, tt.format); got != tt.want {
+				t.Errorf("FormatSelection(%d, %d) = %q, want %q", tt.count, tt.bytes, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package fileops
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// fallocate(2) mode bits for punching holes; not exported by syscall.
+const (
+	fallocKeepSize  = 0x01
+	fallocPunchHole = 0x02
+)
+
+// MaxShredPasses is the most overwrite passes Shred accepts.
+const MaxShredPasses = 35
+
+// copyOnWriteFilesystems maps statfs magic numbers to the names of
+// filesystems that write changed blocks to new locations, and of
+// filesystems layered over others, where overwriting a file in place
+// leaves the old data on disk.
+var copyOnWriteFilesystems = map[uint32]string{
+	0x9123683e: "btrfs",
+	0x2fc12fc1: "zfs",
+	0xca451a4e: "bcachefs",
+	0xf2f52010: "f2fs",
+	0x3434:     "nilfs",
+	0x794c7630: "overlayfs",
+}
+
+// CopyOnWriteFilesystem returns the name of the filesystem holding path if
+// shredding there is only best-effort, or "" otherwise.
+func CopyOnWriteFilesystem(path string) string {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return ""
+	}
+	return copyOnWriteFilesystems[uint32(st.Type)]
+}
+
+// Shred overwrites the contents of files before deleting them, so they
+// cannot be read back from the disk with undelete tools. Each regular file
+// is overwritten with random data passes times, synced to disk after every
+// pass, truncated and then unlinked. With passes 0 its blocks are instead
+// released with fallocate's PUNCH_HOLE, which on SSDs lets the drive
+// discard them, falling back to one overwrite where unsupported.
+// Directories are shredded recursively; symlinks and special files are
+// unlinked without touching what they point to.
+//
+// This is best-effort. On copy-on-write filesystems (see
+// CopyOnWriteFilesystem), in snapshots and backups, and in flash wear
+// levelling, older copies of the data survive.
+func Shred(paths []string, passes int, callback ProgressCallback) *Operation {
+	op := NewOperation(OpShred, paths, "")
+	return defaultQueue.submit(op, func() { performShred(op, paths, passes, callback) }, callback)
+}
+
+// performShred executes Shred.
+func performShred(op *Operation, paths []string, passes int, callback ProgressCallback) {
+	op.SetStatus(StatusRunning)
+
+	fail := func(err error) {
+		op.SetError(err)
+		if callback != nil {
+			callback(op)
+		}
+	}
+	if passes < 0 || passes > MaxShredPasses {
+		fail(fmt.Errorf("shred passes %d must be between 0 and %d", passes, MaxShredPasses))
+		return
+	}
+
+	var total int64
+	for _, path := range paths {
+		size, err := shredSize(path)
+		if err != nil {
+			fail(fmt.Errorf("failed to calculate size: %w", err))
+			return
+		}
+		total += size
+	}
+	total *= int64(max(passes, 1))
+
+	s := shredder{op: op, passes: passes, total: total}
+	for _, path := range paths {
+		if op.IsCancelled() {
+			break
+		}
+		if err := s.shredPath(path); err != nil {
+			if !op.IsCancelled() {
+				fail(fmt.Errorf("failed to shred %s: %w", path, err))
+				return
+			}
+			break
+		}
+	}
+
+	if !op.IsCancelled() {
+		op.UpdateProgress(total, total, "")
+		op.SetStatus(StatusCompleted)
+	}
+	if callback != nil {
+		callback(op)
+	}
+}
+
+// shredSize adds up the sizes of the regular files under path, without
+// following symlinks.
+func shredSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// shredder tracks progress across the files of one Shred operation.
+type shredder struct {
+	op        *Operation
+	passes    int
+	total     int64
+	processed int64
+}
+
+// shredPath shreds a file, or a directory and everything in it.
+func (s *shredder) shredPath(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.IsDir():
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if s.op.IsCancelled() {
+				return fmt.Errorf("operation cancelled")
+			}
+			if err := s.shredPath(filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return os.Remove(path)
+
+	case info.Mode().IsRegular():
+		if err := s.shredFile(path, info); err != nil {
+			return err
+		}
+		return os.Remove(path)
+
+	default:
+		// Symlinks, sockets, FIFOs and devices hold no data of their own
+		return os.Remove(path)
+	}
+}
+
+// shredFile overwrites or punches out the contents of a regular file and
+// truncates it. Read-only files are made writable first, as they are
+// about to be deleted anyway.
+func (s *shredder) shredFile(path string, info fs.FileInfo) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NOFOLLOW, 0)
+	if errors.Is(err, fs.ErrPermission) && info.Mode().Perm()&0o200 == 0 {
+		if err := os.Chmod(path, info.Mode().Perm()|0o200); err != nil {
+			return err
+		}
+		f, err = os.OpenFile(path, os.O_WRONLY|syscall.O_NOFOLLOW, 0)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	size := info.Size()
+	switch {
+	case size == 0:
+		// Nothing to overwrite
+	case s.passes == 0:
+		if err := s.punch(f, path, size); err != nil {
+			return err
+		}
+	default:
+		for range s.passes {
+			if err := s.overwrite(f, path, size); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// punch releases the blocks of f, or overwrites them once where the
+// filesystem cannot punch holes.
+func (s *shredder) punch(f *os.File, path string, size int64) error {
+	err := syscall.Fallocate(int(f.Fd()), fallocPunchHole|fallocKeepSize, 0, size)
+	switch {
+	case err == nil:
+		s.advance(path, size)
+		return f.Sync()
+	case errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.ENOSYS):
+		return s.overwrite(f, path, size)
+	default:
+		return err
+	}
+}
+
+// overwrite writes size bytes of random data over the start of f and
+// syncs it to disk.
+func (s *shredder) overwrite(f *os.File, path string, size int64) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 256*1024)
+	for written := int64(0); written < size; {
+		if s.op.IsCancelled() {
+			return fmt.Errorf("operation cancelled")
+		}
+		chunk := buf[:min(int64(len(buf)), size-written)]
+		if _, err := rand.Read(chunk); err != nil {
+			return err
+		}
+		n, err := f.Write(chunk)
+		written += int64(n)
+		s.advance(path, int64(n))
+		if err != nil {
+			return err
+		}
+	}
+	return f.Sync()
+}
+
+// advance records n more bytes processed.
+func (s *shredder) advance(path string, n int64) {
+	s.processed += n
+	s.op.UpdateProgress(s.processed, s.total, path)
+}
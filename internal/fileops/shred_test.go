@@ -0,0 +1,78 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShred(t *testing.T) {
+	for _, passes := range []int{0, 1, 3} {
+		dir := t.TempDir()
+		outside := t.TempDir()
+
+		file := filepath.Join(dir, "secret.txt")
+		if err := os.WriteFile(file, []byte("top secret contents"), 0o400); err != nil {
+			t.Fatal(err)
+		}
+		// A hard link shares the file's blocks, so it shows what Shred
+		// left of them
+		witness := filepath.Join(outside, "witness")
+		if err := os.Link(file, witness); err != nil {
+			t.Fatal(err)
+		}
+
+		tree := filepath.Join(dir, "tree")
+		if err := os.MkdirAll(filepath.Join(tree, "sub"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tree, "sub", "a.txt"), []byte("aaaa"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tree, "empty"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		target := filepath.Join(outside, "target.txt")
+		if err := os.WriteFile(target, []byte("keep me"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, filepath.Join(tree, "link")); err != nil {
+			t.Fatal(err)
+		}
+
+		op := Shred([]string{file, tree}, passes, nil)
+		waitForOperation(t, op, 5*time.Second)
+		if op.Status != StatusCompleted {
+			t.Fatalf("passes %d: status = %v, error = %v", passes, op.Status, op.Error)
+		}
+
+		for _, path := range []string{file, tree} {
+			if _, err := os.Lstat(path); !os.IsNotExist(err) {
+				t.Errorf("passes %d: %s still exists", passes, path)
+			}
+		}
+		if info, err := os.Stat(witness); err != nil || info.Size() != 0 {
+			t.Errorf("passes %d: hard link kept data: %v, %v", passes, info, err)
+		}
+		if data, err := os.ReadFile(target); err != nil || string(data) != "keep me" {
+			t.Errorf("passes %d: symlink target = %q, %v; want it untouched", passes, data, err)
+		}
+	}
+}
+
+func TestShredPassesOutOfRange(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	op := Shred([]string{file}, MaxShredPasses+1, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.Status != StatusFailed {
+		t.Errorf("status = %v, want %v", op.Status, StatusFailed)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("file was removed: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package fileops
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// treeSize returns how many bytes copying path under policy writes: the
+// sizes of the regular files in it, including those reached through links
+// the policy follows. It stops with ctx's error as soon as ctx is done.
+// Only a path that can't be stat'ed fails it: directories that can't be
+// read inside count as empty, since the copy reports them itself, and a
+// directory met again, through a bind mount or a followed link looping
+// back, is counted once.
+func treeSize(ctx context.Context, path string, policy SymlinkPolicy) (int64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	w := sizeWalk{ctx: ctx, policy: policy, seen: make(map[fileID]bool)}
+	return w.size(path, info), w.err
+}
+
+// sizeWalk is the state of one treeSize.
+type sizeWalk struct {
+	ctx    context.Context
+	policy SymlinkPolicy
+	seen   map[fileID]bool // Directories counted so far
+	err    error           // ctx's error, once the walk stopped
+}
+
+// size returns the size of path, whose Lstat is info.
+func (w *sizeWalk) size(path string, info os.FileInfo) int64 {
+	if w.err = w.ctx.Err(); w.err != nil {
+		return 0
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		if info = w.policy.follow(path); info == nil {
+			return 0
+		}
+	}
+	if !info.IsDir() {
+		if info.Mode().IsRegular() {
+			return info.Size()
+		}
+		return 0
+	}
+
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		id := fileID{dev: uint64(st.Dev), ino: st.Ino} //nolint:unconvert // Dev is uint32 on some platforms
+		if w.seen[id] {
+			return 0
+		}
+		w.seen[id] = true
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		info, err := os.Lstat(child)
+		if err != nil {
+			continue
+		}
+		total += w.size(child, info)
+		if w.err != nil {
+			return 0
+		}
+	}
+	return total
+}
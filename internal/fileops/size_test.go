@@ -0,0 +1,114 @@
+package fileops
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTreeSize(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "file1.txt"), []byte("Hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "sub", "file2.txt"), []byte("World!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Links count for nothing unless followed
+	if err := os.Symlink("file1.txt", filepath.Join(tmpDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy SymlinkPolicy
+		want   int64
+	}{
+		{SymlinksPreserve, 11},
+		{SymlinksDereference, 16},
+	}
+	for _, tt := range tests {
+		size, err := treeSize(context.Background(), tmpDir, tt.policy)
+		if err != nil {
+			t.Fatalf("treeSize(%v) failed: %v", tt.policy, err)
+		}
+		if size != tt.want {
+			t.Errorf("treeSize(%v) = %d, want %d", tt.policy, size, tt.want)
+		}
+	}
+
+	if _, err := treeSize(context.Background(), filepath.Join(tmpDir, "missing"), SymlinksPreserve); err == nil {
+		t.Error("treeSize() of a missing path succeeded")
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTreeSizeLoop(t *testing.T) {
+	// Each directory links to the other, which following links would walk
+	// forever, and to where they both are
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, "data"), []byte("1234"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{"a/to-b": "../b", "b/to-a": "../a"} {
+		if err := os.Symlink(target, filepath.Join(tmpDir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	size, err := treeSize(context.Background(), filepath.Join(tmpDir, "a"), SymlinksDereference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 8 {
+		t.Errorf("treeSize() = %d, want each directory counted once (8)", size)
+	}
+}
+
+func TestTreeSizeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := treeSize(ctx, t.TempDir(), SymlinksPreserve); !errors.Is(err, context.Canceled) {
+		t.Errorf("treeSize() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTreeSizeUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root reads any directory")
+	}
+	tmpDir := t.TempDir()
+	locked := filepath.Join(tmpDir, "locked")
+	if err := os.Mkdir(locked, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(locked, "secret"), []byte("hidden"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "open"), []byte("seen"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(locked, 0755) })
+
+	size, err := treeSize(context.Background(), tmpDir, SymlinksPreserve)
+	if err != nil {
+		t.Fatalf("treeSize() failed on an unreadable subdirectory: %v", err)
+	}
+	if size != 4 {
+		t.Errorf("treeSize() = %d, want 4", size)
+	}
+}
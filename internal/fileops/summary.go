@@ -0,0 +1,84 @@
+package fileops
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Elapsed returns how long the operation ran. It is zero until the
+// operation has both started and finished.
+func (op *Operation) Elapsed() time.Duration {
+	op.mu.RLock()
+	defer op.mu.RUnlock()
+	if op.StartTime.IsZero() || op.EndTime.IsZero() {
+		return 0
+	}
+	return op.EndTime.Sub(op.StartTime)
+}
+
+// Summary describes a finished operation in one line suitable for a
+// notification, e.g. "Copied 3 items in 2.4s" or
+// "Delete of notes.txt failed after 120ms: permission denied".
+func (op *Operation) Summary() string {
+	elapsed := FormatElapsed(op.Elapsed())
+
+	op.mu.RLock()
+	defer op.mu.RUnlock()
+
+	subject := describeSources(op.Source)
+	switch op.Status {
+	case StatusCompleted:
+		return fmt.Sprintf("%s %s in %s", pastTense(op.Type), subject, elapsed)
+	case StatusFailed:
+		return fmt.Sprintf("%s of %s failed after %s: %v", op.Type, subject, elapsed, op.Error)
+	case StatusCancelled:
+		return fmt.Sprintf("%s of %s cancelled after %s", op.Type, subject, elapsed)
+	default:
+		return fmt.Sprintf("%s of %s %s", op.Type, subject, op.Status)
+	}
+}
+
+// FormatElapsed renders a duration compactly: milliseconds below a
+// second, tenths of a second below a minute, whole seconds above that.
+func FormatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// describeSources names a single source by its base name and counts
+// anything larger.
+func describeSources(sources []string) string {
+	if len(sources) == 1 {
+		return filepath.Base(sources[0])
+	}
+	return fmt.Sprintf("%d items", len(sources))
+}
+
+// pastTense returns the verb for a completed operation of the given type.
+func pastTense(t OperationType) string {
+	switch t {
+	case OpCopy:
+		return "Copied"
+	case OpMove:
+		return "Moved"
+	case OpDelete:
+		return "Deleted"
+	case OpRename:
+		return "Renamed"
+	case OpTrash:
+		return "Trashed"
+	case OpShred:
+		return "Shredded"
+	case OpLink:
+		return "Linked"
+	default:
+		return "Finished"
+	}
+}
@@ -0,0 +1,65 @@
+package fileops
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{120*time.Millisecond + 400*time.Microsecond, "120ms"},
+		{2430 * time.Millisecond, "2.4s"},
+		{90*time.Second + 400*time.Millisecond, "1m30s"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatElapsed(tt.d); got != tt.want {
+			t.Errorf("FormatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestOperationSummary(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		opType OperationType
+		source []string
+		status OperationStatus
+		err    error
+		want   string
+	}{
+		{"copy many", OpCopy, []string{"/a/x", "/a/y", "/a/z"}, StatusCompleted, nil, "Copied 3 items in 2s"},
+		{"move one", OpMove, []string{"/a/notes.txt"}, StatusCompleted, nil, "Moved notes.txt in 2s"},
+		{"delete failed", OpDelete, []string{"/a/notes.txt"}, StatusFailed, errors.New("permission denied"), "Delete of notes.txt failed after 2s: permission denied"},
+		{"trash cancelled", OpTrash, []string{"/a/x", "/a/y"}, StatusCancelled, nil, "Trash of 2 items cancelled after 2s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := NewOperation(tt.opType, tt.source, "")
+			op.Status = tt.status
+			op.Error = tt.err
+			op.StartTime = start
+			op.EndTime = start.Add(2 * time.Second)
+
+			if got := op.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOperationElapsedUnfinished(t *testing.T) {
+	op := NewOperation(OpCopy, []string{"/a"}, "/b")
+	op.SetStatus(StatusRunning)
+	if got := op.Elapsed(); got != 0 {
+		t.Errorf("Elapsed() of running operation = %v, want 0", got)
+	}
+}
@@ -0,0 +1,165 @@
+package fileops
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy is what a copy does with the symbolic links it meets.
+type SymlinkPolicy int
+
+const (
+	// SymlinksPreserve recreates links as they are, so a relative link
+	// pointing outside what is copied breaks in the copy
+	SymlinksPreserve SymlinkPolicy = iota
+	// SymlinksRewriteRelative rewrites relative links that point outside
+	// the tree being copied so they still reach the same file from the
+	// copy; links within the tree and absolute links are kept
+	SymlinksRewriteRelative
+	// SymlinksDereference copies the files and directories links point
+	// to instead of the links. Dangling links, and links to a directory
+	// they are in, are kept as links
+	SymlinksDereference
+)
+
+// SymlinkPolicyNames lists the policies' names, in the order of their
+// values, as used in the config file.
+var SymlinkPolicyNames = []string{"preserve", "rewrite-relative", "dereference"}
+
+// String returns the policy's name in the config file.
+func (p SymlinkPolicy) String() string {
+	if int(p) < len(SymlinkPolicyNames) {
+		return SymlinkPolicyNames[p]
+	}
+	return "unknown"
+}
+
+// ParseSymlinkPolicy returns the policy with the given name; "" is
+// SymlinksPreserve.
+func ParseSymlinkPolicy(name string) (SymlinkPolicy, error) {
+	if name == "" {
+		return SymlinksPreserve, nil
+	}
+	for i, n := range SymlinkPolicyNames {
+		if strings.EqualFold(name, n) {
+			return SymlinkPolicy(i), nil
+		}
+	}
+	return SymlinksPreserve, fmt.Errorf("unknown symlink policy %q (want one of %s)", name, strings.Join(SymlinkPolicyNames, ", "))
+}
+
+// follow returns what the symlink link points to if the policy is to copy
+// that rather than the link, and nil to copy the link.
+func (p SymlinkPolicy) follow(link string) fs.FileInfo {
+	if p != SymlinksDereference {
+		return nil
+	}
+	info, err := os.Stat(link)
+	if err != nil {
+		return nil
+	}
+	if info.IsDir() && linksToAncestor(link) {
+		// Following it would copy the directory into itself forever
+		return nil
+	}
+	return info
+}
+
+// linksToAncestor reports whether the symlink link resolves to a
+// directory it is in, or one above that. Every directory in link's path
+// is resolved, so links leading to each other in turn are caught as well
+// as a link to its own parent.
+func linksToAncestor(link string) bool {
+	target, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return false
+	}
+	for dir := filepath.Dir(link); ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil && within(resolved, target) {
+			return true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return false
+		}
+	}
+}
+
+// within reports whether path is dir or below it, comparing the paths
+// as written.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// CountSymlinks returns how many symbolic links there are in paths and
+// below them, not following any.
+func CountSymlinks(paths []string) (int, error) {
+	count := 0
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type()&fs.ModeSymlink != 0 {
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// LinkedDirectory reports whether path is a symbolic link to a directory,
+// and returns that directory with every link on the way resolved.
+func LinkedDirectory(path string) (string, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return "", false
+	}
+	if info, err = os.Stat(path); err != nil || !info.IsDir() {
+		return "", false
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
+
+// copyLink recreates the symlink src at dst, rewriting its target if
+// state.symlinks says so.
+func copyLink(src, dst string, state *copyState) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return fmt.Errorf("failed to read symlink: %w", err)
+	}
+	if state.symlinks == SymlinksRewriteRelative && !filepath.IsAbs(target) {
+		resolved := filepath.Join(filepath.Dir(src), target)
+		if !state.inTree(src, resolved) {
+			if rel, err := filepath.Rel(filepath.Dir(dst), resolved); err == nil {
+				target = rel
+			} else {
+				target = resolved
+			}
+		}
+	}
+	return os.Symlink(target, dst)
+}
+
+// inTree reports whether the link at src and the path it resolves to are
+// inside the same source being copied, so the link works in the copy as
+// it is.
+func (s *copyState) inTree(src, resolved string) bool {
+	for _, root := range s.roots {
+		if src != root && within(src, root) {
+			return within(resolved, root)
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSymlinkPolicy(t *testing.T) {
+	for i, name := range SymlinkPolicyNames {
+		p, err := ParseSymlinkPolicy(name)
+		if err != nil || p != SymlinkPolicy(i) || p.String() != name {
+			t.Errorf("ParseSymlinkPolicy(%q) = %v, %v", name, p, err)
+		}
+	}
+	if p, err := ParseSymlinkPolicy(""); err != nil || p != SymlinksPreserve {
+		t.Errorf("ParseSymlinkPolicy(\"\") = %v, %v; want preserve", p, err)
+	}
+	if _, err := ParseSymlinkPolicy("follow"); err == nil {
+		t.Error("ParseSymlinkPolicy(\"follow\") succeeded")
+	}
+}
+
+// symlinkTree creates, in dir, outside.txt and a tree "src" holding
+// file.txt and links to it, to outside.txt, to an absolute path, to src
+// itself and to nothing.
+//
+//nolint:gosec // Test file permissions are intentionally relaxed
+func symlinkTree(t *testing.T, dir string) string {
+	t.Helper()
+	src := filepath.Join(dir, "src")
+	if err := os.Mkdir(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		filepath.Join(dir, "outside.txt"): "outside",
+		filepath.Join(src, "file.txt"):    "inside",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, target := range map[string]string{
+		"inner":    "file.txt",
+		"rel":      "../outside.txt",
+		"abs":      filepath.Join(dir, "outside.txt"),
+		"loop":     ".",
+		"dangling": "missing.txt",
+	} {
+		if err := os.Symlink(target, filepath.Join(src, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return src
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestCopySymlinkPolicies(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := symlinkTree(t, tmpDir)
+	outside := filepath.Join(tmpDir, "outside.txt")
+
+	tests := []struct {
+		policy SymlinkPolicy
+		want   map[string]string // Link targets; "" for a copied file
+	}{
+		{SymlinksPreserve, map[string]string{
+			"inner": "file.txt", "rel": "../outside.txt", "abs": outside, "loop": ".", "dangling": "missing.txt"}},
+		{SymlinksRewriteRelative, map[string]string{
+			"inner": "file.txt", "rel": "../../../../outside.txt", "abs": outside, "loop": ".", "dangling": "missing.txt"}},
+		{SymlinksDereference, map[string]string{
+			"inner": "", "rel": "", "abs": "", "loop": ".", "dangling": "missing.txt"}},
+	}
+
+	for _, tt := range tests {
+		for _, parallel := range []bool{false, true} {
+			name := tt.policy.String()
+			dst := filepath.Join(tmpDir, "copies", name, "seq", "src")
+			if parallel {
+				dst = filepath.Join(tmpDir, "copies", name, "par", "src")
+			}
+			if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			var op *Operation
+			if parallel {
+				op = CopyMultipleWith([]string{src}, filepath.Dir(dst), TransferOptions{Symlinks: tt.policy}, nil)
+			} else {
+				op = NewOperation(OpCopy, []string{src}, dst)
+				performCopy(op, src, dst, tt.policy, nil)
+			}
+			waitForOperation(t, op, 5*time.Second)
+			if op.GetStatus() != StatusCompleted {
+				t.Fatalf("%s (parallel %v): status = %v (%v)", name, parallel, op.GetStatus(), op.Error)
+			}
+
+			for link, want := range tt.want {
+				path := filepath.Join(dst, link)
+				target, err := os.Readlink(path)
+				switch {
+				case want == "" && err == nil:
+					t.Errorf("%s (parallel %v): %s is a link to %q, want a copied file", name, parallel, link, target)
+				case want != "" && target != want:
+					t.Errorf("%s (parallel %v): %s -> %q (%v), want %q", name, parallel, link, target, err, want)
+				}
+			}
+			if tt.policy != SymlinksPreserve {
+				verifyFileExists(t, filepath.Join(dst, "rel"), "outside")
+			}
+			if _, done, total, _ := op.GetProgress(); done != total {
+				t.Errorf("%s (parallel %v): progress = %d of %d bytes, want all", name, parallel, done, total)
+			}
+		}
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestCopyDereferenceLoop(t *testing.T) {
+	// a links to b, which links back to a: dereferencing follows the first
+	// and keeps the second, which would go round forever
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"a", "b", "dst"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "b", "data"), []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"a/to-b": "../b", "b/to-a": "../a"} {
+		if err := os.Symlink(target, filepath.Join(tmpDir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(tmpDir, "dst")
+	op := CopyMultipleWith([]string{filepath.Join(tmpDir, "a")}, dst, TransferOptions{Symlinks: SymlinksDereference}, nil)
+	waitForOperation(t, op, 5*time.Second)
+	if op.GetStatus() != StatusCompleted {
+		t.Fatalf("status = %v (%v)", op.GetStatus(), op.Error)
+	}
+	verifyFileExists(t, filepath.Join(dst, "a", "to-b", "data"), "b")
+	if target, err := os.Readlink(filepath.Join(dst, "a", "to-b", "to-a")); err != nil || target != "../a" {
+		t.Errorf("to-a = %q (%v), want the link kept", target, err)
+	}
+}
+
+func TestCountSymlinks(t *testing.T) {
+	src := symlinkTree(t, t.TempDir())
+	if n, err := CountSymlinks([]string{src}); err != nil || n != 5 {
+		t.Errorf("CountSymlinks() = %d, %v; want 5", n, err)
+	}
+	info, err := CheckTransfer([]string{src}, t.TempDir(), false)
+	if err != nil || info.Symlinks != 5 {
+		t.Errorf("CheckTransfer().Symlinks = %d, %v; want 5", info.Symlinks, err)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestLinkedDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	dir := filepath.Join(tmpDir, "dir")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(tmpDir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"to-dir":   "dir",
+		"to-link":  "to-dir",
+		"to-file":  "file",
+		"dangling": "missing",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(tmpDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		ok     bool
+	}{
+		{"to-dir", want, true},
+		{"to-link", want, true},
+		{"to-file", "", false},
+		{"dangling", "", false},
+		{"dir", "", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		target, ok := LinkedDirectory(filepath.Join(tmpDir, tt.name))
+		if target != tt.target || ok != tt.ok {
+			t.Errorf("LinkedDirectory(%q) = %q, %v; want %q, %v", tt.name, target, ok, tt.target, tt.ok)
+		}
+	}
+}
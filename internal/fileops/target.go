@@ -0,0 +1,78 @@
+package fileops
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveTarget interprets a location passed on the command line.
+// The argument may be a plain path (absolute, relative, or starting with ~)
+// or a file:// URI. If it names a directory, dir is that directory and
+// selectPath is empty. If it names anything else, dir is its parent and
+// selectPath is the entry itself so the caller can select it after loading.
+func ResolveTarget(arg string) (dir string, selectPath string, err error) {
+	if arg == "" {
+		return "", "", fmt.Errorf("path cannot be empty")
+	}
+
+	path := arg
+	if strings.Contains(arg, "://") {
+		u, err := url.Parse(arg)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid URI %q: %w", arg, err)
+		}
+		if u.Scheme != "file" {
+			return "", "", fmt.Errorf("unsupported URI scheme %q", u.Scheme)
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return "", "", fmt.Errorf("remote file URIs are not supported: %s", arg)
+		}
+		path = u.Path
+	}
+
+	// Expand ~ for paths that didn't go through a shell (e.g. .desktop Exec)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid path: %w", err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", fmt.Errorf("path does not exist: %s", absPath)
+		}
+		return "", "", fmt.Errorf("cannot access path: %w", err)
+	}
+
+	if info.IsDir() {
+		return absPath, "", nil
+	}
+	return filepath.Dir(absPath), absPath, nil
+}
+
+// RevealTarget is like ResolveTarget but always selects the named entry
+// inside its parent, even when it is a directory. It implements "show in
+// folder" behaviour. The root directory has no parent and is opened as-is.
+func RevealTarget(arg string) (dir string, selectPath string, err error) {
+	dir, selectPath, err = ResolveTarget(arg)
+	if err != nil || selectPath != "" {
+		return dir, selectPath, err
+	}
+
+	parent := filepath.Dir(dir)
+	if parent == dir {
+		return dir, "", nil
+	}
+	return parent, dir, nil
+}
@@ -0,0 +1,140 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	subDir := filepath.Join(tmpDir, "my dir")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+	file := filepath.Join(subDir, "notes.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		arg        string
+		wantDir    string
+		wantSelect string
+	}{
+		{"directory path", subDir, subDir, ""},
+		{"file path selects file", file, subDir, file},
+		{"trailing slash", subDir + "/", subDir, ""},
+		{"file URI directory", "file://" + strings.ReplaceAll(subDir, " ", "%20"), subDir, ""},
+		{"file URI file", "file://" + strings.ReplaceAll(file, " ", "%20"), subDir, file},
+		{"file URI localhost", "file://localhost" + strings.ReplaceAll(subDir, " ", "%20"), subDir, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, sel, err := ResolveTarget(tt.arg)
+			if err != nil {
+				t.Fatalf("ResolveTarget(%q) failed: %v", tt.arg, err)
+			}
+			if dir != tt.wantDir {
+				t.Errorf("dir = %q, want %q", dir, tt.wantDir)
+			}
+			if sel != tt.wantSelect {
+				t.Errorf("selectPath = %q, want %q", sel, tt.wantSelect)
+			}
+		})
+	}
+}
+
+func TestResolveTargetRelative(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	if err := os.Mkdir("child", 0755); err != nil {
+		t.Fatalf("Failed to create child dir: %v", err)
+	}
+
+	dir, sel, err := ResolveTarget("child")
+	if err != nil {
+		t.Fatalf("ResolveTarget failed: %v", err)
+	}
+	want, _ := filepath.Abs("child")
+	if dir != want || sel != "" {
+		t.Errorf("ResolveTarget(child) = (%q, %q), want (%q, \"\")", dir, sel, want)
+	}
+}
+
+func TestResolveTargetHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir, _, err := ResolveTarget("~")
+	if err != nil {
+		t.Fatalf("ResolveTarget(~) failed: %v", err)
+	}
+	if dir != home {
+		t.Errorf("ResolveTarget(~) = %q, want %q", dir, home)
+	}
+}
+
+func TestResolveTargetErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  string
+	}{
+		{"empty", ""},
+		{"missing path", "/nonexistent/path/for/warren"},
+		{"unsupported scheme", "sftp://host/path"},
+		{"remote file URI", "file://otherhost/tmp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ResolveTarget(tt.arg); err == nil {
+				t.Errorf("ResolveTarget(%q) expected error, got nil", tt.arg)
+			}
+		})
+	}
+}
+
+func TestRevealTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	subDir := filepath.Join(tmpDir, "sub")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+	file := filepath.Join(subDir, "a.txt")
+	if err := os.WriteFile(file, []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		arg        string
+		wantDir    string
+		wantSelect string
+	}{
+		{"file", file, subDir, file},
+		{"directory selected in parent", subDir, tmpDir, subDir},
+		{"file URI directory", "file://" + subDir, tmpDir, subDir},
+		{"root", "/", "/", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, sel, err := RevealTarget(tt.arg)
+			if err != nil {
+				t.Fatalf("RevealTarget(%q) failed: %v", tt.arg, err)
+			}
+			if dir != tt.wantDir || sel != tt.wantSelect {
+				t.Errorf("RevealTarget(%q) = (%q, %q), want (%q, %q)", tt.arg, dir, sel, tt.wantDir, tt.wantSelect)
+			}
+		})
+	}
+
+	if _, _, err := RevealTarget("/nonexistent/warren"); err == nil {
+		t.Error("RevealTarget on missing path should fail")
+	}
+}
@@ -0,0 +1,91 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// terminals are tried in order when no terminal is configured and
+// $TERMINAL is unset, with the arguments that make each run a command.
+var terminals = [][]string{
+	{"foot"},
+	{"kitty"},
+	{"alacritty", "-e"},
+	{"wezterm", "start", "--"},
+	{"ghostty", "-e"},
+	{"gnome-terminal", "--"},
+	{"konsole", "-e"},
+	{"xterm", "-e"},
+}
+
+// commandFlags returns the arguments a known terminal needs before the
+// command it runs. Terminals not listed take the command as is.
+func commandFlags(name string) []string {
+	for _, t := range terminals {
+		if t[0] == name {
+			return t[1:]
+		}
+	}
+	return nil
+}
+
+// TerminalCommand returns the command line that runs argv in a terminal.
+// terminal is a command line such as "foot" or "kitty --single-instance";
+// if empty, $TERMINAL is used, then the first known terminal installed.
+// A terminal Warren knows gets the arguments that make it run a command
+// ("-e" for alacritty) unless terminal already has arguments.
+func TerminalCommand(terminal string, argv ...string) ([]string, error) {
+	if strings.TrimSpace(terminal) == "" {
+		terminal = os.Getenv("TERMINAL")
+	}
+	term := strings.Fields(terminal)
+	if len(term) == 0 {
+		for _, t := range terminals {
+			if _, err := exec.LookPath(t[0]); err == nil {
+				term = t
+				break
+			}
+		}
+	}
+	if len(term) == 0 {
+		return nil, errors.New("no terminal found; set terminal under [general]")
+	}
+	if len(term) == 1 {
+		term = append(term, commandFlags(filepath.Base(term[0]))...)
+	}
+	return append(append([]string(nil), term...), argv...), nil
+}
+
+// EditorCommand returns the command line that edits path. editor is a
+// command line such as "nvim" or "hx"; if empty, $VISUAL or $EDITOR is
+// used, falling back to vi.
+func EditorCommand(editor, path string) []string {
+	for _, e := range []string{editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(e); len(fields) > 0 {
+			return append(fields, path)
+		}
+	}
+	return []string{"vi", path}
+}
+
+// Launch starts argv in dir without waiting for it to finish.
+func Launch(dir string, argv ...string) error {
+	if len(argv) == 0 {
+		return errors.New("no command to run")
+	}
+	// #nosec G204 -- Launching the configured program is the point
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", argv[0], err)
+	}
+	// Reap the process when it exits; its status is of no interest
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
@@ -0,0 +1,59 @@
+package fileops
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTerminalCommand(t *testing.T) {
+	t.Setenv("TERMINAL", "")
+
+	tests := []struct {
+		terminal string
+		want     []string
+	}{
+		{"foot", []string{"foot", "nvim", "/a"}},
+		{"alacritty", []string{"alacritty", "-e", "nvim", "/a"}},
+		{"/usr/bin/wezterm", []string{"/usr/bin/wezterm", "start", "--", "nvim", "/a"}},
+		{"kitty --single-instance", []string{"kitty", "--single-instance", "nvim", "/a"}},
+		{"xterm -fa Mono -e", []string{"xterm", "-fa", "Mono", "-e", "nvim", "/a"}},
+		{"myterm", []string{"myterm", "nvim", "/a"}},
+	}
+	for _, tt := range tests {
+		got, err := TerminalCommand(tt.terminal, "nvim", "/a")
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("TerminalCommand(%q) = %q, %v, want %q", tt.terminal, got, err, tt.want)
+		}
+	}
+
+	t.Setenv("TERMINAL", "alacritty")
+	if got, _ := TerminalCommand("", "nvim"); !slices.Equal(got, []string{"alacritty", "-e", "nvim"}) {
+		t.Errorf("TerminalCommand() with $TERMINAL = %q", got)
+	}
+
+	t.Setenv("TERMINAL", "")
+	t.Setenv("PATH", t.TempDir())
+	if _, err := TerminalCommand("", "nvim"); err == nil {
+		t.Error("TerminalCommand() without any terminal succeeded")
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := EditorCommand("", "/a"); !slices.Equal(got, []string{"vi", "/a"}) {
+		t.Errorf("EditorCommand() with nothing set = %q", got)
+	}
+
+	t.Setenv("EDITOR", "nano")
+	if got := EditorCommand("", "/a"); !slices.Equal(got, []string{"nano", "/a"}) {
+		t.Errorf("EditorCommand() with $EDITOR = %q", got)
+	}
+	t.Setenv("VISUAL", "nvim -p")
+	if got := EditorCommand("", "/a"); !slices.Equal(got, []string{"nvim", "-p", "/a"}) {
+		t.Errorf("EditorCommand() with $VISUAL = %q", got)
+	}
+	if got := EditorCommand("hx", "/a"); !slices.Equal(got, []string{"hx", "/a"}) {
+		t.Errorf("EditorCommand(hx) = %q", got)
+	}
+}
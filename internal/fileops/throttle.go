@@ -0,0 +1,72 @@
+package fileops
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ioLimit throttles the data every copy writes, together, so background
+// operations leave a slow disk some room. See SetRateLimit.
+var ioLimit rateLimiter
+
+// SetRateLimit caps how fast copies, including moves across filesystems
+// and trashing to another filesystem, write data, in bytes per second
+// over all running operations. 0 removes the limit. Running operations
+// follow a new rate from their next write.
+func SetRateLimit(bytesPerSecond int64) {
+	ioLimit.setRate(bytesPerSecond)
+}
+
+// RateLimit returns the limit set by SetRateLimit; 0 means none.
+func RateLimit() int64 {
+	ioLimit.mu.Lock()
+	defer ioLimit.mu.Unlock()
+	return ioLimit.rate
+}
+
+// rateLimiter spaces out writes so they average at most rate bytes per
+// second. Each write books the time its bytes take at that rate after
+// those booked before it, and waits for its turn.
+type rateLimiter struct {
+	mu   sync.Mutex
+	rate int64     // Bytes per second; 0 for no limit
+	next time.Time // When the bytes booked so far are paid for
+}
+
+// setRate changes the rate, forgetting what was booked at the old one.
+func (l *rateLimiter) setRate(rate int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = max(rate, 0)
+	l.next = time.Time{}
+}
+
+// wait books n bytes and blocks until their turn comes, or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	if l.rate == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		// Idle time isn't saved up for a burst later
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
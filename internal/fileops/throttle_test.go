@@ -0,0 +1,57 @@
+package fileops
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var l rateLimiter
+	ctx := context.Background()
+
+	// No limit: no waiting
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := l.wait(ctx, 1<<20); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("unlimited writes took %v", elapsed)
+	}
+
+	// 1 MiB/s: the first 64 KiB goes at once, the next three wait
+	// 62.5ms each
+	l.setRate(1 << 20)
+	start = time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.wait(ctx, 64<<10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("4 x 64 KiB at 1 MiB/s took %v, want at least 187ms", elapsed)
+	}
+
+	// Waiting stops when the operation is cancelled
+	l.setRate(1)
+	_ = l.wait(ctx, 1<<20)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.wait(cancelled, 1); err == nil {
+		t.Error("wait() on a cancelled context = nil, want an error")
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	defer SetRateLimit(0)
+	SetRateLimit(50 << 20)
+	if got := RateLimit(); got != 50<<20 {
+		t.Errorf("RateLimit() = %d, want %d", got, 50<<20)
+	}
+	SetRateLimit(-1)
+	if got := RateLimit(); got != 0 {
+		t.Errorf("RateLimit() after a negative rate = %d, want 0", got)
+	}
+}
@@ -0,0 +1,171 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// trashInfoTimeFormat is the DeletionDate format required by the
+// FreeDesktop.org trash specification (local time, no zone).
+const trashInfoTimeFormat = "2006-01-02T15:04:05"
+
+// TrashDir returns the user's home trash directory ($XDG_DATA_HOME/Trash),
+// defaulting to ~/.local/share/Trash when XDG_DATA_HOME is not set.
+func TrashDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "Trash"), nil
+}
+
+// TrashInfoPath returns the .trashinfo file describing trashed, a file in
+// a trash's files directory.
+func TrashInfoPath(trashed string) string {
+	trashDir := filepath.Dir(filepath.Dir(trashed))
+	return filepath.Join(trashDir, "info", filepath.Base(trashed)+".trashinfo")
+}
+
+// Trash moves a file or directory to the user's trash.
+func Trash(path string, callback ProgressCallback) *Operation {
+	return TrashMultiple([]string{path}, callback)
+}
+
+// TrashMultiple moves multiple files/directories to the user's trash.
+func TrashMultiple(paths []string, callback ProgressCallback) *Operation {
+	op := NewOperation(OpTrash, paths, "")
+	op.Priority = PriorityHigh
+	return defaultQueue.submit(op, func() { performTrash(op, paths, callback) }, callback)
+}
+
+// performTrash executes the trash operation for one or more paths.
+func performTrash(op *Operation, paths []string, callback ProgressCallback) {
+	op.SetStatus(StatusRunning)
+
+	trashDir, err := TrashDir()
+	if err != nil {
+		op.SetError(err)
+		if callback != nil {
+			callback(op)
+		}
+		return
+	}
+
+	total := int64(len(paths))
+	for i, path := range paths {
+		if op.IsCancelled() {
+			break
+		}
+
+		op.UpdateProgress(int64(i), total, path)
+		if callback != nil {
+			callback(op)
+		}
+
+		if err := trashPath(op, trashDir, path, callback); err != nil {
+			op.SetError(fmt.Errorf("failed to trash %s: %w", path, err))
+			if callback != nil {
+				callback(op)
+			}
+			return
+		}
+	}
+
+	if !op.IsCancelled() {
+		op.UpdateProgress(total, total, "")
+		op.SetStatus(StatusCompleted)
+	}
+
+	if callback != nil {
+		callback(op)
+	}
+}
+
+// trashPath moves a single path into trashDir and writes its .trashinfo file.
+func trashPath(op *Operation, trashDir, path string, callback ProgressCallback) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if _, err := os.Lstat(absPath); err != nil {
+		return fmt.Errorf("failed to stat: %w", err)
+	}
+
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	for _, dir := range []string{filesDir, infoDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create trash directory: %w", err)
+		}
+	}
+
+	name, infoPath, err := reserveTrashName(infoDir, filepath.Base(absPath), absPath)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(filesDir, name)
+	if err := os.Rename(absPath, dest); err != nil {
+		if !errors.Is(err, syscall.EXDEV) {
+			_ = os.Remove(infoPath)
+			return fmt.Errorf("failed to move to trash: %w", err)
+		}
+
+		// Different filesystem: copy into the home trash, then remove
+		totalSize, sizeErr := treeSize(op.ctx, absPath, SymlinksPreserve)
+		if sizeErr != nil {
+			_ = os.Remove(infoPath)
+			return fmt.Errorf("failed to calculate size: %w", sizeErr)
+		}
+		if err := copyRecursive(op, absPath, dest, newCopyState(op, totalSize, callback)); err != nil {
+			_ = os.RemoveAll(dest)
+			_ = os.Remove(infoPath)
+			return fmt.Errorf("failed to copy to trash: %w", err)
+		}
+		if err := os.RemoveAll(absPath); err != nil {
+			return fmt.Errorf("failed to remove original: %w", err)
+		}
+	}
+
+	op.setResult(path, dest)
+	return nil
+}
+
+// reserveTrashName picks an unused name in the trash and atomically creates
+// its .trashinfo file, which is how the spec reserves a name. Long names
+// are shortened so the name, a ".N" suffix and ".trashinfo" still fit
+// MaxNameLength.
+func reserveTrashName(infoDir, base, originalPath string) (string, string, error) {
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: originalPath}).EscapedPath(),
+		time.Now().Format(trashInfoTimeFormat))
+
+	base = truncateName(base, MaxNameLength-len(".trashinfo")-len(".9999"))
+	name := base
+	for i := 2; ; i++ {
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600) // #nosec G304 -- path inside trash dir
+		if err == nil {
+			_, writeErr := f.WriteString(info)
+			closeErr := f.Close()
+			if writeErr != nil || closeErr != nil {
+				_ = os.Remove(infoPath)
+				return "", "", fmt.Errorf("failed to write trash info: %w", errors.Join(writeErr, closeErr))
+			}
+			return name, infoPath, nil
+		}
+		if !os.IsExist(err) {
+			return "", "", fmt.Errorf("failed to create trash info: %w", err)
+		}
+		name = fmt.Sprintf("%s.%d", base, i)
+	}
+}
@@ -0,0 +1,117 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrashDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/custom/data")
+	dir, err := TrashDir()
+	if err != nil {
+		t.Fatalf("TrashDir failed: %v", err)
+	}
+	if dir != "/custom/data/Trash" {
+		t.Errorf("TrashDir() = %q, want /custom/data/Trash", dir)
+	}
+
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("HOME", "/home/rabbit")
+	dir, err = TrashDir()
+	if err != nil {
+		t.Fatalf("TrashDir failed: %v", err)
+	}
+	if dir != "/home/rabbit/.local/share/Trash" {
+		t.Errorf("TrashDir() = %q, want /home/rabbit/.local/share/Trash", dir)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTrash(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+
+	file := filepath.Join(tmpDir, "old notes.txt")
+	if err := os.WriteFile(file, []byte("bye"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	op := Trash(file, nil)
+	waitForOperation(t, op, 5*time.Second)
+
+	if op.Status != StatusCompleted {
+		t.Fatalf("Operation status = %v, want %v (error: %v)", op.Status, StatusCompleted, op.Error)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("Original file should no longer exist")
+	}
+
+	trashDir := filepath.Join(tmpDir, "data", "Trash")
+	trashed := filepath.Join(trashDir, "files", "old notes.txt")
+	verifyFileExists(t, trashed, "bye")
+	if results := op.GetResults(); len(results) != 1 || results[0] != trashed {
+		t.Errorf("GetResults() = %q, want [%s]", results, trashed)
+	}
+
+	info, err := os.ReadFile(TrashInfoPath(trashed))
+	if err != nil {
+		t.Fatalf("Failed to read trashinfo: %v", err)
+	}
+	if !strings.HasPrefix(string(info), "[Trash Info]\n") {
+		t.Errorf("trashinfo missing header: %q", info)
+	}
+	if !strings.Contains(string(info), "Path="+strings.ReplaceAll(file, " ", "%20")+"\n") {
+		t.Errorf("trashinfo has wrong Path: %q", info)
+	}
+	if !strings.Contains(string(info), "DeletionDate=") {
+		t.Errorf("trashinfo missing DeletionDate: %q", info)
+	}
+}
+
+//nolint:gosec // Test file permissions are intentionally relaxed
+func TestTrashMultipleNameCollision(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+
+	dirA := filepath.Join(tmpDir, "a")
+	dirB := filepath.Join(tmpDir, "b")
+	for _, dir := range []string{dirA, dirB} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "same.txt"), []byte(dir), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	op := TrashMultiple([]string{filepath.Join(dirA, "same.txt"), filepath.Join(dirB, "same.txt")}, nil)
+	waitForOperation(t, op, 5*time.Second)
+
+	if op.Status != StatusCompleted {
+		t.Fatalf("Operation status = %v, want %v (error: %v)", op.Status, StatusCompleted, op.Error)
+	}
+
+	filesDir := filepath.Join(tmpDir, "data", "Trash", "files")
+	verifyFileExists(t, filepath.Join(filesDir, "same.txt"), dirA)
+	verifyFileExists(t, filepath.Join(filesDir, "same.txt.2"), dirB)
+}
+
+func TestTrashMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+
+	op := Trash(filepath.Join(tmpDir, "missing"), nil)
+	waitForOperation(t, op, 5*time.Second)
+
+	if op.Status != StatusFailed {
+		t.Errorf("Operation status = %v, want %v", op.Status, StatusFailed)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(tmpDir, "data", "Trash", "info"))
+	if len(entries) != 0 {
+		t.Errorf("Expected no leftover trashinfo files, got %d", len(entries))
+	}
+}
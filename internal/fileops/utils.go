@@ -1,28 +1,9 @@
 package fileops
 
-import (
-	"fmt"
-)
-
-// FormatSize converts a file size in bytes to a human-readable string.
+// FormatSize converts a file size in bytes to a human-readable string
+// using 1024-based units.
 func FormatSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-
-	units := []string{"KB", "MB", "GB", "TB", "PB"}
-	if exp >= len(units) {
-		exp = len(units) - 1
-	}
-
-	return fmt.Sprintf("%.1f %s", float64(bytes)/float64(div), units[exp])
+	return formatUnits(bytes, 1024, []string{"KB", "MB", "GB", "TB", "PB"})
 }
 
 // GetParentDir returns the parent directory of the given path.
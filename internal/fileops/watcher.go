@@ -1,22 +1,53 @@
 package fileops
 
 import (
-	"log"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// maxUserWatchesFile holds the per-user inotify watch limit on Linux.
+const maxUserWatchesFile = "/proc/sys/fs/inotify/max_user_watches"
+
+// Subdirectory watches are capped at a share of the user's inotify limit so
+// Warren never starves other programs (editors, sync clients) of watches.
+const (
+	maxSubdirWatches      = 1024
+	defaultMaxUserWatches = 8192 // Assumed when the limit cannot be read
+	subdirWatchLimitPart  = 8    // Use at most 1/8 of the user's watches
+)
+
 // FileWatcher watches a directory for changes and triggers a callback.
 // It wraps fsnotify.Watcher and provides a simple interface for directory watching.
+//
+// Directories on network and FUSE filesystems, directories inotify refuses
+// to watch, and directories whose events overflowed the inotify queue are
+// polled instead: their entries are compared every poll interval.
+//
+// With subdirectory watching enabled it also watches each immediate
+// subdirectory, so entries appearing or disappearing one level down (which
+// change a directory's item count) trigger the callback too.
 type FileWatcher struct {
-	watcher     *fsnotify.Watcher
-	onChange    func()        // Callback when files change
-	stopChan    chan struct{} // Signal to stop watching
-	mu          sync.Mutex    // Protects currentPath
-	currentPath string        // Currently watched directory
-	running     bool          // Whether watcher is running
+	watcher      *fsnotify.Watcher
+	onChange     func()          // Callback when files change
+	stopChan     chan struct{}   // Signal to stop watching
+	mu           sync.Mutex      // Protects the fields below
+	currentPath  string          // Currently watched directory
+	running      bool            // Whether watcher is running
+	watchSubdirs bool            // Also watch immediate subdirectories
+	subdirs      map[string]bool // Subdirectories currently watched
+	budget       int             // Maximum number of subdirectory watches
+	downgraded   bool            // Subdirectory watching was disabled after hitting the inotify limit
+	pollInterval time.Duration   // How often polled directories are checked
+	pollStop     chan struct{}   // Closed to stop polling; nil when not polling
 }
 
 // NewFileWatcher creates a new file watcher with the given onChange callback.
@@ -30,33 +61,60 @@ func NewFileWatcher(onChange func()) (*FileWatcher, error) {
 	}
 
 	fw := &FileWatcher{
-		watcher:  watcher,
-		onChange: onChange,
-		stopChan: make(chan struct{}),
+		watcher:      watcher,
+		onChange:     onChange,
+		stopChan:     make(chan struct{}),
+		subdirs:      make(map[string]bool),
+		budget:       subdirWatchBudget(readMaxUserWatches()),
+		pollInterval: DefaultPollInterval,
 	}
 
 	return fw, nil
 }
 
 // Start begins watching the specified directory.
-// If already watching a directory, it stops watching the old one first.
+// If already watching a different directory, it stops watching the old one first.
 func (fw *FileWatcher) Start(path string) error {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
 
 	// If already watching a different path, remove it
 	if fw.currentPath != "" && fw.currentPath != path {
-		if err := fw.watcher.Remove(fw.currentPath); err != nil {
-			log.Printf("Warning: failed to remove old watch path %s: %v", fw.currentPath, err)
+		fw.removeSubdirWatches()
+		if fw.pollStop != nil {
+			fw.stopPolling()
+		} else if err := fw.watcher.Remove(fw.currentPath); err != nil {
+			slog.Warn("Failed to remove old watch path", "path", fw.currentPath, "err", err)
 		}
 	}
 
-	// Add the new path
-	if err := fw.watcher.Add(path); err != nil {
-		return err
-	}
+	switch fsType := pollFilesystem(path); {
+	case fw.currentPath == path && fw.pollStop != nil:
+		// Reloads of a polled directory keep polling
 
-	fw.currentPath = path
+	case fsType != "":
+		slog.Info("Polling directory: inotify is unreliable on its filesystem", "path", path, "interval", fw.pollInterval, "fs", fsType)
+		fw.currentPath = path
+		fw.startPolling()
+
+	default:
+		// Add the new path
+		if err := fw.watcher.Add(path); err != nil {
+			// Fall back to polling anything we can still read
+			if _, statErr := os.Stat(path); statErr != nil {
+				return err
+			}
+			slog.Info("Polling directory: cannot watch it", "path", path, "interval", fw.pollInterval, "err", err)
+			fw.currentPath = path
+			fw.startPolling()
+			break
+		}
+
+		fw.currentPath = path
+		if fw.watchSubdirs && !fw.downgraded {
+			fw.addSubdirWatches()
+		}
+	}
 
 	// Start event loop if not already running
 	if !fw.running {
@@ -67,6 +125,141 @@ func (fw *FileWatcher) Start(path string) error {
 	return nil
 }
 
+// SetPollInterval sets how often polled directories are checked. It takes
+// effect the next time polling starts; non-positive values are ignored.
+func (fw *FileWatcher) SetPollInterval(interval time.Duration) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if interval > 0 {
+		fw.pollInterval = interval
+	}
+}
+
+// Polling reports whether the current directory is polled rather than
+// watched through inotify.
+func (fw *FileWatcher) Polling() bool {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.pollStop != nil
+}
+
+// startPolling polls the current directory in place of inotify, dropping
+// any inotify watches on it. Must be called with fw.mu held.
+func (fw *FileWatcher) startPolling() {
+	if fw.pollStop != nil {
+		return
+	}
+	fw.removeSubdirWatches()
+	// Fails harmlessly when the directory was never added
+	_ = fw.watcher.Remove(fw.currentPath)
+
+	// Take the first signature now so changes made right after the switch
+	// are not folded into it
+	sig, _ := pollSignature(fw.currentPath)
+	fw.pollStop = make(chan struct{})
+	go pollLoop(fw.currentPath, sig, fw.pollInterval, fw.onChange, fw.pollStop)
+}
+
+// stopPolling stops polling the current directory.
+// Must be called with fw.mu held.
+func (fw *FileWatcher) stopPolling() {
+	if fw.pollStop != nil {
+		close(fw.pollStop)
+		fw.pollStop = nil
+	}
+}
+
+// SetWatchSubdirectories turns watching of immediate subdirectories on or
+// off, applying it to the current directory straight away.
+func (fw *FileWatcher) SetWatchSubdirectories(enabled bool) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if enabled == fw.watchSubdirs {
+		return
+	}
+	fw.watchSubdirs = enabled
+	if !enabled {
+		fw.removeSubdirWatches()
+		return
+	}
+	// Enabling again gives a watcher that hit the limit another chance
+	fw.downgraded = false
+	if fw.currentPath != "" && fw.pollStop == nil {
+		fw.addSubdirWatches()
+	}
+}
+
+// Downgraded reports whether subdirectory watching was turned off because
+// the inotify watch limit was reached.
+func (fw *FileWatcher) Downgraded() bool {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.downgraded
+}
+
+// addSubdirWatches watches each immediate subdirectory of the current path.
+// Directories with more subdirectories than the budget are not watched one
+// level deep at all, since a partial view would show stale counts.
+// Must be called with fw.mu held.
+func (fw *FileWatcher) addSubdirWatches() {
+	entries, err := os.ReadDir(fw.currentPath)
+	if err != nil {
+		return
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(fw.currentPath, entry.Name()))
+		}
+	}
+	if len(dirs) > fw.budget {
+		slog.Info("Not watching subdirectories: too many for the watch budget", "path", fw.currentPath, "subdirectories", len(dirs), "budget", fw.budget)
+		return
+	}
+
+	for _, dir := range dirs {
+		if !fw.addSubdirWatch(dir) {
+			return
+		}
+	}
+}
+
+// addSubdirWatch watches one subdirectory. It reports false, after
+// downgrading to watching only the current directory, when the system is
+// out of inotify watches. Must be called with fw.mu held.
+func (fw *FileWatcher) addSubdirWatch(dir string) bool {
+	if fw.subdirs[dir] || len(fw.subdirs) >= fw.budget {
+		return true
+	}
+
+	err := fw.watcher.Add(dir)
+	switch {
+	case err == nil:
+		fw.subdirs[dir] = true
+	case errors.Is(err, syscall.ENOSPC):
+		slog.Warn("Inotify watch limit reached; no longer watching subdirectories", "raise", maxUserWatchesFile)
+		fw.removeSubdirWatches()
+		fw.downgraded = true
+		return false
+	default:
+		// Unreadable or vanished subdirectories are simply not watched
+	}
+	return true
+}
+
+// removeSubdirWatches stops watching all subdirectories.
+// Must be called with fw.mu held.
+func (fw *FileWatcher) removeSubdirWatches() {
+	for dir := range fw.subdirs {
+		// Fails harmlessly if the directory was deleted and its watch dropped
+		_ = fw.watcher.Remove(dir)
+	}
+	fw.subdirs = make(map[string]bool)
+}
+
 // Stop stops watching and cleans up resources.
 func (fw *FileWatcher) Stop() error {
 	fw.mu.Lock()
@@ -77,6 +270,7 @@ func (fw *FileWatcher) Stop() error {
 	}
 
 	fw.running = false
+	fw.stopPolling()
 	close(fw.stopChan)
 
 	return fw.watcher.Close()
@@ -95,11 +289,9 @@ func (fw *FileWatcher) eventLoop() {
 				return
 			}
 
-			// Call onChange callback for relevant events
-			// We care about: Create, Write, Remove, Rename
-			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) != 0 {
+			if fw.relevant(event) {
 				// Log only events we're acting on
-				log.Printf("File watcher event: %s %s", event.Op, event.Name)
+				slog.Debug("File watcher event", "op", event.Op, "path", event.Name)
 
 				if fw.onChange != nil {
 					// Debounce the callback to avoid excessive reloads
@@ -111,7 +303,21 @@ func (fw *FileWatcher) eventLoop() {
 			if !ok {
 				return
 			}
-			log.Printf("File watcher error: %v", err)
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// Events were lost: reload now, and poll this directory
+				// since it changes faster than inotify can keep up with
+				fw.mu.Lock()
+				if fw.currentPath != "" {
+					slog.Warn("File watcher overflowed; polling instead", "path", fw.currentPath, "interval", fw.pollInterval)
+					fw.startPolling()
+				}
+				fw.mu.Unlock()
+				if fw.onChange != nil {
+					debouncer.Debounce(fw.onChange)
+				}
+				continue
+			}
+			slog.Warn("File watcher error", "err", err)
 
 		case <-fw.stopChan:
 			return
@@ -119,9 +325,59 @@ func (fw *FileWatcher) eventLoop() {
 	}
 }
 
+// relevant reports whether an event should trigger the callback, and keeps
+// the subdirectory watches in step with directories being created and removed.
+func (fw *FileWatcher) relevant(event fsnotify.Event) bool {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	// We care about: Create, Write, Remove, Rename
+	if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+		return false
+	}
+
+	// Inside a subdirectory only entries coming and going change what the
+	// list shows (the item count); writes to files down there do not
+	if filepath.Dir(event.Name) != fw.currentPath {
+		return event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0
+	}
+
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		delete(fw.subdirs, event.Name)
+	}
+	if event.Op&fsnotify.Create != 0 && fw.watchSubdirs && !fw.downgraded {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			fw.addSubdirWatch(event.Name)
+		}
+	}
+	return true
+}
+
 // CurrentPath returns the currently watched directory path.
 func (fw *FileWatcher) CurrentPath() string {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
 	return fw.currentPath
 }
+
+// readMaxUserWatches returns the inotify watch limit, or 0 if it cannot be read.
+func readMaxUserWatches() int {
+	data, err := os.ReadFile(maxUserWatchesFile)
+	if err != nil {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// subdirWatchBudget returns how many subdirectory watches a watcher may use
+// given the user's inotify limit (0 if unknown).
+func subdirWatchBudget(maxUserWatches int) int {
+	if maxUserWatches <= 0 {
+		maxUserWatches = defaultMaxUserWatches
+	}
+	return min(maxUserWatches/subdirWatchLimitPart, maxSubdirWatches)
+}
@@ -0,0 +1,125 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForChange reports whether changed fires within a second.
+func waitForChange(changed <-chan struct{}) bool {
+	select {
+	case <-changed:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func newTestWatcher(t *testing.T) (*FileWatcher, <-chan struct{}) {
+	t.Helper()
+	changed := make(chan struct{}, 16)
+	fw, err := NewFileWatcher(func() { changed <- struct{}{} })
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = fw.Stop() })
+	return fw, changed
+}
+
+func TestFileWatcherSubdirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+	subdir := filepath.Join(tmpDir, "subdir")
+	if err := os.Mkdir(subdir, 0700); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	t.Run("ignored when disabled", func(t *testing.T) {
+		fw, changed := newTestWatcher(t)
+		if err := fw.Start(tmpDir); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+
+		if err := os.WriteFile(filepath.Join(subdir, "a"), nil, 0600); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if waitForChange(changed) {
+			t.Error("change inside a subdirectory should not fire without subdirectory watching")
+		}
+	})
+
+	t.Run("entries created and removed one level down", func(t *testing.T) {
+		fw, changed := newTestWatcher(t)
+		fw.SetWatchSubdirectories(true)
+		if err := fw.Start(tmpDir); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+
+		file := filepath.Join(subdir, "b")
+		if err := os.WriteFile(file, nil, 0600); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if !waitForChange(changed) {
+			t.Fatal("creating a file in a subdirectory should fire")
+		}
+
+		// Writes down there leave the item count alone
+		if err := os.WriteFile(file, []byte("content"), 0600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if waitForChange(changed) {
+			t.Error("writing to a file in a subdirectory should not fire")
+		}
+
+		if err := os.Remove(file); err != nil {
+			t.Fatalf("Failed to remove file: %v", err)
+		}
+		if !waitForChange(changed) {
+			t.Error("removing a file in a subdirectory should fire")
+		}
+	})
+
+	t.Run("new subdirectories are watched", func(t *testing.T) {
+		fw, changed := newTestWatcher(t)
+		fw.SetWatchSubdirectories(true)
+		if err := fw.Start(tmpDir); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+
+		newDir := filepath.Join(tmpDir, "new")
+		if err := os.Mkdir(newDir, 0700); err != nil {
+			t.Fatalf("Failed to create subdirectory: %v", err)
+		}
+		if !waitForChange(changed) {
+			t.Fatal("creating a subdirectory should fire")
+		}
+
+		if err := os.WriteFile(filepath.Join(newDir, "c"), nil, 0600); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if !waitForChange(changed) {
+			t.Error("creating a file in a new subdirectory should fire")
+		}
+	})
+}
+
+func TestSubdirWatchBudget(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxUserWatches int
+		expected       int
+	}{
+		{"unknown limit", 0, 1024},
+		{"small limit", 800, 100},
+		{"large limit", 524288, 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subdirWatchBudget(tt.maxUserWatches); got != tt.expected {
+				t.Errorf("subdirWatchBudget(%d) = %d, want %d", tt.maxUserWatches, got, tt.expected)
+			}
+		})
+	}
+}
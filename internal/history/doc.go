@@ -0,0 +1,9 @@
+// Package history keeps a log of finished file operations: what was done,
+// when, to which files, where they ended up and whether it worked. The log
+// is saved in the state directory, so it outlives the session, and is what
+// undo works from: the newest operation not yet undone is reversed, then
+// the one before it, and so on.
+//
+// The package has no GTK dependency. History is not safe for concurrent
+// use; cmd/warren only touches it on the GTK main thread.
+package history
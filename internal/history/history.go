@@ -0,0 +1,237 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// File is the history's file name in the state directory.
+const File = "history.json"
+
+// MaxEntries is how many entries are kept; older ones are dropped.
+const MaxEntries = 1000
+
+// Entry is a finished operation.
+type Entry struct {
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	Type        string    `json:"type"` // "Copy", "Move", "Rename", ... (fileops.OperationType)
+	Sources     []string  `json:"sources"`
+	Destination string    `json:"destination,omitempty"`
+	Results     []string  `json:"results,omitempty"` // Where each source ended up (fileops.Operation.Results)
+	Status      string    `json:"status"`            // "Completed", "Failed" or "Cancelled"
+	Error       string    `json:"error,omitempty"`
+	Undone      bool      `json:"undone,omitempty"`
+	UndoOf      string    `json:"undo_of,omitempty"` // ID of the entry this operation undid
+}
+
+// FromOperation records a finished operation.
+func FromOperation(op *fileops.Operation) Entry {
+	e := Entry{
+		ID:          op.ID,
+		Time:        time.Now(),
+		Type:        op.Type.String(),
+		Sources:     append([]string(nil), op.Source...),
+		Destination: op.Destination,
+		Status:      op.GetStatus().String(),
+	}
+	results := op.GetResults()
+	for _, result := range results {
+		if result != "" {
+			e.Results = results
+			break
+		}
+	}
+	if op.Error != nil {
+		e.Error = op.Error.Error()
+	}
+	return e
+}
+
+// Summary describes the entry in one line, e.g. "Move 3 items to /tmp"
+// or "Rename a.txt to b.txt".
+func (e Entry) Summary() string {
+	subject := fmt.Sprintf("%d items", len(e.Sources))
+	if len(e.Sources) == 1 {
+		subject = filepath.Base(e.Sources[0])
+	}
+	switch {
+	case e.Type == fileops.OpRename.String() && e.Destination != "":
+		return fmt.Sprintf("%s %s to %s", e.Type, subject, filepath.Base(e.Destination))
+	case e.Destination != "":
+		return fmt.Sprintf("%s %s to %s", e.Type, subject, e.Destination)
+	default:
+		return fmt.Sprintf("%s %s", e.Type, subject)
+	}
+}
+
+// Matches reports whether every word of query appears, ignoring case, in
+// the entry's type, status, error or paths. An empty query matches.
+func (e Entry) Matches(query string) bool {
+	fields := []string{e.Type, e.Status, e.Error, e.Destination}
+	fields = append(fields, e.Sources...)
+	fields = append(fields, e.Results...)
+	text := strings.ToLower(strings.Join(fields, "\n"))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanUndo reports whether the entry can be reversed: a completed copy,
+// hard link, move, rename or trash, not undone yet and not itself an undo.
+func (e Entry) CanUndo() bool {
+	if e.Undone || e.UndoOf != "" || e.Status != fileops.StatusCompleted.String() {
+		return false
+	}
+	switch e.Type {
+	case fileops.OpCopy.String(), fileops.OpLink.String(), fileops.OpMove.String(), fileops.OpRename.String(), fileops.OpTrash.String():
+	default:
+		return false
+	}
+	for _, result := range e.Results {
+		if result != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Reversal says how to undo an entry: trash the copies or links it made,
+// or move the files it moved back where they were.
+type Reversal struct {
+	Trash   []string // Copies or links to move to the trash
+	From    []string // Paths to move back...
+	To      []string // ...to these
+	Untrash bool     // From are files in the trash, whose .trashinfo files go once they are back
+}
+
+// Reversal returns how to undo the entry; see CanUndo.
+func (e Entry) Reversal() Reversal {
+	var r Reversal
+	for i, result := range e.Results {
+		if result == "" || i >= len(e.Sources) {
+			continue
+		}
+		if e.Type == fileops.OpCopy.String() || e.Type == fileops.OpLink.String() {
+			r.Trash = append(r.Trash, result)
+			continue
+		}
+		r.From = append(r.From, result)
+		r.To = append(r.To, e.Sources[i])
+	}
+	r.Untrash = e.Type == fileops.OpTrash.String()
+	return r
+}
+
+// History is the log of finished operations, oldest first.
+type History struct {
+	entries []Entry
+	path    string            // Where the history is saved; "" to not save it
+	undoOf  map[string]string // Undo operations not added yet, by ID, to the entries they undid
+}
+
+// New creates an empty history, saved in stateDir, or nowhere if stateDir
+// is "".
+func New(stateDir string) *History {
+	h := &History{undoOf: make(map[string]string)}
+	if stateDir != "" {
+		h.path = filepath.Join(stateDir, File)
+	}
+	return h
+}
+
+// Add appends an entry, dropping the oldest past MaxEntries.
+func (h *History) Add(e Entry) {
+	if id, ok := h.undoOf[e.ID]; ok {
+		e.UndoOf = id
+		delete(h.undoOf, e.ID)
+	}
+	h.entries = append(h.entries, e)
+	if extra := len(h.entries) - MaxEntries; extra > 0 {
+		h.entries = append([]Entry(nil), h.entries[extra:]...)
+	}
+}
+
+// Entries returns the entries, oldest first.
+func (h *History) Entries() []Entry {
+	return append([]Entry(nil), h.entries...)
+}
+
+// LastUndoable returns the newest entry that can be undone.
+func (h *History) LastUndoable() (Entry, bool) {
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].CanUndo() {
+			return h.entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+// MarkUndone records that the operation undoID undid the entry id. The
+// undo operation's own entry may be added before or after.
+func (h *History) MarkUndone(id, undoID string) {
+	found := false
+	for i := range h.entries {
+		switch h.entries[i].ID {
+		case id:
+			h.entries[i].Undone = true
+		case undoID:
+			h.entries[i].UndoOf = id
+			found = true
+		}
+	}
+	if !found && undoID != "" {
+		h.undoOf[undoID] = id
+	}
+}
+
+// Load reads the saved history. A missing file is not an error.
+func (h *History) Load() error {
+	if h.path == "" {
+		return nil
+	}
+	// #nosec G304 -- the path is in Warren's state directory
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("invalid %s: %w", File, err)
+	}
+	h.entries = nil
+	for _, e := range entries {
+		h.Add(e)
+	}
+	return nil
+}
+
+// Save writes the history.
+func (h *History) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(h.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return fileops.WriteFileAtomic(h.path, data, 0600)
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+func TestFromOperation(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.txt")
+	if err := fileops.WriteFileAtomic(src, []byte("a"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	op := fileops.Rename(src, filepath.Join(dir, "b.txt"), nil)
+	select {
+	case <-op.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("rename timed out")
+	}
+
+	e := FromOperation(op)
+	if e.ID != op.ID || e.Type != "Rename" || e.Status != "Completed" {
+		t.Errorf("FromOperation() = %+v", e)
+	}
+	if want := []string{filepath.Join(dir, "b.txt")}; !reflect.DeepEqual(e.Results, want) {
+		t.Errorf("Results = %q, want %q", e.Results, want)
+	}
+	if got := e.Summary(); got != "Rename a.txt to b.txt" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if !e.CanUndo() {
+		t.Error("CanUndo() = false for a completed rename")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	e := Entry{Type: "Move", Status: "Completed", Sources: []string{"/home/me/Photos/cat.jpg"}, Destination: "/mnt/backup"}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"move", true},
+		{"CAT backup", true},
+		{"cat copy", false},
+		{"failed", false},
+	}
+	for _, tt := range tests {
+		if got := e.Matches(tt.query); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestReversal(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+		want  Reversal
+	}{
+		{"copy", Entry{Type: "Copy", Sources: []string{"/a", "/b"}, Results: []string{"/d/a", ""}},
+			Reversal{Trash: []string{"/d/a"}}},
+		{"link", Entry{Type: "Link", Sources: []string{"/a"}, Results: []string{"/d/a"}},
+			Reversal{Trash: []string{"/d/a"}}},
+		{"move", Entry{Type: "Move", Sources: []string{"/a", "/b"}, Results: []string{"/d/a", "/d/b"}},
+			Reversal{From: []string{"/d/a", "/d/b"}, To: []string{"/a", "/b"}}},
+		{"trash", Entry{Type: "Trash", Sources: []string{"/a"}, Results: []string{"/t/files/a"}},
+			Reversal{From: []string{"/t/files/a"}, To: []string{"/a"}, Untrash: true}},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.Reversal(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Reversal() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUndoSteps(t *testing.T) {
+	h := New("")
+	h.Add(Entry{ID: "1", Type: "Copy", Status: "Completed", Sources: []string{"/a"}, Results: []string{"/d/a"}})
+	h.Add(Entry{ID: "2", Type: "Delete", Status: "Completed", Sources: []string{"/b"}})
+	h.Add(Entry{ID: "3", Type: "Move", Status: "Completed", Sources: []string{"/c"}, Results: []string{"/d/c"}})
+	h.Add(Entry{ID: "4", Type: "Move", Status: "Failed", Sources: []string{"/e"}})
+
+	e, ok := h.LastUndoable()
+	if !ok || e.ID != "3" {
+		t.Fatalf("LastUndoable() = %q, %v, want 3", e.ID, ok)
+	}
+
+	// The undo's own entry may arrive after it is marked...
+	h.MarkUndone("3", "5")
+	h.Add(Entry{ID: "5", Type: "Move", Status: "Completed", Sources: []string{"/d/c"}, Results: []string{"/c"}})
+	if e, ok := h.LastUndoable(); !ok || e.ID != "1" {
+		t.Fatalf("LastUndoable() after one undo = %q, %v, want 1", e.ID, ok)
+	}
+
+	// ...or before
+	h.Add(Entry{ID: "6", Type: "Move", Status: "Completed", Sources: []string{"/d/a"}, Results: []string{"/x"}})
+	h.MarkUndone("1", "6")
+	if e, ok := h.LastUndoable(); ok {
+		t.Errorf("LastUndoable() after undoing everything = %q, want none", e.ID)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	h := New(dir)
+	for i := 0; i < MaxEntries+5; i++ {
+		h.Add(Entry{ID: string(rune('a' + i%26)), Type: "Copy", Status: "Completed"})
+	}
+	h.MarkUndone(h.Entries()[0].ID, "")
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded := New(dir)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Entries(), h.Entries()) {
+		t.Error("Load() entries differ from those saved")
+	}
+	if n := len(loaded.Entries()); n != MaxEntries {
+		t.Errorf("Load() kept %d entries, want %d", n, MaxEntries)
+	}
+
+	if err := New(t.TempDir()).Load(); err != nil {
+		t.Errorf("Load() without a file error = %v", err)
+	}
+}
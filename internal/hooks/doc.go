@@ -0,0 +1,15 @@
+// Package hooks runs user scripts at points in Warren's lifecycle.
+//
+// Hooks are executables in ~/.config/warren/hooks/ named after the event
+// they handle:
+//
+//	on-enter-dir   After a new directory is loaded
+//	on-select      After the selection moves to a different entry
+//	pre-delete     Before files are deleted; a failing hook cancels it
+//	post-copy      After a paste completes
+//
+// Context is passed in environment variables (WARREN_EVENT, WARREN_DIR,
+// WARREN_PATH, WARREN_FILES, WARREN_DEST). The first non-empty line a hook
+// prints to stdout is shown in the status bar. Missing or non-executable
+// hooks are skipped, so the feature costs nothing until a script is added.
+package hooks
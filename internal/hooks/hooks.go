@@ -0,0 +1,151 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Event names a hook point. The hook executable has the same name.
+type Event string
+
+// Supported hook events.
+const (
+	EnterDir  Event = "on-enter-dir"
+	Select    Event = "on-select"
+	PreDelete Event = "pre-delete"
+	PostCopy  Event = "post-copy"
+)
+
+// DefaultTimeout bounds how long a hook may run before it is killed.
+const DefaultTimeout = 5 * time.Second
+
+// Cancellable reports whether the event runs before an action, in which
+// case a failing hook aborts that action.
+func (e Event) Cancellable() bool {
+	return strings.HasPrefix(string(e), "pre-")
+}
+
+// Context describes the action a hook is being run for.
+type Context struct {
+	Dir   string   // Current directory
+	Path  string   // Entry the event concerns (selected or entered path)
+	Files []string // Files affected by the action
+	Dest  string   // Destination directory for copies
+}
+
+// Env returns the context as WARREN_* environment variables.
+// WARREN_FILES holds one path per line.
+func (c Context) Env(event Event) []string {
+	return []string{
+		"WARREN_EVENT=" + string(event),
+		"WARREN_DIR=" + c.Dir,
+		"WARREN_PATH=" + c.Path,
+		"WARREN_FILES=" + strings.Join(c.Files, "\n"),
+		"WARREN_DEST=" + c.Dest,
+	}
+}
+
+// Result is the outcome of running a hook.
+type Result struct {
+	Cancel bool   // True if a pre- hook vetoed the action
+	Status string // Status bar text printed by the hook, if any
+}
+
+// Runner locates and executes hook scripts.
+type Runner struct {
+	dir     string
+	timeout time.Duration
+}
+
+// NewRunner creates a runner for hooks stored in dir.
+func NewRunner(dir string) *Runner {
+	return &Runner{
+		dir:     dir,
+		timeout: DefaultTimeout,
+	}
+}
+
+// Dir returns the directory hooks are loaded from.
+func (r *Runner) Dir() string {
+	return r.dir
+}
+
+// Has reports whether an executable hook exists for the event.
+func (r *Runner) Has(event Event) bool {
+	if r == nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(r.dir, string(event)))
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// Run executes the hook for event, if there is one.
+// For cancellable events any failure (non-zero exit, timeout, failure to
+// start) sets Cancel so destructive actions fail safe. For other events
+// failures are returned as errors and never block the action.
+func (r *Runner) Run(event Event, hctx Context) (Result, error) {
+	if !r.Has(event) {
+		return Result{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	// #nosec G204 -- hook path is fixed to the user's own config directory
+	cmd := exec.CommandContext(ctx, filepath.Join(r.dir, string(event)))
+	cmd.Env = append(os.Environ(), hctx.Env(event)...)
+	if hctx.Dir != "" {
+		cmd.Dir = hctx.Dir
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	result := Result{Status: firstLine(stdout.String())}
+	if err == nil {
+		return result, nil
+	}
+
+	if ctx.Err() != nil {
+		err = fmt.Errorf("timed out after %s", r.timeout)
+	} else if msg := firstLine(stderr.String()); msg != "" {
+		err = fmt.Errorf("%w: %s", err, msg)
+	}
+	err = fmt.Errorf("hook %s failed: %w", event, err)
+
+	if event.Cancellable() {
+		result.Cancel = true
+		if result.Status == "" {
+			result.Status = err.Error()
+		}
+	}
+
+	var exitErr *exec.ExitError
+	if event.Cancellable() && errors.As(err, &exitErr) {
+		// A deliberate veto is not an error, just a cancelled action
+		return result, nil
+	}
+	return result, err
+}
+
+// firstLine returns the first non-empty line of s, trimmed.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeHook creates an executable shell script for event in dir.
+func writeHook(t *testing.T, dir string, event Event, body string) {
+	t.Helper()
+	path := filepath.Join(dir, string(event))
+	// #nosec G306 -- test hooks must be executable
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+}
+
+func TestEventCancellable(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  bool
+	}{
+		{EnterDir, false},
+		{Select, false},
+		{PreDelete, true},
+		{PostCopy, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.event.Cancellable(); got != tt.want {
+			t.Errorf("%s.Cancellable() = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestRunMissingHook(t *testing.T) {
+	r := NewRunner(t.TempDir())
+
+	result, err := r.Run(PreDelete, Context{})
+	if err != nil {
+		t.Fatalf("Run with no hook returned error: %v", err)
+	}
+	if result.Cancel || result.Status != "" {
+		t.Errorf("Run with no hook = %+v, want zero result", result)
+	}
+}
+
+func TestRunNilRunner(t *testing.T) {
+	var r *Runner
+	if _, err := r.Run(EnterDir, Context{}); err != nil {
+		t.Errorf("nil runner returned error: %v", err)
+	}
+}
+
+func TestRunSkipsNonExecutable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, string(PreDelete)), []byte("#!/bin/sh\nexit 1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+
+	r := NewRunner(dir)
+	if r.Has(PreDelete) {
+		t.Error("Has() should ignore non-executable hooks")
+	}
+	result, err := r.Run(PreDelete, Context{})
+	if err != nil || result.Cancel {
+		t.Errorf("Run() = (%+v, %v), want non-executable hook skipped", result, err)
+	}
+}
+
+func TestRunPassesContext(t *testing.T) {
+	dir := t.TempDir()
+	workDir := t.TempDir()
+	writeHook(t, dir, PostCopy, `echo "$WARREN_EVENT|$WARREN_DIR|$WARREN_PATH|$(echo "$WARREN_FILES" | wc -l)|$WARREN_DEST|$(pwd)"`)
+
+	r := NewRunner(dir)
+	result, err := r.Run(PostCopy, Context{
+		Dir:   workDir,
+		Path:  "/src/a",
+		Files: []string{"/src/a", "/src/b"},
+		Dest:  "/dest",
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	parts := strings.Split(result.Status, "|")
+	if len(parts) != 6 {
+		t.Fatalf("unexpected hook output %q", result.Status)
+	}
+	if parts[0] != "post-copy" || parts[1] != workDir || parts[2] != "/src/a" || parts[4] != "/dest" {
+		t.Errorf("hook saw %q", result.Status)
+	}
+	if strings.TrimSpace(parts[3]) != "2" {
+		t.Errorf("WARREN_FILES had %s lines, want 2", parts[3])
+	}
+	if parts[5] != workDir {
+		t.Errorf("hook ran in %q, want %q", parts[5], workDir)
+	}
+}
+
+func TestRunStatusFirstLine(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, EnterDir, `echo; echo "  git: main  "; echo second`)
+
+	result, err := NewRunner(dir).Run(EnterDir, Context{})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Status != "git: main" {
+		t.Errorf("Status = %q, want %q", result.Status, "git: main")
+	}
+}
+
+func TestRunPreHookCancels(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, PreDelete, `echo "refusing to delete $WARREN_PATH"; exit 1`)
+
+	result, err := NewRunner(dir).Run(PreDelete, Context{Path: "/important"})
+	if err != nil {
+		t.Fatalf("veto should not be an error: %v", err)
+	}
+	if !result.Cancel {
+		t.Error("non-zero exit from pre-delete should cancel")
+	}
+	if result.Status != "refusing to delete /important" {
+		t.Errorf("Status = %q", result.Status)
+	}
+}
+
+func TestRunPostHookFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, PostCopy, `echo oops >&2; exit 3`)
+
+	result, err := NewRunner(dir).Run(PostCopy, Context{})
+	if err == nil {
+		t.Fatal("expected error from failing post-copy hook")
+	}
+	if result.Cancel {
+		t.Error("post-copy hooks must never cancel")
+	}
+	if !strings.Contains(err.Error(), "oops") {
+		t.Errorf("error %q should include hook stderr", err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	dir := t.TempDir()
+	writeHook(t, dir, PreDelete, `exec sleep 5`)
+
+	r := NewRunner(dir)
+	r.timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	result, err := r.Run(PreDelete, Context{})
+	if time.Since(start) > 3*time.Second {
+		t.Error("hook was not killed at the timeout")
+	}
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+	if !result.Cancel {
+		t.Error("a timed out pre-delete hook should cancel the delete")
+	}
+}
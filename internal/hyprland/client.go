@@ -1,17 +1,19 @@
 package hyprland
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"strings"
 )
 
+// batchPrefix marks a command socket request holding several commands
+// separated by batchSeparator.
 const (
-	// Buffer size for IPC responses (64KB)
-	ipcBufferSize = 65536
+	batchPrefix    = "[[BATCH]]"
+	batchSeparator = ";"
 )
 
 // Client provides IPC communication with Hyprland.
@@ -39,19 +41,32 @@ type Window struct {
 		ID   int    `json:"id"`
 		Name string `json:"name"`
 	} `json:"workspace"`
-	Class string `json:"class"`
-	Title string `json:"title"`
-	PID   int    `json:"pid"`
+	Monitor        int    `json:"monitor"` // ID of the monitor showing the window
+	Class          string `json:"class"`
+	Title          string `json:"title"`
+	PID            int    `json:"pid"`
+	FocusHistoryID int    `json:"focusHistoryID"` // 0 for the focused window, higher for older focus
 }
 
-// Event represents a Hyprland event from the event socket.
-type Event struct {
-	Type string // Event type (e.g., "workspace", "activewindow")
-	Data string // Event data
+// WorkspaceRef identifies a workspace inside other IPC replies.
+type WorkspaceRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
 }
 
-// EventHandler is a function that handles Hyprland events.
-type EventHandler func(Event)
+// Monitor represents a Hyprland monitor (output).
+type Monitor struct {
+	ID               int          `json:"id"`
+	Name             string       `json:"name"`        // Connector name, e.g. "DP-1"
+	Description      string       `json:"description"` // Make, model and serial
+	Width            int          `json:"width"`
+	Height           int          `json:"height"`
+	X                int          `json:"x"`
+	Y                int          `json:"y"`
+	ActiveWorkspace  WorkspaceRef `json:"activeWorkspace"`
+	SpecialWorkspace WorkspaceRef `json:"specialWorkspace"` // Open special workspace; empty name if none
+	Focused          bool         `json:"focused"`
+}
 
 // IsHyprland checks if the current environment is running under Hyprland.
 func IsHyprland() bool {
@@ -72,6 +87,13 @@ func getRuntimeDir() string {
 	return fmt.Sprintf("/run/user/%d/hypr", uid)
 }
 
+// Sockets returns the paths of the command and event sockets of the
+// Hyprland instance with signature sig.
+func Sockets(sig string) (command, events string) {
+	runtimeDir := getRuntimeDir()
+	return fmt.Sprintf("%s/%s/.socket.sock", runtimeDir, sig), fmt.Sprintf("%s/%s/.socket2.sock", runtimeDir, sig)
+}
+
 // New creates a new Hyprland IPC client.
 // Returns an error if not running under Hyprland.
 func New() (*Client, error) {
@@ -80,9 +102,7 @@ func New() (*Client, error) {
 		return nil, fmt.Errorf("not running under Hyprland (HYPRLAND_INSTANCE_SIGNATURE not set)")
 	}
 
-	runtimeDir := getRuntimeDir()
-	commandSocket := fmt.Sprintf("%s/%s/.socket.sock", runtimeDir, sig)
-	eventSocket := fmt.Sprintf("%s/%s/.socket2.sock", runtimeDir, sig)
+	commandSocket, eventSocket := Sockets(sig)
 
 	// Verify command socket exists
 	if _, err := os.Stat(commandSocket); err != nil {
@@ -108,13 +128,52 @@ func (c *Client) sendCommand(cmd string) ([]byte, error) {
 		return nil, fmt.Errorf("failed to send command: %w", err)
 	}
 
-	buf := make([]byte, ipcBufferSize)
-	n, err := conn.Read(buf)
+	// Hyprland closes the connection after replying, so read to EOF;
+	// listings such as clients easily exceed a single read
+	resp, err := io.ReadAll(conn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return buf[:n], nil
+	return resp, nil
+}
+
+// Query sends a raw request to the command socket, as hyprctl would
+// (e.g. "version", "clients", "keyword general:gaps_in 5"), and returns
+// Hyprland's reply unparsed.
+func (c *Client) Query(cmd string) ([]byte, error) {
+	return c.sendCommand(cmd)
+}
+
+// QueryJSON sends cmd with the JSON flag (e.g. "clients" becomes
+// "j/clients") and decodes the reply into v.
+func (c *Client) QueryJSON(cmd string, v any) error {
+	resp, err := c.sendCommand("j/" + cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(resp, v); err != nil {
+		return fmt.Errorf("failed to parse %s data: %w", cmd, err)
+	}
+
+	return nil
+}
+
+// Batch sends several commands in one request using Hyprland's batch
+// syntax ("[[BATCH]]cmd1;cmd2"), so they are applied together, and
+// returns the combined reply. Commands may not contain the ";" separator.
+func (c *Client) Batch(commands ...string) ([]byte, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	for _, cmd := range commands {
+		if strings.Contains(cmd, batchSeparator) {
+			return nil, fmt.Errorf("batch command %q contains %q", cmd, batchSeparator)
+		}
+	}
+
+	return c.sendCommand(batchPrefix + strings.Join(commands, batchSeparator))
 }
 
 // GetActiveWorkspace returns the currently active workspace.
@@ -147,6 +206,51 @@ func (c *Client) GetWorkspaces() ([]Workspace, error) {
 	return workspaces, nil
 }
 
+// GetMonitors returns all monitors.
+func (c *Client) GetMonitors() ([]Monitor, error) {
+	resp, err := c.sendCommand("j/monitors")
+	if err != nil {
+		return nil, err
+	}
+
+	var monitors []Monitor
+	if err := json.Unmarshal(resp, &monitors); err != nil {
+		return nil, fmt.Errorf("failed to parse monitors data: %w", err)
+	}
+
+	return monitors, nil
+}
+
+// GetActiveMonitor returns the focused monitor.
+func (c *Client) GetActiveMonitor() (*Monitor, error) {
+	monitors, err := c.GetMonitors()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range monitors {
+		if monitors[i].Focused {
+			return &monitors[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no focused monitor")
+}
+
+// MonitorOf returns the name of the monitor showing the named workspace.
+func (c *Client) MonitorOf(workspace string) (string, error) {
+	workspaces, err := c.GetWorkspaces()
+	if err != nil {
+		return "", err
+	}
+
+	for _, ws := range workspaces {
+		if ws.Name == workspace {
+			return ws.Monitor, nil
+		}
+	}
+	return "", fmt.Errorf("workspace %q not found", workspace)
+}
+
 // GetActiveWindow returns the currently active window.
 func (c *Client) GetActiveWindow() (*Window, error) {
 	resp, err := c.sendCommand("j/activewindow")
@@ -162,35 +266,19 @@ func (c *Client) GetActiveWindow() (*Window, error) {
 	return &win, nil
 }
 
-// ListenEvents listens for Hyprland events and calls the handler for each event.
-// This is a blocking call that runs until an error occurs.
-// Typically run in a goroutine.
-func (c *Client) ListenEvents(handler EventHandler) error {
-	conn, err := net.Dial("unix", c.eventSocket)
+// GetClients returns all windows, in Hyprland's order.
+func (c *Client) GetClients() ([]Window, error) {
+	resp, err := c.sendCommand("j/clients")
 	if err != nil {
-		return fmt.Errorf("failed to connect to Hyprland event socket: %w", err)
-	}
-	defer func() { _ = conn.Close() }()
-
-	scanner := bufio.NewScanner(conn)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.SplitN(line, ">>", 2)
-
-		if len(parts) == 2 {
-			event := Event{
-				Type: parts[0],
-				Data: parts[1],
-			}
-			handler(event)
-		}
+		return nil, err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("event listener error: %w", err)
+	var clients []Window
+	if err := json.Unmarshal(resp, &clients); err != nil {
+		return nil, fmt.Errorf("failed to parse clients data: %w", err)
 	}
 
-	return nil
+	return clients, nil
 }
 
 // Dispatch sends a dispatch command to Hyprland.
@@ -204,3 +292,38 @@ func (c *Client) Dispatch(command string) error {
 func (c *Client) SwitchWorkspace(id int) error {
 	return c.Dispatch(fmt.Sprintf("workspace %d", id))
 }
+
+// FocusWorkspace switches to the named workspace, creating it if needed.
+func (c *Client) FocusWorkspace(workspace string) error {
+	return c.Dispatch("workspace " + Selector(workspace))
+}
+
+// MoveToWorkspace moves the window owned by pid to the named workspace
+// and follows it there.
+func (c *Client) MoveToWorkspace(workspace string, pid int) error {
+	return c.Dispatch(fmt.Sprintf("movetoworkspace %s,pid:%d", Selector(workspace), pid))
+}
+
+// Exec has Hyprland launch argv with window rules (e.g. "workspace 3")
+// applied to the windows it opens. Hyprland runs the command through a
+// shell, so each argument is quoted.
+func (c *Client) Exec(rules []string, argv ...string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("exec: empty command")
+	}
+
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	cmd := strings.Join(quoted, " ")
+	if len(rules) > 0 {
+		cmd = "[" + strings.Join(rules, "; ") + "] " + cmd
+	}
+	return c.Dispatch("exec " + cmd)
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -1,7 +1,9 @@
 package hyprland
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net"
 	"os"
 	"path/filepath"
@@ -194,6 +196,12 @@ func handleMockConnection(conn net.Conn) {
 
 	cmd := string(buf[:n])
 
+	// Echo batches back so tests can check what was sent
+	if strings.HasPrefix(cmd, batchPrefix) {
+		_, _ = conn.Write([]byte(cmd))
+		return
+	}
+
 	// Mock responses based on command
 	var response []byte
 	switch cmd {
@@ -228,6 +236,40 @@ func handleMockConnection(conn net.Conn) {
 		win.Workspace.Name = "1"
 		response, _ = json.Marshal(win)
 
+	case "j/monitors":
+		monitors := []Monitor{
+			{ID: 0, Name: "DP-1", Width: 2560, Height: 1440, ActiveWorkspace: WorkspaceRef{ID: 1, Name: "1"}},
+			{ID: 1, Name: "HDMI-A-1", Width: 1920, Height: 1080, X: 2560, ActiveWorkspace: WorkspaceRef{ID: 2, Name: "2"},
+				SpecialWorkspace: WorkspaceRef{ID: -98, Name: "special:files"}, Focused: true},
+		}
+		response, _ = json.Marshal(monitors)
+
+	case "version":
+		response = []byte("Hyprland 0.45.0 built from branch main")
+
+	case "j/clients":
+		// Large enough to need more than one read
+		clients := make([]Window, 2000)
+		for i := range clients {
+			clients[i] = Window{Address: fmt.Sprintf("0x%x", i), Class: "kitty", Title: "Terminal"}
+		}
+		// Two windows owned by the same process; the second was focused
+		// more recently
+		clients[5].PID = 4242
+		clients[5].Workspace.Name = "3"
+		clients[5].FocusHistoryID = 7
+		clients[9].PID = 4242
+		clients[9].Workspace.Name = "special:files"
+		clients[9].FocusHistoryID = 2
+		// A document open in two viewers; the first was focused last
+		clients[20].Title = "paper.pdf - Document Viewer"
+		clients[20].Class = "org.gnome.Evince"
+		clients[20].FocusHistoryID = 3
+		clients[21].Title = "paper.pdf - Firefox"
+		clients[21].Class = "firefox"
+		clients[21].FocusHistoryID = 5
+		response, _ = json.Marshal(clients)
+
 	default:
 		response = []byte("ok")
 	}
@@ -305,6 +347,91 @@ func TestClient_GetActiveWindow(t *testing.T) {
 	}
 }
 
+func TestClient_GetClients(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	clients, err := client.GetClients()
+	if err != nil {
+		t.Fatalf("GetClients() error = %v", err)
+	}
+
+	// The mock reply is far larger than one socket read, so a truncated
+	// read would fail to parse or drop windows
+	if len(clients) != 2000 {
+		t.Fatalf("GetClients() returned %d windows, want 2000", len(clients))
+	}
+	if clients[9].PID != 4242 || clients[9].Workspace.Name != "special:files" {
+		t.Errorf("clients[9] = %+v, want pid 4242 on special:files", clients[9])
+	}
+}
+
+func TestClient_GetMonitors(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	monitors, err := client.GetMonitors()
+	if err != nil {
+		t.Fatalf("GetMonitors() error = %v", err)
+	}
+
+	if len(monitors) != 2 {
+		t.Fatalf("Got %d monitors, want 2", len(monitors))
+	}
+	if monitors[0].Name != "DP-1" || monitors[0].ActiveWorkspace.Name != "1" {
+		t.Errorf("First monitor = %+v, want DP-1 showing workspace 1", monitors[0])
+	}
+	if monitors[1].SpecialWorkspace.Name != "special:files" {
+		t.Errorf("Second monitor special workspace = %q, want special:files", monitors[1].SpecialWorkspace.Name)
+	}
+}
+
+func TestClient_GetActiveMonitor(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	mon, err := client.GetActiveMonitor()
+	if err != nil {
+		t.Fatalf("GetActiveMonitor() error = %v", err)
+	}
+	if mon.Name != "HDMI-A-1" {
+		t.Errorf("Active monitor = %s, want HDMI-A-1", mon.Name)
+	}
+}
+
+func TestClient_MonitorOf(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	monitor, err := client.MonitorOf("2")
+	if err != nil {
+		t.Fatalf("MonitorOf() error = %v", err)
+	}
+	if monitor != "DP-1" {
+		t.Errorf("MonitorOf(\"2\") = %s, want DP-1", monitor)
+	}
+
+	if _, err := client.MonitorOf("missing"); err == nil {
+		t.Error("MonitorOf() of an unknown workspace should fail")
+	}
+}
+
 // Mock event server
 func setupMockEventServer(t *testing.T, events []string) (string, func()) {
 	tmpDir := t.TempDir()
@@ -337,7 +464,7 @@ func setupMockEventServer(t *testing.T, events []string) (string, func()) {
 	return socketPath, cleanup
 }
 
-func TestClient_ListenEvents(t *testing.T) {
+func TestClient_Subscribe(t *testing.T) {
 	mockEvents := []string{
 		"workspace>>2",
 		"activewindow>>kitty,Terminal",
@@ -351,42 +478,56 @@ func TestClient_ListenEvents(t *testing.T) {
 		eventSocket: socketPath,
 	}
 
-	receivedEvents := make([]Event, 0)
-	done := make(chan bool)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	go func() {
-		err := client.ListenEvents(func(event Event) {
+	events, err := client.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	receivedEvents := make([]Event, 0)
+	for len(receivedEvents) < 3 {
+		select {
+		case event := <-events:
 			receivedEvents = append(receivedEvents, event)
-			if len(receivedEvents) == 3 {
-				done <- true
-			}
-		})
-		if err != nil && err.Error() != "event listener error: EOF" {
-			t.Errorf("ListenEvents() error = %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for events, got %d", len(receivedEvents))
 		}
-	}()
+	}
+
+	// Verify events
+	if receivedEvents[0] != (WorkspaceChanged{Name: "2"}) {
+		t.Errorf("Event 0 = %+v, want WorkspaceChanged{2}", receivedEvents[0])
+	}
+	if receivedEvents[1] != (ActiveWindowChanged{Class: "kitty", Title: "Terminal"}) {
+		t.Errorf("Event 1 = %+v, want ActiveWindowChanged{kitty Terminal}", receivedEvents[1])
+	}
+	if receivedEvents[2] != (FullscreenChanged{Fullscreen: true}) {
+		t.Errorf("Event 2 = %+v, want FullscreenChanged{true}", receivedEvents[2])
+	}
 
-	// Wait for events or timeout
+	// Cancelling closes the channel
+	cancel()
 	select {
-	case <-done:
-		// Success
+	case _, ok := <-events:
+		if ok {
+			// Drain anything already in flight
+			for range events {
+			}
+		}
 	case <-time.After(2 * time.Second):
-		t.Fatal("Timeout waiting for events")
+		t.Fatal("Timeout waiting for the event channel to close")
 	}
+}
 
-	if len(receivedEvents) != 3 {
-		t.Fatalf("Received %d events, want 3", len(receivedEvents))
+func TestClient_SubscribeNoSocket(t *testing.T) {
+	client := &Client{
+		eventSocket: filepath.Join(t.TempDir(), "missing.sock"),
 	}
 
-	// Verify events
-	if receivedEvents[0].Type != "workspace" || receivedEvents[0].Data != "2" {
-		t.Errorf("Event 0 = %+v, want workspace>>2", receivedEvents[0])
-	}
-	if receivedEvents[1].Type != "activewindow" || receivedEvents[1].Data != "kitty,Terminal" {
-		t.Errorf("Event 1 = %+v, want activewindow>>kitty,Terminal", receivedEvents[1])
-	}
-	if receivedEvents[2].Type != "fullscreen" || receivedEvents[2].Data != "1" {
-		t.Errorf("Event 2 = %+v, want fullscreen>>1", receivedEvents[2])
+	if _, err := client.Subscribe(context.Background()); err == nil {
+		t.Error("Subscribe() should fail when the event socket does not exist")
 	}
 }
 
@@ -417,3 +558,133 @@ func TestClient_SwitchWorkspace(t *testing.T) {
 		t.Errorf("SwitchWorkspace() error = %v", err)
 	}
 }
+
+func TestClient_Query(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	resp, err := client.Query("version")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if !strings.HasPrefix(string(resp), "Hyprland 0.45.0") {
+		t.Errorf("Query(version) = %q", resp)
+	}
+}
+
+func TestClient_QueryJSON(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	var clients []Window
+	if err := client.QueryJSON("clients", &clients); err != nil {
+		t.Fatalf("QueryJSON() error = %v", err)
+	}
+	if len(clients) != 2000 {
+		t.Fatalf("QueryJSON(clients) returned %d windows, want 2000", len(clients))
+	}
+	if clients[1999].Address != "0x7cf" {
+		t.Errorf("last client address = %v, want 0x7cf", clients[1999].Address)
+	}
+
+	// Non-JSON replies are reported as parse errors
+	var v any
+	if err := client.QueryJSON("keyword general:gaps_in 5", &v); err == nil {
+		t.Error("QueryJSON() with non-JSON reply should error")
+	}
+}
+
+func TestClient_Batch(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	tests := []struct {
+		name     string
+		commands []string
+		want     string
+		wantErr  bool
+	}{
+		{"empty", nil, "", false},
+		{"single", []string{"dispatch workspace 2"}, "[[BATCH]]dispatch workspace 2", false},
+		{
+			"several",
+			[]string{"keyword general:gaps_in 5", "dispatch workspace 2"},
+			"[[BATCH]]keyword general:gaps_in 5;dispatch workspace 2",
+			false,
+		},
+		{"separator in command", []string{"dispatch exec a; b"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Batch(tt.commands...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Batch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(resp) != tt.want {
+				t.Errorf("Batch() sent %q, want %q", resp, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_MoveToWorkspace(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	err := client.MoveToWorkspace("3", 12345)
+	if err != nil {
+		t.Errorf("MoveToWorkspace() error = %v", err)
+	}
+}
+
+func TestClient_Exec(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	if err := client.Exec([]string{"workspace 3"}, "xdg-open", "/tmp/a b.txt"); err != nil {
+		t.Errorf("Exec() error = %v", err)
+	}
+	if err := client.Exec(nil); err == nil {
+		t.Error("Exec() with no command should error")
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "'plain'"},
+		{"/tmp/a b.txt", "'/tmp/a b.txt'"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf ~); `x`", "'$(rm -rf ~); `x`'"},
+		{"", "''"},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.input); got != tt.expected {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.input, got, tt.expected)
+		}
+	}
+}
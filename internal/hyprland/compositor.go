@@ -0,0 +1,148 @@
+package hyprland
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// This file adapts Client to the compositor.Compositor interface.
+
+// Name returns "Hyprland".
+func (c *Client) Name() string {
+	return "Hyprland"
+}
+
+// ActiveWorkspace returns the name of the focused workspace.
+func (c *Client) ActiveWorkspace() (string, error) {
+	ws, err := c.GetActiveWorkspace()
+	if err != nil {
+		return "", err
+	}
+	return ws.Name, nil
+}
+
+// WindowWorkspace returns the workspace of the most recently focused
+// window owned by pid.
+func (c *Client) WindowWorkspace(pid int) (string, error) {
+	clients, err := c.GetClients()
+	if err != nil {
+		return "", err
+	}
+
+	var found *Window
+	for i := range clients {
+		win := &clients[i]
+		if win.PID == pid && (found == nil || win.FocusHistoryID < found.FocusHistoryID) {
+			found = win
+		}
+	}
+	if found == nil {
+		return "", fmt.Errorf("no window with pid %d", pid)
+	}
+	return found.Workspace.Name, nil
+}
+
+// ExecOn switches to workspace and launches argv with a window rule that
+// places its windows there, even if they appear after the user moves on.
+func (c *Client) ExecOn(workspace string, argv ...string) error {
+	if err := c.FocusWorkspace(workspace); err != nil {
+		return err
+	}
+	return c.Exec([]string{"workspace " + Selector(workspace)}, argv...)
+}
+
+// FocusWindow focuses the most recently focused window, other than
+// Warren's own, for which match(title, class) is true.
+func (c *Client) FocusWindow(match func(title, class string) bool) (bool, error) {
+	clients, err := c.GetClients()
+	if err != nil {
+		return false, err
+	}
+
+	pid := os.Getpid()
+	var found *Window
+	for i := range clients {
+		win := &clients[i]
+		if win.PID == pid || !match(win.Title, win.Class) {
+			continue
+		}
+		if found == nil || win.FocusHistoryID < found.FocusHistoryID {
+			found = win
+		}
+	}
+	if found == nil {
+		return false, nil
+	}
+	return true, c.Dispatch("focuswindow address:" + found.Address)
+}
+
+// FocusNextWindow focuses the next window to open (an openwindow event).
+func (c *Client) FocusNextWindow(ctx context.Context) (<-chan error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	events, err := c.Subscribe(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		defer cancel()
+		for event := range events {
+			if opened, ok := event.(WindowOpened); ok {
+				// Event addresses lack the 0x prefix used by dispatchers
+				result <- c.Dispatch("focuswindow address:0x" + opened.Address)
+				return
+			}
+		}
+		result <- ctx.Err()
+	}()
+	return result, nil
+}
+
+// WorkspaceChanges delivers the workspace Warren is now on after
+// workspace switches, special workspaces being toggled, and windows
+// moving between workspaces. The channel is closed when ctx is cancelled.
+func (c *Client) WorkspaceChanges(ctx context.Context) (<-chan string, error) {
+	events, err := c.Subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan string)
+	go func() {
+		defer close(changes)
+		for event := range events {
+			var workspace string
+			switch e := event.(type) {
+			case WorkspaceChanged:
+				workspace = e.Name
+			case WindowMoved:
+				workspace = e.Workspace
+			case SpecialWorkspaceChanged:
+				workspace = e.Name
+				if workspace == "" {
+					// The special workspace was closed, uncovering the
+					// regular one
+					ws, err := c.GetActiveWorkspace()
+					if err != nil {
+						slog.Warn("Failed to get active workspace", "err", err)
+						continue
+					}
+					workspace = ws.Name
+				}
+			default:
+				continue
+			}
+
+			select {
+			case changes <- workspace:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -0,0 +1,172 @@
+package hyprland
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_ActiveWorkspace(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	got, err := client.ActiveWorkspace()
+	if err != nil {
+		t.Fatalf("ActiveWorkspace() error = %v", err)
+	}
+	if got != "1" {
+		t.Errorf("ActiveWorkspace() = %q, want %q", got, "1")
+	}
+}
+
+func TestClient_WindowWorkspace(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	got, err := client.WindowWorkspace(4242)
+	if err != nil {
+		t.Fatalf("WindowWorkspace() error = %v", err)
+	}
+	if got != "special:files" {
+		t.Errorf("WindowWorkspace(4242) = %q, want the most recently focused window's %q", got, "special:files")
+	}
+
+	if _, err := client.WindowWorkspace(1); err == nil {
+		t.Error("WindowWorkspace() for a pid without windows should error")
+	}
+}
+
+func TestClient_ExecOn(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	if err := client.ExecOn("media", "xdg-open", "/tmp/film.mkv"); err != nil {
+		t.Errorf("ExecOn() error = %v", err)
+	}
+}
+
+func TestClient_WorkspaceChanges(t *testing.T) {
+	mockEvents := []string{
+		"workspace>>2",
+		"activewindow>>kitty,Terminal",
+		"movewindow>>55d0a1b2c3d4,code",
+		"activespecial>>special:files,DP-1",
+		"activespecial>>,DP-1", // Closing the special workspace uncovers "1"
+	}
+
+	eventSocket, cleanupEvents := setupMockEventServer(t, mockEvents)
+	defer cleanupEvents()
+	commandSocket, cleanupCommands := setupMockCommandServer(t)
+	defer cleanupCommands()
+
+	client := &Client{
+		commandSocket: commandSocket,
+		eventSocket:   eventSocket,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := client.WorkspaceChanges(ctx)
+	if err != nil {
+		t.Fatalf("WorkspaceChanges() error = %v", err)
+	}
+
+	want := []string{"2", "code", "special:files", "1"}
+	for i, w := range want {
+		select {
+		case got := <-changes:
+			if got != w {
+				t.Errorf("change %d = %q, want %q", i, got, w)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for change %d", i)
+		}
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestClient_FocusWindow(t *testing.T) {
+	socketPath, cleanup := setupMockCommandServer(t)
+	defer cleanup()
+
+	client := &Client{
+		commandSocket: socketPath,
+	}
+
+	titled := func(name string) func(title, class string) bool {
+		return func(title, _ string) bool { return strings.HasPrefix(title, name) }
+	}
+
+	found, err := client.FocusWindow(titled("paper.pdf"))
+	if err != nil || !found {
+		t.Errorf("FocusWindow(paper.pdf) = %v, %v, want true", found, err)
+	}
+	found, err = client.FocusWindow(titled("missing.txt"))
+	if err != nil || found {
+		t.Errorf("FocusWindow(missing.txt) = %v, %v, want false", found, err)
+	}
+}
+
+func TestClient_FocusNextWindow(t *testing.T) {
+	eventSocket, cleanupEvents := setupMockEventServer(t, []string{
+		"workspace>>2",
+		"openwindow>>80e62df0,2,org.gnome.Evince,paper.pdf",
+	})
+	defer cleanupEvents()
+	commandSocket, cleanupCommands := setupMockCommandServer(t)
+	defer cleanupCommands()
+
+	client := &Client{
+		commandSocket: commandSocket,
+		eventSocket:   eventSocket,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result, err := client.FocusNextWindow(ctx)
+	if err != nil {
+		t.Fatalf("FocusNextWindow() error = %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("FocusNextWindow() result = %v, want nil", err)
+	}
+}
+
+func TestClient_FocusNextWindowTimeout(t *testing.T) {
+	eventSocket, cleanup := setupMockEventServer(t, []string{"workspace>>2"})
+	defer cleanup()
+
+	client := &Client{
+		eventSocket: eventSocket,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	result, err := client.FocusNextWindow(ctx)
+	if err != nil {
+		t.Fatalf("FocusNextWindow() error = %v", err)
+	}
+	if err := <-result; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FocusNextWindow() result = %v, want context.DeadlineExceeded", err)
+	}
+}
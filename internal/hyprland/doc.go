@@ -3,8 +3,10 @@
 // This package implements a client for Hyprland's IPC protocol, allowing Warren to:
 //   - Query active workspace and window information
 //   - Listen for Hyprland events (workspace changes, window events, etc.)
-//   - Send commands to Hyprland
-//   - Maintain per-workspace directory memory
+//   - Send commands to Hyprland, singly, batched, or as raw hyprctl queries
+//
+// Client implements compositor.Compositor, which is how Warren itself uses
+// it; per-workspace directory memory lives in package compositor.
 //
 // The package gracefully handles non-Hyprland environments by providing detection
 // and error handling that allows Warren to function without Hyprland integration.
@@ -22,11 +24,22 @@
 //	    // Handle error
 //	}
 //
+// Other requests go through Query, QueryJSON and Batch:
+//
+//	var clients []Window
+//	err = client.QueryJSON("clients", &clients)
+//	_, err = client.Batch("dispatch workspace 2", "dispatch focuswindow class:kitty")
+//
 // Event listening:
 //
-//	client.ListenEvents(func(event Event) {
-//	    if event.Type == "workspace" {
-//	        // Handle workspace change
+//	events, err := client.Subscribe(ctx)
+//	if err != nil {
+//	    // Handle error
+//	}
+//	for event := range events {
+//	    switch e := event.(type) {
+//	    case WorkspaceChanged:
+//	        // Handle workspace change to e.Name
 //	    }
-//	})
+//	}
 package hyprland
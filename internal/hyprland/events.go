@@ -0,0 +1,271 @@
+package hyprland
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+)
+
+// Event is a parsed event from Hyprland's event socket. Its concrete type
+// is one of the event structs below; events Warren has no type for arrive
+// as UnknownEvent.
+//
+// Hyprland sends most events twice, in a v1 form and a v2 form with extra
+// IDs. Only the v1 forms are parsed; the v2 duplicates arrive as
+// UnknownEvent so consumers don't see every change twice.
+type Event interface {
+	event()
+}
+
+// WorkspaceChanged is sent when the focused workspace changes
+// (workspace>>NAME).
+type WorkspaceChanged struct {
+	Name string
+}
+
+// SpecialWorkspaceChanged is sent when a special workspace is shown on or
+// hidden from a monitor (activespecial>>NAME,MONITOR). Name is empty when
+// the special workspace was closed.
+type SpecialWorkspaceChanged struct {
+	Name    string
+	Monitor string
+}
+
+// WorkspaceCreated is sent when a workspace is created (createworkspace>>NAME).
+type WorkspaceCreated struct {
+	Name string
+}
+
+// WorkspaceDestroyed is sent when a workspace is destroyed
+// (destroyworkspace>>NAME).
+type WorkspaceDestroyed struct {
+	Name string
+}
+
+// MonitorFocused is sent when focus moves to another monitor
+// (focusedmon>>MONITOR,WORKSPACE).
+type MonitorFocused struct {
+	Monitor   string
+	Workspace string
+}
+
+// MonitorAdded is sent when a monitor is connected (monitoradded>>NAME).
+type MonitorAdded struct {
+	Name string
+}
+
+// MonitorRemoved is sent when a monitor is disconnected (monitorremoved>>NAME).
+type MonitorRemoved struct {
+	Name string
+}
+
+// ActiveWindowChanged is sent when window focus changes
+// (activewindow>>CLASS,TITLE). Both fields are empty when no window has focus.
+type ActiveWindowChanged struct {
+	Class string
+	Title string
+}
+
+// WindowOpened is sent when a window opens
+// (openwindow>>ADDRESS,WORKSPACE,CLASS,TITLE).
+type WindowOpened struct {
+	Address   string
+	Workspace string
+	Class     string
+	Title     string
+}
+
+// WindowClosed is sent when a window closes (closewindow>>ADDRESS).
+type WindowClosed struct {
+	Address string
+}
+
+// WindowMoved is sent when a window moves to another workspace
+// (movewindow>>ADDRESS,WORKSPACE).
+type WindowMoved struct {
+	Address   string
+	Workspace string
+}
+
+// FullscreenChanged is sent when the active window enters or leaves
+// fullscreen (fullscreen>>0|1).
+type FullscreenChanged struct {
+	Fullscreen bool
+}
+
+// UnknownEvent is an event without a typed form, or one whose data could
+// not be parsed.
+type UnknownEvent struct {
+	Type string // Event type (e.g., "workspacev2", "urgent")
+	Data string // Event data
+}
+
+func (WorkspaceChanged) event()        {}
+func (SpecialWorkspaceChanged) event() {}
+func (WorkspaceCreated) event()        {}
+func (WorkspaceDestroyed) event()      {}
+func (MonitorFocused) event()          {}
+func (MonitorAdded) event()            {}
+func (MonitorRemoved) event()          {}
+func (ActiveWindowChanged) event()     {}
+func (WindowOpened) event()            {}
+func (WindowClosed) event()            {}
+func (WindowMoved) event()             {}
+func (FullscreenChanged) event()       {}
+func (UnknownEvent) event()            {}
+
+// ParseEvent parses one line from the event socket ("TYPE>>DATA").
+// It reports false for lines that are not events at all.
+func ParseEvent(line string) (Event, bool) {
+	typ, data, ok := strings.Cut(line, ">>")
+	if !ok {
+		return nil, false
+	}
+
+	unknown := UnknownEvent{Type: typ, Data: data}
+	switch typ {
+	case "workspace":
+		if data == "" {
+			return unknown, true
+		}
+		return WorkspaceChanged{Name: data}, true
+
+	case "activespecial":
+		name, monitor, _ := strings.Cut(data, ",")
+		return SpecialWorkspaceChanged{Name: name, Monitor: monitor}, true
+
+	case "createworkspace":
+		return WorkspaceCreated{Name: data}, true
+
+	case "destroyworkspace":
+		return WorkspaceDestroyed{Name: data}, true
+
+	case "focusedmon":
+		monitor, workspace, ok := strings.Cut(data, ",")
+		if !ok {
+			return unknown, true
+		}
+		return MonitorFocused{Monitor: monitor, Workspace: workspace}, true
+
+	case "monitoradded":
+		return MonitorAdded{Name: data}, true
+
+	case "monitorremoved":
+		return MonitorRemoved{Name: data}, true
+
+	case "activewindow":
+		// Titles may contain commas; classes do not
+		class, title, _ := strings.Cut(data, ",")
+		return ActiveWindowChanged{Class: class, Title: title}, true
+
+	case "openwindow":
+		parts := strings.SplitN(data, ",", 4)
+		if len(parts) != 4 {
+			return unknown, true
+		}
+		return WindowOpened{Address: parts[0], Workspace: parts[1], Class: parts[2], Title: parts[3]}, true
+
+	case "closewindow":
+		return WindowClosed{Address: data}, true
+
+	case "movewindow":
+		// Workspace names may contain commas; addresses do not
+		address, workspace, ok := strings.Cut(data, ",")
+		if !ok || workspace == "" {
+			return unknown, true
+		}
+		return WindowMoved{Address: address, Workspace: workspace}, true
+
+	case "fullscreen":
+		switch data {
+		case "0":
+			return FullscreenChanged{Fullscreen: false}, true
+		case "1":
+			return FullscreenChanged{Fullscreen: true}, true
+		}
+		return unknown, true
+
+	default:
+		return unknown, true
+	}
+}
+
+// Reconnect delays for Subscribe, doubling from the first to the last
+// while Hyprland's event socket is unavailable (e.g. during a restart).
+const (
+	reconnectDelay    = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Subscribe connects to Hyprland's event socket and delivers parsed events
+// on the returned channel until ctx is cancelled, when the channel is
+// closed. If the connection drops it reconnects with backoff, so the
+// subscription survives Hyprland reloads. An error is returned only if the
+// first connection fails.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.eventSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		delay := reconnectDelay
+		for {
+			if conn != nil {
+				if c.readEvents(ctx, conn, events) {
+					delay = reconnectDelay
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				slog.Info("Hyprland event socket closed; reconnecting")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxReconnectDelay)
+
+			conn, err = dialer.DialContext(ctx, "unix", c.eventSocket)
+			if err != nil {
+				conn = nil
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// readEvents sends events read from conn until the connection ends or ctx
+// is cancelled, then closes conn. It reports whether any event was read.
+func (c *Client) readEvents(ctx context.Context, conn net.Conn, events chan<- Event) bool {
+	// Unblock the scanner when the subscription is cancelled
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	received := false
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		event, ok := ParseEvent(scanner.Text())
+		if !ok {
+			continue
+		}
+		received = true
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return received
+		}
+	}
+	return received
+}
@@ -0,0 +1,48 @@
+package hyprland
+
+import "testing"
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   Event
+		wantOK bool
+	}{
+		{"numbered workspace", "workspace>>2", WorkspaceChanged{Name: "2"}, true},
+		{"named workspace", "workspace>>code", WorkspaceChanged{Name: "code"}, true},
+		{"special workspace", "workspace>>special:files", WorkspaceChanged{Name: "special:files"}, true},
+		{"empty workspace", "workspace>>", UnknownEvent{Type: "workspace"}, true},
+		{"special opened", "activespecial>>special:files,DP-1", SpecialWorkspaceChanged{Name: "special:files", Monitor: "DP-1"}, true},
+		{"special closed", "activespecial>>,DP-1", SpecialWorkspaceChanged{Monitor: "DP-1"}, true},
+		{"workspace created", "createworkspace>>mail", WorkspaceCreated{Name: "mail"}, true},
+		{"workspace destroyed", "destroyworkspace>>mail", WorkspaceDestroyed{Name: "mail"}, true},
+		{"monitor focused", "focusedmon>>HDMI-A-1,3", MonitorFocused{Monitor: "HDMI-A-1", Workspace: "3"}, true},
+		{"monitor added", "monitoradded>>DP-2", MonitorAdded{Name: "DP-2"}, true},
+		{"monitor removed", "monitorremoved>>DP-2", MonitorRemoved{Name: "DP-2"}, true},
+		{"active window", "activewindow>>kitty,Terminal", ActiveWindowChanged{Class: "kitty", Title: "Terminal"}, true},
+		{"active window title with comma", "activewindow>>firefox,Hello, world", ActiveWindowChanged{Class: "firefox", Title: "Hello, world"}, true},
+		{"no active window", "activewindow>>,", ActiveWindowChanged{}, true},
+		{"window opened", "openwindow>>80e62df0,2,kitty,~/src, again", WindowOpened{Address: "80e62df0", Workspace: "2", Class: "kitty", Title: "~/src, again"}, true},
+		{"window opened malformed", "openwindow>>80e62df0,2", UnknownEvent{Type: "openwindow", Data: "80e62df0,2"}, true},
+		{"window closed", "closewindow>>80e62df0", WindowClosed{Address: "80e62df0"}, true},
+		{"window moved", "movewindow>>122e5f40,3", WindowMoved{Address: "122e5f40", Workspace: "3"}, true},
+		{"window moved to special", "movewindow>>122e5f40,special:files", WindowMoved{Address: "122e5f40", Workspace: "special:files"}, true},
+		{"window moved malformed", "movewindow>>122e5f40", UnknownEvent{Type: "movewindow", Data: "122e5f40"}, true},
+		{"fullscreen on", "fullscreen>>1", FullscreenChanged{Fullscreen: true}, true},
+		{"fullscreen off", "fullscreen>>0", FullscreenChanged{Fullscreen: false}, true},
+		{"fullscreen malformed", "fullscreen>>yes", UnknownEvent{Type: "fullscreen", Data: "yes"}, true},
+		{"v2 duplicate", "workspacev2>>2,2", UnknownEvent{Type: "workspacev2", Data: "2,2"}, true},
+		{"unknown type", "urgent>>80e62df0", UnknownEvent{Type: "urgent", Data: "80e62df0"}, true},
+		{"not an event", "garbage", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseEvent(tt.line)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("ParseEvent(%q) = (%#v, %v), want (%#v, %v)", tt.line, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
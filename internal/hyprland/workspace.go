@@ -0,0 +1,25 @@
+package hyprland
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SpecialPrefix starts the name of every special (scratchpad) workspace:
+// "special" for the default one, "special:NAME" for named ones.
+const SpecialPrefix = "special"
+
+// IsSpecial reports whether a workspace name refers to a special workspace.
+func IsSpecial(name string) bool {
+	return name == SpecialPrefix || strings.HasPrefix(name, SpecialPrefix+":")
+}
+
+// Selector returns the argument that picks a workspace by name in
+// dispatchers and window rules: numbered and special workspaces are
+// given as they are, named ones as "name:NAME".
+func Selector(name string) string {
+	if _, err := strconv.Atoi(name); err == nil || IsSpecial(name) {
+		return name
+	}
+	return "name:" + name
+}
@@ -0,0 +1,41 @@
+package hyprland
+
+import "testing"
+
+func TestIsSpecial(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"special", true},
+		{"special:files", true},
+		{"2", false},
+		{"code", false},
+		{"specialist", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSpecial(tt.name); got != tt.want {
+			t.Errorf("IsSpecial(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSelector(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"3", "3"},
+		{"-1", "-1"},
+		{"special", "special"},
+		{"special:files", "special:files"},
+		{"code", "name:code"},
+	}
+
+	for _, tt := range tests {
+		if got := Selector(tt.name); got != tt.want {
+			t.Errorf("Selector(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Package ipc provides Warren's control socket for scripting.
+//
+// Like Hyprland's command socket, the server listens on a Unix socket and
+// accepts newline-terminated text commands:
+//
+//	cd /path/to/dir     Navigate to a directory
+//	select file.txt     Select an entry (name or absolute path)
+//	get-selection       Print the selected path
+//	pwd                 Print the current directory
+//	reload              Re-read the current directory
+//
+// Each command receives one response line: the result on success (possibly
+// empty) or "error: <message>" on failure. The package has no GTK
+// dependency; the UI supplies a Handler that executes commands.
+//
+// Manual testing:
+//
+//	echo "cd /tmp" | socat - UNIX-CONNECT:$XDG_RUNTIME_DIR/warren/warren.sock
+package ipc
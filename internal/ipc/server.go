@@ -0,0 +1,196 @@
+package ipc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Command names understood by the control socket.
+const (
+	CmdCd           = "cd"
+	CmdSelect       = "select"
+	CmdGetSelection = "get-selection"
+	CmdPwd          = "pwd"
+	CmdReload       = "reload"
+)
+
+// argRequired records which commands take an argument.
+var argRequired = map[string]bool{
+	CmdCd:           true,
+	CmdSelect:       true,
+	CmdGetSelection: false,
+	CmdPwd:          false,
+	CmdReload:       false,
+}
+
+// Command is a parsed control command.
+type Command struct {
+	Name string // Command name (e.g., "cd")
+	Arg  string // Argument, empty for commands without one
+}
+
+// Handler executes a command and returns its textual result.
+// It is called from the server's connection goroutines.
+type Handler func(cmd Command) (string, error)
+
+// ParseCommand parses a single command line.
+func ParseCommand(line string) (Command, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return Command{}, fmt.Errorf("empty command")
+	}
+
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	needsArg, known := argRequired[name]
+	if !known {
+		return Command{}, fmt.Errorf("unknown command %q", name)
+	}
+	if needsArg && arg == "" {
+		return Command{}, fmt.Errorf("%s requires an argument", name)
+	}
+	if !needsArg && arg != "" {
+		return Command{}, fmt.Errorf("%s takes no argument", name)
+	}
+
+	return Command{Name: name, Arg: arg}, nil
+}
+
+// SocketPath returns the control socket location:
+// $XDG_RUNTIME_DIR/warren/warren.sock, or /run/user/$UID when unset.
+func SocketPath() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	return filepath.Join(runtimeDir, "warren", "warren.sock")
+}
+
+// Server accepts control connections on a Unix socket.
+type Server struct {
+	path     string
+	listener net.Listener
+	handler  Handler
+	wg       sync.WaitGroup
+}
+
+// Listen creates the socket at path and starts serving in the background.
+// A stale socket left behind by a crashed instance is replaced; a socket
+// with a live listener is reported as an error.
+func Listen(path string, handler Handler) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("control socket %s is already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	s := &Server{
+		path:     path,
+		listener: listener,
+		handler:  handler,
+	}
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	return s, nil
+}
+
+// Path returns the socket path the server listens on.
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Close stops accepting connections and removes the socket file.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	if removeErr := os.Remove(s.path); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// acceptLoop accepts connections until the listener is closed.
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				slog.Warn("Control socket accept error", "err", err)
+			}
+			return
+		}
+		go s.serve(conn)
+	}
+}
+
+// serve answers each command line received on conn.
+func (s *Server) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var response string
+		cmd, err := ParseCommand(scanner.Text())
+		if err == nil {
+			response, err = s.handler(cmd)
+		}
+		if err != nil {
+			response = "error: " + err.Error()
+		}
+
+		if _, err := fmt.Fprintln(conn, response); err != nil {
+			return
+		}
+	}
+}
+
+// Send connects to the control socket, sends one command line, and returns
+// the response. Error responses are returned as errors.
+func Send(path, line string) (string, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to Warren: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := fmt.Fprintln(conn, line); err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	response = strings.TrimSuffix(response, "\n")
+
+	if msg, ok := strings.CutPrefix(response, "error: "); ok {
+		return "", errors.New(msg)
+	}
+	return response, nil
+}
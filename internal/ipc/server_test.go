@@ -0,0 +1,159 @@
+package ipc
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Command
+		wantErr bool
+	}{
+		{"cd /tmp", Command{Name: CmdCd, Arg: "/tmp"}, false},
+		{"cd /path with spaces ", Command{Name: CmdCd, Arg: "/path with spaces"}, false},
+		{"select file.txt", Command{Name: CmdSelect, Arg: "file.txt"}, false},
+		{"get-selection", Command{Name: CmdGetSelection}, false},
+		{"  pwd\r", Command{Name: CmdPwd}, false},
+		{"reload", Command{Name: CmdReload}, false},
+		{"", Command{}, true},
+		{"cd", Command{}, true},
+		{"reload now", Command{}, true},
+		{"rm -rf /", Command{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := ParseCommand(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCommand(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCommand(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if got := SocketPath(); got != "/run/user/1000/warren/warren.sock" {
+		t.Errorf("SocketPath() = %q", got)
+	}
+}
+
+// shortSocketPath returns a socket path short enough for sun_path limits.
+func shortSocketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "wipc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "s.sock")
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	path := shortSocketPath(t)
+
+	var mu sync.Mutex
+	current := "/home"
+	handler := func(cmd Command) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch cmd.Name {
+		case CmdCd:
+			current = cmd.Arg
+			return "", nil
+		case CmdPwd:
+			return current, nil
+		default:
+			return "", fmt.Errorf("not supported")
+		}
+	}
+
+	server, err := Listen(path, handler)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	if _, err := Send(path, "cd /tmp"); err != nil {
+		t.Fatalf("cd failed: %v", err)
+	}
+	got, err := Send(path, "pwd")
+	if err != nil {
+		t.Fatalf("pwd failed: %v", err)
+	}
+	if got != "/tmp" {
+		t.Errorf("pwd = %q, want /tmp", got)
+	}
+
+	if _, err := Send(path, "reload"); err == nil || err.Error() != "not supported" {
+		t.Errorf("reload error = %v, want 'not supported'", err)
+	}
+	if _, err := Send(path, "bogus"); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("bogus error = %v, want unknown command", err)
+	}
+
+	if err := server.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Socket file should be removed on Close")
+	}
+}
+
+func TestServerMultipleCommandsPerConnection(t *testing.T) {
+	path := shortSocketPath(t)
+	server, err := Listen(path, func(cmd Command) (string, error) { return cmd.Name, nil })
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := fmt.Fprint(conn, "pwd\nreload\n"); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 64)
+	var out strings.Builder
+	for out.Len() < len("pwd\nreload\n") {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		out.Write(buf[:n])
+	}
+	if out.String() != "pwd\nreload\n" {
+		t.Errorf("Responses = %q", out.String())
+	}
+}
+
+func TestListenStaleAndBusySocket(t *testing.T) {
+	path := shortSocketPath(t)
+
+	// A leftover regular file stands in for a stale socket
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	server, err := Listen(path, func(Command) (string, error) { return "", nil })
+	if err != nil {
+		t.Fatalf("Listen should replace stale socket: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	if _, err := Listen(path, func(Command) (string, error) { return "", nil }); err == nil {
+		t.Error("Listen should fail while another server is listening")
+	}
+}
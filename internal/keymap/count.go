@@ -0,0 +1,49 @@
+package keymap
+
+import "strconv"
+
+// maxCount caps numeric prefixes so a held-down digit can't overflow.
+const maxCount = 9999
+
+// Counter accumulates a vim-style numeric prefix ("5" in "5j").
+type Counter struct {
+	n int
+}
+
+// Feed consumes ev if it extends the count and reports whether it did.
+// A leading 0 is not a count, so "0" remains available as a binding.
+func (c *Counter) Feed(ev Event) bool {
+	if ev.Mods != 0 || ev.Rune < '0' || ev.Rune > '9' {
+		return false
+	}
+	digit := int(ev.Rune - '0')
+	if c.n == 0 && digit == 0 {
+		return false
+	}
+	c.n = min(c.n*10+digit, maxCount)
+	return true
+}
+
+// Take returns the accumulated count, or 1 if none was typed, and whether
+// a count was typed. The counter is reset.
+func (c *Counter) Take() (int, bool) {
+	n := c.n
+	c.n = 0
+	if n == 0 {
+		return 1, false
+	}
+	return n, true
+}
+
+// Reset discards the accumulated count.
+func (c *Counter) Reset() {
+	c.n = 0
+}
+
+// String returns the count typed so far, or "" if none.
+func (c *Counter) String() string {
+	if c.n == 0 {
+		return ""
+	}
+	return strconv.Itoa(c.n)
+}
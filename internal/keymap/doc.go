@@ -0,0 +1,16 @@
+// Package keymap parses keybinding strings and matches key presses
+// against them, including multi-key chords.
+//
+// A binding is one or more keys separated by spaces. Each key is a GTK key
+// name or a single character, optionally prefixed with modifiers:
+//
+//	j            plain key
+//	<Ctrl>d      key with a modifier
+//	<Shift>g     same as "G"
+//	g g          two-key sequence (press g, then g again)
+//
+// A Keymap holds the pending part of a chord between presses and drops it
+// after a timeout. Bindings that conflict (identical, or one a prefix of
+// another) are rejected when they are added. The package has no GTK
+// dependency; callers translate GDK events into Event values.
+package keymap
@@ -0,0 +1,425 @@
+package keymap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Modifier is a set of modifier keys.
+type Modifier uint
+
+// Modifier flags.
+const (
+	ModShift Modifier = 1 << iota
+	ModCtrl
+	ModAlt
+	ModSuper
+)
+
+// DefaultTimeout is how long a partial chord waits for its next key.
+const DefaultTimeout = time.Second
+
+// modifierNames maps the names accepted inside <...> to modifiers.
+var modifierNames = map[string]Modifier{
+	"shift":   ModShift,
+	"ctrl":    ModCtrl,
+	"control": ModCtrl,
+	"primary": ModCtrl,
+	"alt":     ModAlt,
+	"mod1":    ModAlt,
+	"super":   ModSuper,
+}
+
+// keyAliases maps GTK names of printable keys to their character so that
+// "period" and "." are recognised as the same key.
+var keyAliases = map[string]string{
+	"space":        " ",
+	"period":       ".",
+	"comma":        ",",
+	"slash":        "/",
+	"backslash":    "\\",
+	"question":     "?",
+	"exclam":       "!",
+	"minus":        "-",
+	"plus":         "+",
+	"equal":        "=",
+	"colon":        ":",
+	"semicolon":    ";",
+	"underscore":   "_",
+	"asciitilde":   "~",
+	"grave":        "`",
+	"apostrophe":   "'",
+	"quotedbl":     "\"",
+	"numbersign":   "#",
+	"dollar":       "$",
+	"percent":      "%",
+	"ampersand":    "&",
+	"asterisk":     "*",
+	"at":           "@",
+	"bracketleft":  "[",
+	"bracketright": "]",
+	"braceleft":    "{",
+	"braceright":   "}",
+	"parenleft":    "(",
+	"parenright":   ")",
+	"less":         "<",
+	"greater":      ">",
+	"bar":          "|",
+}
+
+// modifierKeys are key names for the modifier keys themselves. Pressing
+// them on their own must not interrupt a chord.
+var modifierKeys = map[string]bool{
+	"Shift_L": true, "Shift_R": true,
+	"Control_L": true, "Control_R": true,
+	"Alt_L": true, "Alt_R": true,
+	"Super_L": true, "Super_R": true,
+	"Meta_L": true, "Meta_R": true,
+	"ISO_Level3_Shift": true, "Caps_Lock": true,
+}
+
+// namedKeys are the GTK names of non-printable keys that bindings may use.
+var namedKeys = map[string]bool{
+	"Return": true, "KP_Enter": true, "Escape": true, "Tab": true, "ISO_Left_Tab": true,
+	"BackSpace": true, "Delete": true, "Insert": true,
+	"Home": true, "End": true, "Page_Up": true, "Page_Down": true,
+	"Up": true, "Down": true, "Left": true, "Right": true,
+	"F1": true, "F2": true, "F3": true, "F4": true, "F5": true, "F6": true,
+	"F7": true, "F8": true, "F9": true, "F10": true, "F11": true, "F12": true,
+	"Menu": true, "Print": true, "Pause": true,
+}
+
+// Key is a single key press with modifiers.
+type Key struct {
+	Name string   // Single character or GTK key name (e.g., "Return")
+	Mods Modifier // Required modifiers
+}
+
+// String formats the key in the config syntax.
+func (k Key) String() string {
+	var b strings.Builder
+	for _, m := range []struct {
+		mod  Modifier
+		name string
+	}{{ModCtrl, "Ctrl"}, {ModAlt, "Alt"}, {ModSuper, "Super"}, {ModShift, "Shift"}} {
+		if k.Mods&m.mod != 0 {
+			b.WriteString("<" + m.name + ">")
+		}
+	}
+	if k.Name == " " {
+		b.WriteString("space")
+	} else {
+		b.WriteString(k.Name)
+	}
+	return b.String()
+}
+
+// Known reports whether the key names a real key: a single printable
+// character or a recognised GTK key name. ParseKey accepts any name so
+// unusual keys still work; Known lets config validation flag typos.
+func (k Key) Known() bool {
+	if namedKeys[k.Name] {
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(k.Name)
+	return size == len(k.Name) && unicode.IsPrint(r)
+}
+
+// Matches reports whether a key press triggers this key.
+func (k Key) Matches(ev Event) bool {
+	if k.Mods != ev.Mods {
+		return false
+	}
+	if k.Name == ev.Name {
+		return true
+	}
+	r, size := utf8.DecodeRuneInString(k.Name)
+	return size == len(k.Name) && r == ev.Rune
+}
+
+// Sequence is a chord of one or more keys pressed in turn.
+type Sequence []Key
+
+// String formats the sequence in the config syntax.
+func (s Sequence) String() string {
+	parts := make([]string, len(s))
+	for i, k := range s {
+		parts[i] = k.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// accelNames maps printable characters to the GTK key names that
+// accelerator strings need, the reverse of keyAliases.
+var accelNames = func() map[string]string {
+	names := make(map[string]string, len(keyAliases))
+	for name, char := range keyAliases {
+		names[char] = name
+	}
+	return names
+}()
+
+// Accelerator formats the key in GTK accelerator syntax, as understood by
+// gtk_accelerator_parse: "<Ctrl>d", "period", "G".
+func (k Key) Accelerator() string {
+	var b strings.Builder
+	for _, m := range []struct {
+		mod  Modifier
+		name string
+	}{{ModCtrl, "Ctrl"}, {ModAlt, "Alt"}, {ModSuper, "Super"}, {ModShift, "Shift"}} {
+		if k.Mods&m.mod != 0 {
+			b.WriteString("<" + m.name + ">")
+		}
+	}
+	if name, ok := accelNames[k.Name]; ok {
+		b.WriteString(name)
+	} else {
+		b.WriteString(k.Name)
+	}
+	return b.String()
+}
+
+// Accelerator formats the sequence for GtkShortcutsShortcut, which joins
+// keys pressed in turn with "&".
+func (s Sequence) Accelerator() string {
+	keys := make([]string, len(s))
+	for i, k := range s {
+		keys[i] = k.Accelerator()
+	}
+	return strings.Join(keys, "&")
+}
+
+// Event is a key press as delivered by the toolkit.
+type Event struct {
+	Name string   // GTK key name (e.g., "g", "G", "Return")
+	Rune rune     // Character produced by the key, 0 if none
+	Mods Modifier // Modifiers held during the press
+}
+
+// NewEvent builds an event, dropping Shift when it was consumed to
+// produce a printable character: Shift+g arrives as "G" and matches "G".
+func NewEvent(name string, r rune, mods Modifier) Event {
+	if r != 0 && unicode.IsPrint(r) {
+		mods &^= ModShift
+	}
+	return Event{Name: name, Rune: r, Mods: mods}
+}
+
+// IsModifierKey reports whether the event is a bare modifier key press.
+func (ev Event) IsModifierKey() bool {
+	return modifierKeys[ev.Name]
+}
+
+// KeyFromEvent returns the key that a press corresponds to, for recording
+// bindings. Printable keys are named by their character, so the result's
+// String form parses back to a key that matches the press.
+func KeyFromEvent(ev Event) Key {
+	if unicode.IsPrint(ev.Rune) {
+		return Key{Name: string(ev.Rune), Mods: ev.Mods}
+	}
+	return Key{Name: ev.Name, Mods: ev.Mods}
+}
+
+// ParseKey parses a single key such as "j", "<Ctrl>d" or "<Shift>Tab".
+func ParseKey(s string) (Key, error) {
+	// A lone space is the space key, not an empty binding
+	if s == " " {
+		return Key{Name: " "}, nil
+	}
+
+	var key Key
+	rest := strings.TrimSpace(s)
+	for strings.HasPrefix(rest, "<") {
+		end := strings.Index(rest, ">")
+		if end < 0 {
+			break // "<" used as a key name
+		}
+		mod, ok := modifierNames[strings.ToLower(rest[1:end])]
+		if !ok {
+			return Key{}, fmt.Errorf("unknown modifier %q in %q", rest[:end+1], s)
+		}
+		key.Mods |= mod
+		rest = rest[end+1:]
+	}
+	if rest == "" {
+		return Key{}, fmt.Errorf("missing key in %q", s)
+	}
+
+	if alias, ok := keyAliases[rest]; ok {
+		rest = alias
+	}
+	key.Name = rest
+
+	// Shift on a printable key is expressed through the character itself
+	r, size := utf8.DecodeRuneInString(rest)
+	if key.Mods&ModShift != 0 && size == len(rest) && unicode.IsPrint(r) {
+		if !unicode.IsLetter(r) {
+			return Key{}, fmt.Errorf("use the shifted character instead of <Shift> in %q", s)
+		}
+		key.Name = string(unicode.ToUpper(r))
+		key.Mods &^= ModShift
+	}
+
+	return key, nil
+}
+
+// Parse parses a binding of one or more space-separated keys.
+func Parse(s string) (Sequence, error) {
+	if s == " " {
+		key, _ := ParseKey(s)
+		return Sequence{key}, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty keybinding")
+	}
+
+	seq := make(Sequence, 0, len(fields))
+	for _, field := range fields {
+		key, err := ParseKey(field)
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, key)
+	}
+	return seq, nil
+}
+
+// Binding associates a key sequence with an action name.
+type Binding struct {
+	Keys   Sequence
+	Action string
+}
+
+// Keymap matches key presses against bindings, tracking partial chords.
+type Keymap struct {
+	Timeout time.Duration // How long a partial chord stays pending
+
+	bindings []Binding
+	pending  []Event
+	deadline time.Time
+}
+
+// New creates an empty keymap using DefaultTimeout.
+func New() *Keymap {
+	return &Keymap{Timeout: DefaultTimeout}
+}
+
+// Bind parses spec and binds it to action. It returns an error, and adds
+// nothing, if spec is invalid or conflicts with an existing binding.
+func (m *Keymap) Bind(spec, action string) error {
+	seq, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("%s: %w", action, err)
+	}
+
+	for _, b := range m.bindings {
+		if isPrefix(seq, b.Keys) || isPrefix(b.Keys, seq) {
+			return fmt.Errorf("%s: %q conflicts with %q bound to %s", action, seq, b.Keys, b.Action)
+		}
+	}
+
+	m.bindings = append(m.bindings, Binding{Keys: seq, Action: action})
+	return nil
+}
+
+// Bindings returns the bindings in the order they were bound, for
+// listing them in help.
+func (m *Keymap) Bindings() []Binding {
+	return slices.Clone(m.bindings)
+}
+
+// Feed processes a key press. It returns the action of a completed
+// binding, if any, and whether the key was consumed (as a complete or
+// partial chord). Unconsumed keys should be passed on to other handlers.
+func (m *Keymap) Feed(ev Event, now time.Time) (action string, handled bool) {
+	if ev.IsModifierKey() {
+		return "", false
+	}
+
+	if len(m.pending) > 0 && now.After(m.deadline) {
+		m.pending = nil
+	}
+
+	// Escape abandons a partial chord
+	if len(m.pending) > 0 && ev.Name == "Escape" && ev.Mods == 0 {
+		m.pending = nil
+		return "", true
+	}
+
+	m.pending = append(m.pending, ev)
+
+	partial := false
+	for _, b := range m.bindings {
+		if !matchesPrefix(b.Keys, m.pending) {
+			continue
+		}
+		if len(b.Keys) == len(m.pending) {
+			m.pending = nil
+			return b.Action, true
+		}
+		partial = true
+	}
+
+	if partial {
+		m.deadline = now.Add(m.Timeout)
+		return "", true
+	}
+
+	// The key broke off a chord; try it again as the start of a new one
+	if len(m.pending) > 1 {
+		m.pending = nil
+		return m.Feed(ev, now)
+	}
+
+	m.pending = nil
+	return "", false
+}
+
+// Pending returns the keys of a partial chord, or "" if none is pending.
+func (m *Keymap) Pending(now time.Time) string {
+	if len(m.pending) == 0 || now.After(m.deadline) {
+		return ""
+	}
+	names := make([]string, len(m.pending))
+	for i, ev := range m.pending {
+		names[i] = ev.Name
+	}
+	return strings.Join(names, " ")
+}
+
+// Reset discards any partial chord.
+func (m *Keymap) Reset() {
+	m.pending = nil
+}
+
+// isPrefix reports whether a is a prefix of (or equal to) b.
+func isPrefix(a, b Sequence) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesPrefix reports whether events match the start of seq.
+func matchesPrefix(seq Sequence, events []Event) bool {
+	if len(events) > len(seq) {
+		return false
+	}
+	for i, ev := range events {
+		if !seq[i].Matches(ev) {
+			return false
+		}
+	}
+	return true
+}
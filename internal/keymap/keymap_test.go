@@ -0,0 +1,486 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// press builds an event for a printable character.
+func press(r rune, mods Modifier) Event {
+	return NewEvent(string(r), r, mods)
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Key
+	}{
+		{"j", Key{Name: "j"}},
+		{"G", Key{Name: "G"}},
+		{"Return", Key{Name: "Return"}},
+		{"period", Key{Name: "."}},
+		{"space", Key{Name: " "}},
+		{" ", Key{Name: " "}},
+		{"<Ctrl>d", Key{Name: "d", Mods: ModCtrl}},
+		{"<control>d", Key{Name: "d", Mods: ModCtrl}},
+		{"<Ctrl><Alt>x", Key{Name: "x", Mods: ModCtrl | ModAlt}},
+		{"<Shift>g", Key{Name: "G"}},
+		{"<Ctrl><Shift>g", Key{Name: "G", Mods: ModCtrl}},
+		{"<Shift>Tab", Key{Name: "Tab", Mods: ModShift}},
+		{"<Super>Return", Key{Name: "Return", Mods: ModSuper}},
+		{"<", Key{Name: "<"}},
+		{"<Ctrl><", Key{Name: "<", Mods: ModCtrl}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseKey(tt.in)
+			if err != nil {
+				t.Fatalf("ParseKey(%q) failed: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseKey(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"<Hyper>x",
+		"<Ctrl>",
+		"<Shift>1",
+		"g <Bogus>g",
+	}
+
+	for _, in := range tests {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) expected error", in)
+		}
+	}
+}
+
+func TestKeyKnown(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{"j", true},
+		{"period", true},
+		{"space", true},
+		{"<Ctrl>d", true},
+		{"Return", true},
+		{"F5", true},
+		{"Retrun", false},
+		{"PageDown", false},
+	}
+
+	for _, tt := range tests {
+		key, err := ParseKey(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseKey(%q) error = %v", tt.spec, err)
+		}
+		if got := key.Known(); got != tt.want {
+			t.Errorf("ParseKey(%q).Known() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseSequence(t *testing.T) {
+	seq, err := Parse("g  g")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(seq) != 2 || seq.String() != "g g" {
+		t.Errorf("Parse(\"g  g\") = %v", seq)
+	}
+
+	seq, err = Parse("<Ctrl>x <Ctrl>s")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if seq.String() != "<Ctrl>x <Ctrl>s" {
+		t.Errorf("String() = %q", seq.String())
+	}
+}
+
+func TestAccelerator(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"j", "j"},
+		{"G", "G"},
+		{"<Shift>g", "G"},
+		{"period", "period"},
+		{"?", "question"},
+		{"space", "space"},
+		{"<Ctrl>comma", "<Ctrl>comma"},
+		{"Page_Down", "Page_Down"},
+		{"g g", "g&g"},
+		{"z <Ctrl>d", "z&<Ctrl>d"},
+	}
+	for _, tt := range tests {
+		seq, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.spec, err)
+		}
+		if got := seq.Accelerator(); got != tt.expected {
+			t.Errorf("Parse(%q).Accelerator() = %q, want %q", tt.spec, got, tt.expected)
+		}
+	}
+}
+
+func TestBindings(t *testing.T) {
+	m := New()
+	_ = m.Bind("j", "down")
+	_ = m.Bind("g g", "top")
+	_ = m.Bind("Down", "down")
+	_ = m.Bind("g", "conflicting")
+
+	bindings := m.Bindings()
+	var got []string
+	for _, b := range bindings {
+		got = append(got, b.Keys.String()+"="+b.Action)
+	}
+	if strings.Join(got, ",") != "j=down,g g=top,Down=down" {
+		t.Errorf("Bindings() = %v, want the valid bindings in order", got)
+	}
+
+	bindings[0].Action = "changed"
+	if m.Bindings()[0].Action != "down" {
+		t.Error("Bindings() returned the keymap's own slice")
+	}
+}
+
+func TestKeyMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		ev   Event
+		want bool
+	}{
+		{"plain letter", "j", press('j', 0), true},
+		{"wrong letter", "j", press('k', 0), false},
+		{"case sensitive", "J", press('j', 0), false},
+		{"uppercase via shift", "G", press('G', ModShift), true},
+		{"shift binding", "<Shift>g", press('G', ModShift), true},
+		{"lowercase does not match shifted", "g", press('G', ModShift), false},
+		{"ctrl required", "<Ctrl>d", press('d', 0), false},
+		{"ctrl matches", "<Ctrl>d", press('d', ModCtrl), true},
+		{"extra modifier", "d", press('d', ModCtrl), false},
+		{"ctrl shift letter", "<Ctrl><Shift>g", press('G', ModCtrl|ModShift), true},
+		{"alias by rune", "period", NewEvent("period", '.', 0), true},
+		{"char by rune", ".", NewEvent("period", '.', 0), true},
+		{"special key", "Return", NewEvent("Return", '\r', 0), true},
+		{"shift on special key", "<Shift>Tab", NewEvent("Tab", '\t', ModShift), true},
+		{"question via shift", "question", NewEvent("question", '?', ModShift), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey(tt.key)
+			if err != nil {
+				t.Fatalf("ParseKey(%q) failed: %v", tt.key, err)
+			}
+			if got := key.Matches(tt.ev); got != tt.want {
+				t.Errorf("%q.Matches(%+v) = %v, want %v", tt.key, tt.ev, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyFromEvent(t *testing.T) {
+	tests := []struct {
+		ev   Event
+		want string
+	}{
+		{press('j', 0), "j"},
+		{NewEvent("G", 'G', ModShift), "G"},
+		{press('d', ModCtrl), "<Ctrl>d"},
+		{NewEvent("space", ' ', 0), "space"},
+		{NewEvent("comma", ',', ModCtrl), "<Ctrl>,"},
+		{NewEvent("Return", '\r', 0), "Return"},
+		{NewEvent("ISO_Left_Tab", 0, ModShift), "<Shift>ISO_Left_Tab"},
+	}
+
+	for _, tt := range tests {
+		key := KeyFromEvent(tt.ev)
+		if got := key.String(); got != tt.want {
+			t.Errorf("KeyFromEvent(%+v) = %q, want %q", tt.ev, got, tt.want)
+		}
+
+		// The recorded key must parse back and match the same press
+		parsed, err := ParseKey(key.String())
+		if err != nil {
+			t.Fatalf("ParseKey(%q) failed: %v", key.String(), err)
+		}
+		if !parsed.Matches(tt.ev) {
+			t.Errorf("ParseKey(%q) does not match %+v", key.String(), tt.ev)
+		}
+	}
+}
+
+func TestBindConflicts(t *testing.T) {
+	m := New()
+	if err := m.Bind("d", "delete"); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if err := m.Bind("g g", "top"); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"d", "delete"},        // identical
+		{"d d", "delete"},      // extends a single key
+		{"g", "top"},           // prefix of a chord
+		{"g g", "top"},         // identical chord
+		{"<Shift>G", ""},       // no conflict: "G" differs from "g"
+		{"<Ctrl>d", ""},        // no conflict: modifiers differ
+		{"period", ""},         // no conflict
+		{".", "period-action"}, // alias of the binding above
+	}
+
+	for _, tt := range tests {
+		action := tt.want
+		if action == "" {
+			action = tt.spec + "-action"
+		}
+		err := m.Bind(tt.spec, action)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("Bind(%q) unexpected conflict: %v", tt.spec, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), "conflicts") {
+			t.Errorf("Bind(%q) = %v, want conflict", tt.spec, err)
+		}
+	}
+}
+
+func TestFeedChords(t *testing.T) {
+	m := New()
+	for spec, action := range map[string]string{
+		"j":       "down",
+		"g g":     "top",
+		"G":       "bottom",
+		"<Ctrl>d": "half-page",
+		"z a b":   "nested",
+	} {
+		if err := m.Bind(spec, action); err != nil {
+			t.Fatalf("Bind(%q) failed: %v", spec, err)
+		}
+	}
+
+	now := time.Now()
+	type step struct {
+		ev          Event
+		wantAction  string
+		wantHandled bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"single key", []step{{press('j', 0), "down", true}}},
+		{"unbound key", []step{{press('x', 0), "", false}}},
+		{"chord", []step{{press('g', 0), "", true}, {press('g', 0), "top", true}}},
+		{"shifted key", []step{{press('G', ModShift), "bottom", true}}},
+		{"modifier", []step{{press('d', ModCtrl), "half-page", true}}},
+		{"three keys", []step{{press('z', 0), "", true}, {press('a', 0), "", true}, {press('b', 0), "nested", true}}},
+		{"broken chord retries key", []step{{press('g', 0), "", true}, {press('j', 0), "down", true}}},
+		{"broken chord unbound key", []step{{press('g', 0), "", true}, {press('x', 0), "", false}}},
+		{"escape cancels", []step{{press('g', 0), "", true}, {NewEvent("Escape", 0x1b, 0), "", true}, {press('g', 0), "", true}}},
+		{"modifier key ignored", []step{{press('g', 0), "", true}, {NewEvent("Shift_L", 0, ModShift), "", false}, {press('g', 0), "top", true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.Reset()
+			for i, s := range tt.steps {
+				action, handled := m.Feed(s.ev, now)
+				if action != s.wantAction || handled != s.wantHandled {
+					t.Errorf("step %d (%s): Feed = (%q, %v), want (%q, %v)",
+						i, s.ev.Name, action, handled, s.wantAction, s.wantHandled)
+				}
+			}
+		})
+	}
+}
+
+func TestFeedTimeout(t *testing.T) {
+	m := New()
+	m.Timeout = 100 * time.Millisecond
+	if err := m.Bind("g g", "top"); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+
+	start := time.Now()
+	if _, handled := m.Feed(press('g', 0), start); !handled {
+		t.Fatal("first g should start a chord")
+	}
+	if got := m.Pending(start); got != "g" {
+		t.Errorf("Pending() = %q, want \"g\"", got)
+	}
+
+	// The second press arrives too late and starts a new chord instead
+	late := start.Add(200 * time.Millisecond)
+	if action, _ := m.Feed(press('g', 0), late); action != "" {
+		t.Errorf("timed out chord completed with %q", action)
+	}
+	if action, _ := m.Feed(press('g', 0), late.Add(50*time.Millisecond)); action != "top" {
+		t.Errorf("fresh chord = %q, want top", action)
+	}
+	if got := m.Pending(late); got != "" {
+		t.Errorf("Pending() after completion = %q, want empty", got)
+	}
+}
+
+func TestCounter(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string
+		consumed string // "y" for each key the counter should take
+		want     int
+		wantSet  bool
+	}{
+		{"no count", "", "", 1, false},
+		{"single digit", "5", "y", 5, true},
+		{"multiple digits", "12", "yy", 12, true},
+		{"zero inside count", "10", "yy", 10, true},
+		{"leading zero is a key", "0", "n", 1, false},
+		{"letters ignored", "j", "n", 1, false},
+		{"capped", "123456", "yyyyyy", maxCount, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Counter
+			for i, r := range tt.keys {
+				got := c.Feed(press(r, 0))
+				if want := tt.consumed[i] == 'y'; got != want {
+					t.Errorf("Feed(%q) = %v, want %v", r, got, want)
+				}
+			}
+			n, set := c.Take()
+			if n != tt.want || set != tt.wantSet {
+				t.Errorf("Take() = (%d, %v), want (%d, %v)", n, set, tt.want, tt.wantSet)
+			}
+			if c.String() != "" {
+				t.Errorf("Take() should reset the counter, String() = %q", c.String())
+			}
+		})
+	}
+}
+
+func TestCounterIgnoresModifiers(t *testing.T) {
+	var c Counter
+	if c.Feed(press('5', ModCtrl)) {
+		t.Error("Ctrl+5 should not be taken as a count")
+	}
+	c.Feed(press('4', 0))
+	if c.String() != "4" {
+		t.Errorf("String() = %q, want \"4\"", c.String())
+	}
+	c.Reset()
+	if n, set := c.Take(); n != 1 || set {
+		t.Errorf("Take() after Reset = (%d, %v)", n, set)
+	}
+}
+
+func TestTypeAhead(t *testing.T) {
+	names := []string{"Apple", "apricot", "banana", "Blueberry", "avocado"}
+
+	tests := []struct {
+		name    string
+		typed   string
+		current int
+		want    []int // Selected index after each character
+	}{
+		{"first match from top", "b", 3, []int{2}},
+		{"prefix refines", "bl", 0, []int{2, 3}},
+		{"case insensitive", "AP", 0, []int{0, 0}},
+		{"longer prefix", "apr", 0, []int{0, 0, 1}},
+		{"repeat cycles", "aaaa", 0, []int{0, 1, 4, 0}},
+		{"no match", "z", 0, []int{-1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ta := NewTypeAhead()
+			now := time.Now()
+			current := tt.current
+			for i, r := range tt.typed {
+				got := ta.Type(r, names, current, now)
+				if got != tt.want[i] {
+					t.Fatalf("after %q: index = %d, want %d", tt.typed[:i+1], got, tt.want[i])
+				}
+				if got >= 0 {
+					current = got
+				}
+			}
+		})
+	}
+}
+
+func TestTypeAheadTimeout(t *testing.T) {
+	names := []string{"alpha", "beta", "bravo"}
+	ta := NewTypeAhead()
+	ta.Timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	if got := ta.Type('b', names, 0, start); got != 1 {
+		t.Fatalf("b = %d, want 1", got)
+	}
+	if ta.Expired(start) || ta.String() != "b" {
+		t.Errorf("buffer = %q, expired = %v", ta.String(), ta.Expired(start))
+	}
+
+	// After the timeout "a" starts a new search instead of making "ba"
+	late := start.Add(200 * time.Millisecond)
+	if !ta.Expired(late) {
+		t.Error("buffer should expire after the timeout")
+	}
+	if got := ta.Type('a', names, 1, late); got != 0 {
+		t.Errorf("a after timeout = %d, want 0", got)
+	}
+
+	ta.Reset()
+	if !ta.Expired(late) {
+		t.Error("Reset should empty the buffer")
+	}
+}
+
+func TestCountBeforeChord(t *testing.T) {
+	m := New()
+	if err := m.Bind("d d", "cut"); err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+
+	// As in the window's key handler, digits only count while no chord
+	// is pending
+	var counter Counter
+	now := time.Now()
+	var action string
+	for _, r := range "3dd" {
+		ev := press(r, 0)
+		if m.Pending(now) == "" && counter.Feed(ev) {
+			continue
+		}
+		action, _ = m.Feed(ev, now)
+	}
+
+	if action != "cut" {
+		t.Fatalf("3 d d = %q, want cut", action)
+	}
+	if n, ok := counter.Take(); n != 3 || !ok {
+		t.Errorf("count = %d, %v, want 3, true", n, ok)
+	}
+}
@@ -0,0 +1,156 @@
+package keymap
+
+import (
+	"time"
+	"unicode"
+)
+
+// Line is the Handler of modes that read a line of text, such as a
+// command or a new name. It edits the text at a cursor with the usual
+// keys: arrows, Home and End (or Ctrl+A and Ctrl+E), BackSpace and Delete,
+// Ctrl+W deleting a word and Ctrl+U everything before the cursor. Return
+// submits the text and Escape cancels.
+type Line struct {
+	// Submit is called with the text on Return
+	Submit func(text string)
+
+	// Cancel is called on Escape
+	Cancel func()
+
+	// Changed, if set, is called after every edit and cursor movement
+	Changed func()
+
+	// Complete, if set, returns the text Tab completes text to
+	Complete func(text string) string
+
+	text   []rune
+	cursor int
+}
+
+// SetText replaces the text and puts the cursor at rune offset cursor,
+// or at the end if cursor is out of range.
+func (l *Line) SetText(text string, cursor int) {
+	l.text = []rune(text)
+	if cursor < 0 || cursor > len(l.text) {
+		cursor = len(l.text)
+	}
+	l.cursor = cursor
+	l.changed()
+}
+
+// Text returns the text.
+func (l *Line) Text() string {
+	return string(l.text)
+}
+
+// WithCursor returns the text with mark inserted at the cursor, for
+// display.
+func (l *Line) WithCursor(mark string) string {
+	return string(l.text[:l.cursor]) + mark + string(l.text[l.cursor:])
+}
+
+// Insert types s at the cursor. Input methods deliver composed text this
+// way rather than as key presses.
+func (l *Line) Insert(s string) {
+	inserted := []rune(s)
+	l.text = append(l.text[:l.cursor], append(inserted, l.text[l.cursor:]...)...)
+	l.cursor += len(inserted)
+	l.changed()
+}
+
+// Reset clears the text.
+func (l *Line) Reset() {
+	l.text = nil
+	l.cursor = 0
+}
+
+// Key implements Handler. Keys with Ctrl, Alt or Super that don't edit
+// are ignored, so application shortcuts keep working.
+func (l *Line) Key(ev Event, _ time.Time) Result {
+	switch {
+	case ev.Name == "Return" || ev.Name == "KP_Enter":
+		l.Submit(l.Text())
+		return Consumed
+	case ev.Name == "Escape":
+		l.Cancel()
+		return Consumed
+	case ev.IsModifierKey():
+		return Consumed
+	}
+
+	switch (Key{Name: ev.Name, Mods: ev.Mods}).String() {
+	case "BackSpace":
+		if l.cursor > 0 {
+			l.deleteRange(l.cursor-1, l.cursor)
+		}
+	case "Delete", "KP_Delete":
+		if l.cursor < len(l.text) {
+			l.deleteRange(l.cursor, l.cursor+1)
+		}
+	case "Left", "KP_Left":
+		l.move(l.cursor - 1)
+	case "Right", "KP_Right":
+		l.move(l.cursor + 1)
+	case "Home", "KP_Home", "<Ctrl>a":
+		l.move(0)
+	case "End", "KP_End", "<Ctrl>e":
+		l.move(len(l.text))
+	case "<Ctrl>u":
+		l.deleteRange(0, l.cursor)
+	case "<Ctrl>w":
+		l.deleteRange(l.wordStart(), l.cursor)
+	case "Tab":
+		if l.Complete != nil {
+			l.SetText(l.Complete(l.Text()), -1)
+		}
+	default:
+		if ev.Mods != 0 || !unicode.IsPrint(ev.Rune) {
+			if ev.Mods&(ModCtrl|ModAlt|ModSuper) != 0 {
+				return Ignored
+			}
+			// Other special keys do nothing, but don't reach the view
+			return Consumed
+		}
+		l.Insert(string(ev.Rune))
+	}
+	return Consumed
+}
+
+// move puts the cursor at i, within the text.
+func (l *Line) move(i int) {
+	l.cursor = max(0, min(i, len(l.text)))
+	l.changed()
+}
+
+// deleteRange removes the runes from start to end and leaves the cursor
+// at start.
+func (l *Line) deleteRange(start, end int) {
+	l.text = append(l.text[:start], l.text[end:]...)
+	l.cursor = start
+	l.changed()
+}
+
+// wordStart returns the start of the word before the cursor, skipping
+// spaces and punctuation before it, as Ctrl+W does in a shell.
+func (l *Line) wordStart() int {
+	i := l.cursor
+	for i > 0 && !isWordRune(l.text[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(l.text[i-1]) {
+		i--
+	}
+	return i
+}
+
+// isWordRune reports whether r is part of a word for Ctrl+W.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// changed calls Changed, if set.
+func (l *Line) changed() {
+	if l.Changed != nil {
+		l.Changed()
+	}
+}
@@ -0,0 +1,109 @@
+package keymap
+
+import (
+	"testing"
+	"time"
+)
+
+// typeLine feeds each key to l: single characters as typed, longer
+// strings as key specs such as "BackSpace" or "<Ctrl>w".
+func typeLine(t *testing.T, l *Line, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		var ev Event
+		if r := []rune(key); len(r) == 1 {
+			ev = press(r[0], 0)
+		} else {
+			k, err := ParseKey(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev = NewEvent(k.Name, 0, k.Mods)
+			if r := []rune(k.Name); len(r) == 1 {
+				ev.Rune = r[0]
+			}
+		}
+		if got := l.Key(ev, time.Now()); got != Consumed {
+			t.Fatalf("Key(%s) = %v, want Consumed", key, got)
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	var submitted string
+	cancelled := false
+	l := &Line{
+		Submit: func(text string) { submitted = text },
+		Cancel: func() { cancelled = true },
+	}
+
+	tests := []struct {
+		name  string
+		start string
+		at    int
+		keys  []string
+		want  string
+	}{
+		{"typing", "", 0, []string{"a", "b", "c"}, "abc|"},
+		{"insert in middle", "photo.jpg", 5, []string{"_", "2"}, "photo_2|.jpg"},
+		{"backspace", "abc", -1, []string{"BackSpace"}, "ab|"},
+		{"backspace at start", "abc", 0, []string{"BackSpace"}, "|abc"},
+		{"delete", "abc", 0, []string{"Delete"}, "|bc"},
+		{"arrows", "abc", -1, []string{"Left", "Left", "x", "Right", "Right", "Right"}, "axbc|"},
+		{"home end", "abc", 1, []string{"Home", "x", "<Ctrl>e", "y"}, "xabcy|"},
+		{"ctrl-w", "*.go  *.md ", -1, []string{"<Ctrl>w"}, "*.go  *.|"},
+		{"ctrl-u", "abc def", 4, []string{"<Ctrl>u"}, "|def"},
+		{"space", "a", -1, []string{"space", "b"}, "a b|"},
+		{"unicode", "naïve", 3, []string{"Right", "BackSpace"}, "naï|e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l.SetText(tt.start, tt.at)
+			typeLine(t, l, tt.keys...)
+			if got := l.WithCursor("|"); got != tt.want {
+				t.Errorf("line = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	l.SetText("new name", -1)
+	typeLine(t, l, "Return")
+	if submitted != "new name" {
+		t.Errorf("submitted %q, want new name", submitted)
+	}
+	typeLine(t, l, "Escape")
+	if !cancelled {
+		t.Error("Escape did not cancel")
+	}
+}
+
+func TestLineCompleteAndShortcuts(t *testing.T) {
+	changes := 0
+	l := &Line{
+		Submit:   func(string) {},
+		Cancel:   func() {},
+		Changed:  func() { changes++ },
+		Complete: func(text string) string { return text + "_hidden" },
+	}
+	l.SetText("toggle", -1)
+	typeLine(t, l, "Tab")
+	if l.Text() != "toggle_hidden" {
+		t.Errorf("Tab completed to %q", l.Text())
+	}
+	if changes != 2 {
+		t.Errorf("Changed called %d times, want 2", changes)
+	}
+
+	// Application shortcuts are left alone
+	if got := l.Key(NewEvent("q", 'q', ModCtrl), time.Now()); got != Ignored {
+		t.Errorf("Key(<Ctrl>q) = %v, want Ignored", got)
+	}
+	if got := l.Key(NewEvent("F5", 0, 0), time.Now()); got != Consumed {
+		t.Errorf("Key(F5) = %v, want Consumed", got)
+	}
+
+	l.Insert("ü")
+	if l.WithCursor("|") != "toggle_hiddenü|" {
+		t.Errorf("Insert: line = %q", l.WithCursor("|"))
+	}
+}
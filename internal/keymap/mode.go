@@ -0,0 +1,190 @@
+package keymap
+
+import "time"
+
+// Mode is a keyboard mode: what key presses mean at the moment.
+type Mode int
+
+const (
+	// ModeNormal runs the configured bindings
+	ModeNormal Mode = iota
+	// ModeVisual extends a range of entries from where it was entered
+	ModeVisual
+	// ModeJump selects the entry whose name starts with what is typed
+	ModeJump
+	// ModeCommand reads the name of a command to run
+	ModeCommand
+	// ModeFilter reads a filter for the listing
+	ModeFilter
+	// ModeRename edits the name of the selected entry
+	ModeRename
+	// ModeRegister reads the name of the register the next yank, cut or
+	// paste uses
+	ModeRegister
+	// ModePasteTo reads the directory to paste into
+	ModePasteTo
+	// ModeRun reads the arguments of the executable to run
+	ModeRun
+)
+
+// String returns the mode's name.
+func (m Mode) String() string {
+	switch m {
+	case ModeNormal:
+		return "normal"
+	case ModeVisual:
+		return "visual"
+	case ModeJump:
+		return "jump"
+	case ModeCommand:
+		return "command"
+	case ModeFilter:
+		return "filter"
+	case ModeRename:
+		return "rename"
+	case ModeRegister:
+		return "register"
+	case ModePasteTo:
+		return "paste to"
+	case ModeRun:
+		return "run"
+	default:
+		return "unknown"
+	}
+}
+
+// Result says what a mode's handler did with a key press.
+type Result int
+
+const (
+	// Ignored means the key does nothing in the mode, so the toolkit
+	// may use it
+	Ignored Result = iota
+	// Consumed means the handler used the key
+	Consumed
+	// Passed means the handler left its mode without using the key,
+	// which goes to the handler of the mode now current
+	Passed
+)
+
+// Handler receives the key presses of a mode.
+type Handler interface {
+	Key(ev Event, now time.Time) Result
+}
+
+// HandlerFunc adapts a function to a Handler.
+type HandlerFunc func(ev Event, now time.Time) Result
+
+// Key calls f.
+func (f HandlerFunc) Key(ev Event, now time.Time) Result {
+	return f(ev, now)
+}
+
+// resetter is implemented by handlers with state to drop when their mode
+// is left, such as a partial count.
+type resetter interface {
+	Reset()
+}
+
+// Dispatcher sends key presses to the handler of the current mode. Modes
+// are plugged in with Handle, so adding one doesn't touch the others. It
+// starts in ModeNormal.
+type Dispatcher struct {
+	mode     Mode
+	handlers map[Mode]Handler
+}
+
+// NewDispatcher creates a dispatcher without handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[Mode]Handler)}
+}
+
+// Handle sets the handler of mode, replacing any earlier one.
+func (d *Dispatcher) Handle(mode Mode, h Handler) {
+	d.handlers[mode] = h
+}
+
+// Mode returns the current mode.
+func (d *Dispatcher) Mode() Mode {
+	return d.mode
+}
+
+// SetMode switches to mode. The handler of the mode left is reset if it
+// has a Reset method.
+func (d *Dispatcher) SetMode(mode Mode) {
+	if mode == d.mode {
+		return
+	}
+	if r, ok := d.handlers[d.mode].(resetter); ok {
+		r.Reset()
+	}
+	d.mode = mode
+}
+
+// Feed hands a key press to the current mode and reports whether it was
+// used. A handler returning Passed must have switched modes first; the
+// key then goes to the new mode.
+func (d *Dispatcher) Feed(ev Event, now time.Time) bool {
+	// Each mode gets the key at most once, so handlers passing it back
+	// and forth can't loop
+	tried := make(map[Mode]bool, len(d.handlers))
+	for !tried[d.mode] {
+		tried[d.mode] = true
+		h := d.handlers[d.mode]
+		if h == nil {
+			return false
+		}
+		switch h.Key(ev, now) {
+		case Consumed:
+			return true
+		case Ignored:
+			return false
+		}
+	}
+	return false
+}
+
+// Bindings is the Handler of modes driven by a keymap, such as normal and
+// visual mode: keys run the bound actions, after an optional count
+// ("5j"). Keymap may be replaced between key presses.
+type Bindings struct {
+	Keymap *Keymap
+
+	// Run runs an action with the count typed before it, or 1 and false
+	// if none was
+	Run func(action string, count int, given bool)
+
+	// Prompt shows the count typed so far; "" clears it
+	Prompt func(text string)
+
+	counter Counter
+}
+
+// Key implements Handler.
+func (b *Bindings) Key(ev Event, now time.Time) Result {
+	if b.Keymap.Pending(now) == "" && b.counter.Feed(ev) {
+		b.Prompt(b.counter.String())
+		return Consumed
+	}
+
+	action, handled := b.Keymap.Feed(ev, now)
+	if action == "" {
+		// Either unbound or the first key of a chord
+		if handled {
+			return Consumed
+		}
+		b.counter.Reset()
+		b.Prompt("")
+		return Ignored
+	}
+	n, given := b.counter.Take()
+	b.Prompt("")
+	b.Run(action, n, given)
+	return Consumed
+}
+
+// Reset drops a partial count or chord.
+func (b *Bindings) Reset() {
+	b.counter.Reset()
+	b.Keymap.Reset()
+}
@@ -0,0 +1,107 @@
+package keymap
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// named builds an event for a special key such as Return.
+func named(name string) Event {
+	return NewEvent(name, 0, 0)
+}
+
+func TestDispatcher(t *testing.T) {
+	now := time.Now()
+	d := NewDispatcher()
+
+	var ran []string
+	normal := &Bindings{
+		Keymap: New(),
+		Run: func(action string, count int, given bool) {
+			ran = append(ran, action)
+			if action == "jump" {
+				d.SetMode(ModeJump)
+			}
+			if given && count != 3 {
+				t.Errorf("count = %d, want 3", count)
+			}
+		},
+		Prompt: func(string) {},
+	}
+	for _, b := range [][2]string{{"j", "down"}, {"f", "jump"}, {"Return", "open"}} {
+		if err := normal.Keymap.Bind(b[0], b[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.Handle(ModeNormal, normal)
+
+	var typed []rune
+	d.Handle(ModeJump, HandlerFunc(func(ev Event, _ time.Time) Result {
+		if ev.Rune != 0 {
+			typed = append(typed, ev.Rune)
+			return Consumed
+		}
+		// Anything else leaves jump mode and is handled normally
+		d.SetMode(ModeNormal)
+		return Passed
+	}))
+
+	for _, ev := range []Event{press('3', 0), press('j', 0), press('f', 0), press('a', 0), press('b', 0), named("Return")} {
+		if !d.Feed(ev, now) {
+			t.Errorf("Feed(%s) = false in %s mode", ev.Name, d.Mode())
+		}
+	}
+	if want := []string{"down", "jump", "open"}; !slices.Equal(ran, want) {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+	if string(typed) != "ab" {
+		t.Errorf("jump mode got %q, want ab", string(typed))
+	}
+	if d.Mode() != ModeNormal {
+		t.Errorf("mode = %s, want normal", d.Mode())
+	}
+	if d.Feed(press('x', 0), now) {
+		t.Error("Feed(unbound key) = true")
+	}
+}
+
+func TestDispatcherPassLoop(t *testing.T) {
+	d := NewDispatcher()
+	bounce := func(to Mode) Handler {
+		return HandlerFunc(func(Event, time.Time) Result {
+			d.SetMode(to)
+			return Passed
+		})
+	}
+	d.Handle(ModeNormal, bounce(ModeVisual))
+	d.Handle(ModeVisual, bounce(ModeNormal))
+	if d.Feed(press('x', 0), time.Now()) {
+		t.Error("Feed() = true for a key no mode used")
+	}
+}
+
+func TestDispatcherResetsModeLeft(t *testing.T) {
+	now := time.Now()
+	d := NewDispatcher()
+	prompt := ""
+	normal := &Bindings{Keymap: New(), Run: func(string, int, bool) {}, Prompt: func(text string) { prompt = text }}
+	if err := normal.Keymap.Bind("j", "down"); err != nil {
+		t.Fatal(err)
+	}
+	d.Handle(ModeNormal, normal)
+	d.Handle(ModeVisual, HandlerFunc(func(Event, time.Time) Result { return Consumed }))
+
+	d.Feed(press('5', 0), now)
+	if prompt != "5" {
+		t.Errorf("prompt = %q, want 5", prompt)
+	}
+	d.SetMode(ModeVisual)
+	d.SetMode(ModeNormal)
+	normal.Run = func(_ string, count int, given bool) {
+		if given {
+			t.Errorf("count %d survived leaving the mode", count)
+		}
+	}
+	d.Feed(press('j', 0), now)
+}
@@ -0,0 +1,71 @@
+package keymap
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TypeAhead accumulates typed characters to jump to an entry by name.
+// Typing extends the prefix; repeating a single character cycles through
+// the entries that start with it. The buffer resets after Timeout.
+type TypeAhead struct {
+	Timeout time.Duration // How long the typed prefix is kept between keys
+
+	buffer   string
+	deadline time.Time
+}
+
+// NewTypeAhead creates a type-ahead buffer using DefaultTimeout.
+func NewTypeAhead() *TypeAhead {
+	return &TypeAhead{Timeout: DefaultTimeout}
+}
+
+// Type adds r to the buffer and returns the index in names of the entry to
+// select, or -1 if nothing matches. current is the selected index.
+// Matching ignores case.
+func (t *TypeAhead) Type(r rune, names []string, current int, now time.Time) int {
+	if t.Expired(now) {
+		t.buffer = ""
+	}
+	t.deadline = now.Add(t.Timeout)
+
+	char := string(unicode.ToLower(r))
+	start := current
+	switch {
+	case t.buffer == "":
+		// A fresh search starts from the top
+		t.buffer = char
+		start = 0
+	case strings.Trim(t.buffer, char) == "":
+		// Repeating one character cycles to the next entry with it
+		t.buffer = char
+		start = current + 1
+	default:
+		// Extending the prefix refines from the current match
+		t.buffer += char
+	}
+
+	for i := range names {
+		index := (start + i) % len(names)
+		if strings.HasPrefix(strings.ToLower(names[index]), t.buffer) {
+			return index
+		}
+	}
+	return -1
+}
+
+// Expired reports whether the buffer has timed out (or is empty).
+func (t *TypeAhead) Expired(now time.Time) bool {
+	return t.buffer == "" || now.After(t.deadline)
+}
+
+// String returns the typed prefix.
+func (t *TypeAhead) String() string {
+	return t.buffer
+}
+
+// Reset clears the buffer.
+func (t *TypeAhead) Reset() {
+	t.buffer = ""
+}
@@ -0,0 +1,9 @@
+// Package logging sets up Warren's log, written with log/slog.
+//
+// Records go to stderr and to $XDG_STATE_HOME/warren/warren.log
+// (~/.local/state/warren/warren.log by default). The file is rotated when
+// it grows past MaxSize, keeping a few older files as warren.log.1,
+// warren.log.2 and so on, so it can be attached to bug reports without
+// growing forever. The level can change while Warren runs, when the
+// config is reloaded.
+package logging
@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Rotation limits of the log file.
+const (
+	// MaxSize is the size at which the log file is rotated
+	MaxSize = 1 << 20
+
+	// Backups is how many rotated files are kept
+	Backups = 3
+)
+
+// level is the minimum level of records written, shared by the handlers
+// Setup installs.
+var level slog.LevelVar
+
+// Dir returns the directory holding the log file:
+// $XDG_STATE_HOME/warren, or ~/.local/state/warren if XDG_STATE_HOME is
+// not set. This is config.StateDir, which config's use of ParseLevel
+// keeps this package from importing.
+func Dir() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateHome, "warren"), nil
+}
+
+// Path returns the full path to the log file.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "warren.log"), nil
+}
+
+// ParseLevel parses a log level name: "debug", "info", "warn" or "error".
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", name)
+	}
+}
+
+// SetLevel changes the minimum level of records written.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Setup makes the default slog logger, which the standard log package also
+// writes through, log to stderr and to the log file at Path. If the file
+// can't be opened, logging goes to stderr only and the error is returned.
+// Close the returned closer on exit.
+func Setup(stderr io.Writer) (io.Closer, error) {
+	path, err := Path()
+	if err == nil {
+		var file *rotatingFile
+		file, err = openRotating(path, MaxSize, Backups)
+		if err == nil {
+			// stderr first: writes to the file fail once it is closed, and
+			// MultiWriter stops at the first error
+			slog.SetDefault(slog.New(newHandler(io.MultiWriter(stderr, file))))
+			return file, nil
+		}
+	}
+
+	slog.SetDefault(slog.New(newHandler(stderr)))
+	return io.NopCloser(nil), fmt.Errorf("failed to open log file: %w", err)
+}
+
+// newHandler returns the handler writing records to w at the current level.
+func newHandler(w io.Writer) slog.Handler {
+	return slog.NewTextHandler(w, &slog.HandlerOptions{Level: &level})
+}
+
+// Tail returns up to the last maxBytes of the log file at path, starting
+// at a line boundary. A missing file is empty.
+func Tail(path string, maxBytes int64) (string, error) {
+	// #nosec G304 -- path is the log file, derived from the XDG spec
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat log file: %w", err)
+	}
+	offset := max(info.Size()-maxBytes, 0)
+	data := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read log file: %w", err)
+	}
+
+	text := string(data)
+	if offset > 0 {
+		// Drop the partial line the window starts in
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			text = text[i+1:]
+		}
+	}
+	return text, nil
+}
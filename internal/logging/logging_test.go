@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	path, err := Path()
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	if path != "/state/warren/warren.log" {
+		t.Errorf("Path() = %s, want /state/warren/warren.log", path)
+	}
+
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("HOME", "/home/user")
+	path, err = Path()
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	if path != "/home/user/.local/state/warren/warren.log" {
+		t.Errorf("Path() = %s, want it under ~/.local/state", path)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"info", slog.LevelInfo},
+		{"WARN", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseLevel(%q) = %v, %v, want %v", tt.name, got, err, tt.expected)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) should fail")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+	defer SetLevel(slog.LevelInfo)
+
+	var stderr bytes.Buffer
+	closer, err := Setup(&stderr)
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	SetLevel(slog.LevelWarn)
+	slog.Info("hidden")
+	slog.Warn("shown", "err", os.ErrNotExist)
+	SetLevel(slog.LevelDebug)
+	log.Printf("from the log package")
+	slog.Debug("debugging")
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := Path()
+	text, err := Tail(path, MaxSize)
+	if err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if text != stderr.String() {
+		t.Errorf("log file = %q, stderr = %q, want the same", text, stderr.String())
+	}
+	for _, want := range []string{"level=WARN msg=shown", `err="file does not exist"`, "from the log package", "level=DEBUG msg=debugging"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("log = %q, want it to contain %q", text, want)
+		}
+	}
+	if strings.Contains(text, "hidden") {
+		t.Errorf("log = %q, want no records below the level", text)
+	}
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "warren.log")
+	if text, err := Tail(path, 10); err != nil || text != "" {
+		t.Errorf("Tail() of a missing file = %q, %v, want empty", text, err)
+	}
+
+	if err := os.WriteFile(path, []byte("first line\nsecond\nthird\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	text, err := Tail(path, 12)
+	if err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	if text != "third\n" {
+		t.Errorf("Tail() = %q, want the whole lines in the last 12 bytes", text)
+	}
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rotatingFile is an append-only file that is renamed to path.1 when it
+// would grow past maxSize, shifting older files up to path.<backups>.
+type rotatingFile struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+// openRotating opens the file at path for appending, creating it and its
+// directory if needed.
+func openRotating(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	r := &rotatingFile{path: path, maxSize: maxSize, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens r.path and records its size.
+func (r *rotatingFile) open() error {
+	// #nosec G304 -- path is the log file, derived from the XDG spec
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if it would take the file past maxSize.
+// A record is never split across files.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups up by one, dropping the oldest, moves the
+// current file to path.1 and starts a new one.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+
+	// If renaming fails the file is reopened and keeps growing; losing the
+	// log would be worse
+	for i := r.backups - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.backups > 0 {
+		_ = os.Rename(r.path, r.path+".1")
+	} else {
+		_ = os.Remove(r.path)
+	}
+	return r.open()
+}
+
+// Close closes the file; later writes fail.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
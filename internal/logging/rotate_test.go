@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "warren.log")
+	r, err := openRotating(path, 10, 2)
+	if err != nil {
+		t.Fatalf("openRotating() error = %v", err)
+	}
+
+	for _, record := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := r.Write([]byte(record)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each record overflows the limit, so each starts a new file and the
+	// oldest falls off the end
+	expected := map[string]string{
+		path:        "dddddd\n",
+		path + ".1": "cccccc\n",
+		path + ".2": "bbbbbb\n",
+	}
+	for file, want := range expected {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), data, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 should not exist", filepath.Base(path))
+	}
+
+	if _, err := r.Write([]byte("late\n")); err == nil {
+		t.Error("Write() after Close() should fail")
+	}
+}
+
+func TestRotatingFile_AppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "warren.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := openRotating(path, 100, 1)
+	if err != nil {
+		t.Fatalf("openRotating() error = %v", err)
+	}
+	_, _ = r.Write([]byte("new\n"))
+	_ = r.Close()
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "old\n") || !strings.HasSuffix(string(data), "new\n") {
+		t.Errorf("log = %q, want the new record appended", data)
+	}
+}
@@ -0,0 +1,9 @@
+// Package preview prepares file contents for the preview pane.
+//
+// LoadText reads the start of a file and detects its encoding (UTF-8,
+// UTF-16 with a byte order mark, or ISO-8859-1 as a fallback), refusing
+// files that look binary. Highlight turns the text into Pango markup with
+// comments, strings, numbers and keywords coloured for the language its
+// file name suggests. The package has no GTK dependency; ui.PreviewPane
+// calls it from a goroutine and renders the result.
+package preview
@@ -0,0 +1,331 @@
+package preview
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// token is a kind of highlighted span.
+type token int
+
+const (
+	tokenComment token = iota
+	tokenString
+	tokenNumber
+	tokenKeyword
+)
+
+// spanAttrs are the Pango attributes for each token, chosen to stay
+// readable on both light and dark backgrounds.
+var spanAttrs = map[token]string{
+	tokenComment: `foreground="#878787" style="italic"`,
+	tokenString:  `foreground="#5f9f5f"`,
+	tokenNumber:  `foreground="#d7875f"`,
+	tokenKeyword: `foreground="#5f87d7" weight="bold"`,
+}
+
+// language describes just enough of a language's syntax to colour it.
+type language struct {
+	lineComments []string
+	blockComment [2]string // Start and end; empty if none
+	quotes       string    // Characters that open and close a string
+	multiline    string    // Quotes whose strings may span lines
+	keywords     map[string]bool
+}
+
+// words builds a keyword set.
+func words(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	langGo = &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		multiline:    "`",
+		keywords: words(`break case chan const continue default defer else fallthrough
+			for func go goto if import interface map package range return select
+			struct switch type var nil true false iota`),
+	}
+	langC = &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		keywords: words(`auto break case char class const continue default delete do
+			double else enum extern float for goto if inline int long namespace new
+			nullptr private protected public register return short signed sizeof
+			static struct switch template this typedef union unsigned using virtual
+			void volatile while true false NULL #include #define #if #ifdef #ifndef
+			#else #endif #pragma`),
+	}
+	langJS = &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+		multiline:    "`",
+		keywords: words(`async await break case catch class const continue default
+			delete do else export extends finally for from function if import in
+			instanceof interface let new null return static super switch this throw
+			try type typeof undefined var void while yield true false`),
+	}
+	langRust = &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+		multiline:    "\"",
+		keywords: words(`as async await break const continue crate dyn else enum
+			extern false fn for if impl in let loop match mod move mut pub ref
+			return self Self static struct super trait true type unsafe use where
+			while`),
+	}
+	langJava = &language{
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		keywords: words(`abstract boolean break byte case catch char class const
+			continue default do double else enum extends final finally float for
+			if implements import instanceof int interface long new null package
+			private protected public return short static super switch this throw
+			throws try var void while true false`),
+	}
+	langPython = &language{
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		keywords: words(`and as assert async await break class continue def del elif
+			else except finally for from global if import in is lambda nonlocal not
+			or pass raise return try while with yield None True False self`),
+	}
+	langShell = &language{
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+		multiline:    "\"'",
+		keywords: words(`case do done elif else esac export fi for function if in
+			local read return select then until while echo exit set unset source`),
+	}
+	langLua = &language{
+		lineComments: []string{"--"},
+		blockComment: [2]string{"--[[", "]]"},
+		quotes:       "\"'",
+		keywords: words(`and break do else elseif end false for function goto if in
+			local nil not or repeat return then true until while`),
+	}
+	langConfig = &language{
+		lineComments: []string{"#", ";"},
+		quotes:       "\"'",
+		keywords:     words(`true false yes no on off null`),
+	}
+	langJSON = &language{
+		quotes:   "\"",
+		keywords: words(`true false null`),
+	}
+	langCSS = &language{
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+		keywords:     words(`@import @media @font-face @keyframes !important`),
+	}
+	langMarkup = &language{
+		blockComment: [2]string{"<!--", "-->"},
+		quotes:       "\"",
+	}
+)
+
+// languagesByExt maps lower-case file extensions to languages.
+var languagesByExt = map[string]*language{
+	".go":   langGo,
+	".c":    langC,
+	".h":    langC,
+	".cc":   langC,
+	".cpp":  langC,
+	".hpp":  langC,
+	".js":   langJS,
+	".mjs":  langJS,
+	".jsx":  langJS,
+	".ts":   langJS,
+	".tsx":  langJS,
+	".rs":   langRust,
+	".java": langJava,
+	".kt":   langJava,
+	".py":   langPython,
+	".sh":   langShell,
+	".bash": langShell,
+	".zsh":  langShell,
+	".lua":  langLua,
+	".toml": langConfig,
+	".ini":  langConfig,
+	".conf": langConfig,
+	".cfg":  langConfig,
+	".yaml": langConfig,
+	".yml":  langConfig,
+	".json": langJSON,
+	".css":  langCSS,
+	".html": langMarkup,
+	".htm":  langMarkup,
+	".xml":  langMarkup,
+	".svg":  langMarkup,
+}
+
+// languagesByName maps whole file names without a telling extension.
+var languagesByName = map[string]*language{
+	"makefile":   langShell,
+	"dockerfile": langShell,
+	".bashrc":    langShell,
+	".profile":   langShell,
+	".zshrc":     langShell,
+	"pkgbuild":   langShell,
+}
+
+// languageFor returns the language for a file name, or nil.
+func languageFor(name string) *language {
+	base := strings.ToLower(filepath.Base(name))
+	if lang, ok := languagesByName[base]; ok {
+		return lang
+	}
+	return languagesByExt[filepath.Ext(base)]
+}
+
+// Highlight returns text as Pango markup, with comments, strings, numbers
+// and keywords coloured for the language matching the file name. Text in
+// an unknown language is only escaped.
+func Highlight(text, name string) string {
+	lang := languageFor(name)
+	if lang == nil {
+		return escape(text)
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) * 2)
+	span := func(t token, s string) {
+		b.WriteString("<span ")
+		b.WriteString(spanAttrs[t])
+		b.WriteString(">")
+		b.WriteString(escape(s))
+		b.WriteString("</span>")
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		c := text[i]
+
+		if start := lang.blockComment[0]; start != "" && strings.HasPrefix(rest, start) {
+			end := strings.Index(rest[len(start):], lang.blockComment[1])
+			n := len(rest)
+			if end >= 0 {
+				n = len(start) + end + len(lang.blockComment[1])
+			}
+			span(tokenComment, rest[:n])
+			i += n
+			continue
+		}
+		if lineComment(lang, rest) {
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			span(tokenComment, rest[:n])
+			i += n
+			continue
+		}
+		if strings.IndexByte(lang.quotes, c) >= 0 {
+			n := stringLength(rest, strings.IndexByte(lang.multiline, c) >= 0)
+			span(tokenString, rest[:n])
+			i += n
+			continue
+		}
+
+		// Words: identifiers, keywords (including # and @ forms) and numbers
+		if isWordStart(c) {
+			n := 1
+			for n < len(rest) && isWordByte(rest[n]) {
+				n++
+			}
+			word := rest[:n]
+			switch {
+			case c >= '0' && c <= '9':
+				span(tokenNumber, word)
+			case lang.keywords[word]:
+				span(tokenKeyword, word)
+			default:
+				b.WriteString(escape(word))
+			}
+			i += n
+			continue
+		}
+
+		b.WriteString(escape(rest[:1]))
+		i++
+	}
+	return b.String()
+}
+
+// lineComment reports whether s starts with one of lang's line comments.
+func lineComment(lang *language, s string) bool {
+	for _, prefix := range lang.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringLength returns the length of the string literal at the start of
+// s, including its quotes. Backslash escapes the next character. Unless
+// multiline, a string ends at the end of the line even if unterminated.
+func stringLength(s string, multiline bool) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if !multiline {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// isWordStart reports whether a word (identifier, keyword or number) can
+// start with c. Bytes of multi-byte characters count, so words are never
+// split inside a character.
+func isWordStart(c byte) bool {
+	return isWordByte(c) || c == '#' || c == '@' || c == '!'
+}
+
+// isWordByte reports whether c can continue a word.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' ||
+		c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// escape escapes the characters that are special in Pango markup.
+func escape(s string) string {
+	return markupEscaper.Replace(s)
+}
+
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// LineNumbers returns the numbers of the lines in text, one per line, for
+// a gutter beside it. A final newline doesn't start another line.
+func LineNumbers(text string) string {
+	lines := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		lines++
+	}
+	var b strings.Builder
+	for n := 1; n <= lines; n++ {
+		if n > 1 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strconv.Itoa(n))
+	}
+	return b.String()
+}
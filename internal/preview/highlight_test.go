@@ -0,0 +1,100 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+)
+
+// Shorthands for the expected spans
+func comment(s string) string { return "<span " + spanAttrs[tokenComment] + ">" + s + "</span>" }
+func str(s string) string     { return "<span " + spanAttrs[tokenString] + ">" + s + "</span>" }
+func number(s string) string  { return "<span " + spanAttrs[tokenNumber] + ">" + s + "</span>" }
+func keyword(s string) string { return "<span " + spanAttrs[tokenKeyword] + ">" + s + "</span>" }
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		text string
+		want string
+	}{
+		{"unknown language is escaped", "notes.txt", "a < b && c", "a &lt; b &amp;&amp; c"},
+		{"go keywords and numbers", "main.go", "return x + 42",
+			keyword("return") + " x + " + number("42")},
+		{"identifiers containing keywords", "main.go", "returned", "returned"},
+		{"line comment ends at newline", "main.go", "x // note <b>\ny",
+			"x " + comment("// note &lt;b&gt;") + "\ny"},
+		{"block comment spans lines", "main.c", "/* a\nb */int",
+			comment("/* a\nb */") + keyword("int")},
+		{"string with escaped quote", "main.go", `s := "a\"b" + c`,
+			"s := " + str(`"a\"b"`) + " + c"},
+		{"comment markers inside strings", "main.go", `"// not"`, str(`"// not"`)},
+		{"unterminated string stops at newline", "x.py", "'abc\ndef",
+			str("'abc") + "\n" + keyword("def")},
+		{"raw string spans lines", "main.go", "`a\nb`", str("`a\nb`")},
+		{"hash comments", "x.py", "# hi\npass", comment("# hi") + "\n" + keyword("pass")},
+		{"preprocessor keyword", "x.h", "#include <x>", keyword("#include") + " &lt;x&gt;"},
+		{"file name match", "Makefile", "# build", comment("# build")},
+		{"extension is case-insensitive", "MAIN.GO", "nil", keyword("nil")},
+		{"non-ASCII identifiers", "x.go", "var größe", keyword("var") + " größe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.file); got != tt.want {
+				t.Errorf("Highlight(%q, %q) =\n%s\nwant\n%s", tt.text, tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlight_KeepsText(t *testing.T) {
+	// Stripping the markup gives back the text for every language
+	text := "func f() { /* x */ return \"s\" // c\n}\n# h\n-- l\n'q' 1.5e3 @media !x"
+	for ext := range languagesByExt {
+		markup := Highlight(text, "file"+ext)
+		plain := stripSpans(markup)
+		plain = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(plain)
+		if plain != text {
+			t.Errorf("%s: text changed by highlighting:\n%q", ext, plain)
+		}
+	}
+}
+
+// stripSpans removes <span ...> and </span> tags from markup.
+func stripSpans(markup string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(markup, "<span")
+		end := strings.Index(markup, "</span>")
+		if start < 0 && end < 0 {
+			b.WriteString(markup)
+			return b.String()
+		}
+		if start >= 0 && (end < 0 || start < end) {
+			b.WriteString(markup[:start])
+			markup = markup[start+strings.IndexByte(markup[start:], '>')+1:]
+		} else {
+			b.WriteString(markup[:end])
+			markup = markup[end+len("</span>"):]
+		}
+	}
+}
+
+func TestLineNumbers(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"one", "1"},
+		{"one\n", "1"},
+		{"one\ntwo", "1\n2"},
+		{"one\ntwo\n\n", "1\n2\n3"},
+	}
+	for _, tt := range tests {
+		if got := LineNumbers(tt.text); got != tt.want {
+			t.Errorf("LineNumbers(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package preview
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Kind is how a file is previewed.
+type Kind int
+
+const (
+	KindText  Kind = iota // Decoded and highlighted, or noted as binary
+	KindImage             // Loaded by GdkPixbuf/GdkTexture
+	KindPDF               // First page rendered by RenderPDFPage
+	KindAudio             // Metadata from ProbeMedia
+	KindVideo             // Metadata from ProbeMedia
+)
+
+// kindsByExt maps lower-case extensions to the kinds that aren't text.
+var kindsByExt = map[string]Kind{
+	".png": KindImage, ".jpg": KindImage, ".jpeg": KindImage, ".gif": KindImage,
+	".webp": KindImage, ".bmp": KindImage, ".tif": KindImage, ".tiff": KindImage,
+	".ico": KindImage, ".svg": KindImage, ".avif": KindImage, ".heic": KindImage,
+
+	".pdf": KindPDF,
+
+	".mp3": KindAudio, ".flac": KindAudio, ".ogg": KindAudio, ".oga": KindAudio,
+	".opus": KindAudio, ".wav": KindAudio, ".m4a": KindAudio, ".aac": KindAudio,
+	".wma": KindAudio,
+
+	".mp4": KindVideo, ".mkv": KindVideo, ".webm": KindVideo, ".avi": KindVideo,
+	".mov": KindVideo, ".m4v": KindVideo, ".wmv": KindVideo, ".ogv": KindVideo,
+}
+
+// KindOf returns how the file called name is previewed, judging by its
+// extension. Anything unrecognised is KindText, as are dotfiles such as
+// ".mp3" that have no extension.
+func KindOf(name string) Kind {
+	base := filepath.Base(name)
+	ext := filepath.Ext(base)
+	if ext == base {
+		return KindText
+	}
+	return kindsByExt[strings.ToLower(ext)]
+}
@@ -0,0 +1,25 @@
+package preview
+
+import "testing"
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want Kind
+	}{
+		{"main.go", KindText},
+		{"README", KindText},
+		{"photo.JPG", KindImage},
+		{"icon.svg", KindImage},
+		{"manual.pdf", KindPDF},
+		{"song.flac", KindAudio},
+		{"clip.mkv", KindVideo},
+		{".mp3", KindText}, // A dotfile, not an extension
+	}
+
+	for _, tt := range tests {
+		if got := KindOf(tt.name); got != tt.want {
+			t.Errorf("KindOf(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
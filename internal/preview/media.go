@@ -0,0 +1,286 @@
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoProber is returned by ProbeMedia when GStreamer's gst-discoverer is
+// not installed.
+var ErrNoProber = errors.New("gst-discoverer-1.0 is not installed")
+
+// probeTimeout bounds how long gst-discoverer may take.
+const probeTimeout = 10 * time.Second
+
+// Media describes an audio or video file.
+type Media struct {
+	Duration  time.Duration
+	Container string   // e.g. "Matroska"; empty for bare streams
+	Streams   []Stream // Audio, video and subtitle streams in file order
+	Tags      []Tag    // File-level tags in the order gst-discoverer lists them
+}
+
+// Stream is one audio, video or subtitle stream.
+type Stream struct {
+	Type       string // "audio", "video" or "subtitles"
+	Codec      string // e.g. "H.264 (High Profile)"
+	Width      int    // Video only
+	Height     int    // Video only
+	FrameRate  string // Video only, e.g. "30000/1001"
+	Channels   int    // Audio only
+	SampleRate int    // Audio only, in Hz
+	Bitrate    int    // Bits per second, when known
+}
+
+// Tag is one metadata tag, such as title or artist.
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// skippedTags are tags whose values aren't meant for people: embedded
+// cover art, raw frames and encoder bookkeeping.
+var skippedTags = map[string]bool{
+	"image":                       true,
+	"preview-image":               true,
+	"private-id3v2-frame":         true,
+	"private-qt-tag":              true,
+	"extended-comment":            true,
+	"has-crc":                     true,
+	"channel-mode":                true,
+	"container-specific-track-id": true,
+}
+
+// ProbeMedia reads the duration and tags of an audio or video file with
+// gst-discoverer-1.0.
+func ProbeMedia(path string) (Media, error) {
+	discoverer, err := exec.LookPath("gst-discoverer-1.0")
+	if err != nil {
+		return Media{}, ErrNoProber
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	// #nosec G204 -- fixed program; the path is passed as a single argument
+	output, err := exec.CommandContext(ctx, discoverer, path).CombinedOutput()
+	if err != nil {
+		return Media{}, fmt.Errorf("failed to read %s: %w: %s", filepath.Base(path), err, firstLine(output))
+	}
+	return parseDiscoverer(output), nil
+}
+
+// parseDiscoverer extracts the duration, streams and tags from
+// gst-discoverer's report. Streams are "<type> #N: codec" lines followed by
+// more deeply indented "Name: value" properties; tags are the indented
+// "name: value" lines after "Tags:". Tags inside a stream are skipped.
+func parseDiscoverer(output []byte) Media {
+	var media Media
+	var stream *Stream
+	streamIndent := -1 // Indentation of the current stream's line
+	tagIndent := -1    // Indentation of the "Tags:" line while inside the block
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if tagIndent >= 0 {
+			if indent > tagIndent && trimmed != "" {
+				name, value, ok := strings.Cut(trimmed, ":")
+				value = strings.TrimSpace(value)
+				if ok && value != "" && stream == nil && !skippedTags[name] {
+					media.Tags = append(media.Tags, Tag{Name: name, Value: value})
+				}
+				continue
+			}
+			tagIndent = -1
+		}
+
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if stream != nil && indent <= streamIndent {
+			stream = nil
+		}
+
+		if kind, _, isStream := strings.Cut(name, " #"); isStream {
+			switch kind {
+			case "container":
+				if media.Container == "" {
+					media.Container = value
+				}
+			case "audio", "video", "subtitles":
+				media.Streams = append(media.Streams, Stream{Type: kind, Codec: value})
+				stream = &media.Streams[len(media.Streams)-1]
+				streamIndent = indent
+			}
+			continue
+		}
+
+		switch name {
+		case "Tags":
+			tagIndent = indent
+		case "Duration":
+			if stream == nil {
+				media.Duration = parseClock(value)
+			}
+		}
+		if stream == nil {
+			continue
+		}
+		number, _ := strconv.Atoi(value)
+		switch name {
+		case "Width":
+			stream.Width = number
+		case "Height":
+			stream.Height = number
+		case "Frame rate":
+			stream.FrameRate = value
+		case "Channels":
+			// "2 (front-left, front-right)"
+			channels, _, _ := strings.Cut(value, " ")
+			stream.Channels, _ = strconv.Atoi(channels)
+		case "Sample rate":
+			stream.SampleRate = number
+		case "Bitrate":
+			stream.Bitrate = number
+		}
+	}
+	return media
+}
+
+// Video returns the first video stream, or nil.
+func (m Media) Video() *Stream {
+	return m.stream("video")
+}
+
+// Audio returns the first audio stream, or nil.
+func (m Media) Audio() *Stream {
+	return m.stream("audio")
+}
+
+func (m Media) stream(kind string) *Stream {
+	for i := range m.Streams {
+		if m.Streams[i].Type == kind {
+			return &m.Streams[i]
+		}
+	}
+	return nil
+}
+
+// Summary is a one-line description such as "3:46, 1920×1080 H.264".
+func (m Media) Summary() string {
+	var parts []string
+	if m.Duration > 0 {
+		parts = append(parts, FormatDuration(m.Duration))
+	}
+	if v := m.Video(); v != nil {
+		parts = append(parts, fmt.Sprintf("%d×%d %s", v.Width, v.Height, shortCodec(v.Codec)))
+	} else if a := m.Audio(); a != nil {
+		parts = append(parts, shortCodec(a.Codec))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Fields lists the duration, container, streams and tags as label/value
+// pairs for display.
+func (m Media) Fields() []Tag {
+	var fields []Tag
+	if m.Duration > 0 {
+		fields = append(fields, Tag{"Duration", FormatDuration(m.Duration)})
+	}
+	if m.Container != "" {
+		fields = append(fields, Tag{"Container", m.Container})
+	}
+	for _, s := range m.Streams {
+		fields = append(fields, Tag{strings.ToUpper(s.Type[:1]) + s.Type[1:], s.describe()})
+	}
+	return append(fields, m.Tags...)
+}
+
+// describe lists a stream's codec and properties, e.g.
+// "AAC, 2 channels, 48000 Hz, 128 kbit/s".
+func (s Stream) describe() string {
+	parts := []string{s.Codec}
+	if s.Width > 0 && s.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", s.Width, s.Height))
+	}
+	if fps := frameRate(s.FrameRate); fps != "" {
+		parts = append(parts, fps+" fps")
+	}
+	if s.Channels > 0 {
+		parts = append(parts, fmt.Sprintf("%d channels", s.Channels))
+	}
+	if s.SampleRate > 0 {
+		parts = append(parts, fmt.Sprintf("%d Hz", s.SampleRate))
+	}
+	if s.Bitrate > 0 {
+		parts = append(parts, fmt.Sprintf("%d kbit/s", s.Bitrate/1000))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortCodec drops the profile from a codec name: "H.264 (High Profile)"
+// becomes "H.264".
+func shortCodec(codec string) string {
+	short, _, _ := strings.Cut(codec, " (")
+	return short
+}
+
+// frameRate turns a "30000/1001" fraction into "29.97". Zero or malformed
+// rates are empty.
+func frameRate(fraction string) string {
+	num, den, ok := strings.Cut(fraction, "/")
+	n, err1 := strconv.ParseFloat(num, 64)
+	d, err2 := strconv.ParseFloat(den, 64)
+	if !ok || err1 != nil || err2 != nil || n == 0 || d == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(math.Round(n/d*100)/100, 'f', -1, 64)
+}
+
+// parseClock parses GStreamer's H:MM:SS.fraction durations. Anything else
+// is zero.
+func parseClock(s string) time.Duration {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second))
+}
+
+// FormatDuration formats d as M:SS, or H:MM:SS from an hour up.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// firstLine returns the first line of a command's output, for errors.
+func firstLine(output []byte) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return line
+}
@@ -0,0 +1,162 @@
+package preview
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const discovererReport = `Analyzing file:///music/song.mp3
+Done discovering file:///music/song.mp3
+
+Properties:
+  Duration: 0:03:45.500000000
+  Seekable: yes
+  Live: no
+  Tags: 
+      title: Song Title
+      artist: Some Band
+      album: The Album
+      image: buffer of 51234 bytes, type: image/jpeg
+      track number: 4
+  container #0: ID3 tag
+    audio #1: MPEG-1 Layer 3 (MP3)
+      Stream ID: 9e2f
+`
+
+func TestParseDiscoverer(t *testing.T) {
+	media := parseDiscoverer([]byte(discovererReport))
+
+	if want := 3*time.Minute + 45500*time.Millisecond; media.Duration != want {
+		t.Errorf("Duration = %v, want %v", media.Duration, want)
+	}
+
+	want := []Tag{
+		{"title", "Song Title"},
+		{"artist", "Some Band"},
+		{"album", "The Album"},
+		{"track number", "4"},
+	}
+	if len(media.Tags) != len(want) {
+		t.Fatalf("Tags = %v, want %v", media.Tags, want)
+	}
+	for i := range want {
+		if media.Tags[i] != want[i] {
+			t.Errorf("Tags[%d] = %v, want %v", i, media.Tags[i], want[i])
+		}
+	}
+}
+
+const discovererVideoReport = `Properties:
+  Duration: 1:02:03.000000000
+  Seekable: yes
+  Tags: 
+      title: Holiday
+      container format: Matroska
+  container #0: Matroska
+    video #1: H.264 (High Profile)
+      Stream ID: 1
+      Tags: 
+          language code: en
+      Width: 1920
+      Height: 1080
+      Depth: 24
+      Frame rate: 30000/1001
+      Bitrate: 0
+    audio #2: MPEG-4 AAC
+      Stream ID: 2
+      Channels: 2 (front-left, front-right)
+      Sample rate: 48000
+      Bitrate: 128000
+`
+
+func TestParseDiscovererStreams(t *testing.T) {
+	media := parseDiscoverer([]byte(discovererVideoReport))
+
+	if media.Container != "Matroska" {
+		t.Errorf("Container = %q, want Matroska", media.Container)
+	}
+	want := []Stream{
+		{Type: "video", Codec: "H.264 (High Profile)", Width: 1920, Height: 1080, FrameRate: "30000/1001"},
+		{Type: "audio", Codec: "MPEG-4 AAC", Channels: 2, SampleRate: 48000, Bitrate: 128000},
+	}
+	if len(media.Streams) != len(want) {
+		t.Fatalf("Streams = %+v, want %+v", media.Streams, want)
+	}
+	for i := range want {
+		if media.Streams[i] != want[i] {
+			t.Errorf("Streams[%d] = %+v, want %+v", i, media.Streams[i], want[i])
+		}
+	}
+
+	// Stream tags don't mix with the file's own
+	if len(media.Tags) != 2 || media.Tags[0] != (Tag{"title", "Holiday"}) {
+		t.Errorf("Tags = %v, want title and container format only", media.Tags)
+	}
+
+	if got, want := media.Summary(), "1:02:03, 1920×1080 H.264"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	fields := media.Fields()
+	wantFields := []Tag{
+		{"Duration", "1:02:03"},
+		{"Container", "Matroska"},
+		{"Video", "H.264 (High Profile), 1920×1080, 29.97 fps"},
+		{"Audio", "MPEG-4 AAC, 2 channels, 48000 Hz, 128 kbit/s"},
+		{"title", "Holiday"},
+		{"container format", "Matroska"},
+	}
+	if len(fields) != len(wantFields) {
+		t.Fatalf("Fields() = %v, want %v", fields, wantFields)
+	}
+	for i := range wantFields {
+		if fields[i] != wantFields[i] {
+			t.Errorf("Fields()[%d] = %v, want %v", i, fields[i], wantFields[i])
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"0:00:01.000000000", time.Second},
+		{"1:02:03.5", time.Hour + 2*time.Minute + 3500*time.Millisecond},
+		{"99:99", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseClock(tt.in); got != tt.want {
+			t.Errorf("parseClock(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "0:00"},
+		{3*time.Minute + 45500*time.Millisecond, "3:46"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "1:02:03"},
+	}
+	for _, tt := range tests {
+		if got := FormatDuration(tt.in); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExternalToolsMissing(t *testing.T) {
+	t.Setenv("PATH", "")
+
+	if _, err := ProbeMedia("song.mp3"); !errors.Is(err, ErrNoProber) {
+		t.Errorf("ProbeMedia() error = %v, want ErrNoProber", err)
+	}
+	if _, err := RenderPDFPage("manual.pdf", 800); !errors.Is(err, ErrNoRenderer) {
+		t.Errorf("RenderPDFPage() error = %v, want ErrNoRenderer", err)
+	}
+}
@@ -0,0 +1,47 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ErrNoRenderer is returned by RenderPDFPage when poppler's pdftoppm is
+// not installed.
+var ErrNoRenderer = errors.New("pdftoppm is not installed")
+
+// pdfTimeout bounds how long a page may take to render.
+const pdfTimeout = 10 * time.Second
+
+// RenderPDFPage renders the first page of a PDF as PNG data, scaled so its
+// longer side is size pixels. It runs pdftoppm from poppler-utils, the
+// same renderer poppler-glib wraps.
+func RenderPDFPage(path string, size int) ([]byte, error) {
+	pdftoppm, err := exec.LookPath("pdftoppm")
+	if err != nil {
+		return nil, ErrNoRenderer
+	}
+
+	dir, err := os.MkdirTemp("", "warren-pdf-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdfTimeout)
+	defer cancel()
+
+	out := filepath.Join(dir, "page")
+	// #nosec G204 -- fixed arguments; the path is passed as a single argument
+	cmd := exec.CommandContext(ctx, pdftoppm, "-png", "-f", "1", "-l", "1",
+		"-singlefile", "-scale-to", strconv.Itoa(size), path, out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w: %s", filepath.Base(path), err, firstLine(output))
+	}
+	return os.ReadFile(out + ".png") // #nosec G304 -- our own temporary file
+}
@@ -0,0 +1,162 @@
+package preview
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrBinary is returned for files that don't look like text.
+var ErrBinary = errors.New("binary file")
+
+// ErrSpecial is returned for named pipes, sockets and devices, which are
+// never opened: reading a FIFO or a terminal would block indefinitely.
+var ErrSpecial = errors.New("special file")
+
+// Text is the start of a file decoded for display.
+type Text struct {
+	Content   string // Decoded text with line endings normalised to \n
+	Encoding  string // "UTF-8", "UTF-16LE", "UTF-16BE" or "ISO-8859-1"
+	Truncated bool   // The file is longer than the limit that was read
+}
+
+// Byte order marks recognised by Decode.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// maxControlRatio is the share of control characters above which
+// non-UTF-8 data is treated as binary rather than ISO-8859-1 text.
+const maxControlRatio = 0.1
+
+// LoadText reads up to limit bytes of a file and decodes them. Only
+// regular files (or symlinks to them) are read; anything else is
+// ErrSpecial.
+func LoadText(path string, limit int) (Text, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Text{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return Text{}, ErrSpecial
+	}
+
+	f, err := os.Open(path) // #nosec G304 -- previewing the user's own files
+	if err != nil {
+		return Text{}, err
+	}
+	defer f.Close()
+
+	// One byte more than the limit tells whether there is more
+	data, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil {
+		return Text{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	truncated := len(data) > limit
+	if truncated {
+		data = data[:limit]
+	}
+	return Decode(data, truncated)
+}
+
+// Decode detects the encoding of data and decodes it. truncated says data
+// was cut off at an arbitrary byte, so a partial character at the end is
+// dropped rather than shown as invalid.
+func Decode(data []byte, truncated bool) (Text, error) {
+	text := Text{Truncated: truncated}
+
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		text.Encoding = "UTF-8"
+		text.Content = decodeUTF8(data[len(bomUTF8):], truncated)
+	case bytes.HasPrefix(data, bomUTF16LE):
+		text.Encoding = "UTF-16LE"
+		text.Content = decodeUTF16(data[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		text.Encoding = "UTF-16BE"
+		text.Content = decodeUTF16(data[len(bomUTF16BE):], true)
+	case bytes.IndexByte(data, 0) >= 0:
+		return Text{}, ErrBinary
+	case utf8.Valid(trimPartialRune(data, truncated)):
+		text.Encoding = "UTF-8"
+		text.Content = decodeUTF8(data, truncated)
+	case controlRatio(data) > maxControlRatio:
+		return Text{}, ErrBinary
+	default:
+		text.Encoding = "ISO-8859-1"
+		text.Content = decodeLatin1(data)
+	}
+
+	text.Content = strings.ReplaceAll(text.Content, "\r\n", "\n")
+	return text, nil
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence at the end of
+// truncated data.
+func trimPartialRune(data []byte, truncated bool) []byte {
+	if !truncated {
+		return data
+	}
+	// A sequence is at most 4 bytes, so only the last 3 can be a cut-off start
+	for i := 1; i <= min(3, len(data)); i++ {
+		b := data[len(data)-i]
+		if !utf8.RuneStart(b) {
+			continue
+		}
+		if !utf8.FullRune(data[len(data)-i:]) {
+			return data[:len(data)-i]
+		}
+		break
+	}
+	return data
+}
+
+// decodeUTF8 returns data as a string, replacing invalid sequences.
+func decodeUTF8(data []byte, truncated bool) string {
+	return strings.ToValidUTF8(string(trimPartialRune(data, truncated)), "�")
+}
+
+// decodeUTF16 decodes UTF-16 data, ignoring a trailing odd byte.
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		lo, hi := data[2*i], data[2*i+1]
+		if bigEndian {
+			lo, hi = hi, lo
+		}
+		units[i] = uint16(lo) | uint16(hi)<<8
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeLatin1 decodes ISO-8859-1, where every byte is its own code point.
+func decodeLatin1(data []byte) string {
+	var b strings.Builder
+	b.Grow(len(data) * 2)
+	for _, c := range data {
+		b.WriteRune(rune(c))
+	}
+	return b.String()
+}
+
+// controlRatio returns the share of bytes that are C0 control characters
+// other than the whitespace and escapes found in text files.
+func controlRatio(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var controls int
+	for _, c := range data {
+		if c < 0x20 && !strings.ContainsRune("\t\n\r\f\x1b", rune(c)) {
+			controls++
+		}
+	}
+	return float64(controls) / float64(len(data))
+}
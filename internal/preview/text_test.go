@@ -0,0 +1,104 @@
+package preview
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		truncated bool
+		want      string
+		encoding  string
+		wantErr   error
+	}{
+		{"ascii", []byte("hello\n"), false, "hello\n", "UTF-8", nil},
+		{"utf-8", []byte("héllo wörld"), false, "héllo wörld", "UTF-8", nil},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFbom"), false, "bom", "UTF-8", nil},
+		{"crlf", []byte("a\r\nb\r\n"), false, "a\nb\n", "UTF-8", nil},
+		{"cut inside a character", []byte("caf\xC3"), true, "caf", "UTF-8", nil},
+		{"utf-16le", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, false, "hi", "UTF-16LE", nil},
+		{"utf-16be", []byte{0xFE, 0xFF, 0, 'h', 0, 'i'}, false, "hi", "UTF-16BE", nil},
+		{"latin-1", []byte("caf\xE9 cr\xE8me"), false, "café crème", "ISO-8859-1", nil},
+		{"nul bytes", []byte("ELF\x00\x01\x02"), false, "", "", ErrBinary},
+		{"control characters", []byte("\x01\x02\x03\x04\xFFabc"), false, "", "", ErrBinary},
+		{"empty", nil, false, "", "UTF-8", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data, tt.truncated)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Content != tt.want || got.Encoding != tt.encoding {
+				t.Errorf("Decode() = %q (%s), want %q (%s)", got.Content, got.Encoding, tt.want, tt.encoding)
+			}
+			if got.Truncated != tt.truncated {
+				t.Errorf("Decode() Truncated = %v, want %v", got.Truncated, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestLoadText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		limit     int
+		want      string
+		truncated bool
+	}{
+		{100, "0123456789", false},
+		{10, "0123456789", false},
+		{4, "0123", true},
+	}
+	for _, tt := range tests {
+		got, err := LoadText(path, tt.limit)
+		if err != nil {
+			t.Fatalf("LoadText(limit %d) error = %v", tt.limit, err)
+		}
+		if got.Content != tt.want || got.Truncated != tt.truncated {
+			t.Errorf("LoadText(limit %d) = %q truncated=%v, want %q truncated=%v",
+				tt.limit, got.Content, got.Truncated, tt.want, tt.truncated)
+		}
+	}
+
+	if _, err := LoadText(filepath.Join(t.TempDir(), "missing"), 10); err == nil {
+		t.Error("LoadText() of a missing file should fail")
+	}
+}
+
+func TestLoadTextSpecialFile(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "pipe")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("mkfifo not supported: %v", err)
+	}
+
+	// Opening a FIFO without a writer would block, so a hang here is the bug
+	done := make(chan error, 1)
+	go func() {
+		_, err := LoadText(fifo, 10)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrSpecial) {
+			t.Errorf("LoadText(fifo) error = %v, want ErrSpecial", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("LoadText(fifo) blocked")
+	}
+}
@@ -0,0 +1,10 @@
+// Package registers holds sets of yanked files under vim-style names, so
+// several can be kept at once: `"a y` yanks into register a and `"a p`
+// pastes from it.
+//
+// The unnamed register is the ordinary yank, shown by the file view's yank
+// indicators. It is the one saved in the state directory, so a yank
+// survives a restart; the named registers a to z last for the session.
+// The package has no GTK dependency. Registers is not safe for concurrent
+// use; cmd/warren only touches it on the GTK main thread.
+package registers
@@ -0,0 +1,170 @@
+package registers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// Unnamed is the register yanks go to when none is named.
+const Unnamed = '"'
+
+// File is the unnamed register's file name in the state directory.
+const File = "yank.json"
+
+// Register is a set of yanked files. Cut files are moved on paste rather
+// than copied.
+type Register struct {
+	Paths []string `json:"paths"`
+	Cut   bool     `json:"cut,omitempty"`
+}
+
+// IsEmpty reports whether the register holds no files.
+func (r Register) IsEmpty() bool {
+	return len(r.Paths) == 0
+}
+
+// summaryNames is how many file names Summary lists.
+const summaryNames = 3
+
+// Summary describes the register in a list of registers, e.g.
+// "2 file(s), cut: a.txt, b.txt". Only the first few names are given.
+func (r Register) Summary() string {
+	names := make([]string, 0, summaryNames)
+	for _, path := range r.Paths[:min(len(r.Paths), summaryNames)] {
+		names = append(names, filepath.Base(path))
+	}
+	list := strings.Join(names, ", ")
+	if len(r.Paths) > summaryNames {
+		list += ", …"
+	}
+	kind := ""
+	if r.Cut {
+		kind = ", cut"
+	}
+	return fmt.Sprintf("%d file(s)%s: %s", len(r.Paths), kind, list)
+}
+
+// Valid reports whether name is a register: Unnamed or a to z.
+func Valid(name rune) bool {
+	return name == Unnamed || (name >= 'a' && name <= 'z')
+}
+
+// Name returns how a register is written before a command, e.g. `"a`.
+func Name(name rune) string {
+	if name == Unnamed {
+		return `""`
+	}
+	return `"` + string(name)
+}
+
+// Registers is a set of registers.
+type Registers struct {
+	regs map[rune]Register
+	path string // Where the unnamed register is saved; "" to not save it
+}
+
+// New creates empty registers, saving the unnamed one in stateDir, or
+// nowhere if stateDir is "".
+func New(stateDir string) *Registers {
+	r := &Registers{regs: make(map[rune]Register)}
+	if stateDir != "" {
+		r.path = filepath.Join(stateDir, File)
+	}
+	return r
+}
+
+// Get returns a register, empty if nothing was yanked into it.
+func (r *Registers) Get(name rune) Register {
+	return r.regs[name]
+}
+
+// Set stores files in a register, replacing what it held. Setting an
+// invalid name does nothing.
+func (r *Registers) Set(name rune, reg Register) {
+	if !Valid(name) {
+		return
+	}
+	if reg.IsEmpty() {
+		delete(r.regs, name)
+		return
+	}
+	r.regs[name] = Register{Paths: append([]string(nil), reg.Paths...), Cut: reg.Cut}
+}
+
+// Clear empties a register.
+func (r *Registers) Clear(name rune) {
+	delete(r.regs, name)
+}
+
+// Names lists the registers holding files: Unnamed first, then a to z.
+func (r *Registers) Names() []rune {
+	var names []rune
+	if _, ok := r.regs[Unnamed]; ok {
+		names = append(names, Unnamed)
+	}
+	for name := 'a'; name <= 'z'; name++ {
+		if _, ok := r.regs[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Load reads the saved unnamed register. Paths that no longer exist are
+// dropped. A missing file is not an error.
+func (r *Registers) Load() error {
+	if r.path == "" {
+		return nil
+	}
+	// #nosec G304 -- the path is in Warren's state directory
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var saved Register
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("invalid %s: %w", File, err)
+	}
+	var paths []string
+	for _, path := range saved.Paths {
+		if _, err := os.Lstat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	r.Set(Unnamed, Register{Paths: paths, Cut: saved.Cut})
+	return nil
+}
+
+// Save writes the unnamed register, or removes the file when it is empty.
+func (r *Registers) Save() error {
+	if r.path == "" {
+		return nil
+	}
+	reg := r.regs[Unnamed]
+	if reg.IsEmpty() {
+		if err := os.Remove(r.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	return fileops.WriteFileAtomic(r.path, data, 0600)
+}
@@ -0,0 +1,99 @@
+package registers
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRegisters(t *testing.T) {
+	r := New("")
+
+	r.Set('b', Register{Paths: []string{"/b"}, Cut: true})
+	r.Set(Unnamed, Register{Paths: []string{"/x"}})
+	r.Set('a', Register{Paths: []string{"/a1", "/a2"}})
+	r.Set('A', Register{Paths: []string{"/ignored"}})
+
+	if got, want := r.Names(), []rune{Unnamed, 'a', 'b'}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %q, want %q", got, want)
+	}
+	if reg := r.Get('b'); !reg.Cut || !slices.Equal(reg.Paths, []string{"/b"}) {
+		t.Errorf("Get('b') = %+v", reg)
+	}
+	if !r.Get('c').IsEmpty() {
+		t.Error("register c is not empty")
+	}
+
+	// Setting an empty register or clearing one removes it
+	r.Set('a', Register{})
+	r.Clear('b')
+	if got, want := r.Names(), []rune{Unnamed}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %q after clearing, want %q", got, want)
+	}
+}
+
+func TestName(t *testing.T) {
+	if got := Name('a'); got != `"a` {
+		t.Errorf(`Name('a') = %s, want "a`, got)
+	}
+	if got := Name(Unnamed); got != `""` {
+		t.Errorf(`Name(Unnamed) = %s, want ""`, got)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		reg  Register
+		want string
+	}{
+		{Register{Paths: []string{"/d/a.txt"}}, "1 file(s): a.txt"},
+		{Register{Paths: []string{"/d/a", "/d/b"}, Cut: true}, "2 file(s), cut: a, b"},
+		{Register{Paths: []string{"/a", "/b", "/c", "/d"}}, "4 file(s): a, b, c, …"},
+	}
+	for _, tt := range tests {
+		if got := tt.reg.Summary(); got != tt.want {
+			t.Errorf("Summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := filepath.Join(dir, "state")
+	kept := filepath.Join(dir, "kept.txt")
+	if err := os.WriteFile(kept, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(stateDir)
+	r.Set(Unnamed, Register{Paths: []string{kept, filepath.Join(dir, "gone.txt")}, Cut: true})
+	r.Set('a', Register{Paths: []string{kept}})
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Only the unnamed register is saved, without files deleted since
+	loaded := New(stateDir)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if reg := loaded.Get(Unnamed); !reg.Cut || !slices.Equal(reg.Paths, []string{kept}) {
+		t.Errorf("loaded unnamed register = %+v, want %s cut", reg, kept)
+	}
+	if !loaded.Get('a').IsEmpty() {
+		t.Error("named register was saved")
+	}
+
+	// An empty unnamed register removes the file
+	loaded.Clear(Unnamed)
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, File)); !os.IsNotExist(err) {
+		t.Errorf("%s still exists after saving an empty register", File)
+	}
+	if err := New(stateDir).Load(); err != nil {
+		t.Errorf("Load() without a file: %v", err)
+	}
+}
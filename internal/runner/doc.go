@@ -0,0 +1,8 @@
+// Package runner runs an executable the user picked in the file list,
+// streaming what it prints so cmd/warren can show it as it arrives.
+//
+// Arguments typed for it are split like a shell would split words, with
+// quotes and backslashes, but nothing else of the shell applies: there is
+// no expansion, globbing or redirection. The package has no GTK
+// dependency.
+package runner
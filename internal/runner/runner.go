@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Line is a line of output, without its newline.
+type Line struct {
+	Text   string
+	Stderr bool
+}
+
+// Executable reports whether path is a regular file, or a link to one,
+// that someone may execute.
+func Executable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// Split splits typed arguments into words. Single quotes keep everything
+// up to the next one, double quotes keep spaces and honour backslash
+// escapes, and a backslash outside quotes escapes the next character.
+func Split(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inWord = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("nothing after the final backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Run runs argv in dir and passes each line it prints to output, from
+// stdout and stderr as they arrive. output is called from other
+// goroutines, one line at a time. Run returns the exit status once the
+// program has finished and its output has been read; err is only set if
+// it could not be started, or was killed by cancelling ctx or a signal.
+func Run(ctx context.Context, dir string, argv []string, output func(Line)) (status int, err error) {
+	if len(argv) == 0 {
+		return -1, errors.New("no program to run")
+	}
+	// #nosec G204 -- Running the program the user picked is the point
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return -1, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return -1, err
+	}
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, pipe := range []struct {
+		r      io.Reader
+		stderr bool
+	}{{stdout, false}, {stderr, true}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			readLines(pipe.r, func(text string) {
+				mu.Lock()
+				defer mu.Unlock()
+				output(Line{Text: text, Stderr: pipe.stderr})
+			})
+		}()
+	}
+	// The pipes must be read to the end before Wait closes them
+	wg.Wait()
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// readLines passes each line of r to line, including a last one without
+// a newline.
+func readLines(r io.Reader, line func(string)) {
+	reader := bufio.NewReader(r)
+	for {
+		text, err := reader.ReadString('\n')
+		if text != "" {
+			line(strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r"))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  -v  --name x ", []string{"-v", "--name", "x"}},
+		{`'a b' "c d" e\ f`, []string{"a b", "c d", "e f"}},
+		{`"say \"hi\"" 'it''s'`, []string{`say "hi"`, "its"}},
+		{`'' x`, []string{"", "x"}},
+		{`'$HOME' *.go`, []string{"$HOME", "*.go"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.line)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, %v, want %q", tt.line, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{`"open`, `'open`, `end\`} {
+		if _, err := Split(bad); err == nil {
+			t.Errorf("Split(%q) succeeded", bad)
+		}
+	}
+}
+
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun(t *testing.T) {
+	script := writeScript(t, `echo "out $1"; echo err >&2; pwd; printf last; exit 3`)
+	dir := t.TempDir()
+
+	var out, errs []string
+	status, err := Run(context.Background(), dir, []string{script, "arg"}, func(l Line) {
+		if l.Stderr {
+			errs = append(errs, l.Text)
+		} else {
+			out = append(out, l.Text)
+		}
+	})
+	if err != nil || status != 3 {
+		t.Fatalf("Run() = %d, %v, want 3", status, err)
+	}
+	if want := []string{"out arg", dir, "last"}; !slices.Equal(out, want) {
+		t.Errorf("stdout = %q, want %q", out, want)
+	}
+	if want := []string{"err"}; !slices.Equal(errs, want) {
+		t.Errorf("stderr = %q, want %q", errs, want)
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	script := writeScript(t, "exec sleep 10")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, "", []string{script}, func(Line) {}); err == nil {
+		t.Error("Run() of a cancelled program reported no error")
+	}
+}
+
+func TestRunMissing(t *testing.T) {
+	_, err := Run(context.Background(), "", []string{"/nonexistent/program"}, func(Line) {})
+	if err == nil || !strings.Contains(err.Error(), "nonexistent") {
+		t.Errorf("Run() of a missing program: %v", err)
+	}
+}
+
+func TestExecutable(t *testing.T) {
+	script := writeScript(t, "true")
+	plain := filepath.Join(t.TempDir(), "plain.txt")
+	if err := os.WriteFile(plain, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !Executable(script) || Executable(plain) || Executable(filepath.Dir(script)) {
+		t.Error("Executable() wrong for a script, a plain file or a directory")
+	}
+}
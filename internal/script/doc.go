@@ -0,0 +1,16 @@
+// Package script embeds a Lua runtime for user-defined commands.
+//
+// At startup Warren runs ~/.config/warren/init.lua, which can define
+// commands and bind them to keys through the global "warren" table:
+//
+//	warren.command("home", function()
+//	    warren.cd(os.getenv("HOME"))
+//	end)
+//	warren.bind("g h", "home")
+//
+// The API covers navigation (cd, up, pwd, reload), selection (selected,
+// select), file operations (copy, move, trash) and status messages. The
+// package has no GTK dependency: the UI supplies a Host that performs each
+// call. Lua state is not thread-safe, so all calls must come from the GTK
+// main thread.
+package script
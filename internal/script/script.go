@@ -0,0 +1,217 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// InitFile is the script loaded from Warren's config directory.
+const InitFile = "init.lua"
+
+// Host performs the actions scripts request. Errors are raised as Lua
+// errors in the calling script.
+type Host interface {
+	Cd(path string) error
+	Up() error
+	Pwd() string
+	Reload() error
+	Selected() string // Empty if nothing is selected
+	Select(path string) error
+	Copy(sources []string, dest string) error
+	Move(sources []string, dest string) error
+	Trash(paths []string) error
+	Status(message string)
+}
+
+// Binding maps a key to a script command.
+type Binding struct {
+	Key     string // Key name as used in the [keybindings] config section
+	Command string // Name of the command to run
+}
+
+// Engine is a Lua state with Warren's API installed.
+type Engine struct {
+	state    *lua.LState
+	host     Host
+	commands map[string]*lua.LFunction
+	bindings []Binding
+}
+
+// New creates an engine whose API calls are forwarded to host.
+func New(host Host) *Engine {
+	e := &Engine{
+		state:    lua.NewState(),
+		host:     host,
+		commands: make(map[string]*lua.LFunction),
+	}
+	e.installAPI()
+	return e
+}
+
+// LoadFile runs a script file, registering its commands and bindings.
+func (e *Engine) LoadFile(path string) error {
+	if err := e.state.DoFile(path); err != nil {
+		return fmt.Errorf("failed to run %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadString runs script source. Used by tests.
+func (e *Engine) loadString(source string) error {
+	return e.state.DoString(source)
+}
+
+// Commands returns the names of all defined commands, sorted.
+func (e *Engine) Commands() []string {
+	names := make([]string, 0, len(e.commands))
+	for name := range e.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Bindings returns the key bindings in the order they were declared.
+func (e *Engine) Bindings() []Binding {
+	return e.bindings
+}
+
+// Run executes a command by name.
+func (e *Engine) Run(name string) error {
+	fn, ok := e.commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+	if err := e.state.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}); err != nil {
+		return fmt.Errorf("command %s failed: %w", name, err)
+	}
+	return nil
+}
+
+// Close releases the Lua state.
+func (e *Engine) Close() {
+	e.state.Close()
+}
+
+// installAPI registers the global "warren" table.
+func (e *Engine) installAPI() {
+	L := e.state
+	api := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		// Navigation
+		"cd": func(L *lua.LState) int {
+			e.check(L, e.host.Cd(L.CheckString(1)))
+			return 0
+		},
+		"up": func(L *lua.LState) int {
+			e.check(L, e.host.Up())
+			return 0
+		},
+		"pwd": func(L *lua.LState) int {
+			L.Push(lua.LString(e.host.Pwd()))
+			return 1
+		},
+		"reload": func(L *lua.LState) int {
+			e.check(L, e.host.Reload())
+			return 0
+		},
+
+		// Selection
+		"selected": func(L *lua.LState) int {
+			if path := e.host.Selected(); path != "" {
+				L.Push(lua.LString(path))
+			} else {
+				L.Push(lua.LNil)
+			}
+			return 1
+		},
+		"select": func(L *lua.LState) int {
+			e.check(L, e.host.Select(L.CheckString(1)))
+			return 0
+		},
+
+		// File operations
+		"copy": func(L *lua.LState) int {
+			e.check(L, e.host.Copy(checkPaths(L, 1), L.CheckString(2)))
+			return 0
+		},
+		"move": func(L *lua.LState) int {
+			e.check(L, e.host.Move(checkPaths(L, 1), L.CheckString(2)))
+			return 0
+		},
+		"trash": func(L *lua.LState) int {
+			e.check(L, e.host.Trash(checkPaths(L, 1)))
+			return 0
+		},
+
+		// Status bar
+		"status": func(L *lua.LState) int {
+			e.host.Status(L.CheckString(1))
+			return 0
+		},
+
+		// Commands and bindings
+		"command": func(L *lua.LState) int {
+			e.commands[L.CheckString(1)] = L.CheckFunction(2)
+			return 0
+		},
+		"bind": func(L *lua.LState) int {
+			key := L.CheckString(1)
+			var command string
+			switch v := L.Get(2).(type) {
+			case lua.LString:
+				command = string(v)
+			case *lua.LFunction:
+				// Anonymous commands are named after their key
+				command = "key:" + key
+				e.commands[command] = v
+			default:
+				L.ArgError(2, "command name or function expected")
+			}
+			e.bind(key, command)
+			return 0
+		},
+	})
+	L.SetGlobal("warren", api)
+}
+
+// bind adds a binding, replacing any earlier binding for the same key.
+func (e *Engine) bind(key, command string) {
+	for i := range e.bindings {
+		if e.bindings[i].Key == key {
+			e.bindings[i].Command = command
+			return
+		}
+	}
+	e.bindings = append(e.bindings, Binding{Key: key, Command: command})
+}
+
+// check raises err as a Lua error.
+func (e *Engine) check(L *lua.LState, err error) {
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+}
+
+// checkPaths reads argument n as a path or a list of paths.
+func checkPaths(L *lua.LState, n int) []string {
+	switch v := L.Get(n).(type) {
+	case lua.LString:
+		return []string{string(v)}
+	case *lua.LTable:
+		var paths []string
+		v.ForEach(func(_, value lua.LValue) {
+			if s, ok := value.(lua.LString); ok {
+				paths = append(paths, string(s))
+			}
+		})
+		if len(paths) == 0 {
+			L.ArgError(n, "no paths given")
+		}
+		return paths
+	default:
+		L.ArgError(n, "path or list of paths expected")
+		return nil
+	}
+}
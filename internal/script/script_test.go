@@ -0,0 +1,208 @@
+package script
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeHost records calls made by scripts.
+type fakeHost struct {
+	cwd      string
+	selected string
+	calls    []string
+	status   string
+	failCd   bool
+}
+
+func (h *fakeHost) Cd(path string) error {
+	if h.failCd {
+		return errors.New("no such directory")
+	}
+	h.cwd = path
+	h.calls = append(h.calls, "cd "+path)
+	return nil
+}
+
+func (h *fakeHost) Up() error {
+	h.cwd = filepath.Dir(h.cwd)
+	h.calls = append(h.calls, "up")
+	return nil
+}
+
+func (h *fakeHost) Pwd() string      { return h.cwd }
+func (h *fakeHost) Selected() string { return h.selected }
+func (h *fakeHost) Status(msg string) {
+	h.status = msg
+}
+
+func (h *fakeHost) Reload() error {
+	h.calls = append(h.calls, "reload")
+	return nil
+}
+
+func (h *fakeHost) Select(path string) error {
+	h.selected = path
+	h.calls = append(h.calls, "select "+path)
+	return nil
+}
+
+func (h *fakeHost) Copy(sources []string, dest string) error {
+	h.calls = append(h.calls, "copy "+strings.Join(sources, ",")+" "+dest)
+	return nil
+}
+
+func (h *fakeHost) Move(sources []string, dest string) error {
+	h.calls = append(h.calls, "move "+strings.Join(sources, ",")+" "+dest)
+	return nil
+}
+
+func (h *fakeHost) Trash(paths []string) error {
+	h.calls = append(h.calls, "trash "+strings.Join(paths, ","))
+	return nil
+}
+
+func newTestEngine(t *testing.T, host *fakeHost, source string) *Engine {
+	t.Helper()
+	e := New(host)
+	t.Cleanup(e.Close)
+	if err := e.loadString(source); err != nil {
+		t.Fatalf("Failed to load script: %v", err)
+	}
+	return e
+}
+
+func TestCommandsAndBindings(t *testing.T) {
+	host := &fakeHost{cwd: "/home/user"}
+	e := newTestEngine(t, host, `
+		warren.command("projects", function() warren.cd("/home/user/Projects") end)
+		warren.command("parent", function() warren.up() end)
+		warren.bind("P", "projects")
+		warren.bind("u", function() warren.reload() end)
+		warren.bind("P", "parent")
+	`)
+
+	if got, want := e.Commands(), []string{"key:u", "parent", "projects"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Commands() = %v, want %v", got, want)
+	}
+
+	want := []Binding{{Key: "P", Command: "parent"}, {Key: "u", Command: "key:u"}}
+	if got := e.Bindings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Bindings() = %v, want %v", got, want)
+	}
+
+	if err := e.Run("projects"); err != nil {
+		t.Fatalf("Run(projects) failed: %v", err)
+	}
+	if err := e.Run("key:u"); err != nil {
+		t.Fatalf("Run(key:u) failed: %v", err)
+	}
+	if got, want := host.calls, []string{"cd /home/user/Projects", "reload"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("host calls = %v, want %v", got, want)
+	}
+}
+
+func TestSelectionAndStatus(t *testing.T) {
+	host := &fakeHost{cwd: "/tmp", selected: "/tmp/a.txt"}
+	e := newTestEngine(t, host, `
+		warren.command("show", function()
+			local sel = warren.selected()
+			warren.status(warren.pwd() .. " " .. (sel or "none"))
+		end)
+		warren.command("pick", function() warren.select("/tmp/b.txt") end)
+	`)
+
+	if err := e.Run("show"); err != nil {
+		t.Fatalf("Run(show) failed: %v", err)
+	}
+	if host.status != "/tmp /tmp/a.txt" {
+		t.Errorf("status = %q", host.status)
+	}
+
+	host.selected = ""
+	if err := e.Run("show"); err != nil {
+		t.Fatalf("Run(show) failed: %v", err)
+	}
+	if host.status != "/tmp none" {
+		t.Errorf("selected() should be nil with no selection, status = %q", host.status)
+	}
+
+	if err := e.Run("pick"); err != nil {
+		t.Fatalf("Run(pick) failed: %v", err)
+	}
+	if host.selected != "/tmp/b.txt" {
+		t.Errorf("selected = %q, want /tmp/b.txt", host.selected)
+	}
+}
+
+func TestFileOperations(t *testing.T) {
+	host := &fakeHost{}
+	e := newTestEngine(t, host, `
+		warren.command("ops", function()
+			warren.copy("/a", "/dest")
+			warren.move({"/b", "/c"}, "/dest")
+			warren.trash({"/d"})
+		end)
+	`)
+
+	if err := e.Run("ops"); err != nil {
+		t.Fatalf("Run(ops) failed: %v", err)
+	}
+	want := []string{"copy /a /dest", "move /b,/c /dest", "trash /d"}
+	if !reflect.DeepEqual(host.calls, want) {
+		t.Errorf("host calls = %v, want %v", host.calls, want)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	host := &fakeHost{failCd: true}
+	e := newTestEngine(t, host, `
+		warren.command("broken", function() warren.cd("/missing") end)
+		warren.command("badargs", function() warren.trash(42) end)
+	`)
+
+	tests := []struct {
+		name    string
+		command string
+		wantErr string
+	}{
+		{"unknown command", "nope", "unknown command"},
+		{"host error raised", "broken", "no such directory"},
+		{"bad argument", "badargs", "path or list of paths expected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Run(tt.command)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run(%q) error = %v, want containing %q", tt.command, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), InitFile)
+	if err := os.WriteFile(path, []byte(`warren.bind("g", function() end)`), 0600); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	e := New(&fakeHost{})
+	defer e.Close()
+	if err := e.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if len(e.Bindings()) != 1 {
+		t.Errorf("expected 1 binding, got %d", len(e.Bindings()))
+	}
+
+	if err := os.WriteFile(path, []byte(`this is not lua`), 0600); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+	if err := New(&fakeHost{}).LoadFile(path); err == nil {
+		t.Error("LoadFile should fail on a syntax error")
+	}
+}
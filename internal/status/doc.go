@@ -0,0 +1,10 @@
+// Package status implements the status bar's message queue.
+//
+// Transient messages carry a severity (info, warning, error) that sets how
+// long they stay visible and how they are styled. Messages are shown in
+// order; one that has newer messages waiting is cut short after a minimum
+// display time so the bar never falls far behind. When the queue is empty
+// the status bar shows its persistent summary (selection and yank state).
+// The package is time-driven and has no GTK dependency; ui.StatusBar
+// renders it.
+package status
@@ -0,0 +1,165 @@
+package status
+
+import "time"
+
+// Severity classifies a status message.
+type Severity int
+
+const (
+	// SeverityInfo is routine feedback ("Yanked: notes.txt").
+	SeverityInfo Severity = iota
+	// SeverityWarning is something the user should notice.
+	SeverityWarning
+	// SeverityError is a failed action.
+	SeverityError
+)
+
+// String returns the severity name.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// CSSClass returns the style class applied to the status label.
+func (s Severity) CSSClass() string {
+	return "status-" + s.String()
+}
+
+// Duration returns how long messages of this severity stay visible.
+func (s Severity) Duration() time.Duration {
+	switch s {
+	case SeverityWarning:
+		return 5 * time.Second
+	case SeverityError:
+		return 8 * time.Second
+	default:
+		return 3 * time.Second
+	}
+}
+
+// Severities lists all severities, for clearing style classes.
+var Severities = []Severity{SeverityInfo, SeverityWarning, SeverityError}
+
+// DefaultMinDisplay is how long a message stays up before a waiting
+// message may replace it.
+const DefaultMinDisplay = time.Second
+
+// maxPending bounds the queue; the oldest waiting messages are dropped.
+const maxPending = 5
+
+// Message is a transient status bar message.
+type Message struct {
+	Text     string
+	Severity Severity
+	Duration time.Duration // Zero uses the severity's default
+}
+
+// duration returns the effective display time.
+func (m Message) duration() time.Duration {
+	if m.Duration > 0 {
+		return m.Duration
+	}
+	return m.Severity.Duration()
+}
+
+// queued is a waiting message and when it was pushed.
+type queued struct {
+	msg      Message
+	queuedAt time.Time
+}
+
+// Queue orders transient messages and expires them.
+type Queue struct {
+	MinDisplay time.Duration
+
+	current Message
+	shownAt time.Time
+	active  bool
+	pending []queued
+}
+
+// NewQueue creates an empty queue using DefaultMinDisplay.
+func NewQueue() *Queue {
+	return &Queue{MinDisplay: DefaultMinDisplay}
+}
+
+// Push adds a message. A message identical to the last one queued is
+// ignored so repeated actions don't pile up.
+func (q *Queue) Push(m Message, now time.Time) {
+	if n := len(q.pending); n > 0 {
+		if q.pending[n-1].msg == m {
+			return
+		}
+	} else if q.active && q.current == m && now.Before(q.stopAt()) {
+		q.shownAt = now // Restart the timer on the visible message
+		return
+	}
+
+	if _, showing := q.Current(now); !showing {
+		q.current, q.shownAt, q.active = m, now, true
+		return
+	}
+
+	q.pending = append(q.pending, queued{msg: m, queuedAt: now})
+	if len(q.pending) > maxPending {
+		q.pending = q.pending[len(q.pending)-maxPending:]
+	}
+}
+
+// stopAt returns when the current message stops showing. Waiting messages
+// cut it short after MinDisplay.
+func (q *Queue) stopAt() time.Time {
+	d := q.current.duration()
+	if len(q.pending) > 0 {
+		d = min(d, q.MinDisplay)
+	}
+	return q.shownAt.Add(d)
+}
+
+// Current returns the message to display at now, advancing past expired
+// messages. ok is false when no message is showing.
+func (q *Queue) Current(now time.Time) (msg Message, ok bool) {
+	for q.active {
+		stop := q.stopAt()
+		if now.Before(stop) {
+			return q.current, true
+		}
+		if len(q.pending) == 0 {
+			q.active = false
+			break
+		}
+
+		// The next message starts when the previous one stopped showing
+		next := q.pending[0]
+		q.pending = q.pending[1:]
+		q.current = next.msg
+		q.shownAt = stop
+		if next.queuedAt.After(stop) {
+			q.shownAt = next.queuedAt
+		}
+	}
+	return Message{}, false
+}
+
+// NextUpdate returns how long until Current may return something
+// different. ok is false if nothing is showing.
+func (q *Queue) NextUpdate(now time.Time) (time.Duration, bool) {
+	if _, ok := q.Current(now); !ok {
+		return 0, false
+	}
+	return q.stopAt().Sub(now), true
+}
+
+// Clear drops the current and waiting messages.
+func (q *Queue) Clear() {
+	q.active = false
+	q.pending = nil
+}
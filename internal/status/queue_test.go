@@ -0,0 +1,150 @@
+package status
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		sev      Severity
+		name     string
+		class    string
+		duration time.Duration
+	}{
+		{SeverityInfo, "info", "status-info", 3 * time.Second},
+		{SeverityWarning, "warning", "status-warning", 5 * time.Second},
+		{SeverityError, "error", "status-error", 8 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sev.String(); got != tt.name {
+			t.Errorf("String() = %q, want %q", got, tt.name)
+		}
+		if got := tt.sev.CSSClass(); got != tt.class {
+			t.Errorf("CSSClass() = %q, want %q", got, tt.class)
+		}
+		if got := tt.sev.Duration(); got != tt.duration {
+			t.Errorf("%s Duration() = %v, want %v", tt.name, got, tt.duration)
+		}
+	}
+}
+
+func TestQueueExpires(t *testing.T) {
+	q := NewQueue()
+	start := time.Now()
+
+	if _, ok := q.Current(start); ok {
+		t.Fatal("empty queue should show nothing")
+	}
+
+	q.Push(Message{Text: "hello"}, start)
+	if msg, ok := q.Current(start.Add(2 * time.Second)); !ok || msg.Text != "hello" {
+		t.Errorf("Current() = (%q, %v), want hello", msg.Text, ok)
+	}
+	if d, ok := q.NextUpdate(start.Add(2 * time.Second)); !ok || d != time.Second {
+		t.Errorf("NextUpdate() = (%v, %v), want 1s", d, ok)
+	}
+	if _, ok := q.Current(start.Add(3 * time.Second)); ok {
+		t.Error("info message should expire after 3s")
+	}
+	if _, ok := q.NextUpdate(start.Add(3 * time.Second)); ok {
+		t.Error("NextUpdate should report nothing showing")
+	}
+}
+
+func TestQueueCustomDuration(t *testing.T) {
+	q := NewQueue()
+	start := time.Now()
+	q.Push(Message{Text: "brief", Duration: 500 * time.Millisecond}, start)
+
+	if _, ok := q.Current(start.Add(600 * time.Millisecond)); ok {
+		t.Error("message should honour its own duration")
+	}
+}
+
+func TestQueueOrder(t *testing.T) {
+	q := NewQueue()
+	start := time.Now()
+
+	q.Push(Message{Text: "first"}, start)
+	q.Push(Message{Text: "second", Severity: SeverityError}, start.Add(100*time.Millisecond))
+
+	steps := []struct {
+		at   time.Duration
+		want string
+	}{
+		{500 * time.Millisecond, "first"}, // Still within MinDisplay
+		{time.Second, "second"},           // Cut short by the waiting message
+		{8 * time.Second, "second"},       // Errors last 8s from when shown
+		{9 * time.Second, ""},             // Expired
+		{9*time.Second + time.Millisecond, ""},
+	}
+
+	for _, step := range steps {
+		msg, ok := q.Current(start.Add(step.at))
+		if got := msg.Text; got != step.want || ok != (step.want != "") {
+			t.Errorf("at %v: Current() = (%q, %v), want %q", step.at, got, ok, step.want)
+		}
+	}
+}
+
+func TestQueueLateArrival(t *testing.T) {
+	q := NewQueue()
+	start := time.Now()
+
+	q.Push(Message{Text: "first"}, start)
+	// Arrives after MinDisplay, so it replaces the first message at once
+	q.Push(Message{Text: "second"}, start.Add(2*time.Second))
+
+	if msg, _ := q.Current(start.Add(2 * time.Second)); msg.Text != "second" {
+		t.Errorf("Current() = %q, want second", msg.Text)
+	}
+	if msg, ok := q.Current(start.Add(4900 * time.Millisecond)); !ok || msg.Text != "second" {
+		t.Errorf("second message should show for its full 3s, got (%q, %v)", msg.Text, ok)
+	}
+}
+
+func TestQueueDeduplicates(t *testing.T) {
+	q := NewQueue()
+	start := time.Now()
+
+	q.Push(Message{Text: "same"}, start)
+	q.Push(Message{Text: "same"}, start.Add(2*time.Second))
+
+	// The repeat restarted the timer instead of queueing a copy
+	if msg, ok := q.Current(start.Add(4 * time.Second)); !ok || msg.Text != "same" {
+		t.Errorf("Current() = (%q, %v), want same", msg.Text, ok)
+	}
+	if _, ok := q.Current(start.Add(5 * time.Second)); ok {
+		t.Error("deduplicated message should expire 3s after the repeat")
+	}
+}
+
+func TestQueueBounded(t *testing.T) {
+	q := NewQueue()
+	start := time.Now()
+
+	q.Push(Message{Text: "current"}, start)
+	for i := 0; i < maxPending+3; i++ {
+		q.Push(Message{Text: string(rune('a' + i))}, start)
+	}
+	if len(q.pending) != maxPending {
+		t.Errorf("pending = %d, want %d", len(q.pending), maxPending)
+	}
+	if q.pending[0].msg.Text != "d" {
+		t.Errorf("oldest waiting message = %q, want d", q.pending[0].msg.Text)
+	}
+}
+
+func TestQueueClear(t *testing.T) {
+	q := NewQueue()
+	start := time.Now()
+	q.Push(Message{Text: "one"}, start)
+	q.Push(Message{Text: "two"}, start)
+	q.Clear()
+
+	if _, ok := q.Current(start); ok {
+		t.Error("Clear should remove all messages")
+	}
+}
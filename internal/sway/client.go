@@ -0,0 +1,224 @@
+package sway
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// ipcMagic starts every i3 IPC message.
+const ipcMagic = "i3-ipc"
+
+// headerSize is the magic string followed by the payload length and
+// message type, both 32-bit in native byte order.
+const headerSize = len(ipcMagic) + 8
+
+// Message types sent to Sway.
+const (
+	msgRunCommand    uint32 = 0
+	msgGetWorkspaces uint32 = 1
+	msgSubscribe     uint32 = 2
+	msgGetTree       uint32 = 4
+)
+
+// Client provides IPC communication with Sway.
+type Client struct {
+	socket string // Path from SWAYSOCK
+}
+
+// Workspace represents a Sway workspace.
+type Workspace struct {
+	Num     int    `json:"num"` // -1 for workspaces whose name has no number
+	Name    string `json:"name"`
+	Output  string `json:"output"`
+	Focused bool   `json:"focused"`
+	Visible bool   `json:"visible"`
+}
+
+// Node is a node of Sway's layout tree: the root, an output, a
+// workspace, or a container holding a window or other containers.
+type Node struct {
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	Type          string `json:"type"` // "root", "output", "workspace", "con" or "floating_con"
+	Focused       bool   `json:"focused"`
+	PID           int    `json:"pid"`    // Owning process for windows, 0 otherwise
+	AppID         string `json:"app_id"` // Wayland app ID; empty for X11 windows
+	Nodes         []Node `json:"nodes"`
+	FloatingNodes []Node `json:"floating_nodes"`
+
+	WindowProperties struct {
+		Class string `json:"class"`
+	} `json:"window_properties"` // Set for X11 (Xwayland) windows only
+}
+
+// Class returns the window's app ID, or its X11 class under Xwayland.
+func (n *Node) Class() string {
+	if n.AppID != "" {
+		return n.AppID
+	}
+	return n.WindowProperties.Class
+}
+
+// walk calls fn for node and every node below it, passing the name of the
+// workspace each is on, until fn returns false.
+func (n *Node) walk(workspace string, fn func(node *Node, workspace string) bool) bool {
+	if n.Type == "workspace" {
+		workspace = n.Name
+	}
+	if !fn(n, workspace) {
+		return false
+	}
+	for i := range n.Nodes {
+		if !n.Nodes[i].walk(workspace, fn) {
+			return false
+		}
+	}
+	for i := range n.FloatingNodes {
+		if !n.FloatingNodes[i].walk(workspace, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSway checks if the current environment is running under Sway.
+func IsSway() bool {
+	return os.Getenv("SWAYSOCK") != ""
+}
+
+// New creates a new Sway IPC client.
+// Returns an error if not running under Sway.
+func New() (*Client, error) {
+	socket := os.Getenv("SWAYSOCK")
+	if socket == "" {
+		return nil, fmt.Errorf("not running under Sway (SWAYSOCK not set)")
+	}
+
+	if _, err := os.Stat(socket); err != nil {
+		return nil, fmt.Errorf("sway socket not found: %w", err)
+	}
+
+	return &Client{socket: socket}, nil
+}
+
+// writeMessage sends one i3 IPC message.
+func writeMessage(w io.Writer, msgType uint32, payload []byte) error {
+	buf := make([]byte, headerSize+len(payload))
+	copy(buf, ipcMagic)
+	binary.NativeEndian.PutUint32(buf[len(ipcMagic):], uint32(len(payload)))
+	binary.NativeEndian.PutUint32(buf[len(ipcMagic)+4:], msgType)
+	copy(buf[headerSize:], payload)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// readMessage reads one i3 IPC message, returning its type and payload.
+func readMessage(r io.Reader) (uint32, []byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	if string(header[:len(ipcMagic)]) != ipcMagic {
+		return 0, nil, errors.New("invalid IPC message: bad magic")
+	}
+
+	length := binary.NativeEndian.Uint32(header[len(ipcMagic):])
+	msgType := binary.NativeEndian.Uint32(header[len(ipcMagic)+4:])
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return msgType, payload, nil
+}
+
+// request sends a message to Sway and returns the reply payload.
+func (c *Client) request(msgType uint32, payload string) ([]byte, error) {
+	conn, err := net.Dial("unix", c.socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Sway socket: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := writeMessage(conn, msgType, []byte(payload)); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	_, reply, err := readMessage(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return reply, nil
+}
+
+// GetWorkspaces returns all workspaces.
+func (c *Client) GetWorkspaces() ([]Workspace, error) {
+	resp, err := c.request(msgGetWorkspaces, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var workspaces []Workspace
+	if err := json.Unmarshal(resp, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse workspaces data: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+// GetTree returns the layout tree.
+func (c *Client) GetTree() (*Node, error) {
+	resp, err := c.request(msgGetTree, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var root Node
+	if err := json.Unmarshal(resp, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse tree data: %w", err)
+	}
+
+	return &root, nil
+}
+
+// commandResult is Sway's reply for each command in a RUN_COMMAND message.
+type commandResult struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error"`
+}
+
+// RunCommand runs Sway commands, as swaymsg would (e.g. "workspace 2";
+// several may be separated by ";"). It returns the first command's error.
+func (c *Client) RunCommand(command string) error {
+	resp, err := c.request(msgRunCommand, command)
+	if err != nil {
+		return err
+	}
+
+	var results []commandResult
+	if err := json.Unmarshal(resp, &results); err != nil {
+		return fmt.Errorf("failed to parse command result: %w", err)
+	}
+	for _, r := range results {
+		if !r.Success {
+			return fmt.Errorf("sway: %s", r.Error)
+		}
+	}
+	return nil
+}
+
+// quote quotes s as a single argument in a Sway command.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// shellQuote quotes s as a single POSIX shell word, for exec.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,201 @@
+package sway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestIsSway(t *testing.T) {
+	t.Setenv("SWAYSOCK", "")
+	if IsSway() {
+		t.Error("IsSway() = true without SWAYSOCK")
+	}
+
+	t.Setenv("SWAYSOCK", "/run/user/1000/sway-ipc.1000.1234.sock")
+	if !IsSway() {
+		t.Error("IsSway() = false with SWAYSOCK set")
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("SWAYSOCK", "")
+	if _, err := New(); err == nil {
+		t.Error("New() should fail without SWAYSOCK")
+	}
+
+	t.Setenv("SWAYSOCK", filepath.Join(t.TempDir(), "missing.sock"))
+	if _, err := New(); err == nil {
+		t.Error("New() should fail when the socket does not exist")
+	}
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, msgGetTree, []byte(`{"x":1}`)); err != nil {
+		t.Fatalf("writeMessage() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), ipcMagic) {
+		t.Errorf("message does not start with %q", ipcMagic)
+	}
+
+	msgType, payload, err := readMessage(&buf)
+	if err != nil {
+		t.Fatalf("readMessage() error = %v", err)
+	}
+	if msgType != msgGetTree || string(payload) != `{"x":1}` {
+		t.Errorf("readMessage() = %d %q, want %d %q", msgType, payload, msgGetTree, `{"x":1}`)
+	}
+
+	if _, _, err := readMessage(strings.NewReader("i3-ipX\x00\x00\x00\x00\x00\x00\x00\x00")); err == nil {
+		t.Error("readMessage() should reject a bad magic string")
+	}
+}
+
+// mockServer answers i3 IPC requests with canned replies and records the
+// commands it runs. Subscribers are sent events.
+type mockServer struct {
+	mu       sync.Mutex
+	commands []string
+	events   []mockEvent
+}
+
+type mockEvent struct {
+	msgType uint32
+	payload string
+}
+
+var mockWorkspaces = []Workspace{
+	{Num: 1, Name: "1", Output: "DP-1", Visible: true},
+	{Num: 2, Name: "2", Output: "HDMI-A-1", Focused: true, Visible: true},
+	{Num: -1, Name: "code", Output: "DP-1"},
+}
+
+var mockTree = Node{Type: "root", Nodes: []Node{
+	{Type: "output", Name: "DP-1", Nodes: []Node{
+		{Type: "workspace", Name: "1", Nodes: []Node{
+			{ID: 11, Type: "con", Name: "Terminal", PID: 100, AppID: "kitty"},
+			{ID: 12, Type: "con", Name: "Warren", PID: 4242, AppID: "warren"},
+			{ID: 13, Type: "con", Name: "paper.pdf - Document Viewer", PID: 200, AppID: "org.gnome.Evince"},
+		}},
+		{Type: "workspace", Name: "code", FloatingNodes: []Node{
+			{ID: 21, Type: "floating_con", Name: "Warren", PID: 4242, AppID: "warren", Focused: true},
+		}},
+	}},
+}}
+
+func setupMockServer(t *testing.T, events []mockEvent) (*Client, *mockServer) {
+	socketPath := filepath.Join(t.TempDir(), "sway.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to create mock server: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		_ = os.Remove(socketPath)
+	})
+
+	server := &mockServer{events: events}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return // Server closed
+			}
+			go server.handle(conn)
+		}
+	}()
+
+	return &Client{socket: socketPath}, server
+}
+
+func (s *mockServer) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	msgType, payload, err := readMessage(conn)
+	if err != nil {
+		return
+	}
+
+	var reply []byte
+	switch msgType {
+	case msgGetWorkspaces:
+		reply, _ = json.Marshal(mockWorkspaces)
+	case msgGetTree:
+		reply, _ = json.Marshal(mockTree)
+	case msgRunCommand:
+		s.mu.Lock()
+		s.commands = append(s.commands, string(payload))
+		s.mu.Unlock()
+		if strings.HasPrefix(string(payload), "bogus") {
+			reply = []byte(`[{"success":false,"error":"Unknown/invalid command 'bogus'"}]`)
+		} else {
+			reply = []byte(`[{"success":true}]`)
+		}
+	case msgSubscribe:
+		_ = writeMessage(conn, msgSubscribe, []byte(`{"success":true}`))
+		for _, e := range s.events {
+			_ = writeMessage(conn, e.msgType, []byte(e.payload))
+		}
+		// Keep the subscription open until the client disconnects
+		_, _, _ = readMessage(conn)
+		return
+	}
+	_ = writeMessage(conn, msgType, reply)
+}
+
+func (s *mockServer) ran() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func TestClient_GetWorkspaces(t *testing.T) {
+	client, _ := setupMockServer(t, nil)
+
+	workspaces, err := client.GetWorkspaces()
+	if err != nil {
+		t.Fatalf("GetWorkspaces() error = %v", err)
+	}
+	if len(workspaces) != 3 || workspaces[2].Name != "code" || workspaces[2].Num != -1 {
+		t.Errorf("GetWorkspaces() = %+v", workspaces)
+	}
+}
+
+func TestClient_RunCommand(t *testing.T) {
+	client, server := setupMockServer(t, nil)
+
+	if err := client.RunCommand("workspace 2"); err != nil {
+		t.Errorf("RunCommand() error = %v", err)
+	}
+	err := client.RunCommand("bogus")
+	if err == nil || !strings.Contains(err.Error(), "Unknown/invalid command") {
+		t.Errorf("RunCommand(bogus) error = %v, want Sway's error", err)
+	}
+	if got := server.ran(); len(got) != 2 || got[0] != "workspace 2" {
+		t.Errorf("commands run = %q", got)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2", `"2"`},
+		{"my code", `"my code"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`back\slash`, `"back\\slash"`},
+	}
+
+	for _, tt := range tests {
+		if got := quote(tt.input); got != tt.expected {
+			t.Errorf("quote(%q) = %s, want %s", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package sway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// This file adapts Client to the compositor.Compositor interface.
+
+// Reconnect delays for WorkspaceChanges, doubling from the first to the
+// last while Sway's socket is unavailable (e.g. during a restart).
+const (
+	reconnectDelay    = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Name returns "Sway".
+func (c *Client) Name() string {
+	return "Sway"
+}
+
+// ActiveWorkspace returns the name of the focused workspace.
+func (c *Client) ActiveWorkspace() (string, error) {
+	workspaces, err := c.GetWorkspaces()
+	if err != nil {
+		return "", err
+	}
+
+	for _, ws := range workspaces {
+		if ws.Focused {
+			return ws.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no focused workspace")
+}
+
+// WindowWorkspace returns the workspace of a window owned by pid,
+// preferring the focused one if there are several.
+func (c *Client) WindowWorkspace(pid int) (string, error) {
+	root, err := c.GetTree()
+	if err != nil {
+		return "", err
+	}
+
+	var found string
+	root.walk("", func(node *Node, workspace string) bool {
+		if node.PID != pid {
+			return true
+		}
+		if node.Focused {
+			found = workspace
+			return false
+		}
+		if found == "" {
+			found = workspace
+		}
+		return true
+	})
+
+	if found == "" {
+		return "", fmt.Errorf("no window with pid %d", pid)
+	}
+	return found, nil
+}
+
+// MonitorOf returns the name of the output showing workspace.
+func (c *Client) MonitorOf(workspace string) (string, error) {
+	workspaces, err := c.GetWorkspaces()
+	if err != nil {
+		return "", err
+	}
+
+	for _, ws := range workspaces {
+		if ws.Name == workspace {
+			return ws.Output, nil
+		}
+	}
+	return "", fmt.Errorf("workspace %q not found", workspace)
+}
+
+// FocusWorkspace switches to workspace, creating it if needed.
+func (c *Client) FocusWorkspace(workspace string) error {
+	return c.RunCommand("workspace " + quote(workspace))
+}
+
+// MoveToWorkspace moves the windows owned by pid to workspace and
+// follows them there.
+func (c *Client) MoveToWorkspace(workspace string, pid int) error {
+	return c.RunCommand(fmt.Sprintf("[pid=%d] move container to workspace %s; workspace %s", pid, quote(workspace), quote(workspace)))
+}
+
+// ExecOn switches to workspace and launches argv there. Sway has no
+// launch-time window rules, so windows that appear after the user moves
+// on open wherever the user is.
+func (c *Client) ExecOn(workspace string, argv ...string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("exec: empty command")
+	}
+
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	return c.RunCommand("workspace " + quote(workspace) + "; exec " + strings.Join(quoted, " "))
+}
+
+// FocusWindow focuses a window, other than Warren's own, for which
+// match(title, class) is true, preferring the focused one.
+func (c *Client) FocusWindow(match func(title, class string) bool) (bool, error) {
+	root, err := c.GetTree()
+	if err != nil {
+		return false, err
+	}
+
+	pid := os.Getpid()
+	var found *Node
+	root.walk("", func(node *Node, _ string) bool {
+		if node.PID == 0 || node.PID == pid || !match(node.Name, node.Class()) {
+			return true
+		}
+		if found == nil || node.Focused {
+			found = node
+		}
+		return !node.Focused
+	})
+	if found == nil {
+		return false, nil
+	}
+	return true, c.RunCommand(fmt.Sprintf("[con_id=%d] focus", found.ID))
+}
+
+// FocusNextWindow focuses the next window to open (a window "new" event).
+func (c *Client) FocusNextWindow(ctx context.Context) (<-chan error, error) {
+	conn, err := c.subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		var focusErr error
+		focused := false
+		readEvents(ctx, conn, func(msgType uint32, payload []byte) bool {
+			var e WindowEvent
+			if msgType != eventWindow || json.Unmarshal(payload, &e) != nil || e.Change != "new" {
+				return true
+			}
+			focusErr = c.RunCommand(fmt.Sprintf("[con_id=%d] focus", e.Container.ID))
+			focused = true
+			return false
+		})
+
+		switch {
+		case focused:
+			result <- focusErr
+		case ctx.Err() != nil:
+			result <- ctx.Err()
+		default:
+			result <- fmt.Errorf("sway event socket closed")
+		}
+	}()
+	return result, nil
+}
+
+// WorkspaceChanges delivers the workspace Warren is now on after
+// workspace switches and after Warren's own window moves. It reconnects
+// with backoff if Sway's socket goes away; the channel is closed when
+// ctx is cancelled.
+func (c *Client) WorkspaceChanges(ctx context.Context) (<-chan string, error) {
+	conn, err := c.subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(chan string)
+	pid := os.Getpid()
+	handle := func(msgType uint32, payload []byte) bool {
+		var workspace string
+		switch msgType {
+		case eventWorkspace:
+			var e WorkspaceEvent
+			if err := json.Unmarshal(payload, &e); err != nil || e.Change != "focus" {
+				return true
+			}
+			workspace = e.Current.Name
+		case eventWindow:
+			var e WindowEvent
+			if err := json.Unmarshal(payload, &e); err != nil || e.Change != "move" || e.Container.PID != pid {
+				return true
+			}
+			ws, err := c.WindowWorkspace(pid)
+			if err != nil {
+				return true
+			}
+			workspace = ws
+		default:
+			return true
+		}
+
+		select {
+		case changes <- workspace:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(changes)
+
+		delay := reconnectDelay
+		for {
+			if conn != nil {
+				readEvents(ctx, conn, handle)
+				if ctx.Err() != nil {
+					return
+				}
+				slog.Info("Sway event socket closed; reconnecting")
+				delay = reconnectDelay
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxReconnectDelay)
+
+			conn, err = c.subscribe(ctx)
+			if err != nil {
+				conn = nil
+			}
+		}
+	}()
+
+	return changes, nil
+}
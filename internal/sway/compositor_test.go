@@ -0,0 +1,203 @@
+package sway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_ActiveWorkspace(t *testing.T) {
+	client, _ := setupMockServer(t, nil)
+
+	got, err := client.ActiveWorkspace()
+	if err != nil {
+		t.Fatalf("ActiveWorkspace() error = %v", err)
+	}
+	if got != "2" {
+		t.Errorf("ActiveWorkspace() = %q, want %q", got, "2")
+	}
+}
+
+func TestClient_WindowWorkspace(t *testing.T) {
+	client, _ := setupMockServer(t, nil)
+
+	tests := []struct {
+		pid     int
+		want    string
+		wantErr bool
+	}{
+		{100, "1", false},
+		{4242, "code", false}, // The focused (floating) window wins
+		{999, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := client.WindowWorkspace(tt.pid)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("WindowWorkspace(%d) error = %v, wantErr %v", tt.pid, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("WindowWorkspace(%d) = %q, want %q", tt.pid, got, tt.want)
+		}
+	}
+}
+
+func TestClient_MonitorOf(t *testing.T) {
+	client, _ := setupMockServer(t, nil)
+
+	got, err := client.MonitorOf("2")
+	if err != nil {
+		t.Fatalf("MonitorOf() error = %v", err)
+	}
+	if got != "HDMI-A-1" {
+		t.Errorf("MonitorOf(2) = %q, want %q", got, "HDMI-A-1")
+	}
+	if _, err := client.MonitorOf("missing"); err == nil {
+		t.Error("MonitorOf() of an unknown workspace should error")
+	}
+}
+
+func TestClient_Commands(t *testing.T) {
+	client, server := setupMockServer(t, nil)
+
+	if err := client.FocusWorkspace("my code"); err != nil {
+		t.Errorf("FocusWorkspace() error = %v", err)
+	}
+	if err := client.MoveToWorkspace("3", 4242); err != nil {
+		t.Errorf("MoveToWorkspace() error = %v", err)
+	}
+	if err := client.ExecOn("media", "xdg-open", "/tmp/it's.mkv"); err != nil {
+		t.Errorf("ExecOn() error = %v", err)
+	}
+	if err := client.ExecOn("media"); err == nil {
+		t.Error("ExecOn() with no command should error")
+	}
+
+	want := []string{
+		`workspace "my code"`,
+		`[pid=4242] move container to workspace "3"; workspace "3"`,
+		`workspace "media"; exec 'xdg-open' '/tmp/it'\''s.mkv'`,
+	}
+	got := server.ran()
+	if len(got) != len(want) {
+		t.Fatalf("commands run = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("command %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestClient_WorkspaceChanges(t *testing.T) {
+	workspaceEvent := func(change, name string) mockEvent {
+		payload, _ := json.Marshal(WorkspaceEvent{Change: change, Current: Workspace{Name: name}})
+		return mockEvent{eventWorkspace, string(payload)}
+	}
+	windowEvent := func(change string, pid int) mockEvent {
+		return mockEvent{eventWindow, fmt.Sprintf(`{"change":%q,"container":{"pid":%d}}`, change, pid)}
+	}
+
+	// Warren's own windows are those of the test process; point the tree
+	// at it so a move of "our" window resolves to a workspace
+	warren := &mockTree.Nodes[0].Nodes[1].FloatingNodes[0]
+	warren.PID = os.Getpid()
+	defer func() { warren.PID = 4242 }()
+
+	client, _ := setupMockServer(t, []mockEvent{
+		workspaceEvent("init", "4"), // Not a switch
+		workspaceEvent("focus", "2"),
+		windowEvent("move", 100), // Another application's window
+		windowEvent("focus", os.Getpid()),
+		windowEvent("move", os.Getpid()),
+		workspaceEvent("focus", "code"),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := client.WorkspaceChanges(ctx)
+	if err != nil {
+		t.Fatalf("WorkspaceChanges() error = %v", err)
+	}
+
+	want := []string{"2", "code", "code"}
+	for i, w := range want {
+		select {
+		case got := <-changes:
+			if got != w {
+				t.Errorf("change %d = %q, want %q", i, got, w)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for change %d", i)
+		}
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestClient_WorkspaceChangesNoSocket(t *testing.T) {
+	client := &Client{socket: "/nonexistent/sway.sock"}
+	if _, err := client.WorkspaceChanges(context.Background()); err == nil {
+		t.Error("WorkspaceChanges() should fail when the socket does not exist")
+	}
+}
+
+func TestNode_Class(t *testing.T) {
+	wayland := Node{AppID: "org.gnome.Evince"}
+	x11 := Node{}
+	x11.WindowProperties.Class = "Gimp"
+
+	if got := wayland.Class(); got != "org.gnome.Evince" {
+		t.Errorf("Class() = %q, want the app ID", got)
+	}
+	if got := x11.Class(); got != "Gimp" {
+		t.Errorf("Class() = %q, want the X11 class", got)
+	}
+}
+
+func TestClient_FocusWindow(t *testing.T) {
+	client, server := setupMockServer(t, nil)
+
+	found, err := client.FocusWindow(func(title, class string) bool {
+		return strings.HasPrefix(title, "paper.pdf") && class == "org.gnome.Evince"
+	})
+	if err != nil || !found {
+		t.Fatalf("FocusWindow(paper.pdf) = %v, %v, want true", found, err)
+	}
+	found, err = client.FocusWindow(func(title, _ string) bool { return title == "missing.txt" })
+	if err != nil || found {
+		t.Errorf("FocusWindow(missing.txt) = %v, %v, want false", found, err)
+	}
+
+	if got := server.ran(); len(got) != 1 || got[0] != "[con_id=13] focus" {
+		t.Errorf("commands run = %q, want [[con_id=13] focus]", got)
+	}
+}
+
+func TestClient_FocusNextWindow(t *testing.T) {
+	client, server := setupMockServer(t, []mockEvent{
+		{eventWindow, `{"change":"focus","container":{"id":11}}`},
+		{eventWindow, `{"change":"new","container":{"id":42}}`},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result, err := client.FocusNextWindow(ctx)
+	if err != nil {
+		t.Fatalf("FocusNextWindow() error = %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("FocusNextWindow() result = %v, want nil", err)
+	}
+	if got := server.ran(); len(got) != 1 || got[0] != "[con_id=42] focus" {
+		t.Errorf("commands run = %q, want [[con_id=42] focus]", got)
+	}
+}
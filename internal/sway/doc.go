@@ -0,0 +1,21 @@
+// Package sway provides IPC communication with the Sway window manager.
+//
+// It speaks the i3 IPC protocol over the socket named by SWAYSOCK,
+// allowing Warren to:
+//   - Query workspaces, outputs and the window tree
+//   - Run Sway commands (switch workspace, move windows, exec)
+//   - Subscribe to workspace and window events
+//
+// Client implements compositor.Compositor, giving Sway users the same
+// workspace memory and auto-switching as Hyprland.
+//
+// Basic usage:
+//
+//	client, err := sway.New()
+//	if err != nil {
+//	    // Not running in Sway or IPC unavailable
+//	    return
+//	}
+//
+//	workspaces, err := client.GetWorkspaces()
+package sway
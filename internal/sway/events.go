@@ -0,0 +1,73 @@
+package sway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Event types Sway sends to subscribers; the high bit marks an event.
+const (
+	eventWorkspace uint32 = 0x80000000
+	eventWindow    uint32 = 0x80000003
+)
+
+// WorkspaceEvent is sent when workspaces change. Change is "focus" when
+// the user switches to Current.
+type WorkspaceEvent struct {
+	Change  string    `json:"change"`
+	Current Workspace `json:"current"`
+}
+
+// WindowEvent is sent when a window changes. Change is "move" when it
+// moved to another workspace.
+type WindowEvent struct {
+	Change    string `json:"change"`
+	Container Node   `json:"container"`
+}
+
+// subscribe opens a connection subscribed to workspace and window events.
+func (c *Client) subscribe(ctx context.Context) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Sway socket: %w", err)
+	}
+
+	if err := writeMessage(conn, msgSubscribe, []byte(`["workspace","window"]`)); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	_, reply, err := readMessage(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to read subscribe reply: %w", err)
+	}
+	var result commandResult
+	if err := json.Unmarshal(reply, &result); err != nil || !result.Success {
+		_ = conn.Close()
+		return nil, fmt.Errorf("sway refused the event subscription")
+	}
+	return conn, nil
+}
+
+// readEvents calls handle with each workspace or window event read from
+// conn until the connection ends or ctx is cancelled, then closes conn.
+func readEvents(ctx context.Context, conn net.Conn, handle func(msgType uint32, payload []byte) bool) {
+	// Unblock the read when the subscription is cancelled
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer func() { _ = conn.Close() }()
+
+	for {
+		msgType, payload, err := readMessage(conn)
+		if err != nil {
+			return
+		}
+		if !handle(msgType, payload) {
+			return
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// Package theme turns Warren's appearance settings into CSS and decides
+// between the dark and light style variants.
+//
+// The accent colour and row density from the config become a small
+// stylesheet loaded after Warren's built-in CSS; the user's own
+// style.css is loaded after that, so it always wins. The colour scheme is
+// "system" (follow the FreeDesktop settings portal), "light" or "dark".
+// The package has no GTK dependency; cmd/warren installs the CSS and
+// applies the variant.
+package theme
@@ -0,0 +1,153 @@
+package theme
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UserStyleFile is the name of the user stylesheet in the config directory.
+const UserStyleFile = "style.css"
+
+// Scheme selects the dark or light style variant.
+type Scheme int
+
+const (
+	// SchemeSystem follows the desktop's preference
+	SchemeSystem Scheme = iota
+	// SchemeLight always uses the light variant
+	SchemeLight
+	// SchemeDark always uses the dark variant
+	SchemeDark
+)
+
+// ParseScheme converts a config value ("system", "light", "dark") to a
+// Scheme. An empty value means system.
+func ParseScheme(s string) (Scheme, error) {
+	switch strings.ToLower(s) {
+	case "", "system":
+		return SchemeSystem, nil
+	case "light":
+		return SchemeLight, nil
+	case "dark":
+		return SchemeDark, nil
+	default:
+		return SchemeSystem, fmt.Errorf("unknown color scheme %q (use system, light or dark)", s)
+	}
+}
+
+// Portal color-scheme values from org.freedesktop.appearance.
+const (
+	PortalNoPreference uint32 = 0
+	PortalPreferDark   uint32 = 1
+	PortalPreferLight  uint32 = 2
+)
+
+// PrefersDark reports whether to use the dark variant. portal is the
+// desktop's org.freedesktop.appearance color-scheme value, used only for
+// SchemeSystem.
+func (s Scheme) PrefersDark(portal uint32) bool {
+	switch s {
+	case SchemeDark:
+		return true
+	case SchemeLight:
+		return false
+	default:
+		return portal == PortalPreferDark
+	}
+}
+
+// Density sets how much vertical padding file list rows get.
+type Density int
+
+const (
+	// DensityNormal keeps the theme's row padding
+	DensityNormal Density = iota
+	// DensityCompact fits more rows on screen
+	DensityCompact
+	// DensityComfortable spaces rows out
+	DensityComfortable
+)
+
+// ParseDensity converts a config value ("compact", "normal",
+// "comfortable") to a Density. An empty value means normal.
+func ParseDensity(s string) (Density, error) {
+	switch strings.ToLower(s) {
+	case "", "normal":
+		return DensityNormal, nil
+	case "compact":
+		return DensityCompact, nil
+	case "comfortable":
+		return DensityComfortable, nil
+	default:
+		return DensityNormal, fmt.Errorf("unknown density %q (use compact, normal or comfortable)", s)
+	}
+}
+
+// rowPadding returns the vertical padding per row edge in pixels, or -1 to
+// leave the theme's padding alone.
+func (d Density) rowPadding() int {
+	switch d {
+	case DensityCompact:
+		return 1
+	case DensityComfortable:
+		return 8
+	default:
+		return -1
+	}
+}
+
+// Ellipsize sets where file names too long for the Name column are cut.
+type Ellipsize int
+
+const (
+	// EllipsizeMiddle keeps the start and the end, with the extension
+	EllipsizeMiddle Ellipsize = iota
+	// EllipsizeEnd keeps the start
+	EllipsizeEnd
+)
+
+// ParseEllipsize converts a config value ("middle", "end") to an
+// Ellipsize. An empty value means middle.
+func ParseEllipsize(s string) (Ellipsize, error) {
+	switch strings.ToLower(s) {
+	case "", "middle":
+		return EllipsizeMiddle, nil
+	case "end":
+		return EllipsizeEnd, nil
+	default:
+		return EllipsizeMiddle, fmt.Errorf("unknown ellipsize mode %q (use middle or end)", s)
+	}
+}
+
+// colorPattern matches hex colours (#rgb, #rrggbb, #rrggbbaa) and named
+// colours such as "teal" or GTK's "@accent_bg_color".
+var colorPattern = regexp.MustCompile(`^(#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})|@?[a-zA-Z_]+)$`)
+
+// ValidateColor checks an accent colour from the config. Empty is valid
+// and means the theme's own accent.
+func ValidateColor(s string) error {
+	if s == "" || colorPattern.MatchString(s) {
+		return nil
+	}
+	return fmt.Errorf("invalid color %q (use #rrggbb or a color name)", s)
+}
+
+// CSS returns the stylesheet for an accent colour and row density. Invalid
+// values are skipped so a config typo cannot break the rest of the CSS.
+func CSS(accent string, density Density) string {
+	var b strings.Builder
+
+	if accent != "" && ValidateColor(accent) == nil {
+		fmt.Fprintf(&b, "@define-color accent_color %s;\n", accent)
+		fmt.Fprintf(&b, "@define-color accent_bg_color %s;\n", accent)
+		fmt.Fprintf(&b, "@define-color theme_selected_bg_color %s;\n", accent)
+		fmt.Fprintf(&b, "columnview row:selected { background-color: %s; }\n", accent)
+	}
+
+	if padding := density.rowPadding(); padding >= 0 {
+		fmt.Fprintf(&b, "columnview row cell { padding-top: %dpx; padding-bottom: %dpx; }\n", padding, padding)
+	}
+
+	return b.String()
+}
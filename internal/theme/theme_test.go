@@ -0,0 +1,135 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseScheme(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Scheme
+		wantErr bool
+	}{
+		{"", SchemeSystem, false},
+		{"system", SchemeSystem, false},
+		{"Light", SchemeLight, false},
+		{"dark", SchemeDark, false},
+		{"dusk", SchemeSystem, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScheme(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseScheme(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPrefersDark(t *testing.T) {
+	tests := []struct {
+		scheme Scheme
+		portal uint32
+		want   bool
+	}{
+		{SchemeSystem, PortalPreferDark, true},
+		{SchemeSystem, PortalPreferLight, false},
+		{SchemeSystem, PortalNoPreference, false},
+		{SchemeDark, PortalPreferLight, true},
+		{SchemeLight, PortalPreferDark, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.scheme.PrefersDark(tt.portal); got != tt.want {
+			t.Errorf("Scheme(%d).PrefersDark(%d) = %v, want %v", tt.scheme, tt.portal, got, tt.want)
+		}
+	}
+}
+
+func TestParseDensity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Density
+		wantErr bool
+	}{
+		{"", DensityNormal, false},
+		{"compact", DensityCompact, false},
+		{"Comfortable", DensityComfortable, false},
+		{"tiny", DensityNormal, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDensity(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDensity(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateColor(t *testing.T) {
+	valid := []string{"", "#fff", "#3584e4", "#3584e4cc", "teal", "@accent_bg_color"}
+	invalid := []string{"#12", "#ggg", "rgb(1,2,3)", "red; } * { color: red", "#3584e4 !important"}
+
+	for _, c := range valid {
+		if err := ValidateColor(c); err != nil {
+			t.Errorf("ValidateColor(%q) = %v, want nil", c, err)
+		}
+	}
+	for _, c := range invalid {
+		if err := ValidateColor(c); err == nil {
+			t.Errorf("ValidateColor(%q) = nil, want error", c)
+		}
+	}
+}
+
+func TestCSS(t *testing.T) {
+	tests := []struct {
+		name    string
+		accent  string
+		density Density
+		want    []string
+		notWant []string
+	}{
+		{"defaults", "", DensityNormal, nil, []string{"accent", "padding"}},
+		{"accent", "#3584e4", DensityNormal, []string{"@define-color accent_bg_color #3584e4;", "row:selected"}, []string{"padding"}},
+		{"invalid accent ignored", "red; }", DensityNormal, nil, []string{"red"}},
+		{"compact", "", DensityCompact, []string{"padding-top: 1px"}, nil},
+		{"comfortable", "", DensityComfortable, []string{"padding-bottom: 8px"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			css := CSS(tt.accent, tt.density)
+			for _, s := range tt.want {
+				if !strings.Contains(css, s) {
+					t.Errorf("CSS() = %q, want it to contain %q", css, s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(css, s) {
+					t.Errorf("CSS() = %q, should not contain %q", css, s)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEllipsize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Ellipsize
+		wantErr bool
+	}{
+		{"", EllipsizeMiddle, false},
+		{"middle", EllipsizeMiddle, false},
+		{"End", EllipsizeEnd, false},
+		{"start", EllipsizeMiddle, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEllipsize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseEllipsize(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
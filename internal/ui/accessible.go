@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/status"
+)
+
+// SetAccessibleLabel names widget for screen readers. Use it where the
+// visible text is an icon, an emoji or shorthand such as "Sort: Name ↑".
+func SetAccessibleLabel(widget gtk.Widgetter, label string) {
+	gtk.BaseWidget(widget).UpdateProperty(
+		[]gtk.AccessibleProperty{gtk.AccessiblePropertyLabel},
+		[]glib.Value{*glib.NewValue(label)})
+}
+
+// SetAccessibleDescription adds a longer explanation of widget for screen
+// readers, such as the question a dialog asks.
+func SetAccessibleDescription(widget gtk.Widgetter, description string) {
+	gtk.BaseWidget(widget).UpdateProperty(
+		[]gtk.AccessibleProperty{gtk.AccessiblePropertyDescription},
+		[]glib.Value{*glib.NewValue(description)})
+}
+
+// announce asks screen readers to read text out, through widget's
+// accessible. Errors interrupt what is being read; routine messages wait.
+func announce(widget gtk.Widgetter, text string, severity status.Severity) {
+	priority := gtk.AccessibleAnnouncementPriorityLow
+	switch severity {
+	case status.SeverityWarning:
+		priority = gtk.AccessibleAnnouncementPriorityMedium
+	case status.SeverityError:
+		priority = gtk.AccessibleAnnouncementPriorityHigh
+	}
+	gtk.BaseWidget(widget).Announce(text, priority)
+}
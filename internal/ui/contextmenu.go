@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+// ConnectContextMenu registers a callback invoked when the view is
+// secondary-clicked, with the click position in the coordinates of
+// Widget. A click on an entry selects it first. Callbacks run in the
+// order they were added.
+func (fv *FileView) ConnectContextMenu(callback func(x, y float64)) {
+	if fv.onContextMenu == nil {
+		click := gtk.NewGestureClick()
+		click.SetButton(gdk.BUTTON_SECONDARY)
+		click.ConnectPressed(func(_ int, x, y float64) {
+			for _, callback := range fv.onContextMenu {
+				callback(x, y)
+			}
+		})
+		fv.overlay.AddController(click)
+	}
+	fv.onContextMenu = append(fv.onContextMenu, callback)
+}
+
+// selectOnSecondaryClick makes a secondary click on a cell's child select
+// the cell's row, before ConnectContextMenu callbacks run.
+func (fv *FileView) selectOnSecondaryClick(child gtk.Widgetter, cell *gtk.ColumnViewCell) {
+	click := gtk.NewGestureClick()
+	click.SetButton(gdk.BUTTON_SECONDARY)
+	click.ConnectPressed(func(int, float64, float64) {
+		if pos := int(cell.Position()); pos < len(fv.files) {
+			fv.SelectIndex(pos)
+		}
+	})
+	gtk.BaseWidget(child).AddController(click)
+}
+
+// PopupMenu shows menu in the middle of the view, for a menu opened
+// from the keyboard. Its items activate actions by name in the window's
+// action groups.
+func (fv *FileView) PopupMenu(menu gio.MenuModeller) {
+	fv.PopupMenuAt(menu, float64(fv.overlay.Width())/2, float64(fv.overlay.Height())/2)
+}
+
+// PopupMenuAt shows menu pointing at x, y in the coordinates of Widget.
+func (fv *FileView) PopupMenuAt(menu gio.MenuModeller, x, y float64) {
+	if fv.menu == nil {
+		// One popover, reused: unparenting it when it closes can drop
+		// the activation of the item that closed it
+		fv.menu = gtk.NewPopoverMenuFromModel(menu)
+		fv.menu.SetHasArrow(false)
+		fv.menu.SetHAlign(gtk.AlignStart)
+		fv.menu.SetParent(fv.overlay)
+	} else {
+		fv.menu.SetMenuModel(menu)
+	}
+
+	rect := gdk.NewRectangle(int(x), int(y), 1, 1)
+	fv.menu.SetPointingTo(&rect)
+	fv.menu.Popup()
+}
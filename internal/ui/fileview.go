@@ -1,31 +1,149 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
+	"math"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/diamondburned/gotk4/pkg/gio/v2"
 	"github.com/diamondburned/gotk4/pkg/glib/v2"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/lawrab/warren/internal/config"
 	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/status"
+	"github.com/lawrab/warren/internal/theme"
 	"github.com/lawrab/warren/pkg/models"
 )
 
+// Icons for the yank indicator column.
+const (
+	yankIcon = "object-select-symbolic"
+	cutIcon  = "edit-cut-symbolic"
+)
+
+// iconColumnPadding is how much wider than its icons the icon column is.
+const iconColumnPadding = 12
+
+// CSS classes added to every cell of a file row so row state can be styled.
+// Selection uses GTK's own row:selected state rather than a class.
+const (
+	ClassYanked        = "file-yanked"
+	ClassMarked        = "file-marked"
+	ClassCut           = "file-cut"
+	ClassHidden        = "file-hidden"
+	ClassDirectory     = "file-directory"
+	ClassSymlink       = "file-symlink"
+	ClassBrokenSymlink = "file-broken-symlink"
+	ClassExecutable    = "file-executable"
+	ClassSetuid        = "file-setuid"
+	ClassUnreadable    = "file-unreadable"
+
+	// Pane comparison results, see SetCompareMarks
+	ClassCompareUnique  = "file-compare-unique"
+	ClassCompareDiffers = "file-compare-differs"
+	ClassCompareNewer   = "file-compare-newer"
+)
+
+// ClassSymlinkTarget marks the label after a link's name saying where it
+// points. It carries the row's classes too, so a broken link's target can
+// be styled apart from a working one's.
+const ClassSymlinkTarget = "symlink-target"
+
+// rowStateClasses lists every class rowClasses can return, so stale ones
+// can be removed when a recycled cell is rebound to a different file.
+var rowStateClasses = []string{
+	ClassYanked, ClassCut, ClassMarked, ClassHidden, ClassDirectory,
+	ClassSymlink, ClassBrokenSymlink, ClassExecutable,
+	ClassSetuid, ClassUnreadable,
+	ClassCompareUnique, ClassCompareDiffers, ClassCompareNewer,
+}
+
+// compareClasses maps comparison marks to row classes.
+var compareClasses = map[fileops.CompareMark]string{
+	fileops.MarkUnique:  ClassCompareUnique,
+	fileops.MarkDiffers: ClassCompareDiffers,
+	fileops.MarkNewer:   ClassCompareNewer,
+}
+
+// cssClassed is implemented by every widget used as a cell child.
+type cssClassed interface {
+	AddCSSClass(class string)
+	RemoveCSSClass(class string)
+}
+
 // FileView represents the main file listing widget.
 type FileView struct {
+	overlay       *gtk.Overlay // widget plus the loading indicator
 	widget        *gtk.ScrolledWindow
 	listView      *gtk.ColumnView
-	store         *gio.ListStore
+	store         *gio.ListStore     // Top-level entries, as paths
+	tree          *gtk.TreeListModel // store plus the children of expanded rows
 	currentPath   string
 	selectedIndex int
 	files         []models.FileInfo
 	showHidden    bool
+	hiddenCount   int                // Hidden entries left out of the listing
+	filter        fileops.NameFilter // Persists across directories until cleared
 	sortMode      models.SortBy
 	sortOrder     models.SortOrder
+	hiddenLast    bool // Group hidden entries after the others
 	watcher       *fileops.FileWatcher
 	yankedFiles   []string // Paths of yanked files for copy/paste
+	yankCut       bool     // Yanked files are moved rather than copied on paste
+	sizeFormat    fileops.SizeFormat
+	iconSize      int                   // Pixel size of the icon column's icons
+	iconColumn    *gtk.ColumnViewColumn // Sized to fit iconSize
+	ellipsize     pango.EllipsizeMode   // Where names too long to fit are cut
+	timeFormat    fileops.TimeFormat
+	reloadHolds   int  // Open dialogs that watcher reloads must wait for
+	reloadPending bool // A watcher reload arrived while held or busy
+	treeMode      bool // Directories expand in place instead of being entered
+	replacingRows bool // The store is being refilled; see syncExpandedRows
+
+	// unsubscribeOps stops following the operation queue; see
+	// applyPendingReload
+	unsubscribeOps func()
+
+	// compareMarks highlights entries after a pane comparison, by path;
+	// cleared when another directory is loaded
+	compareMarks map[string]fileops.CompareMark
+
+	// marked is the set of marked entries, by path; see ToggleMark
+	marked map[string]bool
+
+	// entries looks up listed files by path, including the children of
+	// expanded directories
+	entries map[string]models.FileInfo
+
+	// Background loads; see LoadDirectoryAsync
+	loadGeneration uint64             // Bumped by every load to discard stale results
+	loadCancel     context.CancelFunc // Cancels the running background load
+	loadTimer      glib.SourceHandle  // Shows the loading indicator
+	loading        *gtk.Box
+	loadingSpinner *gtk.Spinner
+	loadingLabel   *gtk.Label
+	dirError       *gtk.Box // Shown instead of a listing that can't be read
+	dirErrorLabel  *gtk.Label
+
+	// Directory item counts, read in the background; see countItems
+	itemCounts  *fileops.ItemCounts // nil when counts are off
+	countCancel context.CancelFunc  // Cancels counting for the listing
+	statCancel  context.CancelFunc  // Cancels statFiles for the listing
+	statSort    bool                // Refresh once statFiles is done
+
+	onDirectoryChanged []func(path string)
+	onSelectionChanged []func(file *models.FileInfo)
+	onMarksChanged     []func(marked []models.FileInfo)
+	onListingChanged   []func()
+	notifiedSelection  string // Last path passed to onSelectionChanged
+	onContextMenu      []func(x, y float64)
+	menu               *gtk.PopoverMenu // Context menu; see PopupMenuAt
 }
 
 // NewFileView creates a new file listing widget.
@@ -36,6 +154,9 @@ func NewFileView() *FileView {
 		files:         make([]models.FileInfo, 0),
 		sortMode:      models.SortByName,
 		sortOrder:     models.SortAscending,
+		itemCounts:    fileops.NewItemCounts(),
+		iconSize:      config.DefaultIconSize,
+		ellipsize:     pango.EllipsizeMiddle,
 	}
 
 	// Create file watcher with onChange callback
@@ -43,25 +164,34 @@ func NewFileView() *FileView {
 	watcher, err := fileops.NewFileWatcher(func() {
 		// This runs in a goroutine, so use IdleAdd for GTK thread safety
 		glib.IdleAdd(func() {
-			if fv.currentPath != "" {
-				// Reload the current directory
-				if err := fv.LoadDirectory(fv.currentPath); err != nil {
-					log.Printf("Failed to reload directory after file change: %v", err)
-				}
+			if err := fv.reloadChanged(); err != nil {
+				slog.Warn("Failed to reload directory after file change", "err", err)
 			}
 		})
 	})
 	if err != nil {
-		log.Printf("Warning: Failed to create file watcher: %v", err)
+		slog.Warn("Failed to create file watcher", "err", err)
 		// Continue without watcher - not critical
 	}
 	fv.watcher = watcher
 
-	// Create list store to hold file data
+	// Reloads wait while an operation is changing the directory, and the
+	// one that was due is applied when it ends
+	fv.unsubscribeOps = fileops.DefaultQueue().Subscribe(func(ev fileops.Event) {
+		if ev.Type == fileops.EventFinished {
+			glib.IdleAdd(fv.applyPendingReload)
+		}
+	})
+
+	// Create list store to hold file data, flattened with the children of
+	// expanded directories in tree mode
 	fv.store = gio.NewListStore(glib.TypeObject)
+	fv.tree = gtk.NewTreeListModel(fv.store, false, false, fv.childModel)
+	// Connected before the selection model so fv.files is updated first
+	fv.tree.ConnectItemsChanged(fv.syncExpandedRows)
 
 	// Create selection model
-	selection := gtk.NewSingleSelection(fv.store)
+	selection := gtk.NewSingleSelection(fv.tree)
 	selection.SetAutoselect(false)
 	selection.SetCanUnselect(true)
 
@@ -83,28 +213,42 @@ func NewFileView() *FileView {
 	// Don't propagate natural height - allow scrolling instead of expanding infinitely
 	fv.widget.SetPropagateNaturalHeight(false)
 
+	fv.loading, fv.loadingSpinner, fv.loadingLabel = newLoadingIndicator()
+	fv.overlay = gtk.NewOverlay()
+	fv.overlay.SetChild(fv.widget)
+	fv.overlay.AddOverlay(fv.loading)
+	fv.dirError, fv.dirErrorLabel = newDirectoryError(fv.retryDirectory)
+	fv.overlay.AddOverlay(fv.dirError)
+
 	return fv
 }
 
-// addColumns adds the columns to the column view (yank indicator, name, size, modified).
+// addColumns adds the columns to the column view (yank indicator, icon, name, size, modified).
 func (fv *FileView) addColumns() {
 	// Yank indicator column (icon showing if file is yanked)
 	yankFactory := gtk.NewSignalListItemFactory()
 	yankFactory.ConnectSetup(func(obj *glib.Object) {
 		cell := obj.Cast().(*gtk.ColumnViewCell)
-		image := gtk.NewImageFromIconName("object-select-symbolic")
+		image := gtk.NewImageFromIconName(yankIcon)
 		image.SetIconSize(gtk.IconSizeNormal)
 		cell.SetChild(image)
+		fv.selectOnSecondaryClick(image, cell)
 	})
 	yankFactory.ConnectBind(func(obj *glib.Object) {
 		cell := obj.Cast().(*gtk.ColumnViewCell)
 		image := cell.Child().(*gtk.Image)
 
-		pos := cell.Position()
-		if pos < uint(len(fv.files)) {
-			file := fv.files[pos]
+		if file, _, ok := fv.itemFile(cell.Item()); ok {
+			fv.applyRowClasses(image, file)
 			// Show icon if file is yanked, hide otherwise
 			if fv.IsYanked(file.Path) {
+				if fv.yankCut {
+					image.SetFromIconName(cutIcon)
+					SetAccessibleLabel(image, "Cut")
+				} else {
+					image.SetFromIconName(yankIcon)
+					SetAccessibleLabel(image, "Yanked")
+				}
 				image.SetVisible(true)
 				image.SetOpacity(1.0)
 			} else {
@@ -117,29 +261,73 @@ func (fv *FileView) addColumns() {
 	yankColumn.SetFixedWidth(40)
 	fv.listView.AppendColumn(yankColumn)
 
+	// Icon column, from the icon theme
+	iconFactory := gtk.NewSignalListItemFactory()
+	iconFactory.ConnectSetup(func(obj *glib.Object) {
+		cell := obj.Cast().(*gtk.ColumnViewCell)
+		image := gtk.NewImage()
+		cell.SetChild(image)
+		fv.selectOnSecondaryClick(image, cell)
+	})
+	iconFactory.ConnectBind(func(obj *glib.Object) {
+		cell := obj.Cast().(*gtk.ColumnViewCell)
+		image := cell.Child().(*gtk.Image)
+		if file, _, ok := fv.itemFile(cell.Item()); ok {
+			fv.applyRowClasses(image, file)
+			image.SetFromGIcon(fileIcon(file))
+			image.SetPixelSize(fv.iconSize)
+		}
+	})
+
+	fv.iconColumn = gtk.NewColumnViewColumn("", &iconFactory.ListItemFactory)
+	fv.iconColumn.SetFixedWidth(fv.iconSize + iconColumnPadding)
+	fv.listView.AppendColumn(fv.iconColumn)
+
 	// Name column
 	nameFactory := gtk.NewSignalListItemFactory()
 	nameFactory.ConnectSetup(func(obj *glib.Object) {
 		cell := obj.Cast().(*gtk.ColumnViewCell)
 		label := gtk.NewLabel("")
 		label.SetXAlign(0) // Left align
-		cell.SetChild(label)
+		// Links show where they point after the name
+		target := gtk.NewLabel("")
+		target.SetXAlign(0)
+		target.SetEllipsize(pango.EllipsizeEnd)
+		target.AddCSSClass(ClassSymlinkTarget)
+		box := gtk.NewBox(gtk.OrientationHorizontal, 6)
+		box.Append(label)
+		box.Append(target)
+		// The expander indents tree mode rows and toggles directories
+		expander := gtk.NewTreeExpander()
+		expander.SetChild(box)
+		cell.SetChild(expander)
+		fv.selectOnSecondaryClick(expander, cell)
 	})
 	nameFactory.ConnectBind(func(obj *glib.Object) {
 		cell := obj.Cast().(*gtk.ColumnViewCell)
-		label := cell.Child().(*gtk.Label)
-
-		// Get the file info from the position
-		pos := cell.Position()
-		if pos < uint(len(fv.files)) {
-			file := fv.files[pos]
-			icon := "📄"
-			if file.IsDir {
-				icon = "📁"
-			} else if file.IsSymlink {
-				icon = "🔗"
+		expander := cell.Child().(*gtk.TreeExpander)
+		box := expander.Child().(*gtk.Box)
+		label := box.FirstChild().(*gtk.Label)
+		target := label.NextSibling().(*gtk.Label)
+
+		file, row, ok := fv.itemFile(cell.Item())
+		expander.SetListRow(row)
+		expander.SetIndentForIcon(fv.treeMode)
+		if ok {
+			fv.applyRowClasses(label, file)
+			label.SetText(fileops.DisplayName(file.Name))
+			label.SetEllipsize(fv.ellipsize)
+			label.SetTooltipText(nameTooltip(file))
+			// Screen readers get the icon in words, and the yank state
+			// the indicator column shows
+			SetAccessibleLabel(label, fv.describe(file))
+
+			target.SetVisible(file.IsSymlink)
+			if file.IsSymlink {
+				fv.applyRowClasses(target, file)
+				target.SetText("→ " + fileops.DisplayName(file.SymlinkTarget))
+				target.SetTooltipText(nameTooltip(file))
 			}
-			label.SetText(fmt.Sprintf("%s %s", icon, file.Name))
 		}
 	})
 
@@ -154,18 +342,21 @@ func (fv *FileView) addColumns() {
 		label := gtk.NewLabel("")
 		label.SetXAlign(1) // Right align
 		cell.SetChild(label)
+		fv.selectOnSecondaryClick(label, cell)
 	})
 	sizeFactory.ConnectBind(func(obj *glib.Object) {
 		cell := obj.Cast().(*gtk.ColumnViewCell)
 		label := cell.Child().(*gtk.Label)
 
-		pos := cell.Position()
-		if pos < uint(len(fv.files)) {
-			file := fv.files[pos]
-			if file.IsDir {
-				label.SetText("-")
-			} else {
-				label.SetText(fileops.FormatSize(file.Size))
+		if file, _, ok := fv.itemFile(cell.Item()); ok {
+			fv.applyRowClasses(label, file)
+			switch {
+			case file.Pending:
+				label.SetText("")
+			case file.IsDir:
+				label.SetText(fileops.FormatItemCount(file.ItemCount))
+			default:
+				label.SetText(fileops.FormatSizeAs(file.Size, fv.sizeFormat))
 			}
 		}
 	})
@@ -181,15 +372,19 @@ func (fv *FileView) addColumns() {
 		label := gtk.NewLabel("")
 		label.SetXAlign(0)
 		cell.SetChild(label)
+		fv.selectOnSecondaryClick(label, cell)
 	})
 	modFactory.ConnectBind(func(obj *glib.Object) {
 		cell := obj.Cast().(*gtk.ColumnViewCell)
 		label := cell.Child().(*gtk.Label)
 
-		pos := cell.Position()
-		if pos < uint(len(fv.files)) {
-			file := fv.files[pos]
-			label.SetText(formatModTime(file.ModTime))
+		if file, _, ok := fv.itemFile(cell.Item()); ok {
+			fv.applyRowClasses(label, file)
+			if file.Pending {
+				label.SetText("")
+			} else {
+				label.SetText(fv.formatModTime(file.ModTime))
+			}
 		}
 	})
 
@@ -198,46 +393,363 @@ func (fv *FileView) addColumns() {
 	fv.listView.AppendColumn(modColumn)
 }
 
+// itemFile returns the file a list item stands for, and the tree row
+// wrapping it. The store's items are the paths of files, looked up in
+// fv.entries; cells bind through their item rather than their position,
+// so they show the right file whatever model sorts or filters the store.
+func (fv *FileView) itemFile(item *glib.Object) (models.FileInfo, *gtk.TreeListRow, bool) {
+	if item == nil {
+		return models.FileInfo{}, nil, false
+	}
+	var row *gtk.TreeListRow
+	obj := item.Cast()
+	if r, ok := obj.(*gtk.TreeListRow); ok {
+		row = r
+		obj = r.Item().Cast()
+	}
+	path, ok := obj.(*gtk.StringObject)
+	if !ok {
+		return models.FileInfo{}, row, false
+	}
+	file, ok := fv.entries[path.String()]
+	return file, row, ok
+}
+
+// rowClasses returns the state classes that apply to file.
+func (fv *FileView) rowClasses(file models.FileInfo) []string {
+	var classes []string
+	if fv.IsYanked(file.Path) {
+		if fv.yankCut {
+			classes = append(classes, ClassCut)
+		} else {
+			classes = append(classes, ClassYanked)
+		}
+	}
+	if fv.marked[file.Path] {
+		classes = append(classes, ClassMarked)
+	}
+	if file.IsHidden {
+		classes = append(classes, ClassHidden)
+	}
+	switch {
+	case file.IsBrokenSymlink:
+		classes = append(classes, ClassBrokenSymlink)
+	case file.IsSymlink:
+		classes = append(classes, ClassSymlink)
+	}
+	if file.IsDir {
+		classes = append(classes, ClassDirectory)
+	} else if file.IsExecutable() {
+		classes = append(classes, ClassExecutable)
+	}
+	if file.IsSetuid() {
+		classes = append(classes, ClassSetuid)
+	}
+	if file.IsUnreadable {
+		classes = append(classes, ClassUnreadable)
+	}
+	if class, ok := compareClasses[fv.compareMarks[file.Path]]; ok {
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// applyRowClasses replaces the state classes on a cell's child widget.
+func (fv *FileView) applyRowClasses(w cssClassed, file models.FileInfo) {
+	for _, class := range rowStateClasses {
+		w.RemoveCSSClass(class)
+	}
+	for _, class := range fv.rowClasses(file) {
+		w.AddCSSClass(class)
+	}
+}
+
 // Widget returns the GTK widget.
 func (fv *FileView) Widget() gtk.Widgetter {
-	return fv.widget
+	return fv.overlay
 }
 
-// LoadDirectory loads and displays the contents of a directory.
+// LoadDirectory loads and displays the contents of a directory. Entering
+// another directory selects its first entry; reloading the current one
+// keeps the selected entry, or the row at its position if it was removed.
+//
+// The directory is read on the calling thread; see LoadDirectoryAsync for
+// navigation that may be slow. A background load in progress is cancelled.
 func (fv *FileView) LoadDirectory(path string) error {
-	files, err := fileops.ListDirectory(path, fv.showHidden)
+	fv.cancelLoad()
+	files, hidden, err := fv.listSettings().read(context.Background(), path, false)
 	if err != nil {
-		return fmt.Errorf("failed to load directory: %w", err)
+		return err
 	}
+	return fv.showDirectory(path, files, hidden)
+}
 
-	// Sort files using current sort mode and order
-	fileops.SortFiles(files, fv.sortMode, fv.sortOrder)
-
-	fv.files = files
+// showDirectory displays the sorted listing of path, which leaves out
+// hidden entries.
+func (fv *FileView) showDirectory(path string, files []models.FileInfo, hidden int) error {
+	fv.dirError.SetVisible(false)
+	changed := path != fv.currentPath
+	var expand []string
+	var keep string
+	if !changed {
+		// Keep directories open and the selection across reloads (hidden
+		// toggle, filter, after a delete or rename)
+		expand = fv.expandedPaths()
+		keep = fv.GetSelectedPath()
+	} else {
+		// A comparison only describes the directory it was made for,
+		// and marks pick entries out of it
+		fv.compareMarks = nil
+		fv.dropMarks()
+	}
+	old := fv.files
+	if changed {
+		old = nil
+	}
 	fv.currentPath = path
+	fv.hiddenCount = hidden
+	SetAccessibleLabel(fv.listView, "Files in "+path)
 
 	// Start watching the new directory
 	if fv.watcher != nil {
 		if err := fv.watcher.Start(path); err != nil {
-			log.Printf("Warning: Failed to watch directory %s: %v", path, err)
+			slog.Warn("Failed to watch directory", "path", path, "err", err)
 			// Continue without watching - not critical
 		}
 	}
 
 	// Refresh the display
-	return fv.refreshDisplay()
+	if err := fv.refreshDisplay(old, files, expand, keep); err != nil {
+		return err
+	}
+	fv.statFiles()
+	fv.countItems()
+	fv.notifyListing()
+
+	// Reloads of the same directory (watcher, hidden toggle) are not a change
+	if changed {
+		for _, callback := range fv.onDirectoryChanged {
+			callback(path)
+		}
+	}
+	return nil
 }
 
-// refreshDisplay updates the GTK store and resets selection.
-// This is a helper method used by LoadDirectory and Refresh.
-func (fv *FileView) refreshDisplay() error {
-	// Clear the store
-	fv.store.RemoveAll()
+// reloadChanged re-reads the current directory after a watcher event and
+// applies only the differences to the list, so unchanged rows, the
+// selection and the scroll position stay put. While reloads are held, or
+// an operation such as a large paste is still filling the directory, it
+// just records that one is due.
+func (fv *FileView) reloadChanged() error {
+	if fv.currentPath == "" {
+		return nil
+	}
+	if fv.reloadHolds > 0 || fileops.DefaultQueue().Busy(fv.currentPath) {
+		fv.reloadPending = true
+		return nil
+	}
+	if fv.dirError.Visible() {
+		// Nothing is listed; retrying is up to the user
+		return nil
+	}
+
+	files, hidden, err := fv.listSettings().read(context.Background(), fv.currentPath, false)
+	if err != nil {
+		return err
+	}
+	fv.hiddenCount = hidden
+
+	selectedPath := fv.GetSelectedPath()
+
+	// Store positions only match the listing while nothing is expanded,
+	// so an open tree is rebuilt instead
+	if expand := fv.expandedPaths(); len(expand) > 0 {
+		fv.replaceRows(files, expand)
+		fv.restoreSelection(selectedPath)
+		fv.countItems()
+		fv.notifyListing()
+		return nil
+	}
+
+	if !fv.spliceRows(files) {
+		fv.replaceRows(files, nil)
+	}
+	fv.restoreSelection(selectedPath)
+	fv.countItems()
+	fv.notifyListing()
+	return nil
+}
+
+// spliceRows turns the store, listing fv.files, into one listing files by
+// applying only the differences, so unchanged rows keep their selection
+// and scroll position and don't flicker. It returns false, changing
+// nothing, when more rows moved than a refill would touch; the caller
+// replaces the rows then. Expanded directories must be collapsed.
+func (fv *FileView) spliceRows(files []models.FileInfo) bool {
+	edits := fileops.DiffListing(fv.files, files)
+	if len(edits) > len(files) {
+		return false
+	}
+	fv.files = files
+
+	// Edits leave every row before their index final, so rows bound while
+	// applying them already see the new listing at the right position
+	fv.replacingRows = true
+	removed := false
+	for _, e := range edits {
+		switch e.Kind {
+		case fileops.EditRemove:
+			fv.store.Remove(uint(e.Index))
+			removed = true
+		case fileops.EditInsert:
+			file := files[e.File]
+			fv.entries[file.Path] = file
+			fv.store.Insert(uint(e.Index), gtk.NewStringObject(file.Path).Object)
+		case fileops.EditUpdate:
+			// Replacing the item makes the row bind again
+			file := files[e.File]
+			fv.entries[file.Path] = file
+			obj := gtk.NewStringObject(file.Path)
+			fv.store.Splice(uint(e.Index), 1, []*glib.Object{obj.Object})
+		}
+	}
+	fv.replacingRows = false
+
+	// Forget the files that went; a moved file is removed and inserted
+	// again, in either order, so this waits for the last edit
+	if removed {
+		listed := make(map[string]bool, len(files))
+		for _, file := range files {
+			listed[file.Path] = true
+		}
+		maps.DeleteFunc(fv.entries, func(path string, _ models.FileInfo) bool {
+			return !listed[path]
+		})
+	}
+	return true
+}
 
-	// Add files to store (we use StringObject as placeholders)
+// restoreSelection selects path again after the listing changed, without
+// scrolling. If the file is gone, the row now at the old index (or the last
+// row) is selected instead.
+func (fv *FileView) restoreSelection(path string) {
+	index := -1
 	for i := range fv.files {
-		obj := gtk.NewStringObject(fmt.Sprintf("%d", i))
-		fv.store.Append(obj.Object)
+		if fv.files[i].Path == path {
+			index = i
+			break
+		}
+	}
+	if index < 0 && len(fv.files) > 0 {
+		index = min(max(fv.selectedIndex, 0), len(fv.files)-1)
+	}
+
+	fv.selectedIndex = index
+	if index < 0 {
+		return
+	}
+	selection := fv.listView.Model().Cast().(*gtk.SingleSelection)
+	selection.SetSelected(uint(index))
+
+	file := &fv.files[index]
+	if file.Path != fv.notifiedSelection {
+		fv.notifiedSelection = file.Path
+		for _, callback := range fv.onSelectionChanged {
+			callback(file)
+		}
+	}
+}
+
+// HoldReloads defers watcher-driven reloads, typically while a dialog that
+// refers to the listing (rename, new file) is open. Call the returned
+// function once to release the hold; a reload that arrived meanwhile is
+// applied then.
+func (fv *FileView) HoldReloads() (release func()) {
+	fv.reloadHolds++
+	released := false
+	return func() {
+		if released {
+			return
+		}
+		released = true
+		fv.reloadHolds--
+		fv.applyPendingReload()
+	}
+}
+
+// applyPendingReload applies a reload deferred by reloadChanged once
+// nothing holds it back any more.
+func (fv *FileView) applyPendingReload() {
+	if !fv.reloadPending || fv.reloadHolds > 0 || fileops.DefaultQueue().Busy(fv.currentPath) {
+		return
+	}
+	fv.reloadPending = false
+	if err := fv.reloadChanged(); err != nil {
+		slog.Warn("Failed to reload directory after file change", "err", err)
+	}
+}
+
+// SetWatchSubdirectories sets whether the watcher also watches immediate
+// subdirectories, keeping their item counts current.
+func (fv *FileView) SetWatchSubdirectories(enabled bool) {
+	if fv.watcher != nil {
+		fv.watcher.SetWatchSubdirectories(enabled)
+	}
+}
+
+// SetPollInterval sets how often directories that cannot be watched through
+// inotify are checked for changes.
+func (fv *FileView) SetPollInterval(interval time.Duration) {
+	if fv.watcher != nil {
+		fv.watcher.SetPollInterval(interval)
+	}
+}
+
+// ConnectDirectoryChanged registers a callback invoked after a different
+// directory has been loaded. Callbacks run in the order they were added.
+func (fv *FileView) ConnectDirectoryChanged(callback func(path string)) {
+	fv.onDirectoryChanged = append(fv.onDirectoryChanged, callback)
+}
+
+// ConnectListingChanged registers a callback invoked after the listing was
+// read again, whether of another directory or the same one after a
+// change, so summaries of it such as the entry count can follow.
+// Callbacks run in the order they were added.
+func (fv *FileView) ConnectListingChanged(callback func()) {
+	fv.onListingChanged = append(fv.onListingChanged, callback)
+}
+
+// notifyListing runs the ConnectListingChanged callbacks.
+func (fv *FileView) notifyListing() {
+	for _, callback := range fv.onListingChanged {
+		callback()
+	}
+}
+
+// ConnectSelectionChanged registers a callback invoked when the selection
+// moves to a different entry. Callbacks run in the order they were added.
+func (fv *FileView) ConnectSelectionChanged(callback func(file *models.FileInfo)) {
+	fv.onSelectionChanged = append(fv.onSelectionChanged, callback)
+}
+
+// refreshDisplay shows files, in place of old, and expands the given
+// directories again in tree mode. When old is another listing of the same
+// directory and nothing is expanded, only the rows that changed are
+// touched (see spliceRows); with old nil the store is refilled. The entry
+// at keep stays selected (or the row at its old position, if it is gone);
+// with keep empty the selection resets to the first entry.
+// This is a helper method used by LoadDirectory and Refresh.
+func (fv *FileView) refreshDisplay(old, files []models.FileInfo, expand []string, keep string) error {
+	if old == nil || len(expand) > 0 || !fv.spliceRows(files) {
+		fv.replaceRows(files, expand)
+	}
+
+	if keep != "" {
+		fv.restoreSelection(keep)
+		if fv.selectedIndex >= 0 {
+			fv.listView.ScrollTo(uint(fv.selectedIndex), nil, gtk.ListScrollNone, nil)
+		}
+		return nil
 	}
 
 	// Reset selection
@@ -251,17 +763,28 @@ func (fv *FileView) refreshDisplay() error {
 
 // Refresh re-sorts and refreshes the display without reloading from disk.
 // This is much faster than LoadDirectory for operations that only change
-// the sort order or mode.
+// the sort order or mode. Sorting by size or date while a lazy load is
+// still reading them happens once they are all in.
 func (fv *FileView) Refresh() error {
 	if len(fv.files) == 0 {
 		return nil
 	}
 
-	// Re-sort existing files
-	fileops.SortFiles(fv.files, fv.sortMode, fv.sortOrder)
+	// Sorting by size or date needs the details still being read, so
+	// wait for statFiles, which refreshes again once it has them all
+	fv.statSort = fv.statCancel != nil && !sortsByName(fv.sortMode)
+	if fv.statSort {
+		return nil
+	}
 
-	// Refresh the display
-	return fv.refreshDisplay()
+	// Re-sort the top level; expanded directories are re-read sorted
+	expand := fv.expandedPaths()
+	keep := fv.GetSelectedPath()
+	files := slices.Clone(fv.rootFiles())
+	fv.listSettings().sort(files)
+
+	// Refresh the display, following the selected entry to its new place
+	return fv.refreshDisplay(fv.files, files, expand, keep)
 }
 
 // SelectIndex selects the file at the given index.
@@ -278,6 +801,135 @@ func (fv *FileView) SelectIndex(index int) {
 	// Scroll to make the selected item visible (only scrolls if needed)
 	// gtk.ListScrollNone means scroll minimally - just enough to make it visible
 	fv.listView.ScrollTo(uint(index), nil, gtk.ListScrollNone, nil)
+
+	file := &fv.files[index]
+	if file.Path != fv.notifiedSelection {
+		fv.notifiedSelection = file.Path
+		// Keys move the selection, not the focus, so screen readers
+		// wouldn't notice on their own
+		announce(fv.listView, fmt.Sprintf("%s, %d of %d", fv.describe(*file), index+1, len(fv.files)), status.SeverityInfo)
+		for _, callback := range fv.onSelectionChanged {
+			callback(file)
+		}
+	}
+}
+
+// describe says what file is for screen readers: its name, kind, and
+// whether it is yanked or cut.
+func (fv *FileView) describe(file models.FileInfo) string {
+	text := fileops.DisplayName(file.Name) + ", " + file.Kind()
+	if fv.IsYanked(file.Path) {
+		if fv.yankCut {
+			text += ", cut"
+		} else {
+			text += ", yanked"
+		}
+	}
+	if fv.marked[file.Path] {
+		text += ", marked"
+	}
+	return text
+}
+
+// SelectPath selects the entry with the given path.
+// Returns false if the path is not part of the current listing.
+func (fv *FileView) SelectPath(path string) bool {
+	for i := range fv.files {
+		if fv.files[i].Path == path {
+			fv.SelectIndex(i)
+			return true
+		}
+	}
+	return false
+}
+
+// SelectFirst selects the first item.
+func (fv *FileView) SelectFirst() {
+	fv.SelectIndex(0)
+}
+
+// SelectLast selects the last item.
+func (fv *FileView) SelectLast() {
+	fv.SelectIndex(len(fv.files) - 1)
+}
+
+// MoveSelection moves the selection by delta items, stopping at either end.
+func (fv *FileView) MoveSelection(delta int) {
+	if len(fv.files) == 0 {
+		return
+	}
+	index := max(fv.selectedIndex, 0) + delta
+	fv.SelectIndex(min(max(index, 0), len(fv.files)-1))
+}
+
+// SelectLine selects the nth item, counting from 1. Numbers past the end
+// select the last item.
+func (fv *FileView) SelectLine(n int) {
+	fv.SelectIndex(min(max(n, 1), len(fv.files)) - 1)
+}
+
+// visibleRange returns the first and last rows fully inside the viewport.
+// Rows have a uniform height, so it is derived from the scroll adjustment.
+func (fv *FileView) visibleRange() (first, last int) {
+	n := len(fv.files)
+	adj := fv.widget.VAdjustment()
+	if n == 0 || adj.Upper() <= 0 || adj.PageSize() <= 0 {
+		return 0, n - 1
+	}
+
+	rowHeight := adj.Upper() / float64(n)
+	first = int(math.Ceil(adj.Value() / rowHeight))
+	last = int((adj.Value()+adj.PageSize())/rowHeight) - 1
+
+	first = min(max(first, 0), n-1)
+	last = min(max(last, first), n-1)
+	return first, last
+}
+
+// HalfPageDown scrolls down half a page, moving the selection with it.
+func (fv *FileView) HalfPageDown() {
+	fv.scrollHalfPage(1)
+}
+
+// HalfPageUp scrolls up half a page, moving the selection with it.
+func (fv *FileView) HalfPageUp() {
+	fv.scrollHalfPage(-1)
+}
+
+// scrollHalfPage scrolls by half the visible rows in direction (1 or -1).
+func (fv *FileView) scrollHalfPage(direction int) {
+	if len(fv.files) == 0 {
+		return
+	}
+
+	first, last := fv.visibleRange()
+	half := max((last-first+1)/2, 1)
+
+	// Scroll the viewport first so the selection keeps its place on screen;
+	// the adjustment clamps at either end
+	adj := fv.widget.VAdjustment()
+	rowHeight := adj.Upper() / float64(len(fv.files))
+	adj.SetValue(adj.Value() + float64(direction*half)*rowHeight)
+
+	fv.MoveSelection(direction * half)
+}
+
+// SelectViewTop selects the row offset rows below the top of the viewport.
+func (fv *FileView) SelectViewTop(offset int) {
+	first, last := fv.visibleRange()
+	fv.SelectIndex(min(first+max(offset, 0), last))
+}
+
+// SelectViewMiddle selects the row in the middle of the viewport.
+func (fv *FileView) SelectViewMiddle() {
+	first, last := fv.visibleRange()
+	fv.SelectIndex(first + (last-first)/2)
+}
+
+// SelectViewBottom selects the row offset rows above the bottom of the viewport.
+func (fv *FileView) SelectViewBottom(offset int) {
+	first, last := fv.visibleRange()
+	fv.SelectIndex(max(last-max(offset, 0), first))
 }
 
 // SelectNext moves selection down one item.
@@ -302,33 +954,58 @@ func (fv *FileView) GetSelected() *models.FileInfo {
 	return &fv.files[fv.selectedIndex]
 }
 
+// SelectedIndex returns the index of the selected item, or -1.
+func (fv *FileView) SelectedIndex() int {
+	return fv.selectedIndex
+}
+
+// FileNames returns the names of the listed entries in display order.
+func (fv *FileView) FileNames() []string {
+	names := make([]string, len(fv.files))
+	for i := range fv.files {
+		names[i] = fv.files[i].Name
+	}
+	return names
+}
+
+// Files returns a copy of the listed entries in display order.
+func (fv *FileView) Files() []models.FileInfo {
+	return slices.Clone(fv.files)
+}
+
 // GetCurrentPath returns the current directory path.
 func (fv *FileView) GetCurrentPath() string {
 	return fv.currentPath
 }
 
-// NavigateUp navigates to the parent directory.
-func (fv *FileView) NavigateUp() error {
+// NavigateUp navigates to the parent directory in the background, as
+// LoadDirectoryAsync does. At the root, done runs at once.
+func (fv *FileView) NavigateUp(done func(err error)) {
 	parent := fileops.GetParentDir(fv.currentPath)
 	if parent == fv.currentPath {
 		// Already at root
-		return nil
+		done(nil)
+		return
 	}
-	return fv.LoadDirectory(parent)
+	fv.LoadDirectoryAsync(parent, done)
 }
 
-// NavigateInto enters the selected directory.
-func (fv *FileView) NavigateInto() error {
+// NavigateInto enters the selected directory in the background, as
+// LoadDirectoryAsync does. If nothing or a file is selected, done runs at
+// once with the error.
+func (fv *FileView) NavigateInto(done func(err error)) {
 	selected := fv.GetSelected()
 	if selected == nil {
-		return fmt.Errorf("no file selected")
+		done(fmt.Errorf("no file selected"))
+		return
 	}
 
 	if !selected.IsDir {
-		return fmt.Errorf("not a directory")
+		done(fmt.Errorf("not a directory"))
+		return
 	}
 
-	return fv.LoadDirectory(selected.Path)
+	fv.LoadDirectoryAsync(selected.Path, done)
 }
 
 // ToggleHidden toggles the visibility of hidden files.
@@ -337,13 +1014,81 @@ func (fv *FileView) ToggleHidden() error {
 	return fv.LoadDirectory(fv.currentPath)
 }
 
+// ShowHidden reports whether hidden files are shown.
+func (fv *FileView) ShowHidden() bool {
+	return fv.showHidden
+}
+
+// SetFilter restricts the listing to names matching filter, in this and
+// every directory visited until it is replaced. The zero filter clears it.
+func (fv *FileView) SetFilter(filter fileops.NameFilter) error {
+	fv.filter = filter
+	return fv.LoadDirectory(fv.currentPath)
+}
+
+// Filter returns the current name filter.
+func (fv *FileView) Filter() fileops.NameFilter {
+	return fv.filter
+}
+
 // formatModTime formats a time for display in the file list.
-func formatModTime(t time.Time) string {
-	now := time.Now()
-	if t.Year() == now.Year() {
-		return t.Format("Jan 02 15:04")
+func (fv *FileView) formatModTime(t time.Time) string {
+	if fv.timeFormat == fileops.TimeLocale {
+		// %c is the locale's preferred date and time representation
+		return glib.NewDateTimeFromUnixLocal(t.Unix()).Format("%c")
+	}
+	return fileops.FormatTime(t, time.Now(), fv.timeFormat)
+}
+
+// SetDisplayFormats sets how the Size and Modified columns are formatted
+// and redraws the rows.
+func (fv *FileView) SetDisplayFormats(size fileops.SizeFormat, modTime fileops.TimeFormat) {
+	if size == fv.sizeFormat && modTime == fv.timeFormat {
+		return
 	}
-	return t.Format("Jan 02  2006")
+	fv.sizeFormat = size
+	fv.timeFormat = modTime
+	fv.rebindRows()
+}
+
+// SetIconSize sets the pixel size of the icons beside file names and
+// redraws the rows.
+func (fv *FileView) SetIconSize(size int) {
+	if size == fv.iconSize {
+		return
+	}
+	fv.iconSize = size
+	fv.iconColumn.SetFixedWidth(size + iconColumnPadding)
+	fv.rebindRows()
+}
+
+// SetEllipsize sets where names too long for the Name column are cut, and
+// redraws the rows.
+func (fv *FileView) SetEllipsize(mode theme.Ellipsize) {
+	ellipsize := pango.EllipsizeMiddle
+	if mode == theme.EllipsizeEnd {
+		ellipsize = pango.EllipsizeEnd
+	}
+	if ellipsize == fv.ellipsize {
+		return
+	}
+	fv.ellipsize = ellipsize
+	fv.rebindRows()
+}
+
+// nameTooltip returns the tooltip of a name cell: the whole name, which
+// the cell may cut, and where a link points.
+func nameTooltip(file models.FileInfo) string {
+	tooltip := fileops.DisplayName(file.Name)
+	if file.IsSymlink {
+		tooltip += " → " + fileops.DisplayName(file.SymlinkTarget)
+	}
+	return tooltip
+}
+
+// GetSizeFormat returns how the Size column is formatted.
+func (fv *FileView) GetSizeFormat() fileops.SizeFormat {
+	return fv.sizeFormat
 }
 
 // GetFileCount returns the number of files currently displayed.
@@ -351,6 +1096,12 @@ func (fv *FileView) GetFileCount() int {
 	return len(fv.files)
 }
 
+// HiddenCount returns how many hidden entries the current directory has
+// that are not shown; 0 while hidden files are shown.
+func (fv *FileView) HiddenCount() int {
+	return fv.hiddenCount
+}
+
 // GetSelectedPath returns the path of the selected file, or empty string.
 func (fv *FileView) GetSelectedPath() string {
 	selected := fv.GetSelected()
@@ -368,14 +1119,54 @@ func (fv *FileView) ParentPath() string {
 	return filepath.Dir(fv.currentPath)
 }
 
+// State returns the view's directory, selection and how it lists them,
+// to save with the window layout.
+func (fv *FileView) State() config.ViewState {
+	return config.ViewState{
+		Path:       fv.currentPath,
+		Selected:   fv.GetSelectedPath(),
+		SortMode:   fv.sortMode.String(),
+		SortOrder:  fv.sortOrder.String(),
+		ShowHidden: fv.showHidden,
+		Filter:     fv.filter.String(),
+	}
+}
+
+// ApplyState takes the sort, hidden files and filter of state without
+// reloading, for a view about to load a directory. Loading state.Path and
+// selecting state.Selected is left to the caller, since where a window
+// opens depends on more than where it last was. A filter that no longer
+// parses is dropped.
+func (fv *FileView) ApplyState(state config.ViewState) {
+	fv.sortMode = config.ParseSortMode(state.SortMode)
+	fv.sortOrder = config.ParseSortOrder(state.SortOrder)
+	fv.showHidden = state.ShowHidden
+	filter, err := fileops.ParseNameFilter(state.Filter)
+	if err != nil {
+		slog.Warn("Dropping saved filter", "filter", state.Filter, "err", err)
+		filter = fileops.NameFilter{}
+	}
+	fv.filter = filter
+}
+
 // SetSortMode sets the sort mode and order for the file view.
 func (fv *FileView) SetSortMode(mode models.SortBy, order models.SortOrder) {
 	fv.sortMode = mode
 	fv.sortOrder = order
 }
 
+// SetHiddenLast sets whether hidden entries are listed after the others
+// rather than among them, and re-sorts the listing.
+func (fv *FileView) SetHiddenLast(last bool) error {
+	if last == fv.hiddenLast {
+		return nil
+	}
+	fv.hiddenLast = last
+	return fv.Refresh()
+}
+
 // CycleSortMode cycles through the available sort modes.
-// Order: Name -> Size -> Modified -> Extension -> (repeat)
+// Order: Name -> Size -> Modified -> Extension -> Taken -> (repeat)
 func (fv *FileView) CycleSortMode() error {
 	switch fv.sortMode {
 	case models.SortByName:
@@ -385,6 +1176,8 @@ func (fv *FileView) CycleSortMode() error {
 	case models.SortByModTime:
 		fv.sortMode = models.SortByExtension
 	case models.SortByExtension:
+		fv.sortMode = models.SortByTaken
+	case models.SortByTaken:
 		fv.sortMode = models.SortByName
 	default:
 		fv.sortMode = models.SortByName
@@ -419,6 +1212,11 @@ func (fv *FileView) ToggleSortOrder() error {
 // Close stops the file watcher and cleans up resources.
 // This should be called when the FileView is no longer needed.
 func (fv *FileView) Close() error {
+	fv.unsubscribeOps()
+	if fv.menu != nil {
+		fv.menu.Unparent()
+		fv.menu = nil
+	}
 	if fv.watcher != nil {
 		return fv.watcher.Stop()
 	}
@@ -432,8 +1230,49 @@ func (fv *FileView) YankSelected() {
 		return
 	}
 	fv.yankedFiles = []string{selected.Path}
+	fv.yankCut = false
 	// Trigger a visual refresh to show the yank indicator
-	fv.updateYankVisuals()
+	fv.rebindRows()
+}
+
+// YankRange yanks count items starting at the selection. If cut is true
+// the files are moved instead of copied when pasted. Returns the number of
+// files yanked.
+func (fv *FileView) YankRange(count int, cut bool) int {
+	paths := fv.RangePaths(count)
+	if len(paths) == 0 {
+		return 0
+	}
+	fv.SetYanked(paths, cut)
+	return len(paths)
+}
+
+// RangePaths returns the paths of count entries starting at the
+// selection, fewer at the end of the list.
+func (fv *FileView) RangePaths(count int) []string {
+	if fv.selectedIndex < 0 || count < 1 {
+		return nil
+	}
+	end := min(fv.selectedIndex+count, len(fv.files))
+
+	paths := make([]string, 0, end-fv.selectedIndex)
+	for i := fv.selectedIndex; i < end; i++ {
+		paths = append(paths, fv.files[i].Path)
+	}
+	return paths
+}
+
+// SetYanked replaces the yanked files, e.g. with a yank saved by an
+// earlier session.
+func (fv *FileView) SetYanked(paths []string, cut bool) {
+	fv.yankedFiles = paths
+	fv.yankCut = cut
+	fv.rebindRows()
+}
+
+// IsCut reports whether the yanked files will be moved on paste.
+func (fv *FileView) IsCut() bool {
+	return fv.yankCut
 }
 
 // GetYanked returns the list of yanked file paths.
@@ -444,22 +1283,20 @@ func (fv *FileView) GetYanked() []string {
 // ClearYanked clears the yanked files list.
 func (fv *FileView) ClearYanked() {
 	fv.yankedFiles = nil
+	fv.yankCut = false
 	// Trigger a visual refresh to hide the yank indicator
-	fv.updateYankVisuals()
+	fv.rebindRows()
 }
 
-// updateYankVisuals forces the list view to update yank indicators.
-func (fv *FileView) updateYankVisuals() {
+// rebindRows forces every row to be bound again, updating yank indicators,
+// row state classes and column formatting without reloading the directory.
+func (fv *FileView) rebindRows() {
 	// Preserve current selection
 	currentSelection := fv.selectedIndex
 
 	// Force complete refresh to rebind all cells
 	// This ensures CSS classes are properly updated
-	fv.store.RemoveAll()
-	for i := range fv.files {
-		obj := gtk.NewStringObject(fmt.Sprintf("%d", i))
-		fv.store.Append(obj.Object)
-	}
+	fv.replaceRows(fv.rootFiles(), fv.expandedPaths())
 
 	// Restore selection
 	if currentSelection >= 0 && currentSelection < len(fv.files) {
@@ -467,6 +1304,18 @@ func (fv *FileView) updateYankVisuals() {
 	}
 }
 
+// SetCompareMarks highlights entries of the current directory after a
+// comparison with another pane. Marks are keyed by path; nil clears them.
+func (fv *FileView) SetCompareMarks(marks map[string]fileops.CompareMark) {
+	fv.compareMarks = marks
+	fv.rebindRows()
+}
+
+// HasCompareMarks reports whether comparison highlights are shown.
+func (fv *FileView) HasCompareMarks() bool {
+	return fv.compareMarks != nil
+}
+
 // IsYanked returns true if the file at the given path is yanked.
 func (fv *FileView) IsYanked(path string) bool {
 	for _, yanked := range fv.yankedFiles {
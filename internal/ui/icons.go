@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// Icon theme names for entries that aren't shown by their MIME type.
+// Themed icons fall back along the dashes, so a theme without
+// "folder-locked" shows "folder".
+const (
+	iconFolder       = "folder"
+	iconLockedFolder = "folder-locked"
+	iconUnreadable   = "emblem-unreadable"
+	iconSymlink      = "emblem-symbolic-link"
+	iconSetuid       = "dialog-warning"
+	iconExecutable   = "application-x-executable"
+	iconGeneric      = "text-x-generic"
+)
+
+// fileIcons caches the icons fileIcon returns, by icon name or content
+// type. Only used on the GTK main thread.
+var fileIcons = make(map[string]gio.Iconner)
+
+// fileIcon returns the icon theme's icon for file: a folder, a link, a
+// program, or the icon of the MIME type its name suggests. The state
+// fileIcon picks from is the same the row's CSS classes show.
+func fileIcon(file models.FileInfo) gio.Iconner {
+	name := ""
+	switch {
+	case file.IsUnreadable && file.IsDir:
+		name = iconLockedFolder
+	case file.IsUnreadable:
+		name = iconUnreadable
+	case file.IsDir:
+		name = iconFolder
+	case file.IsSymlink:
+		name = iconSymlink
+	case file.IsSetuid():
+		name = iconSetuid
+	case file.IsExecutable():
+		name = iconExecutable
+	}
+	if name != "" {
+		return cachedIcon(name, func() gio.Iconner {
+			return gio.NewThemedIconWithDefaultFallbacks(name)
+		})
+	}
+
+	// Guessed from the name alone: reading every file to sniff it would
+	// slow listings down
+	_, contentType := gio.ContentTypeGuess(file.Name, nil)
+	return cachedIcon("type:"+contentType, func() gio.Iconner {
+		if icon := gio.ContentTypeGetIcon(contentType); icon != nil {
+			return icon
+		}
+		return gio.NewThemedIcon(iconGeneric)
+	})
+}
+
+// cachedIcon returns the icon cached under key, making it first if there
+// is none.
+func cachedIcon(key string, newIcon func() gio.Iconner) gio.Iconner {
+	icon, ok := fileIcons[key]
+	if !ok {
+		icon = newIcon()
+		fileIcons[key] = icon
+	}
+	return icon
+}
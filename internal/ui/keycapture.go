@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/keymap"
+)
+
+// KeyCapture is a button that records a keybinding. Clicking it starts
+// recording; each key pressed is added to the chord, which is committed
+// when no key follows within keymap.DefaultTimeout. Escape cancels and
+// BackSpace on its own clears the binding.
+// All methods must be called on the GTK main thread.
+type KeyCapture struct {
+	button    *gtk.Button
+	value     string
+	recording bool
+	keys      keymap.Sequence
+	timer     glib.SourceHandle
+	onChanged func(string)
+}
+
+// NewKeyCapture creates a capture button showing value.
+func NewKeyCapture(value string) *KeyCapture {
+	kc := &KeyCapture{
+		button: gtk.NewButton(),
+		value:  value,
+	}
+	kc.button.SetHAlign(gtk.AlignStart)
+	kc.button.ConnectClicked(kc.startRecording)
+
+	keyController := gtk.NewEventControllerKey()
+	keyController.ConnectKeyPressed(func(keyval uint, _ uint, state gdk.ModifierType) bool {
+		if !kc.recording {
+			return false
+		}
+		kc.record(KeyEvent(keyval, state))
+		return true
+	})
+	kc.button.AddController(keyController)
+
+	kc.render()
+	return kc
+}
+
+// Widget returns the GTK widget.
+func (kc *KeyCapture) Widget() gtk.Widgetter {
+	return kc.button
+}
+
+// Value returns the binding in config syntax, or "" if unbound.
+func (kc *KeyCapture) Value() string {
+	return kc.value
+}
+
+// SetOnChanged sets a callback run after a new binding is recorded.
+func (kc *KeyCapture) SetOnChanged(callback func(string)) {
+	kc.onChanged = callback
+}
+
+// startRecording clears the pending chord and waits for keys.
+func (kc *KeyCapture) startRecording() {
+	kc.recording = true
+	kc.keys = nil
+	kc.button.GrabFocus()
+	kc.render()
+}
+
+// record handles a key press while recording.
+func (kc *KeyCapture) record(ev keymap.Event) {
+	if ev.IsModifierKey() {
+		return
+	}
+
+	switch {
+	case ev.Name == "Escape" && ev.Mods == 0:
+		kc.finish(false)
+		return
+	case ev.Name == "BackSpace" && ev.Mods == 0 && len(kc.keys) == 0:
+		kc.keys = nil
+		kc.finish(true)
+		return
+	}
+
+	kc.keys = append(kc.keys, keymap.KeyFromEvent(ev))
+	kc.render()
+
+	// Wait for a possible next key of the chord
+	if kc.timer != 0 {
+		glib.SourceRemove(kc.timer)
+	}
+	kc.timer = glib.TimeoutAdd(uint(keymap.DefaultTimeout.Milliseconds()), func() bool {
+		kc.timer = 0
+		kc.finish(true)
+		return false
+	})
+}
+
+// finish stops recording, keeping the recorded chord if commit is set.
+func (kc *KeyCapture) finish(commit bool) {
+	if kc.timer != 0 {
+		glib.SourceRemove(kc.timer)
+		kc.timer = 0
+	}
+	kc.recording = false
+
+	if commit {
+		kc.value = kc.keys.String()
+		if kc.onChanged != nil {
+			kc.onChanged(kc.value)
+		}
+	}
+	kc.keys = nil
+	kc.render()
+}
+
+// render updates the button label.
+func (kc *KeyCapture) render() {
+	switch {
+	case kc.recording && len(kc.keys) > 0:
+		kc.button.SetLabel(kc.keys.String() + " …")
+	case kc.recording:
+		kc.button.SetLabel("Press keys…")
+	case kc.value == "":
+		kc.button.SetLabel("Unbound")
+	default:
+		kc.button.SetLabel(kc.value)
+	}
+	// The row's label names the button for screen readers, so the
+	// binding itself goes in the description
+	SetAccessibleDescription(kc.button, kc.button.Label())
+}
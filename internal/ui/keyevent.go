@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/lawrab/warren/internal/keymap"
+)
+
+// KeyEvent converts a GDK key press into a keymap event.
+func KeyEvent(keyval uint, state gdk.ModifierType) keymap.Event {
+	var mods keymap.Modifier
+	if state&gdk.ShiftMask != 0 {
+		mods |= keymap.ModShift
+	}
+	if state&gdk.ControlMask != 0 {
+		mods |= keymap.ModCtrl
+	}
+	if state&gdk.AltMask != 0 {
+		mods |= keymap.ModAlt
+	}
+	if state&gdk.SuperMask != 0 {
+		mods |= keymap.ModSuper
+	}
+	return keymap.NewEvent(gdk.KeyvalName(keyval), rune(gdk.KeyvalToUnicode(keyval)), mods)
+}
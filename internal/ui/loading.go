@@ -0,0 +1,449 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// countBatch is how many directories countItems counts between updates of
+// the listing.
+const countBatch = 64
+
+// lazyStatEntries is how many entries a directory needs for navigation
+// to show it by name first, reading sizes and dates in the background.
+const lazyStatEntries = 2000
+
+// statBatch is how many entries statFiles reads between updates of the
+// listing.
+const statBatch = 2048
+
+// loadingDelay is how long, in milliseconds, a background load runs before
+// the loading indicator appears, so fast loads don't flicker.
+const loadingDelay = 200
+
+// listSettings are the view settings a directory is listed with, copied so
+// the listing can be read off the GTK main thread.
+type listSettings struct {
+	showHidden bool
+	filter     fileops.NameFilter
+	sortMode   models.SortBy
+	sortOrder  models.SortOrder
+	hiddenLast bool                // Hidden entries go after the others
+	counts     *fileops.ItemCounts // nil when item counts are off
+}
+
+// listSettings returns the current listing settings.
+func (fv *FileView) listSettings() listSettings {
+	return listSettings{
+		showHidden: fv.showHidden,
+		filter:     fv.filter,
+		sortMode:   fv.sortMode,
+		sortOrder:  fv.sortOrder,
+		hiddenLast: fv.hiddenLast,
+		counts:     fv.itemCounts,
+	}
+}
+
+// read lists, filters and sorts the directory at path, and returns how
+// many hidden entries it left out. Directories get their cached item
+// counts; countItems fills in the rest.
+//
+// With lazy set, a directory of lazyStatEntries or more sorted by name is
+// only listed by name and type, leaving its entries Pending for statFiles.
+func (s listSettings) read(ctx context.Context, path string, lazy bool) ([]models.FileInfo, int, error) {
+	// Hidden entries are listed to be counted, but never stat'ed
+	files, err := fileops.ReadNames(ctx, path, true)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load directory: %w", err)
+	}
+	hidden := 0
+	if !s.showHidden {
+		files, hidden = fileops.DropHidden(files)
+	}
+	if !lazy || !sortsByName(s.sortMode) || len(files) < lazyStatEntries {
+		if files, err = fileops.StatListed(ctx, files); err != nil {
+			return nil, 0, fmt.Errorf("failed to load directory: %w", err)
+		}
+	}
+	files = s.filter.Apply(files)
+	if s.counts != nil {
+		s.counts.Fill(files)
+	}
+	s.sort(files)
+	return files, hidden, nil
+}
+
+// sort orders files by the sort mode and order, grouping hidden entries
+// last if the view does.
+func (s listSettings) sort(files []models.FileInfo) {
+	fileops.SortFiles(files, s.sortMode, s.sortOrder)
+	if s.hiddenLast {
+		fileops.GroupHidden(files)
+	}
+}
+
+// sortsByName reports whether mode orders entries by what ReadNames
+// reads, so a listing can be sorted before its entries are stat'ed.
+func sortsByName(mode models.SortBy) bool {
+	return mode == models.SortByName || mode == models.SortByExtension
+}
+
+// newLoadingIndicator builds the spinner shown over the listing while a
+// directory loads in the background. It starts hidden and lets clicks
+// through to the listing.
+func newLoadingIndicator() (*gtk.Box, *gtk.Spinner, *gtk.Label) {
+	spinner := gtk.NewSpinner()
+	label := gtk.NewLabel("")
+
+	box := gtk.NewBox(gtk.OrientationHorizontal, 8)
+	box.Append(spinner)
+	box.Append(label)
+	box.SetHAlign(gtk.AlignCenter)
+	box.SetVAlign(gtk.AlignCenter)
+	box.AddCSSClass("osd")
+	box.AddCSSClass("loading-indicator")
+	box.SetCanTarget(false)
+	box.SetVisible(false)
+	return box, spinner, label
+}
+
+// LoadDirectoryAsync is LoadDirectory with the directory read in a
+// goroutine, for navigation that must not freeze the window on slow
+// filesystems (NFS, spun-down disks). The current listing stays until the
+// new one is ready, under a spinner if that takes a moment. A large
+// directory sorted by name shows its names as soon as they are read, and
+// their sizes and dates as statFiles gets to them. Loading another
+// directory, in the background or not, cancels the load and done is never
+// called; otherwise done runs on the GTK main thread with the result.
+// A directory the user may not list is still entered, showing the error
+// and a Retry button in place of its entries.
+func (fv *FileView) LoadDirectoryAsync(path string, done func(err error)) {
+	ctx := fv.startLoad()
+	generation := fv.loadGeneration
+	settings := fv.listSettings()
+
+	fv.loadTimer = glib.TimeoutAdd(loadingDelay, func() bool {
+		fv.loadTimer = 0
+		fv.setLoading(path)
+		return false
+	})
+
+	go func() {
+		files, hidden, err := settings.read(ctx, path, true)
+		glib.IdleAdd(func() {
+			if generation != fv.loadGeneration {
+				return
+			}
+			fv.finishLoad()
+			switch {
+			case err == nil:
+				err = fv.showDirectory(path, files, hidden)
+			case errors.Is(err, fs.ErrPermission):
+				fv.showDirectoryError(path, err)
+				err = nil
+			}
+			if done != nil {
+				done(err)
+			}
+		})
+	}()
+}
+
+// Loading reports whether a directory is being loaded in the background.
+func (fv *FileView) Loading() bool {
+	return fv.loadCancel != nil
+}
+
+// startLoad cancels any background load and returns the context for a new
+// one.
+func (fv *FileView) startLoad() context.Context {
+	fv.cancelLoad()
+	ctx, cancel := context.WithCancel(context.Background())
+	fv.loadCancel = cancel
+	return ctx
+}
+
+// cancelLoad abandons the background load, if any, so its result is
+// discarded.
+func (fv *FileView) cancelLoad() {
+	fv.loadGeneration++
+	fv.finishLoad()
+}
+
+// finishLoad cancels the background load, if any, and hides the loading
+// indicator.
+func (fv *FileView) finishLoad() {
+	if fv.loadCancel != nil {
+		fv.loadCancel()
+		fv.loadCancel = nil
+	}
+	if fv.loadTimer != 0 {
+		glib.SourceRemove(fv.loadTimer)
+		fv.loadTimer = 0
+	}
+	fv.setLoading("")
+}
+
+// setLoading shows the loading indicator for path, or hides it if path is
+// empty.
+func (fv *FileView) setLoading(path string) {
+	if path == "" {
+		fv.loadingSpinner.Stop()
+		fv.loading.SetVisible(false)
+		return
+	}
+	fv.loadingLabel.SetText(fmt.Sprintf("Loading %s…", fileops.DisplayName(filepath.Base(path))))
+	fv.loadingSpinner.Start()
+	fv.loading.SetVisible(true)
+}
+
+// countItems counts the entries of listed directories that have no cached
+// count, in the background, and shows the counts in batches as they come
+// in. It cancels counting for the previous listing.
+func (fv *FileView) countItems() {
+	if fv.countCancel != nil {
+		fv.countCancel()
+		fv.countCancel = nil
+	}
+	if fv.itemCounts == nil {
+		return
+	}
+
+	var pending []models.FileInfo
+	for _, i := range fv.itemCounts.Fill(fv.files) {
+		pending = append(pending, fv.files[i])
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fv.countCancel = cancel
+	counts := fv.itemCounts
+	go func() {
+		for start := 0; start < len(pending); start += countBatch {
+			batch := pending[start:min(start+countBatch, len(pending))]
+			results := make(map[string]int, len(batch))
+			for _, dir := range batch {
+				if ctx.Err() != nil {
+					return
+				}
+				results[dir.Path] = counts.Count(dir)
+			}
+			glib.IdleAdd(func() {
+				// Cancelled only on the main thread, so this is final
+				if ctx.Err() == nil {
+					fv.showItemCounts(results)
+				}
+			})
+		}
+	}()
+}
+
+// showItemCounts updates the rows of directories counted by countItems.
+func (fv *FileView) showItemCounts(results map[string]int) {
+	var changed []int
+	for i := range fv.files {
+		file := &fv.files[i]
+		if n, ok := results[file.Path]; ok && file.IsDir && file.ItemCount != n {
+			file.ItemCount = n
+			fv.entries[file.Path] = *file
+			changed = append(changed, i)
+		}
+	}
+	fv.updateRows(changed)
+}
+
+// statFiles reads the details of the entries the listing holds by name
+// only, in the background, and shows them in batches as they come in.
+// It cancels reading them for the previous listing, and once everything
+// is read sorts the listing if Refresh was waiting for that, then counts
+// items.
+func (fv *FileView) statFiles() {
+	if fv.statCancel != nil {
+		fv.statCancel()
+		fv.statCancel = nil
+	}
+	fv.statSort = false
+	var pending []models.FileInfo
+	for _, file := range fv.files {
+		if file.Pending {
+			pending = append(pending, file)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fv.statCancel = cancel
+	go func() {
+		for start := 0; start < len(pending); start += statBatch {
+			batch := pending[start:min(start+statBatch, len(pending))]
+			if fileops.StatFiles(ctx, batch) != nil {
+				return
+			}
+			last := start+statBatch >= len(pending)
+			glib.IdleAdd(func() {
+				// Cancelled only on the main thread, so this is final
+				if ctx.Err() != nil {
+					return
+				}
+				fv.showStats(batch)
+				if last {
+					fv.statCancel = nil
+					if fv.statSort {
+						if err := fv.Refresh(); err != nil {
+							slog.Warn("Failed to sort the listing", "err", err)
+						}
+					}
+					fv.countItems()
+				}
+			})
+		}
+	}()
+}
+
+// showStats updates the rows of entries read by statFiles.
+func (fv *FileView) showStats(batch []models.FileInfo) {
+	results := make(map[string]models.FileInfo, len(batch))
+	for _, file := range batch {
+		results[file.Path] = file
+	}
+	var changed []int
+	for i := range fv.files {
+		file := &fv.files[i]
+		if result, ok := results[file.Path]; ok && file.Pending {
+			*file = result
+			fv.entries[file.Path] = result
+			changed = append(changed, i)
+		}
+	}
+	fv.updateRows(changed)
+}
+
+// updateRows makes the rows of the listing at changed, indices in
+// fv.files in increasing order, bind again.
+func (fv *FileView) updateRows(changed []int) {
+	if len(changed) == 0 {
+		return
+	}
+
+	// Store positions only match the listing while nothing is expanded
+	if len(fv.expandedPaths()) > 0 {
+		fv.rebindRows()
+		return
+	}
+	selectedPath := fv.GetSelectedPath()
+	fv.replacingRows = true
+	for start := 0; start < len(changed); {
+		// Replacing the items makes the rows bind again, a run of
+		// neighbours at a time
+		end := start + 1
+		for end < len(changed) && changed[end] == changed[end-1]+1 {
+			end++
+		}
+		objs := make([]*glib.Object, 0, end-start)
+		for _, i := range changed[start:end] {
+			objs = append(objs, gtk.NewStringObject(fv.files[i].Path).Object)
+		}
+		fv.store.Splice(uint(changed[start]), uint(end-start), objs)
+		start = end
+	}
+	fv.replacingRows = false
+	fv.restoreSelection(selectedPath)
+}
+
+// SetItemCounts sets whether directories show their number of entries in
+// the Size column, and reloads the listing if that changed.
+func (fv *FileView) SetItemCounts(enabled bool) error {
+	if enabled == (fv.itemCounts != nil) {
+		return nil
+	}
+	if enabled {
+		fv.itemCounts = fileops.NewItemCounts()
+	} else {
+		fv.itemCounts = nil
+	}
+	if fv.currentPath == "" {
+		return nil
+	}
+	return fv.LoadDirectory(fv.currentPath)
+}
+
+// newDirectoryError builds the message shown in place of the entries of a
+// directory that could not be listed, with a button calling retry. It
+// starts hidden.
+func newDirectoryError(retry func()) (*gtk.Box, *gtk.Label) {
+	icon := gtk.NewImageFromIconName("action-unavailable-symbolic")
+	icon.SetPixelSize(48)
+	icon.AddCSSClass("dim-label")
+
+	title := gtk.NewLabel("Permission Denied")
+	title.AddCSSClass("title-2")
+
+	label := gtk.NewLabel("")
+	label.SetWrap(true)
+	label.SetJustify(gtk.JustifyCenter)
+	label.AddCSSClass("dim-label")
+
+	button := gtk.NewButtonWithLabel("Retry")
+	button.SetHAlign(gtk.AlignCenter)
+	button.AddCSSClass("pill")
+	button.ConnectClicked(retry)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 12)
+	box.Append(icon)
+	box.Append(title)
+	box.Append(label)
+	box.Append(button)
+	box.SetHAlign(gtk.AlignCenter)
+	box.SetVAlign(gtk.AlignCenter)
+	box.SetMarginStart(24)
+	box.SetMarginEnd(24)
+	box.SetVisible(false)
+	return box, label
+}
+
+// showDirectoryError enters path with an empty listing and err shown in
+// its place. Leaving works as from any directory.
+func (fv *FileView) showDirectoryError(path string, err error) {
+	changed := path != fv.currentPath
+	fv.compareMarks = nil
+	fv.hiddenCount = 0
+	if changed {
+		fv.dropMarks()
+	}
+	fv.replaceRows([]models.FileInfo{}, nil)
+	fv.selectedIndex = -1
+	fv.currentPath = path
+	SetAccessibleLabel(fv.listView, "Files in "+path)
+
+	fv.dirErrorLabel.SetText(fmt.Sprintf("%s\n\n%v", fileops.DisplayName(path), err))
+	fv.dirError.SetVisible(true)
+	fv.notifyListing()
+
+	if changed {
+		for _, callback := range fv.onDirectoryChanged {
+			callback(path)
+		}
+	}
+}
+
+// retryDirectory loads the directory again after an error.
+func (fv *FileView) retryDirectory() {
+	fv.LoadDirectoryAsync(fv.currentPath, func(err error) {
+		if err != nil {
+			fv.dirErrorLabel.SetText(fmt.Sprintf("%s\n\n%v", fileops.DisplayName(fv.currentPath), err))
+		}
+	})
+}
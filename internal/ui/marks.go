@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// ConnectMarksChanged registers a callback invoked with the marked entries,
+// in listing order, whenever entries are marked or unmarked, including
+// when loading another directory clears them. Callbacks run in the order
+// they were added.
+func (fv *FileView) ConnectMarksChanged(callback func(marked []models.FileInfo)) {
+	fv.onMarksChanged = append(fv.onMarksChanged, callback)
+}
+
+// ToggleMark marks the entry at index, or unmarks it if it is marked.
+// Marks are a set of entries apart from the selection, for commands that
+// act on several at once; they last until another directory is loaded.
+func (fv *FileView) ToggleMark(index int) {
+	if index < 0 || index >= len(fv.files) {
+		return
+	}
+	path := fv.files[index].Path
+	if fv.marked[path] {
+		delete(fv.marked, path)
+	} else {
+		if fv.marked == nil {
+			fv.marked = make(map[string]bool)
+		}
+		fv.marked[path] = true
+	}
+	fv.updateRows([]int{index})
+	fv.notifyMarks()
+}
+
+// SelectRange marks the entries from index from to index to, inclusive,
+// in either order, keeping the marks already made. Indices past the ends
+// of the listing are clamped to them.
+func (fv *FileView) SelectRange(from, to int) {
+	if len(fv.files) == 0 {
+		return
+	}
+	first := min(max(min(from, to), 0), len(fv.files)-1)
+	last := min(max(max(from, to), 0), len(fv.files)-1)
+
+	var changed []int
+	for i := first; i <= last; i++ {
+		path := fv.files[i].Path
+		if fv.marked[path] {
+			continue
+		}
+		if fv.marked == nil {
+			fv.marked = make(map[string]bool)
+		}
+		fv.marked[path] = true
+		changed = append(changed, i)
+	}
+	if len(changed) == 0 {
+		return
+	}
+	fv.updateRows(changed)
+	fv.notifyMarks()
+}
+
+// ClearMarks unmarks every entry.
+func (fv *FileView) ClearMarks() {
+	if len(fv.marked) == 0 {
+		return
+	}
+	var changed []int
+	for i, file := range fv.files {
+		if fv.marked[file.Path] {
+			changed = append(changed, i)
+		}
+	}
+	fv.marked = nil
+	fv.updateRows(changed)
+	fv.notifyMarks()
+}
+
+// IsMarked reports whether the entry at path is marked.
+func (fv *FileView) IsMarked(path string) bool {
+	return fv.marked[path]
+}
+
+// GetMarked returns the marked entries in listing order. Entries marked
+// and since removed from the directory are left out.
+func (fv *FileView) GetMarked() []models.FileInfo {
+	if len(fv.marked) == 0 {
+		return nil
+	}
+	var marked []models.FileInfo
+	for _, file := range fv.files {
+		if fv.marked[file.Path] {
+			marked = append(marked, file)
+		}
+	}
+	return marked
+}
+
+// dropMarks forgets the marks of a directory being left, telling the
+// callbacks if there were any. The rows are about to be replaced, so
+// they are not updated.
+func (fv *FileView) dropMarks() {
+	if len(fv.marked) == 0 {
+		return
+	}
+	fv.marked = nil
+	fv.notifyMarks()
+}
+
+// notifyMarks runs the ConnectMarksChanged callbacks.
+func (fv *FileView) notifyMarks() {
+	if len(fv.onMarksChanged) == 0 {
+		return
+	}
+	marked := fv.GetMarked()
+	for _, callback := range fv.onMarksChanged {
+		callback(marked)
+	}
+}
@@ -0,0 +1,468 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/config"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// sortModeOptions and sortOrderOptions are the choices offered for the
+// default sort, in drop-down order.
+var (
+	sortModeOptions = []struct {
+		name string
+		mode models.SortBy
+	}{
+		{"name", models.SortByName},
+		{"size", models.SortBySize},
+		{"modified", models.SortByModTime},
+		{"extension", models.SortByExtension},
+		{"taken", models.SortByTaken},
+	}
+	sortOrderOptions = []struct {
+		name  string
+		order models.SortOrder
+	}{
+		{"ascending", models.SortAscending},
+		{"descending", models.SortDescending},
+	}
+)
+
+// schemeOptions, densityOptions and the format options are the choices
+// as config values. The default comes first so an empty value selects it.
+var (
+	schemeOptions     = []string{"system", "light", "dark"}
+	densityOptions    = []string{"normal", "compact", "comfortable"}
+	dateFormatOptions = []string{"default", "relative", "iso", "locale"}
+	sizeFormatOptions = []string{"binary", "decimal", "bytes"}
+	ellipsizeOptions  = []string{"middle", "end"}
+	logLevelOptions   = []string{"info", "debug", "warn", "error"}
+)
+
+// PreferencesWindow edits a copy of the configuration. Nothing changes
+// until Save, which validates the edited config and hands it to onSave.
+type PreferencesWindow struct {
+	window     *gtk.Window
+	errorLabel *gtk.Label
+	base       config.Config
+	apply      []func(*config.Config) // Copy widget state into a config
+	onSave     func(*config.Config) error
+}
+
+// ShowPreferences opens the preferences window for cfg over parent.
+// onSave receives the edited config and should persist and apply it; an
+// error it returns is shown in the window, which then stays open.
+func ShowPreferences(parent *gtk.Window, cfg *config.Config, onSave func(*config.Config) error) *PreferencesWindow {
+	p := &PreferencesWindow{
+		window:     gtk.NewWindow(),
+		errorLabel: gtk.NewLabel(""),
+		base:       *cfg,
+		onSave:     onSave,
+	}
+
+	p.window.SetTitle("Preferences")
+	p.window.SetTransientFor(parent)
+	p.window.SetModal(true)
+	p.window.SetDefaultSize(560, 520)
+
+	notebook := gtk.NewNotebook()
+	notebook.SetVExpand(true)
+	notebook.AppendPage(p.appearancePage(cfg), gtk.NewLabel("Appearance"))
+	notebook.AppendPage(p.keybindingsPage(cfg), gtk.NewLabel("Keybindings"))
+	notebook.AppendPage(p.confirmPage(cfg), gtk.NewLabel("Confirmations"))
+	notebook.AppendPage(p.generalPage(cfg), gtk.NewLabel("General"))
+	notebook.AppendPage(p.previewPage(cfg), gtk.NewLabel("Preview"))
+	notebook.AppendPage(p.hyprlandPage(cfg), gtk.NewLabel("Hyprland"))
+
+	p.errorLabel.AddCSSClass("status-error")
+	p.errorLabel.SetWrap(true)
+	p.errorLabel.SetXAlign(0)
+	p.errorLabel.SetHExpand(true)
+
+	cancel := gtk.NewButtonWithLabel("Cancel")
+	cancel.ConnectClicked(func() { p.window.Close() })
+	save := gtk.NewButtonWithLabel("Save")
+	save.AddCSSClass("suggested-action")
+	save.ConnectClicked(p.save)
+
+	buttons := gtk.NewBox(gtk.OrientationHorizontal, 6)
+	buttons.SetMarginTop(6)
+	buttons.SetMarginBottom(12)
+	buttons.SetMarginStart(12)
+	buttons.SetMarginEnd(12)
+	buttons.Append(p.errorLabel)
+	buttons.Append(cancel)
+	buttons.Append(save)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 0)
+	box.Append(notebook)
+	box.Append(buttons)
+	p.window.SetChild(box)
+
+	// Escape closes the window; a key capture that is recording handles
+	// Escape itself first
+	keyController := gtk.NewEventControllerKey()
+	keyController.ConnectKeyPressed(func(keyval uint, _ uint, _ gdk.ModifierType) bool {
+		if keyval == gdk.KEY_Escape {
+			p.window.Close()
+			return true
+		}
+		return false
+	})
+	p.window.AddController(keyController)
+
+	p.window.Present()
+	return p
+}
+
+// save validates the edited config and passes it to onSave.
+func (p *PreferencesWindow) save() {
+	edited := p.base
+	for _, apply := range p.apply {
+		apply(&edited)
+	}
+
+	if errs := config.Validate(&edited); len(errs) > 0 {
+		p.errorLabel.SetText(errs[0].Error())
+		return
+	}
+	if err := p.onSave(&edited); err != nil {
+		p.errorLabel.SetText(err.Error())
+		return
+	}
+	p.window.Close()
+}
+
+// appearancePage edits the [appearance] section.
+func (p *PreferencesWindow) appearancePage(cfg *config.Config) gtk.Widgetter {
+	grid := settingsGrid()
+
+	showHidden := p.addSwitch(grid, 0, "Show hidden files", cfg.Appearance.ShowHidden)
+
+	width := gtk.NewSpinButtonWithRange(400, 7680, 10)
+	width.SetValue(float64(cfg.Appearance.WindowWidth))
+	addRow(grid, 1, "Window width", width)
+
+	height := gtk.NewSpinButtonWithRange(300, 4320, 10)
+	height.SetValue(float64(cfg.Appearance.WindowHeight))
+	addRow(grid, 2, "Window height", height)
+
+	remember := p.addSwitch(grid, 3, "Remember window size and layout", cfg.Appearance.RememberWindow)
+
+	// Aliases such as "ext" select their canonical entry
+	modeNames := make([]string, len(sortModeOptions))
+	for i, opt := range sortModeOptions {
+		modeNames[i] = opt.name
+	}
+	sortMode := gtk.NewDropDownFromStrings(modeNames)
+	current := config.ParseSortMode(cfg.Appearance.DefaultSortMode)
+	for i, opt := range sortModeOptions {
+		if opt.mode == current {
+			sortMode.SetSelected(uint(i))
+		}
+	}
+	addRow(grid, 4, "Default sort", sortMode)
+
+	orderNames := make([]string, len(sortOrderOptions))
+	for i, opt := range sortOrderOptions {
+		orderNames[i] = opt.name
+	}
+	sortOrder := gtk.NewDropDownFromStrings(orderNames)
+	if config.ParseSortOrder(cfg.Appearance.DefaultSortOrder) == models.SortDescending {
+		sortOrder.SetSelected(1)
+	}
+	addRow(grid, 5, "Default sort order", sortOrder)
+
+	scheme := newChoice(schemeOptions, cfg.Appearance.ColorScheme)
+	addRow(grid, 6, "Color scheme", scheme)
+
+	density := newChoice(densityOptions, cfg.Appearance.Density)
+	addRow(grid, 7, "Row density", density)
+
+	accent := gtk.NewEntry()
+	accent.SetText(cfg.Appearance.AccentColor)
+	accent.SetPlaceholderText("Theme default, or e.g. #3584e4")
+	accent.SetHExpand(true)
+	addRow(grid, 8, "Accent color", accent)
+
+	dateFormat := newChoice(dateFormatOptions, cfg.Appearance.DateFormat)
+	addRow(grid, 9, "Date format", dateFormat)
+
+	sizeFormat := newChoice(sizeFormatOptions, cfg.Appearance.SizeFormat)
+	addRow(grid, 10, "Size format", sizeFormat)
+
+	iconSize := gtk.NewSpinButtonWithRange(config.MinIconSize, config.MaxIconSize, 2)
+	iconSize.SetValue(float64(cfg.Appearance.IconSize))
+	addRow(grid, 11, "Icon size", iconSize)
+
+	ellipsize := newChoice(ellipsizeOptions, cfg.Appearance.Ellipsize)
+	addRow(grid, 12, "Cut long names at", ellipsize)
+
+	hiddenLast := p.addSwitch(grid, 13, "Hidden files last", cfg.Appearance.HiddenLast)
+
+	p.apply = append(p.apply, func(c *config.Config) {
+		c.Appearance.DateFormat = choiceValue(dateFormatOptions, dateFormat)
+		c.Appearance.SizeFormat = choiceValue(sizeFormatOptions, sizeFormat)
+		c.Appearance.IconSize = iconSize.ValueAsInt()
+		c.Appearance.Ellipsize = choiceValue(ellipsizeOptions, ellipsize)
+		c.Appearance.ColorScheme = choiceValue(schemeOptions, scheme)
+		c.Appearance.Density = choiceValue(densityOptions, density)
+		c.Appearance.AccentColor = strings.TrimSpace(accent.Text())
+		c.Appearance.ShowHidden = showHidden.Active()
+		c.Appearance.HiddenLast = hiddenLast.Active()
+		c.Appearance.WindowWidth = width.ValueAsInt()
+		c.Appearance.WindowHeight = height.ValueAsInt()
+		c.Appearance.RememberWindow = remember.Active()
+		if i := int(sortMode.Selected()); i < len(sortModeOptions) {
+			c.Appearance.DefaultSortMode = sortModeOptions[i].name
+		}
+		if i := int(sortOrder.Selected()); i < len(sortOrderOptions) {
+			c.Appearance.DefaultSortOrder = sortOrderOptions[i].name
+		}
+	})
+	return grid
+}
+
+// keybindingsPage edits the [keybindings] section with key capture buttons.
+func (p *PreferencesWindow) keybindingsPage(cfg *config.Config) gtk.Widgetter {
+	grid := settingsGrid()
+
+	hint := gtk.NewLabel("Click a binding and press keys. Keys pressed in quick succession form a chord; BackSpace unbinds, Escape cancels.")
+	hint.SetWrap(true)
+	hint.SetXAlign(0)
+	hint.AddCSSClass("dim-label")
+	grid.Attach(hint, 0, 0, 2, 1)
+
+	for i, b := range cfg.Keybindings.Bindings() {
+		action := b.Action
+		capture := NewKeyCapture(b.Key)
+		addRow(grid, i+1, actionLabel(action), capture.Widget())
+		p.apply = append(p.apply, func(c *config.Config) {
+			_ = c.Keybindings.Set(action, capture.Value())
+		})
+	}
+
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	scrolled.SetChild(grid)
+	return scrolled
+}
+
+// confirmPage edits the [confirm] section.
+func (p *PreferencesWindow) confirmPage(cfg *config.Config) gtk.Widgetter {
+	grid := settingsGrid()
+
+	del := p.addSwitch(grid, 0, "Confirm delete", cfg.Confirm.Delete)
+	overwrite := p.addSwitch(grid, 1, "Confirm overwriting files", cfg.Confirm.Overwrite)
+	crossFS := p.addSwitch(grid, 2, "Confirm moves to another filesystem", cfg.Confirm.CrossFilesystem)
+
+	large := gtk.NewSpinButtonWithRange(0, 10000, 1)
+	large.SetDigits(1)
+	large.SetValue(cfg.Confirm.LargeOperation)
+	addRow(grid, 3, "Confirm transfers larger than (GB, 0 = never)", large)
+
+	largeDelete := gtk.NewSpinButtonWithRange(0, 10000, 1)
+	largeDelete.SetDigits(1)
+	largeDelete.SetValue(cfg.Confirm.LargeDelete)
+	addRow(grid, 4, "Type \"yes\" to delete more than (GB, 0 = never)", largeDelete)
+
+	largeDeleteItems := gtk.NewSpinButtonWithRange(0, 1000000, 100)
+	largeDeleteItems.SetValue(float64(cfg.Confirm.LargeDeleteItems))
+	addRow(grid, 5, "Type \"yes\" to delete more items than (0 = never)", largeDeleteItems)
+
+	outsideHome := p.addSwitch(grid, 6, "Type \"yes\" to delete outside the home folder", cfg.Confirm.DeleteOutsideHome)
+	symlinks := p.addSwitch(grid, 7, "Ask how to copy symlinks", cfg.Confirm.Symlinks)
+
+	p.apply = append(p.apply, func(c *config.Config) {
+		c.Confirm.Delete = del.Active()
+		c.Confirm.Overwrite = overwrite.Active()
+		c.Confirm.CrossFilesystem = crossFS.Active()
+		c.Confirm.LargeOperation = large.Value()
+		c.Confirm.LargeDelete = largeDelete.Value()
+		c.Confirm.LargeDeleteItems = largeDeleteItems.ValueAsInt()
+		c.Confirm.DeleteOutsideHome = outsideHome.Active()
+		c.Confirm.Symlinks = symlinks.Active()
+	})
+	return grid
+}
+
+// generalPage edits the [general] section.
+func (p *PreferencesWindow) generalPage(cfg *config.Config) gtk.Widgetter {
+	grid := settingsGrid()
+
+	startDir := gtk.NewEntry()
+	startDir.SetText(cfg.General.StartDirectory)
+	startDir.SetHExpand(true)
+	addRow(grid, 0, "Start directory", startDir)
+
+	notify := p.addSwitch(grid, 1, "Desktop notifications", cfg.General.DesktopNotifications)
+	socket := p.addSwitch(grid, 2, "Control socket (after restart)", cfg.General.ControlSocket)
+	countItems := p.addSwitch(grid, 3, "Directory item counts", cfg.General.CountItems)
+	watchSubdirs := p.addSwitch(grid, 4, "Live directory item counts", cfg.General.WatchSubdirectories)
+
+	pollInterval := gtk.NewSpinButtonWithRange(1, 600, 1)
+	pollInterval.SetValue(float64(cfg.General.PollInterval))
+	addRow(grid, 5, "Network mount poll interval (seconds)", pollInterval)
+
+	secureDelete := p.addSwitch(grid, 6, "Secure delete (shred)", cfg.General.SecureDelete)
+	shredPasses := gtk.NewSpinButtonWithRange(0, fileops.MaxShredPasses, 1)
+	shredPasses.SetValue(float64(cfg.General.ShredPasses))
+	addRow(grid, 7, "Shred passes (0 punches holes)", shredPasses)
+
+	logLevel := newChoice(logLevelOptions, cfg.General.LogLevel)
+	addRow(grid, 8, "Log level", logLevel)
+
+	terminal := gtk.NewEntry()
+	terminal.SetText(cfg.General.Terminal)
+	terminal.SetPlaceholderText("$TERMINAL")
+	addRow(grid, 9, "Terminal for editing", terminal)
+
+	editor := gtk.NewEntry()
+	editor.SetText(cfg.General.Editor)
+	editor.SetPlaceholderText("$EDITOR")
+	addRow(grid, 10, "Editor", editor)
+
+	ioLimit := gtk.NewSpinButtonWithRange(0, 100000, 10)
+	ioLimit.SetValue(cfg.General.IOLimit)
+	addRow(grid, 11, "Copy speed limit (MB/s, 0 = none)", ioLimit)
+
+	symlinks := newChoice(fileops.SymlinkPolicyNames, cfg.General.Symlinks)
+	addRow(grid, 12, "Copying symlinks", symlinks)
+
+	physicalPaths := p.addSwitch(grid, 13, "Enter links at their resolved path", cfg.General.PhysicalPaths)
+
+	p.apply = append(p.apply, func(c *config.Config) {
+		c.General.StartDirectory = strings.TrimSpace(startDir.Text())
+		c.General.DesktopNotifications = notify.Active()
+		c.General.ControlSocket = socket.Active()
+		c.General.CountItems = countItems.Active()
+		c.General.WatchSubdirectories = watchSubdirs.Active()
+		c.General.PollInterval = pollInterval.ValueAsInt()
+		c.General.SecureDelete = secureDelete.Active()
+		c.General.ShredPasses = shredPasses.ValueAsInt()
+		c.General.LogLevel = choiceValue(logLevelOptions, logLevel)
+		c.General.Terminal = strings.TrimSpace(terminal.Text())
+		c.General.Editor = strings.TrimSpace(editor.Text())
+		c.General.IOLimit = ioLimit.Value()
+		c.General.Symlinks = choiceValue(fileops.SymlinkPolicyNames, symlinks)
+		c.General.PhysicalPaths = physicalPaths.Active()
+	})
+	return grid
+}
+
+// previewPage edits the [preview] section.
+func (p *PreferencesWindow) previewPage(cfg *config.Config) gtk.Widgetter {
+	grid := settingsGrid()
+
+	enabled := p.addSwitch(grid, 0, "Show preview pane", cfg.Preview.Enabled)
+
+	maxKB := gtk.NewSpinButtonWithRange(1, 4096, 16)
+	maxKB.SetValue(float64(cfg.Preview.MaxTextKB))
+	addRow(grid, 1, "Text preview size (KiB)", maxKB)
+
+	lineNumbers := p.addSwitch(grid, 2, "Line numbers", cfg.Preview.LineNumbers)
+
+	p.apply = append(p.apply, func(c *config.Config) {
+		c.Preview.Enabled = enabled.Active()
+		c.Preview.MaxTextKB = maxKB.ValueAsInt()
+		c.Preview.LineNumbers = lineNumbers.Active()
+	})
+	return grid
+}
+
+// hyprlandPage edits the [hyprland] section.
+func (p *PreferencesWindow) hyprlandPage(cfg *config.Config) gtk.Widgetter {
+	grid := settingsGrid()
+
+	enabled := p.addSwitch(grid, 0, "Hyprland/Sway integration (after restart)", cfg.Hyprland.Enabled)
+	memory := p.addSwitch(grid, 1, "Remember directory per workspace", cfg.Hyprland.WorkspaceMemory)
+	autoSwitch := p.addSwitch(grid, 2, "Switch directory with workspace", cfg.Hyprland.AutoSwitch)
+	switchWorkspace := p.addSwitch(grid, 3, "Switch workspace with directory", cfg.Hyprland.SwitchWorkspace)
+	perMonitor := p.addSwitch(grid, 4, "Remember separately per monitor", cfg.Hyprland.PerMonitorMemory)
+	fallback := p.addSwitch(grid, 5, "Without Hyprland/Sway, remember the last directory", cfg.Hyprland.FallbackMemory)
+
+	openOn := gtk.NewEntry()
+	openOn.SetText(cfg.Hyprland.OpenOnWorkspace)
+	openOn.SetPlaceholderText(`Current workspace, "last", or a workspace`)
+	openOn.SetHExpand(true)
+	addRow(grid, 6, "Open files on workspace", openOn)
+
+	focusExisting := p.addSwitch(grid, 7, "Focus a file's open window instead of reopening", cfg.Hyprland.FocusExisting)
+
+	p.apply = append(p.apply, func(c *config.Config) {
+		c.Hyprland.Enabled = enabled.Active()
+		c.Hyprland.WorkspaceMemory = memory.Active()
+		c.Hyprland.AutoSwitch = autoSwitch.Active()
+		c.Hyprland.SwitchWorkspace = switchWorkspace.Active()
+		c.Hyprland.PerMonitorMemory = perMonitor.Active()
+		c.Hyprland.FallbackMemory = fallback.Active()
+		c.Hyprland.OpenOnWorkspace = strings.TrimSpace(openOn.Text())
+		c.Hyprland.FocusExisting = focusExisting.Active()
+	})
+	return grid
+}
+
+// newChoice creates a drop-down of options with value selected. Values
+// are matched case-insensitively; an unknown value selects the first.
+func newChoice(options []string, value string) *gtk.DropDown {
+	dropDown := gtk.NewDropDownFromStrings(options)
+	for i, opt := range options {
+		if strings.EqualFold(opt, value) {
+			dropDown.SetSelected(uint(i))
+		}
+	}
+	return dropDown
+}
+
+// choiceValue returns the option selected in a drop-down from newChoice.
+func choiceValue(options []string, dropDown *gtk.DropDown) string {
+	if i := int(dropDown.Selected()); i < len(options) {
+		return options[i]
+	}
+	return options[0]
+}
+
+// addSwitch adds a labelled switch to grid.
+func (p *PreferencesWindow) addSwitch(grid *gtk.Grid, row int, label string, active bool) *gtk.Switch {
+	sw := gtk.NewSwitch()
+	sw.SetActive(active)
+	sw.SetHAlign(gtk.AlignStart)
+	addRow(grid, row, label, sw)
+	return sw
+}
+
+// settingsGrid creates the two-column grid used by every page.
+func settingsGrid() *gtk.Grid {
+	grid := gtk.NewGrid()
+	grid.SetRowSpacing(8)
+	grid.SetColumnSpacing(16)
+	grid.SetMarginTop(12)
+	grid.SetMarginBottom(12)
+	grid.SetMarginStart(12)
+	grid.SetMarginEnd(12)
+	return grid
+}
+
+// addRow adds a label and widget to grid. The label also names the widget
+// for screen readers.
+func addRow(grid *gtk.Grid, row int, label string, widget gtk.Widgetter) {
+	l := gtk.NewLabel(label)
+	l.SetXAlign(0)
+	l.SetMnemonicWidget(widget)
+	grid.Attach(l, 0, row, 1, 1)
+	grid.Attach(widget, 1, row, 1, 1)
+}
+
+// actionLabel turns a config key such as "toggle_sort_order" into
+// "Toggle sort order".
+func actionLabel(action string) string {
+	words := strings.ReplaceAll(action, "_", " ")
+	if words == "" {
+		return words
+	}
+	return strings.ToUpper(words[:1]) + words[1:]
+}
@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/preview"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// PreviewPane shows the start of the selected file beside the list, with
+// syntax highlighting and line numbers for text. Files are read and
+// highlighted in a goroutine; a result that arrives after the selection
+// moved on is dropped. All methods must be called on the GTK main thread.
+type PreviewPane struct {
+	box     *gtk.Box
+	header  *gtk.Label
+	gutter  *gtk.Label
+	content *gtk.Label
+
+	limit       int
+	lineNumbers bool
+	file        *models.FileInfo // Shown or being loaded
+	generation  uint64           // Bumped by every Show to discard stale loads
+}
+
+// NewPreviewPane creates an empty preview pane.
+func NewPreviewPane() *PreviewPane {
+	p := &PreviewPane{
+		box:         gtk.NewBox(gtk.OrientationVertical, 6),
+		header:      gtk.NewLabel(""),
+		gutter:      gtk.NewLabel(""),
+		content:     gtk.NewLabel(""),
+		limit:       64 * 1024,
+		lineNumbers: true,
+	}
+
+	p.header.SetXAlign(0)
+	p.header.SetEllipsize(pango.EllipsizeEnd)
+	p.header.AddCSSClass("dim-label")
+	p.header.SetMarginStart(12)
+	p.header.SetMarginEnd(12)
+	p.header.SetMarginTop(6)
+
+	for _, label := range []*gtk.Label{p.gutter, p.content} {
+		label.SetXAlign(0)
+		label.SetYAlign(0)
+		label.AddCSSClass("monospace")
+	}
+	p.gutter.SetJustify(gtk.JustifyRight)
+	p.gutter.AddCSSClass("dim-label")
+
+	text := gtk.NewBox(gtk.OrientationHorizontal, 12)
+	text.SetMarginStart(12)
+	text.SetMarginEnd(12)
+	text.SetMarginBottom(12)
+	text.Append(p.gutter)
+	text.Append(p.content)
+
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetChild(text)
+	scrolled.SetVExpand(true)
+	scrolled.SetHExpand(true)
+
+	p.box.Append(p.header)
+	p.box.Append(scrolled)
+	p.box.SetSizeRequest(200, -1)
+	return p
+}
+
+// Widget returns the GTK widget.
+func (p *PreviewPane) Widget() gtk.Widgetter {
+	return p.box
+}
+
+// SetOptions sets how many bytes of a text file are shown and whether
+// lines are numbered, and redraws the current preview.
+func (p *PreviewPane) SetOptions(limit int, lineNumbers bool) {
+	if limit == p.limit && lineNumbers == p.lineNumbers {
+		return
+	}
+	p.limit = limit
+	p.lineNumbers = lineNumbers
+	if p.file != nil {
+		p.Show(p.file)
+	}
+}
+
+// SetVisible shows or hides the pane. A hidden pane doesn't load files;
+// showing it again previews the last file it was given.
+func (p *PreviewPane) SetVisible(visible bool) {
+	p.box.SetVisible(visible)
+	if visible && p.file != nil {
+		p.Show(p.file)
+	}
+}
+
+// Visible reports whether the pane is shown.
+func (p *PreviewPane) Visible() bool {
+	return p.box.Visible()
+}
+
+// Show previews file. Directories show their item count; text files
+// their first bytes, loaded in the background.
+func (p *PreviewPane) Show(file *models.FileInfo) {
+	// Keep a copy; the file view's entries are replaced on reload
+	copied := *file
+	file = &copied
+	p.file = file
+	p.generation++
+	if !p.box.Visible() {
+		return
+	}
+
+	if file.IsDir {
+		p.setText(fmt.Sprintf("%s — %s", fileops.DisplayName(file.Name), fileops.FormatItemCount(file.ItemCount)), "", "")
+		return
+	}
+
+	p.header.SetText(fmt.Sprintf("%s — %s", fileops.DisplayName(file.Name), fileops.FormatSize(file.Size)))
+	generation, path, name := p.generation, file.Path, file.Name
+	limit, lineNumbers := p.limit, p.lineNumbers
+
+	go func() {
+		header, markup, numbers := loadPreview(path, name, limit, lineNumbers)
+		glib.IdleAdd(func() {
+			if generation != p.generation {
+				return
+			}
+			p.setText(header, markup, numbers)
+		})
+	}()
+}
+
+// Clear empties the pane, e.g. when a directory has no entries.
+func (p *PreviewPane) Clear() {
+	p.file = nil
+	p.generation++
+	p.setText("", "", "")
+}
+
+// setText replaces the header, content markup and line numbers.
+func (p *PreviewPane) setText(header, markup, numbers string) {
+	p.header.SetText(header)
+	p.content.SetMarkup(markup)
+	p.gutter.SetText(numbers)
+	p.gutter.SetVisible(numbers != "")
+}
+
+// loadPreview reads and highlights a file for Show, or describes an audio
+// or video file. It runs off the GTK thread and returns the header text,
+// content markup and line numbers.
+func loadPreview(path, name string, limit int, lineNumbers bool) (header, markup, numbers string) {
+	if kind := preview.KindOf(name); kind == preview.KindAudio || kind == preview.KindVideo {
+		return loadMediaPreview(path, name)
+	}
+
+	text, err := preview.LoadText(path, limit)
+	switch {
+	case errors.Is(err, preview.ErrBinary):
+		return fmt.Sprintf("%s — binary file", fileops.DisplayName(name)), "", ""
+	case errors.Is(err, preview.ErrSpecial):
+		return fmt.Sprintf("%s — special file", fileops.DisplayName(name)), "", ""
+	case err != nil:
+		return fmt.Sprintf("%s — %v", fileops.DisplayName(name), err), "", ""
+	}
+
+	header = fmt.Sprintf("%s — %s", fileops.DisplayName(name), text.Encoding)
+	if text.Truncated {
+		header += fmt.Sprintf(", first %s", fileops.FormatSize(int64(limit)))
+	}
+	if lineNumbers {
+		numbers = preview.LineNumbers(text.Content)
+	}
+	return header, preview.Highlight(text.Content, name), numbers
+}
+
+// loadMediaPreview describes an audio or video file for loadPreview.
+func loadMediaPreview(path, name string) (header, markup, numbers string) {
+	media, err := preview.ProbeMedia(path)
+	switch {
+	case errors.Is(err, preview.ErrNoProber):
+		return fmt.Sprintf("%s — install GStreamer to read media details", fileops.DisplayName(name)), "", ""
+	case err != nil:
+		return fmt.Sprintf("%s — %v", fileops.DisplayName(name), err), "", ""
+	}
+
+	header = fileops.DisplayName(name)
+	if summary := media.Summary(); summary != "" {
+		header += " — " + summary
+	}
+	return header, glib.MarkupEscapeText(formatMedia(media)), ""
+}
+
+// formatMedia lists the details of an audio or video file, one per line.
+func formatMedia(media preview.Media) string {
+	var b strings.Builder
+	for _, field := range media.Fields() {
+		fmt.Fprintf(&b, "%s: %s\n", field.Name, field.Value)
+	}
+	if b.Len() == 0 {
+		return "No metadata"
+	}
+	return b.String()
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/lawrab/warren/internal/exif"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/preview"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// PropertiesWindow describes one file: name, location, type, size,
+// modification time and permissions, followed by the details of audio and
+// video files or the EXIF data of photos, which are read in a goroutine
+// after the window opens.
+type PropertiesWindow struct {
+	window *gtk.Window
+	grid   *gtk.Grid
+	rows   int
+}
+
+// ShowProperties opens a properties window for file over parent.
+func ShowProperties(parent *gtk.Window, file *models.FileInfo) *PropertiesWindow {
+	p := &PropertiesWindow{
+		window: gtk.NewWindow(),
+		grid:   gtk.NewGrid(),
+	}
+
+	p.window.SetTitle(fmt.Sprintf("%s Properties", fileops.DisplayName(file.Name)))
+	p.window.SetTransientFor(parent)
+	p.window.SetModal(true)
+	p.window.SetDefaultSize(440, -1)
+
+	p.grid.SetColumnSpacing(12)
+	p.grid.SetRowSpacing(6)
+	p.grid.SetMarginTop(12)
+	p.grid.SetMarginBottom(12)
+	p.grid.SetMarginStart(12)
+	p.grid.SetMarginEnd(12)
+
+	p.addRow("Name", file.Name)
+	p.addRow("Location", filepath.Dir(file.Path))
+	p.addRow("Type", describeType(file))
+	if file.IsSymlink {
+		p.addRow("Link target", file.SymlinkTarget)
+	}
+	if file.IsDir {
+		count := file.ItemCount
+		if count < 0 {
+			// Not counted yet, or item counts are off
+			count = fileops.CountEntries(file.Path)
+		}
+		p.addRow("Contents", fileops.FormatItemCount(count))
+	} else {
+		p.addRow("Size", fmt.Sprintf("%s (%s)",
+			fileops.FormatSize(file.Size), fileops.FormatSizeAs(file.Size, fileops.SizeBytes)))
+	}
+	p.addRow("Modified", file.ModTime.Format(time.DateTime))
+	p.addRow("Permissions", file.Permissions.String())
+
+	if kind := preview.KindOf(file.Name); !file.IsDir && (kind == preview.KindAudio || kind == preview.KindVideo) {
+		p.loadMedia(file.Path)
+	}
+	if !file.IsDir && exif.IsPhoto(file.Name) {
+		p.loadExif(file.Path)
+	}
+
+	scrolled := gtk.NewScrolledWindow()
+	scrolled.SetPolicy(gtk.PolicyNever, gtk.PolicyAutomatic)
+	scrolled.SetPropagateNaturalHeight(true)
+	scrolled.SetMaxContentHeight(600)
+	scrolled.SetChild(p.grid)
+	p.window.SetChild(scrolled)
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval uint, _ uint, state gdk.ModifierType) bool {
+		if KeyEvent(keyval, state).Name == "Escape" {
+			p.window.Close()
+			return true
+		}
+		return false
+	})
+	p.window.AddController(keys)
+
+	p.window.Present()
+	return p
+}
+
+// addRow appends a label and a selectable value, escaped as file names
+// are since most values are names or paths.
+func (p *PropertiesWindow) addRow(label, value string) {
+	name := gtk.NewLabel(label)
+	name.SetXAlign(1)
+	name.SetYAlign(0)
+	name.AddCSSClass("dim-label")
+
+	text := gtk.NewLabel(fileops.DisplayName(value))
+	text.SetXAlign(0)
+	text.SetSelectable(true)
+	text.SetWrap(true)
+	text.SetWrapMode(pango.WrapWordChar)
+	text.SetHExpand(true)
+	name.SetMnemonicWidget(text)
+
+	p.grid.Attach(name, 0, p.rows, 1, 1)
+	p.grid.Attach(text, 1, p.rows, 1, 1)
+	p.rows++
+}
+
+// addSection appends a bold heading spanning both columns.
+func (p *PropertiesWindow) addSection(title string) {
+	heading := gtk.NewLabel("")
+	heading.SetMarkup(fmt.Sprintf("<b>%s</b>", glib.MarkupEscapeText(title)))
+	heading.SetXAlign(0)
+	heading.SetMarginTop(6)
+	p.grid.Attach(heading, 0, p.rows, 2, 1)
+	p.rows++
+}
+
+// loadMedia adds the duration, streams and tags of an audio or video file
+// once gst-discoverer has read them.
+func (p *PropertiesWindow) loadMedia(path string) {
+	p.addSection("Media")
+	status := gtk.NewLabel("Reading…")
+	status.SetXAlign(0)
+	status.AddCSSClass("dim-label")
+	p.grid.Attach(status, 0, p.rows, 2, 1)
+	p.rows++
+
+	go func() {
+		media, err := preview.ProbeMedia(path)
+		glib.IdleAdd(func() {
+			switch {
+			case errors.Is(err, preview.ErrNoProber):
+				status.SetText("Install GStreamer to read media details")
+				return
+			case err != nil:
+				status.SetText(err.Error())
+				return
+			}
+			fields := media.Fields()
+			if len(fields) == 0 {
+				status.SetText("No metadata")
+				return
+			}
+			p.grid.Remove(status)
+			for _, field := range fields {
+				p.addRow(field.Name, field.Value)
+			}
+		})
+	}()
+}
+
+// loadExif adds the capture time, camera and GPS position of a photo.
+func (p *PropertiesWindow) loadExif(path string) {
+	go func() {
+		data, err := exif.Read(path)
+		glib.IdleAdd(func() {
+			if err != nil {
+				return
+			}
+			p.addSection("Photo")
+			if !data.Taken.IsZero() {
+				p.addRow("Taken", data.Taken.Format(time.DateTime))
+			}
+			if camera := data.Camera(); camera != "" {
+				p.addRow("Camera", camera)
+			}
+			if data.HasGPS {
+				p.addRow("Location", data.Position())
+			}
+		})
+	}()
+}
+
+// describeType names the file's type, e.g. "PNG image" or "Folder".
+func describeType(file *models.FileInfo) string {
+	switch {
+	case file.IsBrokenSymlink:
+		return "Broken link"
+	case file.IsDir:
+		return "Folder"
+	case !file.Permissions.IsRegular() && !file.IsSymlink:
+		return "Special file"
+	}
+	_, contentType := gio.ContentTypeGuess(file.Name, nil)
+	return fmt.Sprintf("%s (%s)", gio.ContentTypeGetDescription(contentType), contentType)
+}
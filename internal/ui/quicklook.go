@@ -0,0 +1,242 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/diamondburned/gotk4/pkg/pango"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/keymap"
+	"github.com/lawrab/warren/internal/preview"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// pdfPageSize is the longer side, in pixels, of rendered PDF pages.
+const pdfPageSize = 1600
+
+// QuickLook is a borderless fullscreen window previewing one file at a
+// time: images scaled to fit, the first page of a PDF, text, or the
+// metadata of audio and video files. Left and Right step through the other
+// entries of the listing; Escape or the key that opened it closes it.
+// Files are loaded in a goroutine, as in PreviewPane. All methods must be
+// called on the GTK main thread.
+type QuickLook struct {
+	window  *gtk.Window
+	header  *gtk.Label
+	picture *gtk.Picture
+	text    *gtk.Label
+	scroll  *gtk.ScrolledWindow
+
+	files      []models.FileInfo
+	index      int
+	limit      int
+	closeKey   *keymap.Key // Besides Escape; nil when unbound
+	generation uint64      // Bumped by every load to discard stale results
+
+	onFileShown []func(file *models.FileInfo)
+}
+
+// NewQuickLook creates a hidden quick look window over parent.
+func NewQuickLook(parent *gtk.Window) *QuickLook {
+	q := &QuickLook{
+		window:  gtk.NewWindow(),
+		header:  gtk.NewLabel(""),
+		picture: gtk.NewPicture(),
+		text:    gtk.NewLabel(""),
+		scroll:  gtk.NewScrolledWindow(),
+		limit:   64 * 1024,
+	}
+
+	q.window.SetTransientFor(parent)
+	q.window.SetModal(true)
+	q.window.SetDecorated(false)
+	q.window.SetHideOnClose(true)
+	q.window.AddCSSClass("quick-look")
+
+	q.header.SetEllipsize(pango.EllipsizeMiddle)
+	q.header.AddCSSClass("dim-label")
+	q.header.SetMarginTop(12)
+	q.header.SetMarginBottom(6)
+
+	q.picture.SetKeepAspectRatio(true)
+	q.picture.SetCanShrink(true)
+	q.picture.SetVExpand(true)
+	q.picture.SetHExpand(true)
+
+	q.text.SetXAlign(0)
+	q.text.SetYAlign(0)
+	q.text.SetSelectable(false)
+	q.text.AddCSSClass("monospace")
+	q.text.SetMarginStart(24)
+	q.text.SetMarginEnd(24)
+	q.text.SetMarginBottom(24)
+	q.scroll.SetChild(q.text)
+	q.scroll.SetVExpand(true)
+	q.scroll.SetHExpand(true)
+
+	box := gtk.NewBox(gtk.OrientationVertical, 0)
+	box.Append(q.header)
+	box.Append(q.picture)
+	box.Append(q.scroll)
+	q.window.SetChild(box)
+
+	keys := gtk.NewEventControllerKey()
+	keys.ConnectKeyPressed(func(keyval uint, _ uint, state gdk.ModifierType) bool {
+		ev := KeyEvent(keyval, state)
+		switch {
+		case ev.Name == "Escape" || (q.closeKey != nil && q.closeKey.Matches(ev)):
+			q.Close()
+		case ev.Name == "Left":
+			q.step(-1)
+		case ev.Name == "Right":
+			q.step(1)
+		default:
+			return false
+		}
+		return true
+	})
+	q.window.AddController(keys)
+
+	return q
+}
+
+// SetCloseKey sets the binding that closes the window besides Escape,
+// normally the one that opened it. For a chord, its last key closes.
+func (q *QuickLook) SetCloseKey(spec string) {
+	q.closeKey = nil
+	if seq, err := keymap.Parse(spec); err == nil && len(seq) > 0 {
+		q.closeKey = &seq[len(seq)-1]
+	}
+}
+
+// SetTextLimit sets how many bytes of a text file are shown.
+func (q *QuickLook) SetTextLimit(limit int) {
+	q.limit = limit
+}
+
+// ConnectFileShown registers a callback run when stepping to another file,
+// so the listing can follow.
+func (q *QuickLook) ConnectFileShown(callback func(file *models.FileInfo)) {
+	q.onFileShown = append(q.onFileShown, callback)
+}
+
+// Open shows files[index] and lets Left and Right step through files.
+func (q *QuickLook) Open(files []models.FileInfo, index int) {
+	if index < 0 || index >= len(files) {
+		return
+	}
+	q.files = files
+	q.index = index
+	q.load(&q.files[index])
+	q.window.Fullscreen()
+	q.window.Present()
+}
+
+// Close hides the window and forgets the files it was given.
+func (q *QuickLook) Close() {
+	q.generation++
+	q.files = nil
+	q.picture.SetPaintable(nil)
+	q.window.Close()
+}
+
+// Visible reports whether the window is open.
+func (q *QuickLook) Visible() bool {
+	return q.window.Visible()
+}
+
+// step shows the file delta entries away, stopping at either end.
+func (q *QuickLook) step(delta int) {
+	index := min(max(q.index+delta, 0), len(q.files)-1)
+	if index == q.index {
+		return
+	}
+	q.index = index
+	file := &q.files[index]
+	q.load(file)
+	for _, callback := range q.onFileShown {
+		callback(file)
+	}
+}
+
+// load shows file, reading it in the background unless it is a directory.
+func (q *QuickLook) load(file *models.FileInfo) {
+	q.generation++
+	q.picture.SetPaintable(nil)
+	q.setText("", "")
+
+	if file.IsDir {
+		q.header.SetText(fmt.Sprintf("%s — %s", fileops.DisplayName(file.Name), fileops.FormatItemCount(file.ItemCount)))
+		q.showPicture(false)
+		return
+	}
+
+	kind := preview.KindOf(file.Name)
+	q.header.SetText(fmt.Sprintf("%s — %s", fileops.DisplayName(file.Name), fileops.FormatSize(file.Size)))
+	q.showPicture(kind == preview.KindImage || kind == preview.KindPDF)
+
+	generation, path, name, limit := q.generation, file.Path, file.Name, q.limit
+	switch kind {
+	case preview.KindImage:
+		go func() {
+			texture, err := gdk.NewTextureFromFilename(path)
+			q.finishPicture(generation, name, texture, err)
+		}()
+
+	case preview.KindPDF:
+		go func() {
+			png, err := preview.RenderPDFPage(path, pdfPageSize)
+			var texture *gdk.Texture
+			if err == nil {
+				texture, err = gdk.NewTextureFromBytes(glib.NewBytes(png))
+			}
+			q.finishPicture(generation, name, texture, err)
+		}()
+
+	default:
+		// Text, or the details of audio and video files
+		go func() {
+			header, markup, _ := loadPreview(path, name, limit, false)
+			glib.IdleAdd(func() {
+				if generation != q.generation {
+					return
+				}
+				q.setText(header, markup)
+			})
+		}()
+	}
+}
+
+// finishPicture shows a texture loaded in the background, or the error.
+func (q *QuickLook) finishPicture(generation uint64, name string, texture *gdk.Texture, err error) {
+	glib.IdleAdd(func() {
+		if generation != q.generation {
+			return
+		}
+		switch {
+		case errors.Is(err, preview.ErrNoRenderer):
+			q.header.SetText(fmt.Sprintf("%s — install poppler-utils to preview PDFs", fileops.DisplayName(name)))
+		case err != nil:
+			q.header.SetText(fmt.Sprintf("%s — %v", fileops.DisplayName(name), err))
+		default:
+			q.picture.SetPaintable(texture)
+		}
+	})
+}
+
+// showPicture switches between the picture and the text view.
+func (q *QuickLook) showPicture(picture bool) {
+	q.picture.SetVisible(picture)
+	q.scroll.SetVisible(!picture)
+}
+
+// setText replaces the content markup, and the header unless it is empty.
+func (q *QuickLook) setText(header, markup string) {
+	if header != "" {
+		q.header.SetText(header)
+	}
+	q.text.SetMarkup(markup)
+}
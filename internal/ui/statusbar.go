@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/internal/status"
+)
+
+// StatusBar shows a persistent summary (selection and yank state) and
+// transient messages that clear themselves after a few seconds, with a
+// spinner for work still running in the background. Segments after them
+// show facts about the listing.
+// All methods must be called on the GTK main thread.
+type StatusBar struct {
+	box      *gtk.Box
+	label    *gtk.Label
+	spinner  *gtk.Spinner
+	segments [segmentCount]*gtk.Label
+	queue    *status.Queue
+	summary  string
+	prompt   string
+	timer    glib.SourceHandle
+}
+
+// Segment is a part of the status bar after the summary and messages.
+// Each is set on its own, so whatever keeps one up to date leaves the
+// others and the summary alone. They are shown in this order.
+type Segment int
+
+const (
+	SegmentItems     Segment = iota // Entries listed, and hidden ones left out
+	SegmentMarked                   // Count and size of the marked entries
+	SegmentFilter                   // The listing's filter
+	SegmentFreeSpace                // Free space on the current filesystem
+	segmentCount
+)
+
+// NewStatusBar creates the status bar label.
+func NewStatusBar() *StatusBar {
+	sb := &StatusBar{
+		label:   gtk.NewLabel("Ready"),
+		queue:   status.NewQueue(),
+		summary: "Ready",
+	}
+	sb.label.SetXAlign(0)
+	sb.label.SetHExpand(true)
+
+	sb.spinner = gtk.NewSpinner()
+	sb.spinner.SetVisible(false)
+
+	sb.box = gtk.NewBox(gtk.OrientationHorizontal, 6)
+	sb.box.SetHExpand(true)
+	SetAccessibleLabel(sb.box, "Status")
+	sb.box.Append(sb.spinner)
+	sb.box.Append(sb.label)
+	for i := range sb.segments {
+		segment := gtk.NewLabel("")
+		segment.AddCSSClass("dim-label")
+		segment.AddCSSClass("status-segment")
+		segment.SetVisible(false)
+		sb.segments[i] = segment
+		sb.box.Append(segment)
+	}
+	return sb
+}
+
+// SetSegment sets the text of a segment; "" hides it. Segments stay while
+// messages and prompts come and go.
+func (sb *StatusBar) SetSegment(segment Segment, text string) {
+	label := sb.segments[segment]
+	// Filters and file system types are the user's text
+	label.SetText(fileops.DisplayName(text))
+	label.SetVisible(text != "")
+}
+
+// Widget returns the GTK widget.
+func (sb *StatusBar) Widget() gtk.Widgetter {
+	return sb.box
+}
+
+// SetBusy shows or hides the spinner beside the text.
+func (sb *StatusBar) SetBusy(busy bool) {
+	sb.spinner.SetVisible(busy)
+	if busy {
+		sb.spinner.Start()
+	} else {
+		sb.spinner.Stop()
+	}
+}
+
+// SetSummary sets the persistent text shown when no message is queued.
+func (sb *StatusBar) SetSummary(text string) {
+	sb.summary = text
+	sb.render()
+}
+
+// SetPrompt shows text in place of messages and the summary until it is
+// cleared with an empty string. Used for pending input such as counts.
+func (sb *StatusBar) SetPrompt(text string) {
+	sb.prompt = text
+	sb.render()
+}
+
+// Info shows a routine message.
+func (sb *StatusBar) Info(text string) {
+	sb.Show(status.SeverityInfo, text)
+}
+
+// Warn shows a warning.
+func (sb *StatusBar) Warn(text string) {
+	sb.Show(status.SeverityWarning, text)
+}
+
+// Error shows an error message.
+func (sb *StatusBar) Error(text string) {
+	sb.Show(status.SeverityError, text)
+}
+
+// Show queues a message with the given severity. Screen readers read it
+// out at once, even if it waits behind other messages on screen.
+func (sb *StatusBar) Show(severity status.Severity, text string) {
+	sb.queue.Push(status.Message{Text: text, Severity: severity}, time.Now())
+	sb.render()
+	announce(sb.label, text, severity)
+}
+
+// render updates the label and schedules the next change.
+func (sb *StatusBar) render() {
+	now := time.Now()
+
+	text, class := sb.summary, ""
+	if msg, ok := sb.queue.Current(now); ok {
+		text, class = msg.Text, msg.Severity.CSSClass()
+	}
+	if sb.prompt != "" {
+		text, class = sb.prompt, ""
+	}
+
+	// Messages quote file names, which may hold newlines and the like
+	sb.label.SetText(fileops.DisplayName(text))
+	for _, severity := range status.Severities {
+		sb.label.RemoveCSSClass(severity.CSSClass())
+	}
+	if class != "" {
+		sb.label.AddCSSClass(class)
+	}
+
+	if sb.timer != 0 {
+		glib.SourceRemove(sb.timer)
+		sb.timer = 0
+	}
+	if d, ok := sb.queue.NextUpdate(now); ok {
+		// Round up so the message has expired when the timer fires
+		sb.timer = glib.TimeoutAdd(uint(d.Milliseconds())+1, func() bool {
+			sb.timer = 0
+			sb.render()
+			return false
+		})
+	}
+}
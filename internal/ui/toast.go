@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+)
+
+// ToastDuration is how long a toast stays on screen, in milliseconds.
+const ToastDuration = 4000
+
+// ToastOverlay floats short notifications over a child widget, in the
+// style of libadwaita's AdwToastOverlay. A new toast replaces the one on
+// screen. All methods must be called on the GTK main thread.
+type ToastOverlay struct {
+	overlay  *gtk.Overlay
+	revealer *gtk.Revealer
+	label    *gtk.Label
+	timer    glib.SourceHandle
+}
+
+// NewToastOverlay wraps child in an overlay that can show toasts.
+func NewToastOverlay(child gtk.Widgetter) *ToastOverlay {
+	t := &ToastOverlay{
+		overlay:  gtk.NewOverlay(),
+		revealer: gtk.NewRevealer(),
+		label:    gtk.NewLabel(""),
+	}
+
+	t.label.AddCSSClass("toast")
+	t.label.SetWrap(true)
+	t.label.SetMaxWidthChars(60)
+
+	t.revealer.SetChild(t.label)
+	t.revealer.SetTransitionType(gtk.RevealerTransitionTypeSlideUp)
+	t.revealer.SetHAlign(gtk.AlignCenter)
+	t.revealer.SetVAlign(gtk.AlignEnd)
+	t.revealer.SetMarginBottom(12)
+	t.revealer.SetCanTarget(false)
+
+	t.overlay.SetChild(child)
+	t.overlay.AddOverlay(t.revealer)
+	return t
+}
+
+// Widget returns the GTK widget.
+func (t *ToastOverlay) Widget() gtk.Widgetter {
+	return t.overlay
+}
+
+// Show displays text for ToastDuration. Failures are styled as errors.
+func (t *ToastOverlay) Show(text string, failed bool) {
+	t.label.SetText(fileops.DisplayName(text))
+	if failed {
+		t.label.AddCSSClass("toast-error")
+	} else {
+		t.label.RemoveCSSClass("toast-error")
+	}
+	t.revealer.SetRevealChild(true)
+
+	if t.timer != 0 {
+		glib.SourceRemove(t.timer)
+	}
+	t.timer = glib.TimeoutAdd(ToastDuration, func() bool {
+		t.timer = 0
+		t.revealer.SetRevealChild(false)
+		return false
+	})
+}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+	"github.com/lawrab/warren/internal/fileops"
+	"github.com/lawrab/warren/pkg/models"
+)
+
+// Tree mode shows directories expanded in place, indented under their
+// parent. The store holds the top-level entries and a gtk.TreeListModel
+// flattens it with the children of expanded rows; fv.files mirrors that
+// flattened list, so everything indexed by position (selection, yank
+// ranges, jumps) works the same in both modes.
+
+// SetTreeMode switches between entering directories and expanding them in
+// place, and reloads the listing.
+func (fv *FileView) SetTreeMode(enabled bool) error {
+	if enabled == fv.treeMode {
+		return nil
+	}
+	fv.treeMode = enabled
+	return fv.LoadDirectory(fv.currentPath)
+}
+
+// TreeMode reports whether directories expand in place.
+func (fv *FileView) TreeMode() bool {
+	return fv.treeMode
+}
+
+// ToggleExpanded expands the selected directory in place, or collapses it
+// if it is already expanded.
+func (fv *FileView) ToggleExpanded() error {
+	selected := fv.GetSelected()
+	if selected == nil {
+		return fmt.Errorf("no file selected")
+	}
+	if !selected.IsDir {
+		return fmt.Errorf("not a directory")
+	}
+
+	row := fv.tree.Row(uint(fv.selectedIndex))
+	if row == nil || !row.IsExpandable() {
+		return fmt.Errorf("cannot expand %s", selected.Name)
+	}
+	row.SetExpanded(!row.Expanded())
+	fv.SelectIndex(fv.selectedIndex)
+	return nil
+}
+
+// CollapseParent collapses the directory containing the selected entry and
+// selects it. It returns false if the selection is at the top level.
+func (fv *FileView) CollapseParent() bool {
+	if !fv.treeMode || fv.selectedIndex < 0 {
+		return false
+	}
+	row := fv.tree.Row(uint(fv.selectedIndex))
+	if row == nil {
+		return false
+	}
+	parent := row.Parent()
+	if parent == nil {
+		return false
+	}
+
+	index := int(parent.Position())
+	parent.SetExpanded(false)
+	fv.SelectIndex(index)
+	return true
+}
+
+// CollapseAll collapses every expanded directory.
+func (fv *FileView) CollapseAll() {
+	fv.ExpandToLevel(0)
+}
+
+// ExpandToLevel expands directories so that depth levels below the current
+// directory are shown, and collapses anything deeper.
+func (fv *FileView) ExpandToLevel(depth int) {
+	if !fv.treeMode {
+		return
+	}
+
+	// Rows are in pre-order, so the children of a row expanded here are
+	// visited next and expanded in turn
+	for i := 0; i < len(fv.files); i++ {
+		row := fv.tree.Row(uint(i))
+		if row == nil {
+			break
+		}
+		switch {
+		case int(row.Depth()) >= depth:
+			if row.Expanded() {
+				row.SetExpanded(false)
+			}
+		case !row.Expanded() && row.IsExpandable():
+			row.SetExpanded(true)
+		}
+	}
+
+	if len(fv.files) > 0 {
+		fv.SelectIndex(max(fv.selectedIndex, 0))
+	}
+}
+
+// replaceRows fills the store with the top-level entries in files, then
+// expands the given directories again.
+func (fv *FileView) replaceRows(files []models.FileInfo, expand []string) {
+	fv.replacingRows = true
+	fv.files = files
+	fv.entries = make(map[string]models.FileInfo, len(files))
+	fv.store.RemoveAll()
+	for _, file := range files {
+		fv.entries[file.Path] = file
+		fv.store.Append(gtk.NewStringObject(file.Path).Object)
+	}
+	fv.replacingRows = false
+
+	for _, path := range expand {
+		if i := fv.indexOf(path); i >= 0 {
+			if row := fv.tree.Row(uint(i)); row != nil && row.IsExpandable() {
+				row.SetExpanded(true)
+			}
+		}
+	}
+}
+
+// expandedPaths returns the expanded directories, parents first.
+func (fv *FileView) expandedPaths() []string {
+	if !fv.treeMode {
+		return nil
+	}
+	var paths []string
+	for i := range fv.files {
+		if row := fv.tree.Row(uint(i)); row != nil && row.Expanded() {
+			paths = append(paths, fv.files[i].Path)
+		}
+	}
+	return paths
+}
+
+// rootFiles returns the top-level entries of the listing.
+func (fv *FileView) rootFiles() []models.FileInfo {
+	if !fv.treeMode {
+		return fv.files
+	}
+	var files []models.FileInfo
+	for _, file := range fv.files {
+		if filepath.Dir(file.Path) == fv.currentPath {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// indexOf returns the position of path in the listing, or -1.
+func (fv *FileView) indexOf(path string) int {
+	for i := range fv.files {
+		if fv.files[i].Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// childModel creates the children of a directory row for the tree model.
+// Outside tree mode, and for files or unreadable directories, it returns
+// nil so the row can't be expanded.
+func (fv *FileView) childModel(item *glib.Object) *gio.ListModel {
+	if !fv.treeMode {
+		return nil
+	}
+	file, _, ok := fv.itemFile(item)
+	if !ok || !file.IsDir {
+		return nil
+	}
+	path := file.Path
+
+	children, err := fileops.ReadDirectory(context.Background(), path, fv.showHidden)
+	if err != nil {
+		return nil
+	}
+	children = fv.filter.Apply(children)
+	if fv.itemCounts != nil {
+		// Expanding is one directory at a time, so count right away
+		for _, i := range fv.itemCounts.Fill(children) {
+			children[i].ItemCount = fv.itemCounts.Count(children[i])
+		}
+	}
+	fv.listSettings().sort(children)
+
+	store := gio.NewListStore(glib.TypeObject)
+	for _, child := range children {
+		fv.entries[child.Path] = child
+		store.Append(gtk.NewStringObject(child.Path).Object)
+	}
+	return &store.ListModel
+}
+
+// syncExpandedRows keeps fv.files in step with the flattened tree when a
+// row is expanded or collapsed, including through its expander arrow. It
+// runs before the selection model sees the change, so rows are never
+// bound against a stale listing. Changes made by replaceRows and
+// reloadChanged are skipped; they set fv.files themselves.
+func (fv *FileView) syncExpandedRows(position, removed, added uint) {
+	if fv.replacingRows {
+		return
+	}
+
+	rows := make([]models.FileInfo, 0, added)
+	for i := range added {
+		file, _, _ := fv.itemFile(fv.tree.Row(position + i).Item())
+		rows = append(rows, file)
+	}
+	start, end := int(position), int(position+removed)
+	fv.files = slices.Replace(fv.files, start, end, rows...)
+
+	// Keep the selection on the same entry, or on the directory it was
+	// collapsed into
+	switch {
+	case fv.selectedIndex >= end:
+		fv.selectedIndex += int(added) - int(removed)
+	case fv.selectedIndex >= start:
+		fv.selectedIndex = start - 1
+	}
+}
@@ -26,20 +26,76 @@ type FileInfo struct {
 	// SymlinkTarget is the target path if this is a symlink
 	SymlinkTarget string
 
+	// IsBrokenSymlink indicates a symlink whose target does not exist
+	IsBrokenSymlink bool
+
 	// Permissions is the file mode and permission bits
 	Permissions os.FileMode
 
 	// ModTime is the last modification time
 	ModTime time.Time
 
+	// TakenTime is when a photo was taken, from its EXIF data. It is only
+	// read when sorting by it and stays zero for other files
+	TakenTime time.Time
+
+	// ItemCount is the number of entries in a directory, or -1 if the
+	// directory could not be read. It is 0 for files.
+	ItemCount int
+
 	// IsHidden indicates if the file should be considered hidden
 	// (starts with . on Unix systems)
 	IsHidden bool
 
+	// IsUnreadable indicates a file the current user cannot read, or a
+	// directory they cannot list or enter
+	IsUnreadable bool
+
+	// Pending indicates an entry listed by name and type only, whose
+	// size, modification time and permission bits are not read yet
+	Pending bool
+
 	// MimeType is the detected MIME type (filled in lazily if needed)
 	MimeType string
 }
 
+// IsExecutable reports whether this is a regular file with any execute
+// permission bit set.
+func (f FileInfo) IsExecutable() bool {
+	return f.Permissions.IsRegular() && f.Permissions.Perm()&0111 != 0
+}
+
+// IsSetuid reports whether this is a regular file that runs with its
+// owner's or group's privileges (setuid or setgid).
+func (f FileInfo) IsSetuid() bool {
+	return f.Permissions.IsRegular() && f.Permissions&(os.ModeSetuid|os.ModeSetgid) != 0
+}
+
+// Kind names what the entry is in words: "folder", "file", "link",
+// "broken link", "program", "setuid program", or "unreadable folder" or
+// "unreadable file". It tells screen readers what the emoji beside a name
+// in the file list shows.
+func (f FileInfo) Kind() string {
+	switch {
+	case f.IsUnreadable && f.IsDir:
+		return "unreadable folder"
+	case f.IsUnreadable:
+		return "unreadable file"
+	case f.IsDir:
+		return "folder"
+	case f.IsBrokenSymlink:
+		return "broken link"
+	case f.IsSymlink:
+		return "link"
+	case f.IsSetuid():
+		return "setuid program"
+	case f.IsExecutable():
+		return "program"
+	default:
+		return "file"
+	}
+}
+
 // FileList represents a collection of files in a directory.
 type FileList struct {
 	// Path is the directory path
@@ -64,6 +120,9 @@ const (
 	SortByModTime
 	// SortByExtension sorts files by file extension
 	SortByExtension
+	// SortByTaken sorts photos by EXIF capture time, other files by
+	// modification time
+	SortByTaken
 )
 
 // SortOrder represents ascending or descending sort order.
@@ -76,6 +135,14 @@ const (
 	SortDescending
 )
 
+// String returns "ascending" or "descending".
+func (o SortOrder) String() string {
+	if o == SortDescending {
+		return "descending"
+	}
+	return "ascending"
+}
+
 // String returns a human-readable name for the sort mode.
 func (s SortBy) String() string {
 	switch s {
@@ -87,6 +154,8 @@ func (s SortBy) String() string {
 		return "Modified"
 	case SortByExtension:
 		return "Extension"
+	case SortByTaken:
+		return "Taken"
 	default:
 		return "Name"
 	}
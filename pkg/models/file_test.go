@@ -1,6 +1,9 @@
 package models
 
-import "testing"
+import (
+	"os"
+	"testing"
+)
 
 func TestSortByString(t *testing.T) {
 	tests := []struct {
@@ -12,6 +15,7 @@ func TestSortByString(t *testing.T) {
 		{"size sort", SortBySize, "Size"},
 		{"modtime sort", SortByModTime, "Modified"},
 		{"extension sort", SortByExtension, "Extension"},
+		{"taken sort", SortByTaken, "Taken"},
 		{"invalid sort defaults to name", SortBy(999), "Name"},
 	}
 
@@ -24,3 +28,82 @@ func TestSortByString(t *testing.T) {
 		})
 	}
 }
+
+func TestFileInfoIsExecutable(t *testing.T) {
+	tests := []struct {
+		name string
+		mode os.FileMode
+		want bool
+	}{
+		{"plain file", 0644, false},
+		{"owner executable", 0744, true},
+		{"other executable", 0601, true},
+		{"directory", os.ModeDir | 0755, false},
+		{"symlink", os.ModeSymlink | 0777, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := FileInfo{Permissions: tt.mode}
+			if got := f.IsExecutable(); got != tt.want {
+				t.Errorf("IsExecutable() with mode %v = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileInfoIsSetuid(t *testing.T) {
+	tests := []struct {
+		name string
+		mode os.FileMode
+		want bool
+	}{
+		{"plain executable", 0755, false},
+		{"setuid", os.ModeSetuid | 0755, true},
+		{"setgid", os.ModeSetgid | 0755, true},
+		{"setgid directory", os.ModeDir | os.ModeSetgid | 0755, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := FileInfo{Permissions: tt.mode}
+			if got := f.IsSetuid(); got != tt.want {
+				t.Errorf("IsSetuid() with mode %v = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileInfoKind(t *testing.T) {
+	tests := []struct {
+		name string
+		file FileInfo
+		want string
+	}{
+		{"file", FileInfo{Permissions: 0644}, "file"},
+		{"folder", FileInfo{IsDir: true, Permissions: os.ModeDir | 0755}, "folder"},
+		{"unreadable folder", FileInfo{IsDir: true, IsUnreadable: true}, "unreadable folder"},
+		{"unreadable file", FileInfo{IsUnreadable: true, Permissions: 0600}, "unreadable file"},
+		{"link", FileInfo{IsSymlink: true, Permissions: os.ModeSymlink | 0777}, "link"},
+		{"broken link", FileInfo{IsSymlink: true, IsBrokenSymlink: true}, "broken link"},
+		{"program", FileInfo{Permissions: 0755}, "program"},
+		{"setuid program", FileInfo{Permissions: os.ModeSetuid | 0755}, "setuid program"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.file.Kind(); got != tt.want {
+				t.Errorf("Kind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortOrderString(t *testing.T) {
+	if got := SortAscending.String(); got != "ascending" {
+		t.Errorf("SortAscending.String() = %q", got)
+	}
+	if got := SortDescending.String(); got != "descending" {
+		t.Errorf("SortDescending.String() = %q", got)
+	}
+}